@@ -42,20 +42,13 @@ func main() {
 		buildDir = ".seam/output"
 	}
 	build := seam.LoadBuild(buildDir)
-	if len(build.Pages) > 0 {
-		fmt.Fprintf(os.Stderr, "Loaded %d pages from %s\n", len(build.Pages), buildDir)
-	} else {
+	if len(build.Pages) == 0 {
 		fmt.Fprintf(os.Stderr, "No build output at %s (API-only mode)\n", buildDir)
 	}
-	if build.RpcHashMap != nil {
-		fmt.Fprintf(os.Stderr, "RPC hash map loaded (%d procedures)\n", len(build.RpcHashMap.Procedures))
-	}
-	if build.I18nConfig != nil {
-		fmt.Fprintf(os.Stderr, "i18n: %d locales, default=%s\n", len(build.I18nConfig.Locales), build.I18nConfig.Default)
-	}
 	r.Build(build)
 
 	seamHandler := r.Handler()
+	_ = r.LogStartupConfig(os.Stderr)
 
 	// Static assets from build output, served under /_seam/static/*
 	publicDir := buildDir + "/public"
@@ -29,6 +29,7 @@ func main() {
 
 	mux := http.NewServeMux()
 	mux.Handle("/_seam/", r.Handler())
+	_ = r.LogStartupConfig(os.Stderr)
 
 	if err := seam.ListenAndServe("0.0.0.0:"+port, mux); err != nil {
 		fmt.Fprintf(os.Stderr, "server error: %v\n", err)
@@ -50,6 +50,27 @@ describe('parseSeamData', () => {
 		expect(document.getElementById).toHaveBeenCalledWith('__sd')
 	})
 
+	it('merges split data scripts back into the page data', () => {
+		const scripts: Record<string, string> = {
+			__data: JSON.stringify({ _scripts: ['__SEAM_I18N__'], user: { id: 1 } }),
+			__SEAM_I18N__: JSON.stringify({ _i18n: { locale: 'en' } }),
+		}
+		vi.stubGlobal('document', {
+			getElementById: vi.fn((id: string) => ({ textContent: scripts[id] ?? null })),
+		})
+
+		expect(parseSeamData()).toEqual({ user: { id: 1 }, _i18n: { locale: 'en' } })
+	})
+
+	it('throws when a split data script is missing', () => {
+		vi.stubGlobal('document', {
+			getElementById: vi.fn((id: string) =>
+				id === '__data' ? { textContent: JSON.stringify({ _scripts: ['__x'] }) } : null,
+			),
+		})
+		expect(() => parseSeamData()).toThrow('__x not found')
+	})
+
 	it('throws with custom dataId in error message', () => {
 		stubDocument(null)
 		expect(() => parseSeamData('__sd')).toThrow('__sd not found')
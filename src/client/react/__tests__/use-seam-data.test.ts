@@ -3,7 +3,7 @@
 import { describe, it, expect } from 'vitest'
 import { createElement } from 'react'
 import { renderToString } from 'react-dom/server'
-import { useSeamData, SeamDataProvider, isLoaderError } from '../src/index.js'
+import { useSeamData, useFormattedSlot, SeamDataProvider, isLoaderError } from '../src/index.js'
 
 // Helper component that renders useSeamData() result as JSON
 function DataCapture() {
@@ -41,6 +41,17 @@ describe('useSeamData', () => {
 		expect(JSON.parse(decoded)).toEqual({ title: 'Hello' })
 	})
 
+	it('reads server-formatted slots', () => {
+		const data = { post: { views: 1234 }, _fmt: { _f0: '1,234', slots: { 'post.views|number': '1,234' } } }
+		function SlotCapture() {
+			return createElement('pre', null, useFormattedSlot('post.views|number') ?? 'missing')
+		}
+		const html = renderToString(
+			createElement(SeamDataProvider, { value: data }, createElement(SlotCapture)),
+		)
+		expect(html).toBe('<pre>1,234</pre>')
+	})
+
 	it('throws when provider value is null', () => {
 		expect(() =>
 			renderToString(createElement(SeamDataProvider, { value: null }, createElement(DataCapture))),
@@ -2,7 +2,13 @@
 
 export { defineRoutes } from './define-routes.js'
 export { Hydrated } from './hydrated.js'
-export { useSeamData, SeamDataProvider, parseSeamData, isLoaderError } from './use-seam-data.js'
+export {
+	useSeamData,
+	useFormattedSlot,
+	SeamDataProvider,
+	parseSeamData,
+	isLoaderError,
+} from './use-seam-data.js'
 export { buildSentinelData } from './sentinel.js'
 export { useSeamSubscription } from './use-seam-subscription.js'
 export { useSeamStream } from './use-seam-stream.js'
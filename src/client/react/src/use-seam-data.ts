@@ -18,9 +18,36 @@ export function useSeamData<T>(key?: string): T {
 	return value as T
 }
 
+/**
+ * Read the server-formatted value of a filtered slot by its marker
+ * expression, e.g. `useFormattedSlot('post.createdAt|date:short')`, so
+ * hydration renders the same text as the server. Returns undefined when
+ * the page has no such root slot.
+ */
+export function useFormattedSlot<T = string>(expr: string): T | undefined {
+	const fmt = useSeamData<Record<string, unknown>>()._fmt as
+		| { slots?: Record<string, unknown> }
+		| undefined
+	return fmt?.slots?.[expr] as T | undefined
+}
+
+/**
+ * Read the page data script. Keys the server split into separate scripts
+ * (`PageDef.DataScripts`, listed under `_scripts`) are merged back in.
+ */
 export function parseSeamData(dataId = '__data'): Record<string, unknown> {
-	const el = document.getElementById(dataId)
-	if (!el?.textContent) throw new Error(`${dataId} not found`)
+	const data = readDataScript(dataId)
+	const split = data._scripts
+	if (Array.isArray(split)) {
+		delete data._scripts
+		for (const id of split as string[]) Object.assign(data, readDataScript(id))
+	}
+	return data
+}
+
+function readDataScript(id: string): Record<string, unknown> {
+	const el = document.getElementById(id)
+	if (!el?.textContent) throw new Error(`${id} not found`)
 	return JSON.parse(el.textContent) as Record<string, unknown>
 }
 
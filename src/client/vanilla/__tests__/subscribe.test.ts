@@ -67,6 +67,30 @@ describe('subscribe()', () => {
 		expect(onData).toHaveBeenCalledWith({ count: 42 })
 	})
 
+	it('rebuilds the full state from snapshot and delta events', async () => {
+		vi.stubGlobal(
+			'fetch',
+			mockFetchSse(
+				'event: snapshot\ndata: {"rows":[1,2],"total":2}\n\n',
+				'event: delta\ndata: [{"op":"replace","path":"/total","value":3},{"op":"add","path":"/rows/2","value":3}]\n\n',
+				'event: delta\ndata: [{"op":"remove","path":"/rows/0","value":null}]\n\n',
+				'event: complete\ndata: {}\n\n',
+			),
+		)
+
+		const client = createClient({ baseUrl: 'http://localhost:3000', reconnect: { enabled: false } })
+		const onData = vi.fn()
+		client.subscribe('dashboard', {}, onData)
+
+		await vi.advanceTimersByTimeAsync(0)
+
+		expect(onData.mock.calls.map((call) => call[0] as unknown)).toEqual([
+			{ rows: [1, 2], total: 2 },
+			{ rows: [1, 2, 3], total: 3 },
+			{ rows: [2, 3], total: 3 },
+		])
+	})
+
 	it('calls onError on SSE error event', async () => {
 		vi.stubGlobal(
 			'fetch',
@@ -3,6 +3,7 @@
 export { createClient } from './client.js'
 export { SeamClientError } from './errors.js'
 export { parseSseStream } from './sse-parser.js'
+export { applyPatch } from './json-patch.js'
 export { seamRpc, configureRpcMap } from './rpc.js'
 export { createChannelHandle } from './channel-handle.js'
 export { createWsChannelHandle } from './ws-channel-handle.js'
@@ -24,5 +25,6 @@ export type { ErrorCode } from './errors.js'
 export type { ChannelHandle } from './channel-handle.js'
 export type { ConnectionState, ReconnectConfig } from './reconnect.js'
 export type { SseCallbacks } from './sse-parser.js'
+export type { PatchOp } from './json-patch.js'
 
 export type ProcedureKind = 'query' | 'command' | 'subscription' | 'stream' | 'upload'
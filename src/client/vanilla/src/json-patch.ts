@@ -0,0 +1,65 @@
+/* src/client/vanilla/src/json-patch.ts */
+
+export interface PatchOp {
+	op: string
+	path: string
+	value?: unknown
+}
+
+function decodePointer(path: string): string[] {
+	if (path === '') return []
+	return path
+		.slice(1)
+		.split('/')
+		.map((token) => token.replace(/~1/g, '/').replace(/~0/g, '~'))
+}
+
+/**
+ * Apply a JSON Patch (RFC 6902) of add, remove, and replace operations
+ * as sent in subscription delta events. Returns the patched document;
+ * containers along changed paths are copied, so the input is not mutated.
+ */
+export function applyPatch(doc: unknown, ops: PatchOp[]): unknown {
+	for (const op of ops) {
+		doc = applyOp(doc, decodePointer(op.path), op)
+	}
+	return doc
+}
+
+function applyOp(node: unknown, tokens: string[], op: PatchOp): unknown {
+	if (tokens.length === 0) {
+		if (op.op === 'add' || op.op === 'replace') return op.value
+		throw new Error(`Unsupported patch op "${op.op}" at the document root`)
+	}
+	const [key, ...rest] = tokens as [string, ...string[]]
+	if (Array.isArray(node)) {
+		const copy = node.slice()
+		const index = key === '-' ? copy.length : Number(key)
+		if (rest.length > 0) {
+			copy[index] = applyOp(copy[index], rest, op)
+		} else if (op.op === 'add') {
+			copy.splice(index, 0, op.value)
+		} else if (op.op === 'replace') {
+			copy[index] = op.value
+		} else if (op.op === 'remove') {
+			copy.splice(index, 1)
+		} else {
+			throw new Error(`Unsupported patch op "${op.op}"`)
+		}
+		return copy
+	}
+	if (node !== null && typeof node === 'object') {
+		const copy: Record<string, unknown> = { ...(node as Record<string, unknown>) }
+		if (rest.length > 0) {
+			copy[key] = applyOp(copy[key], rest, op)
+		} else if (op.op === 'add' || op.op === 'replace') {
+			copy[key] = op.value
+		} else if (op.op === 'remove') {
+			delete copy[key]
+		} else {
+			throw new Error(`Unsupported patch op "${op.op}"`)
+		}
+		return copy
+	}
+	throw new Error(`Patch path "/${tokens.join('/')}" does not exist`)
+}
@@ -1,5 +1,7 @@
 /* src/client/vanilla/src/sse-parser.ts */
 
+import { applyPatch, type PatchOp } from './json-patch.js'
+
 export interface SseCallbacks {
 	onData: (data: unknown) => void
 	onError: (error: { code: string; message: string }) => void
@@ -7,15 +9,24 @@ export interface SseCallbacks {
 	onId?: (id: string) => void
 }
 
+/** State of a snapshot + delta subscription, rebuilt per stream. */
+interface DeltaState {
+	value?: unknown
+	started: boolean
+}
+
 /**
  * Parse an SSE byte stream from a fetch Response body.
- * Handles event/data/id fields separated by blank lines.
+ * Handles event/data/id fields separated by blank lines. Subscriptions
+ * registered with SubscribeDeltas send a snapshot event followed by delta
+ * events (JSON Patch); both are delivered to onData as the full state.
  */
 export async function parseSseStream(
 	reader: ReadableStreamDefaultReader<Uint8Array>,
 	callbacks: SseCallbacks,
 ): Promise<void> {
 	const decoder = new TextDecoder()
+	const state: DeltaState = { started: false }
 	let buffer = ''
 
 	for (;;) {
@@ -29,17 +40,17 @@ export async function parseSseStream(
 		while ((boundary = buffer.indexOf('\n\n')) !== -1) {
 			const block = buffer.slice(0, boundary)
 			buffer = buffer.slice(boundary + 2)
-			processBlock(block, callbacks)
+			processBlock(block, callbacks, state)
 		}
 	}
 
 	// Flush remaining buffer (server may close without trailing \n\n)
 	if (buffer.trim()) {
-		processBlock(buffer, callbacks)
+		processBlock(buffer, callbacks, state)
 	}
 }
 
-function processBlock(block: string, callbacks: SseCallbacks): void {
+function processBlock(block: string, callbacks: SseCallbacks, state: DeltaState): void {
 	let eventType = 'message'
 	let data = ''
 	let id: string | undefined
@@ -66,6 +77,27 @@ function processBlock(block: string, callbacks: SseCallbacks): void {
 		} catch {
 			callbacks.onError({ code: 'INTERNAL_ERROR', message: 'Failed to parse SSE data' })
 		}
+	} else if (eventType === 'snapshot') {
+		try {
+			state.value = JSON.parse(data) as unknown
+			state.started = true
+		} catch {
+			callbacks.onError({ code: 'INTERNAL_ERROR', message: 'Failed to parse SSE snapshot' })
+			return
+		}
+		callbacks.onData(state.value)
+	} else if (eventType === 'delta') {
+		if (!state.started) {
+			callbacks.onError({ code: 'INTERNAL_ERROR', message: 'SSE delta before snapshot' })
+			return
+		}
+		try {
+			state.value = applyPatch(state.value, JSON.parse(data) as PatchOp[])
+		} catch {
+			callbacks.onError({ code: 'INTERNAL_ERROR', message: 'Failed to apply SSE delta' })
+			return
+		}
+		callbacks.onData(state.value)
 	} else if (eventType === 'error') {
 		try {
 			const payload = JSON.parse(data) as { code?: string; message?: string }
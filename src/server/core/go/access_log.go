@@ -0,0 +1,157 @@
+/* src/server/core/go/access_log.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AccessLogFormat selects the access log line format.
+type AccessLogFormat int
+
+const (
+	// AccessLogCombined is the Apache/NCSA combined log format.
+	AccessLogCombined AccessLogFormat = iota
+	// AccessLogJSON writes one JSON object per line.
+	AccessLogJSON
+)
+
+// AccessLog writes one line per /_seam request. It is independent of
+// application logging so ops pipelines can consume it directly.
+type AccessLog struct {
+	Writer io.Writer
+	Format AccessLogFormat
+	// SampleRates maps a route class to the fraction of requests logged
+	// (0..1). Classes without an entry are always logged. Responses with
+	// status >= 500 are logged regardless of sampling.
+	SampleRates map[RouteClass]float64
+
+	mu sync.Mutex
+}
+
+// accessEntry carries per-request details filled in by inner handlers.
+type accessEntry struct {
+	principal string
+	locale    string
+}
+
+type accessEntryKeyType struct{}
+
+var accessEntryKey = accessEntryKeyType{}
+
+func (l *AccessLog) sampled(class RouteClass) bool {
+	rate, ok := l.SampleRates[class]
+	if !ok || rate >= 1 {
+		return true
+	}
+	return rate > 0 && rand.Float64() < rate
+}
+
+func (s *appState) accessLogMiddleware(log *AccessLog, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/_seam/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		start := time.Now()
+		class, procedure := s.classifyRoute(r)
+		entry := &accessEntry{}
+		rec := newResponseRecorder(w)
+		next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), accessEntryKey, entry)))
+
+		if rec.Status() < 500 && !log.sampled(class) {
+			return
+		}
+		line := accessLine{
+			Time:      start,
+			Remote:    ClientIP(r.Context()),
+			Principal: entry.principal,
+			Locale:    entry.locale,
+			Method:    r.Method,
+			URI:       r.URL.RequestURI(),
+			Proto:     r.Proto,
+			Status:    rec.Status(),
+			Bytes:     rec.bytes,
+			Duration:  time.Since(start),
+			Referer:   r.Referer(),
+			UserAgent: r.UserAgent(),
+			Class:     class,
+			Procedure: procedure,
+		}
+		log.mu.Lock()
+		defer log.mu.Unlock()
+		if log.Format == AccessLogJSON {
+			_, _ = log.Writer.Write(line.json())
+		} else {
+			_, _ = io.WriteString(log.Writer, line.combined())
+		}
+	})
+}
+
+type accessLine struct {
+	Time      time.Time
+	Remote    string
+	Principal string
+	Locale    string
+	Method    string
+	URI       string
+	Proto     string
+	Status    int
+	Bytes     int64
+	Duration  time.Duration
+	Referer   string
+	UserAgent string
+	Class     RouteClass
+	Procedure string
+}
+
+func (l accessLine) combined() string {
+	return fmt.Sprintf("%s - %s [%s] \"%s %s %s\" %d %s %q %q\n",
+		l.Remote, dashIfEmpty(l.Principal), l.Time.Format("02/Jan/2006:15:04:05 -0700"),
+		l.Method, l.URI, l.Proto, l.Status, bytesField(l.Bytes), dashIfEmpty(l.Referer), dashIfEmpty(l.UserAgent))
+}
+
+func (l accessLine) json() []byte {
+	fields := map[string]any{
+		"time":        l.Time.UTC().Format(time.RFC3339Nano),
+		"remote":      l.Remote,
+		"principal":   l.Principal,
+		"method":      l.Method,
+		"uri":         l.URI,
+		"proto":       l.Proto,
+		"status":      l.Status,
+		"bytes":       l.Bytes,
+		"duration_ms": float64(l.Duration.Microseconds()) / 1000,
+		"referer":     l.Referer,
+		"user_agent":  l.UserAgent,
+		"class":       l.Class,
+		"procedure":   l.Procedure,
+	}
+	if l.Locale != "" {
+		fields["locale"] = l.Locale
+	}
+	b, _ := json.Marshal(fields)
+	return append(b, '\n')
+}
+
+func dashIfEmpty(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func bytesField(n int64) string {
+	if n == 0 {
+		return "-"
+	}
+	return fmt.Sprint(n)
+}
@@ -0,0 +1,98 @@
+/* src/server/core/go/access_log_test.go */
+
+package seam
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func accessLogTestHandler(log *AccessLog) http.Handler {
+	ok := func(_ context.Context, _ json.RawMessage) (any, error) { return map[string]any{"ok": true}, nil }
+	fail := func(_ context.Context, _ json.RawMessage) (any, error) { return nil, InternalError("boom") }
+	return NewRouter().
+		Procedure(&ProcedureDef{Name: "greet", Handler: ok}).
+		Procedure(&ProcedureDef{Name: "save", Type: "command", Handler: ok}).
+		Procedure(&ProcedureDef{Name: "broken", Handler: fail}).
+		Handler(HandlerOptions{
+			AccessLog: log,
+			Principal: func(r *http.Request) string { return r.Header.Get("X-User") },
+		})
+}
+
+func TestAccessLogCombined(t *testing.T) {
+	var buf bytes.Buffer
+	handler := accessLogTestHandler(&AccessLog{Writer: &buf})
+
+	req := httptest.NewRequest("POST", "/_seam/procedure/greet", strings.NewReader(`{}`))
+	req.RemoteAddr = "203.0.113.7:5123"
+	req.Header.Set("X-User", "alice")
+	req.Header.Set("User-Agent", "test-agent")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	line := buf.String()
+	if !strings.HasPrefix(line, "203.0.113.7 - alice [") {
+		t.Fatalf("unexpected prefix: %q", line)
+	}
+	if !strings.Contains(line, `"POST /_seam/procedure/greet HTTP/1.1" 200 `) {
+		t.Fatalf("missing request line: %q", line)
+	}
+	if !strings.HasSuffix(line, "\"-\" \"test-agent\"\n") {
+		t.Fatalf("unexpected suffix: %q", line)
+	}
+}
+
+func TestAccessLogJSON(t *testing.T) {
+	var buf bytes.Buffer
+	handler := accessLogTestHandler(&AccessLog{Writer: &buf, Format: AccessLogJSON})
+
+	req := httptest.NewRequest("POST", "/_seam/procedure/save", strings.NewReader(`{}`))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("invalid JSON line %q: %v", buf.String(), err)
+	}
+	if entry["class"] != "command" || entry["procedure"] != "save" {
+		t.Errorf("class/procedure = %v/%v", entry["class"], entry["procedure"])
+	}
+	if entry["status"] != float64(200) || entry["bytes"].(float64) == 0 {
+		t.Errorf("status/bytes = %v/%v", entry["status"], entry["bytes"])
+	}
+}
+
+func TestAccessLogSampling(t *testing.T) {
+	var buf bytes.Buffer
+	handler := accessLogTestHandler(&AccessLog{
+		Writer:      &buf,
+		Format:      AccessLogJSON,
+		SampleRates: map[RouteClass]float64{RouteQuery: 0},
+	})
+
+	for _, name := range []string{"greet", "save", "broken"} {
+		req := httptest.NewRequest("POST", "/_seam/procedure/"+name, strings.NewReader(`{}`))
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines (command + 5xx query), got %d: %q", len(lines), buf.String())
+	}
+	if !strings.Contains(lines[0], `"procedure":"save"`) || !strings.Contains(lines[1], `"status":500`) {
+		t.Errorf("unexpected lines: %q", lines)
+	}
+}
+
+func TestAccessLogSkipsNonSeamPaths(t *testing.T) {
+	var buf bytes.Buffer
+	handler := accessLogTestHandler(&AccessLog{Writer: &buf})
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if buf.Len() != 0 {
+		t.Fatalf("expected no log line, got %q", buf.String())
+	}
+}
@@ -0,0 +1,132 @@
+/* src/server/core/go/active_streams.go */
+
+package seam
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ActiveStream is one piece of long-lived or background work in flight in
+// a handler built from the router: an SSE subscription or stream, a
+// WebSocket connection, a page loader goroutine. Subscriptions and
+// streams stay active until their handler closes its event channel, so a
+// handler that ignores context cancellation shows up here after its
+// client has gone.
+type ActiveStream struct {
+	Kind  string    `json:"kind"` // "subscription", "stream", "websocket", "loader"
+	Name  string    `json:"name"` // procedure, channel, or "route dataKey" for loaders
+	Since time.Time `json:"since"`
+}
+
+// activityTracker records the ActiveStreams of every handler built from
+// one router.
+type activityTracker struct {
+	mu      sync.Mutex
+	next    uint64
+	active  map[uint64]ActiveStream
+	changed chan struct{} // closed and replaced whenever work finishes
+}
+
+func newActivityTracker() *activityTracker {
+	return &activityTracker{active: make(map[uint64]ActiveStream), changed: make(chan struct{})}
+}
+
+// track records work starting now; the returned func marks it finished
+// and is safe to call more than once.
+func (t *activityTracker) track(kind, name string) func() {
+	t.mu.Lock()
+	t.next++
+	id := t.next
+	t.active[id] = ActiveStream{Kind: kind, Name: name, Since: time.Now()}
+	t.mu.Unlock()
+	var once sync.Once
+	return func() { once.Do(func() { t.finish(id) }) }
+}
+
+func (t *activityTracker) finish(id uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.active, id)
+	close(t.changed)
+	t.changed = make(chan struct{})
+}
+
+// trackEvents records a handler's event channel. Call the returned func
+// when the request stops reading: the channel is drained in the
+// background, so a producer blocked on send can observe cancellation, and
+// the entry finishes once the handler closes the channel.
+func trackEvents[T any](t *activityTracker, kind, name string, ch <-chan T) func() {
+	done := t.track(kind, name)
+	return func() {
+		go func() {
+			for range ch {
+			}
+			done()
+		}()
+	}
+}
+
+func (t *activityTracker) snapshot() []ActiveStream {
+	t.mu.Lock()
+	streams := make([]ActiveStream, 0, len(t.active))
+	for _, s := range t.active {
+		streams = append(streams, s)
+	}
+	t.mu.Unlock()
+	sort.Slice(streams, func(i, j int) bool {
+		if !streams[i].Since.Equal(streams[j].Since) {
+			return streams[i].Since.Before(streams[j].Since)
+		}
+		return streams[i].Kind+streams[i].Name < streams[j].Kind+streams[j].Name
+	})
+	return streams
+}
+
+// drain waits until no work is active or ctx is done.
+func (t *activityTracker) drain(ctx context.Context) error {
+	for {
+		t.mu.Lock()
+		n, changed := len(t.active), t.changed
+		t.mu.Unlock()
+		if n == 0 {
+			return nil
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			left := t.snapshot()
+			names := make([]string, len(left))
+			for i, s := range left {
+				names[i] = s.Kind + " " + s.Name
+			}
+			return fmt.Errorf("%d streams still active (%s): %w", len(left), strings.Join(names, ", "), ctx.Err())
+		}
+	}
+}
+
+func (r *Router) activity() *activityTracker {
+	if r.tracker == nil {
+		r.tracker = newActivityTracker()
+	}
+	return r.tracker
+}
+
+// ActiveStreams returns the work in flight across every handler built
+// from the router, oldest first.
+func (r *Router) ActiveStreams() []ActiveStream {
+	return r.activity().snapshot()
+}
+
+// Drain waits until every subscription, stream, WebSocket loop, and loader
+// goroutine of the router's handlers has exited, or ctx is done; the
+// error then lists what is still active. Drain does not cancel anything:
+// call it after clients disconnect or the server shuts down, e.g. to
+// assert in tests that handlers honor context cancellation.
+func (r *Router) Drain(ctx context.Context) error {
+	return r.activity().drain(ctx)
+}
@@ -0,0 +1,83 @@
+/* src/server/core/go/active_streams_test.go */
+
+package seam
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDrainAfterClientDisconnect(t *testing.T) {
+	router := NewRouter().Subscription(Subscribe("ticks", func(ctx context.Context, _ struct{}) (<-chan int, error) {
+		ch := make(chan int)
+		go func() {
+			defer close(ch)
+			for i := 0; ; i++ {
+				select {
+				case ch <- i:
+					time.Sleep(time.Millisecond)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+		return ch, nil
+	}))
+	srv := httptest.NewServer(router.Handler())
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, "GET", srv.URL+"/_seam/procedure/ticks?input=%7B%7D", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	line, _ := bufio.NewReader(resp.Body).ReadString('\n')
+	if !strings.HasPrefix(line, ":") && !strings.HasPrefix(line, "event") {
+		t.Fatalf("first line %q", line)
+	}
+	if active := router.ActiveStreams(); len(active) != 1 || active[0].Kind != "subscription" || active[0].Name != "ticks" {
+		t.Fatalf("active %+v", active)
+	}
+
+	cancel()
+	resp.Body.Close()
+	drainCtx, stop := context.WithTimeout(context.Background(), 2*time.Second)
+	defer stop()
+	if err := router.Drain(drainCtx); err != nil {
+		t.Fatal(err)
+	}
+	if active := router.ActiveStreams(); len(active) != 0 {
+		t.Errorf("active after drain %+v", active)
+	}
+}
+
+func TestDrainReportsLeakedSubscription(t *testing.T) {
+	release := make(chan struct{})
+	router := NewRouter().Subscription(Subscribe("leaky", func(context.Context, struct{}) (<-chan int, error) {
+		ch := make(chan int, 1)
+		ch <- 1
+		go func() { <-release; close(ch) }() // ignores cancellation
+		return ch, nil
+	}))
+	h := router.Handler(HandlerOptions{SSEIdleTimeout: 20 * time.Millisecond})
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/_seam/procedure/leaky?input=%7B%7D", nil))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	err := router.Drain(ctx)
+	if err == nil || !strings.Contains(err.Error(), "1 streams still active (subscription leaky)") {
+		t.Fatalf("drain: %v", err)
+	}
+
+	close(release)
+	if err := router.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
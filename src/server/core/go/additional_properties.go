@@ -0,0 +1,152 @@
+/* src/server/core/go/additional_properties.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// AdditionalProperties is a procedure's policy for input fields its
+// schema does not declare, applied to every object in the input. Reject
+// validates the input against the schema in every validation mode (even
+// ValidationModeNever); allow takes effect where input validation runs
+// (Router.Validation); strip always removes the fields before the handler
+// sees the input. The
+// policy is emitted as "additionalProperties" on the manifest entry.
+// Without one, the schema decides (JTD rejects unknown fields unless a
+// properties form sets additionalProperties).
+type AdditionalProperties string
+
+const (
+	AdditionalPropertiesReject AdditionalProperties = "reject" // fail validation with VALIDATION_ERROR
+	AdditionalPropertiesStrip  AdditionalProperties = "strip"  // drop unknown fields before the handler
+	AdditionalPropertiesAllow  AdditionalProperties = "allow"  // pass unknown fields through
+)
+
+// WithAdditionalProperties sets the procedure's unknown input field policy.
+func WithAdditionalProperties(policy AdditionalProperties) ProcedureOption {
+	return func(p *ProcedureDef) {
+		p.AdditionalProperties = policy
+	}
+}
+
+// applyAdditionalProperties checks the policies and returns procedures
+// with strip handlers wrapped, copying the slice when any is.
+func applyAdditionalProperties(procedures []ProcedureDef) []ProcedureDef {
+	var out []ProcedureDef
+	for i := range procedures {
+		p := &procedures[i]
+		switch p.AdditionalProperties {
+		case "", AdditionalPropertiesAllow:
+			continue
+		case AdditionalPropertiesReject:
+			if _, err := compileSchema(p.InputSchema); err != nil {
+				panic(fmt.Sprintf("procedure %q: AdditionalProperties reject needs a valid input schema: %v", p.Name, err))
+			}
+			continue
+		case AdditionalPropertiesStrip:
+		default:
+			panic(fmt.Sprintf("procedure %q has unknown AdditionalProperties %q (want reject, strip, or allow)", p.Name, p.AdditionalProperties))
+		}
+		cs, err := compileSchema(p.InputSchema)
+		if err != nil {
+			panic(fmt.Sprintf("procedure %q: AdditionalProperties strip needs a valid input schema: %v", p.Name, err))
+		}
+		if out == nil {
+			out = make([]ProcedureDef, len(procedures))
+			copy(out, procedures)
+		}
+		out[i].Handler = stripHandler(cs, p.Handler)
+	}
+	if out == nil {
+		return procedures
+	}
+	return out
+}
+
+// stripHandler removes undeclared fields from the input before next runs;
+// input that does not decode is passed on for the handler to reject.
+func stripHandler(cs *compiledSchema, next HandlerFunc) HandlerFunc {
+	return func(ctx context.Context, input json.RawMessage) (any, error) {
+		if v, err := decodeJSONValue(input); err == nil {
+			if raw, err := json.Marshal(stripUnknown(cs, v, "")); err == nil {
+				input = raw
+			}
+		}
+		return next(ctx, input)
+	}
+}
+
+// stripUnknown deletes the object members cs does not declare, in place.
+// discTag is the discriminator tag kept on mapping variants.
+func stripUnknown(cs *compiledSchema, data any, discTag string) any {
+	switch cs.kind {
+	case kindNullable:
+		if data != nil {
+			stripUnknown(cs.inner, data, discTag)
+		}
+	case kindElements:
+		if arr, ok := data.([]any); ok {
+			for _, item := range arr {
+				stripUnknown(cs.inner, item, "")
+			}
+		}
+	case kindValues:
+		if obj, ok := data.(map[string]any); ok {
+			for _, v := range obj {
+				stripUnknown(cs.inner, v, "")
+			}
+		}
+	case kindProperties:
+		obj, ok := data.(map[string]any)
+		if !ok {
+			break
+		}
+		declared := make(map[string]bool, len(cs.required)+len(cs.optional))
+		for _, group := range [][]namedSchema{cs.required, cs.optional} {
+			for _, ns := range group {
+				declared[ns.name] = true
+				if v, ok := obj[ns.name]; ok {
+					stripUnknown(ns.schema, v, "")
+				}
+			}
+		}
+		for k := range obj {
+			if !declared[k] && k != discTag {
+				delete(obj, k)
+			}
+		}
+	case kindDiscriminator:
+		if obj, ok := data.(map[string]any); ok {
+			if tag, ok := obj[cs.tag].(string); ok {
+				if variant, ok := cs.mapping[tag]; ok {
+					stripUnknown(variant, obj, cs.tag)
+				}
+			}
+		}
+	}
+	return data
+}
+
+// setAllowExtra overrides additionalProperties on every properties form
+// in cs.
+func (cs *compiledSchema) setAllowExtra(allow bool) {
+	if cs == nil {
+		return
+	}
+	if cs.kind == kindProperties {
+		cs.allowExtra = allow
+	}
+	cs.inner.setAllowExtra(allow)
+	for _, ns := range cs.required {
+		ns.schema.setAllowExtra(allow)
+	}
+	for _, ns := range cs.optional {
+		ns.schema.setAllowExtra(allow)
+	}
+	for _, variant := range cs.mapping {
+		variant.setAllowExtra(allow)
+	}
+}
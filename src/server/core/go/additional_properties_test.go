@@ -0,0 +1,119 @@
+/* src/server/core/go/additional_properties_test.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func echoInputProc(name string, schema any, opts ...ProcedureOption) *ProcedureDef {
+	def := &ProcedureDef{
+		Name:         name,
+		InputSchema:  schema,
+		OutputSchema: map[string]any{},
+		Handler: func(_ context.Context, input json.RawMessage) (any, error) {
+			return input, nil
+		},
+	}
+	for _, opt := range opts {
+		opt(def)
+	}
+	return def
+}
+
+func TestAdditionalPropertiesPolicies(t *testing.T) {
+	user := map[string]any{
+		"properties": map[string]any{
+			"name":    map[string]any{"type": "string"},
+			"address": map[string]any{"properties": map[string]any{"city": map[string]any{"type": "string"}}},
+		},
+	}
+	open := map[string]any{"properties": map[string]any{"name": map[string]any{"type": "string"}}, "additionalProperties": true}
+	h := NewRouter().
+		Procedure(echoInputProc("plain", user)).
+		Procedure(echoInputProc("strict", open, WithAdditionalProperties(AdditionalPropertiesReject))).
+		Procedure(echoInputProc("stripped", user, WithAdditionalProperties(AdditionalPropertiesStrip))).
+		Procedure(echoInputProc("tolerant", user, WithAdditionalProperties(AdditionalPropertiesAllow))).
+		Validation(ValidationModeAlways).
+		Handler()
+
+	const body = `{"name":"ada","extra":1,"address":{"city":"x","zip":"y"}}`
+	for _, tc := range []struct {
+		name string
+		code int
+		want string
+	}{
+		{"plain", http.StatusBadRequest, "unexpected property"},
+		{"strict", http.StatusBadRequest, `"path":"/extra"`},
+		{"stripped", http.StatusOK, `{"data":{"address":{"city":"x"},"name":"ada"},"ok":true}`},
+		{"tolerant", http.StatusOK, `"zip":"y"`},
+	} {
+		code, resp := rpcBody(h, "/_seam/procedure/"+tc.name, body, nil)
+		if code != tc.code || !strings.Contains(resp, tc.want) {
+			t.Errorf("%s: %d %s", tc.name, code, resp)
+		}
+	}
+	if code, _ := rpcBody(h, "/_seam/procedure/strict", `{"name":"ada"}`, nil); code != http.StatusOK {
+		t.Errorf("strict without extras: %d", code)
+	}
+}
+
+func TestAdditionalPropertiesRejectInEveryValidationMode(t *testing.T) {
+	schema := map[string]any{"properties": map[string]any{"name": map[string]any{"type": "string"}}}
+	h := NewRouter().
+		Procedure(echoInputProc("strict", schema, WithAdditionalProperties(AdditionalPropertiesReject))).
+		Procedure(echoInputProc("plain", schema)).
+		Validation(ValidationModeNever).
+		Handler()
+
+	if code, body := rpcBody(h, "/_seam/procedure/strict", `{"name":"ada","extra":1}`, nil); code != http.StatusBadRequest || !strings.Contains(body, "unexpected property") {
+		t.Errorf("strict: %d %s", code, body)
+	}
+	if code, body := rpcBody(h, "/_seam/procedure/plain", `{"name":"ada","extra":1}`, nil); code != http.StatusOK {
+		t.Errorf("plain without validation: %d %s", code, body)
+	}
+}
+
+func TestAdditionalPropertiesInManifest(t *testing.T) {
+	r := NewRouter().
+		Procedure(echoInputProc("a", map[string]any{}, WithAdditionalProperties(AdditionalPropertiesStrip))).
+		Procedure(echoInputProc("b", map[string]any{}))
+	raw, err := r.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(raw)
+	if !strings.Contains(s, `"additionalProperties":"strip"`) || strings.Count(s, "additionalProperties") != 1 {
+		t.Errorf("manifest %s", s)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("unknown policy accepted")
+		}
+	}()
+	NewRouter().Procedure(echoInputProc("c", map[string]any{}, WithAdditionalProperties("drop"))).Handler()
+}
+
+func TestStripUnknownDiscriminator(t *testing.T) {
+	cs, err := compileSchema(map[string]any{
+		"elements": map[string]any{
+			"discriminator": "kind",
+			"mapping": map[string]any{
+				"a": map[string]any{"properties": map[string]any{"x": map[string]any{"type": "int32"}}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, _ := decodeJSONValue([]byte(`[{"kind":"a","x":1,"y":2},{"kind":"z","y":3}]`))
+	got, _ := json.Marshal(stripUnknown(cs, v, ""))
+	if string(got) != `[{"kind":"a","x":1},{"kind":"z","y":3}]` {
+		t.Errorf("stripped %s", got)
+	}
+}
@@ -0,0 +1,136 @@
+/* src/server/core/go/annotations.go */
+
+package seam
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Annotations declare what a procedure or page touches, for policy checks
+// and tooling. They are emitted in the manifest ("annotations" on
+// procedures, "pages" for annotated pages) and returned by Procedures and
+// Pages; build output pages read them from the route manifest's
+// "annotations" entry. Annotations do not change how requests are served.
+type Annotations struct {
+	PII   []string `json:"pii,omitempty"`   // personal data categories touched (e.g. "email", "address")
+	Auth  string   `json:"auth,omitempty"`  // auth level required (e.g. "public", "user", "admin")
+	Cache string   `json:"cache,omitempty"` // cache sensitivity: "public", "private", or "no-store"
+}
+
+// cacheSensitivities are the values accepted in Annotations.Cache.
+var cacheSensitivities = map[string]bool{"": true, "public": true, "private": true, "no-store": true}
+
+// WithAnnotations declares the procedure's annotations.
+func WithAnnotations(a Annotations) ProcedureOption {
+	return func(p *ProcedureDef) {
+		p.Annotations = &a
+	}
+}
+
+// PolicyTarget is one procedure or page as seen by a Policy.
+type PolicyTarget struct {
+	Kind        string       // "procedure" or "page"
+	Name        string       // procedure name or page route
+	Annotations *Annotations // nil when the target declares none
+	SharedCache bool         // responses may be stored by shared caches (Cache "public" or a prerendered page)
+}
+
+// Policy checks one procedure or page at handler build and returns an
+// error describing the violation. Handler panics listing every violation
+// of HandlerOptions.Policies.
+type Policy func(PolicyTarget) error
+
+// NoPublicPIICache rejects targets that touch PII and may be stored by
+// shared caches.
+func NoPublicPIICache(t PolicyTarget) error {
+	if t.Annotations != nil && len(t.Annotations.PII) > 0 && t.SharedCache {
+		return fmt.Errorf("touches PII (%s) but may be cached publicly", strings.Join(t.Annotations.PII, ", "))
+	}
+	return nil
+}
+
+// PIIRequiresAuth rejects targets that touch PII without requiring an
+// auth level other than "public".
+func PIIRequiresAuth(t PolicyTarget) error {
+	if t.Annotations != nil && len(t.Annotations.PII) > 0 && (t.Annotations.Auth == "" || t.Annotations.Auth == "public") {
+		return fmt.Errorf("touches PII (%s) but requires no auth", strings.Join(t.Annotations.PII, ", "))
+	}
+	return nil
+}
+
+// RequireAnnotations rejects targets without annotations.
+func RequireAnnotations(t PolicyTarget) error {
+	if t.Annotations == nil {
+		return fmt.Errorf("has no annotations")
+	}
+	return nil
+}
+
+// policyTargets lists the procedures and pages of a handler, checking
+// their annotation values.
+func policyTargets(procedures []ProcedureDef, pages []PageDef) []PolicyTarget {
+	targets := make([]PolicyTarget, 0, len(procedures)+len(pages))
+	for i := range procedures {
+		p := &procedures[i]
+		checkAnnotations("procedure", p.Name, p.Annotations)
+		targets = append(targets, PolicyTarget{
+			Kind:        "procedure",
+			Name:        p.Name,
+			Annotations: p.Annotations,
+			SharedCache: p.Annotations != nil && p.Annotations.Cache == "public",
+		})
+	}
+	for i := range pages {
+		p := &pages[i]
+		checkAnnotations("page", p.Route, p.Annotations)
+		targets = append(targets, PolicyTarget{
+			Kind:        "page",
+			Name:        p.Route,
+			Annotations: p.Annotations,
+			SharedCache: p.Prerender || (p.Annotations != nil && p.Annotations.Cache == "public"),
+		})
+	}
+	return targets
+}
+
+// checkAnnotations panics on an unknown cache sensitivity.
+func checkAnnotations(kind, name string, a *Annotations) {
+	if a != nil && !cacheSensitivities[a.Cache] {
+		panic(fmt.Sprintf("%s %q has unknown cache sensitivity %q (want public, private, or no-store)", kind, name, a.Cache))
+	}
+}
+
+// checkPolicies panics with every violation of policies, sorted by kind
+// and name.
+func checkPolicies(policies []Policy, procedures []ProcedureDef, pages []PageDef) {
+	targets := policyTargets(procedures, pages)
+	var violations []string
+	for _, t := range targets {
+		for _, policy := range policies {
+			if err := policy(t); err != nil {
+				violations = append(violations, fmt.Sprintf("%s %s %v", t.Kind, t.Name, err))
+			}
+		}
+	}
+	if len(violations) > 0 {
+		sort.Strings(violations)
+		panic(fmt.Sprintf("policy violations: %s", strings.Join(violations, "; ")))
+	}
+}
+
+// pageAnnotations returns the manifest entries of annotated pages.
+func pageAnnotations(pages []PageDef) map[string]pageManifestEntry {
+	var entries map[string]pageManifestEntry
+	for i := range pages {
+		if pages[i].Annotations == nil {
+			continue
+		}
+		if entries == nil {
+			entries = make(map[string]pageManifestEntry)
+		}
+		entries[pages[i].Route] = pageManifestEntry{Annotations: pages[i].Annotations}
+	}
+	return entries
+}
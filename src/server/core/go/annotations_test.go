@@ -0,0 +1,90 @@
+/* src/server/core/go/annotations_test.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func annotatedRouter(account *PageDef) *Router {
+	return NewRouter().
+		Procedure(Query("getProfile", func(context.Context, struct{}) (string, error) { return "", nil },
+			WithAnnotations(Annotations{PII: []string{"email"}, Auth: "user", Cache: "private"}))).
+		Procedure(Query("getNews", func(context.Context, struct{}) (string, error) { return "", nil })).
+		Page(account)
+}
+
+func TestAnnotationsInManifestAndIntrospection(t *testing.T) {
+	account := &PageDef{Route: "/account", Template: "<p></p>", Annotations: &Annotations{PII: []string{"email", "address"}, Auth: "user"}}
+	r := annotatedRouter(account)
+
+	raw, err := r.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m struct {
+		Procedures map[string]struct {
+			Annotations *Annotations `json:"annotations"`
+		} `json:"procedures"`
+		Pages map[string]struct {
+			Annotations *Annotations `json:"annotations"`
+		} `json:"pages"`
+	}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		t.Fatal(err)
+	}
+	if a := m.Procedures["getProfile"].Annotations; a == nil || a.Auth != "user" || a.Cache != "private" {
+		t.Errorf("procedure annotations %+v", a)
+	}
+	if m.Procedures["getNews"].Annotations != nil {
+		t.Error("unannotated procedure has annotations")
+	}
+	if a := m.Pages["/account"].Annotations; a == nil || strings.Join(a.PII, ",") != "email,address" {
+		t.Errorf("page annotations %s", raw)
+	}
+
+	if p := r.Procedures()[1]; p.Name != "getProfile" || p.Annotations == nil {
+		t.Errorf("procedure info %+v", p)
+	}
+	if p := r.Pages()[0]; p.Annotations == nil || p.Annotations.Auth != "user" {
+		t.Errorf("page info %+v", p)
+	}
+}
+
+func TestPoliciesPanicAtHandlerBuild(t *testing.T) {
+	page := &PageDef{Route: "/account", Template: "<p></p>", Annotations: &Annotations{PII: []string{"email"}, Cache: "public"}}
+	r := annotatedRouter(page)
+	defer func() {
+		msg := fmt.Sprint(recover())
+		for _, want := range []string{
+			"page /account touches PII (email) but may be cached publicly",
+			"page /account touches PII (email) but requires no auth",
+			"procedure getNews has no annotations",
+		} {
+			if !strings.Contains(msg, want) {
+				t.Errorf("panic %q missing %q", msg, want)
+			}
+		}
+		if strings.Contains(msg, "getProfile") {
+			t.Errorf("compliant procedure reported: %s", msg)
+		}
+	}()
+	r.Handler(HandlerOptions{Policies: []Policy{NoPublicPIICache, PIIRequiresAuth, RequireAnnotations}})
+}
+
+func TestPoliciesAcceptCompliantRouter(t *testing.T) {
+	page := &PageDef{Route: "/account", Template: "<p></p>", Annotations: &Annotations{PII: []string{"email"}, Auth: "user", Cache: "no-store"}}
+	annotatedRouter(page).Handler(HandlerOptions{Policies: []Policy{NoPublicPIICache, PIIRequiresAuth}})
+
+	defer func() {
+		if recover() == nil {
+			t.Error("unknown cache sensitivity accepted")
+		}
+	}()
+	page = &PageDef{Route: "/account", Template: "<p></p>", Annotations: &Annotations{Cache: "shared"}}
+	annotatedRouter(page).Handler()
+}
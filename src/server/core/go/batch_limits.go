@@ -0,0 +1,86 @@
+/* src/server/core/go/batch_limits.go */
+
+package seam
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// BatchLimits protects the batch endpoint from oversized batches. Each
+// check is opt-in: zero or negative fields disable it, and
+// DefaultBatchLimits returns suggested values. Batches over MaxCalls or
+// MaxCost are rejected with VALIDATION_ERROR before any call runs; calls
+// still running after MaxDuration fail with VALIDATION_ERROR while
+// finished calls keep their results.
+type BatchLimits struct {
+	MaxCalls    int           // calls per batch (0 = unlimited)
+	MaxCost     int           // sum of call weights (0 = unlimited; see WithWeight)
+	MaxDuration time.Duration // whole-batch execution time (0 = RPCTimeout only)
+}
+
+// BatchCostHeader reports the accounted cost of a batch on its response.
+const BatchCostHeader = "X-Seam-Batch-Cost"
+
+// DefaultBatchLimits returns suggested limits: 100 calls and a cost of
+// 200 per batch, with no extra duration bound.
+func DefaultBatchLimits() BatchLimits {
+	return BatchLimits{MaxCalls: 100, MaxCost: 200}
+}
+
+// WithWeight sets the procedure's cost in a batch (default 1), so
+// expensive procedures use up BatchLimits.MaxCost sooner.
+func WithWeight(weight int) ProcedureOption {
+	return func(p *ProcedureDef) {
+		p.Weight = weight
+	}
+}
+
+// batchCost sums the weights of the calls' procedures; unknown
+// procedures weigh 1 (they fail without running).
+func (s *appState) batchCost(r *http.Request, calls []batchCall) int {
+	cost := 0
+	for _, call := range calls {
+		weight := 1
+		name := call.Procedure
+		if s.hashToName != nil {
+			name = s.hashToName[name]
+		}
+		if proc, ok := s.handlers[s.resolveVersion(name, r)]; ok && proc.Weight > 0 {
+			weight = proc.Weight
+		}
+		cost += weight
+	}
+	return cost
+}
+
+// checkBatch enforces MaxCalls and MaxCost, setting the cost header.
+func (s *appState) checkBatch(w http.ResponseWriter, r *http.Request, calls []batchCall) *Error {
+	l := s.opts.BatchLimits
+	if l.MaxCalls > 0 && len(calls) > l.MaxCalls {
+		return ValidationError(fmt.Sprintf("Batch has %d calls, over the limit of %d", len(calls), l.MaxCalls))
+	}
+	cost := s.batchCost(r, calls)
+	w.Header().Set(BatchCostHeader, strconv.Itoa(cost))
+	if l.MaxCost > 0 && cost > l.MaxCost {
+		return ValidationError(fmt.Sprintf("Batch cost %d exceeds the limit of %d", cost, l.MaxCost))
+	}
+	return nil
+}
+
+// batchDeadline bounds ctx by the RPC timeout and MaxDuration. The
+// returned error is what calls cut off by the deadline report.
+func (s *appState) batchDeadline(ctx context.Context) (context.Context, context.CancelFunc, *Error) {
+	timeout, timeoutErr := s.opts.Tuning.rpcTimeoutOr(s.opts.RPCTimeout), s.withRetryHint(rpcTimeoutError())
+	if d := s.opts.BatchLimits.MaxDuration; d > 0 && (timeout <= 0 || d < timeout) {
+		timeout, timeoutErr = d, ValidationError(fmt.Sprintf("Batch execution exceeded %s", d))
+	}
+	if timeout <= 0 {
+		return ctx, func() {}, timeoutErr
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, cancel, timeoutErr
+}
@@ -0,0 +1,83 @@
+/* src/server/core/go/batch_limits_test.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func batchLimitsHandler(limits BatchLimits) http.Handler {
+	fast := func(context.Context, json.RawMessage) (any, error) { return "ok", nil }
+	return NewRouter().
+		Procedure(&ProcedureDef{Name: "fast", Handler: fast}).
+		Procedure(Query("report", func(context.Context, struct{}) (string, error) { return "ok", nil }, WithWeight(10))).
+		Procedure(&ProcedureDef{Name: "slow", Handler: slowHandler(time.Second)}).
+		RpcHashMap(&RpcHashMap{Batch: "_batch", Procedures: map[string]string{"fast": "fast", "report": "report", "slow": "slow"}}).
+		Handler(HandlerOptions{RPCTimeout: 5 * time.Second, BatchLimits: limits})
+}
+
+func batchOf(procs ...string) string {
+	calls := make([]string, len(procs))
+	for i, p := range procs {
+		calls[i] = `{"procedure":"` + p + `","input":{}}`
+	}
+	return `{"calls":[` + strings.Join(calls, ",") + `]}`
+}
+
+func TestBatchLimitsCallsAndCost(t *testing.T) {
+	h := batchLimitsHandler(BatchLimits{MaxCalls: 3, MaxCost: 15})
+	cases := []struct {
+		body string
+		code int
+		want string
+	}{
+		{batchOf("fast", "fast", "report"), http.StatusOK, `"ok":true`},
+		{batchOf("fast", "fast", "fast", "fast"), http.StatusBadRequest, "Batch has 4 calls, over the limit of 3"},
+		{batchOf("report", "report"), http.StatusBadRequest, "Batch cost 20 exceeds the limit of 15"},
+	}
+	for _, c := range cases {
+		if code, body := rpcBody(h, "/_seam/procedure/_batch", c.body, nil); code != c.code || !strings.Contains(body, c.want) {
+			t.Errorf("%s: %d %s", c.body, code, body)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/_seam/procedure/_batch", strings.NewReader(batchOf("report", "unknown")))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if got := w.Header().Get(BatchCostHeader); got != "11" {
+		t.Errorf("%s = %q, want 11", BatchCostHeader, got)
+	}
+}
+
+func TestBatchLimitsAreOptIn(t *testing.T) {
+	calls := make([]string, 101)
+	for i := range calls {
+		calls[i] = "fast"
+	}
+	h := batchLimitsHandler(BatchLimits{})
+	if code, body := rpcBody(h, "/_seam/procedure/_batch", batchOf(calls...), nil); code != http.StatusOK {
+		t.Errorf("zero limits: %d %s", code, body)
+	}
+	h = batchLimitsHandler(DefaultBatchLimits())
+	if code, body := rpcBody(h, "/_seam/procedure/_batch", batchOf(calls...), nil); code != http.StatusBadRequest || !strings.Contains(body, "limit of 100") {
+		t.Errorf("DefaultBatchLimits MaxCalls: %d %s", code, body)
+	}
+}
+
+func TestBatchLimitsMaxDuration(t *testing.T) {
+	h := batchLimitsHandler(BatchLimits{MaxDuration: 30 * time.Millisecond})
+	code, body := rpcBody(h, "/_seam/procedure/_batch", batchOf("fast", "slow"), nil)
+	if code != http.StatusOK {
+		t.Fatalf("batch: %d %s", code, body)
+	}
+	want := `{"ok":false,"error":{"code":"VALIDATION_ERROR","message":"Batch execution exceeded 30ms","transient":false}}`
+	if !strings.Contains(body, `{"ok":true,"data":"ok"}`) || !strings.Contains(body, want) {
+		t.Errorf("results: %s", body)
+	}
+}
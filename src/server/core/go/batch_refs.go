@@ -0,0 +1,204 @@
+/* src/server/core/go/batch_refs.go */
+
+package seam
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Batch inputs may reference the output of an earlier call in the same
+// batch when the batch sets "refs": true: an object
+// {"$ref": "<index>.data.<path>"} anywhere in the input is replaced by
+// that value before the call runs, e.g. {"userId": {"$ref": "0.data.id"}}.
+// Without the flag "$ref" keys are ordinary input and reach the handler
+// untouched. Path segments are object keys or
+// array indices. A call with references waits for the calls it names;
+// calls without references still run concurrently. When a referenced
+// call fails, the dependent call fails with the same code instead of
+// running.
+
+// batchRef is one parsed {"$ref": ...} target.
+type batchRef struct {
+	call int
+	path []string
+}
+
+// parseBatchRef parses "<index>.data.<path>".
+func parseBatchRef(ref string) (batchRef, bool) {
+	parts := strings.Split(ref, ".")
+	if len(parts) < 2 || parts[1] != "data" {
+		return batchRef{}, false
+	}
+	call, err := strconv.Atoi(parts[0])
+	if err != nil || call < 0 {
+		return batchRef{}, false
+	}
+	for _, seg := range parts[2:] {
+		if seg == "" {
+			return batchRef{}, false
+		}
+	}
+	return batchRef{call: call, path: parts[2:]}, true
+}
+
+// refString returns the target of a {"$ref": "..."} object.
+func refString(v any) (string, bool) {
+	m, ok := v.(map[string]any)
+	if !ok || len(m) != 1 {
+		return "", false
+	}
+	ref, ok := m["$ref"].(string)
+	return ref, ok
+}
+
+// batchDependencies returns the calls each call's input references. A
+// malformed reference, or one to the call itself or a later call, is
+// reported as that call's error.
+func batchDependencies(calls []batchCall) ([][]int, []*Error) {
+	deps := make([][]int, len(calls))
+	errs := make([]*Error, len(calls))
+	for i, call := range calls {
+		if !bytes.Contains(call.Input, []byte(`"$ref"`)) {
+			continue
+		}
+		input, err := decodeNumbers(call.Input)
+		if err != nil {
+			continue // reported by the call's own input handling
+		}
+		seen := map[int]bool{}
+		walkRefs(input, func(ref string) {
+			if errs[i] != nil {
+				return
+			}
+			target, ok := parseBatchRef(ref)
+			switch {
+			case !ok:
+				errs[i] = ValidationError(fmt.Sprintf(`Invalid $ref %q: want "<call>.data.<path>"`, ref))
+			case target.call >= i:
+				errs[i] = ValidationError(fmt.Sprintf("Invalid $ref %q: only earlier calls can be referenced", ref))
+			case !seen[target.call]:
+				seen[target.call] = true
+				deps[i] = append(deps[i], target.call)
+			}
+		})
+	}
+	return deps, errs
+}
+
+// walkRefs calls fn with every $ref target in v.
+func walkRefs(v any, fn func(ref string)) {
+	if ref, ok := refString(v); ok {
+		fn(ref)
+		return
+	}
+	switch x := v.(type) {
+	case map[string]any:
+		for _, child := range x {
+			walkRefs(child, fn)
+		}
+	case []any:
+		for _, child := range x {
+			walkRefs(child, fn)
+		}
+	}
+}
+
+// resolveBatchRefs substitutes the $ref values in input from the results
+// of the calls it depends on, which have all completed.
+func resolveBatchRefs(input json.RawMessage, deps []int, results []batchResult) (json.RawMessage, *batchError) {
+	for _, d := range deps {
+		if res := results[d]; !res.Ok {
+			return nil, &batchError{
+				Code:      res.Error.Code,
+				Message:   fmt.Sprintf("Referenced call %d failed: %s", d, res.Error.Message),
+				Transient: res.Error.Transient,
+			}
+		}
+	}
+	decoded, err := decodeNumbers(input)
+	if err != nil {
+		return input, nil
+	}
+	outputs := make(map[int]any, len(deps))
+	for _, d := range deps {
+		raw, err := codecMarshal(results[d].Data)
+		if err != nil {
+			return nil, toBatchError(InternalError(fmt.Sprintf("Referenced call %d output: %s", d, err)))
+		}
+		if outputs[d], err = decodeNumbers(raw); err != nil {
+			return nil, toBatchError(InternalError(fmt.Sprintf("Referenced call %d output: %s", d, err)))
+		}
+	}
+
+	var refErr *Error
+	var substitute func(v any) any
+	substitute = func(v any) any {
+		if ref, ok := refString(v); ok {
+			target, _ := parseBatchRef(ref)
+			value, found := lookupRefPath(outputs[target.call], target.path)
+			if !found && refErr == nil {
+				refErr = ValidationError(fmt.Sprintf("Invalid $ref %q: no value at that path", ref))
+			}
+			return value
+		}
+		switch x := v.(type) {
+		case map[string]any:
+			for k, child := range x {
+				x[k] = substitute(child)
+			}
+		case []any:
+			for k, child := range x {
+				x[k] = substitute(child)
+			}
+		}
+		return v
+	}
+	resolved := substitute(decoded)
+	if refErr != nil {
+		return nil, toBatchError(refErr)
+	}
+	out, err := json.Marshal(resolved)
+	if err != nil {
+		return nil, toBatchError(InternalError(err.Error()))
+	}
+	return out, nil
+}
+
+// lookupRefPath follows path through objects (keys) and arrays (indices).
+func lookupRefPath(v any, path []string) (any, bool) {
+	for _, seg := range path {
+		switch x := v.(type) {
+		case map[string]any:
+			child, ok := x[seg]
+			if !ok {
+				return nil, false
+			}
+			v = child
+		case []any:
+			idx, err := strconv.Atoi(seg)
+			if err != nil || idx < 0 || idx >= len(x) {
+				return nil, false
+			}
+			v = x[idx]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// decodeNumbers decodes JSON keeping numbers exact, so referenced ids
+// beyond 2^53 survive substitution.
+func decodeNumbers(raw []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
@@ -0,0 +1,108 @@
+/* src/server/core/go/batch_refs_test.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func batchRefsHandler() http.Handler {
+	type user struct {
+		ID    int64    `json:"id"`
+		Teams []string `json:"teams"`
+	}
+	return NewRouter().
+		Procedure(Query("getSession", func(_ context.Context, _ struct{}) (user, error) {
+			time.Sleep(10 * time.Millisecond) // dependents must wait
+			return user{ID: 9007199254740993, Teams: []string{"core", "docs"}}, nil
+		})).
+		Procedure(&ProcedureDef{Name: "fail", Handler: func(context.Context, json.RawMessage) (any, error) { return nil, NotFoundError("no session") }}).
+		Procedure(&ProcedureDef{Name: "echo", Handler: func(_ context.Context, input json.RawMessage) (any, error) { return input, nil }}).
+		RpcHashMap(&RpcHashMap{Batch: "_batch", Procedures: map[string]string{"getSession": "getSession", "fail": "fail", "echo": "echo"}}).
+		Handler()
+}
+
+// batchResults runs calls as a batch with references enabled.
+func batchResults(t *testing.T, h http.Handler, calls string) []batchResult {
+	t.Helper()
+	code, body := rpcBody(h, "/_seam/procedure/_batch", `{"refs":true,"calls":`+calls+`}`, nil)
+	if code != http.StatusOK {
+		t.Fatalf("batch: %d %s", code, body)
+	}
+	var env struct {
+		Data struct {
+			Results []batchResult `json:"results"`
+		} `json:"data"`
+	}
+	dec := json.NewDecoder(strings.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&env); err != nil {
+		t.Fatal(err)
+	}
+	return env.Data.Results
+}
+
+func TestBatchRefsResolveEarlierOutput(t *testing.T) {
+	results := batchResults(t, batchRefsHandler(), `[
+		{"procedure":"getSession","input":{}},
+		{"procedure":"echo","input":{"userId":{"$ref":"0.data.id"},"team":{"$ref":"0.data.teams.1"},"all":[{"$ref":"0.data"}]}},
+		{"procedure":"echo","input":{"plain":true}}
+	]`)
+	if !results[1].Ok {
+		t.Fatalf("dependent call: %+v", results[1].Error)
+	}
+	got, _ := json.Marshal(results[1].Data)
+	want := `{"all":[{"id":9007199254740993,"teams":["core","docs"]}],"team":"docs","userId":9007199254740993}`
+	if string(got) != want {
+		t.Errorf("resolved input\n got %s\nwant %s", got, want)
+	}
+	if !results[2].Ok {
+		t.Errorf("independent call: %+v", results[2].Error)
+	}
+}
+
+func TestBatchRefsErrorsStayPerCall(t *testing.T) {
+	results := batchResults(t, batchRefsHandler(), `[
+		{"procedure":"fail","input":{}},
+		{"procedure":"echo","input":{"id":{"$ref":"0.data.id"}}},
+		{"procedure":"getSession","input":{}},
+		{"procedure":"echo","input":{"id":{"$ref":"2.data.missing"}}},
+		{"procedure":"echo","input":{"id":{"$ref":"5.data.id"}}},
+		{"procedure":"echo","input":{"id":{"$ref":"id"}}},
+		{"procedure":"echo","input":{"ok":1}}
+	]`)
+	wants := []struct {
+		ok      bool
+		code    string
+		message string
+	}{
+		{false, "NOT_FOUND", "no session"},
+		{false, "NOT_FOUND", "Referenced call 0 failed: no session"},
+		{true, "", ""},
+		{false, "VALIDATION_ERROR", `Invalid $ref "2.data.missing": no value at that path`},
+		{false, "VALIDATION_ERROR", `Invalid $ref "5.data.id": only earlier calls can be referenced`},
+		{false, "VALIDATION_ERROR", `Invalid $ref "id": want "<call>.data.<path>"`},
+		{true, "", ""},
+	}
+	for i, want := range wants {
+		res := results[i]
+		if res.Ok != want.ok || (!want.ok && (res.Error.Code != want.code || res.Error.Message != want.message)) {
+			t.Errorf("call %d: ok=%v error=%+v, want %+v", i, res.Ok, res.Error, want)
+		}
+	}
+}
+
+func TestBatchRefsNeedTheBatchFlag(t *testing.T) {
+	_, body := rpcBody(batchRefsHandler(), "/_seam/procedure/_batch", `{"calls":[
+		{"procedure":"getSession","input":{}},
+		{"procedure":"echo","input":{"schema":{"$ref":"0.data.id"}}}
+	]}`, nil)
+	if !strings.Contains(body, `{"ok":true,"data":{"schema":{"$ref":"0.data.id"}}}`) {
+		t.Errorf("unflagged batch rewrote $ref input: %s", body)
+	}
+}
@@ -0,0 +1,158 @@
+/* src/server/core/go/build_fallback.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	engine "github.com/canmi21/seam/src/server/engine/go"
+)
+
+// BuildFallback keeps pages answering while build output is missing at
+// runtime, as with a volume that is not mounted yet or is being swapped:
+// a page whose template cannot be read is served the fallback page with
+// 503 and Retry-After instead of a JSON 500, and the missing file is
+// logged. Lazily loaded templates (LoadBuildOutputLazy) are re-read on the
+// next request, so pages recover as soon as the files are back; a build
+// that was absent at startup is picked up by BuildSet.LoadWhenReady.
+type BuildFallback struct {
+	// HTML is the static fallback page (default: a minimal "temporarily
+	// unavailable" page).
+	HTML string
+	// Template, when set, replaces HTML and is rendered with the slots
+	// `route` and `path` (`<!--seam:route-->`).
+	Template string
+	// RetryAfter is advertised to clients (default 5s).
+	RetryAfter time.Duration
+
+	mu     sync.Mutex
+	logged map[string]time.Time // missing file -> last log
+}
+
+const defaultFallbackHTML = `<!doctype html>
+<html><head><meta charset="utf-8"><title>Temporarily unavailable</title></head>
+<body><h1>Temporarily unavailable</h1><p>This page is being updated. Please try again shortly.</p></body></html>
+`
+
+func (f *BuildFallback) retryAfter() time.Duration {
+	if f.RetryAfter > 0 {
+		return f.RetryAfter
+	}
+	return 5 * time.Second
+}
+
+// missingBuildFile returns the path of the file err failed to read, or ""
+// when err is not a missing-file error.
+func missingBuildFile(err error) string {
+	var pe *fs.PathError
+	if errors.As(err, &pe) && errors.Is(pe.Err, fs.ErrNotExist) {
+		return pe.Path
+	}
+	return ""
+}
+
+// logMissing reports a missing build file, at most once per minute per
+// file so a broken mount does not flood the log.
+func (f *BuildFallback) logMissing(route, file string, err error) {
+	f.mu.Lock()
+	now := time.Now()
+	if last, ok := f.logged[file]; ok && now.Sub(last) < time.Minute {
+		f.mu.Unlock()
+		return
+	}
+	if f.logged == nil {
+		f.logged = make(map[string]time.Time)
+	}
+	f.logged[file] = now
+	f.mu.Unlock()
+	logf(slog.LevelWarn, "build output missing: route=%s file=%s err=%q\n", route, file, err)
+}
+
+// serve writes the fallback page for route.
+func (f *BuildFallback) serve(w http.ResponseWriter, r *http.Request, route string) {
+	html := f.HTML
+	if html == "" {
+		html = defaultFallbackHTML
+	}
+	if f.Template != "" {
+		data, _ := json.Marshal(map[string]string{"route": route, "path": r.URL.Path})
+		rendered, err := engine.InjectNoScript(f.Template, string(data))
+		if err != nil {
+			logf(slog.LevelWarn, "build fallback template: %v\n", err)
+		} else {
+			html = rendered
+		}
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Retry-After", strconv.Itoa(int(f.retryAfter().Round(time.Second)/time.Second)))
+	w.WriteHeader(http.StatusServiceUnavailable)
+	_, _ = w.Write([]byte(html))
+}
+
+// pageTemplateFailed handles a page template that could not be read,
+// serving the fallback page when configured and the file is missing.
+func (s *appState) pageTemplateFailed(w http.ResponseWriter, r *http.Request, page *PageDef, err error) {
+	f := s.opts.BuildFallback
+	file := missingBuildFile(err)
+	if f == nil || file == "" {
+		writeError(w, http.StatusInternalServerError, InternalError(fmt.Sprintf("Template for '%s': %s", page.Route, err)))
+		return
+	}
+	f.logMissing(page.Route, file, err)
+	f.serve(w, r, page.Route)
+}
+
+// LoadWhenReady loads the build output in dir under version once it
+// appears, retrying every interval (default 5s) until it loads or ctx is
+// done. The version becomes active if none is. Until then the set
+// answers page requests with HandlerOptions.BuildFallback, if set.
+func (b *BuildSet) LoadWhenReady(ctx context.Context, version, dir string, interval time.Duration) {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		logged := ""
+		for {
+			err := b.LoadDir(version, dir)
+			if err == nil {
+				logf(slog.LevelInfo, "build output available: version=%s dir=%s\n", version, dir)
+				return
+			}
+			if msg := err.Error(); msg != logged {
+				logged = msg
+				if file := missingBuildFile(err); file != "" {
+					logf(slog.LevelWarn, "build output missing: version=%s file=%s (retrying every %s)\n", version, file, interval)
+				} else {
+					logf(slog.LevelWarn, "build output not loadable: version=%s dir=%s err=%q (retrying every %s)\n", version, dir, err, interval)
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// serveUnloaded answers a request while no build version is loaded.
+func (b *BuildSet) serveUnloaded(w http.ResponseWriter, r *http.Request) {
+	if f := b.opts.BuildFallback; f != nil && (!strings.HasPrefix(r.URL.Path, "/_seam/") || strings.HasPrefix(r.URL.Path, "/_seam/page/")) {
+		f.serve(w, r, r.URL.Path)
+		return
+	}
+	writeError(w, http.StatusServiceUnavailable, NewError("UNAVAILABLE", "No build output loaded", http.StatusServiceUnavailable))
+}
@@ -0,0 +1,82 @@
+/* src/server/core/go/build_fallback_test.go */
+
+package seam
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildFallbackOnMissingTemplate(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"route-manifest.json": `{"routes": {"/about": {"template": "t/about.html"}}}`,
+		"t/about.html":        `<p>about</p>`,
+	})
+	pages, err := LoadBuildOutputLazy(dir, "", NewTemplateCache(1<<20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = os.Remove(filepath.Join(dir, "t/about.html"))
+
+	get := func(h http.Handler) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/_seam/page/about", nil))
+		return w
+	}
+	if w := get(NewRouter().Page(&pages[0]).Handler()); w.Code != http.StatusInternalServerError {
+		t.Errorf("without fallback: %d", w.Code)
+	}
+
+	h := NewRouter().Page(&pages[0]).Handler(HandlerOptions{
+		BuildFallback: &BuildFallback{Template: `<h1>Back soon: <!--seam:route--></h1>`, RetryAfter: 30 * time.Second},
+	})
+	w := get(h)
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "30" ||
+		!strings.Contains(w.Body.String(), "Back soon: /about") {
+		t.Errorf("fallback: %d %v %s", w.Code, w.Header(), w.Body.String())
+	}
+
+	// The template is re-read once it reappears.
+	writeFiles(t, dir, map[string]string{"t/about.html": `<p>about</p>`})
+	if w := get(h); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "<p>about</p>") {
+		t.Errorf("after restore: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestBuildSetLoadWhenReady(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "dist")
+	set := buildSetTestRouter().BuildSet(HandlerOptions{BuildFallback: &BuildFallback{}})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	set.LoadWhenReady(ctx, "v1", dir, 10*time.Millisecond)
+
+	w := httptest.NewRecorder()
+	set.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusServiceUnavailable || !strings.Contains(w.Body.String(), "Temporarily unavailable") {
+		t.Errorf("before build: %d %s", w.Code, w.Body.String())
+	}
+	if code := postStatus(set, "greet", `{}`); code != http.StatusServiceUnavailable {
+		t.Errorf("procedure before build: %d", code)
+	}
+
+	writeFiles(t, dir, map[string]string{
+		"route-manifest.json": `{"routes": {"/": {"template": "t/index.html"}}}`,
+		"t/index.html":        `<p>home</p>`,
+	})
+	deadline := time.Now().Add(2 * time.Second)
+	for set.Active() == "" && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	w = httptest.NewRecorder()
+	set.ServeHTTP(w, httptest.NewRequest("GET", "/_seam/page/", nil))
+	if set.Active() != "v1" || w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "home") {
+		t.Errorf("after build: active %q, %d %s", set.Active(), w.Code, w.Body.String())
+	}
+}
@@ -8,16 +8,20 @@ package seam
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type routeManifest struct {
-	Layouts map[string]layoutEntry `json:"layouts"`
-	Routes  map[string]*routeEntry `json:"routes"`
-	DataID  string                 `json:"data_id"`
-	I18n    *i18nManifest          `json:"i18n"`
+	Layouts     map[string]layoutEntry `json:"layouts"`
+	Routes      map[string]*routeEntry `json:"routes"`
+	DataID      string                 `json:"data_id"`
+	DataScripts []DataScript           `json:"data_scripts"`
+	I18n        *i18nManifest          `json:"i18n"`
 }
 
 type i18nManifest struct {
@@ -30,11 +34,12 @@ type i18nManifest struct {
 }
 
 type layoutEntry struct {
-	Template  string            `json:"template"`
-	Templates map[string]string `json:"templates"`
-	Loaders   json.RawMessage   `json:"loaders"`
-	Parent    string            `json:"parent"`
-	I18nKeys  []string          `json:"i18n_keys"`
+	Template      string            `json:"template"`
+	Templates     map[string]string `json:"templates"`
+	Loaders       json.RawMessage   `json:"loaders"`
+	Parent        string            `json:"parent"`
+	I18nKeys      []string          `json:"i18n_keys"`
+	ErrorTemplate string            `json:"error_template"` // optional error boundary fragment
 }
 
 type routeEntry struct {
@@ -47,6 +52,9 @@ type routeEntry struct {
 	Assets      *PageAssets         `json:"assets"`
 	Projections map[string][]string `json:"projections"`
 	Prerender   *bool               `json:"prerender"`
+	Robots      string              `json:"robots"`
+	Pagination  *Pagination         `json:"pagination"`
+	Annotations *Annotations        `json:"annotations"`
 }
 
 // pickTemplate returns the template path: prefer singular "template",
@@ -148,6 +156,7 @@ func resolveLayoutChain(layoutID, pageTemplate string, layouts map[string]layout
 type layoutResolved struct {
 	template string
 	parent   string
+	path     string // template path relative to the build dir (debug provenance)
 }
 
 // RpcHashMap maps hashed procedure names back to originals.
@@ -185,11 +194,28 @@ type BuildOutput struct {
 	RpcHashMap *RpcHashMap
 	I18nConfig *I18nConfig
 	PublicDir  string // path to public-root directory (empty when absent)
+	Redirects  []RedirectRule
 }
 
 // LoadBuild loads all build artifacts (pages, rpcHashMap, i18n) in one call.
+// SEAM_TEMPLATE_CACHE_MB=N loads templates lazily into an N MiB cache.
 func LoadBuild(dir string) BuildOutput {
-	pages, _ := LoadBuildOutput(dir)
+	start := time.Now()
+	overrides := os.Getenv("SEAM_TEMPLATE_OVERRIDES")
+	var pages []PageDef
+	if mb, err := strconv.Atoi(os.Getenv("SEAM_TEMPLATE_CACHE_MB")); err == nil && mb > 0 {
+		pages, _ = LoadBuildOutputLazy(dir, overrides, NewTemplateCache(int64(mb)<<20))
+	} else {
+		pages, _ = LoadBuildOutputWithOverrides(dir, overrides)
+	}
+	pagesTook := time.Since(start)
+	i18nStart := time.Now()
+	i18nConfig := LoadI18nConfig(dir)
+	if len(pages) > 0 {
+		logf(slog.LevelInfo, "build output loaded: %d pages in %s, i18n in %s\n",
+			len(pages), pagesTook.Round(time.Microsecond), time.Since(i18nStart).Round(time.Microsecond))
+	}
+
 	var pubDir string
 	if explicitDir := os.Getenv("SEAM_PUBLIC_DIR"); explicitDir != "" {
 		if info, err := os.Stat(explicitDir); err == nil && info.IsDir() {
@@ -205,13 +231,40 @@ func LoadBuild(dir string) BuildOutput {
 	return BuildOutput{
 		Pages:      pages,
 		RpcHashMap: LoadRpcHashMap(dir),
-		I18nConfig: LoadI18nConfig(dir),
+		I18nConfig: i18nConfig,
 		PublicDir:  pubDir,
+		Redirects:  LoadRedirects(dir),
 	}
 }
 
 // LoadBuildOutput loads page definitions from seam build output on disk.
 func LoadBuildOutput(dir string) ([]PageDef, error) {
+	return LoadBuildOutputWithOverrides(dir, "")
+}
+
+// LoadBuildOutputWithOverrides loads page definitions like LoadBuildOutput,
+// preferring templates found in the overrides directory (see
+// templateSource). An empty overrides path disables the overlay.
+func LoadBuildOutputWithOverrides(dir, overrides string) ([]PageDef, error) {
+	return loadBuildOutput(dir, overrides, nil)
+}
+
+// LoadBuildOutputLazy loads page definitions without reading route
+// templates: each is read and resolved on first render and kept in cache
+// (NewTemplateCache bounds it in bytes). Layout templates are still
+// loaded up front. Suited to sites with many routes × locales.
+func LoadBuildOutputLazy(dir, overrides string, cache *TemplateCache) ([]PageDef, error) {
+	if cache == nil {
+		cache = NewTemplateCache(0)
+	}
+	return loadBuildOutput(dir, overrides, cache)
+}
+
+func loadBuildOutput(dir, overrides string, cache *TemplateCache) ([]PageDef, error) {
+	src := templateSource{dir: dir, overrides: overrides, partials: newPartialSet(dir, overrides)}
+	if cache != nil {
+		cache.addPartials(src.partials)
+	}
 	manifestPath := filepath.Join(dir, "route-manifest.json")
 	data, err := os.ReadFile(manifestPath)
 	if err != nil {
@@ -228,6 +281,13 @@ func LoadBuildOutput(dir string) ([]PageDef, error) {
 		defaultLocale = manifest.I18n.Default
 	}
 
+	// Read every template up front in parallel; assembly below is in memory
+	files := src.readAll(manifestTemplateReads(&manifest, defaultLocale, cache != nil))
+	read := func(kind, id, locale, relPath string) ([]byte, error) {
+		f := files[templateRead{kind, id, locale, relPath}]
+		return f.data, f.err
+	}
+
 	// Load layout templates (default locale)
 	layouts := make(map[string]layoutResolved)
 	for id, entry := range manifest.Layouts {
@@ -235,11 +295,11 @@ func LoadBuildOutput(dir string) ([]PageDef, error) {
 		if tmplPath == "" {
 			continue
 		}
-		tmplBytes, err := os.ReadFile(filepath.Join(dir, tmplPath))
+		tmplBytes, err := read("layouts", id, "", tmplPath)
 		if err != nil {
 			return nil, fmt.Errorf("read layout template %s: %w", tmplPath, err)
 		}
-		layouts[id] = layoutResolved{template: string(tmplBytes), parent: entry.Parent}
+		layouts[id] = layoutResolved{template: string(tmplBytes), parent: entry.Parent, path: tmplPath}
 	}
 
 	// Load layout templates per locale for locale-specific resolution
@@ -251,7 +311,7 @@ func LoadBuildOutput(dir string) ([]PageDef, error) {
 				continue
 			}
 			for locale, tmplPath := range entry.Templates {
-				tmplBytes, err := os.ReadFile(filepath.Join(dir, tmplPath))
+				tmplBytes, err := read("layouts", id, locale, tmplPath)
 				if err != nil {
 					return nil, fmt.Errorf("read layout locale template %s: %w", tmplPath, err)
 				}
@@ -261,11 +321,25 @@ func LoadBuildOutput(dir string) ([]PageDef, error) {
 				layoutLocaleTemplates[locale][id] = layoutResolved{
 					template: string(tmplBytes),
 					parent:   entry.Parent,
+					path:     tmplPath,
 				}
 			}
 		}
 	}
 
+	// Load error boundary fragments (rendered in place of the outlet)
+	errorFragments := make(map[string]string)
+	for id, entry := range manifest.Layouts {
+		if entry.ErrorTemplate == "" {
+			continue
+		}
+		fragment, err := read("layouts", id+".error", "", entry.ErrorTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("read layout error template %s: %w", entry.ErrorTemplate, err)
+		}
+		errorFragments[id] = string(fragment)
+	}
+
 	var pages []PageDef
 
 	for routePath, entry := range manifest.Routes {
@@ -273,24 +347,42 @@ func LoadBuildOutput(dir string) ([]PageDef, error) {
 		if tmplPath == "" {
 			continue
 		}
-		tmplBytes, err := os.ReadFile(filepath.Join(dir, tmplPath))
-		if err != nil {
-			return nil, fmt.Errorf("read route template %s: %w", tmplPath, err)
+		origin := &lazyTemplate{
+			cache:         cache,
+			src:           src,
+			route:         routePath,
+			layout:        entry.Layout,
+			path:          tmplPath,
+			layouts:       layouts,
+			localeLayouts: layoutLocaleTemplates,
+		}
+		if manifest.I18n != nil {
+			origin.localePaths = entry.Templates
+		}
+		var lazy *lazyTemplate
+		if cache != nil {
+			lazy = origin
 		}
-		pageTemplate := string(tmplBytes)
 
-		// Resolve layout chain
-		template := pageTemplate
-		if entry.Layout != "" {
-			template = resolveLayoutChain(entry.Layout, pageTemplate, layouts)
+		var template string
+		if lazy == nil {
+			tmplBytes, err := read("routes", routePath, "", tmplPath)
+			if err != nil {
+				return nil, fmt.Errorf("read route template %s: %w", tmplPath, err)
+			}
+			// Resolve layout chain
+			template = string(tmplBytes)
+			if entry.Layout != "" {
+				template = resolveLayoutChain(entry.Layout, template, layouts)
+			}
 		}
 
 		// Build locale-specific pre-resolved templates when i18n is active
 		var localeTemplates map[string]string
-		if manifest.I18n != nil && entry.Templates != nil {
+		if lazy == nil && manifest.I18n != nil && entry.Templates != nil {
 			localeTemplates = make(map[string]string)
 			for locale, ltPath := range entry.Templates {
-				ltBytes, err := os.ReadFile(filepath.Join(dir, ltPath))
+				ltBytes, err := read("routes", routePath, locale, ltPath)
 				if err != nil {
 					return nil, fmt.Errorf("read route locale template %s: %w", ltPath, err)
 				}
@@ -364,12 +456,19 @@ func LoadBuildOutput(dir string) ([]PageDef, error) {
 			LocaleTemplates: localeTemplates,
 			Loaders:         allLoaders,
 			DataID:          dataID,
+			DataScripts:     manifest.DataScripts,
 			LayoutChain:     layoutChain,
 			PageLoaderKeys:  pageLoaderKeys,
 			I18nKeys:        i18nKeys,
 			HeadMeta:        entry.HeadMeta,
 			Assets:          entry.Assets,
 			Projections:     entry.Projections,
+			Robots:          entry.Robots,
+			Pagination:      entry.Pagination,
+			Annotations:     entry.Annotations,
+			ErrorBoundaries: buildErrorBoundaries(layoutChain, errorFragments, layouts, layoutLocaleTemplates),
+			lazy:            lazy,
+			origin:          origin,
 		}
 
 		// SSG: mark prerendered pages and resolve static directory
@@ -387,6 +486,28 @@ func LoadBuildOutput(dir string) ([]PageDef, error) {
 	return pages, nil
 }
 
+// buildErrorBoundaries composes each layout's error fragment with its
+// outer layouts, for every layout in the chain (outer to inner) that
+// declares one.
+func buildErrorBoundaries(chain []LayoutChainEntry, fragments map[string]string, layouts map[string]layoutResolved, localeLayouts map[string]map[string]layoutResolved) []ErrorBoundary {
+	var boundaries []ErrorBoundary
+	for _, entry := range chain {
+		fragment, ok := fragments[entry.ID]
+		if !ok {
+			continue
+		}
+		b := ErrorBoundary{LayoutID: entry.ID, Template: resolveLayoutChain(entry.ID, fragment, layouts)}
+		for locale, ll := range localeLayouts {
+			if b.LocaleTemplates == nil {
+				b.LocaleTemplates = make(map[string]string)
+			}
+			b.LocaleTemplates[locale] = resolveLayoutChain(entry.ID, fragment, ll)
+		}
+		boundaries = append(boundaries, b)
+	}
+	return boundaries
+}
+
 // LoadI18nConfig loads i18n configuration and locale messages from build output.
 // Returns nil when i18n is not configured.
 func LoadI18nConfig(dir string) *I18nConfig {
@@ -415,19 +536,20 @@ func LoadI18nConfig(dir string) *I18nConfig {
 
 	if mode == "memory" {
 		i18nDir := filepath.Join(dir, "i18n")
-		for _, locale := range i18n.Locales {
-			localePath := filepath.Join(i18nDir, locale+".json")
-			data, err := os.ReadFile(localePath)
+		loaded := make([]map[string]json.RawMessage, len(i18n.Locales))
+		parallelEach(len(i18n.Locales), func(i int) {
+			loaded[i] = make(map[string]json.RawMessage)
+			data, err := os.ReadFile(filepath.Join(i18nDir, i18n.Locales[i]+".json"))
 			if err != nil {
-				messages[locale] = make(map[string]json.RawMessage)
-				continue
+				return
 			}
 			var routeMessages map[string]json.RawMessage
-			if err := json.Unmarshal(data, &routeMessages); err != nil {
-				messages[locale] = make(map[string]json.RawMessage)
-				continue
+			if err := json.Unmarshal(data, &routeMessages); err == nil {
+				loaded[i] = routeMessages
 			}
-			messages[locale] = routeMessages
+		})
+		for i, locale := range i18n.Locales {
+			messages[locale] = loaded[i]
 		}
 	} else {
 		distDir = dir
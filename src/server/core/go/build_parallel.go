@@ -0,0 +1,97 @@
+/* src/server/core/go/build_parallel.go */
+
+package seam
+
+import "sync"
+
+// buildLoadConcurrency bounds parallel file reads while loading build
+// output (templates, i18n messages).
+const buildLoadConcurrency = 16
+
+// parallelEach calls fn(0..n-1) on at most buildLoadConcurrency goroutines
+// and waits for all calls to return.
+func parallelEach(n int, fn func(i int)) {
+	workers := min(n, buildLoadConcurrency)
+	var wg sync.WaitGroup
+	next := make(chan int)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				fn(i)
+			}
+		}()
+	}
+	for i := range n {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+}
+
+// templateRead identifies one templateSource.read call.
+type templateRead struct {
+	kind, id, locale, path string
+}
+
+type templateFile struct {
+	data []byte
+	err  error
+}
+
+// readAll performs the reads in parallel; each result (or error) is keyed
+// by its request, duplicates read once.
+func (t templateSource) readAll(reqs []templateRead) map[templateRead]templateFile {
+	files := make(map[templateRead]templateFile, len(reqs))
+	var unique []templateRead
+	for _, req := range reqs {
+		if _, ok := files[req]; !ok {
+			files[req] = templateFile{}
+			unique = append(unique, req)
+		}
+	}
+	results := make([]templateFile, len(unique))
+	parallelEach(len(unique), func(i int) {
+		req := unique[i]
+		data, err := t.read(req.kind, req.id, req.locale, req.path)
+		results[i] = templateFile{data: data, err: err}
+	})
+	for i, req := range unique {
+		files[req] = results[i]
+	}
+	return files
+}
+
+// manifestTemplateReads lists the template reads loadBuildOutput makes;
+// lazy loading skips route templates.
+func manifestTemplateReads(m *routeManifest, defaultLocale string, lazy bool) []templateRead {
+	var reqs []templateRead
+	for id, entry := range m.Layouts {
+		if path := pickTemplate(entry.Template, entry.Templates, defaultLocale); path != "" {
+			reqs = append(reqs, templateRead{"layouts", id, "", path})
+		}
+		if m.I18n != nil {
+			for locale, path := range entry.Templates {
+				reqs = append(reqs, templateRead{"layouts", id, locale, path})
+			}
+		}
+		if entry.ErrorTemplate != "" {
+			reqs = append(reqs, templateRead{"layouts", id + ".error", "", entry.ErrorTemplate})
+		}
+	}
+	if lazy {
+		return reqs
+	}
+	for route, entry := range m.Routes {
+		if path := pickTemplate(entry.Template, entry.Templates, defaultLocale); path != "" {
+			reqs = append(reqs, templateRead{"routes", route, "", path})
+			if m.I18n != nil {
+				for locale, ltPath := range entry.Templates {
+					reqs = append(reqs, templateRead{"routes", route, locale, ltPath})
+				}
+			}
+		}
+	}
+	return reqs
+}
@@ -0,0 +1,72 @@
+/* src/server/core/go/build_parallel_test.go */
+
+package seam
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParallelEachBoundsConcurrency(t *testing.T) {
+	var running, peak, calls atomic.Int32
+	parallelEach(100, func(int) {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		running.Add(-1)
+		calls.Add(1)
+	})
+	if calls.Load() != 100 || peak.Load() > buildLoadConcurrency {
+		t.Fatalf("calls=%d peak=%d", calls.Load(), peak.Load())
+	}
+}
+
+func TestLoadBuildOutputManyLocales(t *testing.T) {
+	dir := t.TempDir()
+	locales := []string{"en", "de", "ja"}
+	files := map[string]string{}
+	var routes []string
+	for i := range 50 {
+		var templates []string
+		for _, loc := range locales {
+			path := fmt.Sprintf("t/r%d.%s.html", i, loc)
+			files[path] = fmt.Sprintf("<p>%d-%s</p>", i, loc)
+			templates = append(templates, fmt.Sprintf("%q: %q", loc, path))
+		}
+		routes = append(routes, fmt.Sprintf(`"/r%d": {"templates": {%s}, "layout": "root"}`, i, strings.Join(templates, ",")))
+	}
+	files["t/root.html"] = "<main><!--seam:outlet--></main>"
+	files["route-manifest.json"] = fmt.Sprintf(`{
+		"layouts": {"root": {"template": "t/root.html"}},
+		"routes": {%s},
+		"i18n": {"locales": ["en", "de", "ja"], "default": "en"}
+	}`, strings.Join(routes, ","))
+	writeFiles(t, dir, files)
+
+	pages, err := LoadBuildOutput(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 50 {
+		t.Fatalf("expected 50 pages, got %d", len(pages))
+	}
+	for _, p := range pages {
+		n := strings.TrimPrefix(p.Route, "/r")
+		if p.Template != "<main><p>"+n+"-en</p></main>" || p.LocaleTemplates["ja"] != "<main><p>"+n+"-ja</p></main>" {
+			t.Fatalf("page %s: %q %v", p.Route, p.Template, p.LocaleTemplates)
+		}
+	}
+
+	writeFiles(t, dir, map[string]string{"route-manifest.json": `{"routes": {"/x": {"template": "t/missing.html"}}}`})
+	if _, err := LoadBuildOutput(dir); err == nil || !strings.Contains(err.Error(), "t/missing.html") {
+		t.Fatalf("missing template must fail the load, got %v", err)
+	}
+}
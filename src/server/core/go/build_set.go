@@ -0,0 +1,265 @@
+/* src/server/core/go/build_set.go */
+
+package seam
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"syscall"
+)
+
+// BuildSet holds several build outputs (e.g. blue/green frontend deploys)
+// and serves exactly one of them. Each loaded version gets its own fully
+// built handler, so pages, i18n, the RPC hash map, and public assets are
+// swapped together by a single atomic pointer store. Requests already in
+// flight (including open SSE/WS connections) finish on the version they
+// started on. Unloaded or replaced versions are stopped once their last
+// in-flight request finishes.
+type BuildSet struct {
+	router *Router
+	opts   HandlerOptions
+
+	mu       sync.Mutex
+	handlers map[string]*loadedBuild
+	active   atomic.Pointer[activeBuild]
+}
+
+type activeBuild struct {
+	version string
+	build   *loadedBuild
+}
+
+// loadedBuild counts the requests in flight on one version's handler, so
+// a retired version is stopped only after they drain.
+type loadedBuild struct {
+	handler http.Handler
+	mu      sync.Mutex
+	active  int
+	retired bool
+}
+
+func (l *loadedBuild) serve(w http.ResponseWriter, r *http.Request) {
+	l.mu.Lock()
+	l.active++
+	l.mu.Unlock()
+	defer l.release()
+	l.handler.ServeHTTP(w, r)
+}
+
+func (l *loadedBuild) release() {
+	l.mu.Lock()
+	l.active--
+	drained := l.retired && l.active == 0
+	l.mu.Unlock()
+	if drained {
+		l.stop()
+	}
+}
+
+// retire marks the build unloaded, stopping it now when idle.
+func (l *loadedBuild) retire() {
+	l.mu.Lock()
+	l.retired = true
+	drained := l.active == 0
+	l.mu.Unlock()
+	if drained {
+		l.stop()
+	}
+}
+
+func (l *loadedBuild) stop() {
+	if h, ok := l.handler.(*builtHandler); ok {
+		h.stop()
+	}
+}
+
+// BuildSet creates an empty build set sharing the router's procedures,
+// hub, and the given handler options. Procedures are snapshotted when a
+// version is loaded, so register everything (including SwitchProcedure)
+// before calling Load.
+func (r *Router) BuildSet(opts ...HandlerOptions) *BuildSet {
+	return &BuildSet{
+		router:   r,
+		opts:     r.handlerOptions(opts),
+		handlers: make(map[string]*loadedBuild),
+	}
+}
+
+// Load builds a handler for the given build output under version. Router
+// pages registered via Page are served alongside the version's pages. The
+// first loaded version becomes active. Loading an existing version
+// replaces it (and swaps it in immediately if active).
+func (b *BuildSet) Load(version string, out BuildOutput) {
+	r := b.router
+	pages := append(append([]PageDef{}, r.pages...), out.Pages...)
+	hashMap, i18n, publicDir := r.rpcHashMap, r.i18nConfig, r.publicDir
+	if out.RpcHashMap != nil {
+		hashMap = out.RpcHashMap
+	}
+	if out.I18nConfig != nil {
+		i18n = out.I18nConfig
+	}
+	if out.PublicDir != "" {
+		publicDir = out.PublicDir
+	}
+	redirects := append(append([]RedirectRule{}, r.redirects...), out.Redirects...)
+	build := &loadedBuild{handler: r.buildWith(pages, hashMap, i18n, publicDir, redirects, b.opts)}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	replaced := b.handlers[version]
+	b.handlers[version] = build
+	if cur := b.active.Load(); cur == nil || cur.version == version {
+		b.active.Store(&activeBuild{version: version, build: build})
+	}
+	if replaced != nil {
+		replaced.retire()
+	}
+}
+
+// LoadDir loads the build output directory dir under version.
+func (b *BuildSet) LoadDir(version, dir string) error {
+	if _, err := LoadBuildOutput(dir); err != nil {
+		return fmt.Errorf("load build %s: %w", version, err)
+	}
+	b.Load(version, LoadBuild(dir))
+	return nil
+}
+
+// Activate switches the served version atomically.
+func (b *BuildSet) Activate(version string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	build, ok := b.handlers[version]
+	if !ok {
+		return fmt.Errorf("build version %q is not loaded", version)
+	}
+	b.active.Store(&activeBuild{version: version, build: build})
+	logf(slog.LevelInfo, "active build switched to %s\n", version)
+	return nil
+}
+
+// Unload drops a version that is not currently active. Its handler is
+// stopped once the requests still in flight on it finish.
+func (b *BuildSet) Unload(version string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if cur := b.active.Load(); cur != nil && cur.version == version {
+		return fmt.Errorf("build version %q is active", version)
+	}
+	if build, ok := b.handlers[version]; ok {
+		delete(b.handlers, version)
+		build.retire()
+	}
+	return nil
+}
+
+// Active returns the currently served version ("" before the first Load).
+func (b *BuildSet) Active() string {
+	if cur := b.active.Load(); cur != nil {
+		return cur.version
+	}
+	return ""
+}
+
+// Versions returns the loaded versions, sorted.
+func (b *BuildSet) Versions() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	versions := make([]string, 0, len(b.handlers))
+	for v := range b.handlers {
+		versions = append(versions, v)
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// next returns the version after the active one in sorted order, wrapping
+// around; with two loaded versions this flips blue/green.
+func (b *BuildSet) next() string {
+	versions := b.Versions()
+	if len(versions) == 0 {
+		return ""
+	}
+	active := b.Active()
+	for i, v := range versions {
+		if v == active {
+			return versions[(i+1)%len(versions)]
+		}
+	}
+	return versions[0]
+}
+
+func (b *BuildSet) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	cur := b.active.Load()
+	if cur == nil {
+		b.serveUnloaded(w, r)
+		return
+	}
+	cur.build.serve(w, r)
+}
+
+type buildSwitchInput struct {
+	Version string `json:"version"`
+}
+
+type buildSwitchOutput struct {
+	Active   string `json:"active"`
+	Previous string `json:"previous"`
+}
+
+// SwitchProcedure returns an admin command that activates the version
+// given as input ({"version": "..."}). Guard it with IPFilters or a
+// context check; it is registered like any other procedure.
+func (b *BuildSet) SwitchProcedure(name string, opts ...ProcedureOption) *ProcedureDef {
+	return Command(name, func(_ context.Context, in buildSwitchInput) (buildSwitchOutput, error) {
+		previous := b.Active()
+		if err := b.Activate(in.Version); err != nil {
+			return buildSwitchOutput{}, NotFoundError(err.Error())
+		}
+		return buildSwitchOutput{Active: in.Version, Previous: previous}, nil
+	}, opts...)
+}
+
+// SwitchOnSignal switches versions whenever one of sigs (default SIGHUP)
+// is received, until ctx is done. pick chooses the version to activate
+// and may load it first; nil flips to the next loaded version.
+func (b *BuildSet) SwitchOnSignal(ctx context.Context, pick func() (string, error), sigs ...os.Signal) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGHUP}
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+	go func() {
+		defer signal.Stop(ch)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ch:
+				b.switchTo(pick)
+			}
+		}
+	}()
+}
+
+func (b *BuildSet) switchTo(pick func() (string, error)) {
+	version := b.next()
+	if pick != nil {
+		var err error
+		if version, err = pick(); err != nil {
+			logf(slog.LevelError, "build switch failed: %v\n", err)
+			return
+		}
+	}
+	if err := b.Activate(version); err != nil {
+		logf(slog.LevelError, "build switch failed: %v\n", err)
+	}
+}
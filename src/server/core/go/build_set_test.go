@@ -0,0 +1,142 @@
+/* src/server/core/go/build_set_test.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func buildSetTestRouter() *Router {
+	greet := func(_ context.Context, _ json.RawMessage) (any, error) { return "hi", nil }
+	return NewRouter().Procedure(&ProcedureDef{Name: "greet", Handler: greet})
+}
+
+func postStatus(h http.Handler, name, body string) int {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/_seam/procedure/"+name, strings.NewReader(body)))
+	return w.Code
+}
+
+func TestBuildSetSwitchesHashMapAtomically(t *testing.T) {
+	set := buildSetTestRouter().BuildSet()
+	if code := postStatus(set, "greet", `{}`); code != http.StatusServiceUnavailable {
+		t.Fatalf("empty set: status %d, want 503", code)
+	}
+
+	set.Load("blue", BuildOutput{RpcHashMap: &RpcHashMap{Batch: "b1", Procedures: map[string]string{"greet": "aaaa"}}})
+	set.Load("green", BuildOutput{RpcHashMap: &RpcHashMap{Batch: "b2", Procedures: map[string]string{"greet": "bbbb"}}})
+	if set.Active() != "blue" {
+		t.Fatalf("first loaded version should be active, got %q", set.Active())
+	}
+	if code := postStatus(set, "aaaa", `{}`); code != http.StatusOK {
+		t.Errorf("blue hash: status %d, want 200", code)
+	}
+	if code := postStatus(set, "bbbb", `{}`); code == http.StatusOK {
+		t.Errorf("green hash served while blue is active")
+	}
+
+	if err := set.Activate("green"); err != nil {
+		t.Fatal(err)
+	}
+	if code := postStatus(set, "bbbb", `{}`); code != http.StatusOK {
+		t.Errorf("green hash: status %d, want 200", code)
+	}
+	if code := postStatus(set, "aaaa", `{}`); code == http.StatusOK {
+		t.Errorf("blue hash served after switch")
+	}
+
+	if err := set.Activate("missing"); err == nil {
+		t.Error("expected error activating unknown version")
+	}
+	if err := set.Unload("green"); err == nil {
+		t.Error("expected error unloading the active version")
+	}
+	if err := set.Unload("blue"); err != nil {
+		t.Fatal(err)
+	}
+	if got := set.Versions(); len(got) != 1 || got[0] != "green" {
+		t.Errorf("versions = %v", got)
+	}
+}
+
+func TestBuildSetSwitchProcedureAndToggle(t *testing.T) {
+	router := buildSetTestRouter()
+	set := router.BuildSet()
+	router.Procedure(set.SwitchProcedure("admin.switchBuild"))
+	set.Load("blue", BuildOutput{})
+	set.Load("green", BuildOutput{})
+
+	w := httptest.NewRecorder()
+	set.ServeHTTP(w, httptest.NewRequest("POST", "/_seam/procedure/admin.switchBuild", strings.NewReader(`{"version":"green"}`)))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"active":"green","previous":"blue"`) {
+		t.Fatalf("switch: %d %s", w.Code, w.Body.String())
+	}
+	if code := postStatus(set, "admin.switchBuild", `{"version":"red"}`); code != http.StatusNotFound {
+		t.Errorf("unknown version: status %d, want 404", code)
+	}
+
+	set.switchTo(nil)
+	if set.Active() != "blue" {
+		t.Errorf("toggle from green: active %q, want blue", set.Active())
+	}
+}
+
+func TestBuildSetUnloadStopsDrainedHandler(t *testing.T) {
+	entered, release := make(chan struct{}), make(chan struct{})
+	router := NewRouter().
+		Procedure(Query("getCached", func(context.Context, struct{}) (string, error) { return "", nil }, WithCache(true))).
+		Procedure(&ProcedureDef{Name: "slow", Handler: func(context.Context, json.RawMessage) (any, error) {
+			close(entered)
+			<-release
+			return "done", nil
+		}})
+	set := router.BuildSet()
+	subscribers := func() int { return router.Hub().Subscribers(invalidationTopic) }
+	waitFor := func(want int) {
+		for deadline := time.Now().Add(2 * time.Second); subscribers() != want && time.Now().Before(deadline); {
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	set.Load("blue", BuildOutput{})
+	set.Load("green", BuildOutput{})
+	if n := subscribers(); n != 2 {
+		t.Fatalf("expected one ETag watcher per version, got %d", n)
+	}
+
+	inFlight := make(chan int)
+	go func() { inFlight <- postStatus(set, "slow", `{}`) }()
+	<-entered
+	if err := set.Activate("green"); err != nil {
+		t.Fatal(err)
+	}
+	if err := set.Unload("blue"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if n := subscribers(); n != 2 {
+		t.Fatalf("blue stopped with a request in flight: %d subscribers", n)
+	}
+
+	close(release)
+	if code := <-inFlight; code != http.StatusOK {
+		t.Fatalf("in-flight request: status %d", code)
+	}
+	waitFor(1)
+	if n := subscribers(); n != 1 {
+		t.Fatalf("blue not stopped after draining: %d subscribers", n)
+	}
+
+	// Replacing a loaded version stops the old handler right away
+	set.Load("green", BuildOutput{})
+	waitFor(1)
+	if n := subscribers(); n != 1 {
+		t.Fatalf("replaced green not stopped: %d subscribers", n)
+	}
+}
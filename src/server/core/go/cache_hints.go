@@ -0,0 +1,71 @@
+/* src/server/core/go/cache_hints.go */
+
+package seam
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// CacheHints declares how a query's results may be cached, by the server
+// (Cache-Control and Vary on RPC responses, plus an ETag on GET calls),
+// intermediate caches, and generated clients, which read the same values
+// from the manifest's "cache" entry.
+type CacheHints struct {
+	MaxAge               int      // seconds a result stays fresh (0 = revalidate every time)
+	StaleWhileRevalidate int      // seconds a stale result may be served while refetching
+	VaryOn               []string // request headers the result depends on (e.g. "Accept-Language")
+}
+
+// WithCacheHints marks a query cacheable with the given hints.
+func WithCacheHints(hints CacheHints) ProcedureOption {
+	return func(p *ProcedureDef) {
+		p.Cache = hints
+	}
+}
+
+// MarshalJSON emits the manifest entry; "ttl" repeats maxAge for clients
+// that only read the {"ttl": N} form.
+func (h CacheHints) MarshalJSON() ([]byte, error) {
+	type entry struct {
+		TTL                  int      `json:"ttl"`
+		MaxAge               int      `json:"maxAge"`
+		StaleWhileRevalidate int      `json:"staleWhileRevalidate,omitempty"`
+		VaryOn               []string `json:"varyOn,omitempty"`
+	}
+	return json.Marshal(entry{h.MaxAge, h.MaxAge, h.StaleWhileRevalidate, h.VaryOn})
+}
+
+// cacheHints returns the hints of a cacheable query; the legacy
+// true | {"ttl": N} forms map to MaxAge.
+func cacheHints(proc *ProcedureDef) (CacheHints, bool) {
+	switch c := proc.Cache.(type) {
+	case CacheHints:
+		return c, proc.Type != "command"
+	case *CacheHints:
+		if c != nil && proc.Type != "command" {
+			return *c, true
+		}
+		return CacheHints{}, false
+	}
+	ttl, ok := cacheTTL(proc)
+	return CacheHints{MaxAge: ttl}, ok
+}
+
+// setHeaders writes Cache-Control and Vary for a cacheable response.
+func (h CacheHints) setHeaders(header http.Header) {
+	if h.MaxAge > 0 {
+		cc := fmt.Sprintf("private, max-age=%d", h.MaxAge)
+		if h.StaleWhileRevalidate > 0 {
+			cc += fmt.Sprintf(", stale-while-revalidate=%d", h.StaleWhileRevalidate)
+		}
+		header.Set("Cache-Control", cc)
+	} else {
+		header.Set("Cache-Control", "private, no-cache")
+	}
+	if len(h.VaryOn) > 0 {
+		header.Add("Vary", strings.Join(h.VaryOn, ", "))
+	}
+}
@@ -0,0 +1,63 @@
+/* src/server/core/go/cache_hints_test.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCacheHintsManifest(t *testing.T) {
+	get := func(context.Context, struct{}) (string, error) { return "ok", nil }
+	r := NewRouter().
+		Procedure(Query("getFeed", get, WithCacheHints(CacheHints{MaxAge: 30, StaleWhileRevalidate: 300, VaryOn: []string{"Accept-Language"}}))).
+		Procedure(Query("getLegacy", get, WithCache(map[string]any{"ttl": 60})))
+	raw, err := r.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m struct {
+		Procedures map[string]struct {
+			Cache json.RawMessage `json:"cache"`
+		} `json:"procedures"`
+	}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(m.Procedures["getFeed"].Cache), `{"ttl":30,"maxAge":30,"staleWhileRevalidate":300,"varyOn":["Accept-Language"]}`; got != want {
+		t.Errorf("getFeed cache\n got %s\nwant %s", got, want)
+	}
+	if got := string(m.Procedures["getLegacy"].Cache); got != `{"ttl":60}` {
+		t.Errorf("getLegacy cache %s", got)
+	}
+}
+
+func TestCacheHintsResponseHeaders(t *testing.T) {
+	get := func(context.Context, struct{}) (string, error) { return "ok", nil }
+	h := NewRouter().
+		Procedure(Query("getFeed", get, WithCacheHints(CacheHints{MaxAge: 30, StaleWhileRevalidate: 300, VaryOn: []string{"Accept-Language", "X-Team"}}))).
+		Procedure(Query("getLive", get, WithCacheHints(CacheHints{}))).
+		Procedure(Command("save", get, WithCacheHints(CacheHints{MaxAge: 30}))).
+		Handler()
+	cases := []struct {
+		proc, cacheControl, vary string
+	}{
+		{"getFeed", "private, max-age=30, stale-while-revalidate=300", "Accept-Language, X-Team"},
+		{"getLive", "private, no-cache", ""},
+		{"save", "", ""},
+	}
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/_seam/procedure/"+c.proc, strings.NewReader(`{}`)))
+		if got := w.Header().Get("Cache-Control"); got != c.cacheControl {
+			t.Errorf("%s: Cache-Control %q, want %q", c.proc, got, c.cacheControl)
+		}
+		if got := w.Header().Get("Vary"); got != c.vary {
+			t.Errorf("%s: Vary %q, want %q", c.proc, got, c.vary)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+/* src/server/core/go/canary.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"reflect"
+	"sort"
+	"sync/atomic"
+)
+
+// CanaryHeader names the variant ("stable" or "canary") that served an
+// RPC call of a procedure registered with Router.Canary. Batch results
+// and RPC socket responses carry it in meta.canary.
+const CanaryHeader = "X-Seam-Canary"
+
+type canaryVariantKey struct{}
+
+// withCanarySlot returns ctx with a slot that the canary split of the
+// call run under it fills with the variant it chose.
+func withCanarySlot(ctx context.Context) (context.Context, *string) {
+	slot := new(string)
+	return context.WithValue(ctx, canaryVariantKey{}, slot), slot
+}
+
+// CanaryStats counts the calls of one canary variant.
+type CanaryStats struct {
+	Procedure string  `json:"procedure"`
+	Variant   string  `json:"variant"` // "stable" or "canary"
+	Calls     int64   `json:"calls"`
+	Errors    int64   `json:"errors"`
+	ErrorRate float64 `json:"errorRate"` // Errors / Calls, 0 without calls
+}
+
+// canarySplit routes calls of one procedure between two handlers.
+type canarySplit struct {
+	name    string
+	percent int
+	stable  canaryVariant
+	canary  canaryVariant
+}
+
+type canaryVariant struct {
+	handler HandlerFunc
+	calls   atomic.Int64
+	errors  atomic.Int64
+}
+
+// Canary registers name with two implementations: percent of callers
+// (0-100) get canaryDef's handler, the rest stableDef's. The split is
+// sticky: callers are bucketed by a hash of their principal, or of their
+// client IP when anonymous, so a user keeps seeing the same variant while
+// the percentage stays put. Schemas and options come from stableDef; the
+// kinds, input, output, and error schemas, and context keys must match.
+// RPC responses carry the variant in CanaryHeader, and
+// per-variant calls and errors are reported by CanaryStats and
+// CanaryMetrics.
+func (r *Router) Canary(name string, stableDef, canaryDef *ProcedureDef, percent int) *Router {
+	if percent < 0 || percent > 100 {
+		panic(fmt.Sprintf("canary %q: percent %d is not in 0..100", name, percent))
+	}
+	if stableDef.Type != canaryDef.Type {
+		panic(fmt.Sprintf("canary %q: stable is a %s but canary is a %s", name, procedureKind(stableDef), procedureKind(canaryDef)))
+	}
+	for field, pair := range map[string][2]any{
+		"input schema":  {stableDef.InputSchema, canaryDef.InputSchema},
+		"output schema": {stableDef.OutputSchema, canaryDef.OutputSchema},
+		"error schema":  {stableDef.ErrorSchema, canaryDef.ErrorSchema},
+		"context keys":  {stableDef.ContextKeys, canaryDef.ContextKeys},
+	} {
+		if !reflect.DeepEqual(pair[0], pair[1]) {
+			panic(fmt.Sprintf("canary %q: stable and canary have different %s", name, field))
+		}
+	}
+	stable, canary := sandboxed(*stableDef), sandboxed(*canaryDef)
+	split := &canarySplit{name: name, percent: percent}
+	split.stable.handler = stable.Handler
+	split.canary.handler = canary.Handler
+	def := stable
+	def.Name = name
+	def.Handler = split.call
+	def.canary = split
+	r.procedures = append(r.procedures, def)
+	return r
+}
+
+func procedureKind(def *ProcedureDef) string {
+	if def.Type == "" {
+		return "query"
+	}
+	return def.Type
+}
+
+// variant returns "canary" when the caller of ctx falls in the canary
+// bucket. Callers without a principal or client IP stay on stable.
+func (c *canarySplit) variant(ctx context.Context) string {
+	key := PrincipalOf(ctx)
+	if key == "" {
+		if ip := ClientIP(ctx); ip != "" {
+			key = "ip:" + ip
+		}
+	}
+	if key == "" || c.percent == 0 {
+		return "stable"
+	}
+	h := fnv.New32a()
+	h.Write([]byte(c.name + "\x00" + key))
+	if int(h.Sum32()%100) < c.percent {
+		return "canary"
+	}
+	return "stable"
+}
+
+func (c *canarySplit) call(ctx context.Context, input json.RawMessage) (any, error) {
+	name := c.variant(ctx)
+	if slot, ok := ctx.Value(canaryVariantKey{}).(*string); ok {
+		*slot = name
+	}
+	v := &c.stable
+	if name == "canary" {
+		v = &c.canary
+	}
+	v.calls.Add(1)
+	result, err := v.handler(ctx, input)
+	if err != nil {
+		v.errors.Add(1)
+	}
+	return result, err
+}
+
+func (c *canarySplit) stats() []CanaryStats {
+	stats := make([]CanaryStats, 0, 2)
+	for _, v := range []struct {
+		name    string
+		variant *canaryVariant
+	}{{"stable", &c.stable}, {"canary", &c.canary}} {
+		st := CanaryStats{Procedure: c.name, Variant: v.name, Calls: v.variant.calls.Load(), Errors: v.variant.errors.Load()}
+		if st.Calls > 0 {
+			st.ErrorRate = float64(st.Errors) / float64(st.Calls)
+		}
+		stats = append(stats, st)
+	}
+	return stats
+}
+
+// CanaryStats returns the per-variant counts of every canary procedure,
+// sorted by procedure, stable first. Handlers built from the router (and
+// its clones) share the counts.
+func (r *Router) CanaryStats() []CanaryStats {
+	var stats []CanaryStats
+	for i := range r.procedures {
+		if c := r.procedures[i].canary; c != nil {
+			stats = append(stats, c.stats()...)
+		}
+	}
+	sort.SliceStable(stats, func(i, j int) bool { return stats[i].Procedure < stats[j].Procedure })
+	return stats
+}
+
+// CanaryMetrics serves CanaryStats in the Prometheus text exposition
+// format.
+func (r *Router) CanaryMetrics() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		stats := r.CanaryStats()
+		fmt.Fprintln(w, "# TYPE seam_canary_calls_total counter")
+		for _, s := range stats {
+			fmt.Fprintf(w, "seam_canary_calls_total{procedure=%q,variant=%q} %d\n", s.Procedure, s.Variant, s.Calls)
+		}
+		fmt.Fprintln(w, "# TYPE seam_canary_errors_total counter")
+		for _, s := range stats {
+			fmt.Fprintf(w, "seam_canary_errors_total{procedure=%q,variant=%q} %d\n", s.Procedure, s.Variant, s.Errors)
+		}
+		fmt.Fprintln(w, "# TYPE seam_canary_error_ratio gauge")
+		for _, s := range stats {
+			fmt.Fprintf(w, "seam_canary_error_ratio{procedure=%q,variant=%q} %g\n", s.Procedure, s.Variant, s.ErrorRate)
+		}
+	})
+}
@@ -0,0 +1,114 @@
+/* src/server/core/go/canary_test.go */
+
+package seam
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestCanarySplitsStickyByPrincipal(t *testing.T) {
+	stable := Query("getUser", func(context.Context, struct{}) (string, error) { return "v1", nil })
+	canary := Query("getUserV2", func(context.Context, struct{}) (string, error) { return "", errors.New("boom") })
+	router := NewRouter().Canary("getUser", stable, canary, 30)
+	h := router.Handler(HandlerOptions{Principal: func(r *http.Request) string { return r.Header.Get("X-User") }})
+
+	seen := map[string]int{}
+	for i := range 100 {
+		user := fmt.Sprintf("user-%d", i)
+		var variant string
+		for range 2 {
+			req := httptest.NewRequest("POST", "/_seam/procedure/getUser", strings.NewReader("{}"))
+			req.Header.Set("X-User", user)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			got := w.Header().Get(CanaryHeader)
+			if variant != "" && got != variant {
+				t.Fatalf("%s switched from %s to %s", user, variant, got)
+			}
+			variant = got
+			if ok := w.Code == http.StatusOK; ok != (got == "stable") {
+				t.Fatalf("%s on %s: %d %s", user, got, w.Code, w.Body.String())
+			}
+		}
+		seen[variant]++
+	}
+	if seen["canary"] < 15 || seen["canary"] > 45 || seen["stable"]+seen["canary"] != 100 {
+		t.Errorf("split %v", seen)
+	}
+
+	stats := router.CanaryStats()
+	if len(stats) != 2 || stats[0].Variant != "stable" || stats[0].Errors != 0 ||
+		stats[1].Variant != "canary" || stats[1].Calls != int64(2*seen["canary"]) || stats[1].ErrorRate != 1 {
+		t.Fatalf("stats %+v", stats)
+	}
+	w := httptest.NewRecorder()
+	router.CanaryMetrics().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if !strings.Contains(w.Body.String(), `seam_canary_error_ratio{procedure="getUser",variant="canary"} 1`) {
+		t.Errorf("metrics:\n%s", w.Body.String())
+	}
+}
+
+func TestCanaryRejectsBadConfig(t *testing.T) {
+	q := Query("a", func(context.Context, struct{}) (string, error) { return "", nil })
+	c := Command("b", func(context.Context, struct{}) (string, error) { return "", nil })
+	for name, fn := range map[string]func(){
+		"percent": func() { NewRouter().Canary("a", q, q, 101) },
+		"kind":    func() { NewRouter().Canary("a", q, c, 10) },
+		"schema": func() {
+			NewRouter().Canary("a", q, Query("a2", func(context.Context, struct{ ID string }) (string, error) { return "", nil }), 10)
+		},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: no panic", name)
+				}
+			}()
+			fn()
+		}()
+	}
+}
+
+func TestCanaryTagsBatchAndSocketCalls(t *testing.T) {
+	stable := Query("getUser", func(context.Context, struct{}) (string, error) { return "v1", nil })
+	canary := Query("getUserV2", func(context.Context, struct{}) (string, error) { return "v2", nil })
+	h := NewRouter().Canary("getUser", stable, canary, 100).
+		RpcHashMap(&RpcHashMap{Batch: "_batch", Procedures: map[string]string{"getUser": "getUser"}}).
+		Handler(HandlerOptions{WebSocketRPC: true, Principal: func(r *http.Request) string { return "alice" }})
+
+	_, body := rpcBody(h, "/_seam/procedure/_batch", `{"calls":[{"procedure":"getUser","input":{}}]}`, nil)
+	if !strings.Contains(body, `"data":"v2"`) || !strings.Contains(body, `"meta":{"canary":"canary"}`) {
+		t.Fatalf("batch %s", body)
+	}
+
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+wsRPCPath, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := conn.WriteJSON(map[string]any{"id": "1", "procedure": "getUser", "input": map[string]any{}}); err != nil {
+		t.Fatal(err)
+	}
+	var resp struct {
+		Data string `json:"data"`
+		Meta struct {
+			Canary string `json:"canary"`
+		} `json:"meta"`
+	}
+	if err := conn.ReadJSON(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Data != "v2" || resp.Meta.Canary != "canary" {
+		t.Fatalf("socket %+v", resp)
+	}
+}
@@ -0,0 +1,198 @@
+/* src/server/core/go/canonical.go */
+
+package seam
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CanonicalJSON serializes v with sorted object keys, two-space indentation,
+// no HTML escaping, and ES6-style number formatting for non-integers.
+// Integer literals are kept exactly as written, whatever their size. The
+// output is stable across runs, so manifests can be diffed byte-for-byte.
+func CanonicalJSON(v any) ([]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var tree any
+	if err := dec.Decode(&tree); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := writeCanonical(&buf, tree, 0); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+func writeCanonical(buf *bytes.Buffer, v any, depth int) error {
+	switch val := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(val))
+	case json.Number:
+		s, err := canonicalNumber(val)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(s)
+	case string:
+		writeCanonicalString(buf, val)
+	case []any:
+		if len(val) == 0 {
+			buf.WriteString("[]")
+			return nil
+		}
+		buf.WriteString("[\n")
+		for i, item := range val {
+			writeIndent(buf, depth+1)
+			if err := writeCanonical(buf, item, depth+1); err != nil {
+				return err
+			}
+			if i < len(val)-1 {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('\n')
+		}
+		writeIndent(buf, depth)
+		buf.WriteByte(']')
+	case map[string]any:
+		if len(val) == 0 {
+			buf.WriteString("{}")
+			return nil
+		}
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		buf.WriteString("{\n")
+		for i, k := range keys {
+			writeIndent(buf, depth+1)
+			writeCanonicalString(buf, k)
+			buf.WriteString(": ")
+			if err := writeCanonical(buf, val[k], depth+1); err != nil {
+				return err
+			}
+			if i < len(keys)-1 {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('\n')
+		}
+		writeIndent(buf, depth)
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("canonical json: unexpected type %T", v)
+	}
+	return nil
+}
+
+// canonicalNumber keeps integer literals unchanged, including those beyond
+// int64 that float64 would round, and formats everything else the way
+// JSON.stringify does (encoding/json already follows ES6 for float64).
+func canonicalNumber(n json.Number) (string, error) {
+	if digits := strings.TrimPrefix(n.String(), "-"); digits != "" && strings.Trim(digits, "0123456789") == "" {
+		return n.String(), nil
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return "", fmt.Errorf("canonical json: invalid number %q", n.String())
+	}
+	b, err := json.Marshal(f)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func writeCanonicalString(buf *bytes.Buffer, s string) {
+	var sb bytes.Buffer
+	enc := json.NewEncoder(&sb)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	buf.Write(bytes.TrimSuffix(sb.Bytes(), []byte("\n")))
+}
+
+func writeIndent(buf *bytes.Buffer, depth int) {
+	for i := 0; i < depth; i++ {
+		buf.WriteString("  ")
+	}
+}
+
+// CanonicalManifest returns the manifest in canonical form (see CanonicalJSON).
+func (r *Router) CanonicalManifest() ([]byte, error) {
+	raw, err := r.Manifest()
+	if err != nil {
+		return nil, err
+	}
+	return CanonicalJSON(json.RawMessage(raw))
+}
+
+// CompareGolden compares got against the golden file at path.
+// When SEAM_UPDATE_GOLDEN=1 or the file does not exist yet, the golden file
+// is (re)written instead. On mismatch the error lists the differing lines.
+func CompareGolden(path string, got []byte) error {
+	return compareGolden(path, got, lineDiff)
+}
+
+func compareGolden(path string, got []byte, diff func(want, got string) string) error {
+	want, err := os.ReadFile(path)
+	if os.Getenv("SEAM_UPDATE_GOLDEN") == "1" || os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(path, got, 0o644)
+	}
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(want, got) {
+		return nil
+	}
+	return fmt.Errorf("golden mismatch for %s (set SEAM_UPDATE_GOLDEN=1 to update):\n%s", path, diff(string(want), string(got)))
+}
+
+// lineDiff renders differing lines as "-want"/"+got" pairs, capped to keep
+// failure output readable.
+func lineDiff(want, got string) string {
+	const maxLines = 20
+	wl := strings.Split(want, "\n")
+	gl := strings.Split(got, "\n")
+	n := len(wl)
+	if len(gl) > n {
+		n = len(gl)
+	}
+	var sb strings.Builder
+	shown := 0
+	for i := 0; i < n; i++ {
+		var w, g string
+		if i < len(wl) {
+			w = wl[i]
+		}
+		if i < len(gl) {
+			g = gl[i]
+		}
+		if w == g {
+			continue
+		}
+		if shown == maxLines {
+			sb.WriteString("...\n")
+			break
+		}
+		fmt.Fprintf(&sb, "line %d:\n-%s\n+%s\n", i+1, w, g)
+		shown++
+	}
+	return sb.String()
+}
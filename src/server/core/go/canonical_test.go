@@ -0,0 +1,97 @@
+/* src/server/core/go/canonical_test.go */
+
+package seam
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCanonicalJSONOrderingAndNumbers(t *testing.T) {
+	in := map[string]any{
+		"z":    1.0,
+		"a":    []any{json.Number("2.50"), 1e21, int64(9007199254740993)},
+		"html": "<b>&</b>",
+		"m":    map[string]any{},
+	}
+	got, err := CanonicalJSON(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "a": [
+    2.5,
+    1e+21,
+    9007199254740993
+  ],
+  "html": "<b>&</b>",
+  "m": {},
+  "z": 1
+}
+`
+	if string(got) != want {
+		t.Fatalf("unexpected canonical output:\n%s", got)
+	}
+}
+
+func TestCanonicalJSONKeepsLargeIntegers(t *testing.T) {
+	a, err := CanonicalJSON(json.RawMessage(`{"id":123456789012345678901,"neg":-98765432109876543210}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := CanonicalJSON(json.RawMessage(`{"id":123456789012345678902,"neg":-98765432109876543210}`))
+	if !strings.Contains(string(a), `"id": 123456789012345678901`) || !strings.Contains(string(a), `"neg": -98765432109876543210`) {
+		t.Fatalf("large integers rewritten:\n%s", a)
+	}
+	if string(a) == string(b) {
+		t.Fatal("distinct integers produced the same canonical form")
+	}
+}
+
+func TestCanonicalJSONStableAcrossRuns(t *testing.T) {
+	r := NewRouter().
+		Procedure(&ProcedureDef{Name: "b", InputSchema: map[string]any{"properties": map[string]any{"y": map[string]any{"type": "string"}, "x": map[string]any{"type": "int32"}}}}).
+		Procedure(&ProcedureDef{Name: "a", InputSchema: map[string]any{}})
+	first, err := r.CanonicalManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		again, _ := r.CanonicalManifest()
+		if string(again) != string(first) {
+			t.Fatal("canonical manifest is not stable")
+		}
+	}
+	if strings.Index(string(first), `"a"`) > strings.Index(string(first), `"b"`) {
+		t.Fatal("expected procedures sorted by name")
+	}
+}
+
+func TestCompareGolden(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "testdata", "manifest.golden.json")
+
+	// Missing file is written on first run
+	if err := CompareGolden(path, []byte("{\n  \"a\": 1\n}\n")); err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+	if err := CompareGolden(path, []byte("{\n  \"a\": 1\n}\n")); err != nil {
+		t.Fatalf("expected match: %v", err)
+	}
+
+	err := CompareGolden(path, []byte("{\n  \"a\": 2\n}\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2:") || !strings.Contains(err.Error(), `+  "a": 2`) {
+		t.Fatalf("expected line diff, got %v", err)
+	}
+
+	t.Setenv("SEAM_UPDATE_GOLDEN", "1")
+	if err := CompareGolden(path, []byte("updated\n")); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "updated\n" {
+		t.Fatalf("expected golden update, got %q", data)
+	}
+}
@@ -7,6 +7,7 @@ type IncomingDef struct {
 	InputSchema  any
 	OutputSchema any
 	ErrorSchema  any
+	Echo         string // optional: outgoing event republishing a successful result to other subscribers
 	Handler      HandlerFunc
 }
 
@@ -19,6 +20,7 @@ type ChannelDef struct {
 	Incoming         map[string]IncomingDef
 	Outgoing         map[string]any // event name -> payload schema
 	SubscribeHandler SubscriptionHandlerFunc
+	OnConnect        ChannelConnectFunc // optional: require a handshake frame on WebSocket connections
 }
 
 // channelMeta is the IR hint stored in the manifest.
@@ -35,12 +37,17 @@ type incomingMeta struct {
 }
 
 // expand converts a ChannelDef into Level 0 primitives + metadata.
-func (ch ChannelDef) expand() ([]ProcedureDef, []SubscriptionDef, channelMeta) {
+// With a non-nil hub, echoing incoming messages are wired through it.
+func (ch ChannelDef) expand(hub *Hub) ([]ProcedureDef, []SubscriptionDef, channelMeta) {
 	var procedures []ProcedureDef
 	incomingMetas := make(map[string]incomingMeta)
 
 	for msgName, msgDef := range ch.Incoming {
 		mergedInput := mergeObjectSchemas(ch.InputSchema, msgDef.InputSchema)
+		handler := msgDef.Handler
+		if hub != nil && msgDef.Echo != "" {
+			handler = ch.echoHandler(hub, msgDef.Echo, handler)
+		}
 
 		procedures = append(procedures, ProcedureDef{
 			Name:         ch.Name + "." + msgName,
@@ -48,7 +55,7 @@ func (ch ChannelDef) expand() ([]ProcedureDef, []SubscriptionDef, channelMeta) {
 			InputSchema:  mergedInput,
 			OutputSchema: msgDef.OutputSchema,
 			ErrorSchema:  msgDef.ErrorSchema,
-			Handler:      msgDef.Handler,
+			Handler:      handler,
 		})
 
 		meta := incomingMeta{
@@ -77,11 +84,15 @@ func (ch ChannelDef) expand() ([]ProcedureDef, []SubscriptionDef, channelMeta) {
 		"mapping":       mapping,
 	}
 
+	subscribeHandler := ch.SubscribeHandler
+	if hub != nil && ch.hasEcho() {
+		subscribeHandler = ch.echoSubscribeHandler(hub, subscribeHandler)
+	}
 	subscriptions := []SubscriptionDef{{
 		Name:         ch.Name + ".events",
 		InputSchema:  ch.InputSchema,
 		OutputSchema: unionSchema,
-		Handler:      ch.SubscribeHandler,
+		Handler:      subscribeHandler,
 	}}
 
 	meta := channelMeta{
@@ -0,0 +1,128 @@
+/* src/server/core/go/channel_echo.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+type channelConnKeyType struct{}
+
+// channelConnKey marks contexts derived from one channel WebSocket so echoes
+// of a connection's own commands are not pushed back to it.
+var channelConnKey = channelConnKeyType{}
+
+type channelEcho struct {
+	event   string
+	payload any
+	origin  any
+}
+
+// hasEcho reports whether any incoming message echoes its result.
+func (ch ChannelDef) hasEcho() bool {
+	for _, msg := range ch.Incoming {
+		if msg.Echo != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// validateEcho panics when an incoming message echoes into an undeclared
+// outgoing event.
+func (ch ChannelDef) validateEcho() {
+	for msgName, msg := range ch.Incoming {
+		if msg.Echo == "" {
+			continue
+		}
+		if _, ok := ch.Outgoing[msg.Echo]; !ok {
+			panic(fmt.Sprintf("channel %q: incoming %q echoes undeclared outgoing event %q", ch.Name, msgName, msg.Echo))
+		}
+	}
+}
+
+// echoTopic scopes echoes to subscribers sharing the same channel-level
+// input (e.g. one room), picking only the channel input fields from a
+// possibly merged command input.
+func (ch ChannelDef) echoTopic(input json.RawMessage) string {
+	var fields map[string]any
+	_ = json.Unmarshal(input, &fields)
+	schema, _ := ch.InputSchema.(map[string]any)
+	var names []string
+	for _, key := range []string{"properties", "optionalProperties"} {
+		if props, ok := schema[key].(map[string]any); ok {
+			for name := range props {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	scope := make(map[string]any, len(names))
+	for _, name := range names {
+		if v, ok := fields[name]; ok {
+			scope[name] = v
+		}
+	}
+	key, _ := json.Marshal(scope)
+	return "seam.channel." + ch.Name + ":" + string(key)
+}
+
+// echoHandler wraps an incoming handler to publish successful results as
+// the given outgoing event.
+func (ch ChannelDef) echoHandler(hub *Hub, event string, inner HandlerFunc) HandlerFunc {
+	return func(ctx context.Context, input json.RawMessage) (any, error) {
+		result, err := inner(ctx, input)
+		if err == nil {
+			hub.Publish(ch.echoTopic(input), channelEcho{event: event, payload: result, origin: ctx.Value(channelConnKey)})
+		}
+		return result, err
+	}
+}
+
+// echoSubscribeHandler merges echoed command results into the channel's
+// event stream. The stream ends when the inner stream ends.
+func (ch ChannelDef) echoSubscribeHandler(hub *Hub, inner SubscriptionHandlerFunc) SubscriptionHandlerFunc {
+	return func(ctx context.Context, input json.RawMessage) (<-chan SubscriptionEvent, error) {
+		var src <-chan SubscriptionEvent
+		if inner != nil {
+			var err error
+			if src, err = inner(ctx, input); err != nil {
+				return nil, err
+			}
+		}
+		echoes := hub.Subscribe(ctx, ch.echoTopic(input))
+		origin := ctx.Value(channelConnKey)
+		out := make(chan SubscriptionEvent)
+		go func() {
+			defer close(out)
+			for {
+				var ev SubscriptionEvent
+				select {
+				case e, ok := <-src:
+					if !ok {
+						return
+					}
+					ev = e
+				case msg, ok := <-echoes:
+					if !ok {
+						return
+					}
+					echo := msg.(channelEcho)
+					if origin != nil && echo.origin == origin {
+						continue
+					}
+					ev = SubscriptionEvent{Value: map[string]any{"type": echo.event, "payload": echo.payload}}
+				}
+				select {
+				case out <- ev:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+		return out, nil
+	}
+}
@@ -0,0 +1,105 @@
+/* src/server/core/go/channel_echo_test.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func echoTestChannel() ChannelDef {
+	return ChannelDef{
+		Name: "chat",
+		InputSchema: map[string]any{
+			"properties": map[string]any{"roomId": map[string]any{"type": "string"}},
+		},
+		Incoming: map[string]IncomingDef{
+			"send": {
+				InputSchema: map[string]any{"properties": map[string]any{"text": map[string]any{"type": "string"}}},
+				Echo:        "newMessage",
+				Handler: func(_ context.Context, input json.RawMessage) (any, error) {
+					var in struct {
+						Text string `json:"text"`
+					}
+					_ = json.Unmarshal(input, &in)
+					return map[string]any{"text": in.Text}, nil
+				},
+			},
+		},
+		Outgoing: map[string]any{"newMessage": map[string]any{"properties": map[string]any{"text": map[string]any{"type": "string"}}}},
+		SubscribeHandler: func(ctx context.Context, _ json.RawMessage) (<-chan SubscriptionEvent, error) {
+			ch := make(chan SubscriptionEvent)
+			go func() { <-ctx.Done(); close(ch) }()
+			return ch, nil
+		},
+	}
+}
+
+func TestChannelEchoUndeclaredEventPanics(t *testing.T) {
+	ch := echoTestChannel()
+	send := ch.Incoming["send"]
+	send.Echo = "unknown"
+	ch.Incoming["send"] = send
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for undeclared echo event")
+		}
+	}()
+	NewRouter().Channel(ch)
+}
+
+func TestChannelEchoSkipsSenderAndOtherRooms(t *testing.T) {
+	router := NewRouter().Channel(echoTestChannel())
+	srv := httptest.NewServer(router.Handler(HandlerOptions{HeartbeatInterval: time.Minute}))
+	defer srv.Close()
+
+	dial := func(room string) *websocket.Conn {
+		url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/_seam/procedure/chat.events?input=" + `{"roomId":"` + room + `"}`
+		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return conn
+	}
+	sender, peer, other := dial("r1"), dial("r1"), dial("r2")
+	defer sender.Close()
+	defer peer.Close()
+	defer other.Close()
+
+	for router.Hub().Subscribers(echoTestChannel().echoTopic([]byte(`{"roomId":"r1"}`))) < 2 {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err := sender.WriteJSON(map[string]any{"id": "1", "procedure": "chat.send", "input": map[string]any{"text": "hi"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var push wsPush
+	_ = peer.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if err := peer.ReadJSON(&push); err != nil {
+		t.Fatal(err)
+	}
+	if push.Event != "newMessage" || push.Payload.(map[string]any)["text"] != "hi" {
+		t.Fatalf("unexpected push: %+v", push)
+	}
+
+	// Sender sees only its command response, never its own echo
+	var resp map[string]any
+	_ = sender.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if err := sender.ReadJSON(&resp); err != nil || resp["id"] != "1" {
+		t.Fatalf("expected command response, got %v (%v)", resp, err)
+	}
+	_ = sender.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	if err := sender.ReadJSON(&resp); err == nil {
+		t.Fatalf("sender received its own echo: %v", resp)
+	}
+	_ = other.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	if err := other.ReadJSON(&resp); err == nil {
+		t.Fatalf("other room received echo: %v", resp)
+	}
+}
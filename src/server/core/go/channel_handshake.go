@@ -0,0 +1,114 @@
+/* src/server/core/go/channel_handshake.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/websocket"
+)
+
+// ChannelHandshake is the first frame a client sends on a channel
+// WebSocket when the channel defines OnConnect:
+//
+//	{"handshake": {"token": "...", "clientVersion": "1.4.0", "resumeId": "42"}}
+type ChannelHandshake struct {
+	Token         string         `json:"token,omitempty"`
+	ClientVersion string         `json:"clientVersion,omitempty"`
+	ResumeID      string         `json:"resumeId,omitempty"`
+	Metadata      map[string]any `json:"metadata,omitempty"`
+}
+
+// ChannelConnectFunc validates a handshake before the subscription starts.
+// The returned context (derived from ctx) is used for the subscription and
+// every command on the connection; a returned error rejects the connection
+// with a close code derived from its error code.
+type ChannelConnectFunc func(ctx context.Context, hs ChannelHandshake, input json.RawMessage) (context.Context, error)
+
+type handshakeKeyType struct{}
+
+var handshakeKey = handshakeKeyType{}
+
+// HandshakeOf returns the accepted handshake of the current channel connection.
+func HandshakeOf(ctx context.Context) (ChannelHandshake, bool) {
+	hs, ok := ctx.Value(handshakeKey).(ChannelHandshake)
+	return hs, ok
+}
+
+type wsHandshakeFrame struct {
+	Handshake *ChannelHandshake `json:"handshake"`
+}
+
+// WebSocket close codes used when rejecting a channel connection.
+const (
+	CloseBadHandshake = 4400
+	CloseUnauthorized = 4401
+	CloseForbidden    = 4403
+	CloseRateLimited  = 4429
+	CloseServerError  = 4500
+)
+
+// wsCloseCode maps an error to an application close code (4000-4999).
+func wsCloseCode(err *Error) int {
+	switch err.Code {
+	case "VALIDATION_ERROR":
+		return CloseBadHandshake
+	case "UNAUTHORIZED":
+		return CloseUnauthorized
+	case "FORBIDDEN":
+		return CloseForbidden
+	case "RATE_LIMITED":
+		return CloseRateLimited
+	}
+	switch errorHTTPStatus(err) {
+	case http.StatusBadRequest:
+		return CloseBadHandshake
+	case http.StatusUnauthorized:
+		return CloseUnauthorized
+	case http.StatusForbidden:
+		return CloseForbidden
+	}
+	return CloseServerError
+}
+
+// closeWs sends a close frame carrying err and closes the connection.
+func closeWs(conn *websocket.Conn, err error) {
+	seamErr, ok := err.(*Error)
+	if !ok {
+		seamErr = InternalError(err.Error())
+	}
+	reason := seamErr.Message
+	if len(reason) > 120 { // close frame payload limit is 125 bytes
+		reason = reason[:120]
+	}
+	_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(wsCloseCode(seamErr), reason))
+	_ = conn.Close()
+}
+
+// parseHandshake decodes the first frame of a connection.
+func parseHandshake(frame []byte) (ChannelHandshake, error) {
+	var hf wsHandshakeFrame
+	if err := json.Unmarshal(frame, &hf); err != nil || hf.Handshake == nil {
+		return ChannelHandshake{}, ValidationError("Expected handshake frame")
+	}
+	return *hf.Handshake, nil
+}
+
+// acceptHandshake runs OnConnect and attaches the handshake to ctx. The
+// resume ID doubles as Last-Event-ID for subscription resumption.
+func acceptHandshake(ctx context.Context, onConnect ChannelConnectFunc, hs ChannelHandshake, input json.RawMessage) (context.Context, error) {
+	ctx = context.WithValue(ctx, handshakeKey, hs)
+	if hs.ResumeID != "" {
+		ctx = context.WithValue(ctx, lastEventIDKey, hs.ResumeID)
+	}
+	next, err := onConnect(ctx, hs, input)
+	if err != nil {
+		return nil, err
+	}
+	if next == nil {
+		next = ctx
+	}
+	return next, nil
+}
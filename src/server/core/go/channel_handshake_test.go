@@ -0,0 +1,86 @@
+/* src/server/core/go/channel_handshake_test.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+type handshakeUserKey struct{}
+
+func handshakeTestServer(t *testing.T) *httptest.Server {
+	ch := echoTestChannel()
+	ch.OnConnect = func(ctx context.Context, hs ChannelHandshake, _ json.RawMessage) (context.Context, error) {
+		if hs.Token != "secret" {
+			return nil, UnauthorizedError("bad token")
+		}
+		return context.WithValue(ctx, handshakeUserKey{}, "alice"), nil
+	}
+	send := ch.Incoming["send"]
+	send.Handler = func(ctx context.Context, _ json.RawMessage) (any, error) {
+		hs, _ := HandshakeOf(ctx)
+		return map[string]any{"user": ctx.Value(handshakeUserKey{}), "version": hs.ClientVersion, "resume": LastEventID(ctx)}, nil
+	}
+	ch.Incoming["send"] = send
+	srv := httptest.NewServer(NewRouter().Channel(ch).Handler())
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func dialChannel(t *testing.T, srv *httptest.Server) *websocket.Conn {
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + `/_seam/procedure/chat.events?input={"roomId":"r1"}`
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	return conn
+}
+
+func TestChannelHandshakeAccepted(t *testing.T) {
+	conn := dialChannel(t, handshakeTestServer(t))
+	_ = conn.WriteJSON(map[string]any{"handshake": map[string]any{"token": "secret", "clientVersion": "1.2.0", "resumeId": "7"}})
+
+	var connected wsPush
+	if err := conn.ReadJSON(&connected); err != nil || connected.Event != "__connected" {
+		t.Fatalf("expected __connected, got %+v (%v)", connected, err)
+	}
+	_ = conn.WriteJSON(map[string]any{"id": "1", "procedure": "chat.send", "input": map[string]any{"text": "hi"}})
+	var resp map[string]any
+	if err := conn.ReadJSON(&resp); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := resp["data"].(map[string]any)
+	if data["user"] != "alice" || data["version"] != "1.2.0" || data["resume"] != "7" {
+		t.Fatalf("connection metadata not propagated: %v", resp)
+	}
+}
+
+func TestChannelHandshakeRejectedWithCloseCode(t *testing.T) {
+	cases := []struct {
+		frame any
+		code  int
+	}{
+		{map[string]any{"handshake": map[string]any{"token": "wrong"}}, CloseUnauthorized},
+		{map[string]any{"id": "1", "procedure": "chat.send"}, CloseBadHandshake},
+	}
+	srv := handshakeTestServer(t)
+	for _, c := range cases {
+		conn := dialChannel(t, srv)
+		_ = conn.WriteJSON(c.frame)
+		_, _, err := conn.ReadMessage()
+		var closeErr *websocket.CloseError
+		if !errors.As(err, &closeErr) || closeErr.Code != c.code {
+			t.Fatalf("frame %v: expected close code %d, got %v", c.frame, c.code, err)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+/* src/server/core/go/channel_typed.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// ChannelEvent is one outgoing event of a typed channel subscription: a
+// payload type that names its event. A channel's event union is an
+// interface embedding ChannelEvent, implemented by one struct per event:
+//
+//	type ChatEvent interface{ seam.ChannelEvent }
+//
+//	type NewMessage struct {
+//		Sender string `json:"sender"`
+//		Text   string `json:"text"`
+//	}
+//
+//	func (NewMessage) EventType() string { return "message" }
+type ChannelEvent interface {
+	EventType() string
+}
+
+// SubscribeChannel creates a ChannelDef.SubscribeHandler from a typed
+// handler. The channel input is decoded into In, and each E received is
+// sent in the {"type","payload"} tagged union format, so handlers never
+// build event maps by hand. A nil event is reported as an error event.
+func SubscribeChannel[In any, E ChannelEvent](fn func(context.Context, In) (<-chan E, error)) SubscriptionHandlerFunc {
+	return func(ctx context.Context, raw json.RawMessage) (<-chan SubscriptionEvent, error) {
+		var input In
+		if err := codecUnmarshal(raw, &input); err != nil {
+			return nil, ValidationError("Invalid input: " + err.Error())
+		}
+		dataCh, err := fn(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		eventCh := make(chan SubscriptionEvent)
+		go func() {
+			defer close(eventCh)
+			for ev := range dataCh {
+				if isNilEvent(ev) {
+					eventCh <- SubscriptionEvent{Err: InternalError("Channel subscription sent a nil event")}
+					continue
+				}
+				eventCh <- SubscriptionEvent{Value: map[string]any{"type": ev.EventType(), "payload": ev}}
+			}
+		}()
+		return eventCh, nil
+	}
+}
+
+func isNilEvent(ev ChannelEvent) bool {
+	if ev == nil {
+		return true
+	}
+	v := reflect.ValueOf(ev)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
+
+// ChannelEvents returns the ChannelDef.Outgoing schemas for the variants
+// of an event union, keyed by EventType; zero values suffice. Panics when
+// two variants name the same event.
+//
+//	Outgoing: seam.ChannelEvents(NewMessage{}, UserJoined{}),
+func ChannelEvents(variants ...ChannelEvent) map[string]any {
+	outgoing := make(map[string]any, len(variants))
+	for _, v := range variants {
+		name := v.EventType()
+		if _, dup := outgoing[name]; dup {
+			panic(fmt.Sprintf("channel event %q is declared twice", name))
+		}
+		t := reflect.TypeOf(v)
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		outgoing[name] = schemaFor(t)
+	}
+	return outgoing
+}
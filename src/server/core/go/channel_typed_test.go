@@ -0,0 +1,77 @@
+/* src/server/core/go/channel_typed_test.go */
+
+package seam
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type typedChatEvent interface{ ChannelEvent }
+
+type typedChatMessage struct {
+	Sender string `json:"sender"`
+	Text   string `json:"text"`
+}
+
+func (typedChatMessage) EventType() string { return "message" }
+
+type typedChatJoined struct {
+	User string `json:"user"`
+}
+
+func (*typedChatJoined) EventType() string { return "joined" }
+
+type typedChatInput struct {
+	RoomID string `json:"roomId"`
+}
+
+func typedChatChannel() ChannelDef {
+	return ChannelDef{
+		Name:        "chat",
+		InputSchema: SchemaOf[typedChatInput](),
+		Outgoing:    ChannelEvents(typedChatMessage{}, (*typedChatJoined)(nil)),
+		SubscribeHandler: SubscribeChannel(func(_ context.Context, in typedChatInput) (<-chan typedChatEvent, error) {
+			ch := make(chan typedChatEvent, 3)
+			ch <- &typedChatJoined{User: "bob@" + in.RoomID}
+			ch <- typedChatMessage{Sender: "bob", Text: "hi"}
+			ch <- nil
+			close(ch)
+			return ch, nil
+		}),
+	}
+}
+
+func TestSubscribeChannelTypedEvents(t *testing.T) {
+	h := NewRouter().Channel(typedChatChannel()).Handler()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", `/_seam/procedure/chat.events?input=%7B%22roomId%22%3A%22r1%22%7D`, nil))
+	body := w.Body.String()
+	for _, want := range []string{
+		`{"payload":{"user":"bob@r1"},"type":"joined"}`,
+		`{"payload":{"sender":"bob","text":"hi"},"type":"message"}`,
+		"nil event",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("stream missing %s:\n%s", want, body)
+		}
+	}
+}
+
+func TestChannelEventsSchemas(t *testing.T) {
+	outgoing := typedChatChannel().Outgoing
+	if !reflect.DeepEqual(outgoing["message"], SchemaOf[typedChatMessage]()) ||
+		!reflect.DeepEqual(outgoing["joined"], SchemaOf[typedChatJoined]()) {
+		t.Errorf("outgoing %v", outgoing)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("duplicate event types should panic")
+		}
+	}()
+	ChannelEvents(typedChatMessage{}, typedChatMessage{})
+}
@@ -0,0 +1,105 @@
+/* src/server/core/go/client_ip.go */
+
+package seam
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+type clientIPKeyType struct{}
+
+var clientIPKey = clientIPKeyType{}
+
+// ClientIP returns the client address derived for the request, honoring
+// HandlerOptions.TrustedProxies. Empty outside a seam handler.
+func ClientIP(ctx context.Context) string {
+	if v, ok := ctx.Value(clientIPKey).(string); ok {
+		return v
+	}
+	return ""
+}
+
+// parsePrefixes parses CIDRs or bare addresses (treated as single-host
+// prefixes). Panics on invalid entries, matching registration-time checks.
+func parsePrefixes(option string, entries []string) []netip.Prefix {
+	prefixes := make([]netip.Prefix, 0, len(entries))
+	for _, e := range entries {
+		if !strings.Contains(e, "/") {
+			addr, err := netip.ParseAddr(e)
+			if err != nil {
+				panic(fmt.Sprintf("%s: invalid address %q", option, e))
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		p, err := netip.ParsePrefix(e)
+		if err != nil {
+			panic(fmt.Sprintf("%s: invalid CIDR %q", option, e))
+		}
+		prefixes = append(prefixes, p.Masked())
+	}
+	return prefixes
+}
+
+func containsAddr(prefixes []netip.Prefix, addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, p := range prefixes {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP derives the client address. Forwarding headers are only
+// honored when the direct peer is a trusted proxy; X-Forwarded-For is
+// walked right to left, skipping trusted hops, so a client cannot spoof
+// its address by prepending entries. Only X-Forwarded-For is read unless
+// HandlerOptions.ClientIPHeader names another header (e.g. CF-Connecting-IP
+// behind Cloudflare), so a header the proxy does not overwrite is never
+// trusted by default.
+func (s *appState) clientIP(r *http.Request) string {
+	peer := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(peer); err == nil {
+		peer = host
+	}
+	addr, err := netip.ParseAddr(peer)
+	if err != nil || !containsAddr(s.trustedProxies, addr) {
+		return peer
+	}
+
+	header := s.opts.ClientIPHeader
+	if header == "" {
+		header = "X-Forwarded-For"
+	}
+	values := r.Header.Values(header)
+	if len(values) == 0 {
+		return peer
+	}
+	hops := strings.Split(strings.Join(values, ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		hopAddr, err := netip.ParseAddr(hop)
+		if err != nil {
+			break
+		}
+		if i == 0 || !containsAddr(s.trustedProxies, hopAddr) {
+			return hopAddr.Unmap().String()
+		}
+	}
+	return peer
+}
+
+// clientIPMiddleware stores the derived client address in the request
+// context for ClientIP, the access log, and IP filters.
+func (s *appState) clientIPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), clientIPKey, s.clientIP(r))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
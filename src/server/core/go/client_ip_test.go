@@ -0,0 +1,72 @@
+/* src/server/core/go/client_ip_test.go */
+
+package seam
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIPHonorsTrustedProxies(t *testing.T) {
+	s := &appState{trustedProxies: parsePrefixes("TrustedProxies", []string{"10.0.0.0/8", "::1"})}
+	cases := []struct {
+		name, remote, header, value, want string
+	}{
+		{"untrusted peer ignores header", "198.51.100.1:80", "X-Forwarded-For", "203.0.113.9", "198.51.100.1"},
+		{"trusted peer uses header", "10.1.2.3:80", "X-Forwarded-For", "203.0.113.9", "203.0.113.9"},
+		{"skips trusted hops", "10.1.2.3:80", "X-Forwarded-For", "203.0.113.9, 10.9.9.9", "203.0.113.9"},
+		{"rightmost untrusted wins over spoofed prefix", "10.1.2.3:80", "X-Forwarded-For", "1.1.1.1, 203.0.113.9", "203.0.113.9"},
+		{"cloudflare header ignored by default", "[::1]:443", "CF-Connecting-IP", "2001:db8::7", "::1"},
+		{"garbage falls back to peer", "10.1.2.3:80", "X-Forwarded-For", "nope", "10.1.2.3"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tc.remote
+			req.Header.Set(tc.header, tc.value)
+			if got := s.clientIP(req); got != tc.want {
+				t.Errorf("clientIP = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestClientIPCustomHeader(t *testing.T) {
+	s := &appState{
+		trustedProxies: parsePrefixes("TrustedProxies", []string{"10.0.0.1"}),
+		opts:           HandlerOptions{ClientIPHeader: "X-Real-IP"},
+	}
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "10.0.0.1:80"
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+	req.Header.Set("X-Real-IP", "203.0.113.9")
+	if got := s.clientIP(req); got != "203.0.113.9" {
+		t.Errorf("clientIP = %q, want 203.0.113.9", got)
+	}
+}
+
+func TestClientIPIgnoresSpoofedCloudflareHeader(t *testing.T) {
+	// A proxy that appends X-Forwarded-For but passes CF-Connecting-IP through
+	s := &appState{trustedProxies: parsePrefixes("TrustedProxies", []string{"10.0.0.1"})}
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "10.0.0.1:80"
+	req.Header.Set("CF-Connecting-IP", "1.1.1.1")
+	req.Header.Set("X-Forwarded-For", "203.0.113.9")
+	if got := s.clientIP(req); got != "203.0.113.9" {
+		t.Errorf("clientIP = %q, want 203.0.113.9", got)
+	}
+
+	s.opts.ClientIPHeader = "CF-Connecting-IP"
+	if got := s.clientIP(req); got != "1.1.1.1" {
+		t.Errorf("opt-in clientIP = %q, want 1.1.1.1", got)
+	}
+}
+
+func TestParsePrefixesPanicsOnInvalid(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic")
+		}
+	}()
+	parsePrefixes("TrustedProxies", []string{"10.0.0.0/33"})
+}
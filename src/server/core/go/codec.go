@@ -0,0 +1,69 @@
+/* src/server/core/go/codec.go */
+
+package seam
+
+import (
+	"encoding/json"
+	"io"
+	"sync/atomic"
+)
+
+// Codec encodes and decodes JSON on the RPC hot path: procedure inputs
+// (typed handlers and validation), RPC/batch/upload responses, SSE
+// events, and WebSocket frames. Implementations must be drop-in
+// compatible with encoding/json (struct tags, json.RawMessage, numbers
+// decoded into any as float64), e.g. goccy/go-json or bytedance/sonic in
+// its std-compatible config.
+//
+// Page data rendering and build artifacts keep encoding/json, which sorts
+// map keys deterministically.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// StdCodec is the default Codec backed by encoding/json.
+type StdCodec struct{}
+
+func (StdCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (StdCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+type codecBox struct{ Codec }
+
+var activeCodec atomic.Pointer[codecBox]
+
+// SetCodec replaces the process-wide JSON codec (nil restores StdCodec).
+// The codec is process-wide rather than per handler because typed
+// procedures (Query, Command, ...) decode their input before any handler
+// state is available. Call it during startup, before serving.
+func SetCodec(c Codec) {
+	if c == nil {
+		c = StdCodec{}
+	}
+	activeCodec.Store(&codecBox{c})
+}
+
+func codec() Codec {
+	if box := activeCodec.Load(); box != nil {
+		return box.Codec
+	}
+	return StdCodec{}
+}
+
+func codecMarshal(v any) ([]byte, error) {
+	return codec().Marshal(v)
+}
+
+func codecUnmarshal(data []byte, v any) error {
+	return codec().Unmarshal(data, v)
+}
+
+// writeJSON writes v followed by a newline, matching json.Encoder output.
+func writeJSON(w io.Writer, v any) error {
+	b, err := codecMarshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
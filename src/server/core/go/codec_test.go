@@ -0,0 +1,80 @@
+/* src/server/core/go/codec_test.go */
+
+package seam
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+type countingCodec struct {
+	StdCodec
+	marshals, unmarshals atomic.Int64
+}
+
+func (c *countingCodec) Marshal(v any) ([]byte, error) {
+	c.marshals.Add(1)
+	return c.StdCodec.Marshal(v)
+}
+
+func (c *countingCodec) Unmarshal(data []byte, v any) error {
+	c.unmarshals.Add(1)
+	return c.StdCodec.Unmarshal(data, v)
+}
+
+type codecUser struct {
+	ID    int    `json:"id"`
+	Login string `json:"login"`
+}
+
+func codecTestHandler() http.Handler {
+	return NewRouter().
+		Procedure(Query("getUser", func(_ context.Context, in struct {
+			ID int `json:"id"`
+		}) (codecUser, error) {
+			return codecUser{ID: in.ID, Login: "octocat"}, nil
+		})).
+		Handler()
+}
+
+func TestCustomCodecUsedForRPC(t *testing.T) {
+	c := &countingCodec{}
+	SetCodec(c)
+	t.Cleanup(func() { SetCodec(nil) })
+
+	w := httptest.NewRecorder()
+	codecTestHandler().ServeHTTP(w, httptest.NewRequest("POST", "/_seam/procedure/getUser", strings.NewReader(`{"id":7}`)))
+	if w.Code != http.StatusOK || w.Body.String() != `{"data":{"id":7,"login":"octocat"},"ok":true}`+"\n" {
+		t.Fatalf("unexpected response %d %s", w.Code, w.Body.String())
+	}
+	if c.unmarshals.Load() == 0 || c.marshals.Load() == 0 {
+		t.Fatalf("codec not used: %d unmarshals, %d marshals", c.unmarshals.Load(), c.marshals.Load())
+	}
+}
+
+func BenchmarkRPCCodec(b *testing.B) {
+	h := codecTestHandler()
+	b.ReportAllocs()
+	for b.Loop() {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("POST", "/_seam/procedure/getUser", strings.NewReader(`{"id":7}`)))
+	}
+}
+
+func BenchmarkCodecMarshalList(b *testing.B) {
+	repos := make([]map[string]any, 100)
+	for i := range repos {
+		repos[i] = map[string]any{"id": i, "name": fmt.Sprintf("repo-%d", i), "stars": i * 10, "private": i%2 == 0}
+	}
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := codecMarshal(repos); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+/* src/server/core/go/data_scripts.go */
+
+package seam
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// DataScript moves top-level data keys out of the page's data script into
+// a separate JSON script, e.g. {ID: "__SEAM_I18N__", Keys: []string{"_i18n"}}
+// so a service worker can cache translations apart from loader data.
+// Scripts are emitted in PageDef.DataScripts order; an entry whose ID is
+// the page's DataID places the main script (its Keys are ignored), which
+// otherwise comes first. The main script lists the other IDs under
+// "_scripts" so parseSeamData on the client merges them back.
+type DataScript struct {
+	ID   string   `json:"id"`
+	Keys []string `json:"keys"`
+}
+
+// rewriteDataScripts decodes the engine's data script in html once and
+// applies the post-render data edits to it: dir (when set) moves into
+// _i18n.dir (applyDir), and scripts split keys into separate scripts. The
+// html is returned unchanged when the data script cannot be found or
+// parsed, or when nothing changed.
+func rewriteDataScripts(html, dataID, dir string, scripts []DataScript) string {
+	start, end, data, ok := findDataScript(html, dataID)
+	if !ok {
+		return html
+	}
+	changed := dir != "" && applyDir(data, dir)
+	if !changed && len(scripts) == 0 {
+		return html
+	}
+
+	parts := make(map[string]map[string]json.RawMessage, len(scripts))
+	var ids []string
+	mainPlaced := false
+	for _, ds := range scripts {
+		if ds.ID == dataID {
+			mainPlaced = true
+			continue
+		}
+		ids = append(ids, ds.ID)
+		part := make(map[string]json.RawMessage)
+		for _, key := range ds.Keys {
+			if v, ok := data[key]; ok {
+				part[key] = v
+				delete(data, key)
+			}
+		}
+		parts[ds.ID] = part
+	}
+	if len(ids) > 0 {
+		data[splitScriptsKey], _ = json.Marshal(ids)
+	}
+
+	var b strings.Builder
+	b.WriteString(html[:start])
+	if !mainPlaced {
+		writeDataScript(&b, dataID, data)
+	}
+	for _, ds := range scripts {
+		if ds.ID == dataID {
+			writeDataScript(&b, dataID, data)
+		} else {
+			writeDataScript(&b, ds.ID, parts[ds.ID])
+		}
+	}
+	b.WriteString(html[end:])
+	return b.String()
+}
+
+// splitScriptsKey lists, in the main data script, the IDs of the scripts
+// split out of it.
+const splitScriptsKey = "_scripts"
+
+// findDataScript locates the data script of dataID in html; start and
+// end bound the whole element and data is its decoded payload.
+func findDataScript(html, dataID string) (start, end int, data map[string]json.RawMessage, ok bool) {
+	open := fmt.Sprintf(`<script id="%s" type="application/json">`, dataID)
+	start = strings.Index(html, open)
+	if start < 0 {
+		return 0, 0, nil, false
+	}
+	body := start + len(open)
+	n := strings.Index(html[body:], "</script>")
+	if n < 0 {
+		return 0, 0, nil, false
+	}
+	if err := json.Unmarshal([]byte(html[body:body+n]), &data); err != nil {
+		return 0, 0, nil, false
+	}
+	return start, body + n + len("</script>"), data, true
+}
+
+// writeDataScript writes one JSON script, escaped like the engine's
+// (non-ASCII as \uXXXX) and safe for script context ("<", ">", "&").
+func writeDataScript(b *strings.Builder, id string, data map[string]json.RawMessage) {
+	raw, _ := json.Marshal(data)
+	fmt.Fprintf(b, `<script id="%s" type="application/json">%s</script>`, id, asciiEscapeJSON(string(raw), true))
+}
+
+// checkDataScripts panics on data script IDs that are empty or repeated.
+func checkDataScripts(page *PageDef) {
+	seen := make(map[string]bool, len(page.DataScripts))
+	for _, ds := range page.DataScripts {
+		if ds.ID == "" {
+			panic(fmt.Sprintf("page %q has a data script without an ID", page.Route))
+		}
+		if seen[ds.ID] {
+			panic(fmt.Sprintf("page %q has duplicate data script %q", page.Route, ds.ID))
+		}
+		seen[ds.ID] = true
+	}
+}
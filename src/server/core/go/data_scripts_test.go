@@ -0,0 +1,110 @@
+/* src/server/core/go/data_scripts_test.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSplitDataScriptsOrdering(t *testing.T) {
+	html := `<body><p>x</p><script id="__data" type="application/json">{"_i18n":{"locale":"en"},"a":1,"b":"</b>"}</script></body>`
+
+	got := rewriteDataScripts(html, "__data", "", []DataScript{{ID: "__SEAM_I18N__", Keys: []string{"_i18n"}}})
+	want := `<body><p>x</p><script id="__data" type="application/json">{"_scripts":["__SEAM_I18N__"],"a":1,"b":"\u003c/b\u003e"}</script>` +
+		`<script id="__SEAM_I18N__" type="application/json">{"_i18n":{"locale":"en"}}</script></body>`
+	if got != want {
+		t.Fatalf("unexpected split:\n%s", got)
+	}
+
+	// Listing the main ID places it; absent keys still emit an empty script
+	got = rewriteDataScripts(html, "__data", "", []DataScript{
+		{ID: "__SEAM_I18N__", Keys: []string{"_i18n"}},
+		{ID: "__data"},
+		{ID: "__extra", Keys: []string{"missing"}},
+	})
+	i18n := strings.Index(got, `id="__SEAM_I18N__"`)
+	main := strings.Index(got, `id="__data"`)
+	extra := strings.Index(got, `<script id="__extra" type="application/json">{}</script>`)
+	if i18n < 0 || main < i18n || extra < main || !strings.Contains(got, `"_scripts":["__SEAM_I18N__","__extra"]`) {
+		t.Fatalf("unexpected order:\n%s", got)
+	}
+
+	if got := rewriteDataScripts("<p>no data</p>", "__data", "", []DataScript{{ID: "x"}}); got != "<p>no data</p>" {
+		t.Fatalf("expected html unchanged, got %s", got)
+	}
+}
+
+func TestPageDataScripts(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"route-manifest.json": `{
+			"data_id": "__SEAM_DATA__",
+			"data_scripts": [{"id": "__SEAM_FLAGS__", "keys": ["_flags"]}, {"id": "__SEAM_DATA__"}],
+			"routes": {"/home": {"template": "templates/home.html", "loaders": {"user": {"procedure": "getUser"}}}}
+		}`,
+		"templates/home.html": `<html><body><p><!--seam:user.name--></p></body></html>`,
+	})
+	pages, err := LoadBuildOutput(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := NewRouter().
+		Procedure(Query("getUser", func(context.Context, struct{}) (map[string]string, error) {
+			return map[string]string{"name": "Ada"}, nil
+		})).
+		Page(&pages[0])
+	h := router.Handler(HandlerOptions{
+		ExposeFlags: true,
+		Flags: FlagProviderFunc(func(context.Context, FlagTarget) (map[string]any, error) {
+			return map[string]any{"beta": true}, nil
+		}),
+	})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/_seam/page/home", nil))
+	body := w.Body.String()
+	flags := strings.Index(body, `<script id="__SEAM_FLAGS__" type="application/json">{"_flags":{"beta":true}}</script>`)
+	data := strings.Index(body, `<script id="__SEAM_DATA__" type="application/json">{"__loaders":`)
+	if flags < 0 || data < flags || strings.Count(body, `"_flags"`) != 1 {
+		t.Fatalf("unexpected data scripts:\n%s", body)
+	}
+}
+
+func TestCheckDataScriptsPanics(t *testing.T) {
+	for _, scripts := range [][]DataScript{{{ID: ""}}, {{ID: "a"}, {ID: "a"}}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected panic for %v", scripts)
+				}
+			}()
+			checkDataScripts(&PageDef{Route: "/", DataScripts: scripts})
+		}()
+	}
+}
+
+func TestWriteDataScriptEscapesLikeEngine(t *testing.T) {
+	var b strings.Builder
+	writeDataScript(&b, "__data", map[string]json.RawMessage{"t": json.RawMessage(`"héllo </script>"`)})
+	want := `<script id="__data" type="application/json">{"t":"h\u00e9llo \u003c/script\u003e"}</script>`
+	if b.String() != want {
+		t.Fatalf("writeDataScript = %s", b.String())
+	}
+}
+
+func TestRewriteDataScriptsAppliesDirBeforeSplit(t *testing.T) {
+	html := `<body><script id="__data" type="application/json">{"_dir":"rtl","_i18n":{"locale":"ar"},"a":1}</script></body>`
+	got := rewriteDataScripts(html, "__data", "rtl", []DataScript{{ID: "__SEAM_I18N__", Keys: []string{"_i18n"}}})
+	want := `<body><script id="__data" type="application/json">{"_scripts":["__SEAM_I18N__"],"a":1}</script>` +
+		`<script id="__SEAM_I18N__" type="application/json">{"_i18n":{"dir":"rtl","locale":"ar"}}</script></body>`
+	if got != want {
+		t.Fatalf("unexpected rewrite:\n%s", got)
+	}
+	if got := rewriteDataScripts(`<script id="__data" type="application/json">{"a":1}</script>`, "__data", "ltr", nil); got != `<script id="__data" type="application/json">{"a":1}</script>` {
+		t.Fatalf("expected html unchanged without _i18n, got %s", got)
+	}
+}
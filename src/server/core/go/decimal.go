@@ -0,0 +1,219 @@
+/* src/server/core/go/decimal.go */
+
+package seam
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// String formats of numbers too large or precise for JSON numbers. Their
+// schema is {"type": "string", "metadata": {"format": ...}} and input
+// validation checks the string against the format.
+const (
+	FormatDecimal = "decimal" // "-12.50", "1e-8"
+	FormatBigInt  = "bigint"  // "-123456789012345678901234567890"
+)
+
+var numberFormats = map[string]*regexp.Regexp{
+	FormatDecimal: regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?([eE][+-]?[0-9]+)?$`),
+	FormatBigInt:  regexp.MustCompile(`^-?[0-9]+$`),
+}
+
+var (
+	decimalType  = reflect.TypeFor[Decimal]()
+	bigIntType   = reflect.TypeFor[BigInt]()
+	bigFloatType = reflect.TypeFor[big.Float]()
+)
+
+// stringFormats maps types registered with SchemaFormat to their format.
+var stringFormats sync.Map // reflect.Type -> string
+
+// SchemaFormat declares that T serializes as a JSON string in format, so
+// SchemaOf describes it as such; use it for third-party types such as
+// shopspring's decimal.Decimal: seam.SchemaFormat[decimal.Decimal](seam.FormatDecimal).
+func SchemaFormat[T any](format string) {
+	stringFormats.Store(reflect.TypeFor[T](), format)
+}
+
+// numberFormatOf returns the string format of t, or "" for other types.
+func numberFormatOf(t reflect.Type) string {
+	switch t {
+	case decimalType, bigFloatType:
+		return FormatDecimal
+	case bigIntType:
+		return FormatBigInt
+	}
+	if f, ok := stringFormats.Load(t); ok {
+		return f.(string)
+	}
+	return ""
+}
+
+// Decimal is an exact decimal number, unscaled × 10^-scale, serialized as
+// a JSON string so no float rounding happens on any side of the wire.
+type Decimal struct {
+	unscaled *big.Int
+	scale    int32
+}
+
+// NewDecimal returns unscaled × 10^-scale: NewDecimal(1250, 2) is 12.50.
+func NewDecimal(unscaled int64, scale int32) Decimal {
+	return Decimal{unscaled: big.NewInt(unscaled), scale: scale}
+}
+
+// MaxDecimalScale bounds the digits ParseDecimal expands a value to, on
+// either side of the point: "1e10000" and "1e-10000" parse, "1e10001"
+// does not. It keeps a short exponent in client input from allocating a
+// huge big.Int.
+const MaxDecimalScale = 10000
+
+// ParseDecimal parses a decimal string, optionally with an exponent; the
+// resulting scale must be within ±MaxDecimalScale.
+func ParseDecimal(s string) (Decimal, error) {
+	if !numberFormats[FormatDecimal].MatchString(s) {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+	mantissa, exp := s, int64(0)
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		mantissa = s[:i]
+		if _, err := fmt.Sscan(s[i+1:], &exp); err != nil {
+			return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+		}
+	}
+	intPart, frac, _ := strings.Cut(mantissa, ".")
+	scale := int64(len(frac)) - exp
+	if scale > MaxDecimalScale || scale < -MaxDecimalScale {
+		return Decimal{}, fmt.Errorf("decimal %q out of range", s)
+	}
+	unscaled, _ := new(big.Int).SetString(intPart+frac, 10)
+	if scale < 0 {
+		unscaled.Mul(unscaled, new(big.Int).Exp(big.NewInt(10), big.NewInt(-scale), nil))
+		scale = 0
+	}
+	return Decimal{unscaled: unscaled, scale: int32(scale)}, nil
+}
+
+// Rat returns the exact value as a rational number.
+func (d Decimal) Rat() *big.Rat {
+	denom := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(d.scale)), nil)
+	return new(big.Rat).SetFrac(d.unscaledInt(), denom)
+}
+
+// Scale returns the number of digits after the decimal point.
+func (d Decimal) Scale() int32 { return d.scale }
+
+func (d Decimal) unscaledInt() *big.Int {
+	if d.unscaled == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(d.unscaled)
+}
+
+// String formats the decimal with exactly Scale fraction digits.
+func (d Decimal) String() string {
+	digits := d.unscaledInt()
+	neg := digits.Sign() < 0
+	s := digits.Abs(digits).String()
+	if d.scale > 0 {
+		if pad := int(d.scale) + 1 - len(s); pad > 0 {
+			s = strings.Repeat("0", pad) + s
+		}
+		s = s[:len(s)-int(d.scale)] + "." + s[len(s)-int(d.scale):]
+	}
+	if neg {
+		s = "-" + s
+	}
+	return s
+}
+
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + d.String() + `"`), nil
+}
+
+// UnmarshalJSON accepts a decimal string or a JSON number, both parsed
+// from their text so no precision is lost.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	text, err := numberText(data)
+	if err != nil || text == "" {
+		return err
+	}
+	parsed, err := ParseDecimal(text)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// BigInt is an arbitrary-precision integer serialized as a JSON string,
+// unlike *big.Int whose JSON number loses precision in JavaScript.
+type BigInt struct {
+	v *big.Int
+}
+
+// NewBigInt copies x into a BigInt.
+func NewBigInt(x *big.Int) BigInt {
+	return BigInt{v: new(big.Int).Set(x)}
+}
+
+// Int returns a copy of the value.
+func (b BigInt) Int() *big.Int {
+	if b.v == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(b.v)
+}
+
+func (b BigInt) String() string { return b.Int().String() }
+
+func (b BigInt) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + b.String() + `"`), nil
+}
+
+// UnmarshalJSON accepts an integer string or JSON number.
+func (b *BigInt) UnmarshalJSON(data []byte) error {
+	text, err := numberText(data)
+	if err != nil || text == "" {
+		return err
+	}
+	v, ok := new(big.Int).SetString(text, 10)
+	if !ok || !numberFormats[FormatBigInt].MatchString(text) {
+		return fmt.Errorf("invalid integer %q", text)
+	}
+	b.v = v
+	return nil
+}
+
+// numberText returns the text of a JSON string or number ("" for null).
+func numberText(data []byte) (string, error) {
+	data = bytes.TrimSpace(data)
+	switch {
+	case string(data) == "null":
+		return "", nil
+	case len(data) >= 2 && data[0] == '"' && data[len(data)-1] == '"':
+		return string(data[1 : len(data)-1]), nil
+	case len(data) > 0 && (data[0] == '-' || data[0] >= '0' && data[0] <= '9'):
+		return string(data), nil
+	}
+	return "", fmt.Errorf("expected a number or numeric string, got %s", data)
+}
+
+// validateNumberFormat reports a string that does not match format.
+func validateNumberFormat(format string, data any, path []string, errors *[]ValidationDetail) {
+	re, ok := numberFormats[format]
+	s, isString := data.(string)
+	if !ok || !isString || re.MatchString(s) {
+		return
+	}
+	*errors = append(*errors, ValidationDetail{
+		Path:     pathString(path),
+		Expected: format + " string",
+		Actual:   fmt.Sprintf("%q", s),
+	})
+}
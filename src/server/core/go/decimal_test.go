@@ -0,0 +1,105 @@
+/* src/server/core/go/decimal_test.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	cases := map[string]string{
+		"12.50":   "12.50",
+		"-0.005":  "-0.005",
+		"1e+3":    "1000",
+		"1.25E-4": "0.000125",
+		"7":       "7",
+		"-12e-2":  "-0.12",
+	}
+	for in, want := range cases {
+		d, err := ParseDecimal(in)
+		if err != nil {
+			t.Fatalf("ParseDecimal(%q): %v", in, err)
+		}
+		if d.String() != want {
+			t.Errorf("ParseDecimal(%q) = %s, want %s", in, d, want)
+		}
+	}
+	for _, bad := range []string{"", "1.", ".5", "1,5", "0x10", "NaN", "1e10001", "1e-10001", "1e1000000"} {
+		if _, err := ParseDecimal(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+	if d, err := ParseDecimal("1e-10000"); err != nil || d.Scale() != MaxDecimalScale {
+		t.Errorf("ParseDecimal at the scale cap: %v %v", d.Scale(), err)
+	}
+	if got := NewDecimal(-5, 3).String(); got != "-0.005" {
+		t.Fatalf("NewDecimal = %s", got)
+	}
+	if NewDecimal(1250, 2).Rat().Cmp(big.NewRat(25, 2)) != 0 {
+		t.Fatal("expected 12.50 == 25/2")
+	}
+}
+
+func TestDecimalJSONLossless(t *testing.T) {
+	type Payment struct {
+		Amount Decimal `json:"amount"`
+		Units  BigInt  `json:"units"`
+	}
+	var p Payment
+	in := `{"amount": 0.1000000000000000000000001, "units": "123456789012345678901234567890"}`
+	if err := json.Unmarshal([]byte(in), &p); err != nil {
+		t.Fatal(err)
+	}
+	out, _ := json.Marshal(p)
+	if string(out) != `{"amount":"0.1000000000000000000000001","units":"123456789012345678901234567890"}` {
+		t.Fatalf("unexpected round trip %s", out)
+	}
+	if err := json.Unmarshal([]byte(`{"units": "1.5"}`), &p); err == nil {
+		t.Fatal("expected fractional BigInt to fail")
+	}
+}
+
+// thirdPartyDecimal stands in for a decimal library type marshaling as a string.
+type thirdPartyDecimal struct{ s string }
+
+func (d thirdPartyDecimal) MarshalJSON() ([]byte, error)  { return json.Marshal(d.s) }
+func (d *thirdPartyDecimal) UnmarshalJSON(b []byte) error { return json.Unmarshal(b, &d.s) }
+
+func TestDecimalSchemaAndValidation(t *testing.T) {
+	SchemaFormat[thirdPartyDecimal](FormatDecimal)
+	type Order struct {
+		Total Decimal           `json:"total"`
+		Units *BigInt           `json:"units"`
+		Rate  big.Float         `json:"rate"`
+		Fee   thirdPartyDecimal `json:"fee"`
+	}
+	decimal := map[string]any{"type": "string", "metadata": map[string]any{"format": "decimal"}}
+	want := map[string]any{"properties": map[string]any{
+		"total": decimal,
+		"units": map[string]any{"type": "string", "metadata": map[string]any{"format": "bigint"}, "nullable": true},
+		"rate":  map[string]any{"type": "string", "metadata": map[string]any{"format": "decimal"}},
+		"fee":   map[string]any{"type": "string", "metadata": map[string]any{"format": "decimal"}},
+	}}
+	schema := SchemaOf[Order]()
+	if !reflect.DeepEqual(schema, want) {
+		t.Fatalf("unexpected schema %v", schema)
+	}
+
+	msg, details := ValidateInput(schema, map[string]any{"total": "12.5x", "units": "10", "rate": "1e3", "fee": "0.5"})
+	if msg == "" || len(details) != 1 || details[0].Path != "/total" || details[0].Expected != "decimal string" {
+		t.Fatalf("unexpected validation %q %+v", msg, details)
+	}
+
+	h := NewRouter().Procedure(Command("pay", func(_ context.Context, in Order) (Decimal, error) {
+		return in.Total, nil
+	})).Handler()
+	status, body := rpcBody(h, "/_seam/procedure/pay", `{"total":"19.99","units":null,"rate":"1","fee":"0"}`, nil)
+	if status != 200 || !strings.Contains(body, `"19.99"`) {
+		t.Fatalf("unexpected response %d %s", status, body)
+	}
+}
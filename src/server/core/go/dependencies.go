@@ -0,0 +1,285 @@
+/* src/server/core/go/dependencies.go */
+
+package seam
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Dependencies aggregates the upstream call outcomes handlers report with
+// ReportDependency. A dependency is down when, over the last Window, at
+// least MinReports calls were reported and at least FailureRatio of them
+// failed. Set it on HandlerOptions.Dependencies so procedures can report,
+// and on Lifecycle.Dependencies so readiness fails while a Critical
+// dependency is down; serve it (it is an http.Handler) next to the other
+// metrics for Prometheus-style scraping.
+type Dependencies struct {
+	Critical     []string      // names whose outage fails readiness
+	Window       time.Duration // outcomes considered (default 1m)
+	FailureRatio float64       // failed share marking a dependency down (default 0.5)
+	MinReports   int           // calls needed before judging (default 5)
+
+	mu    sync.RWMutex // guards deps; each log has its own lock
+	deps  map[string]*dependencyLog
+	nowFn func() time.Time // for tests
+}
+
+// dependencySlots splits the window into slots of counters, so memory per
+// dependency is fixed and outcomes expire a slot (Window/dependencySlots)
+// at a time.
+const dependencySlots = 12
+
+// dependencyLatencyBounds are the upper bounds of the latency histogram;
+// percentiles report the bound of the bucket holding the rank, or the
+// largest latency seen for the overflow bucket.
+var dependencyLatencyBounds = [...]time.Duration{
+	time.Millisecond, 2 * time.Millisecond, 5 * time.Millisecond,
+	10 * time.Millisecond, 20 * time.Millisecond, 50 * time.Millisecond,
+	100 * time.Millisecond, 200 * time.Millisecond, 500 * time.Millisecond,
+	time.Second, 2 * time.Second, 5 * time.Second, 10 * time.Second, 30 * time.Second,
+}
+
+// dependencySlot counts the outcomes of one slot of the window.
+type dependencySlot struct {
+	index     int64 // slot number since the epoch; stale when out of the window
+	calls     int
+	failed    int
+	latencies [len(dependencyLatencyBounds) + 1]int // per dependencyLatencyBounds bucket, plus overflow
+	max       time.Duration
+}
+
+type dependencyLog struct {
+	mu          sync.Mutex
+	slots       [dependencySlots]dependencySlot
+	calls       int64
+	failures    int64
+	lastFailure time.Time
+}
+
+// DependencyStatus summarizes one dependency over the window; Calls and
+// Failures are totals since start.
+type DependencyStatus struct {
+	Name          string        `json:"name"`
+	Healthy       bool          `json:"healthy"`
+	Critical      bool          `json:"critical"`
+	Calls         int64         `json:"calls"`
+	Failures      int64         `json:"failures"`
+	WindowCalls   int           `json:"windowCalls"`
+	WindowFailed  int           `json:"windowFailed"`
+	P50           time.Duration `json:"p50"`
+	P99           time.Duration `json:"p99"`
+	LastFailureAt *time.Time    `json:"lastFailureAt,omitempty"`
+}
+
+type dependenciesKeyType struct{}
+
+var dependenciesKey = dependenciesKeyType{}
+
+// ReportDependency records the outcome of a call to the upstream name
+// ("github", "postgres") made while serving ctx. It is a no-op when the
+// handler has no HandlerOptions.Dependencies.
+func ReportDependency(ctx context.Context, name string, ok bool, latency time.Duration) {
+	if d, _ := ctx.Value(dependenciesKey).(*Dependencies); d != nil {
+		d.Report(name, ok, latency)
+	}
+}
+
+func (d *Dependencies) window() time.Duration {
+	if d.Window > 0 {
+		return d.Window
+	}
+	return time.Minute
+}
+
+func (d *Dependencies) failureRatio() float64 {
+	if d.FailureRatio > 0 {
+		return d.FailureRatio
+	}
+	return 0.5
+}
+
+func (d *Dependencies) minReports() int {
+	if d.MinReports > 0 {
+		return d.MinReports
+	}
+	return 5
+}
+
+func (d *Dependencies) now() time.Time {
+	if d.nowFn != nil {
+		return d.nowFn()
+	}
+	return time.Now()
+}
+
+// slotIndex returns the window slot holding now.
+func (d *Dependencies) slotIndex(now time.Time) int64 {
+	width := int64(d.window()) / dependencySlots
+	return now.UnixNano() / max(width, 1)
+}
+
+// Report records one call outcome; ReportDependency calls it for the
+// handler's tracker.
+func (d *Dependencies) Report(name string, ok bool, latency time.Duration) {
+	now := d.now()
+	d.mu.RLock()
+	l, found := d.deps[name]
+	d.mu.RUnlock()
+	if !found {
+		d.mu.Lock()
+		if d.deps == nil {
+			d.deps = make(map[string]*dependencyLog)
+		}
+		if l, found = d.deps[name]; !found {
+			l = &dependencyLog{}
+			d.deps[name] = l
+		}
+		d.mu.Unlock()
+	}
+
+	index := d.slotIndex(now)
+	bucket := sort.Search(len(dependencyLatencyBounds), func(i int) bool { return latency <= dependencyLatencyBounds[i] })
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	slot := &l.slots[index%dependencySlots]
+	if slot.index != index {
+		*slot = dependencySlot{index: index}
+	}
+	slot.calls++
+	slot.latencies[bucket]++
+	slot.max = max(slot.max, latency)
+	l.calls++
+	if !ok {
+		slot.failed++
+		l.failures++
+		l.lastFailure = now
+	}
+}
+
+// status fills the window and total fields of st from the slots still
+// inside the window ending in slot index.
+func (l *dependencyLog) status(st *DependencyStatus, index int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var latencies [len(dependencyLatencyBounds) + 1]int
+	var longest time.Duration
+	for i := range l.slots {
+		slot := &l.slots[i]
+		if slot.index <= index-dependencySlots || slot.index > index {
+			continue
+		}
+		st.WindowCalls += slot.calls
+		st.WindowFailed += slot.failed
+		for b, n := range slot.latencies {
+			latencies[b] += n
+		}
+		longest = max(longest, slot.max)
+	}
+	st.Calls, st.Failures = l.calls, l.failures
+	st.P50 = latencyPercentile(latencies[:], st.WindowCalls, longest, 0.50)
+	st.P99 = latencyPercentile(latencies[:], st.WindowCalls, longest, 0.99)
+	if !l.lastFailure.IsZero() {
+		at := l.lastFailure
+		st.LastFailureAt = &at
+	}
+}
+
+// latencyPercentile estimates the q-th latency of a histogram over
+// dependencyLatencyBounds (nearest rank, like percentile).
+func latencyPercentile(counts []int, total int, longest time.Duration, q float64) time.Duration {
+	if total == 0 {
+		return 0
+	}
+	rank := min(max(int(q*float64(total)+0.5), 1), total)
+	for b, n := range counts {
+		if rank -= n; rank <= 0 {
+			if b < len(dependencyLatencyBounds) {
+				return min(dependencyLatencyBounds[b], longest)
+			}
+			break
+		}
+	}
+	return longest
+}
+
+// Snapshot returns the status of every reported or critical dependency,
+// sorted by name. Critical dependencies never reported count as healthy.
+func (d *Dependencies) Snapshot() []DependencyStatus {
+	index := d.slotIndex(d.now())
+	d.mu.RLock()
+	logs := make(map[string]*dependencyLog, len(d.deps))
+	for name, l := range d.deps {
+		logs[name] = l
+	}
+	d.mu.RUnlock()
+	names := make([]string, 0, len(logs)+len(d.Critical))
+	for name := range logs {
+		names = append(names, name)
+	}
+	for _, name := range d.Critical {
+		if _, ok := logs[name]; !ok && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	stats := make([]DependencyStatus, 0, len(names))
+	for _, name := range names {
+		st := DependencyStatus{Name: name, Healthy: true, Critical: slices.Contains(d.Critical, name)}
+		if l, ok := logs[name]; ok {
+			l.status(&st, index)
+			st.Healthy = st.WindowCalls < d.minReports() ||
+				float64(st.WindowFailed) < d.failureRatio()*float64(st.WindowCalls)
+		}
+		stats = append(stats, st)
+	}
+	return stats
+}
+
+// Check is a ReadinessCheck failing while a Critical dependency is down;
+// Lifecycle runs it when Lifecycle.Dependencies is set.
+func (d *Dependencies) Check() ReadinessCheck {
+	return func(context.Context) error {
+		var down []string
+		for _, st := range d.Snapshot() {
+			if st.Critical && !st.Healthy {
+				down = append(down, fmt.Sprintf("%s (%d of %d calls failed)", st.Name, st.WindowFailed, st.WindowCalls))
+			}
+		}
+		if len(down) > 0 {
+			return fmt.Errorf("critical dependency down: %s", strings.Join(down, ", "))
+		}
+		return nil
+	}
+}
+
+// ServeHTTP writes the stats in the Prometheus text exposition format.
+func (d *Dependencies) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	stats := d.Snapshot()
+	fmt.Fprintln(w, "# TYPE seam_dependency_up gauge")
+	for _, s := range stats {
+		up := 0
+		if s.Healthy {
+			up = 1
+		}
+		fmt.Fprintf(w, "seam_dependency_up{name=%q,critical=\"%t\"} %d\n", s.Name, s.Critical, up)
+	}
+	fmt.Fprintln(w, "# TYPE seam_dependency_calls_total counter")
+	for _, s := range stats {
+		fmt.Fprintf(w, "seam_dependency_calls_total{name=%q,outcome=\"ok\"} %d\n", s.Name, s.Calls-s.Failures)
+		fmt.Fprintf(w, "seam_dependency_calls_total{name=%q,outcome=\"error\"} %d\n", s.Name, s.Failures)
+	}
+	fmt.Fprintln(w, "# TYPE seam_dependency_latency_seconds summary")
+	for _, s := range stats {
+		fmt.Fprintf(w, "seam_dependency_latency_seconds{name=%q,quantile=\"0.5\"} %g\n", s.Name, s.P50.Seconds())
+		fmt.Fprintf(w, "seam_dependency_latency_seconds{name=%q,quantile=\"0.99\"} %g\n", s.Name, s.P99.Seconds())
+	}
+}
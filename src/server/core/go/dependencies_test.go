@@ -0,0 +1,105 @@
+/* src/server/core/go/dependencies_test.go */
+
+package seam
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDependenciesHealth(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	d := &Dependencies{Critical: []string{"github", "postgres"}, MinReports: 4, nowFn: func() time.Time { return now }}
+	for range 3 {
+		d.Report("github", false, 200*time.Millisecond)
+	}
+	d.Report("github", true, 20*time.Millisecond)
+	d.Report("slack", false, time.Second)
+
+	stats := d.Snapshot()
+	if len(stats) != 3 || stats[0].Name != "github" || stats[1].Name != "postgres" || stats[2].Name != "slack" {
+		t.Fatalf("stats %+v", stats)
+	}
+	if gh := stats[0]; gh.Healthy || gh.WindowFailed != 3 || gh.P99 != 200*time.Millisecond || gh.LastFailureAt == nil {
+		t.Errorf("github %+v", gh)
+	}
+	if !stats[1].Healthy || !stats[2].Healthy { // never reported; below MinReports
+		t.Errorf("postgres/slack %+v", stats[1:])
+	}
+	err := d.Check()(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "github (3 of 4 calls failed)") {
+		t.Errorf("check: %v", err)
+	}
+
+	// Outcomes leave the window; totals stay.
+	now = now.Add(2 * time.Minute)
+	d.Report("github", true, 10*time.Millisecond)
+	if err := d.Check()(context.Background()); err != nil {
+		t.Errorf("check after recovery: %v", err)
+	}
+	if gh := d.Snapshot()[0]; !gh.Healthy || gh.WindowCalls != 1 || gh.Calls != 5 || gh.Failures != 3 {
+		t.Errorf("github after window %+v", gh)
+	}
+
+	w := httptest.NewRecorder()
+	d.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	for _, want := range []string{
+		`seam_dependency_up{name="github",critical="true"} 1`,
+		`seam_dependency_calls_total{name="github",outcome="error"} 3`,
+		`seam_dependency_latency_seconds{name="github",quantile="0.5"} 0.01`,
+	} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("metrics missing %s:\n%s", want, w.Body.String())
+		}
+	}
+}
+
+func TestDependenciesExpireBySlot(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	d := &Dependencies{nowFn: func() time.Time { return now }}
+	for range 1000 {
+		d.Report("db", true, 3*time.Millisecond)
+	}
+	now = now.Add(30 * time.Second)
+	d.Report("db", false, 45*time.Second)
+	st := d.Snapshot()[0]
+	if st.WindowCalls != 1001 || st.WindowFailed != 1 || st.P50 != 5*time.Millisecond || st.P99 != 5*time.Millisecond {
+		t.Fatalf("within window %+v", st)
+	}
+
+	// The first slot leaves the window, the later one stays
+	now = now.Add(35 * time.Second)
+	st = d.Snapshot()[0]
+	if st.WindowCalls != 1 || st.P50 != 45*time.Second || st.Calls != 1001 {
+		t.Fatalf("after first slot expired %+v", st)
+	}
+}
+
+func TestReportDependencyFromProcedure(t *testing.T) {
+	deps := &Dependencies{Critical: []string{"github"}, MinReports: 1}
+	h := NewRouter().Procedure(Query("repo", func(ctx context.Context, _ struct{}) (bool, error) {
+		ReportDependency(ctx, "github", false, 5*time.Millisecond)
+		return false, nil
+	})).Handler(HandlerOptions{Dependencies: deps})
+	if code, body := rpcBody(h, "/_seam/procedure/repo", `{}`, nil); code != http.StatusOK {
+		t.Fatalf("call: %d %s", code, body)
+	}
+
+	l := NewLifecycle()
+	l.Dependencies = deps
+	l.MarkLoaded()
+	w := httptest.NewRecorder()
+	l.ReadyHandler().ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+	body := w.Body.String()
+	if w.Code != http.StatusServiceUnavailable || !strings.Contains(body, "critical dependency down: github") ||
+		!strings.Contains(body, `"dependencies":[{"name":"github","healthy":false`) {
+		t.Errorf("readyz: %d %s", w.Code, body)
+	}
+
+	// Without a tracker, reporting is a no-op.
+	ReportDependency(context.Background(), "github", false, 0)
+}
@@ -0,0 +1,153 @@
+/* src/server/core/go/dependency_graph.go */
+
+package seam
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DependencyGraph shows which pages and layouts call which procedures and
+// which commands invalidate which queries, for impact analysis before
+// changing a procedure's schema. It marshals to JSON as is; DOT renders
+// it for Graphviz.
+type DependencyGraph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// GraphNode is a page, layout, or procedure. IDs are "<kind>:<name>".
+type GraphNode struct {
+	ID   string `json:"id"`
+	Kind string `json:"kind"` // "page" | "layout" | "query" | "command" | "stream" | "upload"
+	Name string `json:"name"`
+}
+
+// GraphEdge is a dependency: "loads" (page/layout -> procedure, Label is
+// the data key), "uses" (page -> layout), or "invalidates" (command ->
+// query).
+type GraphEdge struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Kind  string `json:"kind"`
+	Label string `json:"label,omitempty"`
+}
+
+// DependencyGraph builds the graph from the registered pages (with route
+// groups applied) and procedures. Loaders naming unregistered procedures
+// still produce a procedure node, with kind "query".
+func (r *Router) DependencyGraph() *DependencyGraph {
+	g := &DependencyGraph{}
+	nodes := make(map[string]GraphNode)
+	edges := make(map[GraphEdge]bool)
+	procKinds := make(map[string]string)
+
+	addNode := func(kind, name string) string {
+		id := kind + ":" + name
+		if kind != "page" && kind != "layout" {
+			id = "procedure:" + name
+		}
+		if _, ok := nodes[id]; !ok {
+			nodes[id] = GraphNode{ID: id, Kind: kind, Name: name}
+		}
+		return id
+	}
+	procNode := func(name string) string {
+		kind, ok := procKinds[name]
+		if !ok {
+			kind = "query"
+		}
+		return addNode(kind, name)
+	}
+
+	procs := r.Procedures()
+	for _, p := range procs {
+		procKinds[p.Name] = p.Kind
+		addNode(p.Kind, p.Name)
+	}
+	for _, p := range procs {
+		for _, target := range p.Invalidates {
+			edges[GraphEdge{From: procNode(p.Name), To: procNode(target.Query), Kind: "invalidates"}] = true
+		}
+	}
+
+	for _, page := range r.Pages() {
+		pageID := addNode("page", page.Route)
+		layoutOf := make(map[string]string)
+		for _, entry := range page.LayoutChain {
+			layoutID := addNode("layout", entry.ID)
+			edges[GraphEdge{From: pageID, To: layoutID, Kind: "uses"}] = true
+			for _, key := range entry.LoaderKeys {
+				layoutOf[key] = layoutID
+			}
+		}
+		for _, ld := range page.Loaders {
+			from := pageID
+			if layoutID, ok := layoutOf[ld.DataKey]; ok {
+				from = layoutID
+			}
+			edges[GraphEdge{From: from, To: procNode(ld.Procedure), Kind: "loads", Label: ld.DataKey}] = true
+		}
+	}
+
+	for _, n := range nodes {
+		g.Nodes = append(g.Nodes, n)
+	}
+	sort.Slice(g.Nodes, func(i, j int) bool { return g.Nodes[i].ID < g.Nodes[j].ID })
+	for e := range edges {
+		g.Edges = append(g.Edges, e)
+	}
+	sort.Slice(g.Edges, func(i, j int) bool {
+		a, b := g.Edges[i], g.Edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		return a.Label < b.Label
+	})
+	return g
+}
+
+// Dependents returns the IDs of nodes that depend on a procedure,
+// directly or through invalidation: the pages and layouts loading it and
+// the commands invalidating it.
+func (g *DependencyGraph) Dependents(procedure string) []string {
+	target := "procedure:" + procedure
+	var out []string
+	for _, e := range g.Edges {
+		if e.To == target && (e.Kind == "loads" || e.Kind == "invalidates") {
+			out = append(out, e.From)
+		}
+	}
+	return out
+}
+
+// DOT renders the graph in Graphviz DOT format.
+func (g *DependencyGraph) DOT() string {
+	shapes := map[string]string{"page": "box", "layout": "folder", "command": "diamond"}
+	var b strings.Builder
+	b.WriteString("digraph seam {\n\trankdir=LR;\n")
+	for _, n := range g.Nodes {
+		shape, ok := shapes[n.Kind]
+		if !ok {
+			shape = "ellipse"
+		}
+		fmt.Fprintf(&b, "\t%q [label=%q, shape=%s];\n", n.ID, n.Name, shape)
+	}
+	for _, e := range g.Edges {
+		label := e.Kind
+		if e.Label != "" {
+			label += " " + e.Label
+		}
+		style := ""
+		if e.Kind == "invalidates" {
+			style = ", style=dashed"
+		}
+		fmt.Fprintf(&b, "\t%q -> %q [label=%q%s];\n", e.From, e.To, label, style)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
@@ -0,0 +1,57 @@
+/* src/server/core/go/dependency_graph_test.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func graphRouter() *Router {
+	getPost := Query("getPost", func(context.Context, struct{}) (string, error) { return "", nil })
+	getSession := Query("getSession", func(context.Context, struct{}) (string, error) { return "", nil })
+	editPost := Command("editPost", func(context.Context, struct{}) (bool, error) { return true, nil },
+		WithInvalidates(InvalidateTarget{Query: "getPost"}))
+	empty := func(map[string]string) any { return map[string]any{} }
+	return NewRouter().
+		Procedure(getPost).Procedure(getSession).Procedure(editPost).
+		Page(&PageDef{
+			Route: "/posts/:id",
+			Loaders: []LoaderDef{
+				{DataKey: "session", Procedure: "getSession", InputFn: empty},
+				{DataKey: "post", Procedure: "getPost", InputFn: empty},
+			},
+			LayoutChain: []LayoutChainEntry{{ID: "root", LoaderKeys: []string{"session"}}},
+		})
+}
+
+func TestDependencyGraph(t *testing.T) {
+	g := graphRouter().DependencyGraph()
+	edges, _ := json.Marshal(g.Edges)
+	want := `[{"from":"layout:root","to":"procedure:getSession","kind":"loads","label":"session"},` +
+		`{"from":"page:/posts/:id","to":"layout:root","kind":"uses"},` +
+		`{"from":"page:/posts/:id","to":"procedure:getPost","kind":"loads","label":"post"},` +
+		`{"from":"procedure:editPost","to":"procedure:getPost","kind":"invalidates"}]`
+	if string(edges) != want {
+		t.Fatalf("edges:\n%s\nwant:\n%s", edges, want)
+	}
+	if len(g.Nodes) != 5 {
+		t.Fatalf("unexpected nodes %+v", g.Nodes)
+	}
+	if got := g.Dependents("getPost"); !reflect.DeepEqual(got, []string{"page:/posts/:id", "procedure:editPost"}) {
+		t.Fatalf("dependents = %v", got)
+	}
+
+	dot := g.DOT()
+	for _, line := range []string{
+		`"procedure:editPost" [label="editPost", shape=diamond];`,
+		`"procedure:editPost" -> "procedure:getPost" [label="invalidates", style=dashed];`,
+	} {
+		if !strings.Contains(dot, line) {
+			t.Fatalf("DOT missing %q:\n%s", line, dot)
+		}
+	}
+}
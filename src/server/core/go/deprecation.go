@@ -0,0 +1,151 @@
+/* src/server/core/go/deprecation.go */
+
+package seam
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Calls to a procedure marked WithDeprecated get a Warning header (299,
+// RFC 9111 section 5.5) and the notice in the envelope's meta.warnings,
+// and are counted per client by HandlerOptions.DeprecatedCalls.
+
+// resultMeta is the optional "meta" member of a success envelope.
+type resultMeta struct {
+	Warnings       []string `json:"warnings,omitempty"`
+	PollIntervalMs int64    `json:"pollIntervalMs,omitempty"` // poll responses
+	Canary         string   `json:"canary,omitempty"`         // variant of a Router.Canary procedure
+}
+
+// DeprecatedCalls counts calls to deprecated procedures per client, so
+// teams can tell who still depends on an endpoint before retiring it.
+// Serve it (it is an http.Handler) on an internal port for
+// Prometheus-style scraping. Client names come from requests, so only
+// the first MaxClients per procedure get their own count (and metric
+// label); later clients are counted together as OtherClients.
+type DeprecatedCalls struct {
+	// Client identifies the caller (e.g. an SDK name and version header);
+	// default: the principal, else the client IP.
+	Client func(r *http.Request) string
+	// MaxClients caps the clients counted apart per procedure (default 100).
+	MaxClients int
+
+	mu      sync.Mutex
+	calls   map[deprecatedKey]*DeprecatedCallStats
+	clients map[string]int // procedure -> clients counted apart
+}
+
+// OtherClients is the client of calls beyond DeprecatedCalls.MaxClients.
+const OtherClients = "(other)"
+
+func (d *DeprecatedCalls) maxClients() int {
+	if d.MaxClients > 0 {
+		return d.MaxClients
+	}
+	return 100
+}
+
+type deprecatedKey struct{ procedure, client string }
+
+// DeprecatedCallStats counts one client's calls to one deprecated procedure.
+type DeprecatedCallStats struct {
+	Procedure string    `json:"procedure"`
+	Client    string    `json:"client"`
+	Calls     int64     `json:"calls"`
+	LastCall  time.Time `json:"lastCall"`
+}
+
+func (d *DeprecatedCalls) record(procedure, client string) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.calls == nil {
+		d.calls = make(map[deprecatedKey]*DeprecatedCallStats)
+		d.clients = make(map[string]int)
+	}
+	key := deprecatedKey{procedure, client}
+	st, ok := d.calls[key]
+	if !ok && d.clients[procedure] >= d.maxClients() {
+		key.client = OtherClients
+		st, ok = d.calls[key]
+	}
+	if !ok {
+		st = &DeprecatedCallStats{Procedure: procedure, Client: key.client}
+		d.calls[key] = st
+		if key.client != OtherClients {
+			d.clients[procedure]++
+		}
+	}
+	st.Calls++
+	st.LastCall = time.Now()
+}
+
+// Snapshot returns the counts sorted by procedure, then client.
+func (d *DeprecatedCalls) Snapshot() []DeprecatedCallStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	stats := make([]DeprecatedCallStats, 0, len(d.calls))
+	for _, st := range d.calls {
+		stats = append(stats, *st)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Procedure != stats[j].Procedure {
+			return stats[i].Procedure < stats[j].Procedure
+		}
+		return stats[i].Client < stats[j].Client
+	})
+	return stats
+}
+
+// ServeHTTP writes the counts in the Prometheus text exposition format.
+func (d *DeprecatedCalls) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	fmt.Fprintln(w, "# TYPE seam_deprecated_calls_total counter")
+	for _, st := range d.Snapshot() {
+		fmt.Fprintf(w, "seam_deprecated_calls_total{procedure=%q,client=%q} %d\n", st.Procedure, st.Client, st.Calls)
+	}
+}
+
+// deprecationWarning returns the warning text of a deprecated procedure
+// and counts the call; "" when the procedure is not deprecated.
+func (s *appState) deprecationWarning(r *http.Request, name string, proc *ProcedureDef) string {
+	dep := proc.Deprecated
+	if dep == nil {
+		return ""
+	}
+	if d := s.opts.DeprecatedCalls; d != nil {
+		d.record(name, s.deprecationClient(d, r))
+	}
+	msg := fmt.Sprintf("Procedure '%s' is deprecated", name)
+	if dep.Message != "" {
+		msg += ": " + dep.Message
+	}
+	if dep.Replacement != "" {
+		msg += fmt.Sprintf(" (use '%s')", dep.Replacement)
+	}
+	return msg
+}
+
+func (s *appState) deprecationClient(d *DeprecatedCalls, r *http.Request) string {
+	if d.Client != nil {
+		return d.Client(r)
+	}
+	if s.opts.Principal != nil {
+		if p := s.opts.Principal(r); p != "" {
+			return p
+		}
+	}
+	return "ip:" + ClientIP(r.Context())
+}
+
+// addWarningHeader appends a 299 Warning header carrying msg.
+func addWarningHeader(w http.ResponseWriter, msg string) {
+	w.Header().Add("Warning", "299 - "+strconv.Quote(msg))
+}
@@ -0,0 +1,88 @@
+/* src/server/core/go/deprecation_test.go */
+
+package seam
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func deprecationHandler(calls *DeprecatedCalls) http.Handler {
+	get := func(context.Context, struct{}) (string, error) { return "ok", nil }
+	return NewRouter().
+		Procedure(Query("getUser", get, WithDeprecated("returns the v1 shape", "getUser@v2"))).
+		Procedure(Query("getUser", get, WithVersion("v2"))).
+		Procedure(Query("getRepos", get)).
+		RpcHashMap(&RpcHashMap{Batch: "_batch", Procedures: map[string]string{"getUser": "getUser", "getRepos": "getRepos"}}).
+		Handler(HandlerOptions{
+			DeprecatedCalls: calls,
+			Principal:       func(r *http.Request) string { return r.Header.Get("X-Api-Key") },
+		})
+}
+
+func TestDeprecationWarning(t *testing.T) {
+	h := deprecationHandler(nil)
+	const warning = `Procedure 'getUser' is deprecated: returns the v1 shape (use 'getUser@v2')`
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/_seam/procedure/getUser", strings.NewReader(`{}`)))
+	if got := w.Header().Get("Warning"); got != `299 - "`+warning+`"` {
+		t.Errorf("Warning header %q", got)
+	}
+	if want := `{"data":"ok","meta":{"warnings":["` + warning + `"]},"ok":true}`; strings.TrimSpace(w.Body.String()) != want {
+		t.Errorf("body %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/_seam/procedure/getRepos", strings.NewReader(`{}`)))
+	if w.Header().Get("Warning") != "" || strings.Contains(w.Body.String(), "meta") {
+		t.Errorf("non-deprecated call warned: %v %s", w.Header(), w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	body := `{"calls":[{"procedure":"getUser","input":{}},{"procedure":"getUser","input":{}},{"procedure":"getRepos","input":{}}]}`
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/_seam/procedure/_batch", strings.NewReader(body)))
+	if got := w.Header().Values("Warning"); len(got) != 1 {
+		t.Errorf("batch Warning headers %q, want one", got)
+	}
+	if strings.Count(w.Body.String(), `"meta":{"warnings":["`+warning+`"]}`) != 2 {
+		t.Errorf("batch body %s", w.Body.String())
+	}
+}
+
+func TestDeprecatedCallsPerClient(t *testing.T) {
+	calls := &DeprecatedCalls{}
+	h := deprecationHandler(calls)
+	for _, key := range []string{"mobile", "mobile", ""} {
+		req := httptest.NewRequest(http.MethodPost, "/_seam/procedure/getUser", strings.NewReader(`{}`))
+		req.Header.Set("X-Api-Key", key)
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	// the versioned replacement is not deprecated
+	req := httptest.NewRequest(http.MethodPost, "/_seam/procedure/getUser@v2", strings.NewReader(`{}`))
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	stats := calls.Snapshot()
+	if len(stats) != 2 || stats[0].Client != "ip:192.0.2.1" || stats[0].Calls != 1 || stats[1].Client != "mobile" || stats[1].Calls != 2 {
+		t.Fatalf("stats %+v", stats)
+	}
+	w := httptest.NewRecorder()
+	calls.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if !strings.Contains(w.Body.String(), `seam_deprecated_calls_total{procedure="getUser",client="mobile"} 2`) {
+		t.Errorf("metrics %s", w.Body.String())
+	}
+}
+
+func TestDeprecatedCallsCapClients(t *testing.T) {
+	calls := &DeprecatedCalls{MaxClients: 2}
+	for _, client := range []string{"a", "b", "c", "d", "a"} {
+		calls.record("getUser", client)
+	}
+	stats := calls.Snapshot()
+	if len(stats) != 3 || stats[0].Client != OtherClients || stats[0].Calls != 2 || stats[1].Client != "a" || stats[1].Calls != 2 {
+		t.Fatalf("stats %+v", stats)
+	}
+}
@@ -0,0 +1,104 @@
+/* src/server/core/go/dispatch.go */
+
+package seam
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// procedureContext injects proc's context fields from rawCtx (extracted
+// from the request) and the app state into ctx.
+func (s *appState) procedureContext(ctx context.Context, rawCtx map[string]any, proc *ProcedureDef) context.Context {
+	if rawCtx != nil && len(proc.ContextKeys) > 0 {
+		ctx = injectContext(ctx, resolveContextForProc(rawCtx, proc.ContextKeys))
+	}
+	return injectState(ctx, s.appState)
+}
+
+// dispatch runs one resolved call of proc. Every transport goes through
+// it (HTTP RPC, batch calls, the /_seam/ws socket, channel sockets), so
+// IP filters (by resolved name), dry runs, input validation, rate limits,
+// quotas, and locks apply the same everywhere, as do the effects of a
+// call: every call that reaches the handler is charged to the quota, and
+// a successful one records examples, publishes invalidations, and
+// appends to the event store. ctx carries the request's principal,
+// context fields, state, and deadline; r supplies the dry-run header.
+// Errors get the HandlerOptions.DefaultRetryAfter hint.
+func (s *appState) dispatch(ctx context.Context, r *http.Request, name string, proc *ProcedureDef, input []byte) (any, *Error) {
+	result, seamErr := s.dispatchCall(ctx, r, name, proc, input)
+	return result, s.withRetryHint(seamErr)
+}
+
+func (s *appState) dispatchCall(ctx context.Context, r *http.Request, name string, proc *ProcedureDef, input []byte) (any, *Error) {
+	input, dryRun, dryErr := takeDryRun(r, input)
+	if dryErr != nil {
+		return nil, dryErr
+	}
+	if dryRun {
+		ctx = context.WithValue(ctx, dryRunKey, true)
+	}
+
+	// Schemas are compiled when validation is on, and always for
+	// procedures rejecting unknown fields.
+	if cs, ok := s.compiledInputSchemas[name]; ok {
+		var parsed any
+		_ = codecUnmarshal(input, &parsed)
+		if msg, details := validateCompiled(cs, parsed); msg != "" {
+			return nil, ValidationErrorDetailed(
+				fmt.Sprintf("Input validation failed for procedure '%s': %s", name, msg), toAnySlice(details))
+		}
+	}
+
+	class := RouteQuery
+	if proc.Type == "command" {
+		class = RouteCommand
+	}
+	if filterErr := s.checkIPFilters(ctx, class, name); filterErr != nil {
+		return nil, filterErr
+	}
+	if rateErr := s.opts.Tuning.checkRate(ctx); rateErr != nil {
+		return nil, rateErr
+	}
+	if rateErr := s.opts.RateLimits.check(ctx, procedureClass(proc)); rateErr != nil {
+		return nil, rateErr
+	}
+	if quotaErr := s.opts.Quota.check(ctx); quotaErr != nil {
+		return nil, quotaErr
+	}
+
+	if proc.Lock != "" {
+		release, lockErr := s.acquireLock(ctx, proc)
+		if lockErr != nil {
+			return nil, lockErr
+		}
+		defer release()
+	}
+
+	result, err := proc.Handler(ctx, input)
+	if err != nil {
+		s.opts.Quota.charge(ctx, input, nil)
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, rpcTimeoutError()
+		}
+		if seamErr, ok := err.(*Error); ok {
+			return nil, seamErr
+		}
+		return nil, InternalError(err.Error())
+	}
+	s.opts.Quota.charge(ctx, input, result)
+	if dryRun {
+		return result, nil
+	}
+	if s.opts.Examples != nil {
+		s.opts.Examples.record(name, input, result)
+	}
+	if len(proc.InvalidateTargets) > 0 {
+		publishInvalidation(s.hub, invalidationKeys(proc.InvalidateTargets, input))
+	}
+	if s.opts.EventStore != nil && proc.Type == "command" {
+		s.recordCommand(ctx, name, input, result)
+	}
+	return result, nil
+}
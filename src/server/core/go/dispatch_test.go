@@ -0,0 +1,121 @@
+/* src/server/core/go/dispatch_test.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// opsRouter registers proc next to an "ops" channel, so a proc named
+// "ops.<command>" is reachable over HTTP RPC, batches, and the channel
+// socket. Batches use the "_batch" hash with identity names.
+func opsRouter(procs ...*ProcedureDef) *Router {
+	names := map[string]string{}
+	r := NewRouter().Channel(ChannelDef{
+		Name:        "ops",
+		InputSchema: map[string]any{"properties": map[string]any{}},
+		Outgoing:    map[string]any{},
+		SubscribeHandler: func(ctx context.Context, _ json.RawMessage) (<-chan SubscriptionEvent, error) {
+			ch := make(chan SubscriptionEvent)
+			go func() { <-ctx.Done(); close(ch) }()
+			return ch, nil
+		},
+	})
+	for _, p := range procs {
+		r.Procedure(p)
+		names[p.Name] = p.Name
+	}
+	names["ops.events"] = "ops.events"
+	return r.RpcHashMap(&RpcHashMap{Batch: "_batch", Procedures: names})
+}
+
+// callTransports calls name with input over HTTP RPC, a one-call batch,
+// and the ops channel socket, returning each transport's error code
+// ("" on success) keyed "http", "batch", and "channel".
+func callTransports(t *testing.T, h http.Handler, name, input string, header http.Header) map[string]string {
+	t.Helper()
+	codes := map[string]string{}
+	errorCode := func(body string) string {
+		var resp struct {
+			Error *struct {
+				Code string `json:"code"`
+			} `json:"error"`
+		}
+		_ = json.Unmarshal([]byte(body), &resp)
+		if resp.Error == nil {
+			return ""
+		}
+		return resp.Error.Code
+	}
+
+	_, body := rpcBody(h, "/_seam/procedure/"+name, input, header)
+	codes["http"] = errorCode(body)
+
+	_, body = rpcBody(h, "/_seam/procedure/_batch", `{"calls":[{"procedure":"`+name+`","input":`+input+`}]}`, header)
+	var batch struct {
+		Data struct {
+			Results []json.RawMessage `json:"results"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(body), &batch); err != nil || len(batch.Data.Results) != 1 {
+		t.Fatalf("batch response %s", body)
+	}
+	codes["batch"] = errorCode(string(batch.Data.Results[0]))
+
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/_seam/procedure/ops.events?input={}", header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if err := conn.WriteJSON(map[string]any{"id": "1", "procedure": name, "input": json.RawMessage(input)}); err != nil {
+		t.Fatal(err)
+	}
+	for {
+		_, raw, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var frame struct {
+			ID string `json:"id"`
+		}
+		if json.Unmarshal(raw, &frame) == nil && frame.ID == "1" {
+			codes["channel"] = errorCode(string(raw))
+			return codes
+		}
+	}
+}
+
+func TestDryRunOnEveryTransport(t *testing.T) {
+	saved := 0
+	h := opsRouter(Command("ops.save", func(ctx context.Context, _ struct{}) (string, error) {
+		return "saved", SideEffect(ctx, func() error {
+			saved++
+			return nil
+		})
+	})).Handler(HandlerOptions{HeartbeatInterval: time.Minute})
+
+	codes := callTransports(t, h, "ops.save", `{"__dryRun":true}`, nil)
+	for transport, code := range codes {
+		if code != "" {
+			t.Errorf("%s: %s", transport, code)
+		}
+	}
+	if saved != 0 {
+		t.Fatalf("dry runs saved %d times", saved)
+	}
+	callTransports(t, h, "ops.save", `{}`, nil)
+	if saved != 3 {
+		t.Fatalf("real calls saved %d times, want 3", saved)
+	}
+}
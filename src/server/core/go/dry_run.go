@@ -0,0 +1,61 @@
+/* src/server/core/go/dry_run.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+const (
+	// dryRunInputKey is the reserved input key requesting a dry run.
+	dryRunInputKey = "__dryRun"
+	// dryRunHeader requests a dry run for every call of the request.
+	dryRunHeader = "X-Seam-Dry-Run"
+)
+
+type dryRunKeyType struct{}
+
+var dryRunKey = dryRunKeyType{}
+
+// DryRun reports whether the call is a dry run: the command should
+// validate and authorize as usual but skip side effects, returning a
+// preview of its result. Invalidations and event store records are
+// skipped for dry runs.
+func DryRun(ctx context.Context) bool {
+	v, _ := ctx.Value(dryRunKey).(bool)
+	return v
+}
+
+// SideEffect runs fn unless the call is a dry run.
+func SideEffect(ctx context.Context, fn func() error) error {
+	if DryRun(ctx) {
+		return nil
+	}
+	return fn()
+}
+
+// SideEffectValue returns fn's result, or preview's on a dry run.
+func SideEffectValue[T any](ctx context.Context, preview T, fn func() (T, error)) (T, error) {
+	if DryRun(ctx) {
+		return preview, nil
+	}
+	return fn()
+}
+
+// takeDryRun strips the reserved __dryRun key from the input and reports
+// whether the key or the X-Seam-Dry-Run header requested a dry run.
+func takeDryRun(r *http.Request, input []byte) ([]byte, bool, *Error) {
+	rest, raw := takeReservedKey(input, dryRunInputKey)
+	if raw != nil {
+		var dry bool
+		if err := json.Unmarshal(raw, &dry); err != nil {
+			return nil, false, ValidationError(dryRunInputKey + " must be a boolean")
+		}
+		return rest, dry, nil
+	}
+	dry, _ := strconv.ParseBool(r.Header.Get(dryRunHeader))
+	return input, dry, nil
+}
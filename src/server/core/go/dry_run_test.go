@@ -0,0 +1,59 @@
+/* src/server/core/go/dry_run_test.go */
+
+package seam
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+type renameInput struct {
+	Name string `json:"name"`
+}
+
+func renameRouter(saved *[]string) *Router {
+	return NewRouter().Procedure(Command("renameProject", func(ctx context.Context, in renameInput) (string, error) {
+		if in.Name == "" {
+			return "", ValidationError("name required")
+		}
+		err := SideEffect(ctx, func() error {
+			*saved = append(*saved, in.Name)
+			return nil
+		})
+		return "renamed to " + in.Name, err
+	}, WithInvalidates(InvalidateTarget{Query: "getProject"})))
+}
+
+func TestDryRunSkipsSideEffects(t *testing.T) {
+	var saved []string
+	store := NewMemoryEventStore()
+	h := renameRouter(&saved).Handler(HandlerOptions{EventStore: store})
+
+	code, body := rpcBody(h, "/_seam/procedure/renameProject", `{"name":"seam","__dryRun":true}`, nil)
+	if code != http.StatusOK || body != `{"data":"renamed to seam","ok":true}` {
+		t.Fatalf("got %d %s", code, body)
+	}
+	code, _ = rpcBody(h, "/_seam/procedure/renameProject", `{"name":"seam"}`, http.Header{dryRunHeader: {"1"}})
+	if code != http.StatusOK || len(saved) != 0 || len(store.Events()) != 0 {
+		t.Fatalf("dry runs had side effects: saved=%v events=%d", saved, len(store.Events()))
+	}
+
+	// Validation still runs on a dry run
+	if code, _ := rpcBody(h, "/_seam/procedure/renameProject", `{"name":"","__dryRun":true}`, nil); code != http.StatusBadRequest {
+		t.Fatalf("expected validation failure, got %d", code)
+	}
+
+	rpcBody(h, "/_seam/procedure/renameProject", `{"name":"seam","__dryRun":false}`, nil)
+	if len(saved) != 1 || len(store.Events()) != 1 {
+		t.Fatalf("real call skipped side effects: saved=%v", saved)
+	}
+}
+
+func TestDryRunRejectsNonBoolean(t *testing.T) {
+	var saved []string
+	h := renameRouter(&saved).Handler(HandlerOptions{})
+	if code, body := rpcBody(h, "/_seam/procedure/renameProject", `{"name":"x","__dryRun":"yes"}`, nil); code != http.StatusBadRequest {
+		t.Fatalf("got %d %s", code, body)
+	}
+}
@@ -0,0 +1,153 @@
+/* src/server/core/go/emitter.go */
+
+package seam
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// Emitter produces channel events in the {"type","payload"} tagged union
+// format from a struct describing the outgoing events: each exported field
+// is one event, named by its json tag, with the field type as payload type.
+//
+//	type ChatEvents struct {
+//		NewMessage Message `json:"newMessage"`
+//		Typing     Typing  `json:"typing"`
+//	}
+type Emitter[E any] struct {
+	events   chan SubscriptionEvent
+	payloads map[string]reflect.Type
+}
+
+// NewEmitter creates an emitter whose Events channel buffers up to buffer
+// events. Panics when E is not a struct.
+func NewEmitter[E any](buffer int) *Emitter[E] {
+	return &Emitter[E]{
+		events:   make(chan SubscriptionEvent, buffer),
+		payloads: eventPayloadTypes[E](),
+	}
+}
+
+// OutgoingOf returns the ChannelDef.Outgoing schemas for an events struct,
+// keeping channel schemas and emitted payloads in sync.
+func OutgoingOf[E any]() map[string]any {
+	outgoing := make(map[string]any)
+	for name, t := range eventPayloadTypes[E]() {
+		if t.Kind() != reflect.Ptr {
+			outgoing[name] = schemaFor(t)
+			continue
+		}
+		// Pointer payloads are nullable, matching struct field handling
+		schema := schemaFor(t.Elem())
+		if m, ok := schema.(map[string]any); ok {
+			m["nullable"] = true
+		}
+		outgoing[name] = schema
+	}
+	return outgoing
+}
+
+func eventPayloadTypes[E any]() map[string]reflect.Type {
+	t := reflect.TypeFor[E]()
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("emitter events type %s must be a struct", t))
+	}
+	types := make(map[string]reflect.Type, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _ := jsonFieldName(&field)
+		if name == "-" {
+			continue
+		}
+		types[name] = field.Type
+	}
+	return types
+}
+
+// Events returns the stream to hand back from a SubscribeHandler.
+func (e *Emitter[E]) Events() <-chan SubscriptionEvent {
+	return e.events
+}
+
+// Emit sends one event, blocking until it is queued or ctx is done. The
+// payload must be assignable to the event's field type in E.
+func (e *Emitter[E]) Emit(ctx context.Context, event string, payload any) error {
+	want, ok := e.payloads[event]
+	if !ok {
+		return fmt.Errorf("emitter: unknown event %q", event)
+	}
+	if payload == nil {
+		if !isNilable(want) {
+			return fmt.Errorf("emitter: event %q requires a %s payload, got nil", event, want)
+		}
+	} else if got := reflect.TypeOf(payload); !got.AssignableTo(want) {
+		return fmt.Errorf("emitter: event %q requires a %s payload, got %s", event, want, got)
+	}
+	return e.send(ctx, event, payload)
+}
+
+func (e *Emitter[E]) send(ctx context.Context, event string, payload any) error {
+	ev := SubscriptionEvent{Value: map[string]any{"type": event, "payload": payload}}
+	select {
+	case e.events <- ev:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// EmitterEvent is a typed handle on one event of an Emitter, so payloads
+// are checked at compile time rather than by Emit at run time:
+//
+//	newMessage := seam.EventOf[ChatEvents, Message](em, "newMessage")
+//	err := newMessage.Emit(ctx, Message{Text: "hi"})
+type EmitterEvent[E, P any] struct {
+	emitter *Emitter[E]
+	name    string
+}
+
+// EventOf returns the typed handle of event name. Panics when E has no
+// such event or P is not assignable to the event's field type.
+func EventOf[E, P any](e *Emitter[E], name string) EmitterEvent[E, P] {
+	want, ok := e.payloads[name]
+	if !ok {
+		panic(fmt.Sprintf("emitter: unknown event %q", name))
+	}
+	if got := reflect.TypeFor[P](); !got.AssignableTo(want) {
+		panic(fmt.Sprintf("emitter: event %q requires a %s payload, got %s", name, want, got))
+	}
+	return EmitterEvent[E, P]{emitter: e, name: name}
+}
+
+// Emit sends the event, blocking until it is queued or ctx is done.
+func (ev EmitterEvent[E, P]) Emit(ctx context.Context, payload P) error {
+	return ev.emitter.send(ctx, ev.name, payload)
+}
+
+// Fail sends an error event to the subscriber.
+func (e *Emitter[E]) Fail(ctx context.Context, err *Error) error {
+	select {
+	case e.events <- SubscriptionEvent{Err: err}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close ends the event stream. Emit must not be called afterwards.
+func (e *Emitter[E]) Close() {
+	close(e.events)
+}
+
+func isNilable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return true
+	}
+	return false
+}
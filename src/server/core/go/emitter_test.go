@@ -0,0 +1,106 @@
+/* src/server/core/go/emitter_test.go */
+
+package seam
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+type emitterTestMessage struct {
+	Text string `json:"text"`
+}
+
+type emitterTestEvents struct {
+	NewMessage emitterTestMessage  `json:"newMessage"`
+	Typing     *emitterTestMessage `json:"typing"`
+	Ignored    string              `json:"-"`
+}
+
+func TestEmitterEmitsTaggedUnion(t *testing.T) {
+	em := NewEmitter[emitterTestEvents](2)
+	ctx := context.Background()
+	if err := em.Emit(ctx, "newMessage", emitterTestMessage{Text: "hi"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := em.Emit(ctx, "typing", nil); err != nil {
+		t.Fatal(err)
+	}
+	em.Close()
+
+	var got []any
+	for ev := range em.Events() {
+		got = append(got, ev.Value)
+	}
+	want := []any{
+		map[string]any{"type": "newMessage", "payload": emitterTestMessage{Text: "hi"}},
+		map[string]any{"type": "typing", "payload": nil},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v", got)
+	}
+}
+
+func TestEmitterRejectsUnknownEventAndWrongPayload(t *testing.T) {
+	em := NewEmitter[emitterTestEvents](1)
+	ctx := context.Background()
+	if err := em.Emit(ctx, "Ignored", "x"); err == nil {
+		t.Fatal("expected error for unknown event")
+	}
+	if err := em.Emit(ctx, "newMessage", "not a message"); err == nil {
+		t.Fatal("expected error for wrong payload type")
+	}
+	if err := em.Emit(ctx, "newMessage", nil); err == nil {
+		t.Fatal("expected error for nil non-pointer payload")
+	}
+}
+
+func TestEventOfEmitsTypedPayloads(t *testing.T) {
+	em := NewEmitter[emitterTestEvents](1)
+	newMessage := EventOf[emitterTestEvents, emitterTestMessage](em, "newMessage")
+	if err := newMessage.Emit(context.Background(), emitterTestMessage{Text: "hi"}); err != nil {
+		t.Fatal(err)
+	}
+	got := (<-em.Events()).Value
+	want := map[string]any{"type": "newMessage", "payload": emitterTestMessage{Text: "hi"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v", got)
+	}
+
+	for _, build := range []func(){
+		func() { EventOf[emitterTestEvents, string](em, "Ignored") },
+		func() { EventOf[emitterTestEvents, string](em, "newMessage") },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected panic")
+				}
+			}()
+			build()
+		}()
+	}
+}
+
+func TestOutgoingOfMatchesSchemaOf(t *testing.T) {
+	outgoing := OutgoingOf[emitterTestEvents]()
+	if len(outgoing) != 2 {
+		t.Fatalf("expected two events, got %v", outgoing)
+	}
+	if !reflect.DeepEqual(outgoing["newMessage"], SchemaOf[emitterTestMessage]()) {
+		t.Fatalf("unexpected schema: %v", outgoing["newMessage"])
+	}
+	if m, _ := outgoing["typing"].(map[string]any); m["nullable"] != true {
+		t.Fatalf("expected nullable schema for pointer payload: %v", outgoing["typing"])
+	}
+}
+
+func TestNewEmitterPanicsOnNonStruct(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic")
+		}
+	}()
+	NewEmitter[string](0)
+}
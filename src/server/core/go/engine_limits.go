@@ -0,0 +1,52 @@
+/* src/server/core/go/engine_limits.go */
+
+package seam
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	engine "github.com/canmi21/seam/src/server/engine/go"
+)
+
+// EngineLimits bounds each page render in the WASM engine (wall-clock
+// Timeout, linear-memory MaxMemoryBytes, MaxCalls budget); see
+// HandlerOptions.EngineLimits. Zero fields are unlimited.
+type EngineLimits = engine.Limits
+
+// EngineBudgetStats counts engine calls stopped by each limit.
+type EngineBudgetStats = engine.BudgetStats
+
+// EngineBudgetHits returns the process-wide count of engine calls stopped
+// by a limit, for metrics.
+func EngineBudgetHits() EngineBudgetStats {
+	return engine.BudgetHits()
+}
+
+// isEngineBudgetError reports whether a render failed on an engine limit.
+func isEngineBudgetError(err error) bool {
+	return errors.Is(err, engine.ErrTimeout) || errors.Is(err, engine.ErrMemoryLimit) || errors.Is(err, engine.ErrCallLimit)
+}
+
+// renderContext detaches the render from the page deadline, which bounds
+// loaders (an error boundary still renders after a loader timeout), and
+// applies the handler's engine limits.
+func (s *appState) renderContext(ctx context.Context) context.Context {
+	ctx = context.WithoutCancel(ctx)
+	if s.opts.EngineLimits != (EngineLimits{}) {
+		ctx = engine.WithLimits(ctx, s.opts.EngineLimits)
+	}
+	return ctx
+}
+
+// renderError maps a failed render to the client error: engine limit
+// breaches get a clean message with the detail only in the log.
+func renderError(route string, err error) *Error {
+	if isEngineBudgetError(err) {
+		logf(slog.LevelWarn, "page %s render stopped: %v\n", route, err)
+		return InternalError("Page render exceeded engine limits")
+	}
+	return InternalError(fmt.Sprintf("Page render failed: %v", err))
+}
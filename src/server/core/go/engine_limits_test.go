@@ -0,0 +1,72 @@
+/* src/server/core/go/engine_limits_test.go */
+
+package seam
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	engine "github.com/canmi21/seam/src/server/engine/go"
+)
+
+func engineLimitsRouter(payload string) *Router {
+	return NewRouter().
+		Procedure(Query("getBody", func(context.Context, struct{}) (string, error) { return payload, nil })).
+		Page(&PageDef{
+			Route:    "/doc",
+			Template: "<html><body><!--seam:body--></body></html>",
+			Loaders:  []LoaderDef{{DataKey: "body", Procedure: "getBody", InputFn: func(map[string]string) any { return map[string]any{} }}},
+		})
+}
+
+func TestEngineLimitsBreachReturnsInternalError(t *testing.T) {
+	before := EngineBudgetHits()
+	handler := engineLimitsRouter("hello").Handler(HandlerOptions{EngineLimits: EngineLimits{MaxCalls: 10}})
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/_seam/page/doc", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status %d, want 500", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "INTERNAL_ERROR") || !strings.Contains(w.Body.String(), "Page render exceeded engine limits") {
+		t.Fatalf("unexpected body %s", w.Body.String())
+	}
+	if after := EngineBudgetHits(); after.CallLimits != before.CallLimits+1 {
+		t.Fatalf("call limit hits %d -> %d", before.CallLimits, after.CallLimits)
+	}
+}
+
+func TestEngineLimitsWithinBudgetRenders(t *testing.T) {
+	handler := engineLimitsRouter("hello").Handler(HandlerOptions{EngineLimits: EngineLimits{
+		Timeout:        5 * time.Second,
+		MaxMemoryBytes: 64 << 20,
+		MaxCalls:       10_000_000,
+	}})
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/_seam/page/doc", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "hello") {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestEngineMemoryLimitStopsHugePayload(t *testing.T) {
+	ctx := engine.WithLimits(context.Background(), EngineLimits{MaxMemoryBytes: 4 << 20})
+	data := `{"body":"` + strings.Repeat("x", 8<<20) + `"}`
+	_, err := engine.RenderPageContext(ctx, "<p><!--seam:body--></p>", data, `{"layout_chain":[],"data_id":"__data"}`, "")
+	if !errors.Is(err, engine.ErrMemoryLimit) {
+		t.Fatalf("expected memory limit error, got %v", err)
+	}
+}
+
+func TestEngineTimeout(t *testing.T) {
+	ctx := engine.WithLimits(context.Background(), EngineLimits{Timeout: time.Nanosecond})
+	_, err := engine.RenderPageContext(ctx, "<p><!--seam:body--></p>", `{"body":"x"}`, `{"layout_chain":[],"data_id":"__data"}`, "")
+	if !errors.Is(err, engine.ErrTimeout) {
+		t.Fatalf("expected timeout error, got %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+/* src/server/core/go/error_boundary.go */
+
+package seam
+
+import (
+	"context"
+	"net/http"
+)
+
+// ErrorBoundary is a layout that declares an error template. When loaders
+// nested inside the layout fail (or the page times out), the page is
+// rendered as the layout chain down to this layout with the error
+// fragment in its outlet, keeping site chrome instead of a bare error.
+// The fragment reads the failure from the `_error` data key
+// (`<!--seam:_error.message-->`, `_error.code`).
+type ErrorBoundary struct {
+	LayoutID        string
+	Template        string            // outer layouts composed around the error fragment
+	LocaleTemplates map[string]string // locale -> composed template
+}
+
+// layoutIndex returns the position of layoutID in the page's layout chain.
+func layoutIndex(page *PageDef, layoutID string) int {
+	for i, entry := range page.LayoutChain {
+		if entry.ID == layoutID {
+			return i
+		}
+	}
+	return -1
+}
+
+// loaderOwnerIndex returns the layout chain index owning a loader data key,
+// or len(LayoutChain) for page-level loaders.
+func loaderOwnerIndex(page *PageDef, dataKey string) int {
+	for i, entry := range page.LayoutChain {
+		for _, key := range entry.LoaderKeys {
+			if key == dataKey {
+				return i
+			}
+		}
+	}
+	return len(page.LayoutChain)
+}
+
+// errorBoundary returns the index into page.ErrorBoundaries of the
+// innermost boundary strictly outside the given chain position, or -1.
+func errorBoundary(page *PageDef, owner int) int {
+	best, bestIdx := -1, -1
+	for i, b := range page.ErrorBoundaries {
+		if idx := layoutIndex(page, b.LayoutID); idx >= 0 && idx < owner && idx > bestIdx {
+			best, bestIdx = i, idx
+		}
+	}
+	return best
+}
+
+// renderErrorBoundary renders the boundary template with the data of the
+// layouts it keeps plus the error under `_error`.
+func (s *appState) renderErrorBoundary(ctx context.Context, w http.ResponseWriter, page *PageDef, boundary int, data, loaderMeta map[string]any, locale string, failure *Error) {
+	b := &page.ErrorBoundaries[boundary]
+	chain := page.LayoutChain[:layoutIndex(page, b.LayoutID)+1]
+
+	kept := make(map[string]any)
+	keptMeta := make(map[string]any)
+	for _, entry := range chain {
+		for _, key := range entry.LoaderKeys {
+			if v, ok := data[key]; ok {
+				kept[key] = v
+			}
+			if m, ok := loaderMeta[key]; ok {
+				keptMeta[key] = m
+			}
+		}
+	}
+	kept["_error"] = map[string]any{"code": failure.Code, "message": failure.Message}
+
+	tmpl := b.Template
+	if lt, ok := b.LocaleTemplates[locale]; ok && locale != "" {
+		tmpl = lt
+	}
+	status := failure.Status
+	if status == 0 {
+		status = defaultStatus(failure.Code)
+	}
+	s.renderPage(ctx, w, page, tmpl, chain, kept, keptMeta, locale, status)
+}
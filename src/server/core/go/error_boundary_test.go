@@ -0,0 +1,111 @@
+/* src/server/core/go/error_boundary_test.go */
+
+package seam
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func errorBoundaryTestPage(pageLoader string) *PageDef {
+	empty := func(map[string]string) any { return map[string]any{} }
+	return &PageDef{
+		Route:    "/dash",
+		Template: "<html><body><nav><!--seam:nav.title--></nav><main><!--seam:stats.count--></main></body></html>",
+		Loaders: []LoaderDef{
+			{DataKey: "nav", Procedure: "getNav", InputFn: empty},
+			{DataKey: "stats", Procedure: pageLoader, InputFn: empty},
+		},
+		LayoutChain:    []LayoutChainEntry{{ID: "root", LoaderKeys: []string{"nav"}}},
+		PageLoaderKeys: []string{"stats"},
+		ErrorBoundaries: []ErrorBoundary{{
+			LayoutID: "root",
+			Template: "<html><body><nav><!--seam:nav.title--></nav><main><p>Error: <!--seam:_error.message--></p></main></body></html>",
+		}},
+	}
+}
+
+func errorBoundaryTestRouter(pageLoader string) *Router {
+	return NewRouter().
+		Procedure(Query("getNav", func(_ context.Context, _ struct{}) (map[string]string, error) {
+			return map[string]string{"title": "Acme"}, nil
+		})).
+		Procedure(Query("getStats", func(_ context.Context, _ struct{}) (map[string]int, error) {
+			return nil, NotFoundError("No stats yet")
+		})).
+		Procedure(Query("slowStats", func(ctx context.Context, _ struct{}) (map[string]int, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})).
+		Page(errorBoundaryTestPage(pageLoader))
+}
+
+func TestErrorBoundaryRendersLayoutChrome(t *testing.T) {
+	handler := errorBoundaryTestRouter("getStats").Handler()
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/_seam/page/dash", nil))
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status %d, want 404: %s", w.Code, w.Body.String())
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, "<nav>Acme</nav>") || !strings.Contains(body, "Error: No stats yet") {
+		t.Fatalf("expected layout chrome with error fragment, got %s", body)
+	}
+}
+
+func TestErrorBoundaryHandlesTimeout(t *testing.T) {
+	handler := errorBoundaryTestRouter("slowStats").Handler(HandlerOptions{PageTimeout: 20 * time.Millisecond})
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/_seam/page/dash", nil))
+
+	if w.Code != http.StatusGatewayTimeout {
+		t.Fatalf("status %d, want 504", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "Error: Page loader timed out") {
+		t.Fatalf("expected error fragment, got %s", w.Body.String())
+	}
+}
+
+func TestErrorBoundarySelection(t *testing.T) {
+	page := &PageDef{
+		LayoutChain: []LayoutChainEntry{
+			{ID: "root", LoaderKeys: []string{"session"}},
+			{ID: "settings", LoaderKeys: []string{"perms"}},
+		},
+		ErrorBoundaries: []ErrorBoundary{{LayoutID: "root"}, {LayoutID: "settings"}},
+	}
+	cases := map[string]int{"body": 1, "perms": 0, "session": -1}
+	for key, want := range cases {
+		if got := errorBoundary(page, loaderOwnerIndex(page, key)); got != want {
+			t.Errorf("failure in %q: boundary %d, want %d", key, got, want)
+		}
+	}
+}
+
+func TestLoadBuildOutputErrorBoundaries(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"route-manifest.json": `{
+			"layouts": {"root": {"template": "root.html", "error_template": "root.error.html"}},
+			"routes": {"/": {"template": "index.html", "layout": "root"}}
+		}`,
+		"root.html":       `<body>ROOT<!--seam:outlet--></body>`,
+		"root.error.html": `<p>oops</p>`,
+		"index.html":      `<p>home</p>`,
+	})
+	pages, err := LoadBuildOutput(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 1 || len(pages[0].ErrorBoundaries) != 1 {
+		t.Fatalf("expected one boundary, got %+v", pages)
+	}
+	if got := pages[0].ErrorBoundaries[0].Template; got != "<body>ROOT<p>oops</p></body>" {
+		t.Errorf("boundary template = %q", got)
+	}
+}
@@ -0,0 +1,126 @@
+/* src/server/core/go/escape.go */
+
+package seam
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const hexDigits = "0123456789abcdef"
+
+// htmlSensitive marks the ASCII bytes escaped inside strings when
+// escapeHTML is set: "<" and ">" (so "</script>" and "<!--" cannot form)
+// and "&".
+var htmlSensitive = [utf8.RuneSelf]bool{'<': true, '>': true, '&': true}
+
+// asciiEscapeJSON escapes every non-ASCII character inside the string
+// values of json as \uXXXX (surrogate pairs beyond the BMP), producing
+// the same output as the engine's ascii_escape_json without a WASM call.
+// With escapeHTML, "<", ">", and "&" inside strings are escaped too,
+// making the text safe to embed in a <script> element. Input without
+// anything to escape is returned as is; invalid UTF-8 becomes �. Every
+// data script the handler rewrites after the engine call goes through it
+// (escapeDataScript, writeDataScript), so all scripts share one encoding.
+func asciiEscapeJSON(json string, escapeHTML bool) string {
+	first := -1
+	for i := 0; i < len(json); i++ {
+		if c := json[i]; c >= utf8.RuneSelf || (escapeHTML && htmlSensitive[c]) {
+			first = i
+			break
+		}
+	}
+	if first < 0 {
+		return json
+	}
+
+	// Re-scan from the start only to learn the string state at first
+	inString := false
+	for i := 0; i < first; i++ {
+		switch json[i] {
+		case '\\':
+			if inString {
+				i++
+			}
+		case '"':
+			inString = !inString
+		}
+	}
+
+	out := make([]byte, 0, len(json)+len(json)/4)
+	out = append(out, json[:first]...)
+	for i := first; i < len(json); {
+		c := json[i]
+		switch {
+		case !inString:
+			if c == '"' {
+				inString = true
+			}
+			out = append(out, c)
+			i++
+		case c == '\\' && i+1 < len(json):
+			out = append(out, c, json[i+1])
+			i += 2
+		case c == '"':
+			inString = false
+			out = append(out, c)
+			i++
+		case c < utf8.RuneSelf:
+			if escapeHTML && htmlSensitive[c] {
+				out = appendUnicodeEscape(out, rune(c))
+			} else {
+				out = append(out, c)
+			}
+			i++
+		default:
+			r, size := utf8.DecodeRuneInString(json[i:])
+			if r > 0xFFFF {
+				r -= 0x10000
+				out = appendUnicodeEscape(out, 0xD800+(r>>10))
+				out = appendUnicodeEscape(out, 0xDC00+(r&0x3FF))
+			} else {
+				out = appendUnicodeEscape(out, r)
+			}
+			i += size
+		}
+	}
+	return string(out)
+}
+
+func appendUnicodeEscape(out []byte, r rune) []byte {
+	return append(out, '\\', 'u', hexDigits[r>>12&0xF], hexDigits[r>>8&0xF], hexDigits[r>>4&0xF], hexDigits[r&0xF])
+}
+
+// escapeDataScript re-escapes the engine's data script of dataID in html
+// for script context: the engine emits "<" verbatim, so a loader string
+// holding "</script>" would close the tag early. The engine places the
+// script right before the last "</body>" (or at the end without one),
+// which bounds the payload even when it contains "</script>". U+2028 and
+// U+2029 are non-ASCII and already escaped. The html is returned
+// unchanged when the script cannot be located.
+func escapeDataScript(html, dataID string) string {
+	open := fmt.Sprintf(`<script id="%s" type="application/json">`, dataID)
+	const closeTag = "</script>"
+	start := strings.Index(html, open)
+	if start < 0 {
+		return html
+	}
+	end := strings.LastIndex(html, "</body>")
+	if end < 0 || !strings.HasSuffix(html[:end], closeTag) {
+		end = len(html)
+		if !strings.HasSuffix(html, closeTag) {
+			return html
+		}
+	}
+	body, end := start+len(open), end-len(closeTag)
+	if body > end {
+		return html
+	}
+	payload := html[body:end]
+	escaped := asciiEscapeJSON(payload, true)
+	if escaped == payload {
+		return html
+	}
+	return html[:body] + escaped + html[end:]
+}
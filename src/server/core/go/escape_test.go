@@ -0,0 +1,152 @@
+/* src/server/core/go/escape_test.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	engine "github.com/canmi21/seam/src/server/engine/go"
+)
+
+var escapeCases = []string{
+	`{}`,
+	`{"a":"plain ascii","n":1}`,
+	`{"greeting":"你好，世界"}`,
+	`{"emoji":"😀 party 🎉"}`,
+	`{"mixed":"café \"naïve\" \\ résumé","list":["ü","ß",null,true]}`,
+	`{"ls":"line sep"}`,
+	"{\"raw\":\"a b c\"}",
+	`{"html":"<script>alert(1)</script> & more"}`,
+}
+
+func TestAsciiEscapeJSONMatchesEngine(t *testing.T) {
+	for _, in := range escapeCases {
+		want, err := engine.AsciiEscapeJSON(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := asciiEscapeJSON(in, false); got != want {
+			t.Errorf("asciiEscapeJSON(%q) = %q, engine = %q", in, got, want)
+		}
+	}
+}
+
+func TestAsciiEscapeJSONEscapeHTML(t *testing.T) {
+	in := `{"<k>":"</script> & 中"}`
+	got := asciiEscapeJSON(in, true)
+	want := `{"\u003ck\u003e":"\u003c/script\u003e \u0026 \u4e2d"}`
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if plain := asciiEscapeJSON(in, false); !strings.Contains(plain, "</script> & ") {
+		t.Errorf("HTML characters escaped without escapeHTML: %s", plain)
+	}
+}
+
+func TestAsciiEscapeJSONUnchangedInput(t *testing.T) {
+	in := `{"a":"<b>"}`
+	if got := asciiEscapeJSON(in, false); got != in {
+		t.Errorf("got %s, want input unchanged", got)
+	}
+}
+
+func TestAsciiEscapeJSONInvalidUTF8(t *testing.T) {
+	got := asciiEscapeJSON("{\"a\":\"x\xffy\"}", false)
+	if got != `{"a":"x\ufffdy"}` {
+		t.Errorf("got %s", got)
+	}
+}
+
+func TestEscapeDataScript(t *testing.T) {
+	open := `<script id="__data" type="application/json">`
+	hostile := `{"a":"</script><script>alert(1)</script>","b":"x"}`
+	got := escapeDataScript(`<html><body><p>x</p>`+open+hostile+`</script></body></html>`, "__data")
+	want := `<html><body><p>x</p>` + open + `{"a":"\u003c/script\u003e\u003cscript\u003ealert(1)\u003c/script\u003e","b":"x"}</script></body></html>`
+	if got != want {
+		t.Fatalf("got %s", got)
+	}
+
+	// Without </body> the script ends the document
+	got = escapeDataScript(`<p>x</p>`+open+`{"a":"</body>"}</script>`, "__data")
+	if got != `<p>x</p>`+open+`{"a":"\u003c/body\u003e"}</script>` {
+		t.Fatalf("got %s", got)
+	}
+
+	if got := escapeDataScript("<body><p>no data</p></body>", "__data"); got != "<body><p>no data</p></body>" {
+		t.Fatalf("expected html unchanged, got %s", got)
+	}
+}
+
+func TestPageDataScriptEscaped(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"route-manifest.json": `{
+			"data_id": "__SEAM_DATA__",
+			"routes": {"/home": {"template": "templates/home.html", "loaders": {"user": {"procedure": "getUser"}}}}
+		}`,
+		"templates/home.html": `<html><body><p><!--seam:user.name--></p></body></html>`,
+	})
+	pages, err := LoadBuildOutput(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := "</script><script>alert(1)</script>\u2028\u2029 & <!--"
+	router := NewRouter().
+		Procedure(Query("getUser", func(context.Context, struct{}) (map[string]string, error) {
+			return map[string]string{"name": name}, nil
+		})).
+		Page(&pages[0])
+
+	w := httptest.NewRecorder()
+	router.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/_seam/page/home", nil))
+	body := w.Body.String()
+	if strings.Contains(body, "<script>alert") {
+		t.Fatalf("loader data broke out of the data script:\n%s", body)
+	}
+	start, _, data, ok := findDataScript(body, "__SEAM_DATA__")
+	if !ok {
+		t.Fatalf("data script not found:\n%s", body)
+	}
+	var user map[string]string
+	if err := json.Unmarshal(data["user"], &user); err != nil || user["name"] != name {
+		t.Fatalf("user = %v (%v)", user, err)
+	}
+	if payload := body[start:]; strings.ContainsAny(payload[strings.Index(payload, ">")+1:strings.Index(payload, "</script>")], "<>&\u2028\u2029") {
+		t.Fatalf("unescaped characters in data script:\n%s", payload)
+	}
+}
+
+func benchmarkEscapeInput() string {
+	return `{"items":[` + strings.Repeat(`{"title":"Grüße aus Köln — 東京 😀","body":"<p>a & b</p>"},`, 200) + `{}]}`
+}
+
+func BenchmarkAsciiEscapeJSON(b *testing.B) {
+	in := benchmarkEscapeInput()
+	for _, bc := range []struct {
+		name string
+		html bool
+	}{{"ascii", false}, {"html", true}} {
+		b.Run(bc.name, func(b *testing.B) {
+			b.SetBytes(int64(len(in)))
+			b.ReportAllocs()
+			for b.Loop() {
+				asciiEscapeJSON(in, bc.html)
+			}
+		})
+	}
+}
+
+func BenchmarkAsciiEscapeJSONEngine(b *testing.B) {
+	in := benchmarkEscapeInput()
+	b.SetBytes(int64(len(in)))
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := engine.AsciiEscapeJSON(in); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+/* src/server/core/go/event_store.go */
+
+package seam
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// CommandEvent records one successful command call.
+type CommandEvent struct {
+	Name      string          `json:"name"`
+	Input     json.RawMessage `json:"input"`
+	Result    json.RawMessage `json:"result"`
+	Principal string          `json:"principal,omitempty"`
+	Timestamp time.Time       `json:"timestamp"`
+}
+
+// EventStore is an append-only log of command events. Set
+// HandlerOptions.EventStore to record every successful command, whether
+// it was called over HTTP, in a batch, or on a socket.
+type EventStore interface {
+	Append(ctx context.Context, event CommandEvent) error
+	// Scan calls fn for every stored event in append order, stopping at
+	// the first error.
+	Scan(ctx context.Context, fn func(CommandEvent) error) error
+}
+
+// recordCommand appends a successful command call to the event store.
+// The call already succeeded, so a failed append is logged, not returned.
+func (s *appState) recordCommand(ctx context.Context, name string, input []byte, result any) {
+	raw, err := json.Marshal(result)
+	if err != nil {
+		logf(slog.LevelError, "event store: encode result of '%s': %v\n", name, err)
+		return
+	}
+	event := CommandEvent{
+		Name:      name,
+		Input:     append(json.RawMessage(nil), input...),
+		Result:    raw,
+		Principal: PrincipalOf(ctx),
+		Timestamp: time.Now().UTC(),
+	}
+	if err := s.opts.EventStore.Append(ctx, event); err != nil {
+		logf(slog.LevelError, "event store: append '%s': %v\n", name, err)
+	}
+}
+
+// Projection maps command names to event appliers used by Replay.
+type Projection map[string]func(ctx context.Context, event CommandEvent) error
+
+// Replay feeds every stored event with an applier in p through it, in
+// append order, and returns how many were applied. Events of commands
+// without an applier are skipped.
+func Replay(ctx context.Context, store EventStore, p Projection) (int, error) {
+	applied := 0
+	err := store.Scan(ctx, func(event CommandEvent) error {
+		apply, ok := p[event.Name]
+		if !ok {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := apply(ctx, event); err != nil {
+			return fmt.Errorf("replay '%s' at %s: %w", event.Name, event.Timestamp.Format(time.RFC3339Nano), err)
+		}
+		applied++
+		return nil
+	})
+	return applied, err
+}
+
+// DecodeEvent decodes an event's input and result into the command's
+// input and output types.
+func DecodeEvent[In, Out any](event CommandEvent) (In, Out, error) {
+	var in In
+	var out Out
+	if err := json.Unmarshal(event.Input, &in); err != nil {
+		return in, out, fmt.Errorf("decode '%s' input: %w", event.Name, err)
+	}
+	if err := json.Unmarshal(event.Result, &out); err != nil {
+		return in, out, fmt.Errorf("decode '%s' result: %w", event.Name, err)
+	}
+	return in, out, nil
+}
+
+// MemoryEventStore keeps events in memory (tests, single-process demos).
+type MemoryEventStore struct {
+	mu     sync.Mutex
+	events []CommandEvent
+}
+
+func NewMemoryEventStore() *MemoryEventStore {
+	return &MemoryEventStore{}
+}
+
+func (m *MemoryEventStore) Append(_ context.Context, event CommandEvent) error {
+	m.mu.Lock()
+	m.events = append(m.events, event)
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *MemoryEventStore) Scan(_ context.Context, fn func(CommandEvent) error) error {
+	for _, event := range m.Events() {
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Events returns a copy of the stored events.
+func (m *MemoryEventStore) Events() []CommandEvent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]CommandEvent(nil), m.events...)
+}
+
+// FileEventStore appends events as JSON lines to a file.
+type FileEventStore struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+// OpenFileEventStore opens (creating if needed) a JSON-lines event log.
+func OpenFileEventStore(path string) (*FileEventStore, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open event store: %w", err)
+	}
+	return &FileEventStore{path: path, file: f}, nil
+}
+
+func (s *FileEventStore) Append(_ context.Context, event CommandEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+func (s *FileEventStore) Scan(_ context.Context, fn func(CommandEvent) error) error {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return fmt.Errorf("open event store: %w", err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64<<10), 16<<20)
+	for n := 1; scanner.Scan(); n++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var event CommandEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return fmt.Errorf("event store line %d: %w", n, err)
+		}
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func (s *FileEventStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
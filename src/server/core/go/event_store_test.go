@@ -0,0 +1,103 @@
+/* src/server/core/go/event_store_test.go */
+
+package seam
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type counterInput struct {
+	By int `json:"by"`
+}
+
+func counterRouter() *Router {
+	total := 0
+	return NewRouter().
+		Procedure(Command("increment", func(_ context.Context, in counterInput) (int, error) {
+			if in.By < 0 {
+				return 0, errors.New("negative")
+			}
+			total += in.By
+			return total, nil
+		})).
+		Procedure(Query("peek", func(context.Context, struct{}) (int, error) { return total, nil }))
+}
+
+func TestEventStoreRecordsSuccessfulCommands(t *testing.T) {
+	store := NewMemoryEventStore()
+	h := counterRouter().Handler(HandlerOptions{
+		EventStore: store,
+		Principal:  func(*http.Request) string { return "u1" },
+	})
+	rpcBody(h, "/_seam/procedure/increment", `{"by":2}`, nil)
+	rpcBody(h, "/_seam/procedure/increment", `{"by":-1}`, nil)
+	rpcBody(h, "/_seam/procedure/peek", `{}`, nil)
+	rpcBody(h, "/_seam/procedure/increment", `{"by":3}`, nil)
+
+	events := store.Events()
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %+v", events)
+	}
+	ev := events[1]
+	if ev.Name != "increment" || string(ev.Input) != `{"by":3}` || string(ev.Result) != "5" || ev.Principal != "u1" || ev.Timestamp.IsZero() {
+		t.Fatalf("unexpected event %+v", ev)
+	}
+}
+
+func TestEventStoreRecordsEveryTransport(t *testing.T) {
+	store := NewMemoryEventStore()
+	h := opsRouter(Command("ops.increment", func(_ context.Context, in counterInput) (int, error) {
+		return in.By, nil
+	})).Handler(HandlerOptions{
+		EventStore:        store,
+		Principal:         func(*http.Request) string { return "u1" },
+		HeartbeatInterval: time.Minute,
+	})
+	callTransports(t, h, "ops.increment", `{"by":1}`, nil)
+
+	events := store.Events()
+	if len(events) != 3 {
+		t.Fatalf("expected an event per transport, got %+v", events)
+	}
+	for _, ev := range events {
+		if ev.Name != "ops.increment" || ev.Principal != "u1" {
+			t.Errorf("unexpected event %+v", ev)
+		}
+	}
+}
+
+func TestFileEventStoreReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	store, err := OpenFileEventStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := counterRouter().Handler(HandlerOptions{EventStore: store})
+	for _, body := range []string{`{"by":1}`, `{"by":4}`, `{"by":5}`} {
+		rpcBody(h, "/_seam/procedure/increment", body, nil)
+	}
+	_ = store.Close()
+
+	reopened, err := OpenFileEventStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	sum, last := 0, 0
+	n, err := Replay(context.Background(), reopened, Projection{
+		"increment": func(_ context.Context, ev CommandEvent) error {
+			in, out, err := DecodeEvent[counterInput, int](ev)
+			sum += in.By
+			last = out
+			return err
+		},
+	})
+	if err != nil || n != 3 || sum != 10 || last != 10 {
+		t.Fatalf("replay: n=%d sum=%d last=%d err=%v", n, sum, last, err)
+	}
+}
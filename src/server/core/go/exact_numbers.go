@@ -0,0 +1,60 @@
+/* src/server/core/go/exact_numbers.go */
+
+package seam
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// The engine parses the page data and writes it back into the data script,
+// so numbers come out in its own formatting: 1.50 becomes 1.5 and
+// integers wider than 64 bits are rounded to floats. With
+// HandlerOptions.ExactNumbers each data key is serialized once on the Go
+// side and those bytes replace the engine's, both at the top level and
+// under _layouts, so the script carries numbers exactly as the loader
+// produced them.
+
+// marshalDataKeys serializes each page data value separately.
+func marshalDataKeys(data map[string]any) (map[string]json.RawMessage, error) {
+	exact := make(map[string]json.RawMessage, len(data))
+	for key, value := range data {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		exact[key] = raw
+	}
+	return exact, nil
+}
+
+// restoreDataNumbers replaces the values the engine wrote into the data
+// script of dataID with their exact encodings. Keys the engine dropped or
+// added (__loaders, _i18n) are left as they are.
+func restoreDataNumbers(html, dataID string, exact map[string]json.RawMessage) string {
+	start, end, data, ok := findDataScript(html, dataID)
+	if !ok {
+		return html
+	}
+	for key := range data {
+		if raw, ok := exact[key]; ok {
+			data[key] = raw
+		}
+	}
+	var layouts map[string]map[string]json.RawMessage
+	if json.Unmarshal(data["_layouts"], &layouts) == nil && layouts != nil {
+		for _, values := range layouts {
+			for key := range values {
+				if raw, ok := exact[key]; ok {
+					values[key] = raw
+				}
+			}
+		}
+		data["_layouts"], _ = json.Marshal(layouts)
+	}
+	var b strings.Builder
+	b.WriteString(html[:start])
+	writeDataScript(&b, dataID, data)
+	b.WriteString(html[end:])
+	return b.String()
+}
@@ -0,0 +1,50 @@
+/* src/server/core/go/exact_numbers_test.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func exactNumbersPage(opts HandlerOptions) string {
+	page := &PageDef{
+		Route:       "/stats",
+		Template:    `<html><body><p><!--seam:stats.count--></p></body></html>`,
+		LayoutChain: []LayoutChainEntry{{ID: "root", LoaderKeys: []string{"totals"}}},
+		Loaders: []LoaderDef{
+			{DataKey: "stats", Procedure: "getStats", InputFn: func(map[string]string) any { return struct{}{} }},
+			{DataKey: "totals", Procedure: "getStats", InputFn: func(map[string]string) any { return struct{}{} }},
+		},
+	}
+	h := NewRouter().
+		Procedure(Query("getStats", func(context.Context, struct{}) (map[string]any, error) {
+			return map[string]any{
+				"count": 3,
+				"big":   json.Number("123456789012345678901234567890"),
+				"price": json.RawMessage(`1.50`),
+			}, nil
+		})).
+		Page(page).
+		Handler(opts)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/_seam/page/stats", nil))
+	return w.Body.String()
+}
+
+func TestExactNumbersInDataScript(t *testing.T) {
+	const want = `{"big":123456789012345678901234567890,"count":3,"price":1.50}`
+	body := exactNumbersPage(HandlerOptions{ExactNumbers: true})
+	if !strings.Contains(body, `"stats":`+want) || !strings.Contains(body, `"_layouts":{"root":{"totals":`+want+`}}`) {
+		t.Errorf("exact data:\n%s", body)
+	}
+	if !strings.Contains(body, "<p>3</p>") || !strings.Contains(body, `"__loaders":`) {
+		t.Errorf("render:\n%s", body)
+	}
+	if body := exactNumbersPage(HandlerOptions{}); strings.Contains(body, want) {
+		t.Errorf("engine output unexpectedly exact:\n%s", body)
+	}
+}
@@ -0,0 +1,170 @@
+/* src/server/core/go/examples.go */
+
+package seam
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// ExampleCapture records anonymized input/output examples of real
+// procedure calls for generated docs and client mocks. It keeps the first
+// PerProcedure distinct examples of each query and command; values of
+// sensitive fields are replaced and email addresses masked before an
+// example is stored. Serve it (it is an http.Handler) on an internal port
+// or merge it into introspection with Annotate.
+type ExampleCapture struct {
+	PerProcedure int      // examples kept per procedure (default 3)
+	MaxBytes     int      // examples larger than this are skipped (default 4 KiB)
+	Redact       []string // extra field name fragments to redact (matched like the defaults)
+
+	mu       sync.Mutex
+	examples map[string][]Example
+	seen     map[string]map[string]bool // procedure -> encoded example set
+}
+
+// Example is one anonymized call of a procedure.
+type Example struct {
+	Input  any `json:"input"`
+	Output any `json:"output"`
+}
+
+// defaultRedactedFields are always redacted. A field is redacted when its
+// name contains one of them, compared case-insensitively after removing "_"
+// and "-", so "userPassword", "api_token" and "X-Api-Key" all match.
+var defaultRedactedFields = []string{
+	"password", "passphrase", "passwd", "secret", "token", "apikey", "authorization",
+	"credential", "cookie", "session", "ssn", "creditcard", "cardnumber", "cvv", "privatekey",
+}
+
+var redactNormalizer = strings.NewReplacer("_", "", "-", "")
+
+var exampleEmailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+
+const redactedValue = "[redacted]"
+
+func (c *ExampleCapture) perProcedure() int {
+	if c.PerProcedure > 0 {
+		return c.PerProcedure
+	}
+	return 3
+}
+
+func (c *ExampleCapture) maxBytes() int {
+	if c.MaxBytes > 0 {
+		return c.MaxBytes
+	}
+	return 4 << 10
+}
+
+// record stores an anonymized example unless the procedure already has
+// enough or an identical one was seen.
+func (c *ExampleCapture) record(name string, input []byte, output any) {
+	c.mu.Lock()
+	full := len(c.examples[name]) >= c.perProcedure()
+	c.mu.Unlock()
+	if full {
+		return
+	}
+
+	var in any
+	if json.Unmarshal(input, &in) != nil {
+		return
+	}
+	raw, err := codecMarshal(output)
+	if err != nil {
+		return
+	}
+	var out any
+	if json.Unmarshal(raw, &out) != nil {
+		return
+	}
+	example := Example{Input: c.anonymize(in), Output: c.anonymize(out)}
+	encoded, _ := json.Marshal(example)
+	if len(encoded) > c.maxBytes() {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.examples == nil {
+		c.examples = make(map[string][]Example)
+		c.seen = make(map[string]map[string]bool)
+	}
+	if len(c.examples[name]) >= c.perProcedure() || c.seen[name][string(encoded)] {
+		return
+	}
+	if c.seen[name] == nil {
+		c.seen[name] = make(map[string]bool)
+	}
+	c.seen[name][string(encoded)] = true
+	c.examples[name] = append(c.examples[name], example)
+}
+
+func (c *ExampleCapture) anonymize(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, child := range val {
+			if c.redacted(k) {
+				val[k] = redactedValue
+			} else {
+				val[k] = c.anonymize(child)
+			}
+		}
+		return val
+	case []any:
+		for i, child := range val {
+			val[i] = c.anonymize(child)
+		}
+		return val
+	case string:
+		return exampleEmailPattern.ReplaceAllString(val, "user@example.com")
+	}
+	return v
+}
+
+func (c *ExampleCapture) redacted(field string) bool {
+	norm := redactNormalizer.Replace(strings.ToLower(field))
+	for _, name := range defaultRedactedFields {
+		if strings.Contains(norm, name) {
+			return true
+		}
+	}
+	for _, name := range c.Redact {
+		if name = redactNormalizer.Replace(strings.ToLower(name)); name != "" && strings.Contains(norm, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Examples returns a copy of the examples recorded for one procedure.
+func (c *ExampleCapture) Examples(name string) []Example {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Example(nil), c.examples[name]...)
+}
+
+// Annotate fills the Examples field of introspection descriptors, e.g.
+// capture.Annotate(router.Procedures()).
+func (c *ExampleCapture) Annotate(infos []ProcedureInfo) []ProcedureInfo {
+	for i := range infos {
+		infos[i].Examples = c.Examples(infos[i].Name)
+	}
+	return infos
+}
+
+// ServeHTTP writes all examples as {"procedures": {name: [examples]}}.
+func (c *ExampleCapture) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	c.mu.Lock()
+	procedures := make(map[string][]Example, len(c.examples))
+	for name, examples := range c.examples {
+		procedures[name] = append([]Example(nil), examples...)
+	}
+	c.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"procedures": procedures})
+}
@@ -0,0 +1,74 @@
+/* src/server/core/go/examples_test.go */
+
+package seam
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type signupInput struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Plan     string `json:"plan"`
+}
+
+type signupOutput struct {
+	ID      int    `json:"id"`
+	APIKey  string `json:"api_key"`
+	Contact string `json:"contact"`
+}
+
+func TestExampleCaptureAnonymizesAndBounds(t *testing.T) {
+	capture := &ExampleCapture{PerProcedure: 2, Redact: []string{"plan"}}
+	router := NewRouter().Procedure(Command("signup", func(_ context.Context, in signupInput) (signupOutput, error) {
+		return signupOutput{ID: len(in.Email), APIKey: "sk_live_123", Contact: "Mail " + in.Email}, nil
+	}))
+	h := router.Handler(HandlerOptions{Examples: capture})
+
+	for _, body := range []string{
+		`{"email":"ann@corp.io","password":"hunter2","plan":"pro"}`,
+		`{"email":"ann@corp.io","password":"hunter2","plan":"pro"}`, // duplicate
+		`{"email":"bob@corp.io","password":"x","plan":"free"}`,
+		`{"email":"carol@corp.io","password":"y","plan":"free"}`, // over the bound
+	} {
+		rpcBody(h, "/_seam/procedure/signup", body, nil)
+	}
+
+	examples := capture.Examples("signup")
+	if len(examples) != 2 {
+		t.Fatalf("expected 2 examples, got %+v", examples)
+	}
+	in := examples[0].Input.(map[string]any)
+	out := examples[0].Output.(map[string]any)
+	if in["password"] != redactedValue || in["plan"] != redactedValue || in["email"] != "user@example.com" {
+		t.Fatalf("input not anonymized: %v", in)
+	}
+	if out["api_key"] != redactedValue || out["contact"] != "Mail user@example.com" || out["id"] != float64(11) {
+		t.Fatalf("output not anonymized: %v", out)
+	}
+
+	infos := capture.Annotate(router.Procedures())
+	if len(infos[0].Examples) != 2 {
+		t.Fatalf("introspection not annotated: %+v", infos[0])
+	}
+	w := httptest.NewRecorder()
+	capture.ServeHTTP(w, nil)
+	if !strings.HasPrefix(w.Body.String(), `{"procedures":{"signup":[{"input":`) || strings.Contains(w.Body.String(), "corp.io") {
+		t.Fatalf("unexpected examples endpoint body %s", w.Body.String())
+	}
+}
+
+func TestExampleRedactionMatchesFieldNameFragments(t *testing.T) {
+	c := &ExampleCapture{Redact: []string{"internal_note"}}
+	for field, want := range map[string]bool{
+		"userPassword": true, "apiToken": true, "X-Api-Key": true, "refresh_token": true,
+		"clientSecretHash": true, "internalNoteText": true, "username": false, "plan": false,
+	} {
+		if got := c.redacted(field); got != want {
+			t.Errorf("redacted(%q) = %v, want %v", field, got, want)
+		}
+	}
+}
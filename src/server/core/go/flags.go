@@ -0,0 +1,217 @@
+/* src/server/core/go/flags.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// FlagTarget carries the per-request attributes used for flag targeting.
+type FlagTarget struct {
+	Principal string
+	Locale    string
+	Request   *http.Request
+}
+
+// FlagProvider evaluates feature flags for a request.
+type FlagProvider interface {
+	Flags(ctx context.Context, target FlagTarget) (map[string]any, error)
+}
+
+// FlagProviderFunc adapts a function to FlagProvider. Third-party flag
+// services without a dedicated adapter plug in through it.
+type FlagProviderFunc func(ctx context.Context, target FlagTarget) (map[string]any, error)
+
+func (f FlagProviderFunc) Flags(ctx context.Context, target FlagTarget) (map[string]any, error) {
+	return f(ctx, target)
+}
+
+// FlagRule is a targeted flag value. Precedence: principal, locale, default.
+type FlagRule struct {
+	Default    any            `json:"default"`
+	Principals map[string]any `json:"principals,omitempty"`
+	Locales    map[string]any `json:"locales,omitempty"`
+}
+
+func (r FlagRule) evaluate(target FlagTarget) any {
+	if target.Principal != "" {
+		if v, ok := r.Principals[target.Principal]; ok {
+			return v
+		}
+	}
+	if target.Locale != "" {
+		if v, ok := r.Locales[target.Locale]; ok {
+			return v
+		}
+	}
+	return r.Default
+}
+
+type staticFlags map[string]FlagRule
+
+// StaticFlags returns a provider evaluating a fixed rule set.
+func StaticFlags(rules map[string]FlagRule) FlagProvider {
+	return staticFlags(rules)
+}
+
+func (f staticFlags) Flags(_ context.Context, target FlagTarget) (map[string]any, error) {
+	out := make(map[string]any, len(f))
+	for name, rule := range f {
+		out[name] = rule.evaluate(target)
+	}
+	return out, nil
+}
+
+// FileFlags loads a JSON rule set ({"name": FlagRule}) from path once.
+func FileFlags(path string) (FlagProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read flags file: %w", err)
+	}
+	var rules map[string]FlagRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("parse flags file: %w", err)
+	}
+	return StaticFlags(rules), nil
+}
+
+type envFlags struct {
+	prefix string
+}
+
+// EnvFlags reads flags from environment variables named prefix+flagName
+// (e.g. prefix "SEAM_FLAG_" and SEAM_FLAG_newNav=true). Values are parsed
+// as JSON, falling back to the raw string. No per-request targeting.
+func EnvFlags(prefix string) FlagProvider {
+	return envFlags{prefix: prefix}
+}
+
+func (f envFlags) Flags(context.Context, FlagTarget) (map[string]any, error) {
+	out := make(map[string]any)
+	for _, kv := range os.Environ() {
+		key, val, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(key, f.prefix) || len(key) == len(f.prefix) {
+			continue
+		}
+		var parsed any
+		if err := json.Unmarshal([]byte(val), &parsed); err != nil {
+			parsed = val
+		}
+		out[key[len(f.prefix):]] = parsed
+	}
+	return out, nil
+}
+
+// LaunchDarklyContext is the evaluation context LaunchDarklyFlags builds
+// from a FlagTarget.
+type LaunchDarklyContext struct {
+	Kind       string
+	Key        string
+	Anonymous  bool
+	Attributes map[string]string
+}
+
+// LaunchDarklyClient is the subset of a LaunchDarkly server SDK client
+// LaunchDarklyFlags needs. A go-server-sdk v7 client adapts in a few lines:
+//
+//	func (c adapter) AllFlags(_ context.Context, lc seam.LaunchDarklyContext) (map[string]any, error) {
+//		b := ldcontext.NewBuilder(lc.Key).Kind(ldcontext.Kind(lc.Kind)).Anonymous(lc.Anonymous)
+//		for name, v := range lc.Attributes {
+//			b.SetString(name, v)
+//		}
+//		state := c.Client.AllFlagsState(b.Build())
+//		if !state.IsValid() {
+//			return nil, errors.New("launchdarkly: flags unavailable")
+//		}
+//		out := make(map[string]any)
+//		for name, v := range state.ToValuesMap() {
+//			out[name] = v.AsArbitraryValue()
+//		}
+//		return out, nil
+//	}
+type LaunchDarklyClient interface {
+	AllFlags(ctx context.Context, lc LaunchDarklyContext) (map[string]any, error)
+}
+
+// LaunchDarklyOptions tunes LaunchDarklyFlags. Zero values use the defaults.
+type LaunchDarklyOptions struct {
+	Kind         string // context kind (default "user")
+	AnonymousKey string // context key of requests without a principal (default "anonymous")
+}
+
+type launchDarklyFlags struct {
+	client LaunchDarklyClient
+	opts   LaunchDarklyOptions
+}
+
+// LaunchDarklyFlags returns a provider evaluating every flag through
+// LaunchDarkly. The principal becomes the context key (requests without one
+// are anonymous) and the locale the "locale" attribute, so targeting rules
+// are written in LaunchDarkly.
+func LaunchDarklyFlags(client LaunchDarklyClient, opts LaunchDarklyOptions) FlagProvider {
+	if opts.Kind == "" {
+		opts.Kind = "user"
+	}
+	if opts.AnonymousKey == "" {
+		opts.AnonymousKey = "anonymous"
+	}
+	return launchDarklyFlags{client: client, opts: opts}
+}
+
+func (f launchDarklyFlags) Flags(ctx context.Context, target FlagTarget) (map[string]any, error) {
+	lc := LaunchDarklyContext{Kind: f.opts.Kind, Key: target.Principal}
+	if lc.Key == "" {
+		lc.Key, lc.Anonymous = f.opts.AnonymousKey, true
+	}
+	if target.Locale != "" {
+		lc.Attributes = map[string]string{"locale": target.Locale}
+	}
+	flags, err := f.client.AllFlags(ctx, lc)
+	if err != nil {
+		return nil, fmt.Errorf("launchdarkly: %w", err)
+	}
+	return flags, nil
+}
+
+type flagScopeKeyType struct{}
+
+var flagScopeKey = flagScopeKeyType{}
+
+type flagScope struct {
+	provider FlagProvider
+	target   FlagTarget
+}
+
+// Flags returns the evaluated flags for the current request. Evaluation runs
+// at most once per request; provider errors yield an empty set.
+func Flags(ctx context.Context) map[string]any {
+	scope, ok := ctx.Value(flagScopeKey).(*flagScope)
+	if !ok {
+		return nil
+	}
+	flags, err := Memo(ctx, "seam.flags", func() (map[string]any, error) {
+		return scope.provider.Flags(ctx, scope.target)
+	})
+	if err != nil {
+		logf(slog.LevelWarn, "Flag evaluation failed: %v\n", err)
+		return map[string]any{}
+	}
+	return flags
+}
+
+// FlagValue returns a single typed flag value for the current request.
+func FlagValue[T any](ctx context.Context, name string) (T, bool) {
+	var zero T
+	v, ok := Flags(ctx)[name]
+	if !ok {
+		return zero, false
+	}
+	typed, ok := v.(T)
+	return typed, ok
+}
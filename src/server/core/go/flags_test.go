@@ -0,0 +1,148 @@
+/* src/server/core/go/flags_test.go */
+
+package seam
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestStaticFlagsTargeting(t *testing.T) {
+	p := StaticFlags(map[string]FlagRule{
+		"newNav": {
+			Default:    false,
+			Principals: map[string]any{"alice": true},
+			Locales:    map[string]any{"zh": true},
+		},
+	})
+	cases := []struct {
+		target FlagTarget
+		want   any
+	}{
+		{FlagTarget{}, false},
+		{FlagTarget{Locale: "zh"}, true},
+		{FlagTarget{Principal: "alice"}, true},
+		{FlagTarget{Principal: "bob", Locale: "en"}, false},
+	}
+	for _, c := range cases {
+		got, _ := p.Flags(context.Background(), c.target)
+		if got["newNav"] != c.want {
+			t.Errorf("target %+v: got %v, want %v", c.target, got["newNav"], c.want)
+		}
+	}
+}
+
+func TestFileFlags(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flags.json")
+	if err := os.WriteFile(path, []byte(`{"beta":{"default":"off","principals":{"u1":"on"}}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := FileFlags(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := p.Flags(context.Background(), FlagTarget{Principal: "u1"})
+	if got["beta"] != "on" {
+		t.Fatalf("got %v", got)
+	}
+	if _, err := FileFlags(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Fatal("expected error for missing file")
+	}
+}
+
+func TestEnvFlags(t *testing.T) {
+	t.Setenv("TESTFLAG_enabled", "true")
+	t.Setenv("TESTFLAG_limit", "5")
+	t.Setenv("TESTFLAG_variant", "blue")
+	got, _ := EnvFlags("TESTFLAG_").Flags(context.Background(), FlagTarget{})
+	if got["enabled"] != true || got["limit"] != float64(5) || got["variant"] != "blue" {
+		t.Fatalf("unexpected env flags: %v", got)
+	}
+}
+
+type fakeLaunchDarkly struct {
+	got []LaunchDarklyContext
+}
+
+func (f *fakeLaunchDarkly) AllFlags(_ context.Context, lc LaunchDarklyContext) (map[string]any, error) {
+	f.got = append(f.got, lc)
+	return map[string]any{"newNav": lc.Key == "alice"}, nil
+}
+
+func TestLaunchDarklyFlags(t *testing.T) {
+	client := &fakeLaunchDarkly{}
+	p := LaunchDarklyFlags(client, LaunchDarklyOptions{})
+	alice, _ := p.Flags(context.Background(), FlagTarget{Principal: "alice", Locale: "zh"})
+	anon, _ := p.Flags(context.Background(), FlagTarget{})
+	if alice["newNav"] != true || anon["newNav"] != false {
+		t.Fatalf("unexpected flags %v %v", alice, anon)
+	}
+	if c := client.got[0]; c.Kind != "user" || c.Key != "alice" || c.Anonymous || c.Attributes["locale"] != "zh" {
+		t.Errorf("principal context %+v", c)
+	}
+	if c := client.got[1]; c.Key != "anonymous" || !c.Anonymous || c.Attributes != nil {
+		t.Errorf("anonymous context %+v", c)
+	}
+}
+
+func TestFlagsInHandlerWithPrincipal(t *testing.T) {
+	var evaluations atomic.Int32
+	provider := FlagProviderFunc(func(_ context.Context, target FlagTarget) (map[string]any, error) {
+		evaluations.Add(1)
+		return map[string]any{"beta": target.Principal == "alice"}, nil
+	})
+	handler := NewRouter().
+		Procedure(Query("check", func(ctx context.Context, _ struct{}) (map[string]any, error) {
+			beta, _ := FlagValue[bool](ctx, "beta")
+			_ = Flags(ctx) // second read reuses the evaluation
+			return map[string]any{"beta": beta, "who": PrincipalOf(ctx)}, nil
+		})).
+		Handler(HandlerOptions{
+			Principal: func(r *http.Request) string { return r.Header.Get("X-User") },
+			Flags:     provider,
+		})
+
+	req := httptest.NewRequest(http.MethodPost, "/_seam/procedure/check", strings.NewReader("{}"))
+	req.Header.Set("X-User", "alice")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if !strings.Contains(w.Body.String(), `"beta":true`) || !strings.Contains(w.Body.String(), `"who":"alice"`) {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+	if evaluations.Load() != 1 {
+		t.Fatalf("expected one evaluation per request, got %d", evaluations.Load())
+	}
+}
+
+func TestFlagsExposedInPageData(t *testing.T) {
+	page := &PageDef{
+		Route:    "/p",
+		Template: "<html><body><!--seam:a--></body></html>",
+		Loaders: []LoaderDef{
+			{DataKey: "a", Procedure: "a", InputFn: func(map[string]string) any { return struct{}{} }},
+		},
+	}
+	build := func(expose bool) http.Handler {
+		return NewRouter().
+			Procedure(Query("a", func(context.Context, struct{}) (string, error) { return "x", nil })).
+			Page(page).
+			Handler(HandlerOptions{
+				Flags:       StaticFlags(map[string]FlagRule{"darkMode": {Default: true}}),
+				ExposeFlags: expose,
+			})
+	}
+	for _, expose := range []bool{true, false} {
+		w := httptest.NewRecorder()
+		build(expose).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/_seam/page/p", http.NoBody))
+		has := strings.Contains(w.Body.String(), `"_flags":{"darkMode":true}`)
+		if has != expose {
+			t.Fatalf("expose=%v: unexpected body %s", expose, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,100 @@
+/* src/server/core/go/fuzz.go */
+
+package seam
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// RPCInputSeeds is a seed corpus for fuzzing ParseRPCInput: valid and
+// near-valid inputs exercising reserved keys, escapes, and nesting.
+// Downstream fuzz tests add them with f.Add.
+var RPCInputSeeds = [][]byte{
+	[]byte(`{}`),
+	[]byte(`{"id":"42","tags":["a","b"],"nested":{"n":1.5e3,"ok":true,"none":null}}`),
+	[]byte(`{"__fields":["id","owner.login"],"user":"canmi"}`),
+	[]byte(`{"__dryRun":true,"amount":"12.50"}`),
+	[]byte(`{"s":"</script>   😀 \"quoted\""}`),
+	[]byte(`[[[[[[[[[[]]]]]]]]]]`),
+	[]byte(`{"__fields":"not-a-list"}`),
+	[]byte(`{"a":`),
+	[]byte("\xff\xfe"),
+}
+
+// RouteSeeds is a seed corpus for fuzzing MatchRoute as {route, path} pairs.
+var RouteSeeds = [][2]string{
+	{"/user/:id", "/user/42"},
+	{"/blog/:slug/comments/:cid", "/blog/hello-world/comments/7"},
+	{"/files/:name", "/files/a%2Fb"},
+	{"/user/:id", "/user/"},
+	{"/", "/anything/else"},
+}
+
+// ParseRPCInput runs an RPC body through the checks a procedure call
+// applies before its handler: default input limits, JSON validity,
+// reserved __fields/__dryRun keys, and (when schema is non-nil) JTD
+// validation. It returns the input the handler would receive or the
+// error the client would get, and never panics; it exists so `go test
+// -fuzz` can target the SDK's input handling.
+func ParseRPCInput(schema any, body []byte) (json.RawMessage, *Error) {
+	s := &appState{}
+	if err := s.checkInput(body); err != nil {
+		return nil, err
+	}
+	if !json.Valid(body) {
+		return nil, ValidationError("Invalid JSON")
+	}
+	body, _, err := takeFields(body)
+	if err != nil {
+		return nil, err
+	}
+	body, _, err = takeDryRun(&http.Request{Header: http.Header{}}, body)
+	if err != nil {
+		return nil, err
+	}
+	if schema != nil {
+		cs, compileErr := compileSchema(schema)
+		if compileErr != nil {
+			return nil, InternalError(fmt.Sprintf("invalid schema: %v", compileErr))
+		}
+		var parsed any
+		_ = codecUnmarshal(body, &parsed)
+		if msg, details := validateCompiled(cs, parsed); msg != "" {
+			return nil, ValidationErrorDetailed(msg, toAnySlice(details))
+		}
+	}
+	return body, nil
+}
+
+// MatchRoute matches a request path against a page route ("/user/:id")
+// the way page handlers do and returns the extracted params. ok is false
+// when the path does not match or the route is not a valid pattern.
+func MatchRoute(route, path string) (params map[string]string, ok bool) {
+	if !strings.HasPrefix(route, "/") {
+		return nil, false
+	}
+	mux := http.NewServeMux()
+	register := func() (registered bool) {
+		defer func() {
+			if recover() != nil {
+				registered = false
+			}
+		}()
+		mux.HandleFunc("GET "+seamRouteToGoPattern(route), func(_ http.ResponseWriter, r *http.Request) {
+			params, ok = extractParams(route, r), true
+		})
+		return true
+	}
+	if !register() {
+		return nil, false
+	}
+	req, err := http.NewRequest(http.MethodGet, path, nil)
+	if err != nil || req.URL.Path == "" {
+		return nil, false
+	}
+	mux.ServeHTTP(&discardResponse{header: http.Header{}}, req)
+	return params, ok
+}
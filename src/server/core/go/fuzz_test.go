@@ -0,0 +1,90 @@
+/* src/server/core/go/fuzz_test.go */
+
+package seam
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+var fuzzInputSchema = map[string]any{
+	"properties":           map[string]any{"id": map[string]any{"type": "string"}},
+	"optionalProperties":   map[string]any{"tags": map[string]any{"elements": map[string]any{"type": "string"}}},
+	"additionalProperties": true,
+}
+
+func FuzzParseRPCInput(f *testing.F) {
+	for _, seed := range RPCInputSeeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, body []byte) {
+		for _, schema := range []any{nil, fuzzInputSchema} {
+			input, err := ParseRPCInput(schema, body)
+			if err != nil {
+				if input != nil {
+					t.Fatalf("input returned alongside error %v", err)
+				}
+				continue
+			}
+			if !json.Valid(input) {
+				t.Fatalf("accepted invalid JSON %q", input)
+			}
+			var obj map[string]json.RawMessage
+			if json.Unmarshal(input, &obj) == nil {
+				if _, ok := obj[fieldsInputKey]; ok {
+					t.Fatalf("reserved key left in %s", input)
+				}
+				if _, ok := obj[dryRunInputKey]; ok {
+					t.Fatalf("reserved key left in %s", input)
+				}
+			}
+			if schema != nil && !bytes.HasPrefix(bytes.TrimSpace(input), []byte("{")) {
+				t.Fatalf("schema accepted non-object %s", input)
+			}
+		}
+	})
+}
+
+func FuzzMatchRoute(f *testing.F) {
+	for _, seed := range RouteSeeds {
+		f.Add(seed[0], seed[1])
+	}
+	f.Fuzz(func(t *testing.T, route, path string) {
+		params, ok := MatchRoute(route, path)
+		if !ok {
+			return
+		}
+		for _, part := range strings.Split(route, "/") {
+			if name, isParam := strings.CutPrefix(part, ":"); isParam {
+				if _, found := params[name]; !found {
+					t.Fatalf("param %q missing for %s matched by %s", name, route, path)
+				}
+			}
+		}
+	})
+}
+
+func TestMatchRoute(t *testing.T) {
+	params, ok := MatchRoute("/blog/:slug/comments/:cid", "/blog/hello/comments/7")
+	if !ok || params["slug"] != "hello" || params["cid"] != "7" {
+		t.Fatalf("unexpected match %v %v", params, ok)
+	}
+	if _, ok := MatchRoute("/user/:id", "/user/"); ok {
+		t.Fatal("expected empty segment not to match")
+	}
+	if _, ok := MatchRoute("/user/{bad", "/user/1"); ok {
+		t.Fatal("expected invalid pattern to be rejected")
+	}
+}
+
+func TestParseRPCInputReservedKeys(t *testing.T) {
+	input, err := ParseRPCInput(fuzzInputSchema, []byte(`{"id":"1","__fields":["id"],"__dryRun":true}`))
+	if err != nil || string(input) != `{"id":"1"}` {
+		t.Fatalf("unexpected %s %v", input, err)
+	}
+	if _, err := ParseRPCInput(fuzzInputSchema, []byte(`{"tags":[1]}`)); err == nil || err.Code != "VALIDATION_ERROR" {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
@@ -5,6 +5,7 @@ package seam
 import (
 	"context"
 	"encoding/json"
+	"reflect"
 )
 
 // Query creates a ProcedureDef from a typed handler function.
@@ -17,11 +18,12 @@ func Query[In, Out any](name string, fn func(context.Context, In) (Out, error),
 		OutputSchema: SchemaOf[Out](),
 		Handler: func(ctx context.Context, raw json.RawMessage) (any, error) {
 			var input In
-			if err := json.Unmarshal(raw, &input); err != nil {
+			if err := codecUnmarshal(raw, &input); err != nil {
 				return nil, ValidationError("Invalid input: " + err.Error())
 			}
 			return fn(ctx, input)
 		},
+		inputType: reflect.TypeFor[In](),
 	}
 	for _, opt := range opts {
 		opt(def)
@@ -38,11 +40,12 @@ func Command[In, Out any](name string, fn func(context.Context, In) (Out, error)
 		OutputSchema: SchemaOf[Out](),
 		Handler: func(ctx context.Context, raw json.RawMessage) (any, error) {
 			var input In
-			if err := json.Unmarshal(raw, &input); err != nil {
+			if err := codecUnmarshal(raw, &input); err != nil {
 				return nil, ValidationError("Invalid input: " + err.Error())
 			}
 			return fn(ctx, input)
 		},
+		inputType: reflect.TypeFor[In](),
 	}
 	for _, opt := range opts {
 		opt(def)
@@ -60,7 +63,7 @@ func Subscribe[In, Out any](name string, fn func(context.Context, In) (<-chan Ou
 		OutputSchema: SchemaOf[Out](),
 		Handler: func(ctx context.Context, raw json.RawMessage) (<-chan SubscriptionEvent, error) {
 			var input In
-			if err := json.Unmarshal(raw, &input); err != nil {
+			if err := codecUnmarshal(raw, &input); err != nil {
 				return nil, ValidationError("Invalid input: " + err.Error())
 			}
 			dataCh, err := fn(ctx, input)
@@ -89,7 +92,7 @@ func StreamProc[In, Chunk any](name string, fn func(context.Context, In) (<-chan
 		ChunkOutputSchema: SchemaOf[Chunk](),
 		Handler: func(ctx context.Context, raw json.RawMessage) (<-chan StreamEvent, error) {
 			var input In
-			if err := json.Unmarshal(raw, &input); err != nil {
+			if err := codecUnmarshal(raw, &input); err != nil {
 				return nil, ValidationError("Invalid input: " + err.Error())
 			}
 			dataCh, err := fn(ctx, input)
@@ -116,7 +119,7 @@ func UploadProc[In, Out any](name string, fn func(context.Context, In, *SeamFile
 		OutputSchema: SchemaOf[Out](),
 		Handler: func(ctx context.Context, raw json.RawMessage, file *SeamFileHandle) (any, error) {
 			var input In
-			if err := json.Unmarshal(raw, &input); err != nil {
+			if err := codecUnmarshal(raw, &input); err != nil {
 				return nil, ValidationError("Invalid input: " + err.Error())
 			}
 			return fn(ctx, input, file)
@@ -0,0 +1,107 @@
+/* src/server/core/go/golden_pages.go */
+
+package seam
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	engine "github.com/canmi21/seam/src/server/engine/go"
+)
+
+// defaultFixtureConfig is the page config used when a fixture has no
+// config.json: no layouts and the default data script ID.
+const defaultFixtureConfig = `{"layout_chain":[],"data_id":"__data"}`
+
+// CheckPageGoldens renders every page fixture under dir and compares the
+// output byte-for-byte against golden HTML files, the local counterpart of
+// the cross-backend TestPageParity. Each subdirectory is one fixture:
+//
+//	template.html       page template (required)
+//	data.json           loader data (default {})
+//	config.json         engine page config (default: no layouts, "__data")
+//	i18n.json           engine i18n options (optional)
+//	inject.golden.html  expected output of the injector path (engine.Inject)
+//	engine.golden.html  expected output of the full render (engine.RenderPage)
+//
+// Missing goldens are written, as are all of them with SEAM_UPDATE_GOLDEN=1
+// (see CompareGolden). Mismatches are reported per fixture with a diff
+// split at tag boundaries and joined into the returned error:
+//
+//	if err := seam.CheckPageGoldens("testdata/pages"); err != nil {
+//		t.Fatal(err)
+//	}
+func CheckPageGoldens(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	var errs []error
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if err := checkPageFixture(filepath.Join(dir, entry.Name())); err != nil {
+			errs = append(errs, fmt.Errorf("fixture %s: %w", entry.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func checkPageFixture(dir string) error {
+	tmpl, err := os.ReadFile(filepath.Join(dir, "template.html"))
+	if err != nil {
+		return err
+	}
+	data, err := readFixture(dir, "data.json", "{}")
+	if err != nil {
+		return err
+	}
+	config, err := readFixture(dir, "config.json", defaultFixtureConfig)
+	if err != nil {
+		return err
+	}
+	i18n, err := readFixture(dir, "i18n.json", "")
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	injected, err := engine.Inject(string(tmpl), data, "__data")
+	if err != nil {
+		errs = append(errs, fmt.Errorf("inject: %w", err))
+	} else {
+		errs = append(errs, compareGolden(filepath.Join(dir, "inject.golden.html"), []byte(injected), htmlDiff))
+	}
+	rendered, err := engine.RenderPage(string(tmpl), data, config, i18n)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("render: %w", err))
+	} else {
+		errs = append(errs, compareGolden(filepath.Join(dir, "engine.golden.html"), []byte(rendered), htmlDiff))
+	}
+	return errors.Join(errs...)
+}
+
+// readFixture returns the trimmed content of an optional fixture file.
+func readFixture(dir, name, fallback string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if os.IsNotExist(err) {
+		return fallback, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// htmlDiff diffs rendered HTML one tag per line, so a changed attribute in
+// a single-line document shows as the element that changed.
+func htmlDiff(want, got string) string {
+	split := strings.NewReplacer("><", ">\n<")
+	return "(one line per tag)\n" + lineDiff(split.Replace(want), split.Replace(got))
+}
@@ -0,0 +1,49 @@
+/* src/server/core/go/golden_pages_test.go */
+
+package seam
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPageGoldens(t *testing.T) {
+	if err := CheckPageGoldens("testdata/pages"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckPageGoldensReportsTagDiff(t *testing.T) {
+	dir := t.TempDir()
+	fixture := filepath.Join(dir, "card")
+	writeFiles(t, fixture, map[string]string{
+		"template.html": `<div><b><!--seam:name--></b><i>x</i></div>`,
+		"data.json":     `{"name": "Ada"}`,
+	})
+	// First run writes both goldens
+	if err := CheckPageGoldens(dir); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"inject.golden.html", "engine.golden.html"} {
+		if _, err := os.Stat(filepath.Join(fixture, name)); err != nil {
+			t.Fatalf("expected %s: %v", name, err)
+		}
+	}
+
+	writeFiles(t, fixture, map[string]string{"data.json": `{"name": "Grace"}`})
+	err := CheckPageGoldens(dir)
+	if err == nil {
+		t.Fatal("expected golden mismatch")
+	}
+	msg := err.Error()
+	for _, want := range []string{"fixture card:", "inject.golden.html", "engine.golden.html", "-<b>Ada</b>", "+<b>Grace</b>"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("expected %q in %s", want, msg)
+		}
+	}
+	if strings.Contains(msg, "<i>x</i>") {
+		t.Errorf("unchanged tags should not be in the diff: %s", msg)
+	}
+}
@@ -0,0 +1,279 @@
+/* src/server/core/go/grpc_health.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// HealthStatus is a grpc.health.v1 HealthCheckResponse.ServingStatus.
+type HealthStatus int32
+
+const (
+	HealthUnknown        HealthStatus = 0
+	HealthServing        HealthStatus = 1
+	HealthNotServing     HealthStatus = 2
+	HealthServiceUnknown HealthStatus = 3 // Watch only
+)
+
+func (s HealthStatus) String() string {
+	switch s {
+	case HealthServing:
+		return "SERVING"
+	case HealthNotServing:
+		return "NOT_SERVING"
+	case HealthServiceUnknown:
+		return "SERVICE_UNKNOWN"
+	}
+	return "UNKNOWN"
+}
+
+// GRPCHealth implements the standard gRPC health checking protocol
+// (grpc.health.v1.Health, Check and Watch) for service meshes that probe
+// it, without a gRPC dependency. The service "" is the server as a whole
+// and starts SERVING. Run it on its own port with ListenAndServe, or
+// mount it on a server accepting unencrypted HTTP/2.
+type GRPCHealth struct {
+	mu       sync.Mutex
+	statuses map[string]HealthStatus
+	changed  chan struct{} // closed and replaced on every status change
+	shutdown bool
+}
+
+// gRPC status codes used by the health service.
+const (
+	grpcOK            = 0
+	grpcNotFound      = 5
+	grpcUnimplemented = 12
+	grpcInternal      = 13
+)
+
+const (
+	grpcHealthCheck = "/grpc.health.v1.Health/Check"
+	grpcHealthWatch = "/grpc.health.v1.Health/Watch"
+)
+
+// NewGRPCHealth creates a health service reporting "" as SERVING.
+func NewGRPCHealth() *GRPCHealth {
+	return &GRPCHealth{statuses: map[string]HealthStatus{"": HealthServing}, changed: make(chan struct{})}
+}
+
+// SetServingStatus sets the status of service ("" for the server). It is
+// ignored after Shutdown until Resume.
+func (h *GRPCHealth) SetServingStatus(service string, status HealthStatus) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.shutdown {
+		h.setLocked(service, status)
+	}
+}
+
+// Status returns the status of service and whether it is registered.
+func (h *GRPCHealth) Status(service string) (HealthStatus, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	status, ok := h.statuses[service]
+	return status, ok
+}
+
+// Shutdown reports every service NOT_SERVING and freezes the statuses,
+// so the mesh shifts traffic away before the server drains.
+func (h *GRPCHealth) Shutdown() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.shutdown = true
+	for service := range h.statuses {
+		h.setLocked(service, HealthNotServing)
+	}
+}
+
+// Resume undoes Shutdown, reporting every service SERVING.
+func (h *GRPCHealth) Resume() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.shutdown = false
+	for service := range h.statuses {
+		h.setLocked(service, HealthServing)
+	}
+}
+
+func (h *GRPCHealth) setLocked(service string, status HealthStatus) {
+	if old, ok := h.statuses[service]; ok && old == status {
+		return
+	}
+	h.statuses[service] = status
+	close(h.changed)
+	h.changed = make(chan struct{})
+}
+
+// ServeHTTP serves the Check and Watch RPCs; other methods fail with
+// UNIMPLEMENTED.
+func (h *GRPCHealth) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.ProtoMajor != 2 {
+		http.Error(w, "gRPC requires HTTP/2 POST", http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/grpc")
+	if r.URL.Path != grpcHealthCheck && r.URL.Path != grpcHealthWatch {
+		grpcTrailer(w, grpcUnimplemented, "unknown method "+r.URL.Path)
+		return
+	}
+	service, code, msg := readHealthRequest(r.Body)
+	if code != grpcOK {
+		grpcTrailer(w, code, msg)
+		return
+	}
+
+	if r.URL.Path == grpcHealthCheck {
+		status, ok := h.Status(service)
+		if !ok {
+			grpcTrailer(w, grpcNotFound, "unknown service")
+			return
+		}
+		_, _ = w.Write(healthResponseFrame(status))
+		grpcTrailer(w, grpcOK, "")
+		return
+	}
+
+	flusher, _ := w.(http.Flusher)
+	last := HealthStatus(-1)
+	for {
+		h.mu.Lock()
+		status, ok := h.statuses[service]
+		changed := h.changed
+		h.mu.Unlock()
+		if !ok {
+			status = HealthServiceUnknown
+		}
+		if status != last {
+			if _, err := w.Write(healthResponseFrame(status)); err != nil {
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+			last = status
+		}
+		select {
+		case <-changed:
+		case <-r.Context().Done():
+			grpcTrailer(w, grpcOK, "")
+			return
+		}
+	}
+}
+
+// ListenAndServe serves the health service over unencrypted HTTP/2 on addr
+// until ctx is done, then reports NOT_SERVING and closes the listener.
+func (h *GRPCHealth) ListenAndServe(ctx context.Context, addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return h.Serve(ctx, ln)
+}
+
+// Serve is ListenAndServe on an existing listener.
+func (h *GRPCHealth) Serve(ctx context.Context, ln net.Listener) error {
+	srv := &http.Server{Handler: h, ReadHeaderTimeout: 10 * time.Second, Protocols: new(http.Protocols)}
+	srv.Protocols.SetUnencryptedHTTP2(true)
+	errCh := make(chan error, 1)
+	go func() { errCh <- srv.Serve(ln) }()
+	select {
+	case <-ctx.Done():
+		h.Shutdown()
+		// Watch streams only end with their connection
+		_ = srv.Close()
+		<-errCh
+		return nil
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	}
+}
+
+// grpcTrailer sets the call status trailers.
+func grpcTrailer(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set(http.TrailerPrefix+"Grpc-Status", strconv.Itoa(code))
+	if msg != "" {
+		w.Header().Set(http.TrailerPrefix+"Grpc-Message", msg)
+	}
+}
+
+// readHealthRequest decodes the single HealthCheckRequest message of a
+// request body: a 5-byte gRPC frame header, then protobuf with the
+// service name in field 1.
+func readHealthRequest(body io.Reader) (service string, code int, msg string) {
+	var header [5]byte
+	if _, err := io.ReadFull(body, header[:]); err != nil {
+		if err == io.EOF {
+			return "", grpcOK, "" // empty request: the server as a whole
+		}
+		return "", grpcInternal, "truncated message"
+	}
+	if header[0] != 0 {
+		return "", grpcUnimplemented, "compressed messages are not supported"
+	}
+	n := binary.BigEndian.Uint32(header[1:])
+	if n > 64<<10 {
+		return "", grpcInternal, "message too large"
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(body, buf); err != nil {
+		return "", grpcInternal, "truncated message"
+	}
+	for len(buf) > 0 {
+		key, k := binary.Uvarint(buf)
+		if k <= 0 {
+			return "", grpcInternal, "malformed message"
+		}
+		buf = buf[k:]
+		var field []byte
+		switch key & 7 {
+		case 0: // varint
+			_, k = binary.Uvarint(buf)
+		case 1: // 64-bit
+			k = 8
+		case 2: // length-delimited
+			l, lk := binary.Uvarint(buf)
+			if lk <= 0 || l > uint64(len(buf)-lk) {
+				return "", grpcInternal, "malformed message"
+			}
+			field, k = buf[lk:lk+int(l)], lk+int(l)
+		case 5: // 32-bit
+			k = 4
+		default:
+			return "", grpcInternal, "malformed message"
+		}
+		if k <= 0 || k > len(buf) {
+			return "", grpcInternal, "malformed message"
+		}
+		if key == 1<<3|2 {
+			service = string(field)
+		}
+		buf = buf[k:]
+	}
+	return service, grpcOK, ""
+}
+
+// healthResponseFrame encodes a framed HealthCheckResponse (status is
+// field 1; proto3 omits the zero value).
+func healthResponseFrame(status HealthStatus) []byte {
+	var msg []byte
+	if status != 0 {
+		msg = binary.AppendUvarint([]byte{1 << 3}, uint64(status))
+	}
+	frame := make([]byte, 5, 5+len(msg))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(msg)))
+	return append(frame, msg...)
+}
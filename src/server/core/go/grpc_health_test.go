@@ -0,0 +1,158 @@
+/* src/server/core/go/grpc_health_test.go */
+
+package seam
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// healthRequest frames a HealthCheckRequest for service.
+func healthRequest(service string) []byte {
+	msg := append([]byte{1<<3 | 2, byte(len(service))}, service...)
+	frame := make([]byte, 5, 5+len(msg))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(msg)))
+	return append(frame, msg...)
+}
+
+// readHealthResponse reads one framed HealthCheckResponse.
+func readHealthResponse(t *testing.T, r io.Reader) HealthStatus {
+	t.Helper()
+	var header [5]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		t.Fatalf("read frame: %v", err)
+	}
+	msg := make([]byte, binary.BigEndian.Uint32(header[1:]))
+	if _, err := io.ReadFull(r, msg); err != nil {
+		t.Fatalf("read frame: %v", err)
+	}
+	if len(msg) == 0 {
+		return HealthUnknown
+	}
+	status, _ := binary.Uvarint(msg[1:])
+	return HealthStatus(status)
+}
+
+func startGRPCHealth(t *testing.T, h *GRPCHealth) (string, *http.Client, context.CancelFunc) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- h.Serve(ctx, ln) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Serve: %v", err)
+		}
+	})
+	protocols := new(http.Protocols)
+	protocols.SetUnencryptedHTTP2(true)
+	client := &http.Client{Transport: &http.Transport{Protocols: protocols}}
+	return "http://" + ln.Addr().String(), client, cancel
+}
+
+func TestGRPCHealthCheck(t *testing.T) {
+	h := NewGRPCHealth()
+	h.SetServingStatus("seam.Rpc", HealthNotServing)
+	base, client, _ := startGRPCHealth(t, h)
+
+	for _, tc := range []struct {
+		method  string
+		service string
+		code    string
+		status  HealthStatus
+	}{
+		{"Check", "", "0", HealthServing},
+		{"Check", "seam.Rpc", "0", HealthNotServing},
+		{"Check", "missing", "5", -1},
+		{"List", "", "12", -1},
+	} {
+		resp, err := client.Post(base+"/grpc.health.v1.Health/"+tc.method, "application/grpc", bytes.NewReader(healthRequest(tc.service)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.ProtoMajor != 2 || resp.Header.Get("Content-Type") != "application/grpc" {
+			t.Fatalf("%s: proto %d, content type %q", tc.method, resp.ProtoMajor, resp.Header.Get("Content-Type"))
+		}
+		if tc.status >= 0 {
+			if got := readHealthResponse(t, resp.Body); got != tc.status {
+				t.Errorf("%s %q: status %s, want %s", tc.method, tc.service, got, tc.status)
+			}
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if got := resp.Trailer.Get("Grpc-Status"); got != tc.code {
+			t.Errorf("%s %q: grpc-status %q, want %s", tc.method, tc.service, got, tc.code)
+		}
+	}
+}
+
+func TestGRPCHealthWatch(t *testing.T) {
+	h := NewGRPCHealth()
+	base, client, cancel := startGRPCHealth(t, h)
+
+	resp, err := client.Post(base+"/grpc.health.v1.Health/Watch", "application/grpc", bytes.NewReader(healthRequest("")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if got := readHealthResponse(t, resp.Body); got != HealthServing {
+		t.Fatalf("initial status %s", got)
+	}
+
+	h.SetServingStatus("", HealthServing) // unchanged: no message
+	h.SetServingStatus("", HealthNotServing)
+	if got := readHealthResponse(t, resp.Body); got != HealthNotServing {
+		t.Fatalf("status %s after change", got)
+	}
+
+	// Shutdown freezes NOT_SERVING; later updates are ignored
+	h.Shutdown()
+	h.SetServingStatus("", HealthServing)
+	if got, _ := h.Status(""); got != HealthNotServing {
+		t.Fatalf("status %s after Shutdown", got)
+	}
+	h.Resume()
+	if got := readHealthResponse(t, resp.Body); got != HealthServing {
+		t.Fatalf("status %s after Resume", got)
+	}
+
+	cancel()
+	deadline := time.After(5 * time.Second)
+	read := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		close(read)
+	}()
+	select {
+	case <-read:
+	case <-deadline:
+		t.Fatal("watch stream did not end on server stop")
+	}
+}
+
+func TestGRPCHealthWatchUnknownService(t *testing.T) {
+	h := NewGRPCHealth()
+	base, client, _ := startGRPCHealth(t, h)
+	resp, err := client.Post(base+"/grpc.health.v1.Health/Watch", "application/grpc", bytes.NewReader(healthRequest("later")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if got := readHealthResponse(t, resp.Body); got != HealthServiceUnknown {
+		t.Fatalf("status %s", got)
+	}
+	h.SetServingStatus("later", HealthServing)
+	if got := readHealthResponse(t, resp.Body); got != HealthServing {
+		t.Fatalf("status %s after registration", got)
+	}
+}
@@ -6,11 +6,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
+	"net/netip"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strconv"
 	"strings"
+	"sync"
 )
 
 type appState struct {
@@ -34,9 +37,36 @@ type appState struct {
 	compiledStreamSchemas map[string]*compiledSchema
 	compiledUploadSchemas map[string]*compiledSchema
 	prerenderPages        map[string]*PageDef // route -> page (prerender only)
+	hub                   *Hub
+	versions              map[string][]string           // base name -> registered versions (oldest first)
+	channelConnect        map[string]ChannelConnectFunc // channel name -> handshake hook
+	shutdownCh            chan struct{}                 // closed when the serving http.Server shuts down
+	shutdownOnce          sync.Once
+	servers               sync.Map // *http.Server -> struct{} (shutdown hook registered)
+	stagedInputs          *stagedInputStore
+	trustedProxies        []netip.Prefix
+	ipFilters             []compiledIPFilter
+	layoutCache           *layoutCache // nil when HandlerOptions.LayoutCacheTTL is 0
+	etags                 *etagMemo    // ETags of fresh GET query responses
+	slotWarnings          slotWarnings
+	redirects             []compiledRedirect
+	locks                 LockProvider
+	pageFlights           pageFlights
+	debugWarned           sync.Map // route + "\x00" + warning -> struct{} (TemplateDebug)
+	filterWarned          sync.Map // route + "\x00" + warning -> struct{} (slot filters)
+	renderTraces          bool     // ?__seam_trace honored (HandlerOptions.RenderTraces, dev mode only)
+	sseConns              connCounter
+	polls                 pollWatchers
+	wsConns               connCounter
+	activity              *activityTracker
+	headCache             headCache
+	renderCapture         *RenderCapture  // nil in production
+	lifetime              context.Context // done once the handler is stopped or garbage collected
+	stop                  context.CancelFunc
 }
 
 func buildHandler(procedures []ProcedureDef, subscriptions []SubscriptionDef, streams []StreamDef, uploads []UploadDef, channels []ChannelDef, pages []PageDef, rpcHashMap *RpcHashMap, i18nConfig *I18nConfig, publicDir string, strategies []ResolveStrategy, contextConfigs map[string]ContextConfig, registeredState any, opts HandlerOptions, validationMode ValidationMode) http.Handler {
+	i18nConfig = withPseudoLocale(i18nConfig, opts.PseudoLocale)
 	state := &appState{
 		handlers:       make(map[string]*ProcedureDef),
 		subs:           make(map[string]*SubscriptionDef),
@@ -44,6 +74,40 @@ func buildHandler(procedures []ProcedureDef, subscriptions []SubscriptionDef, st
 		i18nConfig:     i18nConfig,
 		contextConfigs: contextConfigs,
 		appState:       registeredState,
+		hub:            opts.Hub,
+		shutdownCh:     make(chan struct{}),
+		stagedInputs:   newStagedInputStore(),
+		trustedProxies: parsePrefixes("TrustedProxies", opts.TrustedProxies),
+		ipFilters:      compileIPFilters(opts.IPFilters),
+		redirects:      compileRedirects(opts.Redirects),
+		locks:          opts.Locks,
+		activity:       opts.activity,
+	}
+	if state.activity == nil {
+		state.activity = newActivityTracker()
+	}
+	if !isProduction() {
+		state.renderCapture = opts.RenderCapture
+	}
+	if state.hub == nil {
+		state.hub = NewHub()
+	}
+	if state.locks == nil {
+		state.locks = MemoryLocks()
+	}
+	if opts.Tuning != nil {
+		opts.Tuning.init(opts)
+	}
+	// Background watchers end with the handler: stopped explicitly, or
+	// once nothing references it (rebuilt handlers must not pile up hub
+	// subscribers). The cleanup captures only the cancel func.
+	state.lifetime, state.stop = context.WithCancel(context.Background())
+	runtime.AddCleanup(state, func(stop context.CancelFunc) { stop() }, state.stop)
+	state.etags = newETagMemo()
+	if opts.LayoutCacheTTL > 0 {
+		state.layoutCache = newLayoutCache(opts.LayoutCacheTTL)
+		state.layoutCache.tuning = opts.Tuning
+		state.layoutCache.watch(state.lifetime, state.hub, state.shutdownCh)
 	}
 
 	if len(strategies) > 0 {
@@ -64,25 +128,51 @@ func buildHandler(procedures []ProcedureDef, subscriptions []SubscriptionDef, st
 		state.batchHash = rpcHashMap.Batch
 		// Built-in procedures bypass hash obfuscation (identity mapping)
 		state.hashToName["seam.i18n.query"] = "seam.i18n.query"
+		state.hashToName[StageInputProcedure] = StageInputProcedure
 	}
 
+	if opts.Sandbox {
+		checkSandboxed(procedures)
+	}
+	opts.SignedURLs.check()
+
 	// Expand channels into Level 0 primitives
 	var channelMetas map[string]channelMeta
 	for _, ch := range channels {
-		procs, subs, meta := ch.expand()
+		procs, subs, meta := ch.expand(state.hub)
 		procedures = append(procedures, procs...)
 		subscriptions = append(subscriptions, subs...)
 		if channelMetas == nil {
 			channelMetas = make(map[string]channelMeta)
 		}
 		channelMetas[ch.Name] = meta
+		if ch.OnConnect != nil {
+			if state.channelConnect == nil {
+				state.channelConnect = make(map[string]ChannelConnectFunc)
+			}
+			state.channelConnect[ch.Name] = ch.OnConnect
+		}
 	}
 
 	// Build manifest
 	manifest := buildManifest(procedures, subscriptions, streams, uploads, channelMetas, state.contextConfigs)
+	manifest.Pages = pageAnnotations(pages)
 	state.manifestJSON, _ = json.Marshal(manifest)
+	if opts.PinnedManifest != "" {
+		checkPinnedManifest(opts.PinnedManifest, state.manifestJSON)
+	}
+
+	checkPolicies(opts.Policies, procedures, pages)
+	procedures = applyAdditionalProperties(procedures)
 
+	if mocks := mockProfilesFor(opts); mocks != nil {
+		procedures = mocks.apply(procedures)
+	}
 	state.registerProcedures(procedures, subscriptions, streams, uploads)
+	if state.hasCacheableQuery() {
+		state.etags.watch(state.lifetime, state.hub, state.shutdownCh)
+	}
+	checkBoundLoaders(pages, state.handlers)
 
 	// Register built-in seam.i18n.query procedure when i18n is configured
 	if i18nConfig != nil {
@@ -104,7 +194,7 @@ func buildHandler(procedures []ProcedureDef, subscriptions []SubscriptionDef, st
 				if !validLocales[locale] {
 					locale = i18nCfg.Default
 				}
-				messages := lookupI18nMessages(i18nCfg, req.Route, locale)
+				messages := state.i18nMessages(req.Route, locale)
 				result := map[string]json.RawMessage{
 					"messages": messages,
 				}
@@ -121,10 +211,17 @@ func buildHandler(procedures []ProcedureDef, subscriptions []SubscriptionDef, st
 		state.handlers["seam.i18n.query"] = &i18nQueryProc
 	}
 
+	// Register built-in subscription input staging command
+	stageProc := state.stageInputProcedure()
+	state.handlers[StageInputProcedure] = &stageProc
+
+	// Register built-in invalidation subscription (kept out of the manifest)
+	invalidationSub := invalidationSubscription(state.hub)
+	state.subs[InvalidationSubscription] = &invalidationSub
+
 	state.shouldValidate = shouldValidateMode(validationMode)
-	if state.shouldValidate {
-		state.compileValidationSchemas()
-	}
+	state.renderTraces = opts.RenderTraces && !isProduction()
+	state.compileValidationSchemas()
 
 	// Collect prerender page info for data endpoint
 	prerenderPages := make(map[string]*PageDef)
@@ -139,7 +236,11 @@ func buildHandler(procedures []ProcedureDef, subscriptions []SubscriptionDef, st
 	mux.HandleFunc("GET /_seam/manifest.json", state.handleManifest)
 	mux.HandleFunc("POST /_seam/procedure/{name}", state.handleRPC)
 	mux.HandleFunc("GET /_seam/procedure/{name}", state.handleSubscribe)
+	mux.HandleFunc("GET /_seam/procedure/{name}/poll", state.handlePoll)
 	mux.HandleFunc("GET /_seam/data/{path...}", state.handlePageData)
+	if opts.WebSocketRPC {
+		mux.HandleFunc("GET "+wsRPCPath, state.handleWsRPC)
+	}
 
 	// Pages are served under /_seam/page/* prefix only.
 	// Root-path serving (e.g. "/" or "/dashboard/:id") is the application's
@@ -157,19 +258,40 @@ func buildHandler(procedures []ProcedureDef, subscriptions []SubscriptionDef, st
 	for i := range pages {
 		goPattern := seamRouteToGoPattern(pages[i].Route)
 		page := &pages[i]
-		mux.HandleFunc("GET /_seam/page"+goPattern, state.makePageHandler(page))
+		if opts.NamespacedSlots || page.NamespacedSlots {
+			checkNamespacedSlots(page)
+		}
+		checkDataScripts(page)
+		checkRobots(page)
+		mux.Handle("GET /_seam/page"+goPattern, state.makePageHandler(page))
+		methods := pageMethods(page)
+		for _, m := range methods {
+			mux.Handle(m+" /_seam/page"+goPattern, state.makePageFormHandler(page))
+		}
 
 		// Only register locale-prefixed routes when url_prefix strategy is present
 		if i18nConfig != nil && hasUrlPrefix {
-			localePattern := "GET /_seam/page/{_seam_locale}" + goPattern
-			mux.HandleFunc(localePattern, state.makePageHandler(page))
+			localePattern := "/_seam/page/{_seam_locale}" + goPattern
+			mux.Handle("GET "+localePattern, state.makePageHandler(page))
+			for _, m := range methods {
+				mux.Handle(m+" "+localePattern, state.makePageFormHandler(page))
+			}
 		}
 	}
 
+	var handler http.Handler = mux
 	if publicDir != "" {
-		return &publicFileHandler{mux: mux, dir: publicDir}
+		handler = &publicFileHandler{mux: mux, dir: publicDir}
 	}
-	return mux
+	return &builtHandler{Handler: state.wrapMiddleware(handler), stop: state.stop}
+}
+
+// builtHandler is the handler buildHandler returns. stop ends its
+// background watchers once it is no longer served (BuildSet.Unload);
+// dropped handlers are stopped when collected.
+type builtHandler struct {
+	http.Handler
+	stop context.CancelFunc
 }
 
 // publicFileHandler wraps a mux and serves static public files for
@@ -239,6 +361,8 @@ func (s *appState) registerProcedures(procedures []ProcedureDef, subscriptions [
 		s.uploads[uploads[i].Name] = &uploads[i]
 	}
 
+	s.versions = buildVersionIndex(s.handlers)
+
 	// Build kind map for POST dispatcher
 	s.kindMap = make(map[string]string)
 	for name, p := range s.handlers {
@@ -257,14 +381,25 @@ func (s *appState) registerProcedures(procedures []ProcedureDef, subscriptions [
 }
 
 // compileValidationSchemas pre-compiles JTD schemas for all registered
-// procedures, subscriptions, streams, and uploads.
+// procedures, subscriptions, streams, and uploads when validation is on.
+// Procedures whose AdditionalProperties is reject are compiled (and
+// validated) in every validation mode.
 func (s *appState) compileValidationSchemas() {
 	s.compiledInputSchemas = make(map[string]*compiledSchema)
 	for name, proc := range s.handlers {
+		if !s.shouldValidate && proc.AdditionalProperties != AdditionalPropertiesReject {
+			continue
+		}
 		if cs, err := compileSchema(proc.InputSchema); err == nil {
+			if proc.AdditionalProperties != "" {
+				cs.setAllowExtra(proc.AdditionalProperties != AdditionalPropertiesReject)
+			}
 			s.compiledInputSchemas[name] = cs
 		}
 	}
+	if !s.shouldValidate {
+		return
+	}
 	s.compiledSubSchemas = make(map[string]*compiledSchema)
 	for name, sub := range s.subs {
 		if cs, err := compileSchema(sub.InputSchema); err == nil {
@@ -296,6 +431,13 @@ func (s *appState) handleRPC(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// POST to a subscription streams SSE with the body as input
+	// (subscription names are not hashed, matching the GET form)
+	if sub, ok := s.subs[name]; ok {
+		s.handleSubscribePost(w, r, sub)
+		return
+	}
+
 	// Resolve hash -> original name when hash map is present
 	if s.hashToName != nil {
 		resolved, ok := s.hashToName[name]
@@ -305,6 +447,7 @@ func (s *appState) handleRPC(w http.ResponseWriter, r *http.Request) {
 		}
 		name = resolved
 	}
+	name = s.resolveVersion(name, r)
 
 	// Dispatch to stream/upload handlers based on kind
 	if kind := s.kindMap[name]; kind == "stream" {
@@ -320,10 +463,13 @@ func (s *appState) handleRPC(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusNotFound, NotFoundError(fmt.Sprintf("Procedure '%s' not found", name)))
 		return
 	}
-
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		writeError(w, http.StatusBadRequest, ValidationError("Failed to read request body"))
+	warning := s.deprecationWarning(r, name, proc)
+	if warning != "" {
+		addWarningHeader(w, warning)
+	}
+	body, readErr := s.readBody(w, r)
+	if readErr != nil {
+		writeError(w, errorHTTPStatus(readErr), readErr)
 		return
 	}
 
@@ -332,49 +478,63 @@ func (s *appState) handleRPC(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx := r.Context()
-	// Inject context from headers
-	if len(s.contextConfigs) > 0 && len(proc.ContextKeys) > 0 {
-		rawCtx := extractRawContext(r, s.contextConfigs)
-		filtered := resolveContextForProc(rawCtx, proc.ContextKeys)
-		ctx = injectContext(ctx, filtered)
+	body, fields, seamErr := takeFields(body)
+	if seamErr == nil && fields == nil {
+		fields = headerFields(r)
 	}
-	ctx = injectState(ctx, s.appState)
-	if s.opts.RPCTimeout > 0 {
-		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, s.opts.RPCTimeout)
-		defer cancel()
+	if seamErr == nil && fields != nil {
+		seamErr = checkFields(fields, proc.OutputSchema)
 	}
-
-	if s.shouldValidate {
-		if cs, ok := s.compiledInputSchemas[name]; ok {
-			var parsed any
-			_ = json.Unmarshal(body, &parsed)
-			if msg, details := validateCompiled(cs, parsed); msg != "" {
-				writeError(w, 400, ValidationErrorDetailed(
-					fmt.Sprintf("Input validation failed for procedure '%s': %s", name, msg), toAnySlice(details)))
-				return
-			}
-		}
+	if seamErr != nil {
+		writeError(w, http.StatusBadRequest, seamErr)
+		return
 	}
 
-	result, err := proc.Handler(ctx, body)
-	if err != nil {
-		if ctx.Err() == context.DeadlineExceeded {
-			writeError(w, http.StatusGatewayTimeout, NewError("INTERNAL_ERROR", "RPC timed out", http.StatusGatewayTimeout))
-			return
-		}
-		if seamErr, ok := err.(*Error); ok {
-			status := errorHTTPStatus(seamErr)
-			writeError(w, status, seamErr)
-		} else {
-			writeError(w, http.StatusInternalServerError, InternalError(err.Error()))
-		}
+	var canary *string
+	if proc.canary != nil {
+		var ctx context.Context
+		ctx, canary = withCanarySlot(r.Context())
+		r = r.WithContext(ctx)
+	}
+	result, seamErr := s.callProcedure(r, name, proc, body)
+	if canary != nil && *canary != "" {
+		w.Header().Set(CanaryHeader, *canary)
+	}
+	if seamErr != nil {
+		writeError(w, errorHTTPStatus(seamErr), seamErr)
 		return
 	}
+	if fields != nil {
+		result = projectResult(result, fields)
+	}
 
+	envelope := map[string]any{"ok": true, "data": result}
+	if warning != "" {
+		envelope["meta"] = resultMeta{Warnings: []string{warning}}
+	}
+	if hints, ok := cacheHints(proc); ok {
+		// POST responses are not revalidated; GET /_seam/procedure/{name} carries the ETag
+		hints.MaxAge = s.opts.Tuning.queryMaxAgeOf(hints.MaxAge)
+		hints.setHeaders(w.Header())
+	}
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "data": result})
+	_ = writeJSON(w, envelope)
+}
+
+// callProcedure runs a query or command for an HTTP request: context
+// injection and RPC timeout, then dispatch.
+func (s *appState) callProcedure(r *http.Request, name string, proc *ProcedureDef, body []byte) (any, *Error) {
+	var rawCtx map[string]any
+	if len(s.contextConfigs) > 0 && len(proc.ContextKeys) > 0 {
+		rawCtx = extractRawContext(r, s.contextConfigs)
+	}
+	ctx := s.procedureContext(s.requestContext(r), rawCtx, proc)
+	if timeout := s.opts.Tuning.rpcTimeoutOr(s.opts.RPCTimeout); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return s.dispatch(ctx, r, name, proc, body)
 }
 
 // --- page data handler ---
@@ -412,19 +572,41 @@ func (s *appState) handlePageData(w http.ResponseWriter, r *http.Request) {
 
 func writeError(w http.ResponseWriter, status int, e *Error) {
 	w.Header().Set("Content-Type", "application/json")
+	if secs := e.retryAfterSeconds(); secs > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(secs))
+	}
 	w.WriteHeader(status)
+	_ = writeJSON(w, map[string]any{
+		"ok":    false,
+		"error": errorObject(e),
+	})
+}
+
+// errorObject renders the error envelope shared by HTTP responses and SSE
+// error events. retryAfterMs is present when
+// the client should back off before retrying; pollIntervalMs when a
+// refused stream should be polled instead.
+func errorObject(e *Error) map[string]any {
 	errObj := map[string]any{
 		"code":      e.Code,
 		"message":   e.Message,
-		"transient": false,
+		"transient": e.Transient,
 	}
 	if e.Details != nil {
 		errObj["details"] = e.Details
 	}
-	_ = json.NewEncoder(w).Encode(map[string]any{
-		"ok":    false,
-		"error": errObj,
-	})
+	if ms := e.retryAfterMs(); ms > 0 {
+		errObj["retryAfterMs"] = ms
+	}
+	if e.PollInterval > 0 {
+		errObj["pollIntervalMs"] = e.PollInterval.Milliseconds()
+	}
+	return errObj
+}
+
+// rpcTimeoutError is returned when a call exceeds the RPC timeout.
+func rpcTimeoutError() *Error {
+	return NewError("INTERNAL_ERROR", "RPC timed out", http.StatusGatewayTimeout)
 }
 
 func errorHTTPStatus(e *Error) int {
@@ -443,6 +625,6 @@ func toAnySlice(details []ValidationDetail) []any {
 }
 
 func mustJSON(v any) string {
-	b, _ := json.Marshal(v)
+	b, _ := codecMarshal(v)
 	return string(b)
 }
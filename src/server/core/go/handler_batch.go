@@ -6,7 +6,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"sync"
 	"time"
@@ -16,6 +15,7 @@ import (
 
 type batchRequest struct {
 	Calls []batchCall `json:"calls"`
+	Refs  bool        `json:"refs,omitempty"` // resolve {"$ref": ...} inputs (batch_refs.go)
 }
 
 type batchCall struct {
@@ -27,46 +27,86 @@ type batchResult struct {
 	Ok    bool        `json:"ok"`
 	Data  any         `json:"data,omitempty"`
 	Error *batchError `json:"error,omitempty"`
+	Meta  *resultMeta `json:"meta,omitempty"`
 }
 
 type batchError struct {
-	Code      string `json:"code"`
-	Message   string `json:"message"`
-	Transient bool   `json:"transient"`
-	Details   []any  `json:"details,omitempty"`
+	Code         string `json:"code"`
+	Message      string `json:"message"`
+	Transient    bool   `json:"transient"`
+	RetryAfterMs int64  `json:"retryAfterMs,omitempty"`
+	Details      []any  `json:"details,omitempty"`
+}
+
+func toBatchError(e *Error) *batchError {
+	return &batchError{
+		Code: e.Code, Message: e.Message, Details: e.Details, Transient: e.Transient,
+		RetryAfterMs: e.retryAfterMs(),
+	}
 }
 
 func (s *appState) handleBatch(w http.ResponseWriter, r *http.Request) {
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		writeError(w, http.StatusBadRequest, ValidationError("Failed to read request body"))
+	body, readErr := s.readBody(w, r)
+	if readErr != nil {
+		writeError(w, errorHTTPStatus(readErr), readErr)
 		return
 	}
 
 	var batch batchRequest
-	if err := json.Unmarshal(body, &batch); err != nil {
+	if err := codecUnmarshal(body, &batch); err != nil {
 		writeError(w, http.StatusBadRequest, ValidationError("Invalid batch JSON"))
 		return
 	}
 
-	ctx := r.Context()
+	if limitErr := s.checkBatch(w, r, batch.Calls); limitErr != nil {
+		writeError(w, http.StatusBadRequest, limitErr)
+		return
+	}
+
 	// Extract raw context once for all batch calls
 	var rawCtx map[string]any
 	if len(s.contextConfigs) > 0 {
 		rawCtx = extractRawContext(r, s.contextConfigs)
 	}
-	if s.opts.RPCTimeout > 0 {
-		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, s.opts.RPCTimeout)
-		defer cancel()
-	}
+	ctx, cancel, timeoutErr := s.batchDeadline(s.requestContext(r))
+	defer cancel()
 
 	results := make([]batchResult, len(batch.Calls))
+	warnings := make([]string, len(batch.Calls))
+	deps, depErrs := make([][]int, len(batch.Calls)), make([]*Error, len(batch.Calls))
+	if batch.Refs {
+		deps, depErrs = batchDependencies(batch.Calls)
+	}
+	done := make([]chan struct{}, len(batch.Calls))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
 	var wg sync.WaitGroup
 	for i, call := range batch.Calls {
 		wg.Add(1)
 		go func(i int, call batchCall) {
 			defer wg.Done()
+			defer close(done[i])
+			if depErrs[i] != nil {
+				results[i] = batchResult{Ok: false, Error: toBatchError(depErrs[i])}
+				return
+			}
+			if len(deps[i]) > 0 {
+				for _, d := range deps[i] {
+					select {
+					case <-done[d]:
+					case <-ctx.Done():
+						results[i] = batchResult{Ok: false, Error: toBatchError(timeoutErr)}
+						return
+					}
+				}
+				input, refErr := resolveBatchRefs(call.Input, deps[i], results)
+				if refErr != nil {
+					results[i] = batchResult{Ok: false, Error: refErr}
+					return
+				}
+				call.Input = input
+			}
 
 			// Resolve hash -> original name
 			name := call.Procedure
@@ -78,66 +118,89 @@ func (s *appState) handleBatch(w http.ResponseWriter, r *http.Request) {
 				}
 				name = resolved
 			}
+			name = s.resolveVersion(name, r)
 
 			proc, ok := s.handlers[name]
 			if !ok {
 				results[i] = batchResult{Ok: false, Error: &batchError{Code: "NOT_FOUND", Message: fmt.Sprintf("Procedure '%s' not found", name)}}
 				return
 			}
+			warnings[i] = s.deprecationWarning(r, name, proc)
 
 			input := call.Input
 			if len(input) == 0 {
 				input = json.RawMessage("{}")
 			}
-
-			if s.shouldValidate {
-				if cs, ok := s.compiledInputSchemas[name]; ok {
-					var parsed any
-					_ = json.Unmarshal(input, &parsed)
-					if msg, details := validateCompiled(cs, parsed); msg != "" {
-						results[i] = batchResult{Ok: false, Error: &batchError{
-							Code:    "VALIDATION_ERROR",
-							Message: fmt.Sprintf("Input validation failed for procedure '%s': %s", name, msg),
-							Details: toAnySlice(details),
-						}}
-						return
-					}
-				}
+			input, fields, fieldsErr := takeFields(input)
+			if fieldsErr == nil && fields != nil {
+				fieldsErr = checkFields(fields, proc.OutputSchema)
 			}
-
-			// Inject per-procedure context
-			callCtx := ctx
-			if rawCtx != nil && len(proc.ContextKeys) > 0 {
-				filtered := resolveContextForProc(rawCtx, proc.ContextKeys)
-				callCtx = injectContext(callCtx, filtered)
+			if fieldsErr != nil {
+				results[i] = batchResult{Ok: false, Error: &batchError{Code: fieldsErr.Code, Message: fieldsErr.Message}}
+				return
 			}
-			callCtx = injectState(callCtx, s.appState)
 
-			result, err := proc.Handler(callCtx, input)
-			if err != nil {
+			callCtx := s.procedureContext(ctx, rawCtx, proc)
+			var canary *string
+			if proc.canary != nil {
+				callCtx, canary = withCanarySlot(callCtx)
+			}
+			result, seamErr := s.dispatch(callCtx, r, name, proc, input)
+			if seamErr != nil {
 				if ctx.Err() == context.DeadlineExceeded {
-					results[i] = batchResult{Ok: false, Error: &batchError{Code: "INTERNAL_ERROR", Message: "RPC timed out"}}
-					return
+					seamErr = timeoutErr
 				}
-				if seamErr, ok := err.(*Error); ok {
-					results[i] = batchResult{Ok: false, Error: &batchError{Code: seamErr.Code, Message: seamErr.Message, Details: seamErr.Details}}
-				} else {
-					results[i] = batchResult{Ok: false, Error: &batchError{Code: "INTERNAL_ERROR", Message: err.Error()}}
+				results[i] = batchResult{Ok: false, Error: toBatchError(seamErr)}
+			} else {
+				if fields != nil {
+					result = projectResult(result, fields)
 				}
-				return
+				results[i] = batchResult{Ok: true, Data: result}
+				if warnings[i] != "" {
+					results[i].Meta = &resultMeta{Warnings: []string{warnings[i]}}
+				}
+			}
+			if canary != nil && *canary != "" {
+				if results[i].Meta == nil {
+					results[i].Meta = &resultMeta{}
+				}
+				results[i].Meta.Canary = *canary
 			}
-			results[i] = batchResult{Ok: true, Data: result}
 		}(i, call)
 	}
 	wg.Wait()
 
+	seen := make(map[string]bool)
+	for _, warning := range warnings {
+		if warning != "" && !seen[warning] {
+			seen[warning] = true
+			addWarningHeader(w, warning)
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "data": map[string]any{"results": results}})
+	_ = writeJSON(w, map[string]any{"ok": true, "data": map[string]any{"results": results}})
 }
 
 // --- subscribe handler ---
 
 func (s *appState) handleSubscribe(w http.ResponseWriter, r *http.Request) {
+	s.watchServer(r)
+	if s.shuttingDown() {
+		writeError(w, http.StatusServiceUnavailable, s.unavailableError())
+		return
+	}
+	if _, isSub := s.subs[r.PathValue("name")]; !isSub && !isWebSocketUpgrade(r) {
+		if query, proc, hints, ok := s.cacheableQuery(r, r.PathValue("name")); ok {
+			s.handleQueryGet(w, r, query, proc, hints)
+			return
+		}
+	}
+	r, ok := s.checkSignedURL(w, r)
+	if !ok {
+		return
+	}
+
 	if isWebSocketUpgrade(r) {
 		s.handleChannelWs(w, r)
 		return
@@ -151,28 +214,54 @@ func (s *appState) handleSubscribe(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	inputStr := r.URL.Query().Get("input")
-	var rawInput json.RawMessage
-	if inputStr != "" {
-		rawInput = json.RawMessage(inputStr)
-	} else {
-		rawInput = json.RawMessage("{}")
+	rawInput, inputErr := s.subscriptionInput(r, name)
+	if inputErr != nil {
+		writeSSEError(w, inputErr)
+		return
 	}
+	s.streamSubscription(w, r, sub, rawInput)
+}
 
-	if s.shouldValidate {
-		if cs, ok := s.compiledSubSchemas[name]; ok {
-			var parsed any
-			_ = json.Unmarshal(rawInput, &parsed)
-			if msg, details := validateCompiled(cs, parsed); msg != "" {
-				writeSSEError(w, ValidationErrorDetailed(
-					fmt.Sprintf("Input validation failed for subscription '%s': %s", name, msg), toAnySlice(details)))
-				return
-			}
-		}
+// handleSubscribePost starts a subscription from a POST with the input in
+// the body (fetch-based EventSource), for proxies that strip long queries.
+func (s *appState) handleSubscribePost(w http.ResponseWriter, r *http.Request, sub *SubscriptionDef) {
+	s.watchServer(r)
+	if s.shuttingDown() {
+		writeError(w, http.StatusServiceUnavailable, s.unavailableError())
+		return
+	}
+
+	body, readErr := s.readBody(w, r)
+	if readErr != nil {
+		writeError(w, errorHTTPStatus(readErr), readErr)
+		return
+	}
+	rawInput := json.RawMessage(body)
+	if len(body) == 0 {
+		rawInput = json.RawMessage("{}")
+	}
+	if inputErr := s.validateSubscriptionInput(sub.Name, rawInput); inputErr != nil {
+		writeSSEError(w, inputErr)
+		return
 	}
+	s.streamSubscription(w, r, sub, rawInput)
+}
 
-	subCtx := r.Context()
-	if lastID := r.Header.Get("Last-Event-ID"); lastID != "" {
+// streamSubscription runs the subscription handler and streams its events as SSE.
+func (s *appState) streamSubscription(w http.ResponseWriter, r *http.Request, sub *SubscriptionDef, rawInput json.RawMessage) {
+	release, ok := s.acquireSSE(w, true)
+	if !ok {
+		return
+	}
+	defer release()
+	subCtx, cancel := context.WithCancel(s.requestContext(r))
+	defer cancel()
+	resume, seq, lastID, resumeErr := s.resumeStream(subCtx, r, sub)
+	if resumeErr != nil {
+		writeSSEError(w, resumeErr)
+		return
+	}
+	if lastID != "" {
 		subCtx = context.WithValue(subCtx, lastEventIDKey, lastID)
 	}
 	if len(s.contextConfigs) > 0 && len(sub.ContextKeys) > 0 {
@@ -181,6 +270,10 @@ func (s *appState) handleSubscribe(w http.ResponseWriter, r *http.Request) {
 		subCtx = injectContext(subCtx, filtered)
 	}
 	subCtx = injectState(subCtx, s.appState)
+	if rateErr := s.opts.RateLimits.check(subCtx, "subscription"); rateErr != nil {
+		writeSSEError(w, rateErr)
+		return
+	}
 
 	ch, err := sub.Handler(subCtx, rawInput)
 	if err != nil {
@@ -191,6 +284,7 @@ func (s *appState) handleSubscribe(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
+	defer trackEvents(s.activity, "subscription", sub.Name, ch)()
 
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
@@ -205,7 +299,6 @@ func (s *appState) handleSubscribe(w http.ResponseWriter, r *http.Request) {
 	heartbeatTicker := time.NewTicker(s.opts.HeartbeatInterval)
 	defer heartbeatTicker.Stop()
 
-	seq := 0
 	var idleTimer *time.Timer
 	if idle > 0 {
 		idleTimer = time.NewTimer(idle)
@@ -219,7 +312,7 @@ func (s *appState) handleSubscribe(w http.ResponseWriter, r *http.Request) {
 				if !ok {
 					goto complete
 				}
-				writeSSEEvent(w, ev, seq)
+				s.writeSSEEvent(w, ev, resume.eventID(subCtx, ev, seq))
 				seq++
 				if canFlush {
 					flusher.Flush()
@@ -232,12 +325,15 @@ func (s *appState) handleSubscribe(w http.ResponseWriter, r *http.Request) {
 				}
 				idleTimer.Reset(idle)
 			case <-heartbeatTicker.C:
-				_, _ = fmt.Fprintf(w, ": heartbeat\n\n")
+				s.writeSSEHeartbeat(w)
 				if canFlush {
 					flusher.Flush()
 				}
 			case <-idleTimer.C:
 				goto complete
+			case <-s.shutdownCh:
+				s.writeSSERestarting(w)
+				return
 			case <-r.Context().Done():
 				return
 			}
@@ -247,16 +343,19 @@ func (s *appState) handleSubscribe(w http.ResponseWriter, r *http.Request) {
 				if !ok {
 					goto complete
 				}
-				writeSSEEvent(w, ev, seq)
+				s.writeSSEEvent(w, ev, resume.eventID(subCtx, ev, seq))
 				seq++
 				if canFlush {
 					flusher.Flush()
 				}
 			case <-heartbeatTicker.C:
-				_, _ = fmt.Fprintf(w, ": heartbeat\n\n")
+				s.writeSSEHeartbeat(w)
 				if canFlush {
 					flusher.Flush()
 				}
+			case <-s.shutdownCh:
+				s.writeSSERestarting(w)
+				return
 			case <-r.Context().Done():
 				return
 			}
@@ -264,32 +363,25 @@ func (s *appState) handleSubscribe(w http.ResponseWriter, r *http.Request) {
 	}
 
 complete:
+	resume.complete(subCtx)
 	_, _ = fmt.Fprintf(w, "event: complete\ndata: {}\n\n")
 	if canFlush {
 		flusher.Flush()
 	}
 }
 
-func writeSSEEvent(w http.ResponseWriter, ev SubscriptionEvent, seq int) {
+func (s *appState) writeSSEEvent(w http.ResponseWriter, ev SubscriptionEvent, id string) {
 	if ev.Err != nil {
-		_, _ = fmt.Fprintf(w, "event: error\ndata: %s\n\n", mustJSON(map[string]any{
-			"code": ev.Err.Code, "message": ev.Err.Message, "transient": false,
-		}))
+		_, _ = fmt.Fprintf(w, "event: error\ndata: %s\n\n", mustJSON(errorObject(s.withRetryHint(ev.Err))))
 	} else {
-		_, _ = fmt.Fprintf(w, "event: data\nid: %d\ndata: %s\n\n", seq, mustJSON(ev.Value))
+		_, _ = fmt.Fprintf(w, "event: %s\nid: %s\ndata: %s\n\n", ev.eventName(), id, mustJSON(ev.Value))
 	}
 }
 
 func writeSSEError(w http.ResponseWriter, e *Error) {
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
-	errObj := map[string]any{
-		"code": e.Code, "message": e.Message, "transient": false,
-	}
-	if e.Details != nil {
-		errObj["details"] = e.Details
-	}
-	_, _ = fmt.Fprintf(w, "event: error\ndata: %s\n\n", mustJSON(errObj))
+	_, _ = fmt.Fprintf(w, "event: error\ndata: %s\n\n", mustJSON(errorObject(e)))
 	if f, ok := w.(http.Flusher); ok {
 		f.Flush()
 	}
@@ -6,74 +6,114 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
-
-	engine "github.com/canmi21/seam/src/server/engine/go"
+	"time"
 )
 
 // --- page handler ---
 
-func (s *appState) makePageHandler(page *PageDef) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		s.servePage(w, r, page)
+func (s *appState) makePageHandler(page *PageDef) http.Handler {
+	var h http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			s.headPage(w, r, page)
+			return
+		}
+		if page.Coalesce && s.traceMode(r) == "" {
+			s.coalescePage(w, r, page)
+			return
+		}
+		s.servePage(w, r, page, nil)
+	})
+	for i := len(page.Middleware) - 1; i >= 0; i-- {
+		h = page.Middleware[i](h)
+	}
+	return h
+}
+
+// pageLocale resolves the request locale ("" when i18n is off), applying
+// HandlerOptions.OnLocaleResolved; ok is false for an unknown locale path
+// prefix.
+func (s *appState) pageLocale(r *http.Request) (locale string, ok bool) {
+	if s.i18nConfig == nil {
+		return "", true
+	}
+	pathLocale := r.PathValue("_seam_locale")
+	if pathLocale != "" && !s.localeSet[pathLocale] {
+		return "", false
 	}
+	return s.overrideLocale(r, ResolveChain(s.strategies, &ResolveData{
+		Request:       r,
+		PathLocale:    pathLocale,
+		Locales:       s.i18nConfig.Locales,
+		DefaultLocale: s.i18nConfig.Default,
+	})), true
 }
 
-func (s *appState) servePage(w http.ResponseWriter, r *http.Request, page *PageDef) {
+// servePage runs the page loaders and renders the page. form is the
+// submission outcome when re-rendering after a form post (nil for GET).
+func (s *appState) servePage(w http.ResponseWriter, r *http.Request, page *PageDef, form *formResult) {
+	if page.Robots != "" {
+		w.Header().Set("X-Robots-Tag", page.Robots)
+	}
 	// SSG short-circuit: serve pre-rendered HTML without loader execution
-	if page.Prerender && page.StaticDir != "" {
-		routePath := r.URL.Path
-		// Strip /_seam/page prefix and optional locale prefix
-		routePath = strings.TrimPrefix(routePath, "/_seam/page")
-		if routePath == "" {
-			routePath = "/"
-		}
-		subPath := routePath
-		if subPath == "/" {
-			subPath = ""
-		}
-		htmlPath, ok := resolveStaticFilePath(page.StaticDir, subPath, "index.html")
-		if ok {
-			if data, err := os.ReadFile(htmlPath); err == nil {
-				w.Header().Set("Content-Type", "text/html; charset=utf-8")
-				_, _ = w.Write(data)
-				return
-			}
+	if htmlPath, ok := prerenderedPath(page, r); ok && form == nil {
+		if data, err := os.ReadFile(htmlPath); err == nil {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write(data)
+			return
 		}
 		// Fall through to dynamic rendering (graceful degradation)
 	}
 
+	trace := s.startTrace(r, page.Route)
+	end := trace.span("params", 0)
 	params := extractParams(page.Route, r)
+	end()
 
 	// Resolve locale when i18n is active
-	var locale string
-	if s.i18nConfig != nil {
-		pathLocale := r.PathValue("_seam_locale")
-		if pathLocale != "" && !s.localeSet[pathLocale] {
-			writeError(w, http.StatusNotFound, NotFoundError("Unknown locale"))
-			return
-		}
-		locale = ResolveChain(s.strategies, &ResolveData{
-			Request:       r,
-			PathLocale:    pathLocale,
-			Locales:       s.i18nConfig.Locales,
-			DefaultLocale: s.i18nConfig.Default,
-		})
+	end = trace.span("locale", 0)
+	locale, ok := s.pageLocale(r)
+	end()
+	if !ok {
+		writeError(w, http.StatusNotFound, NotFoundError("Unknown locale"))
+		return
 	}
+	s.varyLocale(w, r)
 
 	// Select locale-specific template (pre-resolved with layout chain)
-	tmpl := page.Template
-	if locale != "" && page.LocaleTemplates != nil {
-		if lt, ok := page.LocaleTemplates[locale]; ok {
-			tmpl = lt
+	end = trace.span("template", 0)
+	tmpl, err := page.template(locale)
+	if s.opts.TemplateDebug {
+		tmpl, err = page.debugTemplate(locale)
+	}
+	end()
+	if err != nil {
+		s.pageTemplateFailed(w, r, page, err)
+		return
+	}
+	if page.lazy != nil && (s.opts.NamespacedSlots || page.NamespacedSlots) {
+		if bad := s.slotWarnings.namespaced(page, locale, tmpl); len(bad) > 0 {
+			writeError(w, http.StatusInternalServerError, InternalError("Page template references non-loader slots"))
+			return
 		}
 	}
 
-	ctx := r.Context()
+	ctx := s.requestContext(r)
+	if locale != "" {
+		ctx = withLocale(ctx, locale)
+	}
+	if trace != nil {
+		ctx = context.WithValue(ctx, renderTraceKey, trace)
+	}
+	if scope, ok := ctx.Value(flagScopeKey).(*flagScope); ok && locale != "" {
+		scope.target.Locale = locale
+	}
 	if s.opts.PageTimeout > 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, s.opts.PageTimeout)
@@ -91,11 +131,24 @@ func (s *appState) servePage(w http.ResponseWriter, r *http.Request, page *PageD
 
 	var wg sync.WaitGroup
 	results := make(chan loaderResult, len(page.Loaders))
+	var owners map[string]string
+	if s.layoutCache != nil {
+		owners = layoutOwners(page)
+	}
 
-	for _, loader := range page.Loaders {
+	degraded := s.opts.ResourceGuard.degraded()
+	var skipped []LoaderDef
+	for i, loader := range page.Loaders {
+		if degraded && loader.Optional {
+			skipped = append(skipped, loader)
+			continue
+		}
 		wg.Add(1)
-		go func(ld LoaderDef) {
+		done := s.activity.track("loader", page.Route+" "+loader.DataKey)
+		go func(ld LoaderDef, lane int) {
 			defer wg.Done()
+			defer done()
+			defer trace.span(fmt.Sprintf("loader %s (%s)", ld.DataKey, ld.Procedure), lane)()
 			input := ld.InputFn(params)
 			inputJSON, err := json.Marshal(input)
 			if err != nil {
@@ -109,15 +162,23 @@ func (s *appState) servePage(w http.ResponseWriter, r *http.Request, page *PageD
 				return
 			}
 
-			if s.shouldValidate {
-				if cs, ok := s.compiledInputSchemas[ld.Procedure]; ok {
-					var parsed any
-					_ = json.Unmarshal(inputJSON, &parsed)
-					if msg, details := validateCompiled(cs, parsed); msg != "" {
-						results <- loaderResult{key: ld.DataKey, err: ValidationErrorDetailed(
-							fmt.Sprintf("Input validation failed for procedure '%s': %s", ld.Procedure, msg), toAnySlice(details))}
-						return
-					}
+			// Layout loaders: serve from the cross-request cache when fresh
+			var cacheKey string
+			if layoutID, ok := owners[ld.DataKey]; ok && (PrincipalOf(ctx) != "" || slices.Contains(s.opts.PublicLayouts, layoutID)) {
+				cacheKey = layoutCacheKey(layoutID, PrincipalOf(ctx), ld.DataKey, inputJSON)
+				if value, hit := s.layoutCache.get(cacheKey); hit {
+					results <- loaderResult{key: ld.DataKey, value: value, procedure: ld.Procedure, input: input}
+					return
+				}
+			}
+
+			if cs, ok := s.compiledInputSchemas[ld.Procedure]; ok {
+				var parsed any
+				_ = codecUnmarshal(inputJSON, &parsed)
+				if msg, details := validateCompiled(cs, parsed); msg != "" {
+					results <- loaderResult{key: ld.DataKey, err: ValidationErrorDetailed(
+						fmt.Sprintf("Input validation failed for procedure '%s': %s", ld.Procedure, msg), toAnySlice(details))}
+					return
 				}
 			}
 
@@ -130,8 +191,11 @@ func (s *appState) servePage(w http.ResponseWriter, r *http.Request, page *PageD
 			loaderCtx = injectState(loaderCtx, s.appState)
 
 			result, err := proc.Handler(loaderCtx, inputJSON)
+			if err == nil && cacheKey != "" && !degraded {
+				s.layoutCache.set(cacheKey, ld.Procedure, inputJSON, result)
+			}
 			results <- loaderResult{key: ld.DataKey, value: result, procedure: ld.Procedure, input: input, err: err}
-		}(loader)
+		}(loader, i+1)
 	}
 
 	go func() {
@@ -142,23 +206,34 @@ func (s *appState) servePage(w http.ResponseWriter, r *http.Request, page *PageD
 	// Collect loader results with per-loader error boundary
 	data := make(map[string]any)
 	loaderMeta := make(map[string]any)
+	for _, ld := range skipped {
+		loaderMeta[ld.DataKey] = map[string]any{"procedure": ld.Procedure, "skipped": true}
+	}
+	var failed *Error // outermost loader failure, for layout error boundaries
+	failedOwner := -1 // layout chain index owning the failed loader
 	for res := range results {
 		if res.err != nil {
 			// Shared context deadline = page-level error (all loaders affected)
 			if ctx.Err() == context.DeadlineExceeded {
-				writeError(w, http.StatusGatewayTimeout, NewError("INTERNAL_ERROR", "Page loader timed out", http.StatusGatewayTimeout))
+				timeout := NewError("INTERNAL_ERROR", "Page loader timed out", http.StatusGatewayTimeout)
+				if b := errorBoundary(page, len(page.LayoutChain)); b >= 0 {
+					s.renderErrorBoundary(ctx, w, page, b, data, loaderMeta, locale, timeout)
+					return
+				}
+				writeError(w, timeout.Status, timeout)
 				return
 			}
 			// Per-loader error boundary: error marker instead of aborting the page
-			code := "INTERNAL_ERROR"
-			message := res.err.Error()
-			if seamErr, ok := res.err.(*Error); ok {
-				code = seamErr.Code
-				message = seamErr.Message
+			seamErr, ok := res.err.(*Error)
+			if !ok {
+				seamErr = InternalError(res.err.Error())
 			}
-			fmt.Fprintf(os.Stderr, "[seam] Loader %q failed: %v\n", res.key, res.err)
-			data[res.key] = map[string]any{"__error": true, "code": code, "message": message}
+			logf(slog.LevelWarn, "Loader %q failed: %v\n", res.key, res.err)
+			data[res.key] = map[string]any{"__error": true, "code": seamErr.Code, "message": seamErr.Message}
 			loaderMeta[res.key] = map[string]any{"procedure": res.procedure, "input": res.input, "error": true}
+			if owner := loaderOwnerIndex(page, res.key); failed == nil || owner < failedOwner {
+				failed, failedOwner = seamErr, owner
+			}
 			continue
 		}
 		data[res.key] = res.value
@@ -167,22 +242,80 @@ func (s *appState) servePage(w http.ResponseWriter, r *http.Request, page *PageD
 			"input":     res.input,
 		}
 	}
+	if usesTemplateEnv(tmpl) || usesTemplateEnv(page.HeadMeta) {
+		data["_env"] = s.templateEnv(r, locale)
+	}
+	if links, ok := s.pageLinks(page, publicPagePath(r), r.URL.Query(), params, data); ok {
+		ctx = withPageLinks(ctx, links)
+	}
+
+	if failed != nil {
+		if b := errorBoundary(page, failedOwner); b >= 0 {
+			s.renderErrorBoundary(ctx, w, page, b, data, loaderMeta, locale, failed)
+			return
+		}
+	}
+
+	status := http.StatusOK
+	if form != nil {
+		data["_form"] = form
+		if form.Error != nil {
+			status = errorHTTPStatus(form.Error)
+		}
+	}
+	s.renderPage(ctx, w, page, tmpl, page.LayoutChain, data, loaderMeta, locale, status)
+}
 
+// renderPage injects loader data into tmpl and writes the HTML response.
+// chain is the layout chain the template was composed from.
+func (s *appState) renderPage(ctx context.Context, w http.ResponseWriter, page *PageDef, tmpl string, chain []LayoutChainEntry, data, loaderMeta map[string]any, locale string, status int) {
 	// Prune to projected fields before template injection
 	if len(page.Projections) > 0 {
 		data = applyProjection(data, page.Projections)
 	}
+	if hasSlotFilters(tmpl) {
+		tmpl = s.applySlotFilters(page.Route, tmpl, data, locale)
+	}
+	if !s.opts.NamespacedSlots && !page.NamespacedSlots && status == http.StatusOK {
+		s.slotWarnings.check(page.Route, locale, tmpl, data)
+	}
+	dir := ""
+	if locale != "" {
+		dir = s.localeDir(locale)
+		data["_dir"] = dir
+	}
+	if s.opts.ExposeFlags {
+		if flags := Flags(ctx); flags != nil {
+			data["_flags"] = flags
+		}
+	}
 
 	// Marshal loader data to JSON (json.Marshal sorts map keys deterministically)
-	loaderDataJSON, err := json.Marshal(data)
+	trace := renderTraceOf(ctx)
+	end := trace.span("serialize", 0)
+	var exact map[string]json.RawMessage
+	var loaderDataJSON []byte
+	var err error
+	if s.opts.ExactNumbers || memoryAccountOf(ctx) != nil {
+		// Per-key encoding: kept for ExactNumbers, charged to the budget
+		if exact, err = marshalDataKeys(data); err == nil {
+			chargeLoaders(ctx, exact, data, loaderMeta)
+			loaderDataJSON, err = json.Marshal(exact)
+		}
+		if !s.opts.ExactNumbers {
+			exact = nil
+		}
+	} else {
+		loaderDataJSON, err = json.Marshal(data)
+	}
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, InternalError("Failed to serialize page data"))
 		return
 	}
 
 	// Build page config for engine
-	layoutChain := make([]map[string]any, 0, len(page.LayoutChain))
-	for _, entry := range page.LayoutChain {
+	layoutChain := make([]map[string]any, 0, len(chain))
+	for _, entry := range chain {
 		layoutChain = append(layoutChain, map[string]any{
 			"id":          entry.ID,
 			"loader_keys": entry.LoaderKeys,
@@ -197,8 +330,19 @@ func (s *appState) servePage(w http.ResponseWriter, r *http.Request, page *PageD
 		"data_id":         dataID,
 		"loader_metadata": loaderMeta,
 	}
-	if page.HeadMeta != "" {
-		config["head_meta"] = page.HeadMeta
+	headMeta := page.HeadMeta
+	if s.opts.NamespacedSlots || page.NamespacedSlots {
+		// Resolve slots before the engine flattens nested loader fields
+		if tmpl, err = injectNamespaced(tmpl, string(loaderDataJSON)); err == nil {
+			headMeta, err = injectNamespaced(headMeta, string(loaderDataJSON))
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, renderError(page.Route, err))
+			return
+		}
+	}
+	if headMeta != "" {
+		config["head_meta"] = headMeta
 	}
 	if page.Assets != nil {
 		config["page_assets"] = page.Assets
@@ -209,7 +353,7 @@ func (s *appState) servePage(w http.ResponseWriter, r *http.Request, page *PageD
 	i18nOptsJSON := ""
 	if s.i18nConfig != nil && locale != "" {
 		routeHash := s.i18nConfig.RouteHashes[page.Route]
-		messages := lookupI18nMessages(s.i18nConfig, routeHash, locale)
+		messages := s.i18nMessages(routeHash, locale)
 		i18nOpts := map[string]any{
 			"locale":         locale,
 			"default_locale": s.i18nConfig.Default,
@@ -231,14 +375,68 @@ func (s *appState) servePage(w http.ResponseWriter, r *http.Request, page *PageD
 		i18nOptsJSON = string(i18nBytes)
 	}
 
+	if s.opts.TemplateDebug {
+		tmpl = s.annotateSlots(page, tmpl, loaderDataJSON)
+	}
+	end()
+
 	// Single WASM call: slot injection + data script + head meta + lang attribute
-	html, err := engine.RenderPage(tmpl, string(loaderDataJSON), string(configJSON), i18nOptsJSON)
+	end = trace.span("render (wasm)", 0)
+	html, err := renderWithEngine(s.renderContext(ctx), tmpl, string(loaderDataJSON), string(configJSON), i18nOptsJSON)
+	end()
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, InternalError(fmt.Sprintf("Page render failed: %v", err)))
+		writeError(w, http.StatusInternalServerError, renderError(page.Route, err))
 		return
 	}
+	end = trace.span("postprocess", 0)
+	snap := RenderSnapshot{
+		DataID:       dataID,
+		ExactNumbers: exact != nil,
+		Dir:          dir,
+		Robots:       page.Robots,
+		DataScripts:  page.DataScripts,
+	}
+	if links, ok := ctx.Value(pageLinksKey{}).(PageLinks); ok {
+		snap.Links = &links
+	}
+	html = snap.postprocess(html, exact)
+	if s.opts.TemplateDebug {
+		s.checkRendered(page, html)
+	}
+	if s.opts.StrictTemplates {
+		if markers := unresolvedMarkers(html); len(markers) > 0 {
+			logf(slog.LevelWarn, "page %s has unresolved markers: %s\n", page.Route, strings.Join(markers, ", "))
+			writeError(w, http.StatusInternalServerError, InternalError("Page template has unresolved markers"))
+			return
+		}
+	}
+	end()
+	if s.renderCapture != nil {
+		snap.Route, snap.Locale, snap.CapturedAt = page.Route, locale, time.Now().UTC()
+		snap.Template, snap.Data, snap.Config, snap.HTML = tmpl, loaderDataJSON, configJSON, html
+		if i18nOptsJSON != "" {
+			snap.I18n = json.RawMessage(i18nOptsJSON)
+		}
+		s.renderCapture.record(snap)
+	}
+	if !memoryAccountOf(ctx).charge(int64(len(html))) {
+		writeError(w, http.StatusInternalServerError, memoryBudgetError("Rendered page"))
+		return
+	}
+
+	if trace != nil {
+		w.Header().Set("Cache-Control", "no-store")
+		if trace.json {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			_ = json.NewEncoder(w).Encode(trace.events())
+			return
+		}
+		html += trace.comment()
+	}
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
 	_, _ = w.Write([]byte(html))
 }
 
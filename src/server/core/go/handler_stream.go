@@ -3,9 +3,9 @@
 package seam
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"time"
 )
@@ -16,10 +16,21 @@ func (s *appState) handleStream(w http.ResponseWriter, r *http.Request, name str
 		writeError(w, http.StatusNotFound, NotFoundError(fmt.Sprintf("Stream '%s' not found", name)))
 		return
 	}
+	if s.opts.ResourceGuard.degraded() {
+		e := degradedError()
+		e.RetryAfter = s.reconnectDelay()
+		writeError(w, http.StatusServiceUnavailable, e)
+		return
+	}
+	release, ok := s.acquireSSE(w, false)
+	if !ok {
+		return
+	}
+	defer release()
 
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		writeSSEError(w, ValidationError("Failed to read request body"))
+	body, readErr := s.readBody(w, r)
+	if readErr != nil {
+		writeSSEError(w, readErr)
 		return
 	}
 
@@ -31,7 +42,7 @@ func (s *appState) handleStream(w http.ResponseWriter, r *http.Request, name str
 	if s.shouldValidate {
 		if cs, ok := s.compiledStreamSchemas[name]; ok {
 			var parsed any
-			_ = json.Unmarshal(body, &parsed)
+			_ = codecUnmarshal(body, &parsed)
 			if msg, details := validateCompiled(cs, parsed); msg != "" {
 				writeSSEError(w, ValidationErrorDetailed(
 					fmt.Sprintf("Input validation failed for stream '%s': %s", name, msg), toAnySlice(details)))
@@ -40,13 +51,18 @@ func (s *appState) handleStream(w http.ResponseWriter, r *http.Request, name str
 		}
 	}
 
-	ctx := r.Context()
+	ctx, cancel := context.WithCancel(s.requestContext(r))
+	defer cancel()
 	if len(s.contextConfigs) > 0 && len(stream.ContextKeys) > 0 {
 		rawCtx := extractRawContext(r, s.contextConfigs)
 		filtered := resolveContextForProc(rawCtx, stream.ContextKeys)
 		ctx = injectContext(ctx, filtered)
 	}
 	ctx = injectState(ctx, s.appState)
+	if rateErr := s.opts.RateLimits.check(ctx, "subscription"); rateErr != nil {
+		writeSSEError(w, rateErr)
+		return
+	}
 
 	ch, err := stream.Handler(ctx, body)
 	if err != nil {
@@ -57,6 +73,7 @@ func (s *appState) handleStream(w http.ResponseWriter, r *http.Request, name str
 		}
 		return
 	}
+	defer trackEvents(s.activity, "stream", name, ch)()
 
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
@@ -85,7 +102,7 @@ func (s *appState) handleStream(w http.ResponseWriter, r *http.Request, name str
 				if !ok {
 					goto complete
 				}
-				writeStreamEvent(w, ev, seq)
+				s.writeStreamEvent(w, ev, seq)
 				seq++
 				if canFlush {
 					flusher.Flush()
@@ -98,7 +115,7 @@ func (s *appState) handleStream(w http.ResponseWriter, r *http.Request, name str
 				}
 				idleTimer.Reset(idle)
 			case <-heartbeatTicker.C:
-				_, _ = fmt.Fprintf(w, ": heartbeat\n\n")
+				s.writeSSEHeartbeat(w)
 				if canFlush {
 					flusher.Flush()
 				}
@@ -113,13 +130,13 @@ func (s *appState) handleStream(w http.ResponseWriter, r *http.Request, name str
 				if !ok {
 					goto complete
 				}
-				writeStreamEvent(w, ev, seq)
+				s.writeStreamEvent(w, ev, seq)
 				seq++
 				if canFlush {
 					flusher.Flush()
 				}
 			case <-heartbeatTicker.C:
-				_, _ = fmt.Fprintf(w, ": heartbeat\n\n")
+				s.writeSSEHeartbeat(w)
 				if canFlush {
 					flusher.Flush()
 				}
@@ -136,11 +153,9 @@ complete:
 	}
 }
 
-func writeStreamEvent(w http.ResponseWriter, ev StreamEvent, seq int) {
+func (s *appState) writeStreamEvent(w http.ResponseWriter, ev StreamEvent, seq int) {
 	if ev.Err != nil {
-		_, _ = fmt.Fprintf(w, "event: error\ndata: %s\n\n", mustJSON(map[string]any{
-			"code": ev.Err.Code, "message": ev.Err.Message, "transient": false,
-		}))
+		_, _ = fmt.Fprintf(w, "event: error\ndata: %s\n\n", mustJSON(errorObject(s.withRetryHint(ev.Err))))
 	} else {
 		_, _ = fmt.Fprintf(w, "event: data\nid: %d\ndata: %s\n\n", seq, mustJSON(ev.Value))
 	}
@@ -37,7 +37,7 @@ func (s *appState) handleUpload(w http.ResponseWriter, r *http.Request, name str
 	if s.shouldValidate {
 		if cs, ok := s.compiledUploadSchemas[name]; ok {
 			var parsed any
-			_ = json.Unmarshal(metadata, &parsed)
+			_ = codecUnmarshal(metadata, &parsed)
 			if msg, details := validateCompiled(cs, parsed); msg != "" {
 				writeError(w, http.StatusBadRequest, ValidationErrorDetailed(
 					fmt.Sprintf("Input validation failed for upload '%s': %s", name, msg), toAnySlice(details)))
@@ -60,7 +60,7 @@ func (s *appState) handleUpload(w http.ResponseWriter, r *http.Request, name str
 		Size:     header.Size,
 	}
 
-	ctx := r.Context()
+	ctx := s.requestContext(r)
 	if len(s.contextConfigs) > 0 && len(upload.ContextKeys) > 0 {
 		rawCtx := extractRawContext(r, s.contextConfigs)
 		filtered := resolveContextForProc(rawCtx, upload.ContextKeys)
@@ -80,5 +80,5 @@ func (s *appState) handleUpload(w http.ResponseWriter, r *http.Request, name str
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "data": result})
+	_ = writeJSON(w, map[string]any{"ok": true, "data": result})
 }
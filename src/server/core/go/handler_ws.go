@@ -19,9 +19,14 @@ func isWebSocketUpgrade(r *http.Request) bool {
 	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket")
 }
 
+// MsgpackSubprotocol is the WebSocket subprotocol selecting MessagePack
+// binary frames on channel sockets; without it frames are JSON text.
+const MsgpackSubprotocol = "seam.msgpack"
+
 var wsUpgrader = websocket.Upgrader{
 	// Permissive origin check; production deployments should override.
-	CheckOrigin: func(r *http.Request) bool { return true },
+	CheckOrigin:  func(r *http.Request) bool { return true },
+	Subprotocols: []string{MsgpackSubprotocol},
 }
 
 // --- wire types ---
@@ -37,13 +42,15 @@ type wsResponse struct {
 	Ok    bool        `json:"ok"`
 	Data  interface{} `json:"data,omitempty"`
 	Error *wsError    `json:"error,omitempty"`
+	Meta  *resultMeta `json:"meta,omitempty"`
 }
 
 type wsError struct {
-	Code      string `json:"code"`
-	Message   string `json:"message"`
-	Transient bool   `json:"transient"`
-	Details   []any  `json:"details,omitempty"`
+	Code         string `json:"code"`
+	Message      string `json:"message"`
+	Transient    bool   `json:"transient"`
+	RetryAfterMs int64  `json:"retryAfterMs,omitempty"`
+	Details      []any  `json:"details,omitempty"`
 }
 
 type wsPush struct {
@@ -52,7 +59,68 @@ type wsPush struct {
 }
 
 type wsHeartbeat struct {
-	Heartbeat bool `json:"heartbeat"`
+	Heartbeat bool  `json:"heartbeat"`
+	TS        int64 `json:"ts,omitempty"` // server time (ms) when latency probes are on
+}
+
+// wsWriter serializes frames onto a WebSocket: MessagePack binary frames
+// when the seam.msgpack subprotocol was negotiated, JSON text otherwise.
+// Safe for concurrent use.
+type wsWriter struct {
+	conn   *websocket.Conn
+	mu     sync.Mutex
+	binary bool
+}
+
+func newWsWriter(conn *websocket.Conn) *wsWriter {
+	return &wsWriter{conn: conn, binary: conn.Subprotocol() == MsgpackSubprotocol}
+}
+
+func (w *wsWriter) frame(v any) error {
+	var data []byte
+	var err error
+	msgType := websocket.TextMessage
+	if w.binary {
+		data, err = encodeMsgpack(v)
+		msgType = websocket.BinaryMessage
+	} else {
+		data, err = codecMarshal(v)
+	}
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.conn.WriteMessage(msgType, data)
+}
+
+// uplinkJSON returns the JSON text of an uplink frame. Binary frames are
+// MessagePack and only accepted once the seam.msgpack subprotocol was
+// negotiated.
+func (s *appState) uplinkJSON(ws *wsWriter, msgType int, frame []byte) ([]byte, *Error) {
+	if msgType != websocket.BinaryMessage {
+		return frame, nil
+	}
+	if !ws.binary {
+		return nil, ValidationError("Binary frames require the " + MsgpackSubprotocol + " subprotocol")
+	}
+	decoded, err := msgpackToJSON(frame, s.frameDepth())
+	if err != nil {
+		return nil, ValidationError("Invalid uplink MessagePack")
+	}
+	return decoded, nil
+}
+
+func (w *wsWriter) ping(timeout time.Duration) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(timeout))
+}
+
+func (w *wsWriter) close(code int, reason string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_ = w.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason))
 }
 
 // handleChannelWs upgrades an SSE subscribe request to a WebSocket when
@@ -71,30 +139,24 @@ func (s *appState) handleChannelWs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Parse channel input from query parameter
-	inputStr := r.URL.Query().Get("input")
-	var channelInput json.RawMessage
-	if inputStr != "" {
-		channelInput = json.RawMessage(inputStr)
-	} else {
-		channelInput = json.RawMessage("{}")
+	// Parse channel input from query parameter (or staged input reference)
+	channelInput, inputErr := s.subscriptionInput(r, subName)
+	if inputErr != nil {
+		http.Error(w, inputErr.Error(), http.StatusBadRequest)
+		return
 	}
 
-	if s.shouldValidate {
-		if cs, ok := s.compiledSubSchemas[subName]; ok {
-			var parsed any
-			_ = json.Unmarshal(channelInput, &parsed)
-			if msg, details := validateCompiled(cs, parsed); msg != "" {
-				http.Error(w, ValidationErrorDetailed(
-					fmt.Sprintf("Input validation failed for subscription '%s': %s", subName, msg), toAnySlice(details)).Error(), http.StatusBadRequest)
-				return
-			}
-		}
+	release, ok := s.acquireWebSocket(w)
+	if !ok {
+		return
 	}
+	defer release()
+	defer s.activity.track("websocket", channelName)()
 
 	// Start subscription with a cancellable context
-	ctx, cancel := context.WithCancel(r.Context())
+	ctx, cancel := context.WithCancel(s.requestContext(r))
 	defer cancel()
+	ctx = context.WithValue(ctx, channelConnKey, new(byte)) // unique identity per connection
 
 	// Resolve context once at connection time
 	if len(s.contextConfigs) > 0 && len(sub.ContextKeys) > 0 {
@@ -103,15 +165,26 @@ func (s *appState) handleChannelWs(w http.ResponseWriter, r *http.Request) {
 		ctx = injectContext(ctx, filtered)
 	}
 	ctx = injectState(ctx, s.appState)
+	if rateErr := s.opts.RateLimits.check(ctx, "subscription"); rateErr != nil {
+		writeError(w, errorHTTPStatus(rateErr), rateErr)
+		return
+	}
 
-	eventCh, err := sub.Handler(ctx, channelInput)
-	if err != nil {
-		if seamErr, ok := err.(*Error); ok {
-			http.Error(w, seamErr.Message, errorHTTPStatus(seamErr))
-		} else {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+	// Without a handshake hook the subscription starts before the upgrade so
+	// errors surface as plain HTTP responses
+	onConnect := s.channelConnect[channelName]
+	var eventCh <-chan SubscriptionEvent
+	if onConnect == nil {
+		var err error
+		eventCh, err = sub.Handler(ctx, channelInput)
+		if err != nil {
+			if seamErr, ok := err.(*Error); ok {
+				http.Error(w, seamErr.Message, errorHTTPStatus(seamErr))
+			} else {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
 		}
-		return
 	}
 
 	// Upgrade to WebSocket
@@ -120,14 +193,11 @@ func (s *appState) handleChannelWs(w http.ResponseWriter, r *http.Request) {
 		// Upgrade writes its own error response
 		return
 	}
+	s.limitSocket(conn)
 
-	// Mutex protects concurrent writes (heartbeat + push + response)
-	var writeMu sync.Mutex
-	writeJSON := func(v interface{}) error {
-		writeMu.Lock()
-		defer writeMu.Unlock()
-		return conn.WriteJSON(v)
-	}
+	// Serialized writes (heartbeat + push + response)
+	ws := newWsWriter(conn)
+	writeFrame := ws.frame
 
 	// Set read deadline and pong handler for half-open connection detection.
 	// Read deadline is reset on each pong; if no pong arrives within
@@ -137,6 +207,37 @@ func (s *appState) handleChannelWs(w http.ResponseWriter, r *http.Request) {
 		return conn.SetReadDeadline(time.Now().Add(s.opts.HeartbeatInterval + s.opts.PongTimeout))
 	})
 
+	// Handshake: first frame must carry connection metadata for OnConnect
+	if onConnect != nil {
+		msgType, frame, err := conn.ReadMessage()
+		if err != nil {
+			_ = conn.Close()
+			return
+		}
+		var hs ChannelHandshake
+		if frame, frameErr := s.uplinkJSON(ws, msgType, frame); frameErr != nil {
+			err = frameErr
+		} else {
+			hs, err = parseHandshake(frame)
+		}
+		if err == nil {
+			ctx, err = acceptHandshake(ctx, onConnect, hs, channelInput)
+		}
+		if err == nil {
+			eventCh, err = sub.Handler(ctx, channelInput)
+		}
+		if err != nil {
+			closeWs(conn, err)
+			return
+		}
+		if err := writeFrame(wsPush{Event: "__connected", Payload: map[string]any{}}); err != nil {
+			_ = conn.Close()
+			return
+		}
+	}
+
+	defer trackEvents(s.activity, "subscription", subName, eventCh)()
+
 	var wg sync.WaitGroup
 
 	// --- write loop: forward subscription events + heartbeat + ping ---
@@ -151,21 +252,12 @@ func (s *appState) handleChannelWs(w http.ResponseWriter, r *http.Request) {
 			case ev, ok := <-eventCh:
 				if !ok {
 					// Subscription closed; close the WebSocket
-					writeMu.Lock()
-					_ = conn.WriteMessage(websocket.CloseMessage,
-						websocket.FormatCloseMessage(websocket.CloseNormalClosure, "subscription ended"))
-					writeMu.Unlock()
+					ws.close(websocket.CloseNormalClosure, "subscription ended")
 					cancel()
 					return
 				}
 				if ev.Err != nil {
-					if err := writeJSON(wsResponse{
-						Ok: false,
-						Error: &wsError{
-							Code:    ev.Err.Code,
-							Message: ev.Err.Message,
-						},
-					}); err != nil {
+					if err := writeFrame(wsResponse{Ok: false, Error: toWsError(s.withRetryHint(ev.Err))}); err != nil {
 						return
 					}
 					continue
@@ -174,29 +266,32 @@ func (s *appState) handleChannelWs(w http.ResponseWriter, r *http.Request) {
 				if m, ok := ev.Value.(map[string]interface{}); ok {
 					eventType, _ := m["type"].(string)
 					payload := m["payload"]
-					if err := writeJSON(wsPush{Event: eventType, Payload: payload}); err != nil {
+					if err := writeFrame(wsPush{Event: eventType, Payload: payload}); err != nil {
 						return
 					}
 				} else {
 					// Fallback: send raw value as a "data" event
-					if err := writeJSON(wsPush{Event: "data", Payload: ev.Value}); err != nil {
+					if err := writeFrame(wsPush{Event: ev.eventName(), Payload: ev.Value}); err != nil {
 						return
 					}
 				}
 
 			case <-ticker.C:
-				if err := writeJSON(wsHeartbeat{Heartbeat: true}); err != nil {
+				if err := writeFrame(s.heartbeatFrame()); err != nil {
 					return
 				}
 				// Send ping frame for half-open connection detection
-				writeMu.Lock()
-				deadline := time.Now().Add(s.opts.PongTimeout)
-				err := conn.WriteControl(websocket.PingMessage, nil, deadline)
-				writeMu.Unlock()
-				if err != nil {
+				if err := ws.ping(s.opts.PongTimeout); err != nil {
 					return
 				}
 
+			case <-s.shutdownCh:
+				_ = writeFrame(wsPush{Event: "server-restarting", Payload: s.restartNotice()})
+				ws.close(websocket.CloseGoingAway, "server restarting")
+				_ = conn.Close()
+				cancel()
+				return
+
 			case <-ctx.Done():
 				return
 			}
@@ -210,15 +305,26 @@ func (s *appState) handleChannelWs(w http.ResponseWriter, r *http.Request) {
 		defer cancel()
 
 		for {
-			_, message, err := conn.ReadMessage()
+			msgType, message, err := conn.ReadMessage()
 			if err != nil {
 				// Client disconnected or read error
 				return
 			}
+			message, frameErr := s.uplinkJSON(ws, msgType, message)
+			if frameErr != nil {
+				if err := writeFrame(wsResponse{Ok: false, Error: toWsError(frameErr)}); err != nil {
+					return
+				}
+				continue
+			}
+
+			if s.handleLatencyFrame(message, "channel", r, ws) {
+				continue
+			}
 
 			var uplink wsUplink
-			if err := json.Unmarshal(message, &uplink); err != nil {
-				if err := writeJSON(wsResponse{
+			if err := codecUnmarshal(message, &uplink); err != nil {
+				if err := writeFrame(wsResponse{
 					ID: "",
 					Ok: false,
 					Error: &wsError{
@@ -234,7 +340,7 @@ func (s *appState) handleChannelWs(w http.ResponseWriter, r *http.Request) {
 			// Validate procedure belongs to this channel (and is not .events)
 			prefix := channelName + "."
 			if !strings.HasPrefix(uplink.Procedure, prefix) || uplink.Procedure == channelName+".events" {
-				if err := writeJSON(wsResponse{
+				if err := writeFrame(wsResponse{
 					ID: uplink.ID,
 					Ok: false,
 					Error: &wsError{
@@ -252,7 +358,7 @@ func (s *appState) handleChannelWs(w http.ResponseWriter, r *http.Request) {
 			if s.hashToName != nil {
 				resolved, ok := s.hashToName[procName]
 				if !ok {
-					if err := writeJSON(wsResponse{
+					if err := writeFrame(wsResponse{
 						ID: uplink.ID,
 						Ok: false,
 						Error: &wsError{
@@ -269,7 +375,7 @@ func (s *appState) handleChannelWs(w http.ResponseWriter, r *http.Request) {
 
 			proc, ok := s.handlers[procName]
 			if !ok {
-				if err := writeJSON(wsResponse{
+				if err := writeFrame(wsResponse{
 					ID: uplink.ID,
 					Ok: false,
 					Error: &wsError{
@@ -285,87 +391,28 @@ func (s *appState) handleChannelWs(w http.ResponseWriter, r *http.Request) {
 			// Merge channel input + uplink input
 			mergedInput := mergeJSONInputs(channelInput, uplink.Input)
 
-			if s.shouldValidate {
-				if cs, ok := s.compiledInputSchemas[procName]; ok {
-					var parsed any
-					_ = json.Unmarshal(mergedInput, &parsed)
-					if msg, details := validateCompiled(cs, parsed); msg != "" {
-						if err := writeJSON(wsResponse{
-							ID: uplink.ID,
-							Ok: false,
-							Error: &wsError{
-								Code:    "VALIDATION_ERROR",
-								Message: fmt.Sprintf("Input validation failed for procedure '%s': %s", procName, msg),
-								Details: toAnySlice(details),
-							},
-						}); err != nil {
-							return
-						}
-						continue
-					}
-				}
-			}
-
 			// Dispatch command (explicit cancel to avoid defer leak in loop)
-			rpcCtx := ctx
-			// Inject per-procedure context (reuse connection-time extraction)
+			var rawCtx map[string]any
 			if len(s.contextConfigs) > 0 && len(proc.ContextKeys) > 0 {
-				rawCtx := extractRawContext(r, s.contextConfigs)
-				filtered := resolveContextForProc(rawCtx, proc.ContextKeys)
-				rpcCtx = injectContext(rpcCtx, filtered)
+				rawCtx = extractRawContext(r, s.contextConfigs)
 			}
-			rpcCtx = injectState(rpcCtx, s.appState)
+			rpcCtx := s.procedureContext(ctx, rawCtx, proc)
 			var rpcCancel context.CancelFunc
-			if s.opts.RPCTimeout > 0 {
-				rpcCtx, rpcCancel = context.WithTimeout(rpcCtx, s.opts.RPCTimeout)
+			if timeout := s.opts.Tuning.rpcTimeoutOr(s.opts.RPCTimeout); timeout > 0 {
+				rpcCtx, rpcCancel = context.WithTimeout(rpcCtx, timeout)
 			}
-
-			result, err := proc.Handler(rpcCtx, mergedInput)
+			result, seamErr := s.dispatch(rpcCtx, r, procName, proc, mergedInput)
 			if rpcCancel != nil {
 				rpcCancel()
 			}
-			if err != nil {
-				if rpcCtx.Err() == context.DeadlineExceeded {
-					if err := writeJSON(wsResponse{
-						ID: uplink.ID,
-						Ok: false,
-						Error: &wsError{
-							Code:      "INTERNAL_ERROR",
-							Message:   "RPC timed out",
-							Transient: true,
-						},
-					}); err != nil {
-						return
-					}
-					continue
-				}
-				if seamErr, ok := err.(*Error); ok {
-					if err := writeJSON(wsResponse{
-						ID: uplink.ID,
-						Ok: false,
-						Error: &wsError{
-							Code:    seamErr.Code,
-							Message: seamErr.Message,
-						},
-					}); err != nil {
-						return
-					}
-				} else {
-					if err := writeJSON(wsResponse{
-						ID: uplink.ID,
-						Ok: false,
-						Error: &wsError{
-							Code:    "INTERNAL_ERROR",
-							Message: err.Error(),
-						},
-					}); err != nil {
-						return
-					}
+			if seamErr != nil {
+				if err := writeFrame(wsResponse{ID: uplink.ID, Ok: false, Error: toWsError(seamErr)}); err != nil {
+					return
 				}
 				continue
 			}
 
-			if err := writeJSON(wsResponse{
+			if err := writeFrame(wsResponse{
 				ID:   uplink.ID,
 				Ok:   true,
 				Data: result,
@@ -0,0 +1,334 @@
+/* src/server/core/go/handler_ws_rpc.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// wsRPCPath is the endpoint of the persistent WebSocket RPC mode
+// (HandlerOptions.WebSocketRPC).
+const wsRPCPath = "/_seam/ws"
+
+// wsRPCUplink is a client frame on the RPC socket: a procedure call
+// ({id, procedure, input}), a subscription start ({id, subscribe, input}),
+// or a subscription stop ({id, unsubscribe: true}).
+type wsRPCUplink struct {
+	ID          string          `json:"id"`
+	Procedure   string          `json:"procedure,omitempty"`
+	Subscribe   string          `json:"subscribe,omitempty"`
+	Unsubscribe bool            `json:"unsubscribe,omitempty"`
+	Input       json.RawMessage `json:"input"`
+}
+
+// wsRPCEvent is a subscription frame. event is "subscribed" (ack once the
+// subscription is live), "data", "error", "complete", or "unsubscribed"
+// (ack of an unsubscribe frame).
+type wsRPCEvent struct {
+	ID    string   `json:"id"`
+	Event string   `json:"event"`
+	Data  any      `json:"data,omitempty"`
+	Error *wsError `json:"error,omitempty"`
+}
+
+// defaultMaxSocketSubscriptions bounds concurrent subscriptions per RPC socket.
+const defaultMaxSocketSubscriptions = 32
+
+func toWsError(e *Error) *wsError {
+	return &wsError{
+		Code:         e.Code,
+		Message:      e.Message,
+		Transient:    e.Transient || errorHTTPStatus(e) == http.StatusGatewayTimeout,
+		RetryAfterMs: e.retryAfterMs(),
+		Details:      e.Details,
+	}
+}
+
+// rpcOriginAllowed guards /_seam/ws against cross-site WebSocket
+// hijacking: the upgrade must be same-origin or come from an origin in
+// HandlerOptions.WebSocketRPCOrigins.
+func (s *appState) rpcOriginAllowed(r *http.Request) bool {
+	if sameOrigin(r) {
+		return true
+	}
+	origin := r.Header.Get("Origin")
+	for _, allowed := range s.opts.WebSocketRPCOrigins {
+		if strings.EqualFold(origin, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// handleWsRPC serves one persistent WebSocket carrying arbitrary procedure
+// calls and subscriptions, correlated by client-chosen request ids. Calls
+// run concurrently; responses may arrive out of order.
+func (s *appState) handleWsRPC(w http.ResponseWriter, r *http.Request) {
+	s.watchServer(r)
+	if s.shuttingDown() {
+		writeError(w, http.StatusServiceUnavailable, s.unavailableError())
+		return
+	}
+	release, ok := s.acquireWebSocket(w)
+	if !ok {
+		return
+	}
+	defer release()
+	defer s.activity.track("websocket", "rpc")()
+
+	upgrader := websocket.Upgrader{CheckOrigin: s.rpcOriginAllowed}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	s.limitSocket(conn)
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	ws := newWsWriter(conn)
+
+	_ = conn.SetReadDeadline(time.Now().Add(s.opts.HeartbeatInterval + s.opts.PongTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(s.opts.HeartbeatInterval + s.opts.PongTimeout))
+	})
+
+	// Active subscriptions by request id; each entry is compared by pointer
+	// so a finished subscription never stops a newer one reusing its id.
+	type activeSub struct {
+		cancel context.CancelFunc
+		ack    bool // stopped by an unsubscribe uplink; guarded by subsMu
+	}
+	var (
+		wg     sync.WaitGroup
+		subsMu sync.Mutex
+		subs   = make(map[string]*activeSub)
+	)
+	stopSub := func(id string, only *activeSub, ack bool) *activeSub {
+		subsMu.Lock()
+		defer subsMu.Unlock()
+		cur, ok := subs[id]
+		if !ok || (only != nil && cur != only) {
+			return nil
+		}
+		cur.ack = cur.ack || ack
+		cur.cancel()
+		delete(subs, id)
+		return cur
+	}
+	maxSubs := s.opts.MaxSocketSubscriptions
+	if maxSubs <= 0 {
+		maxSubs = defaultMaxSocketSubscriptions
+	}
+
+	// --- heartbeat + shutdown loop ---
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(s.opts.HeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := ws.frame(s.heartbeatFrame()); err != nil {
+					cancel()
+					return
+				}
+				if err := ws.ping(s.opts.PongTimeout); err != nil {
+					cancel()
+					return
+				}
+			case <-s.shutdownCh:
+				_ = ws.frame(wsPush{Event: "server-restarting", Payload: s.restartNotice()})
+				ws.close(websocket.CloseGoingAway, "server restarting")
+				_ = conn.Close()
+				cancel()
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// --- read loop ---
+	for ctx.Err() == nil {
+		msgType, message, err := conn.ReadMessage()
+		if err != nil {
+			break
+		}
+		message, frameErr := s.uplinkJSON(ws, msgType, message)
+		if frameErr != nil {
+			_ = ws.frame(wsResponse{Ok: false, Error: toWsError(frameErr)})
+			continue
+		}
+		if s.handleLatencyFrame(message, "socket", r, ws) {
+			continue
+		}
+		if limitErr := s.checkInput(message); limitErr != nil {
+			_ = ws.frame(wsResponse{Ok: false, Error: &wsError{Code: limitErr.Code, Message: limitErr.Message}})
+			continue
+		}
+		var up wsRPCUplink
+		if err := codecUnmarshal(message, &up); err != nil {
+			_ = ws.frame(wsResponse{Ok: false, Error: &wsError{Code: "VALIDATION_ERROR", Message: "Invalid uplink JSON"}})
+			continue
+		}
+		if len(up.Input) == 0 {
+			up.Input = json.RawMessage("{}")
+		}
+
+		switch {
+		case up.Unsubscribe:
+			// The subscription goroutine acks after its last data frame
+			if stopSub(up.ID, nil, true) == nil {
+				_ = ws.frame(wsRPCEvent{ID: up.ID, Event: "error", Error: toWsError(NotFoundError(fmt.Sprintf("No active subscription '%s'", up.ID)))})
+			}
+		case up.Subscribe != "":
+			stopSub(up.ID, nil, false)
+			subCtx, subCancel := context.WithCancel(ctx)
+			entry := &activeSub{cancel: subCancel}
+			subsMu.Lock()
+			full := len(subs) >= maxSubs
+			if !full {
+				subs[up.ID] = entry
+			}
+			subsMu.Unlock()
+			if full {
+				subCancel()
+				_ = ws.frame(wsRPCEvent{ID: up.ID, Event: "error", Error: toWsError(NewError("RATE_LIMITED",
+					fmt.Sprintf("Subscription limit of %d per connection reached", maxSubs), http.StatusTooManyRequests))})
+				continue
+			}
+			wg.Add(1)
+			go func(up wsRPCUplink) {
+				defer wg.Done()
+				s.runWsSubscription(subCtx, r, up, ws)
+				stopSub(up.ID, entry, false)
+				subsMu.Lock()
+				ack := entry.ack
+				subsMu.Unlock()
+				if ack {
+					_ = ws.frame(wsRPCEvent{ID: up.ID, Event: "unsubscribed"})
+				}
+			}(up)
+		case up.Procedure != "":
+			wg.Add(1)
+			go func(up wsRPCUplink) {
+				defer wg.Done()
+				_ = ws.frame(s.runWsCall(r, up))
+			}(up)
+		default:
+			_ = ws.frame(wsResponse{ID: up.ID, Ok: false, Error: &wsError{Code: "VALIDATION_ERROR", Message: "Uplink needs procedure, subscribe, or unsubscribe"}})
+		}
+	}
+
+	cancel()
+	wg.Wait()
+	_ = conn.Close()
+}
+
+// runWsCall resolves and runs a query or command for an RPC socket frame.
+func (s *appState) runWsCall(r *http.Request, up wsRPCUplink) wsResponse {
+	name := up.Procedure
+	if s.hashToName != nil {
+		resolved, ok := s.hashToName[name]
+		if !ok {
+			return wsResponse{ID: up.ID, Ok: false, Error: toWsError(NotFoundError(fmt.Sprintf("Procedure '%s' not found", name)))}
+		}
+		name = resolved
+	}
+	name = s.resolveVersion(name, r)
+	proc, ok := s.handlers[name]
+	if !ok {
+		return wsResponse{ID: up.ID, Ok: false, Error: toWsError(NotFoundError(fmt.Sprintf("Procedure '%s' not found", name)))}
+	}
+	if !json.Valid(up.Input) {
+		return wsResponse{ID: up.ID, Ok: false, Error: toWsError(ValidationError("Invalid JSON"))}
+	}
+	var canary *string
+	if proc.canary != nil {
+		var ctx context.Context
+		ctx, canary = withCanarySlot(r.Context())
+		r = r.WithContext(ctx)
+	}
+	result, seamErr := s.callProcedure(r, name, proc, up.Input)
+	resp := wsResponse{ID: up.ID, Ok: true, Data: result}
+	if seamErr != nil {
+		resp = wsResponse{ID: up.ID, Ok: false, Error: toWsError(seamErr)}
+	}
+	if canary != nil && *canary != "" {
+		resp.Meta = &resultMeta{Canary: *canary}
+	}
+	return resp
+}
+
+// runWsSubscription streams a subscription's events tagged with the
+// uplink id until it ends, the client unsubscribes, or the socket closes.
+func (s *appState) runWsSubscription(ctx context.Context, r *http.Request, up wsRPCUplink, ws *wsWriter) {
+	sub, ok := s.subs[up.Subscribe]
+	if !ok {
+		_ = ws.frame(wsRPCEvent{ID: up.ID, Event: "error", Error: toWsError(NotFoundError(fmt.Sprintf("Subscription '%s' not found", up.Subscribe)))})
+		return
+	}
+	if signer := s.opts.SignedURLs; signer != nil && signer.Required {
+		_ = ws.frame(wsRPCEvent{ID: up.ID, Event: "error", Error: toWsError(UnauthorizedError("Subscription URL must be signed"))})
+		return
+	}
+	if inputErr := s.validateSubscriptionInput(sub.Name, up.Input); inputErr != nil {
+		_ = ws.frame(wsRPCEvent{ID: up.ID, Event: "error", Error: toWsError(inputErr)})
+		return
+	}
+
+	subCtx := s.requestContext(r.WithContext(ctx))
+	if len(s.contextConfigs) > 0 && len(sub.ContextKeys) > 0 {
+		filtered := resolveContextForProc(extractRawContext(r, s.contextConfigs), sub.ContextKeys)
+		subCtx = injectContext(subCtx, filtered)
+	}
+	subCtx = injectState(subCtx, s.appState)
+	if filterErr := s.checkIPFilters(subCtx, RouteSubscription, sub.Name); filterErr != nil {
+		_ = ws.frame(wsRPCEvent{ID: up.ID, Event: "error", Error: toWsError(filterErr)})
+		return
+	}
+	if rateErr := s.opts.RateLimits.check(subCtx, "subscription"); rateErr != nil {
+		_ = ws.frame(wsRPCEvent{ID: up.ID, Event: "error", Error: toWsError(rateErr)})
+		return
+	}
+
+	ch, err := sub.Handler(subCtx, up.Input)
+	if err != nil {
+		seamErr, ok := err.(*Error)
+		if !ok {
+			seamErr = InternalError(err.Error())
+		}
+		_ = ws.frame(wsRPCEvent{ID: up.ID, Event: "error", Error: toWsError(seamErr)})
+		return
+	}
+	defer trackEvents(s.activity, "subscription", sub.Name, ch)()
+	if err := ws.frame(wsRPCEvent{ID: up.ID, Event: "subscribed"}); err != nil {
+		return
+	}
+	for {
+		select {
+		case ev, ok := <-ch:
+			if !ok {
+				_ = ws.frame(wsRPCEvent{ID: up.ID, Event: "complete"})
+				return
+			}
+			frame := wsRPCEvent{ID: up.ID, Event: ev.eventName(), Data: ev.Value}
+			if ev.Err != nil {
+				frame = wsRPCEvent{ID: up.ID, Event: "error", Error: toWsError(s.withRetryHint(ev.Err))}
+			}
+			if err := ws.frame(frame); err != nil {
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,170 @@
+/* src/server/core/go/handler_ws_rpc_test.go */
+
+package seam
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestWebSocketRPCCallsAndSubscriptions(t *testing.T) {
+	router := NewRouter().
+		Procedure(Query("add", func(_ context.Context, in struct {
+			A int `json:"a"`
+			B int `json:"b"`
+		}) (int, error) {
+			return in.A + in.B, nil
+		})).
+		Subscription(Subscribe("ticks", func(ctx context.Context, _ struct{}) (<-chan int, error) {
+			ch := make(chan int)
+			go func() {
+				defer close(ch)
+				for i := 1; ; i++ {
+					select {
+					case ch <- i:
+						time.Sleep(5 * time.Millisecond)
+					case <-ctx.Done():
+						return
+					}
+				}
+			}()
+			return ch, nil
+		}))
+	srv := httptest.NewServer(router.Handler(HandlerOptions{WebSocketRPC: true, HeartbeatInterval: time.Minute}))
+	defer srv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/_seam/ws", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	send := func(v any) {
+		if err := conn.WriteJSON(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read := func() map[string]any {
+		var m map[string]any
+		if err := conn.ReadJSON(&m); err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+
+	send(map[string]any{"id": "1", "procedure": "add", "input": map[string]any{"a": 2, "b": 3}})
+	if m := read(); m["id"] != "1" || m["ok"] != true || m["data"] != 5.0 {
+		t.Fatalf("unexpected call response %v", m)
+	}
+
+	send(map[string]any{"id": "2", "procedure": "missing"})
+	if m := read(); m["id"] != "2" || m["ok"] != false {
+		t.Fatalf("expected error for unknown procedure, got %v", m)
+	}
+
+	send(map[string]any{"id": "s1", "subscribe": "ticks"})
+	if m := read(); m["id"] != "s1" || m["event"] != "subscribed" {
+		t.Fatalf("expected subscribe ack, got %v", m)
+	}
+	for want := 1.0; want <= 2; want++ {
+		if m := read(); m["id"] != "s1" || m["event"] != "data" || m["data"] != want {
+			t.Fatalf("unexpected subscription frame %v", m)
+		}
+	}
+	send(map[string]any{"id": "s1", "unsubscribe": true})
+	for {
+		m := read()
+		if m["event"] == "data" {
+			continue // ticks sent before the unsubscribe was processed
+		}
+		if m["id"] != "s1" || m["event"] != "unsubscribed" {
+			t.Fatalf("expected unsubscribe ack, got %v", m)
+		}
+		break
+	}
+
+	// No ticks follow the ack
+	send(map[string]any{"id": "3", "procedure": "add", "input": map[string]any{"a": 1, "b": 1}})
+	if m := read(); m["id"] != "3" || m["data"] != 2.0 {
+		t.Fatalf("unexpected frame %v", m)
+	}
+
+	send(map[string]any{"id": "s1", "unsubscribe": true})
+	if m := read(); m["id"] != "s1" || m["event"] != "error" {
+		t.Fatalf("expected error for inactive subscription, got %v", m)
+	}
+}
+
+func TestWebSocketRPCSubscriptionLimit(t *testing.T) {
+	router := NewRouter().Subscription(Subscribe("idle", func(ctx context.Context, _ struct{}) (<-chan int, error) {
+		ch := make(chan int)
+		go func() {
+			<-ctx.Done()
+			close(ch)
+		}()
+		return ch, nil
+	}))
+	srv := httptest.NewServer(router.Handler(HandlerOptions{WebSocketRPC: true, MaxSocketSubscriptions: 1, HeartbeatInterval: time.Minute}))
+	defer srv.Close()
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/_seam/ws", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	var m map[string]any
+	_ = conn.WriteJSON(map[string]any{"id": "a", "subscribe": "idle"})
+	if err := conn.ReadJSON(&m); err != nil || m["event"] != "subscribed" {
+		t.Fatalf("first subscription: %v %v", m, err)
+	}
+	_ = conn.WriteJSON(map[string]any{"id": "b", "subscribe": "idle"})
+	m = nil
+	if err := conn.ReadJSON(&m); err != nil || m["id"] != "b" || m["event"] != "error" {
+		t.Fatalf("expected limit error, got %v %v", m, err)
+	}
+	if code := m["error"].(map[string]any)["code"]; code != "RATE_LIMITED" {
+		t.Fatalf("unexpected error code %v", code)
+	}
+}
+
+func TestWebSocketRPCDisabledByDefault(t *testing.T) {
+	w := httptest.NewRecorder()
+	NewRouter().Handler().ServeHTTP(w, httptest.NewRequest("GET", "/_seam/ws", nil))
+	if w.Code != 404 {
+		t.Fatalf("expected 404 without WebSocketRPC, got %d", w.Code)
+	}
+}
+
+func TestWebSocketRPCRejectsCrossOrigin(t *testing.T) {
+	router := NewRouter()
+	srv := httptest.NewServer(router.Handler(HandlerOptions{WebSocketRPC: true, WebSocketRPCOrigins: []string{"https://app.example.com"}}))
+	defer srv.Close()
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/_seam/ws"
+
+	for origin, want := range map[string]bool{
+		"":                        true,
+		srv.URL:                   true,
+		"https://app.example.com": true,
+		"https://evil.example":    false,
+	} {
+		header := http.Header{}
+		if origin != "" {
+			header.Set("Origin", origin)
+		}
+		conn, _, err := websocket.DefaultDialer.Dial(wsURL, header)
+		if (err == nil) != want {
+			t.Errorf("origin %q: dial err %v, want accepted=%v", origin, err, want)
+		}
+		if conn != nil {
+			conn.Close()
+		}
+	}
+}
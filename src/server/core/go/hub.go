@@ -0,0 +1,83 @@
+/* src/server/core/go/hub.go */
+
+package seam
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// hubBufferSize is the per-subscriber queue depth. Publishers never block:
+// messages to a subscriber whose queue is full are dropped.
+const hubBufferSize = 64
+
+// Hub is an in-process topic-based pub/sub used for server push
+// (invalidations, channel events). Safe for concurrent use. Replicate
+// extends it to other replicas and regions through a broker.
+type Hub struct {
+	mu     sync.RWMutex
+	topics map[string]map[chan any]struct{}
+	repl   atomic.Pointer[hubReplica]
+}
+
+func NewHub() *Hub {
+	return &Hub{topics: make(map[string]map[chan any]struct{})}
+}
+
+// Publish delivers msg to every current subscriber of topic and returns the
+// number of local subscribers that received it. With Replicate active the
+// message is also forwarded to the broker.
+func (h *Hub) Publish(topic string, msg any) int {
+	delivered := h.deliver(topic, msg)
+	if r := h.repl.Load(); r != nil {
+		r.forward(topic, msg)
+	}
+	return delivered
+}
+
+// deliver publishes to local subscribers only.
+func (h *Hub) deliver(topic string, msg any) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	delivered := 0
+	for ch := range h.topics[topic] {
+		select {
+		case ch <- msg:
+			delivered++
+		default:
+		}
+	}
+	return delivered
+}
+
+// Subscribe returns a channel receiving messages published to topic until
+// ctx is cancelled, after which the channel is closed.
+func (h *Hub) Subscribe(ctx context.Context, topic string) <-chan any {
+	ch := make(chan any, hubBufferSize)
+	h.mu.Lock()
+	if h.topics[topic] == nil {
+		h.topics[topic] = make(map[chan any]struct{})
+	}
+	h.topics[topic][ch] = struct{}{}
+	h.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		h.mu.Lock()
+		delete(h.topics[topic], ch)
+		if len(h.topics[topic]) == 0 {
+			delete(h.topics, topic)
+		}
+		h.mu.Unlock()
+		close(ch)
+	}()
+	return ch
+}
+
+// Subscribers returns the number of active subscribers on topic.
+func (h *Hub) Subscribers(topic string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.topics[topic])
+}
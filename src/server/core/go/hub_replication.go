@@ -0,0 +1,321 @@
+/* src/server/core/go/hub_replication.go */
+
+package seam
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// HubEnvelope is a Hub message on a broker, tagged with where it came
+// from so receivers and bridges can prevent loops.
+type HubEnvelope struct {
+	ID      string          `json:"id"`     // unique per message (deduplication)
+	Origin  string          `json:"origin"` // region that published it
+	Node    string          `json:"node"`   // publishing Hub instance
+	Topic   string          `json:"topic"`
+	Kind    string          `json:"kind"` // "invalidation", "channelEcho", or "json"
+	Payload json.RawMessage `json:"payload"`
+	Hops    []string        `json:"hops,omitempty"` // regions it was bridged into
+}
+
+// HubBroker carries envelopes between the Hubs sharing it (one region's
+// Redis pub/sub channel, NATS subject, ...). Subscribe receives every
+// envelope published after it returns, including the subscriber's own.
+// A go-redis client adapts in a few lines:
+//
+//	func (b adapter) Publish(ctx context.Context, env seam.HubEnvelope) error {
+//		data, _ := json.Marshal(env)
+//		return b.Client.Publish(ctx, "seam:hub", data).Err()
+//	}
+//	func (b adapter) Subscribe(ctx context.Context) (<-chan seam.HubEnvelope, error) {
+//		msgs := b.Client.Subscribe(ctx, "seam:hub").Channel()
+//		out := make(chan seam.HubEnvelope)
+//		go func() {
+//			defer close(out)
+//			for m := range msgs {
+//				var env seam.HubEnvelope
+//				if json.Unmarshal([]byte(m.Payload), &env) == nil {
+//					out <- env
+//				}
+//			}
+//		}()
+//		return out, nil
+//	}
+type HubBroker interface {
+	Publish(ctx context.Context, env HubEnvelope) error
+	Subscribe(ctx context.Context) (<-chan HubEnvelope, error)
+}
+
+// HubReplication configures Hub.Replicate.
+type HubReplication struct {
+	Region string    // this deployment's region (required)
+	Broker HubBroker // the region's broker (required)
+	// Topics selects the replicated topics (default: all).
+	Topics func(topic string) bool
+	// Decode rebuilds application messages (kind "json") from other
+	// replicas; without it they arrive as json.RawMessage. Invalidations
+	// and channel echoes are rebuilt by seam.
+	Decode func(env HubEnvelope) (any, error)
+}
+
+const (
+	hubKindInvalidation = "invalidation"
+	hubKindChannelEcho  = "channelEcho"
+	hubKindJSON         = "json"
+
+	hubReplicaQueue = 256  // envelopes waiting for the broker; more are dropped
+	hubRecentIDs    = 4096 // envelope ids remembered for deduplication
+)
+
+// hubReplica links a Hub to its broker.
+type hubReplica struct {
+	hub   *Hub
+	cfg   HubReplication
+	node  string
+	seq   atomic.Uint64
+	queue chan HubEnvelope
+	seen  *recentIDs
+}
+
+// Replicate shares the Hub's messages with every Hub on cfg.Broker until
+// ctx is cancelled: local publishes are forwarded to the broker, and
+// messages from other Hubs are delivered to local subscribers only, never
+// forwarded again. Use BridgeRegions to connect the brokers of two
+// regions.
+func (h *Hub) Replicate(ctx context.Context, cfg HubReplication) error {
+	if cfg.Region == "" || cfg.Broker == nil {
+		return errors.New("seam: HubReplication needs a Region and a Broker")
+	}
+	var node [8]byte
+	_, _ = rand.Read(node[:])
+	r := &hubReplica{
+		hub:   h,
+		cfg:   cfg,
+		node:  hex.EncodeToString(node[:]),
+		queue: make(chan HubEnvelope, hubReplicaQueue),
+		seen:  newRecentIDs(hubRecentIDs),
+	}
+	if !h.repl.CompareAndSwap(nil, r) {
+		return errors.New("seam: hub is already replicating")
+	}
+	msgs, err := cfg.Broker.Subscribe(ctx)
+	if err != nil {
+		h.repl.Store(nil)
+		return fmt.Errorf("seam: hub broker subscribe: %w", err)
+	}
+	go r.send(ctx)
+	go r.receive(ctx, msgs)
+	go func() {
+		<-ctx.Done()
+		h.repl.CompareAndSwap(r, nil)
+	}()
+	return nil
+}
+
+// forward queues a local publish for the broker without blocking.
+func (r *hubReplica) forward(topic string, msg any) {
+	if r.cfg.Topics != nil && !r.cfg.Topics(topic) {
+		return
+	}
+	kind, payload, err := encodeHubMessage(msg)
+	if err != nil {
+		logf(slog.LevelWarn, "hub replication: message on '%s' not replicated: %s\n", topic, err)
+		return
+	}
+	env := HubEnvelope{
+		ID:      fmt.Sprintf("%s-%d", r.node, r.seq.Add(1)),
+		Origin:  r.cfg.Region,
+		Node:    r.node,
+		Topic:   topic,
+		Kind:    kind,
+		Payload: payload,
+	}
+	select {
+	case r.queue <- env:
+	default:
+		logf(slog.LevelWarn, "hub replication: broker queue full, message on '%s' dropped\n", topic)
+	}
+}
+
+func (r *hubReplica) send(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case env := <-r.queue:
+			if err := r.cfg.Broker.Publish(ctx, env); err != nil && ctx.Err() == nil {
+				logf(slog.LevelWarn, "hub replication: publish to broker: %s\n", err)
+			}
+		}
+	}
+}
+
+func (r *hubReplica) receive(ctx context.Context, msgs <-chan HubEnvelope) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case env, ok := <-msgs:
+			if !ok {
+				return
+			}
+			if env.Node == r.node || !r.seen.add(env.ID) {
+				continue
+			}
+			if r.cfg.Topics != nil && !r.cfg.Topics(env.Topic) {
+				continue
+			}
+			msg, err := r.decode(env)
+			if err != nil {
+				logf(slog.LevelWarn, "hub replication: message %s from %s not decoded: %s\n", env.ID, env.Origin, err)
+				continue
+			}
+			r.hub.deliver(env.Topic, msg)
+		}
+	}
+}
+
+func encodeHubMessage(msg any) (string, json.RawMessage, error) {
+	switch m := msg.(type) {
+	case InvalidationEvent:
+		b, err := json.Marshal(m)
+		return hubKindInvalidation, b, err
+	case channelEcho:
+		// the sending connection is local; remote subscribers all receive it
+		b, err := json.Marshal(map[string]any{"event": m.event, "payload": m.payload})
+		return hubKindChannelEcho, b, err
+	default:
+		b, err := json.Marshal(msg)
+		return hubKindJSON, b, err
+	}
+}
+
+func (r *hubReplica) decode(env HubEnvelope) (any, error) {
+	switch env.Kind {
+	case hubKindInvalidation:
+		var ev InvalidationEvent
+		err := json.Unmarshal(env.Payload, &ev)
+		return ev, err
+	case hubKindChannelEcho:
+		var echo struct {
+			Event   string          `json:"event"`
+			Payload json.RawMessage `json:"payload"`
+		}
+		err := json.Unmarshal(env.Payload, &echo)
+		return channelEcho{event: echo.Event, payload: echo.Payload}, err
+	default:
+		if r.cfg.Decode != nil {
+			return r.cfg.Decode(env)
+		}
+		return env.Payload, nil
+	}
+}
+
+// HubRegion names one region's broker for BridgeRegions.
+type HubRegion struct {
+	Name   string
+	Broker HubBroker
+}
+
+// BridgeRegions forwards envelopes between the brokers of two regions
+// until ctx is cancelled. An envelope is never forwarded into the region
+// it originated in or was already bridged into (Hops), so messages do not
+// loop; duplicates from redundant bridges are dropped by the receiving
+// Hubs.
+func BridgeRegions(ctx context.Context, a, b HubRegion) error {
+	fromA, err := a.Broker.Subscribe(ctx)
+	if err != nil {
+		return fmt.Errorf("seam: bridge subscribe to %s: %w", a.Name, err)
+	}
+	fromB, err := b.Broker.Subscribe(ctx)
+	if err != nil {
+		return fmt.Errorf("seam: bridge subscribe to %s: %w", b.Name, err)
+	}
+	go bridgeEnvelopes(ctx, fromA, b)
+	go bridgeEnvelopes(ctx, fromB, a)
+	return nil
+}
+
+func bridgeEnvelopes(ctx context.Context, msgs <-chan HubEnvelope, to HubRegion) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case env, ok := <-msgs:
+			if !ok {
+				return
+			}
+			if env.Origin == to.Name || slices.Contains(env.Hops, to.Name) {
+				continue
+			}
+			env.Hops = append(slices.Clone(env.Hops), to.Name)
+			if err := to.Broker.Publish(ctx, env); err != nil && ctx.Err() == nil {
+				logf(slog.LevelWarn, "hub bridge: publish to %s: %s\n", to.Name, err)
+			}
+		}
+	}
+}
+
+// MemoryBroker returns an in-process HubBroker, for tests and for
+// connecting several handlers in one process.
+func MemoryBroker() HubBroker {
+	return &memoryBroker{hub: NewHub()}
+}
+
+type memoryBroker struct{ hub *Hub }
+
+func (b *memoryBroker) Publish(_ context.Context, env HubEnvelope) error {
+	b.hub.deliver("", env)
+	return nil
+}
+
+func (b *memoryBroker) Subscribe(ctx context.Context) (<-chan HubEnvelope, error) {
+	msgs := b.hub.Subscribe(ctx, "")
+	out := make(chan HubEnvelope, hubBufferSize)
+	go func() {
+		defer close(out)
+		for msg := range msgs {
+			select {
+			case out <- msg.(HubEnvelope):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// recentIDs remembers the last n ids.
+type recentIDs struct {
+	mu   sync.Mutex
+	ring []string
+	next int
+	set  map[string]struct{}
+}
+
+func newRecentIDs(n int) *recentIDs {
+	return &recentIDs{ring: make([]string, n), set: make(map[string]struct{}, n)}
+}
+
+// add records id, reporting false when it was already seen.
+func (r *recentIDs) add(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.set[id]; ok {
+		return false
+	}
+	delete(r.set, r.ring[r.next])
+	r.ring[r.next] = id
+	r.next = (r.next + 1) % len(r.ring)
+	r.set[id] = struct{}{}
+	return true
+}
@@ -0,0 +1,122 @@
+/* src/server/core/go/hub_replication_test.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// replicatedHub returns a Hub replicating to broker in region.
+func replicatedHub(t *testing.T, ctx context.Context, region string, broker HubBroker) *Hub {
+	t.Helper()
+	h := NewHub()
+	if err := h.Replicate(ctx, HubReplication{Region: region, Broker: broker}); err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func receive(t *testing.T, ch <-chan any) any {
+	t.Helper()
+	select {
+	case msg := <-ch:
+		return msg
+	case <-time.After(2 * time.Second):
+		t.Fatal("message not replicated")
+		return nil
+	}
+}
+
+func expectNone(t *testing.T, ch <-chan any) {
+	t.Helper()
+	select {
+	case msg := <-ch:
+		t.Fatalf("unexpected message %#v", msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestHubReplicationAcrossRegions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	eu, us := MemoryBroker(), MemoryBroker()
+	eu1 := replicatedHub(t, ctx, "eu", eu)
+	eu2 := replicatedHub(t, ctx, "eu", eu)
+	us1 := replicatedHub(t, ctx, "us", us)
+	// redundant bridges must not duplicate or loop messages
+	for range 2 {
+		if err := BridgeRegions(ctx, HubRegion{"eu", eu}, HubRegion{"us", us}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	subs := map[string]<-chan any{
+		"eu1": eu1.Subscribe(ctx, invalidationTopic),
+		"eu2": eu2.Subscribe(ctx, invalidationTopic),
+		"us1": us1.Subscribe(ctx, invalidationTopic),
+	}
+	ev := InvalidationEvent{Keys: []InvalidationKey{{Procedure: "getUser"}}}
+	if n := eu1.Publish(invalidationTopic, ev); n != 1 {
+		t.Fatalf("local delivery %d", n)
+	}
+	for name, ch := range subs {
+		got, ok := receive(t, ch).(InvalidationEvent)
+		if !ok || got.Keys[0].Procedure != "getUser" {
+			t.Errorf("%s received %#v", name, got)
+		}
+	}
+	for _, ch := range subs {
+		expectNone(t, ch)
+	}
+
+	echoes := eu1.Subscribe(ctx, "seam.channel.chat:{}")
+	us1.Publish("seam.channel.chat:{}", channelEcho{event: "message", payload: map[string]any{"text": "hi"}, origin: new(byte)})
+	echo, ok := receive(t, echoes).(channelEcho)
+	if !ok || echo.event != "message" || string(mustJSON(echo.payload)) != `{"text":"hi"}` || echo.origin != nil {
+		t.Errorf("echo %#v", echo)
+	}
+
+	app := us1.Subscribe(ctx, "app")
+	eu2.Publish("app", map[string]int{"n": 1})
+	if raw, ok := receive(t, app).(json.RawMessage); !ok || string(raw) != `{"n":1}` {
+		t.Errorf("app message %#v", raw)
+	}
+}
+
+func TestHubReplicationTopicsAndDecode(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	broker := MemoryBroker()
+	type note struct{ Text, From string }
+	cfg := HubReplication{
+		Region: "eu",
+		Broker: broker,
+		Topics: func(topic string) bool { return topic != "local" },
+		Decode: func(env HubEnvelope) (any, error) {
+			var n note
+			err := json.Unmarshal(env.Payload, &n)
+			n.From = env.Origin
+			return n, err
+		},
+	}
+	a, b := NewHub(), NewHub()
+	for _, h := range []*Hub{a, b} {
+		if err := h.Replicate(ctx, cfg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := a.Replicate(ctx, cfg); err == nil {
+		t.Error("second Replicate accepted")
+	}
+
+	notes, local := b.Subscribe(ctx, "notes"), b.Subscribe(ctx, "local")
+	a.Publish("local", "stays here")
+	a.Publish("notes", note{Text: "hi"})
+	if got := receive(t, notes); got != (note{Text: "hi", From: "eu"}) {
+		t.Errorf("decoded %#v", got)
+	}
+	expectNone(t, local)
+}
@@ -0,0 +1,51 @@
+/* src/server/core/go/hub_test.go */
+
+package seam
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestHubPublishSubscribe(t *testing.T) {
+	hub := NewHub()
+	ctx, cancel := context.WithCancel(context.Background())
+	msgs := hub.Subscribe(ctx, "t")
+
+	if n := hub.Publish("other", 1); n != 0 {
+		t.Fatalf("expected no delivery to other topic, got %d", n)
+	}
+	if n := hub.Publish("t", "hello"); n != 1 {
+		t.Fatalf("expected one delivery, got %d", n)
+	}
+	if got := <-msgs; got != "hello" {
+		t.Fatalf("got %v", got)
+	}
+
+	cancel()
+	select {
+	case _, ok := <-msgs:
+		if ok {
+			t.Fatal("expected channel closed after cancel")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("channel not closed after cancel")
+	}
+	if hub.Subscribers("t") != 0 {
+		t.Fatal("expected subscriber removed")
+	}
+}
+
+func TestHubDropsWhenSubscriberFull(t *testing.T) {
+	hub := NewHub()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_ = hub.Subscribe(ctx, "t")
+	for i := 0; i < hubBufferSize; i++ {
+		hub.Publish("t", i)
+	}
+	if n := hub.Publish("t", "overflow"); n != 0 {
+		t.Fatalf("expected publish to a full subscriber to be dropped, got %d", n)
+	}
+}
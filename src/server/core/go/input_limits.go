@@ -0,0 +1,171 @@
+/* src/server/core/go/input_limits.go */
+
+package seam
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/websocket"
+)
+
+// InputLimits bounds JSON request inputs before they are parsed, so a
+// small payload cannot expand into deep recursion or huge allocations
+// (JSON bombs). Zero depth and array fields use the defaults; negative
+// fields disable the check. Limits apply to RPC and batch bodies, stream
+// and subscription inputs, and WebSocket frames.
+type InputLimits struct {
+	// MaxBytes caps request bodies (0 = no cap) and WebSocket frames
+	// (0 = 1 MiB). Bodies are read through http.MaxBytesReader and sockets
+	// get a read limit, so an oversized input is refused without being
+	// buffered.
+	MaxBytes       int
+	MaxDepth       int // nesting of objects and arrays (default 64)
+	MaxArrayLength int // elements of any single array (default 10000)
+}
+
+func (l InputLimits) withDefaults() InputLimits {
+	if l.MaxDepth == 0 {
+		l.MaxDepth = 64
+	}
+	if l.MaxArrayLength == 0 {
+		l.MaxArrayLength = 10000
+	}
+	return l
+}
+
+// checkInput returns a VALIDATION_ERROR (413 for oversized bodies) when
+// body exceeds the configured input limits.
+func (s *appState) checkInput(body []byte) *Error {
+	l := s.opts.InputLimits.withDefaults()
+	if l.MaxBytes > 0 && len(body) > l.MaxBytes {
+		return inputTooLarge(l.MaxBytes)
+	}
+	depth, longest := jsonShape(body, l.MaxDepth, l.MaxArrayLength)
+	if l.MaxDepth > 0 && depth > l.MaxDepth {
+		return ValidationError(fmt.Sprintf("Input nesting exceeds depth %d", l.MaxDepth))
+	}
+	if l.MaxArrayLength > 0 && longest > l.MaxArrayLength {
+		return ValidationError(fmt.Sprintf("Input array exceeds %d elements", l.MaxArrayLength))
+	}
+	return nil
+}
+
+func inputTooLarge(maxBytes int) *Error {
+	return NewError("VALIDATION_ERROR", fmt.Sprintf("Input exceeds %d bytes", maxBytes), http.StatusRequestEntityTooLarge)
+}
+
+// readBody reads the request body, stopping at InputLimits.MaxBytes (or
+// the MemoryBudget), and checks it against the input limits.
+func (s *appState) readBody(w http.ResponseWriter, r *http.Request) ([]byte, *Error) {
+	maxBytes := s.opts.InputLimits.MaxBytes
+	if maxBytes > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, int64(maxBytes))
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, inputTooLarge(int(tooLarge.Limit))
+		}
+		return nil, ValidationError("Failed to read request body")
+	}
+	if limitErr := s.checkInput(body); limitErr != nil {
+		return nil, limitErr
+	}
+	return body, nil
+}
+
+// defaultSocketReadLimit bounds WebSocket frames when InputLimits.MaxBytes
+// is unset.
+const defaultSocketReadLimit = 1 << 20
+
+// limitSocket applies InputLimits.MaxBytes (or defaultSocketReadLimit) as
+// the read limit of a WebSocket; an oversized frame closes the connection.
+func (s *appState) limitSocket(conn *websocket.Conn) {
+	maxBytes := s.opts.InputLimits.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultSocketReadLimit
+	}
+	conn.SetReadLimit(int64(maxBytes))
+}
+
+// frameDepth is the nesting cap of decoded MessagePack frames: MaxDepth,
+// or the default when the JSON depth check is off, since the decoder
+// recurses per level.
+func (s *appState) frameDepth() int {
+	if depth := s.opts.InputLimits.withDefaults().MaxDepth; depth > 0 {
+		return depth
+	}
+	return InputLimits{}.withDefaults().MaxDepth
+}
+
+// jsonShape scans JSON text for its maximum nesting depth and longest
+// array without decoding it. The scan stops once either limit is
+// exceeded (limits <= 0 are not checked).
+func jsonShape(data []byte, maxDepth, maxArray int) (depth, longest int) {
+	// counts[i] is the element count of the i-th open container, -1 for objects
+	var counts []int
+	inString, escaped, pendingValue := false, false, false
+	for _, c := range data {
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case ' ', '\t', '\n', '\r':
+			continue
+		case '"':
+			inString = true
+		case '[', '{':
+			if pendingValue && countElement(counts, maxArray) {
+				return depth, counts[len(counts)-1]
+			}
+			if c == '[' {
+				counts = append(counts, 0)
+			} else {
+				counts = append(counts, -1)
+			}
+			pendingValue = c == '['
+			depth = max(depth, len(counts))
+			if maxDepth > 0 && depth > maxDepth {
+				return depth, longest
+			}
+			continue
+		case ']', '}':
+			if len(counts) > 0 {
+				longest = max(longest, counts[len(counts)-1])
+				counts = counts[:len(counts)-1]
+			}
+			pendingValue = false
+			continue
+		case ',':
+			pendingValue = len(counts) > 0 && counts[len(counts)-1] >= 0
+			continue
+		}
+		// First byte of a scalar (or string) value
+		if pendingValue {
+			pendingValue = false
+			if countElement(counts, maxArray) {
+				return depth, counts[len(counts)-1]
+			}
+		}
+	}
+	return depth, longest
+}
+
+// countElement counts a value of the innermost array and reports whether
+// it went over maxArray.
+func countElement(counts []int, maxArray int) bool {
+	counts[len(counts)-1]++
+	return maxArray > 0 && counts[len(counts)-1] > maxArray
+}
@@ -0,0 +1,128 @@
+/* src/server/core/go/input_limits_test.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestJSONShape(t *testing.T) {
+	cases := []struct {
+		in             string
+		depth, longest int
+	}{
+		{`{}`, 1, 0},
+		{`[]`, 1, 0},
+		{`[1, "a,]", {"k": [true, null]}, [[]]]`, 3, 4},
+		{`{"a": {"b": {"c": "[[[[" }}}`, 3, 0},
+		{`"\"[" `, 0, 0},
+		{`[[1,2,3],[4]]`, 2, 3},
+	}
+	for _, tc := range cases {
+		depth, longest := jsonShape([]byte(tc.in), 0, 0)
+		if depth != tc.depth || longest != tc.longest {
+			t.Errorf("jsonShape(%s) = %d, %d; want %d, %d", tc.in, depth, longest, tc.depth, tc.longest)
+		}
+	}
+	// Scanning stops at the first exceeded limit
+	if depth, _ := jsonShape([]byte(strings.Repeat("[", 1000)), 5, 0); depth != 6 {
+		t.Fatalf("expected early stop at depth 6, got %d", depth)
+	}
+}
+
+func TestInputLimits(t *testing.T) {
+	router := NewRouter().
+		RpcHashMap(&RpcHashMap{Batch: "_batch", Procedures: map[string]string{"echo": "echo"}}).
+		Procedure(&ProcedureDef{Name: "echo", InputSchema: map[string]any{}, OutputSchema: map[string]any{},
+			Handler: func(_ context.Context, in json.RawMessage) (any, error) { return in, nil }})
+	h := router.Handler(HandlerOptions{InputLimits: InputLimits{MaxBytes: 256, MaxDepth: 4, MaxArrayLength: 3}})
+
+	cases := []struct {
+		path, body string
+		status     int
+		message    string
+	}{
+		{"/_seam/procedure/echo", `{"a":[[1,2,3]]}`, http.StatusOK, ""},
+		{"/_seam/procedure/echo", `{"a":[[[[1]]]]}`, http.StatusBadRequest, "depth 4"},
+		{"/_seam/procedure/echo", `{"a":[1,2,3,4]}`, http.StatusBadRequest, "3 elements"},
+		{"/_seam/procedure/echo", `{"a":"` + strings.Repeat("x", 300) + `"}`, http.StatusRequestEntityTooLarge, "256 bytes"},
+		{"/_seam/procedure/_batch", `{"calls":[{"procedure":"echo","input":{}},{"procedure":"echo","input":{}},{"procedure":"echo","input":{}},{"procedure":"echo","input":{}}]}`, http.StatusBadRequest, "3 elements"},
+	}
+	for _, tc := range cases {
+		status, body := rpcBody(h, tc.path, tc.body, nil)
+		if status != tc.status || !strings.Contains(body, tc.message) {
+			t.Errorf("%s %s: got %d %s", tc.path, tc.body, status, body)
+		}
+	}
+
+	// Defaults apply to zero fields; negative disables
+	deep := strings.Repeat("[", 100) + strings.Repeat("]", 100)
+	if status, _ := rpcBody(router.Handler(), "/_seam/procedure/echo", deep, nil); status != http.StatusBadRequest {
+		t.Fatalf("expected default depth limit, got %d", status)
+	}
+	if status, body := rpcBody(router.Handler(HandlerOptions{InputLimits: InputLimits{MaxDepth: -1}}), "/_seam/procedure/echo", deep, nil); status != http.StatusOK {
+		t.Fatalf("expected disabled depth limit, got %d %s", status, body)
+	}
+}
+
+func TestInputLimitsMaxBytesOptIn(t *testing.T) {
+	router := NewRouter().Procedure(&ProcedureDef{Name: "echo", InputSchema: map[string]any{}, OutputSchema: map[string]any{},
+		Handler: func(_ context.Context, in json.RawMessage) (any, error) { return len(in), nil }})
+	big := `{"a":"` + strings.Repeat("x", 2<<20) + `"}`
+
+	// no byte cap unless configured
+	if status, body := rpcBody(router.Handler(), "/_seam/procedure/echo", big, nil); status != http.StatusOK {
+		t.Fatalf("default handler: got %d %.80s", status, body)
+	}
+
+	// a body without a declared length stops at the cap
+	h := router.Handler(HandlerOptions{InputLimits: InputLimits{MaxBytes: 1024}})
+	req := httptest.NewRequest(http.MethodPost, "/_seam/procedure/echo", io.MultiReader(strings.NewReader(big)))
+	req.ContentLength = -1
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("chunked oversized body: got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestInputLimitsSocketReadLimit(t *testing.T) {
+	srv := httptest.NewServer(NewRouter().Handler(HandlerOptions{WebSocketRPC: true, InputLimits: InputLimits{MaxBytes: 64}}))
+	defer srv.Close()
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/_seam/ws", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"id":"1","procedure":"x","input":"`+strings.Repeat("x", 256)+`"}`))
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, _, err = conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseMessageTooBig) {
+		t.Fatalf("oversized frame: got %v, want close 1009", err)
+	}
+}
+
+func TestInputLimitsDefaultSocketReadLimit(t *testing.T) {
+	srv := httptest.NewServer(NewRouter().Handler(HandlerOptions{WebSocketRPC: true}))
+	defer srv.Close()
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/_seam/ws", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"id":"1","procedure":"x","input":"`+strings.Repeat("x", defaultSocketReadLimit)+`"}`))
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, _, err = conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseMessageTooBig) {
+		t.Fatalf("oversized frame without MaxBytes: got %v, want close 1009", err)
+	}
+}
@@ -0,0 +1,188 @@
+/* src/server/core/go/introspect.go */
+
+package seam
+
+import "sort"
+
+// ProcedureInfo is a read-only descriptor of a registered procedure.
+// Schema values are shared with the router and must not be mutated.
+type ProcedureInfo struct {
+	Name                 string
+	Kind                 string // "query" | "command" | "stream" | "upload"
+	InputSchema          any
+	OutputSchema         any // chunk schema for streams
+	ErrorSchema          any
+	ContextKeys          []string
+	Suppress             []string
+	Cache                any
+	Deprecated           *Deprecation
+	Invalidates          []InvalidateTarget
+	Examples             []Example // filled by ExampleCapture.Annotate
+	Annotations          *Annotations
+	AdditionalProperties AdditionalProperties
+}
+
+// SubscriptionInfo is a read-only descriptor of a registered subscription.
+type SubscriptionInfo struct {
+	Name         string
+	InputSchema  any
+	OutputSchema any
+	ErrorSchema  any
+	ContextKeys  []string
+	Suppress     []string
+}
+
+// LoaderInfo describes a page loader binding.
+type LoaderInfo struct {
+	DataKey   string
+	Procedure string
+}
+
+// PageInfo is a read-only descriptor of a registered page.
+type PageInfo struct {
+	Route          string
+	Loaders        []LoaderInfo
+	LayoutChain    []LayoutChainEntry
+	PageLoaderKeys []string
+	Locales        []string // locales with a pre-resolved template
+	Prerender      bool
+	Annotations    *Annotations
+}
+
+// expandAll returns procedure and subscription copies with channels
+// expanded into Level 0 primitives, leaving Router state untouched.
+func (r *Router) expandAll() ([]ProcedureDef, []SubscriptionDef, map[string]channelMeta) {
+	var channelMetas map[string]channelMeta
+	procs := append([]ProcedureDef{}, r.procedures...)
+	subs := append([]SubscriptionDef{}, r.subscriptions...)
+	for _, ch := range r.channels {
+		p, s, meta := ch.expand(nil)
+		procs = append(procs, p...)
+		subs = append(subs, s...)
+		if channelMetas == nil {
+			channelMetas = make(map[string]channelMeta)
+		}
+		channelMetas[ch.Name] = meta
+	}
+	return procs, subs, channelMetas
+}
+
+// Procedures returns descriptors for all queries, commands, streams, and
+// uploads (including channel commands), sorted by name.
+func (r *Router) Procedures() []ProcedureInfo {
+	procs, _, _ := r.expandAll()
+	infos := make([]ProcedureInfo, 0, len(procs)+len(r.streams)+len(r.uploads))
+	for _, p := range procs {
+		kind := p.Type
+		if kind == "" {
+			kind = "query"
+		}
+		infos = append(infos, ProcedureInfo{
+			Name:         p.Name,
+			Kind:         kind,
+			InputSchema:  p.InputSchema,
+			OutputSchema: p.OutputSchema,
+			ErrorSchema:  p.ErrorSchema,
+			ContextKeys:  cloneStrings(p.ContextKeys),
+			Suppress:     cloneStrings(p.Suppress),
+			Cache:        p.Cache,
+			Deprecated:   p.Deprecated,
+			Invalidates:  p.InvalidateTargets,
+			Annotations:  p.Annotations,
+
+			AdditionalProperties: p.AdditionalProperties,
+		})
+	}
+	for _, st := range r.streams {
+		infos = append(infos, ProcedureInfo{
+			Name:         st.Name,
+			Kind:         "stream",
+			InputSchema:  st.InputSchema,
+			OutputSchema: st.ChunkOutputSchema,
+			ErrorSchema:  st.ErrorSchema,
+			ContextKeys:  cloneStrings(st.ContextKeys),
+			Suppress:     cloneStrings(st.Suppress),
+		})
+	}
+	for _, u := range r.uploads {
+		infos = append(infos, ProcedureInfo{
+			Name:         u.Name,
+			Kind:         "upload",
+			InputSchema:  u.InputSchema,
+			OutputSchema: u.OutputSchema,
+			ErrorSchema:  u.ErrorSchema,
+			ContextKeys:  cloneStrings(u.ContextKeys),
+			Suppress:     cloneStrings(u.Suppress),
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// Subscriptions returns descriptors for all subscriptions (including
+// channel event streams), sorted by name.
+func (r *Router) Subscriptions() []SubscriptionInfo {
+	_, subs, _ := r.expandAll()
+	infos := make([]SubscriptionInfo, 0, len(subs))
+	for _, s := range subs {
+		infos = append(infos, SubscriptionInfo{
+			Name:         s.Name,
+			InputSchema:  s.InputSchema,
+			OutputSchema: s.OutputSchema,
+			ErrorSchema:  s.ErrorSchema,
+			ContextKeys:  cloneStrings(s.ContextKeys),
+			Suppress:     cloneStrings(s.Suppress),
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// Pages returns descriptors for all registered pages, sorted by route.
+func (r *Router) Pages() []PageInfo {
+	pages := resolveBoundLoaders(applyRouteGroups(r.pages, r.groups))
+	infos := make([]PageInfo, 0, len(pages))
+	for i := range pages {
+		p := &pages[i]
+		loaders := make([]LoaderInfo, 0, len(p.Loaders))
+		for _, ld := range p.Loaders {
+			loaders = append(loaders, LoaderInfo{DataKey: ld.DataKey, Procedure: ld.Procedure})
+		}
+		sort.Slice(loaders, func(a, b int) bool { return loaders[a].DataKey < loaders[b].DataKey })
+
+		chain := make([]LayoutChainEntry, len(p.LayoutChain))
+		for j, entry := range p.LayoutChain {
+			chain[j] = LayoutChainEntry{ID: entry.ID, LoaderKeys: cloneStrings(entry.LoaderKeys)}
+		}
+
+		var locales []string
+		for loc := range p.LocaleTemplates {
+			locales = append(locales, loc)
+		}
+		if p.lazy != nil {
+			for loc := range p.lazy.localePaths {
+				locales = append(locales, loc)
+			}
+		}
+		sort.Strings(locales)
+
+		infos = append(infos, PageInfo{
+			Route:          p.Route,
+			Loaders:        loaders,
+			LayoutChain:    chain,
+			PageLoaderKeys: cloneStrings(p.PageLoaderKeys),
+			Locales:        locales,
+			Prerender:      p.Prerender,
+			Annotations:    p.Annotations,
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Route < infos[j].Route })
+	return infos
+}
+
+func cloneStrings(s []string) []string {
+	if s == nil {
+		return nil
+	}
+	return append([]string(nil), s...)
+}
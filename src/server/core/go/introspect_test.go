@@ -0,0 +1,85 @@
+/* src/server/core/go/introspect_test.go */
+
+package seam
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRouterProceduresIntrospection(t *testing.T) {
+	type in struct {
+		ID string `json:"id"`
+	}
+	r := NewRouter().
+		Procedure(Query("getUser", func(ctx context.Context, _ in) (in, error) { return in{}, nil }, WithProcedureContext("auth"))).
+		Procedure(Command("updateUser", func(ctx context.Context, _ in) (in, error) { return in{}, nil })).
+		Stream(&StreamDef{Name: "tail", Handler: nil}).
+		Upload(&UploadDef{Name: "avatar"}).
+		Channel(ChannelDef{
+			Name:     "chat",
+			Incoming: map[string]IncomingDef{"send": {InputSchema: map[string]any{}, Handler: echoHandler()}},
+			Outgoing: map[string]any{"msg": map[string]any{}},
+		})
+
+	infos := r.Procedures()
+	want := []struct{ name, kind string }{
+		{"avatar", "upload"},
+		{"chat.send", "command"},
+		{"getUser", "query"},
+		{"tail", "stream"},
+		{"updateUser", "command"},
+	}
+	if len(infos) != len(want) {
+		t.Fatalf("expected %d procedures, got %d", len(want), len(infos))
+	}
+	for i, w := range want {
+		if infos[i].Name != w.name || infos[i].Kind != w.kind {
+			t.Errorf("procedure %d: got %s/%s, want %s/%s", i, infos[i].Name, infos[i].Kind, w.name, w.kind)
+		}
+	}
+	if infos[2].InputSchema == nil {
+		t.Error("expected getUser input schema")
+	}
+
+	// Mutating the descriptor must not leak into router state
+	infos[2].ContextKeys[0] = "mutated"
+	if r.Procedures()[2].ContextKeys[0] != "auth" {
+		t.Fatal("expected context keys to be copied")
+	}
+
+	subs := r.Subscriptions()
+	if len(subs) != 1 || subs[0].Name != "chat.events" {
+		t.Fatalf("expected chat.events subscription, got %+v", subs)
+	}
+}
+
+func TestRouterPagesIntrospection(t *testing.T) {
+	r := NewRouter().
+		Page(&PageDef{
+			Route: "/user/:id",
+			Loaders: []LoaderDef{
+				{DataKey: "user", Procedure: "getUser"},
+				{DataKey: "session", Procedure: "getSession"},
+			},
+			LayoutChain:     []LayoutChainEntry{{ID: "root", LoaderKeys: []string{"session"}}},
+			PageLoaderKeys:  []string{"user"},
+			LocaleTemplates: map[string]string{"zh": "", "en": ""},
+		}).
+		Page(&PageDef{Route: "/"})
+
+	pages := r.Pages()
+	if len(pages) != 2 || pages[0].Route != "/" || pages[1].Route != "/user/:id" {
+		t.Fatalf("unexpected pages: %+v", pages)
+	}
+	user := pages[1]
+	if len(user.Loaders) != 2 || user.Loaders[0].DataKey != "session" || user.Loaders[1].Procedure != "getUser" {
+		t.Fatalf("unexpected loaders: %+v", user.Loaders)
+	}
+	if len(user.Locales) != 2 || user.Locales[0] != "en" {
+		t.Fatalf("expected sorted locales, got %v", user.Locales)
+	}
+	if len(user.LayoutChain) != 1 || user.LayoutChain[0].ID != "root" {
+		t.Fatalf("unexpected layout chain: %+v", user.LayoutChain)
+	}
+}
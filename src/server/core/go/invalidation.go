@@ -0,0 +1,181 @@
+/* src/server/core/go/invalidation.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// InvalidationSubscription is the built-in subscription clients listen on to
+// learn which query keys changed and refetch them.
+const InvalidationSubscription = "__seam_invalidations"
+
+const invalidationTopic = "seam.invalidations"
+
+// InvalidationKey identifies cached query results to refetch. A nil Input
+// invalidates every cached input of the procedure.
+type InvalidationKey struct {
+	Procedure string `json:"procedure"`
+	Input     any    `json:"input,omitempty"`
+}
+
+// InvalidationEvent is the payload pushed on the invalidation subscription.
+type InvalidationEvent struct {
+	Keys []InvalidationKey `json:"keys"`
+}
+
+// Hub returns the router's in-process pub/sub hub, shared with every
+// handler built from this router unless HandlerOptions.Hub overrides it.
+func (r *Router) Hub() *Hub {
+	if r.hub == nil {
+		r.hub = NewHub()
+	}
+	return r.hub
+}
+
+// Invalidate notifies connected clients that all cached results of the given
+// query procedures are stale.
+func (r *Router) Invalidate(procedures ...string) {
+	keys := make([]InvalidationKey, len(procedures))
+	for i, p := range procedures {
+		keys[i] = InvalidationKey{Procedure: p}
+	}
+	r.InvalidateKeys(keys...)
+}
+
+// InvalidateKeys notifies connected clients that specific query keys are stale.
+func (r *Router) InvalidateKeys(keys ...InvalidationKey) {
+	publishInvalidation(r.Hub(), keys)
+}
+
+func publishInvalidation(hub *Hub, keys []InvalidationKey) {
+	if len(keys) == 0 {
+		return
+	}
+	hub.Publish(invalidationTopic, InvalidationEvent{Keys: keys})
+}
+
+// invalidationSubscription builds the built-in subscription streaming
+// invalidation events from hub until the client disconnects.
+func invalidationSubscription(hub *Hub) SubscriptionDef {
+	return SubscriptionDef{
+		Name:        InvalidationSubscription,
+		InputSchema: map[string]any{},
+		Handler: func(ctx context.Context, _ json.RawMessage) (<-chan SubscriptionEvent, error) {
+			msgs := hub.Subscribe(ctx, invalidationTopic)
+			out := make(chan SubscriptionEvent)
+			go func() {
+				defer close(out)
+				for msg := range msgs {
+					select {
+					case out <- SubscriptionEvent{Value: msg}:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}()
+			return out, nil
+		},
+	}
+}
+
+// MappingValue maps a query input field from a command input field. With
+// Each set, From names an array and one key is produced per element.
+type MappingValue struct {
+	From string `json:"from"`
+	Each bool   `json:"each,omitempty"`
+}
+
+// InvalidateTarget declares a query made stale by a command. Without a
+// mapping every cached input of the query is invalidated.
+type InvalidateTarget struct {
+	Query   string                  `json:"query"`
+	Mapping map[string]MappingValue `json:"mapping,omitempty"`
+}
+
+// Invalidates declares queries made stale by this command. After each
+// successful call the server publishes their keys on the invalidation
+// subscription; the declaration is also emitted in the manifest.
+func (p *ProcedureDef) Invalidates(queries ...string) *ProcedureDef {
+	for _, q := range queries {
+		p.InvalidateTargets = append(p.InvalidateTargets, InvalidateTarget{Query: q})
+	}
+	return p
+}
+
+// WithInvalidates declares invalidation targets, including input mappings.
+func WithInvalidates(targets ...InvalidateTarget) ProcedureOption {
+	return func(p *ProcedureDef) {
+		p.InvalidateTargets = append(p.InvalidateTargets, targets...)
+	}
+}
+
+// invalidationKeys resolves a command's invalidation targets against its
+// input. Targets whose mapped fields are absent, or whose "each" mappings
+// expand past maxInvalidationInputs, degrade to invalidating the whole query.
+func invalidationKeys(targets []InvalidateTarget, input json.RawMessage) []InvalidationKey {
+	var fields map[string]any
+	_ = json.Unmarshal(input, &fields)
+
+	var keys []InvalidationKey
+	for _, t := range targets {
+		inputs, ok := mapInvalidationInputs(t.Mapping, fields)
+		if !ok {
+			keys = append(keys, InvalidationKey{Procedure: t.Query})
+			continue
+		}
+		for _, in := range inputs {
+			keys = append(keys, InvalidationKey{Procedure: t.Query, Input: in})
+		}
+	}
+	return keys
+}
+
+// maxInvalidationInputs caps the inputs one target's "each" mappings expand
+// to; larger products invalidate the whole query instead.
+const maxInvalidationInputs = 256
+
+// mapInvalidationInputs builds query inputs from command fields, expanding
+// "each" mappings into the cartesian product of their elements. ok is false
+// when a field is missing or the product exceeds maxInvalidationInputs.
+func mapInvalidationInputs(mapping map[string]MappingValue, fields map[string]any) ([]any, bool) {
+	if len(mapping) == 0 {
+		return nil, false
+	}
+	inputs := []map[string]any{{}}
+	for queryField, m := range mapping {
+		v, ok := fields[m.From]
+		if !ok {
+			return nil, false
+		}
+		values := []any{v}
+		if m.Each {
+			arr, isArr := v.([]any)
+			if !isArr {
+				return nil, false
+			}
+			values = arr
+			if len(inputs)*len(values) > maxInvalidationInputs {
+				return nil, false
+			}
+		}
+		next := make([]map[string]any, 0, len(inputs)*len(values))
+		for _, base := range inputs {
+			for _, val := range values {
+				in := make(map[string]any, len(base)+1)
+				for k, bv := range base {
+					in[k] = bv
+				}
+				in[queryField] = val
+				next = append(next, in)
+			}
+		}
+		inputs = next
+	}
+	out := make([]any, len(inputs))
+	for i, in := range inputs {
+		out[i] = in
+	}
+	return out, true
+}
@@ -0,0 +1,148 @@
+/* src/server/core/go/invalidation_test.go */
+
+package seam
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInvalidationSubscriptionReceivesRouterInvalidate(t *testing.T) {
+	router := NewRouter()
+	srv := httptest.NewServer(router.Handler(HandlerOptions{HeartbeatInterval: time.Second}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/_seam/procedure/"+InvalidationSubscription, http.NoBody)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	for router.Hub().Subscribers(invalidationTopic) == 0 {
+		time.Sleep(5 * time.Millisecond)
+	}
+	router.Invalidate("getUser")
+	router.InvalidateKeys(InvalidationKey{Procedure: "getPost", Input: map[string]any{"id": 1}})
+
+	scanner := bufio.NewScanner(resp.Body)
+	var data []string
+	for len(data) < 2 && scanner.Scan() {
+		if line := scanner.Text(); strings.HasPrefix(line, "data: ") {
+			data = append(data, line)
+		}
+	}
+	if len(data) != 2 {
+		t.Fatalf("expected two invalidation events, got %v", data)
+	}
+	if !strings.Contains(data[0], `{"keys":[{"procedure":"getUser"}]}`) {
+		t.Fatalf("unexpected first event: %s", data[0])
+	}
+	if !strings.Contains(data[1], `{"procedure":"getPost","input":{"id":1}}`) {
+		t.Fatalf("unexpected second event: %s", data[1])
+	}
+}
+
+func TestInvalidationSubscriptionNotInManifest(t *testing.T) {
+	manifest, err := NewRouter().Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(manifest), InvalidationSubscription) {
+		t.Fatalf("built-in subscription leaked into manifest: %s", manifest)
+	}
+}
+
+func TestInvalidationKeysMapping(t *testing.T) {
+	targets := []InvalidateTarget{
+		{Query: "listUsers"},
+		{Query: "getUser", Mapping: map[string]MappingValue{"id": {From: "userId"}}},
+		{Query: "getPost", Mapping: map[string]MappingValue{"id": {From: "postIds", Each: true}}},
+		{Query: "getTeam", Mapping: map[string]MappingValue{"id": {From: "missing"}}},
+	}
+	keys := invalidationKeys(targets, []byte(`{"userId":"u1","postIds":[1,2]}`))
+	got, _ := json.Marshal(keys)
+	want := `[{"procedure":"listUsers"},{"procedure":"getUser","input":{"id":"u1"}},` +
+		`{"procedure":"getPost","input":{"id":1}},{"procedure":"getPost","input":{"id":2}},{"procedure":"getTeam"}]`
+	if string(got) != want {
+		t.Fatalf("got %s\nwant %s", got, want)
+	}
+}
+
+func TestInvalidationKeysEachProductCap(t *testing.T) {
+	targets := []InvalidateTarget{{Query: "getCell", Mapping: map[string]MappingValue{
+		"row": {From: "rows", Each: true},
+		"col": {From: "cols", Each: true},
+	}}}
+	small := invalidationKeys(targets, []byte(`{"rows":[1,2],"cols":[1,2,3]}`))
+	if len(small) != 6 {
+		t.Fatalf("expected 6 keys, got %d", len(small))
+	}
+	if empty := invalidationKeys(targets, []byte(`{"rows":[],"cols":[1]}`)); len(empty) != 0 {
+		t.Fatalf("expected no keys for an empty each field, got %d", len(empty))
+	}
+
+	ids := make([]int, 100)
+	for i := range ids {
+		ids[i] = i
+	}
+	input, _ := json.Marshal(map[string]any{"rows": ids, "cols": ids})
+	keys := invalidationKeys(targets, input)
+	if len(keys) != 1 || keys[0].Input != nil || keys[0].Procedure != "getCell" {
+		t.Fatalf("expected whole-query fallback above the cap, got %d keys", len(keys))
+	}
+}
+
+func TestCommandInvalidatesPublishesAfterSuccess(t *testing.T) {
+	type in struct {
+		UserID string `json:"userId"`
+		Fail   bool   `json:"fail,omitempty"`
+	}
+	router := NewRouter().Procedure(Command("updateUser", func(_ context.Context, i in) (bool, error) {
+		if i.Fail {
+			return false, ValidationError("nope")
+		}
+		return true, nil
+	}, WithInvalidates(InvalidateTarget{Query: "getUser", Mapping: map[string]MappingValue{"id": {From: "userId"}}})).
+		Invalidates("listUsers"))
+	handler := router.Handler()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := router.Hub().Subscribe(ctx, invalidationTopic)
+
+	call := func(body string) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/_seam/procedure/updateUser", strings.NewReader(body)))
+	}
+	call(`{"userId":"u1","fail":true}`)
+	call(`{"userId":"u1"}`)
+
+	select {
+	case msg := <-events:
+		ev := msg.(InvalidationEvent)
+		if len(ev.Keys) != 2 || ev.Keys[0].Procedure != "getUser" || ev.Keys[1].Procedure != "listUsers" {
+			t.Fatalf("unexpected event: %+v", ev)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected invalidation event")
+	}
+	select {
+	case msg := <-events:
+		t.Fatalf("failed command must not invalidate, got %+v", msg)
+	default:
+	}
+
+	manifest, _ := router.Manifest()
+	if !strings.Contains(string(manifest), `"invalidates":[{"query":"getUser","mapping":{"id":{"from":"userId"}}},{"query":"listUsers"}]`) {
+		t.Fatalf("manifest missing invalidates: %s", manifest)
+	}
+}
@@ -0,0 +1,94 @@
+/* src/server/core/go/ip_filter_test.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func ipFilterTestHandler(filters ...IPFilter) http.Handler {
+	ok := func(_ context.Context, _ json.RawMessage) (any, error) { return map[string]any{"ok": true}, nil }
+	return NewRouter().
+		Procedure(&ProcedureDef{Name: "public", Handler: ok}).
+		Procedure(&ProcedureDef{Name: "admin.reset", Type: "command", Handler: ok}).
+		Handler(HandlerOptions{TrustedProxies: []string{"10.0.0.1"}, IPFilters: filters})
+}
+
+func callFrom(handler http.Handler, proc, remote, forwarded string) int {
+	req := httptest.NewRequest("POST", "/_seam/procedure/"+proc, strings.NewReader(`{}`))
+	req.RemoteAddr = remote
+	if forwarded != "" {
+		req.Header.Set("X-Forwarded-For", forwarded)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestIPFilterAllowListPerProcedure(t *testing.T) {
+	handler := ipFilterTestHandler(IPFilter{
+		Classes:    []RouteClass{RouteCommand},
+		Procedures: []string{"admin.reset"},
+		Allow:      []string{"192.168.100.0/24"},
+	})
+
+	if code := callFrom(handler, "admin.reset", "203.0.113.9:1", ""); code != http.StatusForbidden {
+		t.Errorf("outside VPN: status %d, want 403", code)
+	}
+	if code := callFrom(handler, "admin.reset", "10.0.0.1:1", "192.168.100.20"); code != http.StatusOK {
+		t.Errorf("VPN via trusted proxy: status %d, want 200", code)
+	}
+	if code := callFrom(handler, "admin.reset", "192.168.100.20:1", ""); code != http.StatusOK {
+		t.Errorf("VPN direct: status %d, want 200", code)
+	}
+	if code := callFrom(handler, "public", "203.0.113.9:1", ""); code != http.StatusOK {
+		t.Errorf("unfiltered procedure: status %d, want 200", code)
+	}
+}
+
+func TestIPFilterDenyWins(t *testing.T) {
+	handler := ipFilterTestHandler(IPFilter{
+		Allow: []string{"198.51.100.0/24"},
+		Deny:  []string{"198.51.100.66"},
+	})
+	if code := callFrom(handler, "public", "198.51.100.5:1", ""); code != http.StatusOK {
+		t.Errorf("allowed: status %d, want 200", code)
+	}
+	if code := callFrom(handler, "public", "198.51.100.66:1", ""); code != http.StatusForbidden {
+		t.Errorf("denied: status %d, want 403", code)
+	}
+}
+
+func TestIPFilterResolvedNameOnEveryTransport(t *testing.T) {
+	ok := func(context.Context, struct{}) (bool, error) { return true, nil }
+	h := opsRouter(Command("ops.wipe", ok), Query("ops.peek", ok)).
+		Subscription(Subscribe("ticks", func(context.Context, struct{}) (<-chan int, error) { return make(chan int), nil })).
+		Handler(HandlerOptions{HeartbeatInterval: time.Minute, IPFilters: []IPFilter{
+			{Classes: []RouteClass{RouteCommand}, Procedures: []string{"ops.wipe"}, Deny: []string{"0.0.0.0/0", "::/0"}},
+			{Classes: []RouteClass{RouteSubscription}, Procedures: []string{"ticks"}, Deny: []string{"0.0.0.0/0", "::/0"}},
+		}})
+
+	for transport, code := range callTransports(t, h, "ops.wipe", `{}`, nil) {
+		if code != "FORBIDDEN" {
+			t.Errorf("%s: got %q, want FORBIDDEN", transport, code)
+		}
+	}
+	for transport, code := range callTransports(t, h, "ops.peek", `{}`, nil) {
+		if code != "" {
+			t.Errorf("%s: unfiltered query got %q", transport, code)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/_seam/procedure/ticks/poll", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("poll: got %d, want 403", w.Code)
+	}
+}
@@ -0,0 +1,495 @@
+/* src/server/core/go/json_patch.go */
+
+package seam
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// PatchOp is one JSON Patch (RFC 6902) operation. JSONPatch emits add,
+// remove, and replace; ApplyPatch also runs move, copy, and test.
+type PatchOp struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"` // JSON Pointer (RFC 6901)
+	From  string `json:"from,omitempty"`
+	Value any    `json:"value"`
+}
+
+// MarshalJSON omits value on remove, move, and copy, keeping explicit
+// nulls elsewhere.
+func (p PatchOp) MarshalJSON() ([]byte, error) {
+	switch p.Op {
+	case "remove", "move", "copy":
+		return json.Marshal(struct {
+			Op   string `json:"op"`
+			Path string `json:"path"`
+			From string `json:"from,omitempty"`
+		}{p.Op, p.Path, p.From})
+	}
+	type plain PatchOp
+	return json.Marshal(plain(p))
+}
+
+// JSONPatch returns the operations turning prev into next, comparing
+// their JSON encodings: object members are added, removed, or diffed by
+// key; arrays element by element, with the tail added or removed.
+func JSONPatch(prev, next any) ([]PatchOp, error) {
+	a, err := toJSONValue(prev)
+	if err != nil {
+		return nil, err
+	}
+	b, err := toJSONValue(next)
+	if err != nil {
+		return nil, err
+	}
+	return diffJSONValues(a, b), nil
+}
+
+func toJSONValue(v any) (any, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return decodeJSONValue(raw)
+}
+
+// decodeJSONValue decodes keeping numbers exact, so equal numbers compare
+// equal and patches carry them unchanged.
+func decodeJSONValue(raw []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v any
+	err := dec.Decode(&v)
+	return v, err
+}
+
+func diffJSONValues(a, b any) []PatchOp {
+	ops := []PatchOp{}
+	diffJSONAt(&ops, "", a, b)
+	return ops
+}
+
+func diffJSONAt(ops *[]PatchOp, path string, a, b any) {
+	switch va := a.(type) {
+	case map[string]any:
+		vb, ok := b.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(va)+len(vb))
+		for k := range va {
+			keys = append(keys, k)
+		}
+		for k := range vb {
+			if _, ok := va[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			child := path + "/" + pointerEscaper.Replace(k)
+			x, inA := va[k]
+			y, inB := vb[k]
+			switch {
+			case inA && inB:
+				diffJSONAt(ops, child, x, y)
+			case inA:
+				*ops = append(*ops, PatchOp{Op: "remove", Path: child})
+			default:
+				*ops = append(*ops, PatchOp{Op: "add", Path: child, Value: y})
+			}
+		}
+		return
+	case []any:
+		vb, ok := b.([]any)
+		if !ok {
+			break
+		}
+		common := min(len(va), len(vb))
+		for i := range common {
+			diffJSONAt(ops, path+"/"+strconv.Itoa(i), va[i], vb[i])
+		}
+		for i := common; i < len(vb); i++ {
+			*ops = append(*ops, PatchOp{Op: "add", Path: path + "/" + strconv.Itoa(i), Value: vb[i]})
+		}
+		// Remove from the end so earlier indices stay valid.
+		for i := len(va) - 1; i >= common; i-- {
+			*ops = append(*ops, PatchOp{Op: "remove", Path: path + "/" + strconv.Itoa(i)})
+		}
+		return
+	}
+	if !reflect.DeepEqual(a, b) {
+		*ops = append(*ops, PatchOp{Op: "replace", Path: path, Value: b})
+	}
+}
+
+var pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+// errPatchTest marks a failed test operation.
+var errPatchTest = errors.New("test failed")
+
+func parsePointer(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("pointer %q must start with /", path)
+	}
+	tokens := strings.Split(path[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = pointerUnescaper.Replace(t)
+	}
+	return tokens, nil
+}
+
+// ApplyPatch applies operations to a decoded JSON document (maps,
+// slices, scalars) and returns the result; doc may be modified. It is
+// the client side of JSONPatch and the engine behind Patch.Apply.
+func ApplyPatch(doc any, ops []PatchOp) (any, error) {
+	for _, op := range ops {
+		var err error
+		if doc, err = applyOne(doc, op); err != nil {
+			return nil, fmt.Errorf("patch %s %q: %w", op.Op, op.Path, err)
+		}
+	}
+	return doc, nil
+}
+
+func applyOne(doc any, op PatchOp) (any, error) {
+	tokens, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+	switch op.Op {
+	case "add", "remove", "replace":
+		return applyPatchOp(doc, tokens, op.Op, op.Value)
+	case "test":
+		cur, err := valueAt(doc, tokens)
+		if err != nil {
+			return nil, err
+		}
+		want, err := toJSONValue(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(cur, want) {
+			return nil, errPatchTest
+		}
+		return doc, nil
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		v, err := valueAt(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "move" {
+			if op.Path == op.From || strings.HasPrefix(op.Path, op.From+"/") {
+				return nil, fmt.Errorf("cannot move %q into itself", op.From)
+			}
+			if doc, err = applyPatchOp(doc, from, "remove", nil); err != nil {
+				return nil, err
+			}
+		} else if v, err = toJSONValue(v); err != nil {
+			return nil, err
+		}
+		return applyPatchOp(doc, tokens, "add", v)
+	}
+	return nil, fmt.Errorf("unknown op")
+}
+
+// valueAt returns the value tokens point to.
+func valueAt(node any, tokens []string) (any, error) {
+	for _, key := range tokens {
+		switch n := node.(type) {
+		case map[string]any:
+			v, ok := n[key]
+			if !ok {
+				return nil, fmt.Errorf("no member %q", key)
+			}
+			node = v
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(n) {
+				return nil, fmt.Errorf("bad index %q", key)
+			}
+			node = n[i]
+		default:
+			return nil, fmt.Errorf("cannot descend into %T", node)
+		}
+	}
+	return node, nil
+}
+
+// applyPatchOp adds, replaces, or removes the value tokens point to.
+func applyPatchOp(node any, tokens []string, op string, value any) (any, error) {
+	if len(tokens) == 0 {
+		if op == "remove" {
+			return nil, fmt.Errorf("cannot remove the document root")
+		}
+		return value, nil
+	}
+	key, rest := tokens[0], tokens[1:]
+	switch n := node.(type) {
+	case map[string]any:
+		if len(rest) > 0 {
+			child, ok := n[key]
+			if !ok {
+				return nil, fmt.Errorf("no member %q", key)
+			}
+			v, err := applyPatchOp(child, rest, op, value)
+			if err != nil {
+				return nil, err
+			}
+			n[key] = v
+			return n, nil
+		}
+		if _, ok := n[key]; !ok && op != "add" {
+			return nil, fmt.Errorf("no member %q", key)
+		}
+		if op == "remove" {
+			delete(n, key)
+		} else {
+			n[key] = value
+		}
+		return n, nil
+	case []any:
+		i := len(n)
+		if key != "-" {
+			var err error
+			if i, err = strconv.Atoi(key); err != nil || i < 0 || i > len(n) {
+				return nil, fmt.Errorf("bad index %q", key)
+			}
+		}
+		if i == len(n) && (len(rest) > 0 || op != "add") {
+			return nil, fmt.Errorf("index %d out of range", i)
+		}
+		if len(rest) > 0 {
+			v, err := applyPatchOp(n[i], rest, op, value)
+			if err != nil {
+				return nil, err
+			}
+			n[i] = v
+			return n, nil
+		}
+		switch op {
+		case "add":
+			return slices.Insert(n, i, value), nil
+		case "replace":
+			n[i] = value
+			return n, nil
+		}
+		return slices.Delete(n, i, i+1), nil
+	}
+	return nil, fmt.Errorf("cannot descend into %T", node)
+}
+
+// Patch is a partial-update input: a JSON Patch against a T, e.g.
+//
+//	type UpdateUser struct {
+//		ID    string           `json:"id"`
+//		Patch seam.Patch[User] `json:"patch"`
+//	}
+//
+// Decoding rejects unknown operations, paths that do not exist in T's
+// schema, and removal of required fields, with VALIDATION_ERROR. The
+// handler loads the entity and calls Apply.
+type Patch[T any] []PatchOp
+
+func (Patch[T]) seamSchema() any {
+	return map[string]any{"elements": map[string]any{
+		"properties": map[string]any{
+			"op":   map[string]any{"enum": []any{"add", "remove", "replace", "move", "copy", "test"}},
+			"path": map[string]any{"type": "string"},
+		},
+		"optionalProperties": map[string]any{
+			"from":  map[string]any{"type": "string"},
+			"value": map[string]any{},
+		},
+	}}
+}
+
+// UnmarshalJSON decodes the operations and checks them against T's schema.
+func (p *Patch[T]) UnmarshalJSON(data []byte) error {
+	var ops []PatchOp
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&ops); err != nil {
+		return err
+	}
+	schema := SchemaOf[T]()
+	for i, op := range ops {
+		if err := checkPatchOp(schema, op); err != nil {
+			return fmt.Errorf("patch op %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+	*p = ops
+	return nil
+}
+
+// Touches reports whether an operation reads or writes the value at
+// pointer, inside it, or one of its parents, e.g. p.Touches("/role") to
+// reserve a field for admins.
+func (p Patch[T]) Touches(pointer string) bool {
+	for _, op := range p {
+		paths := []string{op.Path}
+		if op.Op == "move" || op.Op == "copy" {
+			paths = append(paths, op.From)
+		}
+		for _, path := range paths {
+			if path == pointer || path == "" || strings.HasPrefix(path, pointer+"/") || strings.HasPrefix(pointer, path+"/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Apply returns entity with the patch applied. A failed test operation
+// returns CONFLICT; a patch that cannot apply, or whose result does not
+// match T's schema, returns VALIDATION_ERROR. Fields JSON does not see
+// (unexported, json:"-") keep the entity's values.
+func (p Patch[T]) Apply(entity T) (T, error) {
+	var zero T
+	doc, err := toJSONValue(entity)
+	if err != nil {
+		return zero, InternalError(fmt.Sprintf("Patch target not serializable: %v", err))
+	}
+	if doc, err = ApplyPatch(doc, p); err != nil {
+		if errors.Is(err, errPatchTest) {
+			return zero, NewError("CONFLICT", err.Error(), http.StatusConflict)
+		}
+		return zero, ValidationError(err.Error())
+	}
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return zero, InternalError(err.Error())
+	}
+	cs, err := patchTargetSchema[T]()
+	if err != nil {
+		return zero, InternalError(fmt.Sprintf("invalid schema: %v", err))
+	}
+	var parsed any
+	_ = codecUnmarshal(raw, &parsed)
+	if msg, details := validateCompiled(cs, parsed); msg != "" {
+		return zero, ValidationErrorDetailed("Patched value is invalid: "+msg, toAnySlice(details))
+	}
+	out := withoutJSONFields(entity)
+	if err := codecUnmarshal(raw, &out); err != nil {
+		return zero, ValidationError("Patched value is invalid: " + err.Error())
+	}
+	return out, nil
+}
+
+var patchSchemas sync.Map // reflect.Type -> *compiledSchema
+
+func patchTargetSchema[T any]() (*compiledSchema, error) {
+	t := reflect.TypeFor[T]()
+	if cs, ok := patchSchemas.Load(t); ok {
+		return cs.(*compiledSchema), nil
+	}
+	cs, err := compileSchema(SchemaOf[T]())
+	if err != nil {
+		return nil, err
+	}
+	patchSchemas.Store(t, cs)
+	return cs, nil
+}
+
+// withoutJSONFields zeroes the fields of a struct that JSON encodes, so
+// decoding over it keeps only the rest; other types become zero.
+func withoutJSONFields[T any](entity T) T {
+	v := reflect.ValueOf(&entity).Elem()
+	if v.Kind() != reflect.Struct {
+		var zero T
+		return zero
+	}
+	for i := range v.NumField() {
+		f := v.Type().Field(i)
+		if !f.IsExported() && !f.Anonymous {
+			continue
+		}
+		if name, _ := jsonFieldName(&f); name == "-" {
+			continue
+		}
+		v.Field(i).SetZero()
+	}
+	return entity
+}
+
+// checkPatchOp validates an operation's paths against a JTD schema.
+func checkPatchOp(schema any, op PatchOp) error {
+	switch op.Op {
+	case "add", "remove", "replace", "test":
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return err
+		}
+		fromOp := "test"
+		if op.Op == "move" {
+			fromOp = "remove"
+		}
+		if err := checkPatchPath(schema, from, fromOp); err != nil {
+			return fmt.Errorf("from: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown op %q", op.Op)
+	}
+	tokens, err := parsePointer(op.Path)
+	if err != nil {
+		return err
+	}
+	return checkPatchPath(schema, tokens, op.Op)
+}
+
+func checkPatchPath(schema any, tokens []string, op string) error {
+	if len(tokens) == 0 && op == "remove" {
+		return fmt.Errorf("cannot remove the whole value")
+	}
+	s, _ := schema.(map[string]any)
+	for i, tok := range tokens {
+		last := i == len(tokens)-1
+		props, hasProps := s["properties"].(map[string]any)
+		opt, hasOpt := s["optionalProperties"].(map[string]any)
+		switch {
+		case hasProps || hasOpt:
+			if child, ok := props[tok]; ok {
+				if last && op == "remove" {
+					return fmt.Errorf("field %q is required", tok)
+				}
+				s, _ = child.(map[string]any)
+			} else if child, ok := opt[tok]; ok {
+				s, _ = child.(map[string]any)
+			} else {
+				return fmt.Errorf("no field %q", tok)
+			}
+		case s["elements"] != nil:
+			if tok != "-" || !last || op != "add" {
+				if _, err := strconv.Atoi(tok); err != nil {
+					return fmt.Errorf("%q is not an array index", tok)
+				}
+			}
+			s, _ = s["elements"].(map[string]any)
+		case s["values"] != nil:
+			s, _ = s["values"].(map[string]any)
+		case s["type"] != nil || s["enum"] != nil:
+			return fmt.Errorf("%q is inside a scalar", tok)
+		default:
+			// Discriminated unions and untyped values: checked by Apply.
+			return nil
+		}
+	}
+	return nil
+}
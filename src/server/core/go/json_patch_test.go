@@ -0,0 +1,128 @@
+/* src/server/core/go/json_patch_test.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJSONPatchRoundTrip(t *testing.T) {
+	warn := "disk 91%"
+	prev := dashboard{Title: "Ops", Counts: map[string]int{"a/b": 1, "c": 2}, Recent: []string{"x", "y", "z"}, Warning: &warn}
+	next := dashboard{Title: "Ops", Counts: map[string]int{"a/b": 3, "d": 4}, Recent: []string{"x", "w"}}
+	ops, err := JSONPatch(prev, next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := json.Marshal(ops)
+	want := `[{"op":"replace","path":"/counts/a~1b","value":3},{"op":"remove","path":"/counts/c"},` +
+		`{"op":"add","path":"/counts/d","value":4},{"op":"replace","path":"/recent/1","value":"w"},` +
+		`{"op":"remove","path":"/recent/2"},{"op":"replace","path":"/warning","value":null}]`
+	if string(raw) != want {
+		t.Errorf("patch\n%s\nwant\n%s", raw, want)
+	}
+
+	doc, _ := toJSONValue(prev)
+	got, err := ApplyPatch(doc, ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected, _ := toJSONValue(next); !reflect.DeepEqual(got, expected) {
+		t.Errorf("applied %v, want %v", got, expected)
+	}
+	if ops, _ := JSONPatch(next, next); len(ops) != 0 {
+		t.Errorf("equal states patched: %v", ops)
+	}
+}
+
+func TestApplyPatchMoveCopyTest(t *testing.T) {
+	doc, _ := toJSONValue(map[string]any{"a": map[string]any{"b": 1}, "list": []int{1, 2}})
+	ops := []PatchOp{
+		{Op: "test", Path: "/a/b", Value: 1},
+		{Op: "copy", From: "/a", Path: "/c"},
+		{Op: "move", From: "/a/b", Path: "/list/-"},
+		{Op: "add", Path: "/list/0", Value: 0},
+	}
+	got, err := ApplyPatch(doc, ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw, _ := json.Marshal(got); string(raw) != `{"a":{},"c":{"b":1},"list":[0,1,2,1]}` {
+		t.Errorf("patched %s", raw)
+	}
+	if _, err := ApplyPatch(got, []PatchOp{{Op: "test", Path: "/c/b", Value: 2}}); err == nil {
+		t.Error("failing test op applied")
+	}
+	raw, _ := json.Marshal(ops[1:3])
+	if string(raw) != `[{"op":"copy","path":"/c","from":"/a"},{"op":"move","path":"/list/-","from":"/a/b"}]` {
+		t.Errorf("marshaled %s", raw)
+	}
+}
+
+type patchUser struct {
+	Name     string            `json:"name"`
+	Age      int               `json:"age"`
+	Nickname *string           `json:"nickname,omitempty"`
+	Tags     []string          `json:"tags"`
+	Labels   map[string]string `json:"labels"`
+	Role     string            `json:"role"`
+	Password string            `json:"-"`
+}
+
+type updateUserInput struct {
+	ID    string           `json:"id"`
+	Patch Patch[patchUser] `json:"patch"`
+}
+
+func TestPatchCommand(t *testing.T) {
+	stored := patchUser{Name: "Alice", Age: 30, Tags: []string{"a"}, Labels: map[string]string{"team": "core"}, Role: "member", Password: "hash"}
+	router := NewRouter().Procedure(Command("updateUser", func(_ context.Context, in updateUserInput) (patchUser, error) {
+		if in.Patch.Touches("/role") {
+			return patchUser{}, ForbiddenError("Only admins change roles")
+		}
+		updated, err := in.Patch.Apply(stored)
+		if err != nil {
+			return patchUser{}, err
+		}
+		if updated.Password != "hash" {
+			t.Errorf("hidden field lost: %+v", updated)
+		}
+		return updated, nil
+	}))
+	h := router.Handler(HandlerOptions{})
+
+	cases := []struct {
+		patch string
+		code  int
+		want  string
+	}{
+		{`[{"op":"test","path":"/age","value":30},{"op":"replace","path":"/age","value":31},{"op":"add","path":"/tags/-","value":"b"},{"op":"add","path":"/nickname","value":"Al"},{"op":"remove","path":"/labels/team"}]`,
+			http.StatusOK, `{"name":"Alice","age":31,"nickname":"Al","tags":["a","b"],"labels":{},"role":"member"}`},
+		{`[{"op":"replace","path":"/nmae","value":"x"}]`, http.StatusBadRequest, `patch op 0 (replace /nmae): no field \"nmae\"`},
+		{`[{"op":"remove","path":"/name"}]`, http.StatusBadRequest, `field \"name\" is required`},
+		{`[{"op":"replace","path":"/age","value":"old"}]`, http.StatusBadRequest, "Patched value is invalid"},
+		{`[{"op":"test","path":"/age","value":29}]`, http.StatusConflict, `"code":"CONFLICT"`},
+		{`[{"op":"replace","path":"/role","value":"admin"}]`, http.StatusForbidden, "Only admins"},
+		{`[{"op":"merge","path":"/age"}]`, http.StatusBadRequest, "VALIDATION_ERROR"},
+	}
+	for _, c := range cases {
+		code, body := rpcBody(h, "/_seam/procedure/updateUser", `{"id":"u1","patch":`+c.patch+`}`, nil)
+		if code != c.code || !strings.Contains(body, c.want) {
+			t.Errorf("%s: %d %s", c.patch, code, body)
+		}
+	}
+}
+
+func TestPatchSchema(t *testing.T) {
+	schema := SchemaOf[updateUserInput]().(map[string]any)
+	patch := schema["properties"].(map[string]any)["patch"].(map[string]any)
+	op := patch["elements"].(map[string]any)["properties"].(map[string]any)["op"]
+	if !reflect.DeepEqual(op, map[string]any{"enum": []any{"add", "remove", "replace", "move", "copy", "test"}}) {
+		t.Errorf("patch schema %v", patch)
+	}
+}
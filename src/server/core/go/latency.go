@@ -0,0 +1,174 @@
+/* src/server/core/go/latency.go */
+
+package seam
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LatencySample is one application-level round trip measured on a
+// WebSocket (channel or RPC socket): the server's timestamped heartbeat
+// echoed back by the client as {"pong": ts}.
+type LatencySample struct {
+	Transport string // "channel" or "socket"
+	Principal string
+	RTT       time.Duration
+}
+
+// latencyFrame is a ping/pong uplink frame. Latency probes are enabled
+// by HandlerOptions.OnLatency:
+//
+//   - WS heartbeats carry the server time: {"heartbeat": true, "ts": ms}.
+//     A client echoing {"pong": ts} yields a LatencySample.
+//   - Clients may send {"ping": ts}; the server answers {"pong": ts,
+//     "serverTs": ms} so the client measures its own round trip.
+//   - SSE heartbeats are followed by a ": ping <ms>" comment, letting
+//     fetch-based readers estimate one-way delay and clock skew.
+type latencyFrame struct {
+	Ping *int64 `json:"ping,omitempty"`
+	Pong *int64 `json:"pong,omitempty"`
+}
+
+type latencyReply struct {
+	Pong     int64 `json:"pong"`
+	ServerTs int64 `json:"serverTs"`
+}
+
+func unixMillis() int64 {
+	return time.Now().UnixMilli()
+}
+
+// heartbeatFrame returns the periodic WS heartbeat, timestamped when
+// latency probes are enabled.
+func (s *appState) heartbeatFrame() wsHeartbeat {
+	if s.opts.OnLatency == nil {
+		return wsHeartbeat{Heartbeat: true}
+	}
+	return wsHeartbeat{Heartbeat: true, TS: unixMillis()}
+}
+
+// handleLatencyFrame consumes ping/pong uplink frames, reporting whether
+// the message was one.
+func (s *appState) handleLatencyFrame(message []byte, transport string, r *http.Request, ws *wsWriter) bool {
+	if s.opts.OnLatency == nil || !strings.Contains(string(message), `"p`) {
+		return false
+	}
+	var f latencyFrame
+	if codecUnmarshal(message, &f) != nil || (f.Ping == nil && f.Pong == nil) {
+		return false
+	}
+	if f.Ping != nil {
+		_ = ws.frame(latencyReply{Pong: *f.Ping, ServerTs: unixMillis()})
+	}
+	if f.Pong != nil {
+		rtt := time.Duration(unixMillis()-*f.Pong) * time.Millisecond
+		if rtt >= 0 {
+			principal := ""
+			if s.opts.Principal != nil {
+				principal = s.opts.Principal(r)
+			}
+			s.opts.OnLatency(LatencySample{Transport: transport, Principal: principal, RTT: rtt})
+		}
+	}
+	return true
+}
+
+// writeSSEHeartbeat writes a periodic SSE heartbeat comment, followed by a
+// timestamped ping comment when latency probes are enabled.
+func (s *appState) writeSSEHeartbeat(w http.ResponseWriter) {
+	if s.opts.OnLatency == nil {
+		_, _ = fmt.Fprintf(w, ": heartbeat\n\n")
+		return
+	}
+	_, _ = fmt.Fprintf(w, ": heartbeat\n: ping %d\n\n", unixMillis())
+}
+
+// LatencyMetrics aggregates LatencySamples per transport; pass its
+// Observe method as HandlerOptions.OnLatency and serve it for
+// Prometheus-style scraping.
+type LatencyMetrics struct {
+	Window int // samples kept per transport (default 1024)
+
+	mu      sync.Mutex
+	samples map[string]*latencySamples
+}
+
+type latencySamples struct {
+	ring  []int64 // microseconds
+	next  int
+	count int64
+}
+
+// LatencyStats summarizes round trips for one transport.
+type LatencyStats struct {
+	Transport string        `json:"transport"`
+	Count     int64         `json:"count"`
+	P50       time.Duration `json:"p50"`
+	P90       time.Duration `json:"p90"`
+	P99       time.Duration `json:"p99"`
+}
+
+func (m *LatencyMetrics) Observe(sample LatencySample) {
+	window := m.Window
+	if window <= 0 {
+		window = 1024
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.samples == nil {
+		m.samples = make(map[string]*latencySamples)
+	}
+	s, ok := m.samples[sample.Transport]
+	if !ok {
+		s = &latencySamples{ring: make([]int64, 0, window)}
+		m.samples[sample.Transport] = s
+	}
+	us := sample.RTT.Microseconds()
+	if len(s.ring) < cap(s.ring) {
+		s.ring = append(s.ring, us)
+	} else {
+		s.ring[s.next] = us
+		s.next = (s.next + 1) % len(s.ring)
+	}
+	s.count++
+}
+
+// Snapshot returns stats per transport, sorted by transport.
+func (m *LatencyMetrics) Snapshot() []LatencyStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stats := make([]LatencyStats, 0, len(m.samples))
+	for transport, s := range m.samples {
+		sorted := append([]int64(nil), s.ring...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		stats = append(stats, LatencyStats{
+			Transport: transport,
+			Count:     s.count,
+			P50:       time.Duration(percentile(sorted, 0.50)) * time.Microsecond,
+			P90:       time.Duration(percentile(sorted, 0.90)) * time.Microsecond,
+			P99:       time.Duration(percentile(sorted, 0.99)) * time.Microsecond,
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Transport < stats[j].Transport })
+	return stats
+}
+
+// ServeHTTP writes the stats in the Prometheus text exposition format.
+func (m *LatencyMetrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	fmt.Fprintln(w, "# TYPE seam_connection_rtt_seconds summary")
+	for _, s := range m.Snapshot() {
+		for _, q := range []struct {
+			label string
+			value time.Duration
+		}{{"0.5", s.P50}, {"0.9", s.P90}, {"0.99", s.P99}} {
+			fmt.Fprintf(w, "seam_connection_rtt_seconds{transport=%q,quantile=%q} %g\n", s.Transport, q.label, q.value.Seconds())
+		}
+		fmt.Fprintf(w, "seam_connection_rtt_seconds_count{transport=%q} %d\n", s.Transport, s.Count)
+	}
+}
@@ -0,0 +1,90 @@
+/* src/server/core/go/latency_test.go */
+
+package seam
+
+import (
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestLatencyFramesOnSocket(t *testing.T) {
+	var mu sync.Mutex
+	var samples []LatencySample
+	handler := NewRouter().Handler(HandlerOptions{
+		WebSocketRPC:      true,
+		HeartbeatInterval: time.Minute,
+		OnLatency: func(s LatencySample) {
+			mu.Lock()
+			samples = append(samples, s)
+			mu.Unlock()
+		},
+	})
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/_seam/ws", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	sent := time.Now().UnixMilli()
+	_ = conn.WriteJSON(map[string]any{"ping": sent})
+	var reply map[string]any
+	if err := conn.ReadJSON(&reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply["pong"] != float64(sent) || reply["serverTs"] == nil {
+		t.Fatalf("unexpected ping reply %v", reply)
+	}
+
+	// Echo a heartbeat timestamp from 50ms ago; a ping round trip follows so
+	// the pong is processed before the assertion
+	_ = conn.WriteJSON(map[string]any{"pong": time.Now().Add(-50 * time.Millisecond).UnixMilli()})
+	_ = conn.WriteJSON(map[string]any{"ping": sent})
+	if err := conn.ReadJSON(&reply); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(samples) != 1 || samples[0].Transport != "socket" || samples[0].RTT < 50*time.Millisecond {
+		t.Fatalf("unexpected samples %+v", samples)
+	}
+}
+
+func TestSSEHeartbeatPingComment(t *testing.T) {
+	s := &appState{opts: HandlerOptions{OnLatency: func(LatencySample) {}}}
+	w := httptest.NewRecorder()
+	s.writeSSEHeartbeat(w)
+	if !strings.HasPrefix(w.Body.String(), ": heartbeat\n: ping ") {
+		t.Fatalf("unexpected heartbeat %q", w.Body.String())
+	}
+
+	s.opts.OnLatency = nil
+	w = httptest.NewRecorder()
+	s.writeSSEHeartbeat(w)
+	if w.Body.String() != ": heartbeat\n\n" {
+		t.Fatalf("probes must be off without OnLatency, got %q", w.Body.String())
+	}
+}
+
+func TestLatencyMetrics(t *testing.T) {
+	m := &LatencyMetrics{}
+	for i := 1; i <= 10; i++ {
+		m.Observe(LatencySample{Transport: "socket", RTT: time.Duration(i) * time.Millisecond})
+	}
+	stats := m.Snapshot()
+	if len(stats) != 1 || stats[0].Count != 10 || stats[0].P50 != 5*time.Millisecond || stats[0].P99 != 10*time.Millisecond {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, nil)
+	if !strings.Contains(w.Body.String(), `seam_connection_rtt_seconds_count{transport="socket"} 10`) {
+		t.Fatalf("unexpected metrics:\n%s", w.Body.String())
+	}
+}
@@ -0,0 +1,138 @@
+/* src/server/core/go/layout_cache.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// layoutCacheSweepSize triggers a sweep of expired entries on insert.
+const layoutCacheSweepSize = 1024
+
+// layoutCache holds layout loader results across requests, keyed by
+// layout id, principal, data key, and loader input. Page-level loaders
+// are never cached, and neither are anonymous requests outside
+// HandlerOptions.PublicLayouts. Entries are dropped when their procedure is
+// invalidated through the hub.
+type layoutCache struct {
+	ttl     time.Duration
+	tuning  *Tuning // overrides ttl when set
+	mu      sync.Mutex
+	entries map[string]layoutCacheEntry
+}
+
+type layoutCacheEntry struct {
+	value     any
+	procedure string
+	input     []byte
+	expires   time.Time
+}
+
+func newLayoutCache(ttl time.Duration) *layoutCache {
+	return &layoutCache{ttl: ttl, entries: make(map[string]layoutCacheEntry)}
+}
+
+func layoutCacheKey(layoutID, principal, dataKey string, input []byte) string {
+	return layoutID + "\x00" + principal + "\x00" + dataKey + "\x00" + string(input)
+}
+
+func (c *layoutCache) get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(e.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return e.value, true
+}
+
+func (c *layoutCache) set(key, procedure string, input []byte, value any) {
+	ttl := c.tuning.layoutTTLOr(c.ttl)
+	if ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if len(c.entries) >= layoutCacheSweepSize {
+		for k, e := range c.entries {
+			if now.After(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+	}
+	c.entries[key] = layoutCacheEntry{value: value, procedure: procedure, input: input, expires: now.Add(ttl)}
+}
+
+// invalidate drops entries matching the keys; a nil Input matches every
+// input of the procedure.
+func (c *layoutCache) invalidate(keys []InvalidationKey) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		var input []byte
+		if key.Input != nil {
+			input, _ = json.Marshal(key.Input)
+		}
+		for k, e := range c.entries {
+			if e.procedure == key.Procedure && (input == nil || jsonEqual(input, e.input)) {
+				delete(c.entries, k)
+			}
+		}
+	}
+}
+
+// watch applies hub invalidations until ctx is done (the handler was
+// stopped or collected) or shutdown is closed.
+func (c *layoutCache) watch(ctx context.Context, hub *Hub, shutdown <-chan struct{}) {
+	ctx, cancel := context.WithCancel(ctx)
+	msgs := hub.Subscribe(ctx, invalidationTopic)
+	go func() {
+		defer cancel()
+		for {
+			select {
+			case <-shutdown:
+				return
+			case msg, ok := <-msgs:
+				if !ok {
+					return
+				}
+				if ev, ok := msg.(InvalidationEvent); ok {
+					c.invalidate(ev.Keys)
+				}
+			}
+		}
+	}()
+}
+
+// jsonEqual compares two JSON documents structurally.
+func jsonEqual(a, b []byte) bool {
+	var av, bv any
+	if json.Unmarshal(a, &av) != nil || json.Unmarshal(b, &bv) != nil {
+		return false
+	}
+	ac, _ := json.Marshal(av)
+	bc, _ := json.Marshal(bv)
+	return string(ac) == string(bc)
+}
+
+// layoutOwners maps each layout loader data key to its layout id.
+func layoutOwners(page *PageDef) map[string]string {
+	if len(page.LayoutChain) == 0 {
+		return nil
+	}
+	owners := make(map[string]string)
+	for _, entry := range page.LayoutChain {
+		for _, key := range entry.LoaderKeys {
+			owners[key] = entry.ID
+		}
+	}
+	return owners
+}
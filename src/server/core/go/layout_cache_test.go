@@ -0,0 +1,135 @@
+/* src/server/core/go/layout_cache_test.go */
+
+package seam
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLayoutCacheSkipsRepeatedLayoutLoaders(t *testing.T) {
+	var sessionCalls, pageCalls atomic.Int32
+	router := NewRouter().
+		Procedure(Query("getSession", func(ctx context.Context, _ struct{}) (map[string]string, error) {
+			sessionCalls.Add(1)
+			return map[string]string{"user": PrincipalOf(ctx)}, nil
+		})).
+		Procedure(Query("getPage", func(_ context.Context, _ struct{}) (map[string]string, error) {
+			pageCalls.Add(1)
+			return map[string]string{"title": "home"}, nil
+		})).
+		Page(&PageDef{
+			Route:    "/home",
+			Template: "<html><body><!--seam:session.user--> <!--seam:page.title--></body></html>",
+			Loaders: []LoaderDef{
+				{DataKey: "session", Procedure: "getSession", InputFn: func(map[string]string) any { return map[string]any{} }},
+				{DataKey: "page", Procedure: "getPage", InputFn: func(map[string]string) any { return map[string]any{} }},
+			},
+			LayoutChain:    []LayoutChainEntry{{ID: "root", LoaderKeys: []string{"session"}}},
+			PageLoaderKeys: []string{"page"},
+		})
+	handler := router.Handler(HandlerOptions{
+		LayoutCacheTTL: time.Minute,
+		Principal:      func(r *http.Request) string { return r.Header.Get("X-User") },
+	})
+
+	get := func(user string) {
+		t.Helper()
+		req := httptest.NewRequest("GET", "/_seam/page/home", nil)
+		req.Header.Set("X-User", user)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status %d: %s", w.Code, w.Body.String())
+		}
+	}
+
+	get("alice")
+	get("alice")
+	if sessionCalls.Load() != 1 || pageCalls.Load() != 2 {
+		t.Fatalf("after two alice requests: session=%d page=%d, want 1/2", sessionCalls.Load(), pageCalls.Load())
+	}
+	get("bob")
+	if sessionCalls.Load() != 2 {
+		t.Fatalf("principal should be part of the key: session=%d", sessionCalls.Load())
+	}
+
+	router.Invalidate("getSession")
+	deadline := time.Now().Add(time.Second)
+	for {
+		get("alice")
+		if sessionCalls.Load() == 3 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if sessionCalls.Load() != 3 {
+		t.Fatalf("invalidation should drop cached layout data: session=%d", sessionCalls.Load())
+	}
+}
+
+func TestLayoutCacheAnonymousOnlyForPublicLayouts(t *testing.T) {
+	var calls atomic.Int32
+	router := NewRouter().
+		Procedure(Query("getNav", func(context.Context, struct{}) (string, error) {
+			calls.Add(1)
+			return "nav", nil
+		})).
+		Page(&PageDef{
+			Route:       "/home",
+			Template:    "<html><body><!--seam:nav--></body></html>",
+			Loaders:     []LoaderDef{{DataKey: "nav", Procedure: "getNav", InputFn: func(map[string]string) any { return map[string]any{} }}},
+			LayoutChain: []LayoutChainEntry{{ID: "root", LoaderKeys: []string{"nav"}}},
+		})
+	get := func(h http.Handler) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/_seam/page/home", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("status %d: %s", w.Code, w.Body.String())
+		}
+	}
+
+	private := router.Handler(HandlerOptions{LayoutCacheTTL: time.Minute})
+	get(private)
+	get(private)
+	if calls.Load() != 2 {
+		t.Fatalf("anonymous layout data was cached: %d calls", calls.Load())
+	}
+
+	public := router.Handler(HandlerOptions{LayoutCacheTTL: time.Minute, PublicLayouts: []string{"root"}})
+	get(public)
+	get(public)
+	if calls.Load() != 3 {
+		t.Fatalf("public layout not cached: %d calls", calls.Load())
+	}
+}
+
+func TestLayoutCacheExpiry(t *testing.T) {
+	c := newLayoutCache(time.Millisecond)
+	c.set("k", "getSession", []byte(`{}`), "v")
+	if _, ok := c.get("k"); !ok {
+		t.Fatal("expected fresh entry")
+	}
+	time.Sleep(2 * time.Millisecond)
+	if _, ok := c.get("k"); ok {
+		t.Fatal("expected expired entry to miss")
+	}
+}
+
+func TestLayoutCacheInvalidateByInput(t *testing.T) {
+	c := newLayoutCache(time.Minute)
+	c.set("a", "getOrg", []byte(`{"id":"1"}`), "one")
+	c.set("b", "getOrg", []byte(`{"id":"2"}`), "two")
+	c.invalidate([]InvalidationKey{{Procedure: "getOrg", Input: map[string]any{"id": "1"}}})
+	if _, ok := c.get("a"); ok {
+		t.Error("matching input should be dropped")
+	}
+	if _, ok := c.get("b"); !ok {
+		t.Error("other inputs should stay cached")
+	}
+}
@@ -0,0 +1,231 @@
+/* src/server/core/go/lifecycle.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// ReadinessCheck reports whether a dependency (e.g. the message broker)
+// is reachable; a non-nil error fails readiness.
+type ReadinessCheck func(ctx context.Context) error
+
+// Lifecycle ties a server to Kubernetes probes for rolling updates: the
+// pod reports ready only once the build output is loaded (MarkLoaded) and
+// every check passes, and on SIGTERM it fails readiness first, keeps
+// serving for PreStopDelay while endpoints stop routing to it, then drains
+// in-flight RPCs and notifies SSE/WebSocket clients before exiting.
+type Lifecycle struct {
+	// PreStopDelay is how long the pod keeps serving after SIGTERM with
+	// readiness failing (default 5s, negative for none); keep it below the
+	// pod's terminationGracePeriodSeconds minus DrainTimeout.
+	PreStopDelay time.Duration
+	// DrainTimeout bounds the graceful shutdown after the delay (default 20s).
+	DrainTimeout time.Duration
+	// CheckTimeout bounds each readiness check (default 2s).
+	CheckTimeout time.Duration
+	// ReadyPath and LivePath are the probe endpoints ListenAndServe adds
+	// (default "/readyz" and "/livez").
+	ReadyPath string
+	LivePath  string
+	// Health, when set, reports NOT_SERVING until MarkLoaded and from
+	// SIGTERM on, for meshes probing gRPC health.
+	Health *GRPCHealth
+	// Dependencies, when set, fails readiness while a critical upstream
+	// is down and adds the dependency statuses to the probe body.
+	Dependencies *Dependencies
+
+	mu       sync.Mutex
+	checks   map[string]ReadinessCheck
+	loaded   atomic.Bool
+	draining atomic.Bool
+}
+
+// NewLifecycle creates a lifecycle with the default timings.
+func NewLifecycle() *Lifecycle {
+	return &Lifecycle{checks: make(map[string]ReadinessCheck)}
+}
+
+// AddCheck registers a readiness check under name.
+func (l *Lifecycle) AddCheck(name string, check ReadinessCheck) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.checks == nil {
+		l.checks = make(map[string]ReadinessCheck)
+	}
+	l.checks[name] = check
+}
+
+// MarkLoaded records that the build output is loaded, e.g. after
+// LoadBuildOutput succeeds; readiness fails until then.
+func (l *Lifecycle) MarkLoaded() {
+	l.loaded.Store(true)
+	if l.Health != nil {
+		l.Health.SetServingStatus("", HealthServing)
+	}
+}
+
+// Draining reports whether shutdown has begun.
+func (l *Lifecycle) Draining() bool {
+	return l.draining.Load()
+}
+
+// Ready runs the checks and returns the failing ones by name; an empty
+// map means ready.
+func (l *Lifecycle) Ready(ctx context.Context) map[string]string {
+	failures := make(map[string]string)
+	if l.draining.Load() {
+		failures["lifecycle"] = "shutting down"
+	}
+	if !l.loaded.Load() {
+		failures["build"] = "build output not loaded"
+	}
+
+	l.mu.Lock()
+	checks := make(map[string]ReadinessCheck, len(l.checks))
+	for name, check := range l.checks {
+		checks[name] = check
+	}
+	l.mu.Unlock()
+	if l.Dependencies != nil {
+		checks["dependencies"] = l.Dependencies.Check()
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for name, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, l.checkTimeout())
+			defer cancel()
+			if err := check(checkCtx); err != nil {
+				mu.Lock()
+				failures[name] = err.Error()
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return failures
+}
+
+// ReadyHandler serves the readiness probe: 200 when ready, else 503 with
+// the failing checks; with Dependencies set the body lists their statuses.
+func (l *Lifecycle) ReadyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		failures := l.Ready(r.Context())
+		body := map[string]any{"status": "ready"}
+		status := http.StatusOK
+		if len(failures) > 0 {
+			body["status"], body["failures"] = "not ready", failures
+			status = http.StatusServiceUnavailable
+		}
+		if l.Dependencies != nil {
+			body["dependencies"] = l.Dependencies.Snapshot()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(body)
+	})
+}
+
+// LiveHandler serves the liveness probe, which passes while the process
+// serves requests, draining included.
+func (l *Lifecycle) LiveHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		_, _ = w.Write([]byte(`{"status":"ok"}` + "\n"))
+	})
+}
+
+// ListenAndServe is the lifecycle-aware ListenAndServe: it serves handler
+// plus the probe endpoints on addr until SIGINT or SIGTERM, then runs the
+// shutdown sequence. Returns nil on clean shutdown.
+func (l *Lifecycle) ListenAndServe(addr string, handler http.Handler) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Seam Go backend running on http://localhost:%d\n", ln.Addr().(*net.TCPAddr).Port)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	return l.Serve(ctx, ln, handler)
+}
+
+// Serve serves on ln until ctx is done (the termination signal), then
+// fails readiness, waits PreStopDelay, and shuts the server down within
+// DrainTimeout.
+func (l *Lifecycle) Serve(ctx context.Context, ln net.Listener, handler http.Handler) error {
+	mux := http.NewServeMux()
+	mux.Handle(l.path(l.ReadyPath, "/readyz"), l.ReadyHandler())
+	mux.Handle(l.path(l.LivePath, "/livez"), l.LiveHandler())
+	mux.Handle("/", handler)
+	srv := &http.Server{Handler: mux}
+	if l.Health != nil && !l.loaded.Load() {
+		l.Health.SetServingStatus("", HealthNotServing)
+	}
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- srv.Serve(ln) }()
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+	}
+
+	l.draining.Store(true)
+	if l.Health != nil {
+		l.Health.Shutdown()
+	}
+	delay := l.PreStopDelay
+	if delay == 0 {
+		delay = 5 * time.Second
+	}
+	if delay > 0 {
+		logf(slog.LevelInfo, "shutdown requested: readiness failing, draining in %s\n", delay)
+		select {
+		case <-time.After(delay):
+		case err := <-errCh:
+			return err
+		}
+	}
+
+	drain := l.DrainTimeout
+	if drain <= 0 {
+		drain = 20 * time.Second
+	}
+	drainCtx, cancel := context.WithTimeout(context.Background(), drain)
+	defer cancel()
+	return srv.Shutdown(drainCtx)
+}
+
+func (l *Lifecycle) checkTimeout() time.Duration {
+	if l.CheckTimeout > 0 {
+		return l.CheckTimeout
+	}
+	return 2 * time.Second
+}
+
+func (l *Lifecycle) path(p, def string) string {
+	if p == "" {
+		p = def
+	}
+	return "GET " + p
+}
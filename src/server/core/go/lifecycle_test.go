@@ -0,0 +1,142 @@
+/* src/server/core/go/lifecycle_test.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func readyStatus(t *testing.T, l *Lifecycle) (int, map[string]string) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	l.ReadyHandler().ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+	var body struct {
+		Failures map[string]string `json:"failures"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+	return w.Code, body.Failures
+}
+
+func TestLifecycleReadiness(t *testing.T) {
+	l := NewLifecycle()
+	brokerDown := errors.New("broker unreachable")
+	var brokerErr error = brokerDown
+	l.AddCheck("broker", func(context.Context) error { return brokerErr })
+
+	code, failures := readyStatus(t, l)
+	if code != 503 || failures["build"] == "" || failures["broker"] != "broker unreachable" {
+		t.Fatalf("before load: %d %v", code, failures)
+	}
+	l.MarkLoaded()
+	if code, failures = readyStatus(t, l); code != 503 || len(failures) != 1 {
+		t.Fatalf("broker down: %d %v", code, failures)
+	}
+	brokerErr = nil
+	if code, failures = readyStatus(t, l); code != 200 || len(failures) != 0 {
+		t.Fatalf("ready: %d %v", code, failures)
+	}
+}
+
+func TestLifecycleCheckTimeout(t *testing.T) {
+	l := &Lifecycle{CheckTimeout: 20 * time.Millisecond}
+	l.MarkLoaded()
+	l.AddCheck("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if code, failures := readyStatus(t, l); code != 503 || failures["slow"] != context.DeadlineExceeded.Error() {
+		t.Fatalf("slow check: %d %v", code, failures)
+	}
+}
+
+func TestLifecycleShutdownSequence(t *testing.T) {
+	health := NewGRPCHealth()
+	l := &Lifecycle{PreStopDelay: 300 * time.Millisecond, DrainTimeout: 2 * time.Second, Health: health}
+	if status, _ := health.Status(""); status != HealthServing {
+		t.Fatalf("initial health %s", status)
+	}
+
+	inFlight := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			close(inFlight)
+			time.Sleep(400 * time.Millisecond)
+		}
+		_, _ = io.WriteString(w, "done")
+	})
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := "http://" + ln.Addr().String()
+	ctx, terminate := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- l.Serve(ctx, ln, handler) }()
+
+	get := func(path string) (int, string) {
+		resp, err := http.Get(base + path)
+		if err != nil {
+			return 0, err.Error()
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	if code, _ := get("/readyz"); code != 503 {
+		t.Fatalf("readyz before load: %d", code)
+	}
+	if status, _ := health.Status(""); status != HealthNotServing {
+		t.Fatalf("health before load: %s", status)
+	}
+	l.MarkLoaded()
+	if code, _ := get("/readyz"); code != 200 {
+		t.Fatalf("readyz after load: %d", code)
+	}
+
+	slow := make(chan string, 1)
+	go func() {
+		_, body := get("/slow")
+		slow <- body
+	}()
+	<-inFlight
+	terminate()
+	time.Sleep(50 * time.Millisecond)
+
+	// During the pre-stop delay: not ready, but still serving
+	if code, _ := get("/readyz"); code != 503 {
+		t.Fatalf("readyz while draining: %d", code)
+	}
+	if code, body := get("/page"); code != 200 || body != "done" {
+		t.Fatalf("request during pre-stop delay: %d %s", code, body)
+	}
+	if code, _ := get("/livez"); code != 200 {
+		t.Fatalf("livez while draining: %d", code)
+	}
+	if status, _ := health.Status(""); status != HealthNotServing {
+		t.Fatalf("health while draining: %s", status)
+	}
+
+	if body := <-slow; body != "done" {
+		t.Fatalf("in-flight request dropped: %s", body)
+	}
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Fatalf("Serve: %v", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Serve did not return")
+	}
+	if code, _ := get("/page"); code != 0 {
+		t.Fatalf("served after shutdown: %d", code)
+	}
+}
@@ -0,0 +1,354 @@
+/* src/server/core/go/lint_template.go */
+
+package seam
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TemplateIssue is one finding of LintTemplate.
+type TemplateIssue struct {
+	Kind    string `json:"kind"`   // "unknown-key" | "dead-branch" | "not-iterable" | "unbalanced"
+	Marker  string `json:"marker"` // directive of the offending marker, e.g. "if:user.admin"
+	Message string `json:"message"`
+}
+
+// LintTemplate cross-checks the markers of a page template against the
+// JTD schema of its data: an object schema whose properties are the
+// loader keys and whose values are the loaders' output schemas. It
+// reports slots, conditionals, loops, and match blocks naming keys the
+// schema lacks, loops over non-lists, match arms the schema's enum or
+// boolean type can never select, else branches of conditionals on values
+// that are always truthy, and unbalanced blocks. Slot names resolve like
+// the engine's: by full path, else through the fields of top-level
+// objects. Empty ({}) schemas accept any path.
+func LintTemplate(html string, schema any) []TemplateIssue {
+	l := &templateLinter{root: schema}
+	if m, ok := schema.(map[string]any); ok {
+		l.defs, _ = m["definitions"].(map[string]any)
+	}
+	l.run(html)
+	return l.issues
+}
+
+type templateLinter struct {
+	root   any
+	defs   map[string]any
+	issues []TemplateIssue
+	blocks []lintBlock
+	scopes []any // element schemas of enclosing loops, innermost last
+}
+
+type lintBlock struct {
+	kind      string // "if" | "each" | "match"
+	directive string
+	schema    any // if/match: schema of the tested value (nil when unknown)
+	found     bool
+}
+
+func (l *templateLinter) report(kind, directive, format string, args ...any) {
+	l.issues = append(l.issues, TemplateIssue{Kind: kind, Marker: directive, Message: fmt.Sprintf(format, args...)})
+}
+
+func (l *templateLinter) run(html string) {
+	for {
+		start := strings.Index(html, "<!--seam:")
+		if start < 0 {
+			break
+		}
+		html = html[start+len("<!--seam:"):]
+		end := strings.Index(html, "-->")
+		if end < 0 {
+			break
+		}
+		directive := html[:end]
+		html = html[end+len("-->"):]
+		l.marker(directive)
+	}
+	for i := len(l.blocks) - 1; i >= 0; i-- {
+		l.report("unbalanced", l.blocks[i].directive, "%s block is never closed", l.blocks[i].kind)
+	}
+}
+
+func (l *templateLinter) marker(directive string) {
+	switch {
+	case reservedSlots[directive]:
+	case strings.HasPrefix(directive, "include:"): // expanded at load time
+	case strings.HasPrefix(directive, "if:"):
+		schema, found := l.lookup(directive, strings.TrimPrefix(directive, "if:"))
+		l.blocks = append(l.blocks, lintBlock{kind: "if", directive: directive, schema: schema, found: found})
+	case directive == "else":
+		if top := l.top("if"); top == nil {
+			l.report("unbalanced", directive, "else outside an if block")
+		} else if top.found && alwaysTruthy(l.deref(top.schema)) {
+			l.report("dead-branch", top.directive, "else branch never renders: %s is always truthy", strings.TrimPrefix(top.directive, "if:"))
+		}
+	case strings.HasPrefix(directive, "endif:"):
+		top := l.top("if")
+		if top == nil || top.directive != "if:"+strings.TrimPrefix(directive, "endif:") {
+			l.report("unbalanced", directive, "endif does not close a matching if block")
+			return
+		}
+		l.pop()
+	case strings.HasPrefix(directive, "each:"):
+		path := strings.TrimPrefix(directive, "each:")
+		schema, found := l.lookup(directive, path)
+		var elem any = map[string]any{}
+		if found {
+			if m, ok := l.deref(schema).(map[string]any); ok && schemaForm(m) == "elements" {
+				elem = m["elements"]
+			} else if ok && schemaForm(m) != "empty" {
+				l.report("not-iterable", directive, "%s is not a list", path)
+			}
+		}
+		l.blocks = append(l.blocks, lintBlock{kind: "each", directive: directive})
+		l.scopes = append(l.scopes, elem)
+	case directive == "endeach":
+		if l.top("each") == nil {
+			l.report("unbalanced", directive, "endeach outside an each block")
+			return
+		}
+		l.pop()
+		l.scopes = l.scopes[:len(l.scopes)-1]
+	case strings.HasPrefix(directive, "match:"):
+		schema, found := l.lookup(directive, strings.TrimPrefix(directive, "match:"))
+		l.blocks = append(l.blocks, lintBlock{kind: "match", directive: directive, schema: schema, found: found})
+	case strings.HasPrefix(directive, "when:"):
+		top := l.top("match")
+		if top == nil {
+			l.report("unbalanced", directive, "when outside a match block")
+			return
+		}
+		value := strings.TrimPrefix(directive, "when:")
+		if values := matchValues(l.deref(top.schema)); top.found && values != nil && !values[value] {
+			l.report("dead-branch", top.directive, "arm %q never matches: %s is one of %s",
+				value, strings.TrimPrefix(top.directive, "match:"), strings.Join(sortedKeys(values), ", "))
+		}
+	case directive == "endmatch":
+		if l.top("match") == nil {
+			l.report("unbalanced", directive, "endmatch outside a match block")
+			return
+		}
+		l.pop()
+	default:
+		path := directive
+		if i := strings.Index(path, ":style:"); i >= 0 {
+			path = path[:i]
+		} else if i := strings.Index(path, ":attr:"); i >= 0 {
+			path = path[:i]
+		}
+		path, _, _ = strings.Cut(strings.TrimSuffix(path, ":html"), "|") // slot filters
+		l.lookup(directive, path)
+	}
+}
+
+// top returns the innermost open block when it has the given kind.
+func (l *templateLinter) top(kind string) *lintBlock {
+	if len(l.blocks) == 0 || l.blocks[len(l.blocks)-1].kind != kind {
+		return nil
+	}
+	return &l.blocks[len(l.blocks)-1]
+}
+
+func (l *templateLinter) pop() { l.blocks = l.blocks[:len(l.blocks)-1] }
+
+// lookup resolves a marker path to its schema, reporting unknown keys.
+// found is false when the path could not be checked or was reported.
+func (l *templateLinter) lookup(directive, path string) (schema any, found bool) {
+	head, rest, _ := strings.Cut(path, ".")
+	switch {
+	case head == "$" || head == "$$":
+		depth := 1
+		if head == "$$" {
+			depth = 2
+		}
+		if len(l.scopes) < depth {
+			l.report("unknown-key", directive, "%s used outside an each block", head)
+			return nil, false
+		}
+		return l.walk(directive, path, l.scopes[len(l.scopes)-depth], rest)
+	case reservedDataKeys[head]:
+		return nil, false
+	}
+	if schema, ok := l.field(l.root, head); ok {
+		return l.walk(directive, path, schema, rest)
+	}
+	// Flattened slot: a field of a top-level object
+	if props := l.properties(l.root); props != nil {
+		for _, key := range sortedKeys(props) {
+			if schema, ok := l.field(props[key], head); ok {
+				return l.walk(directive, path, schema, rest)
+			}
+		}
+	}
+	if l.open(l.root) {
+		return nil, false
+	}
+	l.report("unknown-key", directive, "unknown key %q", path)
+	return nil, false
+}
+
+func (l *templateLinter) walk(directive, path string, schema any, rest string) (any, bool) {
+	if rest == "" {
+		return schema, true
+	}
+	for _, seg := range strings.Split(rest, ".") {
+		next, ok := l.field(schema, seg)
+		if !ok {
+			if l.open(schema) {
+				return nil, false
+			}
+			l.report("unknown-key", directive, "unknown key %q in %q", seg, path)
+			return nil, false
+		}
+		schema = next
+	}
+	return schema, true
+}
+
+// field returns the schema of one key of an object, map, or
+// discriminated union schema.
+func (l *templateLinter) field(schema any, key string) (any, bool) {
+	m, ok := l.deref(schema).(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	if values, ok := m["values"]; ok {
+		return values, true
+	}
+	if tag, _ := m["discriminator"].(string); tag != "" {
+		if key == tag {
+			return map[string]any{"type": "string"}, true
+		}
+		mapping, _ := m["mapping"].(map[string]any)
+		for _, name := range sortedKeys(mapping) {
+			if s, ok := l.field(mapping[name], key); ok {
+				return s, true
+			}
+		}
+		return nil, false
+	}
+	if props := l.properties(m); props != nil {
+		s, ok := props[key]
+		return s, ok
+	}
+	return nil, false
+}
+
+// properties merges the required and optional properties of an object
+// schema; optional ones are made nullable, as they may be absent.
+func (l *templateLinter) properties(schema any) map[string]any {
+	m, ok := l.deref(schema).(map[string]any)
+	if !ok {
+		return nil
+	}
+	props, _ := m["properties"].(map[string]any)
+	optional, _ := m["optionalProperties"].(map[string]any)
+	if props == nil && optional == nil {
+		return nil
+	}
+	out := make(map[string]any, len(props)+len(optional))
+	for k, v := range optional {
+		out[k] = withNullable(v)
+	}
+	for k, v := range props {
+		out[k] = v
+	}
+	return out
+}
+
+func withNullable(schema any) any {
+	m, ok := schema.(map[string]any)
+	if !ok {
+		return schema
+	}
+	out := make(map[string]any, len(m)+1)
+	for k, v := range m {
+		out[k] = v
+	}
+	out["nullable"] = true
+	return out
+}
+
+// open reports whether a schema accepts any key: the empty form or an
+// unresolvable ref.
+func (l *templateLinter) open(schema any) bool {
+	m, ok := l.deref(schema).(map[string]any)
+	return !ok || schemaForm(m) == "empty" || schemaForm(m) == "ref"
+}
+
+// deref follows refs into definitions, carrying nullability over.
+func (l *templateLinter) deref(schema any) any {
+	for range 32 {
+		m, ok := schema.(map[string]any)
+		if !ok {
+			return schema
+		}
+		ref, _ := m["ref"].(string)
+		def, ok := l.defs[ref]
+		if ref == "" || !ok {
+			return schema
+		}
+		if nullable, _ := m["nullable"].(bool); nullable {
+			def = withNullable(def)
+		}
+		schema = def
+	}
+	return schema
+}
+
+// alwaysTruthy reports whether every value of a schema is truthy for the
+// engine: non-nullable objects, maps, and discriminated unions.
+func alwaysTruthy(schema any) bool {
+	m, ok := schema.(map[string]any)
+	if !ok {
+		return false
+	}
+	if nullable, _ := m["nullable"].(bool); nullable {
+		return false
+	}
+	switch schemaForm(m) {
+	case "properties", "values", "discriminator":
+		return true
+	}
+	return false
+}
+
+// matchValues returns the strings a match on schema can compare against,
+// or nil when they are not a closed set. Nullable values add "" (null
+// stringifies to the empty string).
+func matchValues(schema any) map[string]bool {
+	m, ok := schema.(map[string]any)
+	if !ok {
+		return nil
+	}
+	var values map[string]bool
+	switch {
+	case m["enum"] != nil:
+		values = stringSet(m["enum"])
+		if names, ok := m["enum"].([]string); ok {
+			for _, name := range names {
+				values[name] = true
+			}
+		}
+	case m["type"] == "boolean":
+		values = map[string]bool{"true": true, "false": true}
+	default:
+		return nil
+	}
+	if nullable, _ := m["nullable"].(bool); nullable {
+		values[""] = true
+	}
+	return values
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,84 @@
+/* src/server/core/go/lint_template_test.go */
+
+package seam
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func lintSchema(t *testing.T, raw string) any {
+	t.Helper()
+	var schema any
+	if err := json.Unmarshal([]byte(raw), &schema); err != nil {
+		t.Fatal(err)
+	}
+	return schema
+}
+
+func TestLintTemplate(t *testing.T) {
+	schema := lintSchema(t, `{
+		"properties": {
+			"user": {"properties": {
+				"name": {"type": "string"},
+				"role": {"enum": ["admin", "member"]},
+				"admin": {"type": "boolean"},
+				"profile": {"ref": "profile"}
+			}, "optionalProperties": {"team": {"ref": "profile"}}},
+			"posts": {"elements": {"properties": {"title": {"type": "string"}, "tags": {"elements": {"type": "string"}}}}},
+			"extra": {}
+		},
+		"definitions": {"profile": {"properties": {"bio": {"type": "string"}}}}
+	}`)
+	tmpl := `<h1><!--seam:user.name--></h1><p><!--seam:name--></p><a><!--seam:user.profile.bio:attr:title--></a>
+<!--seam:if:user.profile-->yes<!--seam:else-->never<!--seam:endif:user.profile-->
+<!--seam:if:user.team-->team<!--seam:else-->none<!--seam:endif:user.team-->
+<!--seam:match:user.role--><!--seam:when:admin-->A<!--seam:when:owner-->O<!--seam:endmatch-->
+<!--seam:match:user.admin--><!--seam:when:true-->T<!--seam:when:yes-->Y<!--seam:endmatch-->
+<!--seam:each:posts--><!--seam:$.title--><!--seam:$.body--><!--seam:each:$.tags--><!--seam:$--><!--seam:$$.title--><!--seam:endeach--><!--seam:endeach-->
+<!--seam:each:user.name--><!--seam:endeach-->
+<!--seam:user.missing--><!--seam:extra.anything--><!--seam:_i18n.locale--><!--seam:outlet--><!--seam:nope:html-->
+<!--seam:if:user.admin-->`
+
+	got := LintTemplate(tmpl, schema)
+	want := []TemplateIssue{
+		{"dead-branch", "if:user.profile", "else branch never renders: user.profile is always truthy"},
+		{"dead-branch", "match:user.role", `arm "owner" never matches: user.role is one of admin, member`},
+		{"dead-branch", "match:user.admin", `arm "yes" never matches: user.admin is one of false, true`},
+		{"unknown-key", "$.body", `unknown key "body" in "$.body"`},
+		{"not-iterable", "each:user.name", "user.name is not a list"},
+		{"unknown-key", "user.missing", `unknown key "missing" in "user.missing"`},
+		{"unknown-key", "nope:html", `unknown key "nope"`},
+		{"unbalanced", "if:user.admin", "if block is never closed"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("issues:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestLintTemplateUnbalanced(t *testing.T) {
+	got := LintTemplate(`<!--seam:$.x--><!--seam:else--><!--seam:if:a--><!--seam:endif:b--><!--seam:endeach--><!--seam:when:x-->`, map[string]any{})
+	want := []TemplateIssue{
+		{"unknown-key", "$.x", "$ used outside an each block"},
+		{"unbalanced", "else", "else outside an if block"},
+		{"unbalanced", "endif:b", "endif does not close a matching if block"},
+		{"unbalanced", "endeach", "endeach outside an each block"},
+		{"unbalanced", "when:x", "when outside a match block"},
+		{"unbalanced", "if:a", "if block is never closed"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("issues:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestLintTemplateGoSchema(t *testing.T) {
+	type user struct {
+		Name   string `json:"name"`
+		Status string `json:"status"`
+	}
+	schema := map[string]any{"properties": map[string]any{"user": SchemaOf[user]()}}
+	if got := LintTemplate(`<!--seam:user.name--><!--seam:user.status--><!--seam:user.email-->`, schema); len(got) != 1 || got[0].Marker != "user.email" {
+		t.Fatalf("issues: %+v", got)
+	}
+}
@@ -0,0 +1,154 @@
+/* src/server/core/go/load_shedding.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// ConnectionLimits caps concurrent long-lived connections. A refused
+// subscription gets an UNAVAILABLE error event (an HTTP 503 envelope for
+// WebSockets) carrying pollIntervalMs, so clients can degrade to polling
+// GET /_seam/procedure/{name}/poll instead of reconnecting in a loop.
+// Zero limits are unlimited.
+type ConnectionLimits struct {
+	MaxSSE        int           // open SSE subscriptions and streams
+	MaxWebSockets int           // open channel and WebSocketRPC sockets
+	PollInterval  time.Duration // polling interval suggested to refused clients (default 5s)
+}
+
+func (l ConnectionLimits) pollInterval() time.Duration {
+	if l.PollInterval > 0 {
+		return l.PollInterval
+	}
+	return 5 * time.Second
+}
+
+// connCounter counts open connections of one kind against a limit.
+type connCounter struct{ open atomic.Int64 }
+
+// acquire reserves a connection; ok is false when limit (> 0) is reached.
+func (c *connCounter) acquire(limit int) (release func(), ok bool) {
+	if limit <= 0 {
+		return func() {}, true
+	}
+	if c.open.Add(1) > int64(limit) {
+		c.open.Add(-1)
+		return nil, false
+	}
+	return func() { c.open.Add(-1) }, true
+}
+
+// sheddingError refuses a connection over the limit. With poll set the
+// error suggests polling at the configured interval.
+func (s *appState) sheddingError(kind string, poll bool) *Error {
+	e := NewError("UNAVAILABLE", fmt.Sprintf("Too many open %s connections", kind), http.StatusServiceUnavailable)
+	e.Transient = true
+	e.RetryAfter = s.reconnectDelay()
+	if poll {
+		e.PollInterval = s.opts.ConnectionLimits.pollInterval()
+	}
+	return e
+}
+
+// acquireSSE reserves an SSE connection, writing the shedding error event
+// when the limit is reached.
+func (s *appState) acquireSSE(w http.ResponseWriter, poll bool) (func(), bool) {
+	release, ok := s.sseConns.acquire(s.opts.ConnectionLimits.MaxSSE)
+	if !ok {
+		writeSSEError(w, s.sheddingError("SSE", poll))
+	}
+	return release, ok
+}
+
+// acquireWebSocket reserves a WebSocket before the upgrade, answering 503
+// when the limit is reached.
+func (s *appState) acquireWebSocket(w http.ResponseWriter) (func(), bool) {
+	release, ok := s.wsConns.acquire(s.opts.ConnectionLimits.MaxWebSockets)
+	if !ok {
+		writeError(w, http.StatusServiceUnavailable, s.sheddingError("WebSocket", true))
+	}
+	return release, ok
+}
+
+// handlePoll answers GET /_seam/procedure/{name}/poll with the latest
+// value of the subscription: pollers of the same subscription, input,
+// caller, and context fields share one running subscription (see
+// pollWatch), so a poll reads its current value instead of starting the
+// subscription again. The first poll waits up to the RPC timeout for a
+// value; data is null when nothing arrives in time. Polls pass the same
+// checks as SSE subscriptions (signed URLs, IP filters, RateLimits) plus
+// the tuned rate limit. meta.pollIntervalMs suggests when to poll again.
+func (s *appState) handlePoll(w http.ResponseWriter, r *http.Request) {
+	if s.shuttingDown() {
+		writeError(w, http.StatusServiceUnavailable, s.unavailableError())
+		return
+	}
+	r, ok := s.checkSignedURL(w, r)
+	if !ok {
+		return
+	}
+	name := r.PathValue("name")
+	sub, ok := s.subs[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, NotFoundError(fmt.Sprintf("Subscription '%s' not found", name)))
+		return
+	}
+	rawInput, inputErr := s.subscriptionInput(r, name)
+	if inputErr != nil {
+		writeError(w, errorHTTPStatus(inputErr), inputErr)
+		return
+	}
+
+	ctx := s.requestContext(r)
+	var fields map[string]any
+	if len(s.contextConfigs) > 0 && len(sub.ContextKeys) > 0 {
+		fields = resolveContextForProc(extractRawContext(r, s.contextConfigs), sub.ContextKeys)
+		ctx = injectContext(ctx, fields)
+	}
+	ctx = injectState(ctx, s.appState)
+	if rateErr := s.opts.Tuning.checkRate(ctx); rateErr != nil {
+		writeError(w, errorHTTPStatus(rateErr), rateErr)
+		return
+	}
+	if rateErr := s.opts.RateLimits.check(ctx, "subscription"); rateErr != nil {
+		writeError(w, errorHTTPStatus(rateErr), rateErr)
+		return
+	}
+
+	pw, watchErr := s.pollWatch(ctx, sub, fields, rawInput)
+	if watchErr != nil {
+		writeError(w, errorHTTPStatus(watchErr), watchErr)
+		return
+	}
+	wait := r.Context()
+	if timeout := s.opts.Tuning.rpcTimeoutOr(s.opts.RPCTimeout); timeout > 0 {
+		var cancel context.CancelFunc
+		wait, cancel = context.WithTimeout(wait, timeout)
+		defer cancel()
+	}
+	select {
+	case <-pw.ready:
+	case <-wait.Done():
+	}
+	data, evErr := pw.latest()
+	if evErr != nil {
+		writeError(w, errorHTTPStatus(evErr), evErr)
+		return
+	}
+	if data == nil {
+		data = json.RawMessage("null")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = writeJSON(w, map[string]any{
+		"ok":   true,
+		"data": data,
+		"meta": resultMeta{PollIntervalMs: s.opts.ConnectionLimits.pollInterval().Milliseconds()},
+	})
+}
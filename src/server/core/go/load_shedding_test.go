@@ -0,0 +1,201 @@
+/* src/server/core/go/load_shedding_test.go */
+
+package seam
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// pollRouter has "counter", which emits its current value and then
+// waits, and "quiet", which never emits.
+func pollRouter() *Router {
+	return NewRouter().
+		Subscription(Subscribe("counter", func(ctx context.Context, _ struct{}) (<-chan int, error) {
+			ch := make(chan int, 1)
+			ch <- 42
+			go func() {
+				<-ctx.Done()
+				close(ch)
+			}()
+			return ch, nil
+		})).
+		Subscription(Subscribe("quiet", func(ctx context.Context, _ struct{}) (<-chan int, error) {
+			ch := make(chan int)
+			go func() {
+				<-ctx.Done()
+				close(ch)
+			}()
+			return ch, nil
+		}))
+}
+
+func TestSSELimitSuggestsPolling(t *testing.T) {
+	srv := httptest.NewServer(pollRouter().Handler(HandlerOptions{
+		ConnectionLimits: ConnectionLimits{MaxSSE: 1, PollInterval: 3 * time.Second},
+	}))
+	defer srv.Close()
+
+	first, err := http.Get(srv.URL + "/_seam/procedure/counter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// wait until the first stream is established
+	if _, err := bufio.NewReader(first.Body).ReadString('\n'); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := http.Get(srv.URL + "/_seam/procedure/counter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	for _, want := range []string{"event: error\n", `"code":"UNAVAILABLE"`, `"pollIntervalMs":3000`, `"transient":true`} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("refused stream %q missing %s", body, want)
+		}
+	}
+
+	first.Body.Close()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		resp, err := http.Get(srv.URL + "/_seam/procedure/counter")
+		if err != nil {
+			t.Fatal(err)
+		}
+		line, _ := bufio.NewReader(resp.Body).ReadString('\n')
+		resp.Body.Close()
+		if line == ": heartbeat\n" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("slot not released: %q", line)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWebSocketLimit(t *testing.T) {
+	srv := httptest.NewServer(pollRouter().Handler(HandlerOptions{
+		WebSocketRPC:     true,
+		ConnectionLimits: ConnectionLimits{MaxWebSockets: 1},
+	}))
+	defer srv.Close()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/_seam/ws"
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	if err == nil || resp == nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("second socket: %v %v", err, resp)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), `"pollIntervalMs":5000`) {
+		t.Errorf("refusal body %s", body)
+	}
+}
+
+func TestPollEndpoint(t *testing.T) {
+	h := pollRouter().Handler(HandlerOptions{RPCTimeout: 20 * time.Millisecond})
+	get := func(path string) (int, string) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w.Code, strings.TrimSpace(w.Body.String())
+	}
+
+	if status, body := get("/_seam/procedure/counter/poll"); status != 200 || body != `{"data":42,"meta":{"pollIntervalMs":5000},"ok":true}` {
+		t.Errorf("counter: %d %s", status, body)
+	}
+	if status, body := get("/_seam/procedure/quiet/poll"); status != 200 || body != `{"data":null,"meta":{"pollIntervalMs":5000},"ok":true}` {
+		t.Errorf("quiet: %d %s", status, body)
+	}
+	if status, _ := get("/_seam/procedure/missing/poll"); status != 404 {
+		t.Errorf("missing: %d", status)
+	}
+	if status, _ := get("/_seam/procedure/counter/poll?input=%5B"); status != 400 {
+		t.Errorf("bad input: %d", status)
+	}
+}
+
+func TestPollSharesOneSubscriptionAndReturnsLatest(t *testing.T) {
+	var starts atomic.Int32
+	values := make(chan map[string]any)
+	router := NewRouter().Subscription(SubscribeDeltas("board", func(ctx context.Context, _ struct{}) (<-chan map[string]any, error) {
+		starts.Add(1)
+		ch := make(chan map[string]any)
+		go func() {
+			defer close(ch)
+			for {
+				select {
+				case v := <-values:
+					ch <- v
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+		return ch, nil
+	}))
+	h := router.Handler(HandlerOptions{
+		RPCTimeout: time.Second,
+		RateLimits: &RateLimits{Anonymous: ClassRates{Subscription: RateLimit{Calls: 1000, Burst: 1000}}},
+	})
+	get := func() (int, string) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/_seam/procedure/board/poll", nil))
+		return w.Code, strings.TrimSpace(w.Body.String())
+	}
+
+	done := make(chan string)
+	go func() { _, body := get(); done <- body }()
+	values <- map[string]any{"a": 1, "b": "x"}
+	if body := <-done; !strings.Contains(body, `"data":{"a":1,"b":"x"}`) {
+		t.Fatalf("first poll: %s", body)
+	}
+	// a delta is folded into the full state; the next poll sees it
+	values <- map[string]any{"a": 2, "b": "x"}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		_, body := get()
+		if strings.Contains(body, `"data":{"a":2,"b":"x"}`) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("second poll: %s", body)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if n := starts.Load(); n != 1 {
+		t.Fatalf("subscription started %d times, want 1", n)
+	}
+}
+
+func TestPollHonorsRateLimits(t *testing.T) {
+	h := pollRouter().Handler(HandlerOptions{
+		RPCTimeout: 20 * time.Millisecond,
+		RateLimits: &RateLimits{Anonymous: ClassRates{Subscription: RateLimit{Calls: 1}}},
+	})
+	codes := make([]int, 0, 2)
+	for range 2 {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/_seam/procedure/counter/poll", nil))
+		codes = append(codes, w.Code)
+	}
+	if codes[0] != http.StatusOK || codes[1] != http.StatusTooManyRequests {
+		t.Fatalf("poll statuses %v, want [200 429]", codes)
+	}
+}
@@ -0,0 +1,126 @@
+/* src/server/core/go/loader_binding.go */
+
+package seam
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// LoaderInput derives a loader's procedure input from route params. Build
+// one with MapParams or InputFrom.
+type LoaderInput struct {
+	fn     func(params map[string]string) any
+	typ    reflect.Type      // InputFrom: the typed input, checked against the procedure
+	fields map[string]string // MapParams: input field -> route param, checked against the schema
+}
+
+// MapParams builds an input object from route params, keyed by input
+// field: MapParams(map[string]string{"id": "userId"}) sends {"id": <:userId>}.
+// Fields are checked against the procedure's input schema at handler build.
+func MapParams(fields map[string]string) LoaderInput {
+	return LoaderInput{
+		fields: fields,
+		fn: func(params map[string]string) any {
+			obj := make(map[string]any, len(fields))
+			for field, param := range fields {
+				obj[field] = params[param]
+			}
+			return obj
+		},
+	}
+}
+
+// InputFrom builds a typed input from route params. In must match the
+// input type of the bound Query/Command, checked at handler build.
+func InputFrom[In any](fn func(params map[string]string) In) LoaderInput {
+	return LoaderInput{
+		typ: reflect.TypeFor[In](),
+		fn:  func(params map[string]string) any { return fn(params) },
+	}
+}
+
+// Load binds dataKey to a procedure handle returned by Query or Command,
+// so a misspelled procedure is a compile error rather than a runtime 500.
+// The procedure's final name (after Namespace/WithVersion) is resolved
+// when the handler is built; Handler panics if it is not registered.
+func Load(dataKey string, proc *ProcedureDef, input LoaderInput) LoaderDef {
+	return LoaderDef{DataKey: dataKey, Procedure: proc.Name, InputFn: input.fn, proc: proc, input: input}
+}
+
+// Load adds a page-level loader bound to a procedure handle (see Load).
+func (p *PageDef) Load(dataKey string, proc *ProcedureDef, input LoaderInput) *PageDef {
+	p.Loaders = append(p.Loaders, Load(dataKey, proc, input))
+	p.PageLoaderKeys = append(p.PageLoaderKeys, dataKey)
+	return p
+}
+
+// resolveBoundLoaders returns copies of pages whose handle-bound loaders
+// carry the procedure's current name; the input slice is left untouched.
+func resolveBoundLoaders(pages []PageDef) []PageDef {
+	out := make([]PageDef, len(pages))
+	for i, page := range pages {
+		copied := false
+		for j, ld := range page.Loaders {
+			if ld.proc == nil || ld.Procedure == ld.proc.Name {
+				continue
+			}
+			if !copied {
+				page.Loaders = append([]LoaderDef{}, page.Loaders...)
+				copied = true
+			}
+			page.Loaders[j].Procedure = ld.proc.Name
+		}
+		out[i] = page
+	}
+	return out
+}
+
+// checkBoundLoaders panics when a handle-bound loader names a procedure
+// that is not registered or passes an input that does not fit it.
+func checkBoundLoaders(pages []PageDef, handlers map[string]*ProcedureDef) {
+	for _, page := range pages {
+		for _, ld := range page.Loaders {
+			if ld.proc == nil {
+				continue
+			}
+			proc, ok := handlers[ld.Procedure]
+			if !ok {
+				panic(fmt.Sprintf("loader %q of page %q uses procedure %q, which is not registered", ld.DataKey, page.Route, ld.Procedure))
+			}
+			if ld.input.typ != nil && ld.proc.inputType != nil && ld.input.typ != ld.proc.inputType {
+				panic(fmt.Sprintf("loader %q of page %q passes %s to procedure %q, which takes %s",
+					ld.DataKey, page.Route, ld.input.typ, ld.Procedure, ld.proc.inputType))
+			}
+			if unknown := unknownInputFields(ld.input.fields, proc.InputSchema); len(unknown) > 0 {
+				panic(fmt.Sprintf("loader %q of page %q maps unknown input fields %v of procedure %q",
+					ld.DataKey, page.Route, unknown, ld.Procedure))
+			}
+		}
+	}
+}
+
+// unknownInputFields lists mapped fields absent from an input schema that
+// describes properties.
+func unknownInputFields(fields map[string]string, schema any) []string {
+	m, ok := schema.(map[string]any)
+	if !ok {
+		return nil
+	}
+	props, _ := m["properties"].(map[string]any)
+	optional, _ := m["optionalProperties"].(map[string]any)
+	if props == nil && optional == nil {
+		return nil
+	}
+	var unknown []string
+	for field := range fields {
+		_, inProps := props[field]
+		_, inOptional := optional[field]
+		if !inProps && !inOptional {
+			unknown = append(unknown, field)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
@@ -0,0 +1,80 @@
+/* src/server/core/go/loader_binding_test.go */
+
+package seam
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type getUserInput struct {
+	ID string `json:"id"`
+}
+
+type bindingUser struct {
+	Name string `json:"name"`
+}
+
+func newGetUser() *ProcedureDef {
+	return Query("getUser", func(_ context.Context, in getUserInput) (bindingUser, error) {
+		return bindingUser{Name: "user-" + in.ID}, nil
+	})
+}
+
+func TestLoadBindsProcedureHandle(t *testing.T) {
+	getUser := newGetUser()
+	page := &PageDef{Route: "/u/:userId", Template: "<html><body><!--seam:user.name--></body></html>"}
+	page.Load("user", getUser, MapParams(map[string]string{"id": "userId"}))
+	// Namespacing after binding is picked up when the handler is built
+	router := NewRouter().Namespace("accounts", getUser).Page(page)
+
+	if got := router.Pages()[0].Loaders[0].Procedure; got != "accounts.getUser" {
+		t.Fatalf("introspection procedure = %q", got)
+	}
+	w := httptest.NewRecorder()
+	router.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/_seam/page/u/42", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "user-42") {
+		t.Fatalf("got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestLoadInputFromTyped(t *testing.T) {
+	getUser := newGetUser()
+	page := &PageDef{Route: "/u/:userId", Template: "<html><body><!--seam:user.name--></body></html>"}
+	page.Load("user", getUser, InputFrom(func(p map[string]string) getUserInput { return getUserInput{ID: p["userId"]} }))
+	w := httptest.NewRecorder()
+	NewRouter().Procedure(getUser).Page(page).Handler().ServeHTTP(w, httptest.NewRequest("GET", "/_seam/page/u/7", nil))
+	if !strings.Contains(w.Body.String(), "user-7") {
+		t.Fatalf("got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestLoadPanicsOnMisbinding(t *testing.T) {
+	cases := map[string]func(*PageDef, *ProcedureDef){
+		"not registered": func(p *PageDef, proc *ProcedureDef) {
+			p.Load("user", Query("other", func(context.Context, struct{}) (int, error) { return 0, nil }), MapParams(nil))
+		},
+		"wrong input type": func(p *PageDef, proc *ProcedureDef) {
+			p.Load("user", proc, InputFrom(func(map[string]string) bindingUser { return bindingUser{} }))
+		},
+		"unknown field": func(p *PageDef, proc *ProcedureDef) {
+			p.Load("user", proc, MapParams(map[string]string{"userID": "userId"}))
+		},
+	}
+	for name, bind := range cases {
+		t.Run(name, func(t *testing.T) {
+			getUser := newGetUser()
+			page := &PageDef{Route: "/u/:userId", Template: "<html></html>"}
+			bind(page, getUser)
+			defer func() {
+				if recover() == nil {
+					t.Fatal("expected Handler to panic")
+				}
+			}()
+			NewRouter().Procedure(getUser).Page(page).Handler()
+		})
+	}
+}
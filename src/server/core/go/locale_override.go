@@ -0,0 +1,49 @@
+/* src/server/core/go/locale_override.go */
+
+package seam
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+)
+
+type localeKeyType struct{}
+
+var localeKey = localeKeyType{}
+
+// LocaleOf returns the final locale of the page request being rendered
+// (after HandlerOptions.OnLocaleResolved), or "" outside page loaders and
+// when i18n is off.
+func LocaleOf(ctx context.Context) string {
+	if v, ok := ctx.Value(localeKey).(string); ok {
+		return v
+	}
+	return ""
+}
+
+// overrideLocale applies HandlerOptions.OnLocaleResolved to the locale
+// chosen by the resolve strategies. Overrides naming a locale outside the
+// configured set are ignored and logged.
+func (s *appState) overrideLocale(r *http.Request, resolved string) string {
+	if s.opts.OnLocaleResolved == nil {
+		return resolved
+	}
+	locale := s.opts.OnLocaleResolved(r, resolved)
+	if locale == resolved {
+		return resolved
+	}
+	if !s.localeSet[locale] {
+		logf(slog.LevelWarn, "OnLocaleResolved returned unknown locale %q for %s, keeping %q\n", locale, r.URL.Path, resolved)
+		return resolved
+	}
+	return locale
+}
+
+// withLocale records the final locale in ctx and the access log entry.
+func withLocale(ctx context.Context, locale string) context.Context {
+	if entry, ok := ctx.Value(accessEntryKey).(*accessEntry); ok {
+		entry.locale = locale
+	}
+	return context.WithValue(ctx, localeKey, locale)
+}
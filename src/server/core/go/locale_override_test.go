@@ -0,0 +1,69 @@
+/* src/server/core/go/locale_override_test.go */
+
+package seam
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOnLocaleResolvedOverride(t *testing.T) {
+	var loaderLocale string
+	router := NewRouter().
+		Procedure(Query("getTitle", func(ctx context.Context, _ struct{}) (string, error) {
+			loaderLocale = LocaleOf(ctx)
+			return "Hello", nil
+		})).
+		Page(&PageDef{
+			Route:           "/about",
+			Template:        "<html><body>en:<!--seam:title--></body></html>",
+			LocaleTemplates: map[string]string{"de": "<html><body>de:<!--seam:title--></body></html>"},
+			Loaders:         []LoaderDef{{DataKey: "title", Procedure: "getTitle", InputFn: func(map[string]string) any { return map[string]any{} }}},
+		}).
+		I18nConfig(&I18nConfig{Locales: []string{"en", "de"}, Default: "en", Messages: map[string]map[string]json.RawMessage{}}).
+		ResolveStrategies(FromAcceptLanguage())
+
+	var logBuf bytes.Buffer
+	h := router.Handler(HandlerOptions{
+		AccessLog: &AccessLog{Writer: &logBuf, Format: AccessLogJSON},
+		OnLocaleResolved: func(r *http.Request, resolved string) string {
+			if strings.Contains(r.UserAgent(), "Googlebot") {
+				return "en"
+			}
+			if r.Header.Get("X-Force") != "" {
+				return r.Header.Get("X-Force")
+			}
+			return resolved
+		},
+	})
+	get := func(header map[string]string) string {
+		req := httptest.NewRequest("GET", "/_seam/page/about", nil)
+		req.Header.Set("Accept-Language", "de")
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	if body := get(nil); !strings.Contains(body, "de:Hello") || loaderLocale != "de" {
+		t.Fatalf("expected resolved de, got %q (loader %q)", body, loaderLocale)
+	}
+	logBuf.Reset()
+	if body := get(map[string]string{"User-Agent": "Googlebot/2.1"}); !strings.Contains(body, "en:Hello") || loaderLocale != "en" {
+		t.Fatalf("expected forced en, got %q (loader %q)", body, loaderLocale)
+	}
+	if !strings.Contains(logBuf.String(), `"locale":"en"`) {
+		t.Fatalf("expected locale in access log, got %s", logBuf.String())
+	}
+	// Unknown override falls back to the resolved locale
+	if body := get(map[string]string{"X-Force": "fr"}); !strings.Contains(body, "de:Hello") {
+		t.Fatalf("expected fallback to de, got %q", body)
+	}
+}
@@ -0,0 +1,168 @@
+/* src/server/core/go/lock.go */
+
+package seam
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// LockProvider grants named exclusive locks. Procedures marked WithLock
+// hold their lock for the duration of the handler.
+type LockProvider interface {
+	// TryLock acquires name without waiting, reporting false when another
+	// holder has it. release must be called exactly once.
+	TryLock(ctx context.Context, name string) (release func(), ok bool, err error)
+}
+
+// WithLock runs the procedure under the named lock; a call arriving while
+// the lock is held fails with CONFLICT (409) instead of running
+// concurrently, whichever transport it came over (HTTP, batch, or
+// socket). Use a shared provider (RedisLocks) in
+// HandlerOptions.Locks to exclude other replicas too.
+func WithLock(name string) ProcedureOption {
+	return func(p *ProcedureDef) {
+		p.Lock = name
+	}
+}
+
+// acquireLock takes the procedure's lock, returning its release function.
+func (s *appState) acquireLock(ctx context.Context, proc *ProcedureDef) (func(), *Error) {
+	release, ok, err := s.locks.TryLock(ctx, proc.Lock)
+	if err != nil {
+		return nil, InternalError(fmt.Sprintf("Lock '%s': %s", proc.Lock, err))
+	}
+	if !ok {
+		return nil, NewError("CONFLICT", fmt.Sprintf("Procedure '%s' is already running (lock '%s')", proc.Name, proc.Lock), http.StatusConflict)
+	}
+	return release, nil
+}
+
+type memoryLocks struct {
+	mu   sync.Mutex
+	held map[string]bool
+}
+
+// MemoryLocks returns an in-process provider. It only excludes calls
+// within one process; it is the default when HandlerOptions.Locks is nil.
+func MemoryLocks() LockProvider {
+	return &memoryLocks{held: make(map[string]bool)}
+}
+
+func (m *memoryLocks) TryLock(_ context.Context, name string) (func(), bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.held[name] {
+		return nil, false, nil
+	}
+	m.held[name] = true
+	return func() {
+		m.mu.Lock()
+		delete(m.held, name)
+		m.mu.Unlock()
+	}, true, nil
+}
+
+// RedisLockClient is the subset of a Redis client RedisLocks needs. A
+// go-redis client adapts in a few lines:
+//
+//	func (c adapter) SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error) {
+//		return c.Client.SetNX(ctx, key, value, ttl).Result()
+//	}
+//	func (c adapter) Eval(ctx context.Context, script string, keys []string, args ...any) (any, error) {
+//		return c.Client.Eval(ctx, script, keys, args...).Result()
+//	}
+type RedisLockClient interface {
+	SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error)
+	Eval(ctx context.Context, script string, keys []string, args ...any) (any, error)
+}
+
+// RedisLockOptions tunes RedisLocks. Zero values use the defaults.
+type RedisLockOptions struct {
+	Prefix string        // key prefix (default "seam:lock:")
+	TTL    time.Duration // lock expiry, refreshed every TTL/3 while held (default 30s)
+}
+
+// Compare-and-act scripts: only the holder's token may extend or delete.
+const (
+	redisUnlockScript  = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("del", KEYS[1]) else return 0 end`
+	redisRefreshScript = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("pexpire", KEYS[1], ARGV[2]) else return 0 end`
+)
+
+type redisLocks struct {
+	client RedisLockClient
+	prefix string
+	ttl    time.Duration
+}
+
+// RedisLocks returns a provider sharing locks across replicas through
+// Redis (SET NX with a per-holder token). Held locks are refreshed in the
+// background so long-running procedures keep them; a crashed replica's
+// lock expires after TTL.
+func RedisLocks(client RedisLockClient, opts RedisLockOptions) LockProvider {
+	if opts.Prefix == "" {
+		opts.Prefix = "seam:lock:"
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = 30 * time.Second
+	}
+	return &redisLocks{client: client, prefix: opts.Prefix, ttl: opts.TTL}
+}
+
+func (l *redisLocks) TryLock(ctx context.Context, name string) (func(), bool, error) {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	token := hex.EncodeToString(b[:])
+	key := l.prefix + name
+
+	ok, err := l.client.SetNX(ctx, key, token, l.ttl)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(l.ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				res, err := l.client.Eval(context.Background(), redisRefreshScript, []string{key}, token, l.ttl.Milliseconds())
+				if err != nil || !redisTruthy(res) {
+					logf(slog.LevelWarn, "lock '%s' could not be refreshed; it may be taken over\n", name)
+					return
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(stop)
+			<-done
+			_, _ = l.client.Eval(context.Background(), redisUnlockScript, []string{key}, token)
+		})
+	}, true, nil
+}
+
+// redisTruthy reports whether a script reply is a non-zero integer.
+func redisTruthy(v any) bool {
+	switch n := v.(type) {
+	case int64:
+		return n != 0
+	case int:
+		return n != 0
+	}
+	return false
+}
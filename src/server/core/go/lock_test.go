@@ -0,0 +1,122 @@
+/* src/server/core/go/lock_test.go */
+
+package seam
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithLockRejectsConcurrentCall(t *testing.T) {
+	started := make(chan struct{})
+	finish := make(chan struct{})
+	router := NewRouter().Procedure(Command("recompute", func(context.Context, struct{}) (string, error) {
+		started <- struct{}{}
+		<-finish
+		return "done", nil
+	}, WithLock("nightly")))
+	h := router.Handler(HandlerOptions{})
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if code, body := rpcBody(h, "/_seam/procedure/recompute", `{}`, nil); code != http.StatusOK {
+			t.Errorf("first call: %d %s", code, body)
+		}
+	}()
+	<-started
+
+	code, body := rpcBody(h, "/_seam/procedure/recompute", `{}`, nil)
+	if code != http.StatusConflict || !strings.Contains(body, `"CONFLICT"`) {
+		t.Fatalf("concurrent call: %d %s", code, body)
+	}
+	close(finish)
+	wg.Wait()
+
+	// Released after the first call returns
+	go func() { <-started }()
+	if code, body := rpcBody(h, "/_seam/procedure/recompute", `{}`, nil); code != http.StatusOK {
+		t.Fatalf("call after release: %d %s", code, body)
+	}
+}
+
+// fakeRedis implements the SET NX and compare-and-act scripts RedisLocks uses.
+type fakeRedis struct {
+	mu   sync.Mutex
+	keys map[string]string
+}
+
+func (f *fakeRedis) SetNX(_ context.Context, key, value string, _ time.Duration) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.keys[key]; ok {
+		return false, nil
+	}
+	f.keys[key] = value
+	return true, nil
+}
+
+func (f *fakeRedis) Eval(_ context.Context, script string, keys []string, args ...any) (any, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.keys[keys[0]] != args[0] {
+		return int64(0), nil
+	}
+	if script == redisUnlockScript {
+		delete(f.keys, keys[0])
+	}
+	return int64(1), nil
+}
+
+// heldLocks is a LockProvider whose locks are always held elsewhere.
+type heldLocks struct{}
+
+func (heldLocks) TryLock(context.Context, string) (func(), bool, error) { return nil, false, nil }
+
+func TestWithLockOnEveryTransport(t *testing.T) {
+	ran := 0
+	h := opsRouter(Command("ops.recompute", func(context.Context, struct{}) (string, error) {
+		ran++
+		return "done", nil
+	}, WithLock("nightly"))).Handler(HandlerOptions{Locks: heldLocks{}, HeartbeatInterval: time.Minute})
+
+	for transport, code := range callTransports(t, h, "ops.recompute", `{}`, nil) {
+		if code != "CONFLICT" {
+			t.Errorf("%s: got %q, want CONFLICT", transport, code)
+		}
+	}
+	if ran != 0 {
+		t.Fatalf("handler ran %d times under a held lock", ran)
+	}
+}
+
+func TestRedisLocksSharedAcrossProviders(t *testing.T) {
+	client := &fakeRedis{keys: make(map[string]string)}
+	replicaA := RedisLocks(client, RedisLockOptions{TTL: 30 * time.Millisecond})
+	replicaB := RedisLocks(client, RedisLockOptions{TTL: 30 * time.Millisecond})
+
+	release, ok, err := replicaA.TryLock(context.Background(), "nightly")
+	if err != nil || !ok {
+		t.Fatalf("replica A: ok=%v err=%v", ok, err)
+	}
+	// Outlives the TTL through background refreshes
+	time.Sleep(50 * time.Millisecond)
+	if _, ok, _ := replicaB.TryLock(context.Background(), "nightly"); ok {
+		t.Fatal("replica B acquired a held lock")
+	}
+	if _, held := client.keys["seam:lock:nightly"]; !held {
+		t.Fatal("lock key missing while held")
+	}
+
+	release()
+	releaseB, ok, _ := replicaB.TryLock(context.Background(), "nightly")
+	if !ok {
+		t.Fatal("replica B could not acquire a released lock")
+	}
+	releaseB()
+}
@@ -0,0 +1,23 @@
+/* src/server/core/go/logging.go */
+
+package seam
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"sync/atomic"
+)
+
+// logLevel is the level of the last handler built with a Tuning; seam's
+// own log lines below it are dropped. Without one everything is logged.
+var logLevel atomic.Pointer[slog.LevelVar]
+
+// logf writes one of seam's own log lines ("[seam] " + format) to stderr
+// unless level is below the tuned log level.
+func logf(level slog.Level, format string, args ...any) {
+	if v := logLevel.Load(); v != nil && level < v.Level() {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "[seam] "+format, args...)
+}
@@ -11,22 +11,33 @@ type manifestSchema struct {
 	Context           map[string]contextManifestEntry `json:"context,omitempty"`
 	Procedures        map[string]procedureEntry       `json:"procedures"`
 	Channels          map[string]channelMeta          `json:"channels,omitempty"`
+	Pages             map[string]pageManifestEntry    `json:"pages,omitempty"`
 	TransportDefaults map[string]any                  `json:"transportDefaults"`
 }
 
+type pageManifestEntry struct {
+	Annotations *Annotations `json:"annotations"`
+}
+
 type contextManifestEntry struct {
 	Extract string `json:"extract"`
 }
 
 type procedureEntry struct {
-	Kind        string   `json:"kind"`
-	Input       any      `json:"input"`
-	Output      any      `json:"output,omitempty"`
-	ChunkOutput any      `json:"chunkOutput,omitempty"`
-	Error       any      `json:"error,omitempty"`
-	Context     []string `json:"context,omitempty"`
-	Suppress    []string `json:"suppress,omitempty"`
-	Cache       any      `json:"cache,omitempty"`
+	Kind                 string               `json:"kind"`
+	Input                any                  `json:"input"`
+	Output               any                  `json:"output,omitempty"`
+	ChunkOutput          any                  `json:"chunkOutput,omitempty"`
+	Error                any                  `json:"error,omitempty"`
+	Invalidates          []InvalidateTarget   `json:"invalidates,omitempty"`
+	Context              []string             `json:"context,omitempty"`
+	Suppress             []string             `json:"suppress,omitempty"`
+	Cache                any                  `json:"cache,omitempty"`
+	Version              string               `json:"version,omitempty"`
+	Deprecated           *Deprecation         `json:"deprecated,omitempty"`
+	Deltas               bool                 `json:"deltas,omitempty"`
+	Annotations          *Annotations         `json:"annotations,omitempty"`
+	AdditionalProperties AdditionalProperties `json:"additionalProperties,omitempty"`
 }
 
 // --- manifest builder ---
@@ -43,7 +54,7 @@ func buildManifest(procedures []ProcedureDef, subscriptions []SubscriptionDef, s
 			Kind:   procType,
 			Input:  p.InputSchema,
 			Output: p.OutputSchema,
-			Error:  p.ErrorSchema,
+			Error:  withValidationDetails(p.ErrorSchema, p.InputSchema),
 		}
 		if len(p.ContextKeys) > 0 {
 			entry.Context = p.ContextKeys
@@ -54,6 +65,13 @@ func buildManifest(procedures []ProcedureDef, subscriptions []SubscriptionDef, s
 		if p.Cache != nil {
 			entry.Cache = p.Cache
 		}
+		_, entry.Version = splitVersion(p.Name)
+		entry.Deprecated = p.Deprecated
+		entry.Annotations = p.Annotations
+		entry.AdditionalProperties = p.AdditionalProperties
+		if procType == "command" && len(p.InvalidateTargets) > 0 {
+			entry.Invalidates = p.InvalidateTargets
+		}
 		procs[p.Name] = entry
 	}
 	for _, s := range subscriptions {
@@ -61,7 +79,8 @@ func buildManifest(procedures []ProcedureDef, subscriptions []SubscriptionDef, s
 			Kind:   "subscription",
 			Input:  s.InputSchema,
 			Output: s.OutputSchema,
-			Error:  s.ErrorSchema,
+			Error:  withValidationDetails(s.ErrorSchema, s.InputSchema),
+			Deltas: s.Deltas,
 		}
 		if len(s.ContextKeys) > 0 {
 			entry.Context = s.ContextKeys
@@ -76,7 +95,7 @@ func buildManifest(procedures []ProcedureDef, subscriptions []SubscriptionDef, s
 			Kind:        "stream",
 			Input:       st.InputSchema,
 			ChunkOutput: st.ChunkOutputSchema,
-			Error:       st.ErrorSchema,
+			Error:       withValidationDetails(st.ErrorSchema, st.InputSchema),
 		}
 		if len(st.ContextKeys) > 0 {
 			entry.Context = st.ContextKeys
@@ -91,7 +110,7 @@ func buildManifest(procedures []ProcedureDef, subscriptions []SubscriptionDef, s
 			Kind:   "upload",
 			Input:  u.InputSchema,
 			Output: u.OutputSchema,
-			Error:  u.ErrorSchema,
+			Error:  withValidationDetails(u.ErrorSchema, u.InputSchema),
 		}
 		if len(u.ContextKeys) > 0 {
 			entry.Context = u.ContextKeys
@@ -0,0 +1,332 @@
+/* src/server/core/go/manifest_diff.go */
+
+package seam
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ChangeSeverity classifies a manifest change by its impact on deployed clients.
+type ChangeSeverity string
+
+const (
+	ChangeBreaking ChangeSeverity = "breaking" // deployed clients may fail
+	ChangeAdditive ChangeSeverity = "additive" // safe for deployed clients
+)
+
+// ManifestChange describes one difference between two manifests.
+type ManifestChange struct {
+	Severity  ChangeSeverity `json:"severity"`
+	Procedure string         `json:"procedure"`
+	Path      string         `json:"path"` // e.g. "input/user/name"; empty for procedure-level changes
+	Message   string         `json:"message"`
+}
+
+func (c ManifestChange) String() string {
+	if c.Path == "" {
+		return fmt.Sprintf("[%s] %s: %s", c.Severity, c.Procedure, c.Message)
+	}
+	return fmt.Sprintf("[%s] %s %s: %s", c.Severity, c.Procedure, c.Path, c.Message)
+}
+
+// HasBreaking reports whether any change is breaking.
+func HasBreaking(changes []ManifestChange) bool {
+	for _, c := range changes {
+		if c.Severity == ChangeBreaking {
+			return true
+		}
+	}
+	return false
+}
+
+type diffManifest struct {
+	Procedures map[string]diffProcedure `json:"procedures"`
+}
+
+type diffProcedure struct {
+	Kind        string `json:"kind"`
+	Input       any    `json:"input"`
+	Output      any    `json:"output"`
+	ChunkOutput any    `json:"chunkOutput"`
+}
+
+// schemaDirection tells the comparator who produces values for a schema.
+// Input is produced by clients (server must stay permissive); output is
+// consumed by clients (server must not stop sending what they expect).
+type schemaDirection int
+
+const (
+	dirInput schemaDirection = iota
+	dirOutput
+)
+
+// DiffManifests compares two serialized manifests and classifies every change
+// as breaking or additive. Results are sorted by procedure and path.
+func DiffManifests(oldJSON, newJSON []byte) ([]ManifestChange, error) {
+	var oldM, newM diffManifest
+	if err := json.Unmarshal(oldJSON, &oldM); err != nil {
+		return nil, fmt.Errorf("parse old manifest: %w", err)
+	}
+	if err := json.Unmarshal(newJSON, &newM); err != nil {
+		return nil, fmt.Errorf("parse new manifest: %w", err)
+	}
+
+	d := &manifestDiffer{}
+	for name, oldP := range oldM.Procedures {
+		newP, ok := newM.Procedures[name]
+		if !ok {
+			d.add(ChangeBreaking, name, "", "procedure removed")
+			continue
+		}
+		if oldP.Kind != newP.Kind {
+			d.add(ChangeBreaking, name, "", fmt.Sprintf("kind changed from %s to %s", oldP.Kind, newP.Kind))
+			continue
+		}
+		d.procedure = name
+		d.compare(oldP.Input, newP.Input, []string{"input"}, dirInput)
+		d.compare(oldP.Output, newP.Output, []string{"output"}, dirOutput)
+		d.compare(oldP.ChunkOutput, newP.ChunkOutput, []string{"chunkOutput"}, dirOutput)
+	}
+	for name := range newM.Procedures {
+		if _, ok := oldM.Procedures[name]; !ok {
+			d.add(ChangeAdditive, name, "", "procedure added")
+		}
+	}
+
+	sort.SliceStable(d.changes, func(i, j int) bool {
+		if d.changes[i].Procedure != d.changes[j].Procedure {
+			return d.changes[i].Procedure < d.changes[j].Procedure
+		}
+		return d.changes[i].Path < d.changes[j].Path
+	})
+	return d.changes, nil
+}
+
+type manifestDiffer struct {
+	procedure string
+	changes   []ManifestChange
+}
+
+func (d *manifestDiffer) add(sev ChangeSeverity, proc, path, msg string) {
+	d.changes = append(d.changes, ManifestChange{Severity: sev, Procedure: proc, Path: path, Message: msg})
+}
+
+func (d *manifestDiffer) emit(sev ChangeSeverity, path []string, msg string) {
+	d.add(sev, d.procedure, strings.Join(path, "/"), msg)
+}
+
+func (d *manifestDiffer) compare(oldS, newS any, path []string, dir schemaDirection) {
+	oldMap, _ := oldS.(map[string]any)
+	newMap, _ := newS.(map[string]any)
+	if oldMap == nil && newMap == nil {
+		return
+	}
+
+	oldNullable, _ := oldMap["nullable"].(bool)
+	newNullable, _ := newMap["nullable"].(bool)
+	if oldNullable != newNullable {
+		// Input: dropping null acceptance breaks clients sending null.
+		// Output: starting to send null breaks clients not expecting it.
+		breaking := (dir == dirInput && oldNullable) || (dir == dirOutput && newNullable)
+		d.emit(severityOf(breaking), path, fmt.Sprintf("nullable changed from %v to %v", oldNullable, newNullable))
+	}
+
+	oldForm, newForm := schemaForm(oldMap), schemaForm(newMap)
+	if oldForm != newForm {
+		// Widening to the empty schema accepts anything on input
+		breaking := !(dir == dirInput && newForm == "empty")
+		d.emit(severityOf(breaking), path, fmt.Sprintf("schema form changed from %s to %s", oldForm, newForm))
+		return
+	}
+
+	switch oldForm {
+	case "type":
+		oldT, _ := oldMap["type"].(string)
+		newT, _ := newMap["type"].(string)
+		if oldT != newT {
+			d.emit(ChangeBreaking, path, fmt.Sprintf("type changed from %s to %s", oldT, newT))
+		}
+	case "enum":
+		d.compareEnum(oldMap, newMap, path, dir)
+	case "elements", "values":
+		d.compare(oldMap[oldForm], newMap[newForm], childPath(path, "[]"), dir)
+	case "properties":
+		d.compareProperties(oldMap, newMap, path, dir)
+	case "discriminator":
+		d.compareDiscriminator(oldMap, newMap, path, dir)
+	case "ref":
+		if !reflect.DeepEqual(oldMap, newMap) {
+			d.emit(ChangeBreaking, path, "referenced schema changed")
+		}
+	}
+}
+
+func (d *manifestDiffer) compareEnum(oldMap, newMap map[string]any, path []string, dir schemaDirection) {
+	oldVals := stringSet(oldMap["enum"])
+	newVals := stringSet(newMap["enum"])
+	for v := range oldVals {
+		if !newVals[v] {
+			d.emit(severityOf(dir == dirInput), path, fmt.Sprintf("enum value %q removed", v))
+		}
+	}
+	for v := range newVals {
+		if !oldVals[v] {
+			d.emit(severityOf(dir == dirOutput), path, fmt.Sprintf("enum value %q added", v))
+		}
+	}
+}
+
+func (d *manifestDiffer) compareProperties(oldMap, newMap map[string]any, path []string, dir schemaDirection) {
+	oldReq, _ := oldMap["properties"].(map[string]any)
+	newReq, _ := newMap["properties"].(map[string]any)
+	oldOpt, _ := oldMap["optionalProperties"].(map[string]any)
+	newOpt, _ := newMap["optionalProperties"].(map[string]any)
+
+	names := make(map[string]bool)
+	for _, m := range []map[string]any{oldReq, newReq, oldOpt, newOpt} {
+		for k := range m {
+			names[k] = true
+		}
+	}
+	sorted := make([]string, 0, len(names))
+	for k := range names {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	for _, name := range sorted {
+		fieldPath := childPath(path, name)
+		oldSchema, wasReq := oldReq[name]
+		if !wasReq {
+			oldSchema = oldOpt[name]
+		}
+		newSchema, isReq := newReq[name]
+		if !isReq {
+			newSchema = newOpt[name]
+		}
+		_, wasOpt := oldOpt[name]
+		_, isOpt := newOpt[name]
+		existed := wasReq || wasOpt
+		exists := isReq || isOpt
+
+		switch {
+		case !existed && isReq:
+			d.emit(severityOf(dir == dirInput), fieldPath, "required field added")
+		case !existed && isOpt:
+			d.emit(ChangeAdditive, fieldPath, "optional field added")
+		case existed && !exists:
+			// Input: strict validation rejects clients still sending the field.
+			// Output: clients reading the field get undefined.
+			if wasOpt && dir == dirOutput {
+				d.emit(ChangeBreaking, fieldPath, "optional field removed")
+			} else {
+				d.emit(ChangeBreaking, fieldPath, "field removed")
+			}
+		case wasOpt && isReq:
+			d.emit(severityOf(dir == dirInput), fieldPath, "field became required")
+			d.compare(oldSchema, newSchema, fieldPath, dir)
+		case wasReq && isOpt:
+			d.emit(severityOf(dir == dirOutput), fieldPath, "field became optional")
+			d.compare(oldSchema, newSchema, fieldPath, dir)
+		default:
+			d.compare(oldSchema, newSchema, fieldPath, dir)
+		}
+	}
+
+	oldExtra, _ := oldMap["additionalProperties"].(bool)
+	newExtra, _ := newMap["additionalProperties"].(bool)
+	if oldExtra && !newExtra && dir == dirInput {
+		d.emit(ChangeBreaking, path, "additional properties no longer allowed")
+	}
+}
+
+func (d *manifestDiffer) compareDiscriminator(oldMap, newMap map[string]any, path []string, dir schemaDirection) {
+	oldTag, _ := oldMap["discriminator"].(string)
+	newTag, _ := newMap["discriminator"].(string)
+	if oldTag != newTag {
+		d.emit(ChangeBreaking, path, fmt.Sprintf("discriminator changed from %q to %q", oldTag, newTag))
+		return
+	}
+	oldMapping, _ := oldMap["mapping"].(map[string]any)
+	newMapping, _ := newMap["mapping"].(map[string]any)
+	for tag, oldBranch := range oldMapping {
+		branchPath := childPath(path, tag)
+		newBranch, ok := newMapping[tag]
+		if !ok {
+			d.emit(severityOf(dir == dirInput), branchPath, "variant removed")
+			continue
+		}
+		d.compare(oldBranch, newBranch, branchPath, dir)
+	}
+	for tag := range newMapping {
+		if _, ok := oldMapping[tag]; !ok {
+			d.emit(severityOf(dir == dirOutput), childPath(path, tag), "variant added")
+		}
+	}
+}
+
+// schemaForm returns the JTD form of a schema (ignoring nullable).
+func schemaForm(m map[string]any) string {
+	for _, form := range []string{"ref", "type", "enum", "elements", "values", "discriminator"} {
+		if _, ok := m[form]; ok {
+			return form
+		}
+	}
+	if _, ok := m["properties"]; ok {
+		return "properties"
+	}
+	if _, ok := m["optionalProperties"]; ok {
+		return "properties"
+	}
+	return "empty"
+}
+
+func childPath(path []string, seg string) []string {
+	return append(append(make([]string, 0, len(path)+1), path...), seg)
+}
+
+func severityOf(breaking bool) ChangeSeverity {
+	if breaking {
+		return ChangeBreaking
+	}
+	return ChangeAdditive
+}
+
+func stringSet(v any) map[string]bool {
+	set := make(map[string]bool)
+	arr, _ := v.([]any)
+	for _, item := range arr {
+		if s, ok := item.(string); ok {
+			set[s] = true
+		}
+	}
+	return set
+}
+
+// checkPinnedManifest panics when the current manifest breaks compatibility
+// with the pinned manifest file, refusing to serve incompatible clients.
+func checkPinnedManifest(pinnedPath string, current []byte) {
+	pinned, err := os.ReadFile(pinnedPath)
+	if err != nil {
+		panic(fmt.Sprintf("read pinned manifest %s: %v", pinnedPath, err))
+	}
+	changes, err := DiffManifests(pinned, current)
+	if err != nil {
+		panic(fmt.Sprintf("diff pinned manifest %s: %v", pinnedPath, err))
+	}
+	if !HasBreaking(changes) {
+		return
+	}
+	var lines []string
+	for _, c := range changes {
+		if c.Severity == ChangeBreaking {
+			lines = append(lines, "  "+c.String())
+		}
+	}
+	panic(fmt.Sprintf("manifest has breaking changes vs pinned %s:\n%s", pinnedPath, strings.Join(lines, "\n")))
+}
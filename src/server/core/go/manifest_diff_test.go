@@ -0,0 +1,127 @@
+/* src/server/core/go/manifest_diff_test.go */
+
+package seam
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func diffOf(t *testing.T, oldJSON, newJSON string) []ManifestChange {
+	t.Helper()
+	changes, err := DiffManifests([]byte(oldJSON), []byte(newJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return changes
+}
+
+func TestDiffManifestsProcedureLevel(t *testing.T) {
+	oldM := `{"procedures":{"a":{"kind":"query","input":{}},"b":{"kind":"query","input":{}}}}`
+	newM := `{"procedures":{"b":{"kind":"command","input":{}},"c":{"kind":"query","input":{}}}}`
+	changes := diffOf(t, oldM, newM)
+	if len(changes) != 3 {
+		t.Fatalf("expected 3 changes, got %v", changes)
+	}
+	if changes[0].Procedure != "a" || changes[0].Severity != ChangeBreaking {
+		t.Errorf("expected removed a breaking, got %v", changes[0])
+	}
+	if changes[1].Procedure != "b" || !strings.Contains(changes[1].Message, "kind changed") {
+		t.Errorf("expected kind change for b, got %v", changes[1])
+	}
+	if changes[2].Procedure != "c" || changes[2].Severity != ChangeAdditive {
+		t.Errorf("expected added c additive, got %v", changes[2])
+	}
+}
+
+func TestDiffManifestsDirectionalFields(t *testing.T) {
+	oldM := `{"procedures":{"getUser":{"kind":"query",
+		"input":{"properties":{"id":{"type":"string"}}},
+		"output":{"properties":{"name":{"type":"string"},"bio":{"type":"string"}}}}}}`
+	newM := `{"procedures":{"getUser":{"kind":"query",
+		"input":{"properties":{"id":{"type":"string"},"org":{"type":"string"}},"optionalProperties":{"verbose":{"type":"boolean"}}},
+		"output":{"properties":{"name":{"type":"string"},"avatar":{"type":"string"}}}}}}`
+	changes := diffOf(t, oldM, newM)
+
+	want := map[string]ChangeSeverity{
+		"input/org":     ChangeBreaking, // new required input field
+		"input/verbose": ChangeAdditive, // new optional input field
+		"output/avatar": ChangeAdditive, // new output field
+		"output/bio":    ChangeBreaking, // removed output field
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("expected %d changes, got %v", len(want), changes)
+	}
+	for _, c := range changes {
+		if want[c.Path] != c.Severity {
+			t.Errorf("%s: expected %s, got %s (%s)", c.Path, want[c.Path], c.Severity, c.Message)
+		}
+	}
+	if !HasBreaking(changes) {
+		t.Fatal("expected breaking changes")
+	}
+}
+
+func TestDiffManifestsNarrowedTypes(t *testing.T) {
+	oldM := `{"procedures":{"p":{"kind":"query",
+		"input":{"properties":{"n":{"type":"int32"},"s":{"enum":["a","b"]},"x":{"type":"string","nullable":true}}},
+		"output":{"elements":{"enum":["a"]}}}}}`
+	newM := `{"procedures":{"p":{"kind":"query",
+		"input":{"properties":{"n":{"type":"int8"},"s":{"enum":["a","b","c"]},"x":{"type":"string"}}},
+		"output":{"elements":{"enum":["a","z"]}}}}}`
+	changes := diffOf(t, oldM, newM)
+	got := make(map[string]ChangeSeverity)
+	for _, c := range changes {
+		got[c.Path] = c.Severity
+	}
+	if got["input/n"] != ChangeBreaking {
+		t.Error("expected int32 -> int8 to be breaking")
+	}
+	if got["input/s"] != ChangeAdditive {
+		t.Error("expected input enum widening to be additive")
+	}
+	if got["input/x"] != ChangeBreaking {
+		t.Error("expected dropping input nullability to be breaking")
+	}
+	if got["output/[]"] != ChangeBreaking {
+		t.Error("expected output enum widening to be breaking")
+	}
+}
+
+func TestDiffManifestsIdentical(t *testing.T) {
+	r := NewRouter().Procedure(&ProcedureDef{Name: "a", InputSchema: map[string]any{"properties": map[string]any{"x": map[string]any{"type": "string"}}}})
+	m, _ := r.Manifest()
+	if changes := diffOf(t, string(m), string(m)); len(changes) != 0 {
+		t.Fatalf("expected no changes, got %v", changes)
+	}
+}
+
+func TestPinnedManifestRefusesBreakingChanges(t *testing.T) {
+	pinned := filepath.Join(t.TempDir(), "manifest.json")
+	old := NewRouter().
+		Procedure(&ProcedureDef{Name: "a", InputSchema: map[string]any{}, Handler: echoHandler()}).
+		Procedure(&ProcedureDef{Name: "b", InputSchema: map[string]any{}, Handler: echoHandler()})
+	m, _ := old.Manifest()
+	if err := os.WriteFile(pinned, m, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Additive change boots
+	_ = NewRouter().
+		Procedure(&ProcedureDef{Name: "a", InputSchema: map[string]any{}, Handler: echoHandler()}).
+		Procedure(&ProcedureDef{Name: "b", InputSchema: map[string]any{}, Handler: echoHandler()}).
+		Procedure(&ProcedureDef{Name: "c", InputSchema: map[string]any{}, Handler: echoHandler()}).
+		Handler(HandlerOptions{PinnedManifest: pinned})
+
+	defer func() {
+		rec := recover()
+		if rec == nil || !strings.Contains(rec.(string), "procedure removed") {
+			t.Fatalf("expected panic on breaking change, got %v", rec)
+		}
+	}()
+	NewRouter().
+		Procedure(&ProcedureDef{Name: "a", InputSchema: map[string]any{}, Handler: echoHandler()}).
+		Handler(HandlerOptions{PinnedManifest: pinned})
+}
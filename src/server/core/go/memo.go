@@ -0,0 +1,65 @@
+/* src/server/core/go/memo.go */
+
+package seam
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+type memoKeyType struct{}
+
+var memoKey = memoKeyType{}
+
+// memoStore is a per-request cache shared by every procedure call within
+// one HTTP request (all loaders of a page, all calls of a batch).
+type memoStore struct {
+	mu      sync.Mutex
+	entries map[string]*memoEntry
+}
+
+type memoEntry struct {
+	once  sync.Once
+	value any
+	err   error
+}
+
+// injectMemo attaches a fresh request-scoped memo store to ctx.
+func injectMemo(ctx context.Context) context.Context {
+	return context.WithValue(ctx, memoKey, &memoStore{entries: make(map[string]*memoEntry)})
+}
+
+// Memo returns the result of fn for key, computing it at most once per request.
+// Concurrent callers with the same key wait for the first computation; errors
+// are memoized too. Outside a seam request, fn is called directly. Reusing a
+// key with a different T is a programming error and returns INTERNAL_ERROR
+// without calling fn.
+func Memo[T any](ctx context.Context, key string, fn func() (T, error)) (T, error) {
+	store, ok := ctx.Value(memoKey).(*memoStore)
+	if !ok {
+		return fn()
+	}
+
+	store.mu.Lock()
+	entry, ok := store.entries[key]
+	if !ok {
+		entry = &memoEntry{}
+		store.entries[key] = entry
+	}
+	store.mu.Unlock()
+
+	entry.once.Do(func() {
+		entry.value, entry.err = fn()
+	})
+
+	var zero T
+	if entry.err != nil {
+		return zero, entry.err
+	}
+	typed, ok := entry.value.(T)
+	if !ok && entry.value != nil {
+		return zero, InternalError(fmt.Sprintf("Memo key %q holds %T, not %T", key, entry.value, zero))
+	}
+	return typed, nil
+}
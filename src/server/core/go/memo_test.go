@@ -0,0 +1,83 @@
+/* src/server/core/go/memo_test.go */
+
+package seam
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestMemoOutsideRequestCallsThrough(t *testing.T) {
+	calls := 0
+	for i := 0; i < 2; i++ {
+		v, err := Memo(context.Background(), "k", func() (int, error) { calls++; return 7, nil })
+		if err != nil || v != 7 {
+			t.Fatalf("unexpected result %d %v", v, err)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("expected pass-through without request scope, got %d calls", calls)
+	}
+}
+
+func TestMemoSharedAcrossPageLoaders(t *testing.T) {
+	var sessionLookups atomic.Int32
+	lookup := func(ctx context.Context) (string, error) {
+		return Memo(ctx, "session", func() (string, error) {
+			sessionLookups.Add(1)
+			return "alice", nil
+		})
+	}
+	handler := NewRouter().
+		Procedure(Query("a", func(ctx context.Context, _ struct{}) (string, error) { return lookup(ctx) })).
+		Procedure(Query("b", func(ctx context.Context, _ struct{}) (string, error) { return lookup(ctx) })).
+		Page(&PageDef{
+			Route:    "/p",
+			Template: "<html><body><!--seam:a--></body></html>",
+			Loaders: []LoaderDef{
+				{DataKey: "a", Procedure: "a", InputFn: func(map[string]string) any { return struct{}{} }},
+				{DataKey: "b", Procedure: "b", InputFn: func(map[string]string) any { return struct{}{} }},
+			},
+		}).
+		Handler()
+
+	for i := 1; i <= 2; i++ {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/_seam/page/p", http.NoBody))
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "alice") {
+			t.Fatalf("unexpected page response %d: %s", w.Code, w.Body.String())
+		}
+		if got := sessionLookups.Load(); got != int32(i) {
+			t.Fatalf("expected one lookup per request, got %d after %d requests", got, i)
+		}
+	}
+}
+
+func TestMemoCachesErrors(t *testing.T) {
+	ctx := injectMemo(context.Background())
+	calls := 0
+	fail := func() (int, error) { calls++; return 0, errors.New("boom") }
+	_, err1 := Memo(ctx, "k", fail)
+	_, err2 := Memo(ctx, "k", fail)
+	if err1 == nil || err2 == nil || calls != 1 {
+		t.Fatalf("expected memoized error, calls=%d", calls)
+	}
+}
+
+func TestMemoTypeConflict(t *testing.T) {
+	ctx := injectMemo(context.Background())
+	if _, err := Memo(ctx, "k", func() (int, error) { return 7, nil }); err != nil {
+		t.Fatal(err)
+	}
+	calls := 0
+	_, err := Memo(ctx, "k", func() (string, error) { calls++; return "x", nil })
+	var e *Error
+	if !errors.As(err, &e) || e.Code != "INTERNAL_ERROR" || !strings.Contains(e.Message, "holds int, not string") || calls != 0 {
+		t.Fatalf("expected type conflict error without recompute, got %v (calls=%d)", err, calls)
+	}
+}
@@ -0,0 +1,259 @@
+/* src/server/core/go/memory_budget.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// MemoryBudget caps the approximate bytes one request holds, so a single
+// pathological page cannot exhaust a shared deployment. Each RPC, batch,
+// stream, and page request accounts its body, the serialized results of
+// its page loaders, and its rendered HTML against PerRequest:
+//
+//   - a body over the budget is rejected with 413
+//   - a loader result that would exceed it is truncated to an error
+//     marker (`__error`, code RESOURCE_EXHAUSTED), so error boundaries
+//     render as for a failed loader
+//   - rendered HTML over the budget fails the page with 500
+//
+// The heaviest targets are kept for metrics: serve the budget (it is an
+// http.Handler) for Prometheus-style scraping, or read Snapshot.
+type MemoryBudget struct {
+	PerRequest int64 // bytes per request (required)
+	TopN       int   // offenders reported by Snapshot and ServeHTTP (default 10)
+
+	mu      sync.Mutex
+	targets map[sizeKey]*memoryUsage
+}
+
+type memoryUsage struct {
+	requests   int64
+	total      int64
+	max        int64
+	overBudget int64
+}
+
+// MemoryStats summarizes the accounted bytes of one procedure or page route.
+type MemoryStats struct {
+	Kind       string `json:"kind"` // "procedure" or "page"
+	Name       string `json:"name"`
+	Requests   int64  `json:"requests"`
+	TotalBytes int64  `json:"totalBytes"`
+	MaxBytes   int64  `json:"maxBytes"`
+	OverBudget int64  `json:"overBudget"` // requests that hit the budget
+}
+
+// memoryAccount is the running total of one request.
+type memoryAccount struct {
+	budget int64
+	used   atomic.Int64
+	over   atomic.Bool
+}
+
+type memoryAccountKeyType struct{}
+
+var memoryAccountKey = memoryAccountKeyType{}
+
+func memoryAccountOf(ctx context.Context) *memoryAccount {
+	a, _ := ctx.Value(memoryAccountKey).(*memoryAccount)
+	return a
+}
+
+// charge adds n bytes and reports whether the request is still within its
+// budget; rejected charges are not kept. A nil account always fits.
+func (a *memoryAccount) charge(n int64) bool {
+	if a == nil {
+		return true
+	}
+	if a.used.Add(n) > a.budget {
+		a.used.Add(-n)
+		a.over.Store(true)
+		return false
+	}
+	return true
+}
+
+func memoryBudgetError(what string) *Error {
+	return NewError("RESOURCE_EXHAUSTED", what+" exceeds the request memory budget", http.StatusInternalServerError)
+}
+
+// chargeLoaders accounts the loader results of a page by their JSON size,
+// reusing raw, the per-key encoding of the page data. A result that would
+// exceed the request budget is replaced (in raw, data, and loaderMeta) by
+// an error marker, so error boundaries render as for a failed loader.
+// Keys are charged in sorted order for a deterministic outcome.
+func chargeLoaders(ctx context.Context, raw map[string]json.RawMessage, data, loaderMeta map[string]any) {
+	a := memoryAccountOf(ctx)
+	if a == nil {
+		return
+	}
+	keys := make([]string, 0, len(loaderMeta))
+	for key := range loaderMeta {
+		if _, ok := raw[key]; ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		meta, _ := loaderMeta[key].(map[string]any)
+		if meta["error"] == true || a.charge(int64(len(raw[key]))) {
+			continue
+		}
+		e := memoryBudgetError(fmt.Sprintf("Loader %q result", key))
+		logf(slog.LevelWarn, "Loader %q failed: %v\n", key, e)
+		marker := map[string]any{"__error": true, "code": e.Code, "message": e.Message}
+		raw[key], _ = json.Marshal(marker)
+		data[key] = marker
+		if meta != nil {
+			meta["error"] = true
+		}
+	}
+}
+
+// budgetReader charges body bytes as the handler reads them.
+type budgetReader struct {
+	io.ReadCloser
+	account *memoryAccount
+}
+
+func (b *budgetReader) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 && !b.account.charge(int64(n)) {
+		// readBody answers a MaxBytesError with 413
+		return n, &http.MaxBytesError{Limit: b.account.budget}
+	}
+	return n, err
+}
+
+// memoryBudgetMiddleware opens an account per procedure, batch, stream,
+// and page request and records its total, under "(unknown)" for names
+// that are not registered. Uploads, subscriptions, and sockets are not
+// accounted.
+func (s *appState) memoryBudgetMiddleware(mb *MemoryBudget, next http.Handler) http.Handler {
+	if mb.PerRequest <= 0 {
+		panic("MemoryBudget.PerRequest must be positive")
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		class, procedure := s.classifyRoute(r)
+		var kind string
+		switch class {
+		case RouteQuery, RouteCommand, RouteStream:
+			kind = "procedure"
+		case RouteBatch:
+			kind, procedure = "procedure", "batch"
+		case RoutePage:
+			kind = "page"
+		default:
+			next.ServeHTTP(w, r)
+			return
+		}
+		if kind == "procedure" && class != RouteBatch {
+			procedure = s.procedureLabel(procedure)
+		}
+		if r.ContentLength > mb.PerRequest {
+			e := memoryBudgetError("Request body")
+			e.Status = http.StatusRequestEntityTooLarge
+			mb.record(kind, procedure, r.ContentLength, true)
+			writeError(w, e.Status, e)
+			return
+		}
+		account := &memoryAccount{budget: mb.PerRequest}
+		r = r.WithContext(context.WithValue(r.Context(), memoryAccountKey, account))
+		if r.Body != nil && r.Body != http.NoBody {
+			r.Body = &budgetReader{ReadCloser: r.Body, account: account}
+		}
+		next.ServeHTTP(w, r)
+		name := procedure
+		if kind == "page" {
+			name = pageRouteName(r)
+		}
+		mb.record(kind, name, account.used.Load(), account.over.Load())
+	})
+}
+
+func (mb *MemoryBudget) record(kind, name string, used int64, over bool) {
+	mb.mu.Lock()
+	if mb.targets == nil {
+		mb.targets = make(map[sizeKey]*memoryUsage)
+	}
+	key := sizeKey{kind, name}
+	u, ok := mb.targets[key]
+	if !ok {
+		u = &memoryUsage{}
+		mb.targets[key] = u
+	}
+	u.requests++
+	u.total += used
+	u.max = max(u.max, used)
+	first := false
+	if over {
+		u.overBudget++
+		first = u.overBudget == 1
+	}
+	mb.mu.Unlock()
+
+	if first {
+		logf(slog.LevelWarn, "%s %s exceeded the %d byte request memory budget\n", kind, name, mb.PerRequest)
+	}
+}
+
+func (mb *MemoryBudget) topN() int {
+	if mb.TopN > 0 {
+		return mb.TopN
+	}
+	return 10
+}
+
+// Snapshot returns the TopN targets by largest request, heaviest first.
+func (mb *MemoryBudget) Snapshot() []MemoryStats {
+	mb.mu.Lock()
+	stats := make([]MemoryStats, 0, len(mb.targets))
+	for key, u := range mb.targets {
+		stats = append(stats, MemoryStats{
+			Kind:       key.kind,
+			Name:       key.name,
+			Requests:   u.requests,
+			TotalBytes: u.total,
+			MaxBytes:   u.max,
+			OverBudget: u.overBudget,
+		})
+	}
+	mb.mu.Unlock()
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].MaxBytes != stats[j].MaxBytes {
+			return stats[i].MaxBytes > stats[j].MaxBytes
+		}
+		return stats[i].Kind+stats[i].Name < stats[j].Kind+stats[j].Name
+	})
+	if len(stats) > mb.topN() {
+		stats = stats[:mb.topN()]
+	}
+	return stats
+}
+
+// ServeHTTP writes the top offenders in the Prometheus text exposition format.
+func (mb *MemoryBudget) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	stats := mb.Snapshot()
+	fmt.Fprintln(w, "# TYPE seam_request_memory_max_bytes gauge")
+	for _, s := range stats {
+		fmt.Fprintf(w, "seam_request_memory_max_bytes{kind=%q,name=%q} %d\n", s.Kind, s.Name, s.MaxBytes)
+	}
+	fmt.Fprintln(w, "# TYPE seam_request_memory_bytes_total counter")
+	for _, s := range stats {
+		fmt.Fprintf(w, "seam_request_memory_bytes_total{kind=%q,name=%q} %d\n", s.Kind, s.Name, s.TotalBytes)
+	}
+	fmt.Fprintln(w, "# TYPE seam_request_memory_over_budget_total counter")
+	for _, s := range stats {
+		fmt.Fprintf(w, "seam_request_memory_over_budget_total{kind=%q,name=%q} %d\n", s.Kind, s.Name, s.OverBudget)
+	}
+}
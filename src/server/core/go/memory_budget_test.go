@@ -0,0 +1,99 @@
+/* src/server/core/go/memory_budget_test.go */
+
+package seam
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func memoryBudgetHandler(t *testing.T, mb *MemoryBudget) http.Handler {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"route-manifest.json": `{"routes": {"/report": {"template": "t/report.html", "loaders": {
+			"title": {"procedure": "getTitle"},
+			"rows": {"procedure": "getRows"}
+		}}}}`,
+		"t/report.html": `<html><body><h1><!--seam:title--></h1></body></html>`,
+	})
+	pages, err := LoadBuildOutput(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewRouter().
+		Procedure(Query("getTitle", func(context.Context, struct{}) (string, error) { return "Report", nil })).
+		Procedure(Query("getRows", func(context.Context, struct{}) ([]string, error) {
+			return []string{strings.Repeat("x", 4096)}, nil
+		})).
+		Procedure(Command("save", func(context.Context, struct{ Note string }) (bool, error) { return true, nil })).
+		Page(&pages[0]).
+		Handler(HandlerOptions{MemoryBudget: mb})
+}
+
+func TestMemoryBudgetTruncatesLoaderAndRejectsBody(t *testing.T) {
+	mb := &MemoryBudget{PerRequest: 2048}
+	h := memoryBudgetHandler(t, mb)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/_seam/page/report", nil))
+	body := w.Body.String()
+	if w.Code != http.StatusOK || !strings.Contains(body, "<h1>Report</h1>") ||
+		!strings.Contains(body, `"rows":{"__error":true,"code":"RESOURCE_EXHAUSTED"`) {
+		t.Errorf("page: %d %s", w.Code, body)
+	}
+
+	code, resp := rpcBody(h, "/_seam/procedure/save", `{"Note":"`+strings.Repeat("n", 3000)+`"}`, nil)
+	if code != http.StatusRequestEntityTooLarge || !strings.Contains(resp, "RESOURCE_EXHAUSTED") {
+		t.Errorf("oversized body: %d %s", code, resp)
+	}
+	if code, _ := rpcBody(h, "/_seam/procedure/save", `{"Note":"ok"}`, nil); code != http.StatusOK {
+		t.Errorf("small body: %d", code)
+	}
+
+	stats := mb.Snapshot()
+	if len(stats) != 2 || stats[0].Name != "save" || stats[0].OverBudget != 1 || stats[1].Name != "/report" || stats[1].OverBudget != 1 {
+		t.Fatalf("stats %+v", stats)
+	}
+	w = httptest.NewRecorder()
+	mb.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if !strings.Contains(w.Body.String(), `seam_request_memory_over_budget_total{kind="page",name="/report"} 1`) {
+		t.Errorf("metrics:\n%s", w.Body.String())
+	}
+}
+
+func TestMemoryBudgetRejectsLargePage(t *testing.T) {
+	h := memoryBudgetHandler(t, &MemoryBudget{PerRequest: 4200})
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/_seam/page/report", nil))
+	if w.Code != http.StatusInternalServerError || !strings.Contains(w.Body.String(), "Rendered page exceeds") {
+		t.Errorf("page: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestMemoryBudgetChunkedBodyAndUnknownNames(t *testing.T) {
+	mb := &MemoryBudget{PerRequest: 1024}
+	h := memoryBudgetHandler(t, mb)
+
+	req := httptest.NewRequest("POST", "/_seam/procedure/save", io.MultiReader(strings.NewReader(`{"Note":"`+strings.Repeat("n", 3000)+`"}`)))
+	req.ContentLength = -1
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("chunked oversized body: %d %s", w.Code, w.Body.String())
+	}
+
+	for _, name := range []string{"nope1", "nope2"} {
+		rpcBody(h, "/_seam/procedure/"+name, `{}`, nil)
+	}
+	var names []string
+	for _, s := range mb.Snapshot() {
+		names = append(names, s.Name)
+	}
+	if strings.Join(names, ",") != "save,(unknown)" {
+		t.Fatalf("targets %v", names)
+	}
+}
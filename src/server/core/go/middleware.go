@@ -0,0 +1,35 @@
+/* src/server/core/go/middleware.go */
+
+package seam
+
+import "net/http"
+
+// wrapMiddleware applies the handler-wide middleware configured in
+// HandlerOptions around the routed handler (outermost first).
+func (s *appState) wrapMiddleware(h http.Handler) http.Handler {
+	if s.opts.MemoryBudget != nil {
+		h = s.memoryBudgetMiddleware(s.opts.MemoryBudget, h)
+	}
+	if s.opts.ResponseSizes != nil {
+		h = s.responseSizesMiddleware(s.opts.ResponseSizes, h)
+	}
+	if len(s.redirects) > 0 {
+		h = s.redirectMiddleware(h)
+	}
+	if s.opts.PasswordProtect != nil {
+		h = s.passwordMiddleware(s.opts.PasswordProtect, h)
+	}
+	if s.opts.SecurityHeaders != nil {
+		h = s.securityHeadersMiddleware(s.opts.SecurityHeaders, h)
+	}
+	if len(s.ipFilters) > 0 {
+		h = s.ipFilterMiddleware(h)
+	}
+	if s.opts.OnResponse != nil {
+		h = s.responseHookMiddleware(s.opts.OnResponse, h)
+	}
+	if s.opts.AccessLog != nil && s.opts.AccessLog.Writer != nil {
+		h = s.accessLogMiddleware(s.opts.AccessLog, h)
+	}
+	return s.clientIPMiddleware(h)
+}
@@ -0,0 +1,224 @@
+/* src/server/core/go/mock_profiles.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/rand/v2"
+	"os"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// MockProfiles slows down and fails procedures on purpose so frontend
+// teams can exercise loading states and error UI against a real backend.
+// Profiles apply only when SEAM_ENV is one of Env, never in production.
+// Set HandlerOptions.MockProfiles, or point SEAM_MOCK_PROFILES at a JSON
+// file (FileMockProfiles) to enable them without code changes:
+//
+//	{
+//	  "env": ["staging"],
+//	  "procedures": {
+//	    "getUser": {"latency": {"p50": "120ms", "p99": "2s"}, "failureRate": 0.05},
+//	    "blog.*":  {"latency": {"min": "50ms", "max": "400ms"}, "error": "UNAVAILABLE"}
+//	  }
+//	}
+type MockProfiles struct {
+	Env []string `json:"env"` // SEAM_ENV values the profiles apply in (default ["staging"])
+	// Procedures maps procedure names or path.Match patterns ("blog.*",
+	// "*") to profiles; an exact name wins, then the longest pattern.
+	Procedures map[string]MockProfile `json:"procedures"`
+}
+
+// MockProfile is the injected behavior of one procedure.
+type MockProfile struct {
+	Latency     MockLatency `json:"latency"`
+	FailureRate float64     `json:"failureRate"` // 0..1, share of calls failed after the delay
+	Error       string      `json:"error"`       // error code of injected failures (default INTERNAL_ERROR)
+}
+
+// MockLatency is a delay distribution: log-normal through P50 and P99
+// when P50 is set, else uniform between Min and Max. Min and Max also
+// clamp log-normal samples. In JSON, durations are strings ("250ms") or
+// milliseconds.
+type MockLatency struct {
+	Min, Max time.Duration
+	P50, P99 time.Duration
+}
+
+// UnmarshalJSON reads {"min", "max", "p50", "p99"} durations.
+func (l *MockLatency) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	for key, dst := range map[string]*time.Duration{"min": &l.Min, "max": &l.Max, "p50": &l.P50, "p99": &l.P99} {
+		v, ok := raw[key]
+		if !ok {
+			continue
+		}
+		d, err := parseMockDuration(v)
+		if err != nil {
+			return fmt.Errorf("latency %s: %w", key, err)
+		}
+		*dst = d
+	}
+	return nil
+}
+
+func parseMockDuration(v json.RawMessage) (time.Duration, error) {
+	var s string
+	if json.Unmarshal(v, &s) == nil {
+		return time.ParseDuration(s)
+	}
+	ms, err := strconv.ParseFloat(string(v), 64)
+	if err != nil {
+		return 0, fmt.Errorf("want a duration string or milliseconds, got %s", v)
+	}
+	return time.Duration(ms * float64(time.Millisecond)), nil
+}
+
+// sample draws one delay.
+func (l MockLatency) sample() time.Duration {
+	var d time.Duration
+	switch {
+	case l.P50 > 0:
+		// z(0.99) = 2.326: sigma puts the 99th percentile at P99.
+		sigma := 0.0
+		if l.P99 > l.P50 {
+			sigma = math.Log(float64(l.P99)/float64(l.P50)) / 2.326
+		}
+		d = time.Duration(float64(l.P50) * math.Exp(sigma*rand.NormFloat64()))
+	case l.Max > l.Min:
+		d = l.Min + rand.N(l.Max-l.Min)
+	default:
+		d = l.Min
+	}
+	if d < l.Min {
+		d = l.Min
+	}
+	if l.Max > 0 && d > l.Max {
+		d = l.Max
+	}
+	return d
+}
+
+// FileMockProfiles loads profiles from a JSON file.
+func FileMockProfiles(path string) (*MockProfiles, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read mock profiles: %w", err)
+	}
+	var p MockProfiles
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("parse mock profiles: %w", err)
+	}
+	return &p, nil
+}
+
+// mockProfilesFor returns the profiles in effect: opts.MockProfiles or
+// the SEAM_MOCK_PROFILES file, when SEAM_ENV selects them. An unreadable
+// file or invalid profile panics, like other handler configuration errors.
+func mockProfilesFor(opts HandlerOptions) *MockProfiles {
+	p := opts.MockProfiles
+	if p == nil {
+		file := os.Getenv("SEAM_MOCK_PROFILES")
+		if file == "" {
+			return nil
+		}
+		var err error
+		if p, err = FileMockProfiles(file); err != nil {
+			panic(err.Error())
+		}
+	}
+	envs := p.Env
+	if len(envs) == 0 {
+		envs = []string{"staging"}
+	}
+	if isProduction() || !slices.Contains(envs, os.Getenv("SEAM_ENV")) {
+		return nil
+	}
+	for pattern, prof := range p.Procedures {
+		if _, err := path.Match(pattern, ""); err != nil {
+			panic(fmt.Sprintf("mock profile %q: bad pattern", pattern))
+		}
+		if prof.FailureRate < 0 || prof.FailureRate > 1 {
+			panic(fmt.Sprintf("mock profile %q: failureRate %v outside 0..1", pattern, prof.FailureRate))
+		}
+	}
+	return p
+}
+
+// profile returns the profile matching name.
+func (p *MockProfiles) profile(name string) (MockProfile, bool) {
+	if prof, ok := p.Procedures[name]; ok {
+		return prof, true
+	}
+	patterns := make([]string, 0, len(p.Procedures))
+	for pattern := range p.Procedures {
+		if ok, _ := path.Match(pattern, name); ok {
+			patterns = append(patterns, pattern)
+		}
+	}
+	if len(patterns) == 0 {
+		return MockProfile{}, false
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		if len(patterns[i]) != len(patterns[j]) {
+			return len(patterns[i]) > len(patterns[j])
+		}
+		return patterns[i] < patterns[j]
+	})
+	return p.Procedures[patterns[0]], true
+}
+
+// apply returns procedures with matching handlers wrapped; the input
+// slice (the router's) is left untouched.
+func (p *MockProfiles) apply(procedures []ProcedureDef) []ProcedureDef {
+	out := make([]ProcedureDef, len(procedures))
+	copy(out, procedures)
+	mocked := 0
+	for i := range out {
+		prof, ok := p.profile(out[i].Name)
+		if !ok {
+			continue
+		}
+		out[i].Handler = prof.wrap(out[i].Name, out[i].Handler)
+		mocked++
+	}
+	if mocked > 0 {
+		logf(slog.LevelInfo, "mock profiles active for %d procedures (SEAM_ENV=%s)\n", mocked, os.Getenv("SEAM_ENV"))
+	}
+	return out
+}
+
+func (prof MockProfile) wrap(name string, next HandlerFunc) HandlerFunc {
+	code := prof.Error
+	if code == "" {
+		code = "INTERNAL_ERROR"
+	}
+	return func(ctx context.Context, input json.RawMessage) (any, error) {
+		if d := prof.Latency.sample(); d > 0 {
+			t := time.NewTimer(d)
+			select {
+			case <-t.C:
+			case <-ctx.Done():
+				t.Stop()
+				return nil, ctx.Err()
+			}
+		}
+		if prof.FailureRate > 0 && rand.Float64() < prof.FailureRate {
+			e := &Error{Code: code, Message: fmt.Sprintf("Injected failure for procedure '%s' (mock profile)", name)}
+			e.Transient = errorHTTPStatus(e) >= 500 || code == "RATE_LIMITED"
+			return nil, e
+		}
+		return next(ctx, input)
+	}
+}
@@ -0,0 +1,86 @@
+/* src/server/core/go/mock_profiles_test.go */
+
+package seam
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mockRouter() *Router {
+	ok := func(context.Context, struct{}) (bool, error) { return true, nil }
+	return NewRouter().
+		Procedure(Query("getUser", ok)).
+		Procedure(Query("blog.getPost", ok)).
+		Procedure(Query("blog.listPosts", ok))
+}
+
+func TestMockProfilesFromFile(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"mocks.json": `{
+		"procedures": {
+			"blog.*": {"latency": {"min": "30ms", "max": 30}},
+			"blog.listPosts": {"failureRate": 1, "error": "UNAVAILABLE"}
+		}
+	}`})
+	t.Setenv("SEAM_MOCK_PROFILES", filepath.Join(dir, "mocks.json"))
+	t.Setenv("SEAM_ENV", "staging")
+	h := mockRouter().Handler(HandlerOptions{})
+
+	start := time.Now()
+	if code, body := rpcBody(h, "/_seam/procedure/blog.getPost", `{}`, nil); code != http.StatusOK {
+		t.Errorf("getPost: %d %s", code, body)
+	}
+	if d := time.Since(start); d < 30*time.Millisecond {
+		t.Errorf("latency not injected: %s", d)
+	}
+	code, body := rpcBody(h, "/_seam/procedure/blog.listPosts", `{}`, nil)
+	if code != http.StatusServiceUnavailable || !strings.Contains(body, "Injected failure for procedure 'blog.listPosts'") {
+		t.Errorf("listPosts: %d %s", code, body)
+	}
+	start = time.Now()
+	if code, _ := rpcBody(h, "/_seam/procedure/getUser", `{}`, nil); code != http.StatusOK || time.Since(start) >= 30*time.Millisecond {
+		t.Errorf("unmatched procedure mocked: %d", code)
+	}
+}
+
+func TestMockProfilesOnlyInSelectedEnv(t *testing.T) {
+	profiles := &MockProfiles{Procedures: map[string]MockProfile{"*": {FailureRate: 1}}}
+	for _, env := range []string{"", "development", "production"} {
+		t.Setenv("SEAM_ENV", env)
+		h := mockRouter().Handler(HandlerOptions{MockProfiles: profiles})
+		if code, body := rpcBody(h, "/_seam/procedure/getUser", `{}`, nil); code != http.StatusOK {
+			t.Errorf("SEAM_ENV=%q: %d %s", env, code, body)
+		}
+	}
+	t.Setenv("SEAM_ENV", "qa")
+	profiles.Env = []string{"qa"}
+	h := mockRouter().Handler(HandlerOptions{MockProfiles: profiles})
+	if code, _ := rpcBody(h, "/_seam/procedure/getUser", `{}`, nil); code != http.StatusInternalServerError {
+		t.Errorf("SEAM_ENV=qa: %d", code)
+	}
+}
+
+func TestMockLatencySample(t *testing.T) {
+	lat := MockLatency{P50: 100 * time.Millisecond, P99: time.Second, Max: 2 * time.Second}
+	below := 0
+	for range 2000 {
+		d := lat.sample()
+		if d <= 0 || d > lat.Max {
+			t.Fatalf("sample %s out of range", d)
+		}
+		if d <= lat.P50 {
+			below++
+		}
+	}
+	if below < 850 || below > 1150 {
+		t.Errorf("%d of 2000 samples at or below p50", below)
+	}
+	if d := (MockLatency{Min: 5 * time.Millisecond}).sample(); d != 5*time.Millisecond {
+		t.Errorf("fixed latency %s", d)
+	}
+}
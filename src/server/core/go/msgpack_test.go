@@ -0,0 +1,147 @@
+/* src/server/core/go/msgpack_test.go */
+
+package seam
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestMsgpackRoundTrip(t *testing.T) {
+	values := []any{
+		nil, true, false,
+		0, 127, 128, 255, 65535, 1 << 20, int64(1) << 40,
+		-1, -32, -33, -200, -40000, -(1 << 40),
+		1.5, -0.25,
+		"", "short", strings.Repeat("x", 40), strings.Repeat("y", 300),
+		[]any{}, []any{1, "a", nil}, make([]any, 20),
+		map[string]any{}, map[string]any{"b": 1, "a": []any{true}},
+	}
+	for _, v := range values {
+		packed, err := encodeMsgpack(v)
+		if err != nil {
+			t.Fatalf("encode %v: %v", v, err)
+		}
+		got, err := msgpackToJSON(packed, 64)
+		if err != nil {
+			t.Fatalf("decode %v: %v", v, err)
+		}
+		want, _ := json.Marshal(v)
+		if string(got) != string(want) {
+			t.Fatalf("round trip mismatch: got %s, want %s", got, want)
+		}
+	}
+}
+
+func TestMsgpackRejectsTruncatedAndTrailing(t *testing.T) {
+	packed, _ := encodeMsgpack(map[string]any{"key": "value"})
+	if _, err := msgpackToJSON(packed[:len(packed)-1], 64); err == nil {
+		t.Fatal("expected error for truncated input")
+	}
+	if _, err := msgpackToJSON(append(packed, 0x01), 64); err == nil {
+		t.Fatal("expected error for trailing data")
+	}
+}
+
+func TestMsgpackRejectsDeepNesting(t *testing.T) {
+	// 8 MB of single-element arrays would otherwise recurse per byte
+	bomb := append(bytes.Repeat([]byte{0x91}, 8<<20), 0xc0)
+	if _, err := msgpackToJSON(bomb, 64); err == nil || !strings.Contains(err.Error(), "depth 64") {
+		t.Fatalf("expected depth error, got %v", err)
+	}
+	nested := append(bytes.Repeat([]byte{0x81, 0xa1, 'k'}, 64), 0xc0)
+	if _, err := msgpackToJSON(nested, 64); err != nil {
+		t.Fatalf("64 levels must decode: %v", err)
+	}
+	if _, err := msgpackToJSON(append([]byte{0x91}, nested...), 64); err == nil {
+		t.Fatal("expected depth error at 65 levels")
+	}
+}
+
+func TestChannelWsBinaryFramesRequireMsgpack(t *testing.T) {
+	srv := httptest.NewServer(NewRouter().Channel(echoTestChannel()).Handler())
+	defer srv.Close()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + `/_seam/procedure/chat.events?input={"roomId":"r1"}`
+
+	read := func(conn *websocket.Conn, binary bool) string {
+		_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if binary {
+			data, _ = msgpackToJSON(data, 64)
+		}
+		return string(data)
+	}
+
+	// Without the subprotocol, binary frames are refused
+	plain, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plain.Close()
+	uplink, _ := encodeMsgpack(map[string]any{"id": "1", "procedure": "chat.send", "input": map[string]any{"text": "hi"}})
+	_ = plain.WriteMessage(websocket.BinaryMessage, uplink)
+	if got := read(plain, false); !strings.Contains(got, "require the seam.msgpack subprotocol") {
+		t.Fatalf("unnegotiated binary frame: %s", got)
+	}
+
+	// With it, a deeply nested frame is refused and the socket stays usable
+	dialer := websocket.Dialer{Subprotocols: []string{MsgpackSubprotocol}}
+	conn, _, err := dialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.WriteMessage(websocket.BinaryMessage, append(bytes.Repeat([]byte{0x91}, 512<<10), 0xc0))
+	if got := read(conn, true); !strings.Contains(got, "Invalid uplink MessagePack") {
+		t.Fatalf("nested frame: %s", got)
+	}
+	_ = conn.WriteMessage(websocket.BinaryMessage, uplink)
+	if got := read(conn, true); got != `{"data":{"text":"hi"},"id":"1","ok":true}` {
+		t.Fatalf("socket unusable after refused frame: %s", got)
+	}
+}
+
+func TestChannelWsMsgpackFrames(t *testing.T) {
+	srv := httptest.NewServer(NewRouter().Channel(echoTestChannel()).Handler())
+	defer srv.Close()
+
+	dialer := websocket.Dialer{Subprotocols: []string{MsgpackSubprotocol}}
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + `/_seam/procedure/chat.events?input={"roomId":"r1"}`
+	conn, _, err := dialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if conn.Subprotocol() != MsgpackSubprotocol {
+		t.Fatalf("subprotocol not negotiated: %q", conn.Subprotocol())
+	}
+
+	uplink, _ := encodeMsgpack(map[string]any{"id": "1", "procedure": "chat.send", "input": map[string]any{"text": "hi"}})
+	if err := conn.WriteMessage(websocket.BinaryMessage, uplink); err != nil {
+		t.Fatal(err)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	msgType, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msgType != websocket.BinaryMessage {
+		t.Fatalf("expected binary frame, got type %d", msgType)
+	}
+	decoded, err := msgpackToJSON(data, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(decoded) != `{"data":{"text":"hi"},"id":"1","ok":true}` {
+		t.Fatalf("unexpected response: %s", decoded)
+	}
+}
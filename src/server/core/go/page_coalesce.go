@@ -0,0 +1,103 @@
+/* src/server/core/go/page_coalesce.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+)
+
+// pageFlights tracks in-flight renders of Coalesce pages by render cache
+// key (URL, locale, variant; see CacheKeyFunc), so a burst of identical requests runs the loaders once.
+type pageFlights struct {
+	mu      sync.Mutex
+	flights map[string]*pageFlight
+}
+
+type pageFlight struct {
+	done chan struct{}
+	res  *bufferResponse
+}
+
+// coalescePage serves a Coalesce page, joining an in-flight render of
+// the same URL when there is one. Personalized requests (a principal is
+// set by HandlerOptions.Principal or a signed URL) and non-GET requests
+// always render on their own; request context fields the page's loaders
+// read are part of the flight key. The shared render is detached from the
+// leader's cancellation so waiters are not failed by a disconnecting
+// leader.
+func (s *appState) coalescePage(w http.ResponseWriter, r *http.Request, page *PageDef) {
+	locale, ok := s.pageLocale(r)
+	if principal, _ := s.principal(r); r.Method != http.MethodGet || !ok || principal != "" {
+		s.servePage(w, r, page, nil)
+		return
+	}
+	key := s.renderCacheKey(r, locale) + s.loaderContextKey(r, page)
+
+	f := &s.pageFlights
+	f.mu.Lock()
+	if flight, ok := f.flights[key]; ok {
+		f.mu.Unlock()
+		select {
+		case <-flight.done:
+			flight.res.copyTo(w)
+		case <-r.Context().Done():
+		}
+		return
+	}
+	flight := &pageFlight{done: make(chan struct{}), res: &bufferResponse{header: http.Header{}}}
+	if f.flights == nil {
+		f.flights = make(map[string]*pageFlight)
+	}
+	f.flights[key] = flight
+	f.mu.Unlock()
+
+	func() {
+		// Release waiters even if the render panics
+		defer func() {
+			f.mu.Lock()
+			delete(f.flights, key)
+			f.mu.Unlock()
+			close(flight.done)
+		}()
+		s.servePage(flight.res, r.WithContext(context.WithoutCancel(r.Context())), page, nil)
+	}()
+	flight.res.copyTo(w)
+}
+
+// loaderContextKey encodes the request context fields read by the page's
+// loaders, so requests that differ in them never share a render.
+func (s *appState) loaderContextKey(r *http.Request, page *PageDef) string {
+	if len(s.contextConfigs) == 0 {
+		return ""
+	}
+	var keys []string
+	for _, ld := range page.Loaders {
+		if proc, ok := s.handlers[ld.Procedure]; ok {
+			keys = append(keys, proc.ContextKeys...)
+		}
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	fields, _ := json.Marshal(resolveContextForProc(extractRawContext(r, s.contextConfigs), keys))
+	return "\x00" + string(fields)
+}
+
+// copyTo replays a buffered response; cookies are never shared.
+func (b *bufferResponse) copyTo(w http.ResponseWriter) {
+	for k, vs := range b.header {
+		if k == "Set-Cookie" {
+			continue
+		}
+		w.Header()[k] = append([]string(nil), vs...)
+	}
+	status := b.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	_, _ = w.Write(b.body.Bytes())
+}
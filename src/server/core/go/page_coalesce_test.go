@@ -0,0 +1,122 @@
+/* src/server/core/go/page_coalesce_test.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func coalesceTestHandler(coalesce bool, loads *atomic.Int32, release <-chan struct{}) http.Handler {
+	return NewRouter().
+		Procedure(Query("getHot", func(context.Context, struct{}) (string, error) {
+			loads.Add(1)
+			<-release
+			return "hot", nil
+		})).
+		Page(&PageDef{
+			Route:    "/hot",
+			Template: "<html><body><!--seam:hot--></body></html>",
+			Loaders:  []LoaderDef{{DataKey: "hot", Procedure: "getHot", InputFn: func(map[string]string) any { return map[string]any{} }}},
+			Coalesce: coalesce,
+		}).
+		Handler(HandlerOptions{Principal: func(r *http.Request) string { return r.Header.Get("X-User") }})
+}
+
+func burst(h http.Handler, n int, header http.Header) []*httptest.ResponseRecorder {
+	out := make([]*httptest.ResponseRecorder, n)
+	var wg sync.WaitGroup
+	for i := range out {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			req := httptest.NewRequest("GET", "/_seam/page/hot", nil)
+			for k, vs := range header {
+				req.Header[k] = vs
+			}
+			out[i] = httptest.NewRecorder()
+			h.ServeHTTP(out[i], req)
+		}(i)
+	}
+	wg.Wait()
+	return out
+}
+
+func TestCoalescedPageRendersOnce(t *testing.T) {
+	var loads atomic.Int32
+	release := make(chan struct{})
+	h := coalesceTestHandler(true, &loads, release)
+	time.AfterFunc(50*time.Millisecond, func() { close(release) })
+
+	for i, w := range burst(h, 20, nil) {
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "hot") || w.Header().Get("Content-Type") == "" {
+			t.Fatalf("response %d: %d %v %s", i, w.Code, w.Header(), w.Body.String())
+		}
+	}
+	if n := loads.Load(); n != 1 {
+		t.Fatalf("loader ran %d times, want 1", n)
+	}
+}
+
+func TestCoalesceSkipsPersonalizedRequests(t *testing.T) {
+	var loads atomic.Int32
+	release := make(chan struct{})
+	close(release)
+	h := coalesceTestHandler(true, &loads, release)
+	burst(h, 5, http.Header{"X-User": {"alice"}})
+	if n := loads.Load(); n != 5 {
+		t.Fatalf("loader ran %d times, want 5", n)
+	}
+}
+
+func TestCoalesceKeysOnLoaderContext(t *testing.T) {
+	var loads atomic.Int32
+	release := make(chan struct{})
+	h := NewRouter().
+		Context("tenant", ContextConfig{Extract: "header:x-tenant"}).
+		Procedure(&ProcedureDef{
+			Name:        "getTenant",
+			ContextKeys: []string{"tenant"},
+			Handler: func(ctx context.Context, _ json.RawMessage) (any, error) {
+				loads.Add(1)
+				<-release
+				tenant, _ := ContextValue[string](ctx, "tenant")
+				return tenant, nil
+			},
+		}).
+		Page(&PageDef{
+			Route:    "/hot",
+			Template: "<html><body><!--seam:tenant--></body></html>",
+			Loaders:  []LoaderDef{{DataKey: "tenant", Procedure: "getTenant", InputFn: func(map[string]string) any { return map[string]any{} }}},
+			Coalesce: true,
+		}).
+		Handler(HandlerOptions{})
+	time.AfterFunc(50*time.Millisecond, func() { close(release) })
+
+	var acme, globex []*httptest.ResponseRecorder
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() { defer wg.Done(); acme = burst(h, 5, http.Header{"X-Tenant": {"acme"}}) }()
+	go func() { defer wg.Done(); globex = burst(h, 5, http.Header{"X-Tenant": {"globex"}}) }()
+	wg.Wait()
+	for _, w := range acme {
+		if !strings.Contains(w.Body.String(), "<body>acme") {
+			t.Fatalf("acme got %s", w.Body.String())
+		}
+	}
+	for _, w := range globex {
+		if !strings.Contains(w.Body.String(), "<body>globex") {
+			t.Fatalf("globex got %s", w.Body.String())
+		}
+	}
+	if n := loads.Load(); n != 2 {
+		t.Fatalf("loader ran %d times, want 2", n)
+	}
+}
@@ -0,0 +1,223 @@
+/* src/server/core/go/page_form.go */
+
+package seam
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// maxFormMemory bounds multipart form parsing; larger parts spill to disk.
+const maxFormMemory = 32 << 20
+
+// PageForm routes classic form submissions (PageDef.Methods other than
+// GET) to a command. Form fields become the command input, coerced to the
+// command's input schema (numbers, booleans, repeated fields as arrays);
+// route params fill fields the form does not send. File parts are
+// ignored; use an upload procedure for files.
+//
+// On success the client is redirected to Redirect with 303 See Other
+// (post/redirect/get), or the page is re-rendered when Redirect is empty.
+// On failure the page is re-rendered with the error status. Either way a
+// re-render exposes `_form` to slots: {values, ok, result, error}.
+type PageForm struct {
+	Command          string
+	Redirect         string // may reference route params (":id"); "" re-renders the page
+	AllowCrossOrigin bool   // skip the Origin check (cross-site form posts are rejected by default)
+}
+
+// formResult is the `_form` data injected when re-rendering after a submission.
+type formResult struct {
+	Values map[string]any `json:"values"`
+	OK     bool           `json:"ok"`
+	Result any            `json:"result,omitempty"`
+	Error  *Error         `json:"error,omitempty"`
+}
+
+// pageMethods returns the non-GET methods a page accepts. Panics when a
+// page accepts form methods without a PageForm.
+func pageMethods(page *PageDef) []string {
+	var methods []string
+	for _, m := range page.Methods {
+		m = strings.ToUpper(m)
+		if m == http.MethodGet || m == http.MethodHead || slices.Contains(methods, m) {
+			continue
+		}
+		methods = append(methods, m)
+	}
+	if len(methods) > 0 && (page.Form == nil || page.Form.Command == "") {
+		panic(fmt.Sprintf("page %s accepts %s but has no Form.Command", page.Route, strings.Join(methods, ", ")))
+	}
+	return methods
+}
+
+func (s *appState) makePageFormHandler(page *PageDef) http.Handler {
+	var h http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.handlePageForm(w, r, page)
+	})
+	for i := len(page.Middleware) - 1; i >= 0; i-- {
+		h = page.Middleware[i](h)
+	}
+	return h
+}
+
+func (s *appState) handlePageForm(w http.ResponseWriter, r *http.Request, page *PageDef) {
+	form := page.Form
+	if !form.AllowCrossOrigin && !sameOrigin(r) {
+		writeError(w, http.StatusForbidden, ForbiddenError("Cross-origin form submission rejected"))
+		return
+	}
+
+	values, err := parseFormValues(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ValidationError("Invalid form body: "+err.Error()))
+		return
+	}
+
+	proc, ok := s.handlers[form.Command]
+	if !ok {
+		writeError(w, http.StatusInternalServerError, InternalError(fmt.Sprintf("Procedure '%s' not found", form.Command)))
+		return
+	}
+	params := extractParams(page.Route, r)
+	input := coerceFormInput(values, params, proc.InputSchema)
+	body, _ := json.Marshal(input)
+
+	result, seamErr := s.callProcedure(r, form.Command, proc, body)
+	if seamErr == nil && form.Redirect != "" {
+		http.Redirect(w, r, expandRouteParams(form.Redirect, params), http.StatusSeeOther)
+		return
+	}
+	s.servePage(w, r, page, &formResult{Values: input, OK: seamErr == nil, Result: result, Error: seamErr})
+}
+
+// sameOrigin accepts requests without an Origin header (non-browser
+// clients, older browsers) and those whose Origin host matches the
+// request host.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return r.Header.Get("Sec-Fetch-Site") != "cross-site"
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	host := r.Host
+	if fwd := r.Header.Get("X-Forwarded-Host"); fwd != "" {
+		host = fwd
+	}
+	return u.Host == host
+}
+
+func parseFormValues(r *http.Request) (url.Values, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		if err := r.ParseMultipartForm(maxFormMemory); err != nil {
+			return nil, err
+		}
+		return url.Values(r.MultipartForm.Value), nil
+	}
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+	return r.PostForm, nil
+}
+
+// coerceFormInput converts form values into a JSON object guided by the
+// JTD input schema. Without a schema entry, single values stay strings
+// and repeated values become string arrays.
+func coerceFormInput(values url.Values, params map[string]string, schema any) map[string]any {
+	props := map[string]any{}
+	var required map[string]any
+	if m, ok := schema.(map[string]any); ok {
+		required, _ = m["properties"].(map[string]any)
+		optional, _ := m["optionalProperties"].(map[string]any)
+		for _, p := range []map[string]any{required, optional} {
+			for k, v := range p {
+				props[k] = v
+			}
+		}
+	}
+
+	input := make(map[string]any, len(values)+len(params))
+	// HTML omits unchecked checkboxes: required booleans default to false
+	for k, v := range required {
+		if jtdType(v) == "boolean" {
+			input[k] = false
+		}
+	}
+	for k, v := range params {
+		input[k] = coerceFormValue(v, props[k])
+	}
+	for k, vs := range values {
+		fieldSchema := props[k]
+		m, _ := fieldSchema.(map[string]any)
+		if elems, ok := m["elements"]; ok {
+			arr := make([]any, len(vs))
+			for i, v := range vs {
+				arr[i] = coerceFormValue(v, elems)
+			}
+			input[k] = arr
+			continue
+		}
+		if len(vs) == 1 || fieldSchema != nil {
+			input[k] = coerceFormValue(vs[0], fieldSchema)
+			continue
+		}
+		arr := make([]any, len(vs))
+		for i, v := range vs {
+			arr[i] = v
+		}
+		input[k] = arr
+	}
+	return input
+}
+
+func jtdType(schema any) string {
+	m, _ := schema.(map[string]any)
+	t, _ := m["type"].(string)
+	return t
+}
+
+// coerceFormValue converts a form string to the JTD type; values that do
+// not parse stay strings so validation reports them.
+func coerceFormValue(v string, schema any) any {
+	switch jtdType(schema) {
+	case "boolean":
+		switch strings.ToLower(v) {
+		case "", "false", "0", "off", "no":
+			return false
+		case "true", "1", "on", "yes":
+			return true
+		}
+	case "int8", "int16", "int32", "uint8", "uint16", "uint32":
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n
+		}
+	case "float32", "float64":
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	}
+	return v
+}
+
+// expandRouteParams substitutes ":name" segments in target with params.
+func expandRouteParams(target string, params map[string]string) string {
+	parts := strings.Split(target, "/")
+	for i, p := range parts {
+		if strings.HasPrefix(p, ":") {
+			if v, ok := params[p[1:]]; ok {
+				parts[i] = url.PathEscape(v)
+			}
+		}
+	}
+	return strings.Join(parts, "/")
+}
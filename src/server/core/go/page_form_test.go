@@ -0,0 +1,110 @@
+/* src/server/core/go/page_form_test.go */
+
+package seam
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type formTestInput struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Age       int32  `json:"age"`
+	Subscribe bool   `json:"subscribe"`
+}
+
+func formTestHandler(redirect string) (http.Handler, *formTestInput) {
+	var got formTestInput
+	router := NewRouter().
+		Procedure(Command("updateProfile", func(_ context.Context, in formTestInput) (map[string]bool, error) {
+			if in.Name == "" {
+				return nil, ValidationError("Name is required")
+			}
+			got = in
+			return map[string]bool{"saved": true}, nil
+		})).
+		Page(&PageDef{
+			Route:    "/users/:id/edit",
+			Template: `<html><body><!--seam:if:_form.error--><p class="err"><!--seam:_form.error.message--></p><!--seam:endif:_form.error--><input value="<!--seam:_form.values.name-->"></body></html>`,
+			Methods:  []string{"GET", "POST"},
+			Form:     &PageForm{Command: "updateProfile", Redirect: redirect},
+		})
+	return router.Handler(), &got
+}
+
+func postForm(h http.Handler, form url.Values, origin string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/_seam/page/users/42/edit", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestPageFormRedirectsAfterPost(t *testing.T) {
+	handler, got := formTestHandler("/users/:id")
+	w := postForm(handler, url.Values{"name": {"Alice"}, "age": {"31"}}, "http://example.com")
+
+	if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/users/42" {
+		t.Fatalf("expected 303 to /users/42, got %d %q", w.Code, w.Header().Get("Location"))
+	}
+	want := formTestInput{ID: "42", Name: "Alice", Age: 31, Subscribe: false}
+	if *got != want {
+		t.Errorf("command input = %+v, want %+v", *got, want)
+	}
+}
+
+func TestPageFormRerendersWithError(t *testing.T) {
+	handler, _ := formTestHandler("/users/:id")
+	w := postForm(handler, url.Values{"name": {""}, "age": {"31"}}, "")
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status %d, want 400", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), `<p class="err">Name is required</p>`) {
+		t.Errorf("expected error in re-rendered page, got %s", w.Body.String())
+	}
+}
+
+func TestPageFormRejectsCrossOrigin(t *testing.T) {
+	handler, _ := formTestHandler("")
+	w := postForm(handler, url.Values{"name": {"Mallory"}}, "https://evil.test")
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("status %d, want 403", w.Code)
+	}
+}
+
+func TestCoerceFormInput(t *testing.T) {
+	schema := map[string]any{
+		"properties": map[string]any{
+			"done": map[string]any{"type": "boolean"},
+			"qty":  map[string]any{"type": "uint16"},
+		},
+		"optionalProperties": map[string]any{
+			"tags":  map[string]any{"elements": map[string]any{"type": "string"}},
+			"price": map[string]any{"type": "float64"},
+		},
+	}
+	got := coerceFormInput(url.Values{"qty": {"3"}, "tags": {"a", "b"}, "price": {"9.5"}, "extra": {"x", "y"}}, nil, schema)
+	want := map[string]any{"done": false, "qty": int64(3), "tags": []any{"a", "b"}, "price": 9.5, "extra": []any{"x", "y"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("coerceFormInput = %#v, want %#v", got, want)
+	}
+}
+
+func TestPageMethodsRequiresForm(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic")
+		}
+	}()
+	pageMethods(&PageDef{Route: "/x", Methods: []string{"POST"}})
+}
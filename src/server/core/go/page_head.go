@@ -0,0 +1,125 @@
+/* src/server/core/go/page_head.go */
+
+package seam
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HEAD requests on page routes (uptime checkers, link validators) get the
+// headers of the GET response, including its Content-Length, without a
+// body. Prerendered pages are answered from the static file's size. Other
+// pages are rendered once into a buffer; with HandlerOptions.HeadCacheTTL
+// the resulting status and headers are reused per render cache key and
+// loader context fields, so repeated HEADs of anonymous requests (no
+// principal from HandlerOptions.Principal or a signed URL) skip the loaders
+// entirely.
+
+const maxHeadCacheEntries = 1024
+
+type headCache struct {
+	mu      sync.Mutex
+	entries map[string]headEntry
+}
+
+type headEntry struct {
+	status  int
+	header  http.Header
+	expires time.Time
+}
+
+func (c *headCache) get(key string, now time.Time) (headEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok || now.After(e.expires) {
+		return headEntry{}, false
+	}
+	return e, true
+}
+
+func (c *headCache) set(key string, e headEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]headEntry)
+	}
+	if len(c.entries) >= maxHeadCacheEntries {
+		now := time.Now()
+		for k, old := range c.entries {
+			if now.After(old.expires) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= maxHeadCacheEntries {
+			clear(c.entries)
+		}
+	}
+	c.entries[key] = e
+}
+
+// prerenderedPath returns the static HTML file of a prerendered page
+// request, if the page has one.
+func prerenderedPath(page *PageDef, r *http.Request) (string, bool) {
+	if !page.Prerender || page.StaticDir == "" {
+		return "", false
+	}
+	// Strip /_seam/page prefix and optional locale prefix
+	subPath := strings.TrimPrefix(r.URL.Path, "/_seam/page")
+	if subPath == "/" {
+		subPath = ""
+	}
+	return resolveStaticFilePath(page.StaticDir, subPath, "index.html")
+}
+
+// headPage answers a HEAD request on a page route.
+func (s *appState) headPage(w http.ResponseWriter, r *http.Request, page *PageDef) {
+	if path, ok := prerenderedPath(page, r); ok {
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			if page.Robots != "" {
+				w.Header().Set("X-Robots-Tag", page.Robots)
+			}
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Header().Set("Content-Length", strconv.FormatInt(info.Size(), 10))
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+	}
+
+	ttl := s.opts.HeadCacheTTL
+	key := ""
+	principal, _ := s.principal(r)
+	if locale, ok := s.pageLocale(r); ok && ttl > 0 && principal == "" {
+		key = page.Route + "\x00" + s.renderCacheKey(r, locale) + s.loaderContextKey(r, page)
+		if e, hit := s.headCache.get(key, time.Now()); hit {
+			writeHead(w, e.header, e.status)
+			return
+		}
+	}
+
+	buf := &bufferResponse{header: http.Header{}}
+	s.servePage(buf, r, page, nil)
+	status := buf.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	buf.header.Set("Content-Length", strconv.Itoa(buf.body.Len()))
+	if key != "" && status == http.StatusOK {
+		header := buf.header.Clone()
+		header.Del("Set-Cookie")
+		s.headCache.set(key, headEntry{status: status, header: header, expires: time.Now().Add(ttl)})
+	}
+	writeHead(w, buf.header, status)
+}
+
+func writeHead(w http.ResponseWriter, header http.Header, status int) {
+	for k, vs := range header {
+		w.Header()[k] = append([]string(nil), vs...)
+	}
+	w.WriteHeader(status)
+}
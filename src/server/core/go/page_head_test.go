@@ -0,0 +1,100 @@
+/* src/server/core/go/page_head_test.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHeadPageMatchesGet(t *testing.T) {
+	var calls atomic.Int32
+	page := &PageDef{
+		Route:    "/status",
+		Template: `<html><body><p><!--seam:status.text--></p></body></html>`,
+		Loaders:  []LoaderDef{{DataKey: "status", Procedure: "getStatus", InputFn: func(map[string]string) any { return struct{}{} }}},
+	}
+	h := NewRouter().
+		Procedure(Query("getStatus", func(context.Context, struct{}) (map[string]string, error) {
+			calls.Add(1)
+			return map[string]string{"text": "all good"}, nil
+		})).
+		Page(page).
+		Handler(HandlerOptions{HeadCacheTTL: time.Minute})
+
+	get := httptest.NewRecorder()
+	h.ServeHTTP(get, httptest.NewRequest("GET", "/_seam/page/status", nil))
+	for range 2 {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("HEAD", "/_seam/page/status", nil))
+		if w.Code != http.StatusOK || w.Body.Len() != 0 ||
+			w.Header().Get("Content-Length") != strconv.Itoa(get.Body.Len()) ||
+			w.Header().Get("Content-Type") != "text/html; charset=utf-8" {
+			t.Fatalf("HEAD: %d %v body %d bytes, GET %d bytes", w.Code, w.Header(), w.Body.Len(), get.Body.Len())
+		}
+	}
+	if n := calls.Load(); n != 2 { // GET + first HEAD; second HEAD is cached
+		t.Errorf("loader calls %d, want 2", n)
+	}
+}
+
+func TestHeadCacheSkipsPersonalizedRequests(t *testing.T) {
+	var calls atomic.Int32
+	page := &PageDef{
+		Route:    "/me",
+		Template: `<html><body><p><!--seam:me--></p></body></html>`,
+		Loaders:  []LoaderDef{{DataKey: "me", Procedure: "getMe", InputFn: func(map[string]string) any { return struct{}{} }}},
+	}
+	h := NewRouter().
+		Context("tenant", ContextConfig{Extract: "header:x-tenant"}).
+		Procedure(&ProcedureDef{
+			Name:        "getMe",
+			ContextKeys: []string{"tenant"},
+			Handler: func(ctx context.Context, _ json.RawMessage) (any, error) {
+				calls.Add(1)
+				tenant, _ := ContextValue[string](ctx, "tenant")
+				return PrincipalOf(ctx) + tenant, nil
+			},
+		}).
+		Page(page).
+		Handler(HandlerOptions{HeadCacheTTL: time.Minute})
+
+	head := func(principal, tenant string) string {
+		req := httptest.NewRequest("HEAD", "/_seam/page/me", nil)
+		if principal != "" {
+			req = req.WithContext(context.WithValue(req.Context(), signedPrincipalKey, principal))
+		}
+		req.Header.Set("X-Tenant", tenant)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w.Header().Get("Content-Length")
+	}
+	if a, b := head("al", "x"), head("alexandra", "x"); a == b {
+		t.Errorf("signed principals shared a cached HEAD: %s", a)
+	}
+	if a, b := head("", "acme"), head("", "globex-corporation"); a == b {
+		t.Errorf("loader context fields shared a cached HEAD: %s", a)
+	}
+	if n := calls.Load(); n != 4 {
+		t.Errorf("loader calls %d, want 4", n)
+	}
+}
+
+func TestHeadPrerenderedPage(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"about/index.html": "<p>about</p>"})
+	page := &PageDef{Route: "/about", Template: "<p>dynamic</p>", Prerender: true, StaticDir: dir}
+	h := NewRouter().Page(page).Handler()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("HEAD", "/_seam/page/about", nil))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Length") != "12" || w.Body.Len() != 0 {
+		t.Errorf("HEAD: %d %v %q", w.Code, w.Header(), w.Body.String())
+	}
+}
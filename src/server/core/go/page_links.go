@@ -0,0 +1,227 @@
+/* src/server/core/go/page_links.go */
+
+package seam
+
+import (
+	"context"
+	"html"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Pagination describes how a page is paginated, for rel=prev/next links.
+// The current page comes from the Page data path, else from Param (a
+// route param like /blog/:page or a query parameter), else 1; the last
+// page comes from the Pages data path.
+type Pagination struct {
+	Param string `json:"param"` // route param or query parameter carrying the page number (default "page")
+	Page  string `json:"page"`  // data path of the current page number, e.g. "posts.page" (optional)
+	Pages string `json:"pages"` // data path of the page count, e.g. "posts.totalPages"
+}
+
+func (p *Pagination) param() string {
+	if p.Param != "" {
+		return p.Param
+	}
+	return "page"
+}
+
+// PageLinks are the link relations of a rendered page.
+type PageLinks struct {
+	Canonical string `json:"canonical,omitempty"`
+	Prev      string `json:"prev,omitempty"`
+	Next      string `json:"next,omitempty"`
+}
+
+// HTML returns the <link> tags of the non-empty relations.
+func (l PageLinks) HTML() string {
+	var b strings.Builder
+	for _, rel := range [...]struct{ name, href string }{{"canonical", l.Canonical}, {"prev", l.Prev}, {"next", l.Next}} {
+		if rel.href != "" {
+			b.WriteString(`<link rel="` + rel.name + `" href="` + html.EscapeString(rel.href) + `">`)
+		}
+	}
+	return b.String()
+}
+
+// PaginationLinks computes the links of page (1-based) out of pages for
+// the page URL u: the canonical URL drops every query parameter but the
+// page number, which is omitted on the first page. When route (a seam
+// route like "/blog/:page") has a :param segment the page number is
+// written into the path instead of the query. pages <= 0 means unknown:
+// no next link.
+func PaginationLinks(u *url.URL, route, param string, page, pages int) PageLinks {
+	if page < 1 {
+		page = 1
+	}
+	at := func(n int) string {
+		v := *u
+		v.RawQuery, v.Fragment = "", ""
+		if seg := routeParamSegment(route, param, v.Path); seg >= 0 {
+			parts := strings.Split(v.Path, "/")
+			parts[seg] = strconv.Itoa(n)
+			v.Path, v.RawPath = strings.Join(parts, "/"), ""
+		} else if n > 1 {
+			v.RawQuery = url.Values{param: {strconv.Itoa(n)}}.Encode()
+		}
+		return v.String()
+	}
+	links := PageLinks{Canonical: at(page)}
+	if page > 1 {
+		links.Prev = at(page - 1)
+	}
+	if page < pages {
+		links.Next = at(page + 1)
+	}
+	return links
+}
+
+// routeParamSegment returns the index of the :param segment of route in
+// path, aligning route with the end of path (a locale prefix may precede
+// it), or -1.
+func routeParamSegment(route, param, path string) int {
+	if route == "" {
+		return -1
+	}
+	routeParts := strings.Split(route, "/")
+	pathParts := strings.Split(path, "/")
+	offset := len(pathParts) - len(routeParts)
+	if offset < 0 {
+		return -1
+	}
+	for i, p := range routeParts {
+		if p == ":"+param {
+			return offset + i
+		}
+	}
+	return -1
+}
+
+var canonicalLinkTag = regexp.MustCompile(`(?i)<link\s[^>]*rel\s*=\s*["']?canonical["']?`)
+
+type pageLinksKey struct{}
+
+// pageLinks computes the links of a page response: rel=canonical when
+// HandlerOptions.CanonicalLinks and SiteURL are set, rel=prev/next when
+// the page has Pagination. Without SiteURL, prev/next are root-relative.
+func (s *appState) pageLinks(page *PageDef, path string, query url.Values, params map[string]string, data map[string]any) (PageLinks, bool) {
+	canonical := s.opts.CanonicalLinks && s.opts.SiteURL != ""
+	if !canonical && page.Pagination == nil {
+		return PageLinks{}, false
+	}
+	u, err := url.Parse(strings.TrimSuffix(s.opts.SiteURL, "/") + path)
+	if err != nil {
+		return PageLinks{}, false
+	}
+	if page.Pagination == nil {
+		return PageLinks{Canonical: u.String()}, true
+	}
+	p := page.Pagination
+	current, ok := pageDataInt(data, p.Page)
+	if !ok {
+		raw := params[p.param()]
+		if raw == "" {
+			raw = query.Get(p.param())
+		}
+		current, _ = strconv.Atoi(raw)
+	}
+	pages, _ := pageDataInt(data, p.Pages)
+	links := PaginationLinks(u, page.Route, p.param(), current, pages)
+	if !canonical {
+		links.Canonical = ""
+	}
+	return links, true
+}
+
+// pageDataInt reads an integer at a dotted path of page data, walking
+// maps and loader result structs (by JSON field name) in place.
+func pageDataInt(data map[string]any, path string) (int, bool) {
+	if path == "" {
+		return 0, false
+	}
+	v := reflect.ValueOf(data)
+	for _, seg := range strings.Split(path, ".") {
+		if v = dataField(v, seg); !v.IsValid() {
+			return 0, false
+		}
+	}
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return 0, false
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return int(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		return int(f), f == float64(int(f))
+	case reflect.String:
+		i, err := strconv.Atoi(v.String())
+		return i, err == nil
+	}
+	return 0, false
+}
+
+// dataField returns the member name of a string-keyed map or the field
+// JSON-encoded as name of a struct, or an invalid Value.
+func dataField(v reflect.Value, name string) reflect.Value {
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return reflect.Value{}
+		}
+		return v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+	case reflect.Struct:
+		for i := range v.NumField() {
+			f := v.Type().Field(i)
+			if !f.IsExported() && !f.Anonymous {
+				continue
+			}
+			if f.Anonymous && f.Tag.Get("json") == "" {
+				if inner := dataField(v.Field(i), name); inner.IsValid() {
+					return inner
+				}
+				continue
+			}
+			if key, _ := jsonFieldName(&f); key == name {
+				return v.Field(i)
+			}
+		}
+	}
+	return reflect.Value{}
+}
+
+func withPageLinks(ctx context.Context, links PageLinks) context.Context {
+	return context.WithValue(ctx, pageLinksKey{}, links)
+}
+
+// injectPageLinks adds the page's link tags from ctx before </head>,
+// keeping a canonical link the template already has.
+func injectPageLinks(ctx context.Context, doc string) string {
+	links, ok := ctx.Value(pageLinksKey{}).(PageLinks)
+	if !ok {
+		return doc
+	}
+	if canonicalLinkTag.MatchString(doc) {
+		links.Canonical = ""
+	}
+	tags := links.HTML()
+	i := strings.Index(strings.ToLower(doc), "</head>")
+	if tags == "" || i < 0 {
+		return doc
+	}
+	return doc[:i] + tags + doc[i:]
+}
@@ -0,0 +1,122 @@
+/* src/server/core/go/page_links_test.go */
+
+package seam
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestPaginationLinks(t *testing.T) {
+	u, _ := url.Parse("https://example.com/blog?page=2&utm_source=x")
+	got := PaginationLinks(u, "/blog", "page", 2, 3)
+	want := PageLinks{
+		Canonical: "https://example.com/blog?page=2",
+		Prev:      "https://example.com/blog",
+		Next:      "https://example.com/blog?page=3",
+	}
+	if got != want {
+		t.Errorf("query param: %+v", got)
+	}
+
+	u, _ = url.Parse("/de/archive/3")
+	got = PaginationLinks(u, "/archive/:n", "n", 3, 3)
+	want = PageLinks{Canonical: "/de/archive/3", Prev: "/de/archive/2"}
+	if got != want {
+		t.Errorf("route param: %+v", got)
+	}
+
+	if got := PaginationLinks(u, "", "page", 0, 0); got.Prev != "" || got.Next != "" {
+		t.Errorf("first page of unknown count: %+v", got)
+	}
+	if html := want.HTML(); html != `<link rel="canonical" href="/de/archive/3"><link rel="prev" href="/de/archive/2">` {
+		t.Errorf("HTML() = %s", html)
+	}
+}
+
+type linkPosts struct {
+	Page       int `json:"page"`
+	TotalPages int `json:"totalPages"`
+}
+
+func TestPageLinkTags(t *testing.T) {
+	empty := func(map[string]string) any { return struct{}{} }
+	h := NewRouter().
+		Procedure(Query("listPosts", func(context.Context, struct{}) (linkPosts, error) {
+			return linkPosts{Page: 2, TotalPages: 4}, nil
+		})).
+		Page(&PageDef{
+			Route:      "/posts",
+			Template:   `<html><head><title>Posts</title></head><body></body></html>`,
+			Loaders:    []LoaderDef{{DataKey: "posts", Procedure: "listPosts", InputFn: empty}},
+			Pagination: &Pagination{Page: "posts.page", Pages: "posts.totalPages"},
+		}).
+		Page(&PageDef{Route: "/tags/:page", Template: `<html><head></head><body></body></html>`, Pagination: &Pagination{}}).
+		Page(&PageDef{Route: "/about", Template: `<html><head><link rel="canonical" href="https://example.com/about-us"></head><body></body></html>`}).
+		Handler(HandlerOptions{SiteURL: "https://example.com", CanonicalLinks: true})
+	get := func(path string) string {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w.Body.String()
+	}
+
+	body := get("/_seam/page/posts?page=9")
+	want := `<link rel="canonical" href="https://example.com/posts?page=2"><link rel="prev" href="https://example.com/posts"><link rel="next" href="https://example.com/posts?page=3"></head>`
+	if !strings.Contains(body, want) {
+		t.Errorf("loader data pagination: %s", body)
+	}
+
+	body = get("/_seam/page/tags/1")
+	if !strings.Contains(body, `<link rel="canonical" href="https://example.com/tags/1"></head>`) || strings.Contains(body, `rel="prev"`) {
+		t.Errorf("route param pagination: %s", body)
+	}
+
+	body = get("/_seam/page/about")
+	if strings.Count(body, `rel="canonical"`) != 1 {
+		t.Errorf("template canonical duplicated: %s", body)
+	}
+}
+
+func TestCanonicalLinksAreOptIn(t *testing.T) {
+	h := NewRouter().
+		Page(&PageDef{Route: "/about", Template: `<html><head></head><body></body></html>`}).
+		Page(&PageDef{Route: "/tags/:page", Template: `<html><head></head><body></body></html>`, Pagination: &Pagination{}}).
+		Handler(HandlerOptions{SiteURL: "https://example.com"})
+	get := func(path string) string {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w.Body.String()
+	}
+	if body := get("/_seam/page/about"); strings.Contains(body, "<link") {
+		t.Errorf("SiteURL alone added links: %s", body)
+	}
+	body := get("/_seam/page/tags/2")
+	if strings.Contains(body, `rel="canonical"`) || !strings.Contains(body, `<link rel="prev" href="https://example.com/tags/1">`) {
+		t.Errorf("pagination without canonical: %s", body)
+	}
+}
+
+func TestPageDataIntWalksLoaderResults(t *testing.T) {
+	type meta struct {
+		Total int64 `json:"total"`
+	}
+	data := map[string]any{
+		"posts": &struct {
+			meta
+			Page float64 `json:"page"`
+			Raw  string  `json:"raw"`
+		}{meta: meta{Total: 7}, Page: 3, Raw: "4"},
+		"plain": map[string]any{"n": 5},
+	}
+	for path, want := range map[string]int{"posts.total": 7, "posts.page": 3, "posts.raw": 4, "plain.n": 5} {
+		if got, ok := pageDataInt(data, path); !ok || got != want {
+			t.Errorf("%s = %d, %v", path, got, ok)
+		}
+	}
+	if _, ok := pageDataInt(data, "posts.missing"); ok {
+		t.Error("missing path found")
+	}
+}
@@ -0,0 +1,148 @@
+/* src/server/core/go/password_protect.go */
+
+package seam
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// unlockPath receives the passphrase form posted by the lock page.
+const unlockPath = "/_seam/unlock"
+
+// PasswordProtect guards page routes (pages, page data, public files) for
+// preview/staging deployments; RPC endpoints are not affected. Set
+// Username+Password for HTTP basic auth, Passphrase for a shared
+// passphrase form that sets a cookie, or both (either unlocks).
+type PasswordProtect struct {
+	Username   string
+	Password   string
+	Passphrase string
+	Realm      string        // basic auth realm (default "Restricted")
+	CookieName string        // passphrase cookie (default "seam_passphrase")
+	MaxAge     time.Duration // passphrase cookie lifetime (default 7 days)
+}
+
+func (p *PasswordProtect) cookieName() string {
+	if p.CookieName != "" {
+		return p.CookieName
+	}
+	return "seam_passphrase"
+}
+
+// cookieValue is derived from the passphrase, so changing the passphrase
+// invalidates every issued cookie.
+func (p *PasswordProtect) cookieValue() string {
+	mac := hmac.New(sha256.New, []byte(p.Passphrase))
+	mac.Write([]byte("seam-passphrase"))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func secureEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+func (p *PasswordProtect) authorized(r *http.Request) bool {
+	if p.Password != "" {
+		if user, pass, ok := r.BasicAuth(); ok && secureEqual(user, p.Username) && secureEqual(pass, p.Password) {
+			return true
+		}
+	}
+	if p.Passphrase != "" {
+		if c, err := r.Cookie(p.cookieName()); err == nil && secureEqual(c.Value, p.cookieValue()) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *appState) passwordMiddleware(p *PasswordProtect, next http.Handler) http.Handler {
+	if p.Password == "" && p.Passphrase == "" {
+		panic("PasswordProtect requires Password or Passphrase")
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == unlockPath && p.Passphrase != "" {
+			p.unlock(w, r)
+			return
+		}
+		switch class, _ := s.classifyRoute(r); class {
+		case RoutePage, RoutePageData, RouteStatic:
+		default:
+			next.ServeHTTP(w, r)
+			return
+		}
+		if p.authorized(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if p.Passphrase == "" {
+			realm := p.Realm
+			if realm == "" {
+				realm = "Restricted"
+			}
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf("Basic realm=%q, charset=\"UTF-8\"", realm))
+			writeError(w, http.StatusUnauthorized, UnauthorizedError("Authentication required"))
+			return
+		}
+		p.writeLockPage(w, r, false)
+	})
+}
+
+// unlock checks the posted passphrase and redirects back to the page.
+func (p *PasswordProtect) unlock(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, NewError("METHOD_NOT_ALLOWED", "Use POST", http.StatusMethodNotAllowed))
+		return
+	}
+	if !secureEqual(r.PostFormValue("passphrase"), p.Passphrase) {
+		p.writeLockPage(w, r, true)
+		return
+	}
+	maxAge := p.MaxAge
+	if maxAge <= 0 {
+		maxAge = 7 * 24 * time.Hour
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     p.cookieName(),
+		Value:    p.cookieValue(),
+		Path:     "/",
+		MaxAge:   int(maxAge.Seconds()),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	next := r.PostFormValue("next")
+	// Only local paths: reject scheme-relative and absolute URLs
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		next = "/"
+	}
+	http.Redirect(w, r, next, http.StatusSeeOther)
+}
+
+// writeLockPage renders the passphrase form. next is the browser-visible
+// URL of the requested page (without the /_seam/page prefix).
+func (p *PasswordProtect) writeLockPage(w http.ResponseWriter, r *http.Request, failed bool) {
+	next := r.PostFormValue("next")
+	if r.URL.Path != unlockPath {
+		next = strings.TrimPrefix(r.URL.RequestURI(), "/_seam/page")
+	}
+	msg := ""
+	if failed {
+		msg = `<p role="alert">Incorrect passphrase.</p>`
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusUnauthorized)
+	fmt.Fprintf(w, `<!DOCTYPE html><html><head><meta charset="utf-8"><meta name="robots" content="noindex"><title>Protected</title></head>`+
+		`<body><form method="post" action="%s">%s<input type="hidden" name="next" value="%s">`+
+		`<input type="password" name="passphrase" autofocus required><button type="submit">Enter</button></form></body></html>`,
+		unlockPath, msg, html.EscapeString(next))
+}
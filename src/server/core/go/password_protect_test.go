@@ -0,0 +1,94 @@
+/* src/server/core/go/password_protect_test.go */
+
+package seam
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func passwordTestRouter() *Router {
+	return NewRouter().
+		Procedure(Query("ping", func(_ context.Context, _ struct{}) (string, error) { return "pong", nil })).
+		Page(&PageDef{Route: "/", Template: "<html><body>home</body></html>"})
+}
+
+func TestPasswordProtectBasicAuth(t *testing.T) {
+	handler := passwordTestRouter().Handler(HandlerOptions{
+		PasswordProtect: &PasswordProtect{Username: "preview", Password: "s3cret"},
+	})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/_seam/page/", nil))
+	if w.Code != http.StatusUnauthorized || !strings.HasPrefix(w.Header().Get("WWW-Authenticate"), "Basic ") {
+		t.Fatalf("expected basic auth challenge, got %d %q", w.Code, w.Header().Get("WWW-Authenticate"))
+	}
+
+	req := httptest.NewRequest("GET", "/_seam/page/", nil)
+	req.SetBasicAuth("preview", "s3cret")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("valid credentials: status %d", w.Code)
+	}
+
+	req.SetBasicAuth("preview", "wrong")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("wrong password: status %d", w.Code)
+	}
+
+	if code := postStatus(handler, "ping", `{}`); code != http.StatusOK {
+		t.Fatalf("RPC must not be protected, got %d", code)
+	}
+}
+
+func TestPasswordProtectPassphrase(t *testing.T) {
+	handler := passwordTestRouter().Handler(HandlerOptions{
+		PasswordProtect: &PasswordProtect{Passphrase: "open sesame"},
+	})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/_seam/page/?tab=1", nil))
+	if w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), `name="next" value="/?tab=1"`) {
+		t.Fatalf("expected lock page, got %d %s", w.Code, w.Body.String())
+	}
+
+	unlock := func(pass, next string) *httptest.ResponseRecorder {
+		form := url.Values{"passphrase": {pass}, "next": {next}}
+		req := httptest.NewRequest("POST", unlockPath, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := unlock("wrong", "/"); w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), "Incorrect") {
+		t.Fatalf("wrong passphrase: %d", w.Code)
+	}
+	if w := unlock("open sesame", "//evil.example"); w.Header().Get("Location") != "/" {
+		t.Fatalf("open redirect not rejected: %q", w.Header().Get("Location"))
+	}
+
+	w = unlock("open sesame", "/?tab=1")
+	if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/?tab=1" {
+		t.Fatalf("unlock: %d %q", w.Code, w.Header().Get("Location"))
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("expected passphrase cookie, got %v", cookies)
+	}
+
+	req := httptest.NewRequest("GET", "/_seam/page/", nil)
+	req.AddCookie(cookies[0])
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("cookie should unlock pages, got %d", w.Code)
+	}
+}
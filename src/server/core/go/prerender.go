@@ -0,0 +1,132 @@
+/* src/server/core/go/prerender.go */
+
+package seam
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	engine "github.com/canmi21/seam/src/server/engine/go"
+)
+
+type renderSessionKeyType struct{}
+
+var renderSessionKey = renderSessionKeyType{}
+
+// PrerenderOptions selects the pages Router.Prerender renders.
+type PrerenderOptions struct {
+	Handler http.Handler // handler to render through (default: a fresh r.Handler())
+	Routes  []string     // concrete page paths ("/", "/blog/hello")
+	Locales []string     // locales per route (default: every configured locale)
+}
+
+// PrerenderedPage is the rendered HTML of one route in one locale.
+type PrerenderedPage struct {
+	Path   string // request path, e.g. "/_seam/page/de/blog/hello"
+	Route  string
+	Locale string
+	Status int
+	HTML   []byte
+	Err    error
+}
+
+// Prerender renders each route in every locale for static export or ISR
+// jobs. All locales of one route share a single engine instance instead
+// of instantiating WASM per render; routes render in parallel. Pages that
+// fail (status >= 400) carry Err and are joined into the returned error.
+func (r *Router) Prerender(ctx context.Context, opts PrerenderOptions) ([]PrerenderedPage, error) {
+	h := opts.Handler
+	if h == nil {
+		h = r.Handler()
+	}
+	locales := opts.Locales
+	if locales == nil && r.i18nConfig != nil {
+		locales = r.i18nConfig.Locales
+	}
+	if len(locales) == 0 {
+		locales = []string{""}
+	}
+
+	pages := make([]PrerenderedPage, len(opts.Routes)*len(locales))
+	parallelEach(len(opts.Routes), func(i int) {
+		route := opts.Routes[i]
+		session, err := engine.NewSession()
+		if err != nil {
+			for j, locale := range locales {
+				pages[i*len(locales)+j] = PrerenderedPage{Path: seamPagePath(route, locale), Route: route, Locale: locale, Err: err}
+			}
+			return
+		}
+		defer session.Close()
+		renderCtx := context.WithValue(ctx, renderSessionKey, session)
+		for j, locale := range locales {
+			pages[i*len(locales)+j] = prerenderOne(renderCtx, h, route, locale)
+		}
+	})
+
+	var errs []error
+	for _, p := range pages {
+		if p.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", p.Path, p.Err))
+		}
+	}
+	return pages, errors.Join(errs...)
+}
+
+func prerenderOne(ctx context.Context, h http.Handler, route, locale string) PrerenderedPage {
+	page := PrerenderedPage{Path: seamPagePath(route, locale), Route: route, Locale: locale}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, page.Path, nil)
+	if err != nil {
+		page.Err = err
+		return page
+	}
+	w := &bufferResponse{header: http.Header{}}
+	h.ServeHTTP(w, req)
+	page.Status, page.HTML = w.status, w.body.Bytes()
+	if page.Status >= 400 {
+		page.Err = fmt.Errorf("status %d", page.Status)
+	}
+	return page
+}
+
+// seamPagePath maps a page path and optional locale to its /_seam/page URL.
+func seamPagePath(route, locale string) string {
+	if locale != "" {
+		return "/_seam/page/" + locale + route
+	}
+	return "/_seam/page" + route
+}
+
+// renderWithEngine renders through the request's engine session when a
+// batch job attached one, else on a fresh engine instance.
+func renderWithEngine(ctx context.Context, tmpl, loaderDataJSON, configJSON, i18nOptsJSON string) (string, error) {
+	if session, ok := ctx.Value(renderSessionKey).(*engine.Session); ok {
+		return session.RenderPageContext(ctx, tmpl, loaderDataJSON, configJSON, i18nOptsJSON)
+	}
+	return engine.RenderPageContext(ctx, tmpl, loaderDataJSON, configJSON, i18nOptsJSON)
+}
+
+// bufferResponse is a ResponseWriter capturing status and body in memory.
+type bufferResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferResponse) Header() http.Header { return w.header }
+
+func (w *bufferResponse) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(b)
+}
+
+func (w *bufferResponse) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
@@ -0,0 +1,52 @@
+/* src/server/core/go/prerender_test.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	engine "github.com/canmi21/seam/src/server/engine/go"
+)
+
+func TestPrerenderLocalesShareSession(t *testing.T) {
+	router := NewRouter().
+		Procedure(Query("getTitle", func(context.Context, struct{}) (string, error) { return "Hello", nil })).
+		Page(&PageDef{
+			Route:           "/about",
+			Template:        "<html><body>en:<!--seam:title--></body></html>",
+			LocaleTemplates: map[string]string{"de": "<html><body>de:<!--seam:title--></body></html>"},
+			Loaders:         []LoaderDef{{DataKey: "title", Procedure: "getTitle", InputFn: func(map[string]string) any { return map[string]any{} }}},
+		}).
+		I18nConfig(&I18nConfig{Locales: []string{"en", "de"}, Default: "en", Messages: map[string]map[string]json.RawMessage{}})
+
+	pages, err := router.Prerender(context.Background(), PrerenderOptions{Routes: []string{"/about", "/nope"}})
+	if len(pages) != 4 {
+		t.Fatalf("expected 4 pages, got %d", len(pages))
+	}
+	if !strings.Contains(string(pages[0].HTML), "en:Hello") || !strings.Contains(string(pages[1].HTML), "de:Hello") {
+		t.Fatalf("unexpected renders:\n%s\n%s", pages[0].HTML, pages[1].HTML)
+	}
+	if pages[1].Path != "/_seam/page/de/about" || pages[1].Status != 200 {
+		t.Fatalf("unexpected page %+v", pages[1])
+	}
+	if err == nil || !strings.Contains(err.Error(), "/_seam/page/en/nope: status 404") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestRenderPagesBatchMatchesSingleCalls(t *testing.T) {
+	calls := []engine.RenderCall{
+		{Template: "<p><!--seam:a--></p>", LoaderDataJSON: `{"a":"one"}`, ConfigJSON: `{"layout_chain":[],"data_id":"__data"}`},
+		{Template: "<p><!--seam:a--></p>", LoaderDataJSON: `{"a":"two"}`, ConfigJSON: `{"layout_chain":[],"data_id":"__data"}`},
+	}
+	batch, errs := engine.RenderPages(calls)
+	for i, c := range calls {
+		single, err := engine.RenderPage(c.Template, c.LoaderDataJSON, c.ConfigJSON, c.I18nOptsJSON)
+		if err != nil || errs[i] != nil || single != batch[i] {
+			t.Fatalf("call %d: batch %q (%v) != single %q (%v)", i, batch[i], errs[i], single, err)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+/* src/server/core/go/principal.go */
+
+package seam
+
+import (
+	"context"
+	"net/http"
+)
+
+type principalKeyType struct{}
+
+var principalKey = principalKeyType{}
+
+// PrincipalOf returns the request principal derived by HandlerOptions.Principal,
+// or "" for anonymous requests and when no principal function is configured.
+func PrincipalOf(ctx context.Context) string {
+	if v, ok := ctx.Value(principalKey).(string); ok {
+		return v
+	}
+	return ""
+}
+
+// requestContext builds the per-request base context shared by every
+// procedure call of one HTTP request: memo store, principal, flag scope,
+// dependency tracker.
+func (s *appState) requestContext(r *http.Request) context.Context {
+	ctx := injectMemo(r.Context())
+	principal, ok := s.principal(r)
+	if ok {
+		ctx = context.WithValue(ctx, principalKey, principal)
+		if entry, ok := ctx.Value(accessEntryKey).(*accessEntry); ok {
+			entry.principal = principal
+		}
+	}
+	if s.opts.Flags != nil {
+		locale := ""
+		if s.i18nConfig != nil {
+			locale = ResolveChain(s.strategies, &ResolveData{
+				Request:       r,
+				Locales:       s.i18nConfig.Locales,
+				DefaultLocale: s.i18nConfig.Default,
+			})
+		}
+		ctx = context.WithValue(ctx, flagScopeKey, &flagScope{
+			provider: s.opts.Flags,
+			target:   FlagTarget{Principal: principal, Locale: locale, Request: r},
+		})
+	}
+	if s.opts.Dependencies != nil {
+		ctx = context.WithValue(ctx, dependenciesKey, s.opts.Dependencies)
+	}
+	return ctx
+}
+
+// principal derives the principal of r: the one a signed URL carries, else
+// HandlerOptions.Principal's. ok is false when neither applies.
+func (s *appState) principal(r *http.Request) (string, bool) {
+	if signed, ok := r.Context().Value(signedPrincipalKey).(string); ok {
+		return signed, true
+	}
+	if s.opts.Principal != nil {
+		return s.opts.Principal(r), true
+	}
+	return "", false
+}
@@ -0,0 +1,98 @@
+/* src/server/core/go/pseudo_locale.go */
+
+package seam
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+)
+
+// pseudoAccents maps ASCII letters to accented look-alikes that stay
+// readable while exposing strings that bypass translation.
+var pseudoAccents = map[rune]rune{
+	'a': 'á', 'b': 'ƀ', 'c': 'ç', 'd': 'ð', 'e': 'é', 'f': 'ƒ', 'g': 'ĝ', 'h': 'ĥ', 'i': 'î',
+	'j': 'ĵ', 'k': 'ķ', 'l': 'ļ', 'm': 'ɱ', 'n': 'ñ', 'o': 'ö', 'p': 'þ', 'q': 'ǫ', 'r': 'ŕ',
+	's': 'š', 't': 'ţ', 'u': 'û', 'v': 'ṽ', 'w': 'ŵ', 'x': 'ẋ', 'y': 'ý', 'z': 'ž',
+	'A': 'Å', 'B': 'Ɓ', 'C': 'Ç', 'D': 'Ð', 'E': 'É', 'F': 'Ƒ', 'G': 'Ĝ', 'H': 'Ĥ', 'I': 'Î',
+	'J': 'Ĵ', 'K': 'Ķ', 'L': 'Ļ', 'M': 'Ṁ', 'N': 'Ñ', 'O': 'Ö', 'P': 'Þ', 'Q': 'Ǫ', 'R': 'Ŕ',
+	'S': 'Š', 'T': 'Ţ', 'U': 'Û', 'V': 'Ṽ', 'W': 'Ŵ', 'X': 'Ẋ', 'Y': 'Ý', 'Z': 'Ž',
+}
+
+// withPseudoLocale returns a copy of cfg that lists pseudo as a locale, so
+// the resolve strategies (prefix, cookie, query, Accept-Language) can
+// select it like a real one.
+func withPseudoLocale(cfg *I18nConfig, pseudo string) *I18nConfig {
+	if cfg == nil || pseudo == "" || slices.Contains(cfg.Locales, pseudo) {
+		return cfg
+	}
+	copied := *cfg
+	copied.Locales = append(slices.Clone(cfg.Locales), pseudo)
+	return &copied
+}
+
+// i18nMessages returns the messages of a route for locale; the pseudo
+// locale gets the default locale's messages pseudolocalized on the fly.
+func (s *appState) i18nMessages(routeHash, locale string) json.RawMessage {
+	if locale == "" || locale != s.opts.PseudoLocale {
+		return lookupI18nMessages(s.i18nConfig, routeHash, locale)
+	}
+	var messages any
+	if err := json.Unmarshal(lookupI18nMessages(s.i18nConfig, routeHash, s.i18nConfig.Default), &messages); err != nil {
+		return json.RawMessage("{}")
+	}
+	out, _ := json.Marshal(pseudolocalizeValue(messages))
+	return out
+}
+
+func pseudolocalizeValue(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, child := range val {
+			val[k] = pseudolocalizeValue(child)
+		}
+		return val
+	case []any:
+		for i, child := range val {
+			val[i] = pseudolocalizeValue(child)
+		}
+		return val
+	case string:
+		return pseudolocalize(val)
+	}
+	return v
+}
+
+// pseudolocalize accents letters, brackets the text, and pads it by about
+// 40% to mimic longer translations, e.g. "Save {count} files" becomes
+// "[Šáṽé {count} ƒîļéš ~~~~]". {placeholders} and <tags> are kept.
+func pseudolocalize(text string) string {
+	var b strings.Builder
+	b.WriteByte('[')
+	letters := 0
+	var closing rune
+	for _, r := range text {
+		switch {
+		case closing != 0:
+			if r == closing {
+				closing = 0
+			}
+		case r == '{':
+			closing = '}'
+		case r == '<':
+			closing = '>'
+		default:
+			if accented, ok := pseudoAccents[r]; ok {
+				r = accented
+				letters++
+			}
+		}
+		b.WriteRune(r)
+	}
+	if pad := (letters*2 + 4) / 5; pad > 0 {
+		b.WriteByte(' ')
+		b.WriteString(strings.Repeat("~", pad))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
@@ -0,0 +1,62 @@
+/* src/server/core/go/pseudo_locale_test.go */
+
+package seam
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPseudolocalize(t *testing.T) {
+	cases := map[string]string{
+		"Hello":                    "[Ĥéļļö ~~]",
+		"Save {count} files":       "[Šáṽé {count} ƒîļéš ~~~~]",
+		"Read <a href=x>more</a>!": "[Ŕéáð <a href=x>ɱöŕé</a>! ~~~~]",
+		"42":                       "[42]",
+	}
+	for in, want := range cases {
+		if got := pseudolocalize(in); got != want {
+			t.Errorf("pseudolocalize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestPseudoLocalePage(t *testing.T) {
+	router := NewRouter().
+		Page(&PageDef{Route: "/about", Template: "<html><body><p>about</p></body></html>"}).
+		I18nConfig(&I18nConfig{
+			Locales:     []string{"en", "de"},
+			Default:     "en",
+			RouteHashes: map[string]string{"/about": "a1b2c3d4"},
+			Messages: map[string]map[string]json.RawMessage{
+				"en": {"a1b2c3d4": json.RawMessage(`{"greet":"Hello {name}","nav":{"home":"Home"}}`)},
+			},
+		}).
+		ResolveStrategies(FromUrlQuery("lang"))
+	h := router.Handler(HandlerOptions{PseudoLocale: "en-XA"})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/_seam/page/about?lang=en-XA", nil))
+	body := w.Body.String()
+	if !strings.Contains(body, `lang="en-XA"`) || !strings.Contains(body, "<p>about</p>") {
+		t.Fatalf("expected default template in pseudo locale, got %s", body)
+	}
+	// The engine ASCII-escapes the data script
+	if !strings.Contains(body, `"greet":"[\u0124\u00e9\u013c\u013c\u00f6 {name} ~~]"`) {
+		t.Fatalf("expected pseudolocalized messages, got %s", body)
+	}
+
+	status, resp := rpcBody(h, "/_seam/procedure/seam.i18n.query", `{"route":"a1b2c3d4","locale":"en-XA"}`, nil)
+	if status != 200 || !strings.Contains(resp, "[Ĥöɱé ~~]") {
+		t.Fatalf("unexpected i18n query response %d %s", status, resp)
+	}
+
+	// Without the option the pseudo locale is unknown and falls back
+	w = httptest.NewRecorder()
+	router.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/_seam/page/about?lang=en-XA", nil))
+	if strings.Contains(w.Body.String(), "en-XA") {
+		t.Fatalf("pseudo locale served without PseudoLocale: %s", w.Body.String())
+	}
+}
@@ -0,0 +1,197 @@
+/* src/server/core/go/quota.go */
+
+package seam
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// QuotaUsage is what one principal consumed in one accounting window.
+type QuotaUsage struct {
+	Calls int64 `json:"calls"`
+	Bytes int64 `json:"bytes"` // request input + response result
+}
+
+// QuotaLimit caps a principal's usage per window. Zero fields are unlimited.
+type QuotaLimit struct {
+	Calls int64
+	Bytes int64
+}
+
+// QuotaStore persists per-principal usage. Windows are identified by
+// their start time; a shared store (Redis, SQL) accounts across replicas.
+type QuotaStore interface {
+	// Add records calls and bytes for principal in window and returns the
+	// usage after adding.
+	Add(ctx context.Context, principal string, window time.Time, delta QuotaUsage) (QuotaUsage, error)
+	// Usage returns principal's usage in window.
+	Usage(ctx context.Context, principal string, window time.Time) (QuotaUsage, error)
+}
+
+// Quota accounts query and command calls per principal (see
+// HandlerOptions.Principal, e.g. an API key) in fixed windows and rejects
+// calls of principals over their limit with RATE_LIMITED, Retry-After
+// set to the window end. Calls are accounted on every transport (HTTP,
+// batch, sockets); anonymous calls are not. Every call that reaches its
+// handler is charged, failed calls and dry runs included, so retrying a
+// failing call is not free: the input bytes always count, the result
+// bytes when there is a result. Calls rejected before the handler
+// (validation, rate limits, the quota itself) are not charged. Usage is
+// charged after the handler returns, so concurrent calls may overshoot a
+// limit by the calls in flight. Store errors are logged and the call is
+// allowed.
+type Quota struct {
+	Store  QuotaStore                        // default: NewMemoryQuotaStore()
+	Window time.Duration                     // accounting window (default 24h)
+	Limit  func(principal string) QuotaLimit // nil: account only, never reject
+
+	storeOnce sync.Once
+}
+
+func (q *Quota) store() QuotaStore {
+	q.storeOnce.Do(func() {
+		if q.Store == nil {
+			q.Store = NewMemoryQuotaStore()
+		}
+	})
+	return q.Store
+}
+
+func (q *Quota) window() time.Duration {
+	if q.Window > 0 {
+		return q.Window
+	}
+	return 24 * time.Hour
+}
+
+// currentWindow returns the start and end of the window containing now.
+func (q *Quota) currentWindow() (time.Time, time.Time) {
+	start := time.Now().Truncate(q.window())
+	return start, start.Add(q.window())
+}
+
+// check rejects the call when the principal is over quota.
+func (q *Quota) check(ctx context.Context) *Error {
+	principal := PrincipalOf(ctx)
+	if q == nil || principal == "" || q.Limit == nil {
+		return nil
+	}
+	limit := q.Limit(principal)
+	if limit == (QuotaLimit{}) {
+		return nil
+	}
+	start, end := q.currentWindow()
+	usage, err := q.store().Usage(ctx, principal, start)
+	if err != nil {
+		logf(slog.LevelWarn, "quota usage of %q: %v\n", principal, err)
+		return nil
+	}
+	if (limit.Calls > 0 && usage.Calls >= limit.Calls) || (limit.Bytes > 0 && usage.Bytes >= limit.Bytes) {
+		e := RateLimitedError(fmt.Sprintf("Quota exceeded for this window (%d calls, %d bytes used)", usage.Calls, usage.Bytes))
+		e.RetryAfter = time.Until(end)
+		return e
+	}
+	return nil
+}
+
+// charge accounts one call and its payload sizes; result is nil when the
+// call failed.
+func (q *Quota) charge(ctx context.Context, input []byte, result any) {
+	principal := PrincipalOf(ctx)
+	if q == nil || principal == "" {
+		return
+	}
+	var out []byte
+	if result != nil {
+		out, _ = codecMarshal(result)
+	}
+	start, _ := q.currentWindow()
+	if _, err := q.store().Add(context.WithoutCancel(ctx), principal, start, QuotaUsage{Calls: 1, Bytes: int64(len(input) + len(out))}); err != nil {
+		logf(slog.LevelError, "quota charge of %q: %v\n", principal, err)
+	}
+}
+
+// QuotaReport is the result of the usage procedure.
+type QuotaReport struct {
+	Principal   string     `json:"principal"`
+	Usage       QuotaUsage `json:"usage"`
+	LimitCalls  int64      `json:"limitCalls"` // 0 = unlimited
+	LimitBytes  int64      `json:"limitBytes"` // 0 = unlimited
+	WindowStart time.Time  `json:"windowStart"`
+	WindowEnd   time.Time  `json:"windowEnd"`
+}
+
+// QuotaUsageInput is the input of the usage procedure. Principal may be
+// omitted; when given it must be the caller's own.
+type QuotaUsageInput struct {
+	Principal string `json:"principal,omitempty"`
+}
+
+// UsageProcedure returns a query reporting the caller's own usage in the
+// current window. Anonymous callers get UNAUTHORIZED and a request for
+// another principal FORBIDDEN; read other principals' usage from the
+// QuotaStore directly (e.g. in an admin tool). Pass the same Quota as
+// HandlerOptions.Quota.
+func (q *Quota) UsageProcedure(name string) *ProcedureDef {
+	return Query(name, func(ctx context.Context, in QuotaUsageInput) (QuotaReport, error) {
+		principal := PrincipalOf(ctx)
+		if principal == "" {
+			return QuotaReport{}, UnauthorizedError("Quota usage needs an authenticated caller")
+		}
+		if in.Principal != "" && in.Principal != principal {
+			return QuotaReport{}, ForbiddenError("Quota usage of another principal")
+		}
+		start, end := q.currentWindow()
+		usage, err := q.store().Usage(ctx, principal, start)
+		if err != nil {
+			return QuotaReport{}, InternalError(fmt.Sprintf("Quota usage: %s", err))
+		}
+		report := QuotaReport{Principal: principal, Usage: usage, WindowStart: start, WindowEnd: end}
+		if q.Limit != nil {
+			limit := q.Limit(principal)
+			report.LimitCalls, report.LimitBytes = limit.Calls, limit.Bytes
+		}
+		return report, nil
+	})
+}
+
+// MemoryQuotaStore keeps usage in process memory, dropping windows older
+// than the latest one seen. It only accounts calls served by this process.
+type MemoryQuotaStore struct {
+	mu     sync.Mutex
+	window time.Time
+	usage  map[string]QuotaUsage
+}
+
+// NewMemoryQuotaStore creates an empty in-process store.
+func NewMemoryQuotaStore() *MemoryQuotaStore {
+	return &MemoryQuotaStore{usage: make(map[string]QuotaUsage)}
+}
+
+func (m *MemoryQuotaStore) Add(_ context.Context, principal string, window time.Time, delta QuotaUsage) (QuotaUsage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if window.After(m.window) {
+		m.window, m.usage = window, make(map[string]QuotaUsage)
+	} else if window.Before(m.window) {
+		return delta, nil // late charge for a closed window
+	}
+	u := m.usage[principal]
+	u.Calls += delta.Calls
+	u.Bytes += delta.Bytes
+	m.usage[principal] = u
+	return u, nil
+}
+
+func (m *MemoryQuotaStore) Usage(_ context.Context, principal string, window time.Time) (QuotaUsage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !window.Equal(m.window) {
+		return QuotaUsage{}, nil
+	}
+	return m.usage[principal], nil
+}
@@ -0,0 +1,104 @@
+/* src/server/core/go/quota_test.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func quotaRouter(quota *Quota) http.Handler {
+	return NewRouter().
+		Procedure(Query("getRepos", func(context.Context, struct{}) ([]string, error) { return []string{"seam"}, nil })).
+		Procedure(quota.UsageProcedure("admin.quotaUsage")).
+		Handler(HandlerOptions{
+			Quota:     quota,
+			Principal: func(r *http.Request) string { return r.Header.Get("X-Api-Key") },
+		})
+}
+
+func TestQuotaRejectsOverLimitPrincipal(t *testing.T) {
+	quota := &Quota{Limit: func(principal string) QuotaLimit {
+		if principal == "partner" {
+			return QuotaLimit{Calls: 2}
+		}
+		return QuotaLimit{}
+	}}
+	h := quotaRouter(quota)
+	partner := http.Header{"X-Api-Key": {"partner"}}
+
+	for i := 0; i < 2; i++ {
+		if code, body := rpcBody(h, "/_seam/procedure/getRepos", `{}`, partner); code != http.StatusOK {
+			t.Fatalf("call %d: %d %s", i, code, body)
+		}
+	}
+	code, body := rpcBody(h, "/_seam/procedure/getRepos", `{}`, partner)
+	if code != http.StatusTooManyRequests || !strings.Contains(body, `"code":"RATE_LIMITED"`) || !strings.Contains(body, `"retryAfterMs":`) {
+		t.Fatalf("over quota: %d %s", code, body)
+	}
+	// Other principals and anonymous callers are unaffected
+	if code, _ := rpcBody(h, "/_seam/procedure/getRepos", `{}`, http.Header{"X-Api-Key": {"other"}}); code != http.StatusOK {
+		t.Fatalf("other principal got %d", code)
+	}
+	if code, _ := rpcBody(h, "/_seam/procedure/getRepos", `{}`, nil); code != http.StatusOK {
+		t.Fatalf("anonymous got %d", code)
+	}
+}
+
+func TestQuotaUsageProcedure(t *testing.T) {
+	quota := &Quota{Limit: func(string) QuotaLimit { return QuotaLimit{Bytes: 1 << 20} }}
+	h := quotaRouter(quota)
+	partner := http.Header{"X-Api-Key": {"partner"}}
+	rpcBody(h, "/_seam/procedure/getRepos", `{}`, partner)
+	rpcBody(h, "/_seam/procedure/getRepos", `{}`, partner)
+
+	// only the caller's own usage is reported
+	if code, _ := rpcBody(h, "/_seam/procedure/admin.quotaUsage", `{"principal":"partner"}`, http.Header{"X-Api-Key": {"other"}}); code != http.StatusForbidden {
+		t.Fatalf("another principal's usage: %d", code)
+	}
+	if code, _ := rpcBody(h, "/_seam/procedure/admin.quotaUsage", `{}`, nil); code != http.StatusUnauthorized {
+		t.Fatalf("anonymous usage: %d", code)
+	}
+	code, body := rpcBody(h, "/_seam/procedure/admin.quotaUsage", `{}`, partner)
+	if code != http.StatusOK {
+		t.Fatalf("usage: %d %s", code, body)
+	}
+	var resp struct {
+		Data QuotaReport `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		t.Fatal(err)
+	}
+	// Each call: 2 input bytes + 8 result bytes (["seam"])
+	if resp.Data.Principal != "partner" || resp.Data.Usage.Calls != 2 || resp.Data.Usage.Bytes != 20 || resp.Data.LimitBytes != 1<<20 {
+		t.Fatalf("unexpected report %s", body)
+	}
+	if !resp.Data.WindowEnd.After(resp.Data.WindowStart) {
+		t.Fatalf("bad window %s", strconv.Quote(body))
+	}
+}
+
+func TestQuotaChargesFailedCallsOnEveryTransport(t *testing.T) {
+	store := NewMemoryQuotaStore()
+	quota := &Quota{Store: store}
+	h := opsRouter(Command("ops.fail", func(context.Context, struct{}) (bool, error) {
+		return false, ValidationError("nope")
+	})).Handler(HandlerOptions{
+		Quota:             quota,
+		Principal:         func(*http.Request) string { return "partner" },
+		HeartbeatInterval: time.Minute,
+	})
+	callTransports(t, h, "ops.fail", `{}`, nil)
+
+	start, _ := quota.currentWindow()
+	usage, _ := store.Usage(context.Background(), "partner", start)
+	// three calls of 2 input bytes each, no results
+	if usage != (QuotaUsage{Calls: 3, Bytes: 6}) {
+		t.Fatalf("usage %+v", usage)
+	}
+}
@@ -0,0 +1,143 @@
+/* src/server/core/go/rate_limits.go */
+
+package seam
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RateLimit is a token bucket: Calls per RateLimits.Window, refilled
+// continuously, holding up to Burst unspent calls (default Calls). A
+// batch spends one token per inner call, so Burst above Calls lets a
+// caller that was idle send a batch larger than the steady rate.
+type RateLimit struct {
+	Calls int64 // 0 = unlimited
+	Burst int64
+}
+
+// ClassRates gives each procedure class its own budget. Subscription
+// covers SSE, polls, WebSocket connections and subscriptions, and POST
+// streams (counted when they start).
+type ClassRates struct {
+	Query        RateLimit
+	Command      RateLimit
+	Subscription RateLimit
+}
+
+// RateLimits applies per-caller rate limits partitioned by procedure
+// class (typically commands stricter than queries). Authenticated callers
+// (HandlerOptions.Principal) are keyed by principal and limited by
+// Authenticated; anonymous callers are keyed by client IP and limited by
+// Anonymous. Calls over the limit get RATE_LIMITED with Retry-After set
+// to when the next token is due. Buckets are per process.
+type RateLimits struct {
+	Authenticated ClassRates
+	Anonymous     ClassRates
+	Window        time.Duration // period Calls is given in (default 1m)
+
+	mu      sync.Mutex
+	buckets map[rateBucketKey]*tokenBucket
+	adds    int
+}
+
+type rateBucketKey struct {
+	class  string // "query", "command", or "subscription"
+	caller string
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func (rl *RateLimits) window() time.Duration {
+	if rl.Window > 0 {
+		return rl.Window
+	}
+	return time.Minute
+}
+
+// procedureClass maps a procedure type to its rate class.
+func procedureClass(proc *ProcedureDef) string {
+	if proc.Type == "command" {
+		return "command"
+	}
+	return "query"
+}
+
+// check spends one token of the caller's class budget, or rejects the call.
+func (rl *RateLimits) check(ctx context.Context, class string) *Error {
+	if rl == nil {
+		return nil
+	}
+	rates, caller := rl.Anonymous, "ip:"+ClientIP(ctx)
+	if principal := PrincipalOf(ctx); principal != "" {
+		rates, caller = rl.Authenticated, principal
+	}
+	var limit RateLimit
+	switch class {
+	case "command":
+		limit = rates.Command
+	case "subscription":
+		limit = rates.Subscription
+	default:
+		limit = rates.Query
+	}
+	if limit.Calls <= 0 {
+		return nil
+	}
+	if wait, ok := rl.take(rateBucketKey{class, caller}, limit); !ok {
+		e := RateLimitedError(fmt.Sprintf("Rate limit of %d %s calls per %s exceeded", limit.Calls, class, rl.window()))
+		e.Transient = true
+		e.RetryAfter = wait
+		return e
+	}
+	return nil
+}
+
+// take spends a token from key's bucket, or reports how long until one
+// is available.
+func (rl *RateLimits) take(key rateBucketKey, limit RateLimit) (time.Duration, bool) {
+	capacity := float64(limit.Burst)
+	if limit.Burst <= 0 {
+		capacity = float64(limit.Calls)
+	}
+	perNs := float64(limit.Calls) / float64(rl.window())
+	now := time.Now()
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if rl.buckets == nil {
+		rl.buckets = make(map[rateBucketKey]*tokenBucket)
+	}
+	b, ok := rl.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: capacity, last: now}
+		rl.buckets[key] = b
+		rl.sweep(now)
+	}
+	b.tokens = min(capacity, b.tokens+float64(now.Sub(b.last))*perNs)
+	b.last = now
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) / perNs), false
+	}
+	b.tokens--
+	return 0, true
+}
+
+// sweep drops buckets idle for a full window (refilled by now, so
+// indistinguishable from new ones) every 1024 new buckets.
+func (rl *RateLimits) sweep(now time.Time) {
+	rl.adds++
+	if rl.adds%1024 != 0 {
+		return
+	}
+	for key, b := range rl.buckets {
+		if now.Sub(b.last) > rl.window() {
+			delete(rl.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+/* src/server/core/go/rate_limits_test.go */
+
+package seam
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func rateLimitedHandler(limits *RateLimits) http.Handler {
+	ok := func(context.Context, struct{}) (bool, error) { return true, nil }
+	return NewRouter().
+		Procedure(Query("list", ok)).
+		Procedure(Command("save", ok)).
+		RpcHashMap(&RpcHashMap{Batch: "_batch", Procedures: map[string]string{"list": "list", "save": "save"}}).
+		Handler(HandlerOptions{
+			RateLimits: limits,
+			Principal:  func(r *http.Request) string { return r.Header.Get("X-User") },
+		})
+}
+
+func TestRateLimitsByClassAndCaller(t *testing.T) {
+	h := rateLimitedHandler(&RateLimits{
+		Authenticated: ClassRates{Query: RateLimit{Calls: 100}, Command: RateLimit{Calls: 2}},
+		Anonymous:     ClassRates{Command: RateLimit{Calls: 1}},
+		Window:        time.Hour,
+	})
+	alice := http.Header{"X-User": {"alice"}}
+
+	for i := range 2 {
+		if code, body := rpcBody(h, "/_seam/procedure/save", `{}`, alice); code != http.StatusOK {
+			t.Fatalf("command %d: %d %s", i, code, body)
+		}
+	}
+	code, body := rpcBody(h, "/_seam/procedure/save", `{}`, alice)
+	if code != http.StatusTooManyRequests || !strings.Contains(body, "2 command calls per 1h0m0s") {
+		t.Errorf("third command: %d %s", code, body)
+	}
+	// Queries have their own budget.
+	if code, _ := rpcBody(h, "/_seam/procedure/list", `{}`, alice); code != http.StatusOK {
+		t.Errorf("query after commands: %d", code)
+	}
+
+	// Anonymous callers get the stricter limit, and unlimited queries.
+	if code, _ := rpcBody(h, "/_seam/procedure/save", `{}`, nil); code != http.StatusOK {
+		t.Errorf("anonymous command: %d", code)
+	}
+	if code, _ := rpcBody(h, "/_seam/procedure/save", `{}`, nil); code != http.StatusTooManyRequests {
+		t.Errorf("second anonymous command: %d", code)
+	}
+	for range 5 {
+		if code, _ := rpcBody(h, "/_seam/procedure/list", `{}`, nil); code != http.StatusOK {
+			t.Fatalf("anonymous query limited: %d", code)
+		}
+	}
+}
+
+func TestRateLimitsCountBatchCalls(t *testing.T) {
+	h := rateLimitedHandler(&RateLimits{
+		Anonymous: ClassRates{Query: RateLimit{Calls: 2, Burst: 3}},
+		Window:    time.Hour,
+	})
+	_, body := rpcBody(h, "/_seam/procedure/_batch", batchOf("list", "list", "list", "list"), nil)
+	if strings.Count(body, `"ok":true,"data":true`) != 3 || !strings.Contains(body, `"code":"RATE_LIMITED"`) {
+		t.Errorf("batch of 4 against a burst of 3: %s", body)
+	}
+}
+
+func TestRateLimitRefill(t *testing.T) {
+	rl := &RateLimits{Window: time.Second}
+	limit := RateLimit{Calls: 10, Burst: 1}
+	key := rateBucketKey{"query", "ip:1"}
+	if _, ok := rl.take(key, limit); !ok {
+		t.Fatal("first call refused")
+	}
+	wait, ok := rl.take(key, limit)
+	if ok || wait <= 0 || wait > 100*time.Millisecond {
+		t.Fatalf("second call: ok=%v wait=%s", ok, wait)
+	}
+	time.Sleep(wait + 5*time.Millisecond)
+	if _, ok := rl.take(key, limit); !ok {
+		t.Error("token not refilled")
+	}
+}
+
+func TestRateLimitsOnChannelSocket(t *testing.T) {
+	save := Command("ops.save", func(context.Context, struct{}) (bool, error) { return true, nil })
+	h := opsRouter(save).Handler(HandlerOptions{
+		HeartbeatInterval: time.Minute,
+		RateLimits: &RateLimits{
+			Anonymous: ClassRates{Command: RateLimit{Calls: 1}, Subscription: RateLimit{Calls: 1}},
+			Window:    time.Hour,
+		},
+	})
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/_seam/procedure/ops.events?input={}"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	// Commands on the socket share the caller's command budget.
+	codes := []string{}
+	for _, id := range []string{"1", "2"} {
+		_ = conn.WriteJSON(map[string]any{"id": id, "procedure": "ops.save", "input": map[string]any{}})
+		for {
+			var frame struct {
+				ID    string `json:"id"`
+				Error *struct {
+					Code string `json:"code"`
+				} `json:"error"`
+			}
+			if err := conn.ReadJSON(&frame); err != nil {
+				t.Fatal(err)
+			}
+			if frame.ID == id {
+				code := ""
+				if frame.Error != nil {
+					code = frame.Error.Code
+				}
+				codes = append(codes, code)
+				break
+			}
+		}
+	}
+	if codes[0] != "" || codes[1] != "RATE_LIMITED" {
+		t.Fatalf("socket command codes %q", codes)
+	}
+
+	// Opening a socket counts against the subscription budget.
+	_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	if err == nil || resp == nil || resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("second socket over the subscription limit: %v %v", resp, err)
+	}
+}
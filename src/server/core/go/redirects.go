@@ -0,0 +1,165 @@
+/* src/server/core/go/redirects.go */
+
+package seam
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// RedirectRule redirects or rewrites page paths before page matching.
+// From is a path pattern where ":name" captures one segment and a final
+// "*name" captures the rest of the path; To may reference the captures
+// and may be an absolute URL (host canonicalization). Status 301, 302,
+// 307, or 308 redirects; 0 rewrites internally (e.g. locale aliases).
+// Page requests are matched on their path without the /_seam/page
+// prefix. Rules are evaluated in order; the first match wins.
+type RedirectRule struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Status int    `json:"status,omitempty"`
+	Host   string `json:"host,omitempty"` // only match requests for this host
+}
+
+type compiledRedirect struct {
+	rule     RedirectRule
+	segments []string
+}
+
+func compileRedirects(rules []RedirectRule) []compiledRedirect {
+	compiled := make([]compiledRedirect, 0, len(rules))
+	for _, rule := range rules {
+		if !strings.HasPrefix(rule.From, "/") {
+			panic(fmt.Sprintf("redirect from %q must start with \"/\"", rule.From))
+		}
+		switch rule.Status {
+		case 0, http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		default:
+			panic(fmt.Sprintf("redirect %s: unsupported status %d", rule.From, rule.Status))
+		}
+		if rule.Status == 0 && !strings.HasPrefix(rule.To, "/") {
+			panic(fmt.Sprintf("rewrite %s: target %q must be a path", rule.From, rule.To))
+		}
+		segments := splitPath(rule.From)
+		for i, seg := range segments {
+			if strings.HasPrefix(seg, "*") && i != len(segments)-1 {
+				panic(fmt.Sprintf("redirect %s: wildcard must be the last segment", rule.From))
+			}
+		}
+		compiled = append(compiled, compiledRedirect{rule: rule, segments: segments})
+	}
+	return compiled
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+// match returns the captures when path matches the rule pattern.
+func (c *compiledRedirect) match(host, path string) (map[string]string, bool) {
+	if c.rule.Host != "" && !strings.EqualFold(c.rule.Host, host) {
+		return nil, false
+	}
+	parts := splitPath(path)
+	captures := make(map[string]string)
+	for i, seg := range c.segments {
+		if strings.HasPrefix(seg, "*") {
+			captures[seg[1:]] = strings.Join(parts[i:], "/")
+			return captures, true
+		}
+		if i >= len(parts) {
+			return nil, false
+		}
+		if strings.HasPrefix(seg, ":") {
+			captures[seg[1:]] = parts[i]
+		} else if seg != parts[i] {
+			return nil, false
+		}
+	}
+	return captures, len(parts) == len(c.segments)
+}
+
+// expand substitutes ":name" and "*name" references in the target.
+func (c *compiledRedirect) expand(captures map[string]string) string {
+	scheme, rest := "", c.rule.To
+	if i := strings.Index(rest, "://"); i >= 0 {
+		scheme, rest = rest[:i+3], rest[i+3:]
+	}
+	path, query, hasQuery := strings.Cut(rest, "?")
+	parts := strings.Split(path, "/")
+	for i, p := range parts {
+		if len(p) > 1 && (p[0] == ':' || p[0] == '*') {
+			if v, ok := captures[p[1:]]; ok {
+				parts[i] = v
+			}
+		}
+	}
+	target := scheme + strings.Join(parts, "/")
+	if hasQuery {
+		target += "?" + query
+	}
+	return target
+}
+
+// redirectMiddleware applies the redirect/rewrite table to page requests
+// (/_seam/page/...) and to non-/_seam paths (public/root-mounted pages).
+func (s *appState) redirectMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prefix, path := "", r.URL.Path
+		switch {
+		case strings.HasPrefix(path, "/_seam/page/") || path == "/_seam/page":
+			prefix, path = "/_seam/page", strings.TrimPrefix(path, "/_seam/page")
+		case strings.HasPrefix(path, "/_seam/"):
+			next.ServeHTTP(w, r)
+			return
+		}
+		for i := range s.redirects {
+			rule := &s.redirects[i]
+			captures, ok := rule.match(r.Host, path)
+			if !ok {
+				continue
+			}
+			target := rule.expand(captures)
+			if rule.rule.Status == 0 {
+				u := *r.URL
+				u.Path, u.RawPath = prefix+target, ""
+				if p, q, ok := strings.Cut(u.Path, "?"); ok {
+					u.Path, u.RawQuery = p, q
+				}
+				r2 := r.Clone(r.Context())
+				r2.URL = &u
+				next.ServeHTTP(w, r2)
+				return
+			}
+			if r.URL.RawQuery != "" && !strings.Contains(target, "?") {
+				target += "?" + r.URL.RawQuery
+			}
+			http.Redirect(w, r, target, rule.rule.Status)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// LoadRedirects loads redirects.json from build output (nil when absent).
+func LoadRedirects(dir string) []RedirectRule {
+	data, err := os.ReadFile(filepath.Join(dir, "redirects.json"))
+	if err != nil {
+		return nil
+	}
+	var rules []RedirectRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		logf(slog.LevelWarn, "ignoring invalid redirects.json: %v\n", err)
+		return nil
+	}
+	return rules
+}
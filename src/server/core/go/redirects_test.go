@@ -0,0 +1,97 @@
+/* src/server/core/go/redirects_test.go */
+
+package seam
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRedirectsAndRewrites(t *testing.T) {
+	router := NewRouter().
+		Page(&PageDef{Route: "/posts/:slug", Template: "<html><body>post</body></html>"}).
+		Build(BuildOutput{Redirects: []RedirectRule{
+			{From: "/blog/:slug", To: "/posts/:slug", Status: http.StatusFound},
+		}})
+	handler := router.Handler(HandlerOptions{Redirects: []RedirectRule{
+		{From: "/blog/pinned", To: "/posts/welcome", Status: http.StatusPermanentRedirect},
+		{From: "/docs/*rest", To: "https://docs.example.com/*rest", Status: http.StatusMovedPermanently},
+		{From: "/articles/:slug", To: "/posts/:slug"},
+		{From: "/", To: "https://www.example.com/", Status: http.StatusMovedPermanently, Host: "example.com"},
+	}})
+
+	tests := []struct {
+		host, path string
+		status     int
+		location   string
+	}{
+		{"", "/_seam/page/blog/pinned", http.StatusPermanentRedirect, "/posts/welcome"},
+		{"", "/_seam/page/blog/hello?ref=x", http.StatusFound, "/posts/hello?ref=x"},
+		{"", "/docs/guide/install", http.StatusMovedPermanently, "https://docs.example.com/guide/install"},
+		{"", "/_seam/page/articles/hello", http.StatusOK, ""},
+		{"example.com", "/", http.StatusMovedPermanently, "https://www.example.com/"},
+		{"", "/_seam/page/blog/a/b", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		if tt.host != "" {
+			req.Host = tt.host
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != tt.status {
+			t.Errorf("%s%s: status %d, want %d", tt.host, tt.path, w.Code, tt.status)
+			continue
+		}
+		if got := w.Header().Get("Location"); got != tt.location {
+			t.Errorf("%s%s: location %q, want %q", tt.host, tt.path, got, tt.location)
+		}
+	}
+}
+
+func TestRedirectsSkipProtocolRoutes(t *testing.T) {
+	handler := NewRouter().Handler(HandlerOptions{Redirects: []RedirectRule{
+		{From: "/*rest", To: "/elsewhere", Status: http.StatusFound},
+	}})
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/_seam/manifest.json", nil))
+	if w.Code == http.StatusFound {
+		t.Fatal("protocol routes must not be redirected")
+	}
+}
+
+func TestCompileRedirectsRejectsInvalidRules(t *testing.T) {
+	for _, rule := range []RedirectRule{
+		{From: "old", To: "/new", Status: http.StatusFound},
+		{From: "/old", To: "/new", Status: http.StatusOK},
+		{From: "/old", To: "https://example.com/"},
+		{From: "/*rest/tail", To: "/new", Status: http.StatusFound},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected panic for %+v", rule)
+				}
+			}()
+			compileRedirects([]RedirectRule{rule})
+		}()
+	}
+}
+
+func TestLoadRedirects(t *testing.T) {
+	dir := t.TempDir()
+	if LoadRedirects(dir) != nil {
+		t.Fatal("expected nil without redirects.json")
+	}
+	body := `[{"from":"/old","to":"/new","status":301}]`
+	if err := os.WriteFile(filepath.Join(dir, "redirects.json"), []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rules := LoadRedirects(dir)
+	if len(rules) != 1 || rules[0].To != "/new" || rules[0].Status != 301 {
+		t.Fatalf("unexpected rules %+v", rules)
+	}
+}
@@ -0,0 +1,322 @@
+/* src/server/core/go/remote_build.go */
+
+package seam
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotModified is returned by a BuildOutputSource when the remote build
+// output still matches the ETag passed to Fetch.
+var ErrNotModified = errors.New("build output not modified")
+
+// BuildOutputSource fetches a build output directory packed as a
+// .tar.gz archive (the contents of .seam/output at the archive root).
+// Fetch receives the ETag of the cached copy ("" when none) and returns
+// ErrNotModified when it is still current. The returned ETag may be ""
+// if the source has none; the archive content hash is used instead.
+type BuildOutputSource interface {
+	Fetch(ctx context.Context, etag string) (archive io.ReadCloser, newETag string, err error)
+}
+
+// HTTPSource fetches the build archive over HTTP(S) with conditional
+// requests. S3 and GCS objects work through their HTTPS endpoints or
+// presigned URLs; use Header for bearer tokens.
+type HTTPSource struct {
+	URL    string
+	Client *http.Client // default http.DefaultClient
+	Header http.Header
+}
+
+func (s *HTTPSource) Fetch(ctx context.Context, etag string) (io.ReadCloser, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	for k, vs := range s.Header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, resp.Header.Get("ETag"), nil
+	case http.StatusNotModified:
+		resp.Body.Close()
+		return nil, "", ErrNotModified
+	default:
+		resp.Body.Close()
+		return nil, "", fmt.Errorf("fetch %s: unexpected status %s", s.URL, resp.Status)
+	}
+}
+
+// RemoteBuild keeps a local, extracted copy of a remote build output under
+// CacheDir. The cache survives restarts, so a server can start from the
+// last fetched build when the source is unreachable.
+type RemoteBuild struct {
+	Source   BuildOutputSource
+	CacheDir string
+	Interval time.Duration // refresh interval for Watch (default 1m)
+
+	mu      sync.Mutex
+	current remoteBuildState
+}
+
+// remoteBuildState is persisted as CacheDir/current.json.
+type remoteBuildState struct {
+	ETag string `json:"etag"`
+	Dir  string `json:"dir"` // directory name under CacheDir
+}
+
+const remoteBuildStateFile = "current.json"
+
+// Sync refreshes the cached build and returns its directory. changed
+// reports whether a new build was extracted. On fetch failure the last
+// cached build is returned alongside the error.
+func (rb *RemoteBuild) Sync(ctx context.Context) (dir string, changed bool, err error) {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	if rb.current.Dir == "" {
+		rb.readState()
+	}
+	cached := ""
+	if rb.current.Dir != "" {
+		cached = filepath.Join(rb.CacheDir, rb.current.Dir)
+	}
+
+	archive, etag, err := rb.Source.Fetch(ctx, rb.current.ETag)
+	if errors.Is(err, ErrNotModified) && cached != "" {
+		return cached, false, nil
+	}
+	if err != nil {
+		return cached, false, err
+	}
+	defer archive.Close()
+
+	if err := os.MkdirAll(rb.CacheDir, 0o755); err != nil {
+		return cached, false, err
+	}
+	tmp, err := os.MkdirTemp(rb.CacheDir, ".fetch-")
+	if err != nil {
+		return cached, false, err
+	}
+	hash := sha256.New()
+	if err := extractTarGz(io.TeeReader(archive, hash), tmp); err != nil {
+		os.RemoveAll(tmp)
+		return cached, false, fmt.Errorf("extract build archive: %w", err)
+	}
+	sum := hex.EncodeToString(hash.Sum(nil))
+	if etag == "" {
+		etag = sum
+	}
+	name := "build-" + sum[:16]
+	if name == rb.current.Dir {
+		os.RemoveAll(tmp)
+		rb.current.ETag = etag
+		rb.writeState()
+		return cached, false, nil
+	}
+	final := filepath.Join(rb.CacheDir, name)
+	os.RemoveAll(final)
+	if err := os.Rename(tmp, final); err != nil {
+		os.RemoveAll(tmp)
+		return cached, false, err
+	}
+	previous := rb.current.Dir
+	rb.current = remoteBuildState{ETag: etag, Dir: name}
+	rb.writeState()
+	rb.prune(name, previous)
+	return final, true, nil
+}
+
+// Load syncs and loads the cached build output.
+func (rb *RemoteBuild) Load(ctx context.Context) (BuildOutput, error) {
+	dir, _, err := rb.Sync(ctx)
+	if dir == "" {
+		return BuildOutput{}, err
+	}
+	if err != nil {
+		logf(slog.LevelWarn, "build fetch failed, using cached build: %v\n", err)
+	}
+	return LoadBuild(dir), nil
+}
+
+// Watch loads the current build into set and refreshes it every Interval
+// until ctx is done. Each new build is loaded under its cache directory
+// name and activated; the previously active version stays loaded so
+// in-flight requests can finish.
+func (rb *RemoteBuild) Watch(ctx context.Context, set *BuildSet) error {
+	dir, _, err := rb.Sync(ctx)
+	if dir == "" {
+		return err
+	}
+	if err != nil {
+		logf(slog.LevelWarn, "build fetch failed, using cached build: %v\n", err)
+	}
+	if err := rb.activate(set, dir); err != nil {
+		return err
+	}
+
+	interval := rb.Interval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			dir, changed, err := rb.Sync(ctx)
+			if err != nil {
+				logf(slog.LevelWarn, "build refresh failed: %v\n", err)
+				continue
+			}
+			if changed {
+				if err := rb.activate(set, dir); err != nil {
+					logf(slog.LevelWarn, "build refresh failed: %v\n", err)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func (rb *RemoteBuild) activate(set *BuildSet, dir string) error {
+	version := filepath.Base(dir)
+	previous := set.Active()
+	if err := set.LoadDir(version, dir); err != nil {
+		return err
+	}
+	if err := set.Activate(version); err != nil {
+		return err
+	}
+	// Keep only the active and previous versions loaded
+	for _, v := range set.Versions() {
+		if v != version && v != previous {
+			_ = set.Unload(v)
+		}
+	}
+	return nil
+}
+
+func (rb *RemoteBuild) readState() {
+	data, err := os.ReadFile(filepath.Join(rb.CacheDir, remoteBuildStateFile))
+	if err != nil {
+		return
+	}
+	var st remoteBuildState
+	if json.Unmarshal(data, &st) != nil || st.Dir == "" {
+		return
+	}
+	if info, err := os.Stat(filepath.Join(rb.CacheDir, st.Dir)); err == nil && info.IsDir() {
+		rb.current = st
+	}
+}
+
+func (rb *RemoteBuild) writeState() {
+	data, _ := json.Marshal(rb.current)
+	path := filepath.Join(rb.CacheDir, remoteBuildStateFile)
+	tmp := path + ".tmp"
+	if os.WriteFile(tmp, data, 0o644) == nil {
+		_ = os.Rename(tmp, path)
+	}
+}
+
+// prune removes cached builds other than the current and previous one.
+func (rb *RemoteBuild) prune(keep ...string) {
+	entries, err := os.ReadDir(rb.CacheDir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if !e.IsDir() || !strings.HasPrefix(name, "build-") {
+			continue
+		}
+		if name != keep[0] && name != keep[1] {
+			os.RemoveAll(filepath.Join(rb.CacheDir, name))
+		}
+	}
+}
+
+// extractTarGz unpacks regular files and directories into dest, rejecting
+// entries that would escape it.
+func extractTarGz(r io.Reader, dest string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		name := filepath.FromSlash(strings.TrimPrefix(hdr.Name, "./"))
+		if name == "" || name == "." {
+			continue
+		}
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("archive entry %q escapes the output directory", hdr.Name)
+		}
+		target := filepath.Join(dest, name)
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, tr)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	// Drain trailing data so the content hash covers the whole archive
+	_, err = io.Copy(io.Discard, r)
+	return err
+}
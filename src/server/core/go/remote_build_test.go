@@ -0,0 +1,142 @@
+/* src/server/core/go/remote_build_test.go */
+
+package seam
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func tarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// buildArchiveServer serves the archive in *body with ETag *etag and
+// counts full (200) responses.
+func buildArchiveServer(body *[]byte, etag *string, full *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == *etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full.Add(1)
+		w.Header().Set("ETag", *etag)
+		_, _ = w.Write(*body)
+	}))
+}
+
+func TestRemoteBuildSyncWithETag(t *testing.T) {
+	body := tarGz(t, map[string]string{"route-manifest.json": `{"routes":{}}`, "rpc-hash-map.json": `{"batch":"b1","procedures":{}}`})
+	etag := `"v1"`
+	var full atomic.Int32
+	srv := buildArchiveServer(&body, &etag, &full)
+	defer srv.Close()
+
+	cache := t.TempDir()
+	rb := &RemoteBuild{Source: &HTTPSource{URL: srv.URL}, CacheDir: cache}
+	dir, changed, err := rb.Sync(context.Background())
+	if err != nil || !changed {
+		t.Fatalf("first sync: changed=%v err=%v", changed, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "rpc-hash-map.json")); err != nil {
+		t.Fatalf("archive not extracted: %v", err)
+	}
+
+	again, changed, err := rb.Sync(context.Background())
+	if err != nil || changed || again != dir || full.Load() != 1 {
+		t.Fatalf("unchanged sync: dir=%q changed=%v err=%v fetches=%d", again, changed, err, full.Load())
+	}
+
+	// A fresh RemoteBuild reuses the persisted cache (conditional request)
+	restarted := &RemoteBuild{Source: &HTTPSource{URL: srv.URL}, CacheDir: cache}
+	if d, changed, err := restarted.Sync(context.Background()); err != nil || changed || d != dir {
+		t.Fatalf("restart sync: dir=%q changed=%v err=%v", d, changed, err)
+	}
+
+	body = tarGz(t, map[string]string{"route-manifest.json": `{"routes":{}}`, "rpc-hash-map.json": `{"batch":"b2","procedures":{}}`})
+	etag = `"v2"`
+	next, changed, err := rb.Sync(context.Background())
+	if err != nil || !changed || next == dir {
+		t.Fatalf("new build: dir=%q changed=%v err=%v", next, changed, err)
+	}
+	if out := LoadBuild(next); out.RpcHashMap == nil || out.RpcHashMap.Batch != "b2" {
+		t.Errorf("expected new build contents, got %+v", out.RpcHashMap)
+	}
+}
+
+func TestRemoteBuildFallsBackToCache(t *testing.T) {
+	body := tarGz(t, map[string]string{"route-manifest.json": `{"routes":{}}`})
+	etag := `"v1"`
+	var full atomic.Int32
+	srv := buildArchiveServer(&body, &etag, &full)
+	cache := t.TempDir()
+	rb := &RemoteBuild{Source: &HTTPSource{URL: srv.URL}, CacheDir: cache}
+	dir, _, err := rb.Sync(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+
+	offline := &RemoteBuild{Source: &HTTPSource{URL: srv.URL}, CacheDir: cache}
+	got, _, err := offline.Sync(context.Background())
+	if err == nil || got != dir {
+		t.Fatalf("expected cached dir with error, got dir=%q err=%v", got, err)
+	}
+}
+
+func TestExtractTarGzRejectsTraversal(t *testing.T) {
+	archive := tarGz(t, map[string]string{"../evil.txt": "x"})
+	err := extractTarGz(bytes.NewReader(archive), t.TempDir())
+	if err == nil || !strings.Contains(err.Error(), "escapes") {
+		t.Fatalf("expected traversal error, got %v", err)
+	}
+}
+
+func TestRemoteBuildWatchActivatesBuild(t *testing.T) {
+	body := tarGz(t, map[string]string{"route-manifest.json": `{"routes":{}}`})
+	etag := `"v1"`
+	var full atomic.Int32
+	srv := buildArchiveServer(&body, &etag, &full)
+	defer srv.Close()
+
+	set := buildSetTestRouter().BuildSet()
+	rb := &RemoteBuild{Source: &HTTPSource{URL: srv.URL}, CacheDir: t.TempDir()}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := rb.Watch(ctx, set); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(set.Active(), "build-") {
+		t.Fatalf("active = %q", set.Active())
+	}
+	if code := postStatus(set, "greet", `{}`); code != http.StatusOK {
+		t.Errorf("status %d, want 200", code)
+	}
+}
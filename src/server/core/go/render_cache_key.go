@@ -0,0 +1,60 @@
+/* src/server/core/go/render_cache_key.go */
+
+package seam
+
+import (
+	"net/http"
+	"strings"
+)
+
+// RenderCacheKey lists what a rendered page varies by besides its URL.
+// With i18n in hidden mode one URL serves every locale, so any cache of
+// rendered pages must key on the resolved locale too.
+type RenderCacheKey struct {
+	URL            string // request URI (path and query)
+	Locale         string // resolved locale ("" when i18n is off)
+	PrincipalClass string // "anonymous" or "authenticated"
+	Variant        string // experiment variant from HandlerOptions.Variant
+}
+
+// String joins the parts with "|", e.g. "/home?x=1|de|anonymous|b".
+func (k RenderCacheKey) String() string {
+	return strings.Join([]string{k.URL, k.Locale, k.PrincipalClass, k.Variant}, "|")
+}
+
+// CacheKeyFunc maps a page request and its key parts to the key used by
+// render caches (HandlerOptions.CacheKey). Return key.String() to keep
+// the defaults; drop parts to share entries or add request properties
+// the page depends on.
+type CacheKeyFunc func(r *http.Request, key RenderCacheKey) string
+
+// renderCacheKey builds the cache key of a page request for locale.
+func (s *appState) renderCacheKey(r *http.Request, locale string) string {
+	key := RenderCacheKey{URL: r.URL.RequestURI(), Locale: locale, PrincipalClass: "anonymous"}
+	if s.opts.Principal != nil && s.opts.Principal(r) != "" {
+		key.PrincipalClass = "authenticated"
+	}
+	if s.opts.Variant != nil {
+		key.Variant = s.opts.Variant(r)
+	}
+	if s.opts.CacheKey != nil {
+		return s.opts.CacheKey(r, key)
+	}
+	return key.String()
+}
+
+// varyLocale tells downstream caches which request headers chose the
+// locale of a page served without a locale path prefix.
+func (s *appState) varyLocale(w http.ResponseWriter, r *http.Request) {
+	if s.i18nConfig == nil || r.PathValue("_seam_locale") != "" {
+		return
+	}
+	for _, strategy := range s.strategies {
+		switch strategy.Kind() {
+		case "accept_language":
+			w.Header().Add("Vary", "Accept-Language")
+		case "cookie":
+			w.Header().Add("Vary", "Cookie")
+		}
+	}
+}
@@ -0,0 +1,54 @@
+/* src/server/core/go/render_cache_key_test.go */
+
+package seam
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRenderCacheKeyParts(t *testing.T) {
+	s := &appState{opts: HandlerOptions{
+		Principal: func(r *http.Request) string { return r.Header.Get("X-User") },
+		Variant:   func(r *http.Request) string { return r.Header.Get("X-Bucket") },
+	}}
+	r := httptest.NewRequest("GET", "/_seam/page/home?tab=1", nil)
+	r.Header.Set("X-Bucket", "b")
+	if got := s.renderCacheKey(r, "de"); got != "/_seam/page/home?tab=1|de|anonymous|b" {
+		t.Fatalf("unexpected key %q", got)
+	}
+	r.Header.Set("X-User", "u1")
+	if got := s.renderCacheKey(r, "de"); got != "/_seam/page/home?tab=1|de|authenticated|b" {
+		t.Fatalf("unexpected key %q", got)
+	}
+
+	s.opts.CacheKey = func(r *http.Request, key RenderCacheKey) string {
+		key.Variant = ""
+		return key.String()
+	}
+	if got := s.renderCacheKey(r, "en"); got != "/_seam/page/home?tab=1|en|authenticated|" {
+		t.Fatalf("unexpected custom key %q", got)
+	}
+}
+
+func TestPageVaryLocale(t *testing.T) {
+	s := &appState{
+		i18nConfig: &I18nConfig{Locales: []string{"en", "de"}, Default: "en"},
+		strategies: []ResolveStrategy{FromCookie("lang"), FromAcceptLanguage()},
+	}
+	w := httptest.NewRecorder()
+	s.varyLocale(w, httptest.NewRequest("GET", "/_seam/page/home", nil))
+	if got := w.Header().Values("Vary"); len(got) != 2 || got[0] != "Cookie" || got[1] != "Accept-Language" {
+		t.Fatalf("unexpected Vary %v", got)
+	}
+
+	// A locale path prefix pins the locale
+	w = httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/_seam/page/de/home", nil)
+	r.SetPathValue("_seam_locale", "de")
+	s.varyLocale(w, r)
+	if got := w.Header().Values("Vary"); len(got) != 0 {
+		t.Fatalf("expected no Vary, got %v", got)
+	}
+}
@@ -0,0 +1,150 @@
+/* src/server/core/go/render_replay.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	engine "github.com/canmi21/seam/src/server/engine/go"
+)
+
+// RenderSnapshot is everything a page render fed the engine and the HTML
+// rewriting after it: the template as rendered (layouts, locale, and slot
+// filters applied), the loader data, the engine config, and the
+// post-processing settings. ReplayRender turns it back into the same HTML
+// without the procedures behind the loaders. Snapshots are plain JSON, so
+// they can be saved from RenderCapture and replayed in a test or on
+// another machine.
+type RenderSnapshot struct {
+	Route      string          `json:"route"`
+	Locale     string          `json:"locale,omitempty"`
+	CapturedAt time.Time       `json:"capturedAt"`
+	Template   string          `json:"template"`
+	Data       json.RawMessage `json:"data"`
+	Config     json.RawMessage `json:"config"`
+	I18n       json.RawMessage `json:"i18n,omitempty"`
+	HTML       string          `json:"html"` // output of the captured render
+
+	DataID       string       `json:"dataId"`
+	ExactNumbers bool         `json:"exactNumbers,omitempty"`
+	Dir          string       `json:"dir,omitempty"`
+	Robots       string       `json:"robots,omitempty"`
+	Links        *PageLinks   `json:"links,omitempty"`
+	DataScripts  []DataScript `json:"dataScripts,omitempty"`
+}
+
+// ReplayRender renders snap again with the engine and the same
+// post-processing as the live handler. Edit Template or Data before
+// replaying to bisect a template issue against the captured data.
+func ReplayRender(snap RenderSnapshot) (string, error) {
+	html, err := engine.RenderPage(snap.Template, string(snap.Data), string(snap.Config), string(snap.I18n))
+	if err != nil {
+		return "", renderError(snap.Route, err)
+	}
+	var exact map[string]json.RawMessage
+	if snap.ExactNumbers {
+		_ = json.Unmarshal(snap.Data, &exact)
+	}
+	return snap.postprocess(html, exact), nil
+}
+
+// postprocess applies the HTML rewriting that follows the engine call;
+// exact holds the per-key data encodings under ExactNumbers.
+func (snap *RenderSnapshot) postprocess(html string, exact map[string]json.RawMessage) string {
+	if exact != nil {
+		html = restoreDataNumbers(html, snap.DataID, exact)
+	}
+	html = escapeDataScript(html, snap.DataID)
+	if snap.Dir == "rtl" {
+		html = setHTMLDir(html, snap.Dir)
+	}
+	if snap.Dir != "" || len(snap.DataScripts) > 0 {
+		html = rewriteDataScripts(html, snap.DataID, snap.Dir, snap.DataScripts)
+	}
+	if snap.Robots != "" {
+		html = injectRobotsMeta(html, snap.Robots)
+	}
+	if snap.Links != nil {
+		html = injectPageLinks(withPageLinks(context.Background(), *snap.Links), html)
+	}
+	return html
+}
+
+// RenderCapture keeps the snapshots of recent page renders for
+// ReplayRender. It is a development aid: loader data is stored verbatim,
+// so the handler ignores it when SEAM_ENV or NODE_ENV is "production".
+// Serve it (it is an http.Handler) on an internal port to download the
+// snapshots as JSON, optionally narrowed with ?route=/users/:id.
+type RenderCapture struct {
+	PerRoute int // snapshots kept per route, oldest dropped first (default 5)
+
+	mu        sync.Mutex
+	snapshots map[string][]RenderSnapshot
+}
+
+func (c *RenderCapture) perRoute() int {
+	if c.PerRoute > 0 {
+		return c.PerRoute
+	}
+	return 5
+}
+
+func (c *RenderCapture) record(snap RenderSnapshot) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.snapshots == nil {
+		c.snapshots = make(map[string][]RenderSnapshot)
+	}
+	list := append(c.snapshots[snap.Route], snap)
+	if n := len(list) - c.perRoute(); n > 0 {
+		list = append([]RenderSnapshot(nil), list[n:]...)
+	}
+	c.snapshots[snap.Route] = list
+}
+
+// Snapshots returns the kept snapshots of route, oldest first; an empty
+// route returns those of every route, sorted by route.
+func (c *RenderCapture) Snapshots(route string) []RenderSnapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if route != "" {
+		return append([]RenderSnapshot(nil), c.snapshots[route]...)
+	}
+	routes := make([]string, 0, len(c.snapshots))
+	for r := range c.snapshots {
+		routes = append(routes, r)
+	}
+	sort.Strings(routes)
+	var all []RenderSnapshot
+	for _, r := range routes {
+		all = append(all, c.snapshots[r]...)
+	}
+	return all
+}
+
+// Last returns the newest snapshot of route.
+func (c *RenderCapture) Last(route string) (RenderSnapshot, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	list := c.snapshots[route]
+	if len(list) == 0 {
+		return RenderSnapshot{}, false
+	}
+	return list[len(list)-1], true
+}
+
+// ServeHTTP writes the snapshots (of ?route= when given) as a JSON array.
+func (c *RenderCapture) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	snaps := c.Snapshots(r.URL.Query().Get("route"))
+	if snaps == nil {
+		snaps = []RenderSnapshot{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(w).Encode(snaps)
+}
@@ -0,0 +1,88 @@
+/* src/server/core/go/render_replay_test.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func replayRouter(fail *bool) *Router {
+	page := &PageDef{
+		Route:       "/users/:id",
+		Template:    `<html><head></head><body><h1><!--seam:user.name--></h1></body></html>`,
+		Robots:      "noindex",
+		DataScripts: []DataScript{{ID: "__user", Keys: []string{"user"}}},
+		Loaders: []LoaderDef{{DataKey: "user", Procedure: "getUser", InputFn: func(p map[string]string) any {
+			return map[string]string{"id": p["id"]}
+		}}},
+	}
+	return NewRouter().
+		Procedure(Query("getUser", func(_ context.Context, in struct {
+			ID string `json:"id"`
+		}) (map[string]any, error) {
+			if *fail {
+				return nil, errors.New("upstream down")
+			}
+			return map[string]any{"name": "Ada " + in.ID, "score": json.Number("1.50")}, nil
+		})).
+		Page(page)
+}
+
+func TestReplayRenderMatchesCapturedRender(t *testing.T) {
+	fail := false
+	capture := &RenderCapture{PerRoute: 1}
+	h := replayRouter(&fail).Handler(HandlerOptions{RenderCapture: capture, ExactNumbers: true})
+	for _, id := range []string{"1", "2"} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/_seam/page/users/"+id, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("render: %d %s", w.Code, w.Body.String())
+		}
+	}
+	live := capture.Snapshots("/users/:id")
+	if len(live) != 1 || !strings.Contains(live[0].HTML, "<h1>Ada 2</h1>") {
+		t.Fatalf("snapshots %+v", live)
+	}
+
+	// Save and replay offline, with the upstream procedure unavailable.
+	fail = true
+	w := httptest.NewRecorder()
+	capture.ServeHTTP(w, httptest.NewRequest("GET", "/?route=/users/:id", nil))
+	var saved []RenderSnapshot
+	if err := json.Unmarshal(w.Body.Bytes(), &saved); err != nil || len(saved) != 1 {
+		t.Fatalf("saved %v %s", err, w.Body.String())
+	}
+	html, err := ReplayRender(saved[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if html != saved[0].HTML {
+		t.Errorf("replay differs:\n got %s\nwant %s", html, saved[0].HTML)
+	}
+	if !strings.Contains(html, `"score":1.50`) || !strings.Contains(html, `<meta name="robots" content="noindex">`) {
+		t.Errorf("replay lost post-processing:\n%s", html)
+	}
+
+	snap, _ := capture.Last("/users/:id")
+	snap.Template = strings.Replace(snap.Template, "<h1>", "<h2>", 1)
+	if html, err := ReplayRender(snap); err != nil || !strings.Contains(html, "<h2>Ada 2") {
+		t.Errorf("edited replay: %v %s", err, html)
+	}
+}
+
+func TestRenderCaptureIgnoredInProduction(t *testing.T) {
+	t.Setenv("SEAM_ENV", "production")
+	fail := false
+	capture := &RenderCapture{}
+	h := replayRouter(&fail).Handler(HandlerOptions{RenderCapture: capture})
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/_seam/page/users/1", nil))
+	if snaps := capture.Snapshots(""); len(snaps) != 0 {
+		t.Errorf("captured in production: %d", len(snaps))
+	}
+}
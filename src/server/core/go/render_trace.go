@@ -0,0 +1,126 @@
+/* src/server/core/go/render_trace.go */
+
+package seam
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TraceQueryParam requests a render trace of one page when the handler
+// opts in with HandlerOptions.RenderTraces, outside production
+// (SEAM_ENV/NODE_ENV=production): "1" appends the timeline to the HTML as
+// a comment; "json" returns it instead of the page in Chrome trace event
+// format, which Perfetto and speedscope show as a flamegraph. Traced
+// requests bypass coalescing.
+const TraceQueryParam = "__seam_trace"
+
+// renderTrace records the timeline of one page render.
+type renderTrace struct {
+	route string
+	json  bool
+	start time.Time
+
+	mu    sync.Mutex
+	spans []traceSpan
+}
+
+type traceSpan struct {
+	name  string
+	lane  int // 0 = request goroutine, n = loader n
+	start time.Duration
+	dur   time.Duration
+}
+
+type renderTraceKeyType struct{}
+
+var renderTraceKey = renderTraceKeyType{}
+
+// traceMode returns the requested trace mode ("1" or "json"), or "" when
+// tracing is off or not requested.
+func (s *appState) traceMode(r *http.Request) string {
+	if !s.renderTraces || r.URL.RawQuery == "" {
+		return ""
+	}
+	switch mode := r.URL.Query().Get(TraceQueryParam); mode {
+	case "1", "json":
+		return mode
+	}
+	return ""
+}
+
+func (s *appState) startTrace(r *http.Request, route string) *renderTrace {
+	mode := s.traceMode(r)
+	if mode == "" {
+		return nil
+	}
+	return &renderTrace{route: route, json: mode == "json", start: time.Now()}
+}
+
+func renderTraceOf(ctx context.Context) *renderTrace {
+	t, _ := ctx.Value(renderTraceKey).(*renderTrace)
+	return t
+}
+
+// span starts a span on lane; calling the result ends it. A nil trace
+// records nothing.
+func (t *renderTrace) span(name string, lane int) func() {
+	if t == nil {
+		return func() {}
+	}
+	started := time.Now()
+	return func() {
+		sp := traceSpan{name: name, lane: lane, start: started.Sub(t.start), dur: time.Since(started)}
+		t.mu.Lock()
+		t.spans = append(t.spans, sp)
+		t.mu.Unlock()
+	}
+}
+
+func (t *renderTrace) sorted() []traceSpan {
+	t.mu.Lock()
+	spans := append([]traceSpan(nil), t.spans...)
+	t.mu.Unlock()
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	return spans
+}
+
+// comment renders the timeline as an HTML comment, one span per line.
+func (t *renderTrace) comment() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<!--seam-trace page %s total %s\n", t.route, time.Since(t.start).Round(time.Microsecond))
+	for _, sp := range t.sorted() {
+		fmt.Fprintf(&b, "%10s %10s  %s\n", "+"+sp.start.Round(time.Microsecond).String(),
+			sp.dur.Round(time.Microsecond), strings.ReplaceAll(sp.name, "--", "- -"))
+	}
+	b.WriteString("-->")
+	return b.String()
+}
+
+// events renders the timeline in Chrome trace event format: complete
+// events (microseconds) under one page span, one thread per lane.
+func (t *renderTrace) events() map[string]any {
+	total := time.Since(t.start)
+	events := []map[string]any{
+		{"name": "thread_name", "ph": "M", "pid": 1, "tid": 0, "args": map[string]any{"name": "request"}},
+		{"name": "page " + t.route, "cat": "seam", "ph": "X", "ts": 0, "dur": micros(total), "pid": 1, "tid": 0},
+	}
+	lanes := map[int]bool{0: true}
+	for _, sp := range t.sorted() {
+		if !lanes[sp.lane] {
+			lanes[sp.lane] = true
+			events = append(events, map[string]any{"name": "thread_name", "ph": "M", "pid": 1, "tid": sp.lane,
+				"args": map[string]any{"name": fmt.Sprintf("loader %d", sp.lane)}})
+		}
+		events = append(events, map[string]any{"name": sp.name, "cat": "seam", "ph": "X",
+			"ts": micros(sp.start), "dur": micros(sp.dur), "pid": 1, "tid": sp.lane})
+	}
+	return map[string]any{"traceEvents": events, "displayTimeUnit": "ms"}
+}
+
+func micros(d time.Duration) float64 { return float64(d) / float64(time.Microsecond) }
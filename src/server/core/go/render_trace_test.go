@@ -0,0 +1,106 @@
+/* src/server/core/go/render_trace_test.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func tracedRouter() *Router {
+	input := func(map[string]string) any { return map[string]any{} }
+	return NewRouter().
+		Procedure(Query("getUser", func(context.Context, struct{}) (map[string]string, error) {
+			return map[string]string{"name": "Ada"}, nil
+		})).
+		Procedure(Query("getPosts", func(context.Context, struct{}) ([]string, error) { return []string{"a"}, nil })).
+		Page(&PageDef{
+			Route:    "/profile",
+			Template: `<html><body><p><!--seam:user.name--></p></body></html>`,
+			Loaders: []LoaderDef{
+				{DataKey: "user", Procedure: "getUser", InputFn: input},
+				{DataKey: "posts", Procedure: "getPosts", InputFn: input},
+			},
+			Coalesce: true,
+		})
+}
+
+func TestRenderTraceComment(t *testing.T) {
+	h := tracedRouter().Handler(HandlerOptions{RenderTraces: true})
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/_seam/page/profile?__seam_trace=1", nil))
+	body := w.Body.String()
+	i := strings.Index(body, "<!--seam-trace page /profile total ")
+	if w.Code != 200 || i < 0 || !strings.HasPrefix(body, "<html><body><p>Ada</p>") || !strings.HasSuffix(body, "-->") {
+		t.Fatalf("unexpected response %d:\n%s", w.Code, body)
+	}
+	for _, span := range []string{"params", "locale", "template", "loader user (getUser)", "loader posts (getPosts)", "serialize", "render (wasm)", "postprocess"} {
+		if !strings.Contains(body[i:], "  "+span+"\n") {
+			t.Errorf("missing span %q in\n%s", span, body[i:])
+		}
+	}
+	if w.Header().Get("Cache-Control") != "no-store" {
+		t.Errorf("Cache-Control = %q", w.Header().Get("Cache-Control"))
+	}
+
+	plain := httptest.NewRecorder()
+	h.ServeHTTP(plain, httptest.NewRequest("GET", "/_seam/page/profile", nil))
+	if strings.Contains(plain.Body.String(), "seam-trace") {
+		t.Fatalf("trace without the query flag: %s", plain.Body.String())
+	}
+}
+
+func TestRenderTraceJSON(t *testing.T) {
+	w := httptest.NewRecorder()
+	tracedRouter().Handler(HandlerOptions{RenderTraces: true}).ServeHTTP(w, httptest.NewRequest("GET", "/_seam/page/profile?__seam_trace=json", nil))
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("Content-Type = %q: %s", ct, w.Body.String())
+	}
+	var trace struct {
+		TraceEvents []struct {
+			Name string  `json:"name"`
+			Ph   string  `json:"ph"`
+			Ts   float64 `json:"ts"`
+			Dur  float64 `json:"dur"`
+			Tid  int     `json:"tid"`
+		} `json:"traceEvents"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &trace); err != nil {
+		t.Fatal(err)
+	}
+	tids := make(map[string]int)
+	var total float64
+	for _, ev := range trace.TraceEvents {
+		if ev.Ph == "X" {
+			tids[ev.Name] = ev.Tid
+			if ev.Name == "page /profile" {
+				total = ev.Dur
+			} else if ev.Ts+ev.Dur > total+1 {
+				t.Errorf("span %s ends after the page span", ev.Name)
+			}
+		}
+	}
+	if tids["render (wasm)"] != 0 || tids["loader user (getUser)"] != 1 || tids["loader posts (getPosts)"] != 2 {
+		t.Fatalf("unexpected lanes %v", tids)
+	}
+}
+
+func TestRenderTraceProduction(t *testing.T) {
+	t.Setenv("SEAM_ENV", "production")
+	w := httptest.NewRecorder()
+	tracedRouter().Handler(HandlerOptions{RenderTraces: true}).ServeHTTP(w, httptest.NewRequest("GET", "/_seam/page/profile?__seam_trace=1", nil))
+	if strings.Contains(w.Body.String(), "seam-trace") {
+		t.Fatalf("trace in production: %s", w.Body.String())
+	}
+}
+
+func TestRenderTraceNeedsOptIn(t *testing.T) {
+	w := httptest.NewRecorder()
+	tracedRouter().Handler().ServeHTTP(w, httptest.NewRequest("GET", "/_seam/page/profile?__seam_trace=1", nil))
+	if strings.Contains(w.Body.String(), "seam-trace") {
+		t.Fatalf("trace without RenderTraces: %s", w.Body.String())
+	}
+}
@@ -0,0 +1,131 @@
+/* src/server/core/go/resource_guard.go */
+
+package seam
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/metrics"
+	"sync"
+	"time"
+)
+
+// ResourceGuard watches heap and goroutine counts and degrades the
+// handler when a threshold is crossed, shedding expensive work before
+// the process is OOM-killed: new streams are refused, Optional loaders
+// are skipped, and the layout cache stops storing entries. It recovers
+// once both readings fall below 90% of their thresholds. Serve it as a
+// health endpoint: it answers 503 while degraded.
+type ResourceGuard struct {
+	MaxHeapBytes  uint64        // live heap threshold (0 = unchecked)
+	MaxGoroutines int           // goroutine threshold (0 = unchecked)
+	Interval      time.Duration // minimum time between samples (default 1s)
+	// OnChange is called when the guard enters or leaves degraded mode.
+	OnChange func(ResourceState)
+
+	mu      sync.Mutex
+	state   ResourceState
+	sampled time.Time
+	sample  func() (heapBytes uint64, goroutines int) // test hook
+}
+
+// ResourceState is the guard's last reading.
+type ResourceState struct {
+	Degraded   bool   `json:"degraded"`
+	Reason     string `json:"reason,omitempty"`
+	HeapBytes  uint64 `json:"heapBytes"`
+	Goroutines int    `json:"goroutines"`
+}
+
+// degradedFeatures lists what a degraded handler turns off, for health output.
+var degradedFeatures = []string{"streams", "optionalLoaders", "layoutCacheFill"}
+
+func (g *ResourceGuard) interval() time.Duration {
+	if g.Interval > 0 {
+		return g.Interval
+	}
+	return time.Second
+}
+
+// State samples the process (at most once per Interval) and returns the
+// current state.
+func (g *ResourceGuard) State() ResourceState {
+	g.mu.Lock()
+	if time.Since(g.sampled) < g.interval() {
+		state := g.state
+		g.mu.Unlock()
+		return state
+	}
+	g.sampled = time.Now()
+	sample := g.sample
+	if sample == nil {
+		sample = readResourceMetrics
+	}
+	prev := g.state
+	heap, goroutines := sample()
+	next := ResourceState{HeapBytes: heap, Goroutines: goroutines}
+	switch {
+	case g.MaxHeapBytes > 0 && heap > g.MaxHeapBytes:
+		next.Degraded, next.Reason = true, fmt.Sprintf("heap %d bytes over %d", heap, g.MaxHeapBytes)
+	case g.MaxGoroutines > 0 && goroutines > g.MaxGoroutines:
+		next.Degraded, next.Reason = true, fmt.Sprintf("%d goroutines over %d", goroutines, g.MaxGoroutines)
+	case prev.Degraded && !g.recovered(heap, goroutines):
+		next.Degraded, next.Reason = true, prev.Reason
+	}
+	g.state = next
+	g.mu.Unlock()
+
+	if next.Degraded != prev.Degraded {
+		if next.Degraded {
+			logf(slog.LevelWarn, "resource guard: degraded (%s)\n", next.Reason)
+		} else {
+			logf(slog.LevelInfo, "resource guard: recovered\n")
+		}
+		if g.OnChange != nil {
+			g.OnChange(next)
+		}
+	}
+	return next
+}
+
+// recovered applies hysteresis: both readings must drop below 90%.
+func (g *ResourceGuard) recovered(heap uint64, goroutines int) bool {
+	return (g.MaxHeapBytes == 0 || heap < g.MaxHeapBytes/10*9) &&
+		(g.MaxGoroutines == 0 || goroutines < g.MaxGoroutines*9/10)
+}
+
+// degraded reports whether expensive features are off; a nil guard never
+// degrades.
+func (g *ResourceGuard) degraded() bool {
+	return g != nil && g.State().Degraded
+}
+
+// ServeHTTP reports the guard's state: 200 when healthy, 503 with the
+// disabled features while degraded.
+func (g *ResourceGuard) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	state := g.State()
+	body := map[string]any{"status": "ok", "heapBytes": state.HeapBytes, "goroutines": state.Goroutines}
+	status := http.StatusOK
+	if state.Degraded {
+		body["status"], body["reason"], body["disabled"] = "degraded", state.Reason, degradedFeatures
+		status = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func readResourceMetrics() (uint64, int) {
+	samples := []metrics.Sample{
+		{Name: "/memory/classes/heap/objects:bytes"},
+		{Name: "/sched/goroutines:goroutines"},
+	}
+	metrics.Read(samples)
+	return samples[0].Value.Uint64(), int(samples[1].Value.Uint64())
+}
+
+func degradedError() *Error {
+	return NewError("UNAVAILABLE", "Server is degraded under resource pressure", http.StatusServiceUnavailable)
+}
@@ -0,0 +1,94 @@
+/* src/server/core/go/resource_guard_test.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func fakeGuard(heap *atomic.Uint64) *ResourceGuard {
+	g := &ResourceGuard{MaxHeapBytes: 1000, Interval: time.Nanosecond}
+	g.sample = func() (uint64, int) { return heap.Load(), 1 }
+	return g
+}
+
+func TestResourceGuardHysteresis(t *testing.T) {
+	var heap atomic.Uint64
+	var changes []bool
+	g := fakeGuard(&heap)
+	g.OnChange = func(s ResourceState) { changes = append(changes, s.Degraded) }
+
+	for _, step := range []struct {
+		heap uint64
+		want bool
+	}{{500, false}, {1200, true}, {950, true}, {850, false}} {
+		heap.Store(step.heap)
+		if got := g.State().Degraded; got != step.want {
+			t.Fatalf("heap %d: degraded=%v, want %v", step.heap, got, step.want)
+		}
+	}
+	if len(changes) != 2 || !changes[0] || changes[1] {
+		t.Fatalf("unexpected transitions %v", changes)
+	}
+}
+
+func TestResourceGuardDegradesHandler(t *testing.T) {
+	var heap atomic.Uint64
+	guard := fakeGuard(&heap)
+	var extraCalls atomic.Int32
+	router := NewRouter().
+		Procedure(Query("getMain", func(context.Context, struct{}) (string, error) { return "main", nil })).
+		Procedure(Query("getExtra", func(context.Context, struct{}) (string, error) {
+			extraCalls.Add(1)
+			return "extra", nil
+		})).
+		Stream(StreamProc("tail", func(context.Context, struct{}) (<-chan int, error) {
+			ch := make(chan int)
+			close(ch)
+			return ch, nil
+		})).
+		Page(&PageDef{
+			Route:    "/dash",
+			Template: "<html><body><!--seam:main-->|<!--seam:extra--></body></html>",
+			Loaders: []LoaderDef{
+				{DataKey: "main", Procedure: "getMain", InputFn: func(map[string]string) any { return map[string]any{} }},
+				{DataKey: "extra", Procedure: "getExtra", InputFn: func(map[string]string) any { return map[string]any{} }, Optional: true},
+			},
+		})
+	handler := router.Handler(HandlerOptions{ResourceGuard: guard})
+
+	heap.Store(2000)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/_seam/page/dash", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "main|") || extraCalls.Load() != 0 {
+		t.Fatalf("degraded page: status %d, extra calls %d: %s", w.Code, extraCalls.Load(), w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/_seam/procedure/tail", strings.NewReader(`{}`)))
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") == "" {
+		t.Fatalf("degraded stream: status %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	guard.ServeHTTP(w, httptest.NewRequest("GET", "/healthz", nil))
+	var health map[string]any
+	_ = json.Unmarshal(w.Body.Bytes(), &health)
+	if w.Code != http.StatusServiceUnavailable || health["status"] != "degraded" {
+		t.Fatalf("health: %d %v", w.Code, health)
+	}
+
+	heap.Store(100)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/_seam/page/dash", nil))
+	if !strings.Contains(w.Body.String(), "main|extra") || extraCalls.Load() != 1 {
+		t.Fatalf("recovered page: %s", w.Body.String())
+	}
+}
@@ -0,0 +1,126 @@
+/* src/server/core/go/response_hook.go */
+
+package seam
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// ResponseEvent is a finished response handed to HandlerOptions.OnResponse.
+// The hook may replace Body and edit Header; Status may be changed too.
+type ResponseEvent struct {
+	Request   *http.Request
+	Class     RouteClass
+	Procedure string // resolved procedure name for procedure routes
+	Status    int
+	Header    http.Header
+	Body      []byte
+}
+
+// responseHookMiddleware buffers responses and passes them through
+// OnResponse before they are sent. Subscriptions, streams, sockets, and
+// static routes (public files of any size) are not buffered; a response
+// that flushes or hijacks mid-way (streamed batches) switches to
+// pass-through and skips the hook.
+func (s *appState) responseHookMiddleware(hook func(*ResponseEvent), next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		class, procedure := s.classifyRoute(r)
+		switch class {
+		case RouteSubscription, RouteStream, RouteSocket, RouteStatic:
+			next.ServeHTTP(w, r)
+			return
+		}
+		bw := &bufferedResponse{ResponseWriter: w}
+		next.ServeHTTP(bw, r)
+		if bw.passthrough {
+			return
+		}
+		status := bw.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		if status == http.StatusNoContent || status == http.StatusNotModified || r.Method == http.MethodHead {
+			bw.ResponseWriter.WriteHeader(status)
+			return
+		}
+		ev := &ResponseEvent{Request: r, Class: class, Procedure: procedure, Status: status, Header: w.Header(), Body: bw.buf.Bytes()}
+		original := ev.Body
+		hook(ev)
+		if !bytes.Equal(ev.Body, original) {
+			// The validator no longer describes what is sent.
+			ev.Header.Del("ETag")
+		}
+		ev.Header.Set("Content-Length", strconv.Itoa(len(ev.Body)))
+		bw.ResponseWriter.WriteHeader(ev.Status)
+		_, _ = bw.ResponseWriter.Write(ev.Body)
+	})
+}
+
+// bufferedResponse holds the status and body until the handler returns,
+// unless the handler flushes or hijacks.
+type bufferedResponse struct {
+	http.ResponseWriter
+	status      int
+	buf         bytes.Buffer
+	passthrough bool
+}
+
+func (bw *bufferedResponse) WriteHeader(status int) {
+	if bw.passthrough {
+		bw.ResponseWriter.WriteHeader(status)
+		return
+	}
+	if bw.status == 0 {
+		bw.status = status
+	}
+}
+
+func (bw *bufferedResponse) Write(b []byte) (int, error) {
+	if bw.passthrough {
+		return bw.ResponseWriter.Write(b)
+	}
+	if bw.status == 0 {
+		bw.status = http.StatusOK
+	}
+	return bw.buf.Write(b)
+}
+
+// release sends what was buffered and stops buffering.
+func (bw *bufferedResponse) release() {
+	if bw.passthrough {
+		return
+	}
+	bw.passthrough = true
+	if bw.status != 0 {
+		bw.ResponseWriter.WriteHeader(bw.status)
+	}
+	if bw.buf.Len() > 0 {
+		_, _ = bw.ResponseWriter.Write(bw.buf.Bytes())
+		bw.buf.Reset()
+	}
+}
+
+func (bw *bufferedResponse) Flush() {
+	bw.release()
+	if f, ok := bw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (bw *bufferedResponse) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := bw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	bw.passthrough = true
+	return h.Hijack()
+}
+
+func (bw *bufferedResponse) Unwrap() http.ResponseWriter {
+	return bw.ResponseWriter
+}
@@ -0,0 +1,102 @@
+/* src/server/core/go/response_hook_test.go */
+
+package seam
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type hookUser struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+func TestOnResponseRewritesBodies(t *testing.T) {
+	var classes []RouteClass
+	router := NewRouter().
+		Procedure(Query("getUser", func(context.Context, struct{}) (hookUser, error) {
+			return hookUser{Name: "Alice", Email: "alice@example.com"}, nil
+		})).
+		Page(&PageDef{Route: "/", Template: `<html><body><p>home</p></body></html>`})
+	h := router.Handler(HandlerOptions{OnResponse: func(ev *ResponseEvent) {
+		classes = append(classes, ev.Class)
+		switch {
+		case ev.Class == RoutePage && strings.HasPrefix(ev.Header.Get("Content-Type"), "text/html"):
+			ev.Body = bytes.Replace(ev.Body, []byte("<body>"), []byte(`<body><div class="banner">Test environment</div>`), 1)
+		case ev.Procedure == "getUser" && ev.Request.Header.Get("X-Client") == "kiosk":
+			var resp struct {
+				OK   bool           `json:"ok"`
+				Data map[string]any `json:"data"`
+			}
+			if json.Unmarshal(ev.Body, &resp) == nil {
+				delete(resp.Data, "email")
+				ev.Body, _ = json.Marshal(resp)
+			}
+		}
+	}})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/_seam/page/", nil))
+	if !strings.Contains(w.Body.String(), `<body><div class="banner">Test environment</div><p>home</p>`) {
+		t.Errorf("page not rewritten: %s", w.Body.String())
+	}
+	if got := w.Header().Get("Content-Length"); got != "" && got != strconv.Itoa(w.Body.Len()) {
+		t.Errorf("Content-Length %s for %d bytes", got, w.Body.Len())
+	}
+
+	_, body := rpcBody(h, "/_seam/procedure/getUser", `{}`, http.Header{"X-Client": {"kiosk"}})
+	if strings.Contains(body, "email") || !strings.Contains(body, "Alice") {
+		t.Errorf("kiosk response %s", body)
+	}
+	_, body = rpcBody(h, "/_seam/procedure/getUser", `{}`, nil)
+	if !strings.Contains(body, "alice@example.com") {
+		t.Errorf("default response %s", body)
+	}
+	if len(classes) != 3 || classes[0] != RoutePage || classes[1] != RouteQuery {
+		t.Errorf("classes %v", classes)
+	}
+}
+
+func TestOnResponseSkipsStreams(t *testing.T) {
+	called := false
+	router := NewRouter().Subscription(&SubscriptionDef{
+		Name:        "ticks",
+		InputSchema: map[string]any{},
+		Handler: func(ctx context.Context, _ json.RawMessage) (<-chan SubscriptionEvent, error) {
+			ch := make(chan SubscriptionEvent, 1)
+			ch <- SubscriptionEvent{Value: 1}
+			close(ch)
+			return ch, nil
+		},
+	})
+	h := router.Handler(HandlerOptions{OnResponse: func(*ResponseEvent) { called = true }})
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/_seam/procedure/ticks?input=%7B%7D", nil))
+	if called || !strings.Contains(w.Body.String(), "data:") {
+		t.Errorf("hook called %v, body %q", called, w.Body.String())
+	}
+}
+
+func TestOnResponseSkipsPublicFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "app.js"), []byte("console.log(1)"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	called := false
+	h := NewRouter().Build(BuildOutput{PublicDir: dir}).
+		Handler(HandlerOptions{OnResponse: func(*ResponseEvent) { called = true }})
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/app.js", nil))
+	if called || w.Body.String() != "console.log(1)" {
+		t.Errorf("hook called %v, body %q", called, w.Body.String())
+	}
+}
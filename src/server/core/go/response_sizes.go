@@ -0,0 +1,235 @@
+/* src/server/core/go/response_sizes.go */
+
+package seam
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ResponseSizes records response body sizes per procedure and page route
+// and warns when a response exceeds its payload budget. Percentiles are
+// computed over the last Window responses of each target. Serve it (it
+// is an http.Handler) on an internal port for Prometheus-style scraping.
+//
+// Budgets are keyed by procedure name or page route ("/dashboard/:id");
+// Budget applies to targets without an entry. Each target warns once per
+// Window responses at most, so a hot endpoint does not flood the log.
+type ResponseSizes struct {
+	Window  int              // samples kept per target (default 1024)
+	Budget  int64            // default budget in bytes (0 = no default)
+	Budgets map[string]int64 // per-target budgets in bytes
+
+	mu      sync.Mutex
+	targets map[sizeKey]*sizeSamples
+}
+
+type sizeKey struct {
+	kind string // "procedure" or "page"
+	name string
+}
+
+type sizeSamples struct {
+	ring       []int64
+	next       int
+	count      int64
+	total      int64
+	max        int64
+	sinceWarn  int
+	overBudget int64
+}
+
+// SizeStats summarizes recorded response sizes for one target.
+type SizeStats struct {
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Count      int64  `json:"count"`
+	TotalBytes int64  `json:"totalBytes"`
+	P50        int64  `json:"p50"`
+	P90        int64  `json:"p90"`
+	P99        int64  `json:"p99"`
+	Max        int64  `json:"max"`
+	OverBudget int64  `json:"overBudget"` // responses above the budget
+}
+
+func (rs *ResponseSizes) window() int {
+	if rs.Window > 0 {
+		return rs.Window
+	}
+	return 1024
+}
+
+func (rs *ResponseSizes) budget(name string) int64 {
+	if b, ok := rs.Budgets[name]; ok {
+		return b
+	}
+	return rs.Budget
+}
+
+// record adds a sample and warns when it exceeds the target's budget.
+func (rs *ResponseSizes) record(kind, name string, size int64) {
+	rs.mu.Lock()
+	if rs.targets == nil {
+		rs.targets = make(map[sizeKey]*sizeSamples)
+	}
+	key := sizeKey{kind, name}
+	s, ok := rs.targets[key]
+	if !ok {
+		s = &sizeSamples{ring: make([]int64, 0, rs.window()), sinceWarn: rs.window()}
+		rs.targets[key] = s
+	}
+	if len(s.ring) < cap(s.ring) {
+		s.ring = append(s.ring, size)
+	} else {
+		s.ring[s.next] = size
+		s.next = (s.next + 1) % len(s.ring)
+	}
+	s.count++
+	s.total += size
+	s.max = max(s.max, size)
+	s.sinceWarn++
+
+	budget := rs.budget(name)
+	warn := false
+	if budget > 0 && size > budget {
+		s.overBudget++
+		if s.sinceWarn >= rs.window() {
+			s.sinceWarn = 0
+			warn = true
+		}
+	}
+	rs.mu.Unlock()
+
+	if warn {
+		logf(slog.LevelWarn, "%s %s response is %d bytes, over its %d byte budget\n", kind, name, size, budget)
+	}
+}
+
+// Snapshot returns stats for every recorded target, sorted by kind and name.
+func (rs *ResponseSizes) Snapshot() []SizeStats {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	stats := make([]SizeStats, 0, len(rs.targets))
+	for key, s := range rs.targets {
+		sorted := append([]int64(nil), s.ring...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		stats = append(stats, SizeStats{
+			Kind:       key.kind,
+			Name:       key.name,
+			Count:      s.count,
+			TotalBytes: s.total,
+			P50:        percentile(sorted, 0.50),
+			P90:        percentile(sorted, 0.90),
+			P99:        percentile(sorted, 0.99),
+			Max:        s.max,
+			OverBudget: s.overBudget,
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Kind != stats[j].Kind {
+			return stats[i].Kind < stats[j].Kind
+		}
+		return stats[i].Name < stats[j].Name
+	})
+	return stats
+}
+
+// percentile returns the nearest-rank percentile of sorted samples.
+func percentile(sorted []int64, q float64) int64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(q*float64(len(sorted))+0.5) - 1
+	idx = min(max(idx, 0), len(sorted)-1)
+	return sorted[idx]
+}
+
+// ServeHTTP writes the stats in the Prometheus text exposition format.
+func (rs *ResponseSizes) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	stats := rs.Snapshot()
+	fmt.Fprintln(w, "# TYPE seam_response_bytes summary")
+	for _, s := range stats {
+		labels := fmt.Sprintf(`kind=%q,name=%q`, s.Kind, s.Name)
+		for _, q := range []struct {
+			label string
+			value int64
+		}{{"0.5", s.P50}, {"0.9", s.P90}, {"0.99", s.P99}} {
+			fmt.Fprintf(w, "seam_response_bytes{%s,quantile=%q} %d\n", labels, q.label, q.value)
+		}
+		fmt.Fprintf(w, "seam_response_bytes_sum{%s} %d\n", labels, s.TotalBytes)
+		fmt.Fprintf(w, "seam_response_bytes_count{%s} %d\n", labels, s.Count)
+	}
+	fmt.Fprintln(w, "# TYPE seam_response_over_budget_total counter")
+	for _, s := range stats {
+		fmt.Fprintf(w, "seam_response_over_budget_total{kind=%q,name=%q} %d\n", s.Kind, s.Name, s.OverBudget)
+	}
+}
+
+// responseSizesMiddleware records body sizes of procedure and page
+// responses. Streaming responses (SSE, WS) are skipped; batches are
+// recorded as a whole under the "batch" procedure name, and calls of
+// unregistered names under "(unknown)".
+func (s *appState) responseSizesMiddleware(rs *ResponseSizes, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		class, procedure := s.classifyRoute(r)
+		var kind string
+		switch class {
+		case RouteQuery, RouteCommand, RouteUpload:
+			kind = "procedure"
+		case RouteBatch:
+			kind, procedure = "procedure", "batch"
+		case RoutePage:
+			kind = "page"
+		default:
+			next.ServeHTTP(w, r)
+			return
+		}
+		rec := newResponseRecorder(w)
+		next.ServeHTTP(rec, r)
+		var name string
+		switch class {
+		case RoutePage:
+			name = pageRouteName(r)
+		case RouteBatch:
+			name = procedure
+		default:
+			name = s.procedureLabel(procedure)
+		}
+		rs.record(kind, name, rec.bytes)
+	})
+}
+
+// pageRouteName converts the matched mux pattern back to the seam route
+// ("GET /_seam/page/users/{id}" -> "/users/:id"), dropping the locale
+// prefix segment. Unmatched requests share one name to bound cardinality.
+func pageRouteName(r *http.Request) string {
+	pattern := r.Pattern
+	if pattern == "" {
+		return "(unmatched)"
+	}
+	if _, path, ok := strings.Cut(pattern, " "); ok {
+		pattern = path
+	}
+	pattern = strings.TrimPrefix(pattern, "/_seam/page")
+	pattern = strings.TrimPrefix(pattern, "/{_seam_locale}")
+	parts := strings.Split(pattern, "/")
+	for i, p := range parts {
+		if strings.HasPrefix(p, "{") && strings.HasSuffix(p, "}") {
+			name := p[1 : len(p)-1]
+			if rest, ok := strings.CutSuffix(name, "..."); ok {
+				parts[i] = "*" + rest
+			} else {
+				parts[i] = ":" + name
+			}
+		}
+	}
+	if route := strings.Join(parts, "/"); route != "" {
+		return route
+	}
+	return "/"
+}
@@ -0,0 +1,69 @@
+/* src/server/core/go/response_sizes_test.go */
+
+package seam
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestResponseSizesRecordsProceduresAndPages(t *testing.T) {
+	sizes := &ResponseSizes{Budgets: map[string]int64{"getRepos": 10}}
+	handler := NewRouter().
+		Procedure(Query("getRepos", func(_ context.Context, _ struct{}) ([]string, error) {
+			return []string{"seam", "engine", "cli"}, nil
+		})).
+		Page(&PageDef{Route: "/users/:id", Template: "<html><body>user</body></html>"}).
+		Handler(HandlerOptions{ResponseSizes: sizes})
+
+	for range 3 {
+		postStatus(handler, "getRepos", `{}`)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/_seam/page/users/42", nil))
+
+	stats := sizes.Snapshot()
+	if len(stats) != 2 {
+		t.Fatalf("expected 2 targets, got %+v", stats)
+	}
+	page, proc := stats[0], stats[1]
+	if page.Kind != "page" || page.Name != "/users/:id" || page.Count != 1 || page.P50 != int64(w.Body.Len()) {
+		t.Errorf("unexpected page stats %+v (body %d)", page, w.Body.Len())
+	}
+	if proc.Kind != "procedure" || proc.Name != "getRepos" || proc.Count != 3 || proc.OverBudget != 3 {
+		t.Errorf("unexpected procedure stats %+v", proc)
+	}
+
+	w = httptest.NewRecorder()
+	sizes.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if !strings.Contains(w.Body.String(), `seam_response_bytes_count{kind="procedure",name="getRepos"} 3`) {
+		t.Errorf("unexpected metrics output:\n%s", w.Body.String())
+	}
+}
+
+func TestResponseSizesBucketsUnknownProcedures(t *testing.T) {
+	sizes := &ResponseSizes{}
+	handler := NewRouter().Handler(HandlerOptions{ResponseSizes: sizes})
+	for _, name := range []string{"nope1", "nope2", "nope3"} {
+		postStatus(handler, name, `{}`)
+	}
+	stats := sizes.Snapshot()
+	if len(stats) != 1 || stats[0].Name != "(unknown)" || stats[0].Count != 3 {
+		t.Fatalf("unknown names not bucketed: %+v", stats)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	if p := percentile(sorted, 0.5); p != 5 {
+		t.Errorf("p50 = %d", p)
+	}
+	if p := percentile(sorted, 0.99); p != 10 {
+		t.Errorf("p99 = %d", p)
+	}
+	if p := percentile(nil, 0.5); p != 0 {
+		t.Errorf("empty p50 = %d", p)
+	}
+}
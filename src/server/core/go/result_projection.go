@@ -0,0 +1,145 @@
+/* src/server/core/go/result_projection.go */
+
+package seam
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	// fieldsInputKey is the reserved input key carrying a result projection.
+	fieldsInputKey = "__fields"
+	// fieldsHeader carries a comma-separated projection for single RPC calls.
+	fieldsHeader = "X-Seam-Fields"
+)
+
+// takeFields removes the reserved __fields key from an object input and
+// returns the remaining input with the requested fields. Fields are
+// dot-separated paths into the output; for list outputs they apply to
+// each element. Inputs without the key are returned unchanged.
+func takeFields(input []byte) ([]byte, []string, *Error) {
+	rest, raw := takeReservedKey(input, fieldsInputKey)
+	if raw == nil {
+		return input, nil, nil
+	}
+	var fields []string
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, nil, ValidationError(fieldsInputKey + " must be an array of field paths")
+	}
+	return rest, fields, nil
+}
+
+// takeReservedKey removes a reserved key from an object input, returning
+// the remaining input and the key's raw value (nil when absent).
+func takeReservedKey(input []byte, key string) ([]byte, json.RawMessage) {
+	if !bytes.Contains(input, []byte(`"`+key+`"`)) {
+		return input, nil
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(input, &obj); err != nil {
+		return input, nil
+	}
+	raw, ok := obj[key]
+	if !ok {
+		return input, nil
+	}
+	delete(obj, key)
+	rest, _ := json.Marshal(obj)
+	return rest, raw
+}
+
+// headerFields parses the X-Seam-Fields header ("id,name,owner.login").
+func headerFields(r *http.Request) []string {
+	v := r.Header.Get(fieldsHeader)
+	if v == "" {
+		return nil
+	}
+	var fields []string
+	for _, f := range strings.Split(v, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// checkFields validates projection paths against the output schema. Paths
+// into schemas that do not describe properties (values, discriminator,
+// empty) are accepted as-is.
+func checkFields(fields []string, outputSchema any) *Error {
+	root := outputSchema
+	if m, ok := unwrapNullable(root).(map[string]any); ok {
+		if elems, ok := m["elements"]; ok {
+			root = elems
+		}
+	}
+	for _, field := range fields {
+		schema := root
+		for _, part := range splitDot(field) {
+			m, ok := unwrapNullable(schema).(map[string]any)
+			if !ok {
+				break
+			}
+			props, _ := m["properties"].(map[string]any)
+			optional, _ := m["optionalProperties"].(map[string]any)
+			if props == nil && optional == nil {
+				break
+			}
+			next, ok := props[part]
+			if !ok {
+				next, ok = optional[part]
+			}
+			if !ok {
+				return ValidationError(fmt.Sprintf("Unknown field '%s' in %s", field, fieldsInputKey))
+			}
+			schema = next
+		}
+	}
+	return nil
+}
+
+func unwrapNullable(schema any) any {
+	m, ok := schema.(map[string]any)
+	if !ok {
+		return schema
+	}
+	if nullable, _ := m["nullable"].(bool); !nullable {
+		return schema
+	}
+	inner := make(map[string]any, len(m)-1)
+	for k, v := range m {
+		if k != "nullable" {
+			inner[k] = v
+		}
+	}
+	return inner
+}
+
+// projectResult prunes a procedure result to the requested fields. List
+// results are projected element-wise.
+func projectResult(result any, fields []string) any {
+	raw, err := codecMarshal(result)
+	if err != nil {
+		return result
+	}
+	var generic any
+	if codecUnmarshal(raw, &generic) != nil {
+		return result
+	}
+	switch v := generic.(type) {
+	case []any:
+		for i, item := range v {
+			if m, ok := item.(map[string]any); ok {
+				v[i] = pickFields(m, fields)
+			}
+		}
+		return v
+	case map[string]any:
+		return pickFields(v, fields)
+	}
+	return generic
+}
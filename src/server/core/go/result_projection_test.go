@@ -0,0 +1,81 @@
+/* src/server/core/go/result_projection_test.go */
+
+package seam
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type projectionOwner struct {
+	Login  string `json:"login"`
+	Avatar string `json:"avatar"`
+}
+
+type projectionRepo struct {
+	ID          int             `json:"id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Owner       projectionOwner `json:"owner"`
+}
+
+func projectionRouter() *Router {
+	return NewRouter().
+		RpcHashMap(&RpcHashMap{Batch: "_batch", Procedures: map[string]string{"getUserRepos": "getUserRepos"}}).
+		Procedure(Query("getUserRepos", func(_ context.Context, in struct {
+			User string `json:"user"`
+		}) ([]projectionRepo, error) {
+			return []projectionRepo{
+				{ID: 1, Name: in.User + "/seam", Description: "long text", Owner: projectionOwner{Login: in.User, Avatar: "a.png"}},
+				{ID: 2, Name: in.User + "/engine", Description: "more text", Owner: projectionOwner{Login: in.User, Avatar: "a.png"}},
+			}, nil
+		}))
+}
+
+func rpcBody(h http.Handler, path, body string, header http.Header) (int, string) {
+	req := httptest.NewRequest("POST", path, strings.NewReader(body))
+	for k, vs := range header {
+		req.Header[k] = vs
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w.Code, strings.TrimSpace(w.Body.String())
+}
+
+func TestResultProjectionViaInput(t *testing.T) {
+	h := projectionRouter().Handler(HandlerOptions{})
+	code, body := rpcBody(h, "/_seam/procedure/getUserRepos", `{"user":"canmi","__fields":["id","owner.login"]}`, nil)
+	want := `{"data":[{"id":1,"owner":{"login":"canmi"}},{"id":2,"owner":{"login":"canmi"}}],"ok":true}`
+	if code != http.StatusOK || body != want {
+		t.Fatalf("got %d %s, want %s", code, body, want)
+	}
+}
+
+func TestResultProjectionViaHeader(t *testing.T) {
+	h := projectionRouter().Handler(HandlerOptions{})
+	code, body := rpcBody(h, "/_seam/procedure/getUserRepos", `{"user":"canmi"}`, http.Header{fieldsHeader: {"name"}})
+	want := `{"data":[{"name":"canmi/seam"},{"name":"canmi/engine"}],"ok":true}`
+	if code != http.StatusOK || body != want {
+		t.Fatalf("got %d %s, want %s", code, body, want)
+	}
+}
+
+func TestResultProjectionRejectsUnknownFields(t *testing.T) {
+	h := projectionRouter().Handler(HandlerOptions{})
+	code, body := rpcBody(h, "/_seam/procedure/getUserRepos", `{"user":"canmi","__fields":["owner.email"]}`, nil)
+	if code != http.StatusBadRequest || !strings.Contains(body, "owner.email") {
+		t.Fatalf("got %d %s", code, body)
+	}
+}
+
+func TestResultProjectionInBatch(t *testing.T) {
+	h := projectionRouter().Handler(HandlerOptions{})
+	code, body := rpcBody(h, "/_seam/procedure/_batch",
+		`{"calls":[{"procedure":"getUserRepos","input":{"user":"canmi","__fields":["id"]}}]}`, nil)
+	if code != http.StatusOK || !strings.Contains(body, `"data":[{"id":1},{"id":2}]`) {
+		t.Fatalf("got %d %s", code, body)
+	}
+}
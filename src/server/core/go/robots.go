@@ -0,0 +1,62 @@
+/* src/server/core/go/robots.go */
+
+package seam
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// robotsDirectives are the values accepted in PageDef.Robots.
+var robotsDirectives = map[string]bool{
+	"noindex": true, "nofollow": true, "none": true,
+	"noarchive": true, "nosnippet": true, "noimageindex": true,
+}
+
+var robotsMetaTag = regexp.MustCompile(`(?i)<meta\s[^>]*name\s*=\s*["']?robots["']?`)
+
+// normalizeRobots returns the comma-separated directives of a Robots
+// value in canonical form ("noindex, nofollow"), or an error naming an
+// unknown directive.
+func normalizeRobots(value string) (string, error) {
+	var out []string
+	for _, d := range strings.Split(value, ",") {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if d == "" {
+			continue
+		}
+		if !robotsDirectives[d] {
+			return "", fmt.Errorf("unknown robots directive %q", d)
+		}
+		out = append(out, d)
+	}
+	return strings.Join(out, ", "), nil
+}
+
+// checkRobots panics on a page whose Robots value is not a list of known
+// directives, and stores the canonical form.
+func checkRobots(page *PageDef) {
+	if page.Robots == "" {
+		return
+	}
+	robots, err := normalizeRobots(page.Robots)
+	if err != nil {
+		panic(fmt.Sprintf("page %q: %s", page.Route, err))
+	}
+	page.Robots = robots
+}
+
+// injectRobotsMeta adds <meta name="robots"> before </head> unless the
+// page already has one.
+func injectRobotsMeta(doc, robots string) string {
+	if robotsMetaTag.MatchString(doc) {
+		return doc
+	}
+	i := strings.Index(strings.ToLower(doc), "</head>")
+	if i < 0 {
+		return doc
+	}
+	return doc[:i] + `<meta name="robots" content="` + html.EscapeString(robots) + `">` + doc[i:]
+}
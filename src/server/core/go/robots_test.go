@@ -0,0 +1,63 @@
+/* src/server/core/go/robots_test.go */
+
+package seam
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRobotsHeaderAndMeta(t *testing.T) {
+	h := NewRouter().
+		Page(&PageDef{Route: "/dashboard", Robots: "noindex, NOFOLLOW", Template: `<html><head><title>Dash</title></head><body>dash</body></html>`}).
+		Page(&PageDef{Route: "/preview", Robots: "none", Template: `<html><head><meta name="robots" content="noindex"></head><body>preview</body></html>`}).
+		Page(&PageDef{Route: "/", Template: `<html><head></head><body>home</body></html>`}).
+		Handler()
+	get := func(path string) (string, string) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w.Header().Get("X-Robots-Tag"), w.Body.String()
+	}
+
+	tag, body := get("/_seam/page/dashboard")
+	if tag != "noindex, nofollow" {
+		t.Errorf("X-Robots-Tag = %q", tag)
+	}
+	if !strings.Contains(body, `<meta name="robots" content="noindex, nofollow"></head>`) {
+		t.Errorf("missing meta tag: %s", body)
+	}
+
+	tag, body = get("/_seam/page/preview")
+	if tag != "none" || strings.Count(body, `name="robots"`) != 1 {
+		t.Errorf("existing meta tag: %q %s", tag, body)
+	}
+
+	if tag, body = get("/_seam/page/"); tag != "" || strings.Contains(body, "robots") {
+		t.Errorf("unexpected robots on an indexable page: %q %s", tag, body)
+	}
+}
+
+func TestRobotsInvalidDirectivePanics(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(r.(string), `"noindx"`) {
+			t.Errorf("recover() = %v", r)
+		}
+	}()
+	NewRouter().Page(&PageDef{Route: "/x", Robots: "noindx", Template: "<html></html>"}).Handler()
+}
+
+func TestRobotsFromBuildManifest(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"route-manifest.json":  `{"routes": {"/admin": {"template": "templates/admin.html", "robots": "noindex"}}}`,
+		"templates/admin.html": `<html><head></head><body>admin</body></html>`,
+	})
+	pages, err := LoadBuildOutput(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 1 || pages[0].Robots != "noindex" {
+		t.Fatalf("pages %+v", pages)
+	}
+}
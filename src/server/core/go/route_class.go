@@ -0,0 +1,100 @@
+/* src/server/core/go/route_class.go */
+
+package seam
+
+import (
+	"net/http"
+	"strings"
+)
+
+// RouteClass groups /_seam endpoints for per-class policies (access log
+// sampling, IP filtering, rate limits).
+type RouteClass string
+
+const (
+	RouteManifest     RouteClass = "manifest"
+	RouteQuery        RouteClass = "query"
+	RouteCommand      RouteClass = "command"
+	RouteSubscription RouteClass = "subscription"
+	RouteStream       RouteClass = "stream"
+	RouteUpload       RouteClass = "upload"
+	RouteBatch        RouteClass = "batch"
+	RoutePage         RouteClass = "page"
+	RoutePageData     RouteClass = "data"
+	RouteSocket       RouteClass = "socket" // persistent WebSocket RPC (/_seam/ws)
+	RouteStatic       RouteClass = "static" // non-/_seam requests (public files)
+)
+
+// classifyRoute returns the class of a request and, for procedure
+// endpoints, the resolved procedure name.
+func (s *appState) classifyRoute(r *http.Request) (RouteClass, string) {
+	path := r.URL.Path
+	switch {
+	case path == "/_seam/manifest.json":
+		return RouteManifest, ""
+	case strings.HasPrefix(path, "/_seam/page/"):
+		return RoutePage, ""
+	case strings.HasPrefix(path, "/_seam/data/"):
+		return RoutePageData, ""
+	case path == wsRPCPath:
+		return RouteSocket, ""
+	case !strings.HasPrefix(path, "/_seam/procedure/"):
+		return RouteStatic, ""
+	}
+
+	name := strings.TrimPrefix(path, "/_seam/procedure/")
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		// GET /_seam/procedure/{name}/poll polls the subscription;
+		// other GETs are subscriptions or cacheable queries
+		if sub, ok := strings.CutSuffix(name, "/poll"); ok {
+			return RouteSubscription, sub
+		}
+		if _, ok := s.subs[name]; ok || isWebSocketUpgrade(r) {
+			return RouteSubscription, name
+		}
+		if query, _, _, ok := s.cacheableQuery(r, name); ok {
+			return RouteQuery, query
+		}
+		return RouteSubscription, name
+	}
+	if s.batchHash != "" && name == s.batchHash {
+		return RouteBatch, ""
+	}
+	if _, ok := s.subs[name]; ok {
+		return RouteSubscription, name
+	}
+	if s.hashToName != nil {
+		if resolved, ok := s.hashToName[name]; ok {
+			name = resolved
+		}
+	}
+	name = s.resolveVersion(name, r)
+	switch s.kindMap[name] {
+	case "command":
+		return RouteCommand, name
+	case "stream":
+		return RouteStream, name
+	case "upload":
+		return RouteUpload, name
+	}
+	if p, ok := s.handlers[name]; ok && p.Type == "command" {
+		return RouteCommand, name
+	}
+	return RouteQuery, name
+}
+
+// unknownProcedure labels calls of names that are not registered in
+// per-procedure stats, so client-chosen URLs cannot grow their key sets.
+const unknownProcedure = "(unknown)"
+
+// procedureLabel returns name when it is a registered procedure,
+// subscription, stream, or upload, else unknownProcedure.
+func (s *appState) procedureLabel(name string) string {
+	if _, ok := s.kindMap[name]; ok {
+		return name
+	}
+	if _, ok := s.subs[name]; ok {
+		return name
+	}
+	return unknownProcedure
+}
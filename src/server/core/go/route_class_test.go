@@ -0,0 +1,38 @@
+/* src/server/core/go/route_class_test.go */
+
+package seam
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClassifyRoute(t *testing.T) {
+	s := &appState{
+		handlers: map[string]*ProcedureDef{"getUser": {Name: "getUser"}, "save": {Name: "save", Type: "command"}},
+		subs:     map[string]*SubscriptionDef{"live": {Name: "live"}},
+		kindMap:  map[string]string{"upload": "upload", "tail": "stream"},
+	}
+	cases := []struct {
+		method, path string
+		class        RouteClass
+		procedure    string
+	}{
+		{"GET", "/_seam/manifest.json", RouteManifest, ""},
+		{"GET", "/_seam/page/home", RoutePage, ""},
+		{"GET", "/_seam/data/home", RoutePageData, ""},
+		{"GET", "/_seam/procedure/live", RouteSubscription, "live"},
+		{"POST", "/_seam/procedure/live", RouteSubscription, "live"},
+		{"POST", "/_seam/procedure/getUser", RouteQuery, "getUser"},
+		{"POST", "/_seam/procedure/save", RouteCommand, "save"},
+		{"POST", "/_seam/procedure/upload", RouteUpload, "upload"},
+		{"POST", "/_seam/procedure/tail", RouteStream, "tail"},
+		{"GET", "/favicon.ico", RouteStatic, ""},
+	}
+	for _, tc := range cases {
+		class, proc := s.classifyRoute(httptest.NewRequest(tc.method, tc.path, nil))
+		if class != tc.class || proc != tc.procedure {
+			t.Errorf("%s %s: got (%s, %q), want (%s, %q)", tc.method, tc.path, class, proc, tc.class, tc.procedure)
+		}
+	}
+}
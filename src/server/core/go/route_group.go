@@ -0,0 +1,79 @@
+/* src/server/core/go/route_group.go */
+
+package seam
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// RouteGroup attaches shared loaders and middleware to every page whose
+// route falls under Prefix (segment-aware: "/settings" covers "/settings"
+// and "/settings/profile", not "/settingsx"). Group loaders are added as
+// page-level loaders; a page loader with the same data key wins. Groups
+// apply in registration order, the first group's middleware outermost.
+type RouteGroup struct {
+	Prefix     string
+	Loaders    []LoaderDef
+	Middleware []func(http.Handler) http.Handler
+}
+
+// Group registers a route group. Panics when Prefix does not start with "/".
+func (r *Router) Group(g RouteGroup) *Router {
+	if !strings.HasPrefix(g.Prefix, "/") {
+		panic(fmt.Sprintf("route group prefix %q must start with \"/\"", g.Prefix))
+	}
+	g.Prefix = strings.TrimSuffix(g.Prefix, "/")
+	r.groups = append(r.groups, g)
+	return r
+}
+
+func (g *RouteGroup) matches(route string) bool {
+	if g.Prefix == "" {
+		return true
+	}
+	return route == g.Prefix || strings.HasPrefix(route, g.Prefix+"/")
+}
+
+// applyRouteGroups returns copies of pages with matching group loaders and
+// middleware merged in; the input slice is left untouched.
+func applyRouteGroups(pages []PageDef, groups []RouteGroup) []PageDef {
+	if len(groups) == 0 {
+		return pages
+	}
+	out := make([]PageDef, len(pages))
+	for i, page := range pages {
+		var loaders []LoaderDef
+		var keys []string
+		var middleware []func(http.Handler) http.Handler
+		own := make(map[string]bool, len(page.Loaders))
+		for _, ld := range page.Loaders {
+			own[ld.DataKey] = true
+		}
+		for gi := range groups {
+			g := &groups[gi]
+			if !g.matches(page.Route) {
+				continue
+			}
+			for _, ld := range g.Loaders {
+				if own[ld.DataKey] {
+					continue
+				}
+				own[ld.DataKey] = true
+				loaders = append(loaders, ld)
+				keys = append(keys, ld.DataKey)
+			}
+			middleware = append(middleware, g.Middleware...)
+		}
+		if len(loaders) > 0 {
+			page.Loaders = append(append([]LoaderDef{}, page.Loaders...), loaders...)
+			page.PageLoaderKeys = append(append([]string{}, page.PageLoaderKeys...), keys...)
+		}
+		if len(middleware) > 0 {
+			page.Middleware = append(middleware, page.Middleware...)
+		}
+		out[i] = page
+	}
+	return out
+}
@@ -0,0 +1,83 @@
+/* src/server/core/go/route_group_test.go */
+
+package seam
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRouteGroupSharesLoadersAndMiddleware(t *testing.T) {
+	empty := func(map[string]string) any { return map[string]any{} }
+	requireUser := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-User") == "" {
+				http.Redirect(w, r, "/login", http.StatusFound)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+	router := NewRouter().
+		Procedure(Query("getPerms", func(_ context.Context, _ struct{}) (map[string]string, error) {
+			return map[string]string{"role": "admin"}, nil
+		})).
+		Group(RouteGroup{
+			Prefix:     "/settings",
+			Loaders:    []LoaderDef{{DataKey: "perms", Procedure: "getPerms", InputFn: empty}},
+			Middleware: []func(http.Handler) http.Handler{requireUser},
+		}).
+		Page(&PageDef{Route: "/settings/profile", Template: "<html><body>role=<!--seam:perms.role--></body></html>"}).
+		Page(&PageDef{Route: "/settingsx", Template: "<html><body>other</body></html>"})
+	handler := router.Handler()
+
+	req := httptest.NewRequest("GET", "/_seam/page/settings/profile", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusFound {
+		t.Fatalf("group middleware should redirect anonymous users, got %d", w.Code)
+	}
+
+	req.Header.Set("X-User", "alice")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "role=admin") {
+		t.Fatalf("expected group loader data, got %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/_seam/page/settingsx", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("prefix match must be segment-aware, got %d", w.Code)
+	}
+}
+
+func TestApplyRouteGroupsPageLoaderWins(t *testing.T) {
+	pages := []PageDef{{
+		Route:   "/settings",
+		Loaders: []LoaderDef{{DataKey: "perms", Procedure: "pagePerms"}},
+	}}
+	groups := []RouteGroup{{Prefix: "/settings", Loaders: []LoaderDef{
+		{DataKey: "perms", Procedure: "groupPerms"},
+		{DataKey: "session", Procedure: "getSession"},
+	}}}
+	out := applyRouteGroups(pages, groups)
+	if len(out[0].Loaders) != 2 || out[0].Loaders[0].Procedure != "pagePerms" || out[0].Loaders[1].DataKey != "session" {
+		t.Fatalf("unexpected loaders: %+v", out[0].Loaders)
+	}
+	if len(pages[0].Loaders) != 1 {
+		t.Fatal("input pages must not be modified")
+	}
+}
+
+func TestGroupPanicsOnRelativePrefix(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic")
+		}
+	}()
+	NewRouter().Group(RouteGroup{Prefix: "settings"})
+}
@@ -0,0 +1,107 @@
+/* src/server/core/go/router_clone.go */
+
+package seam
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"path"
+	"slices"
+)
+
+// Overlay is what one environment changes on a shared registration list:
+// procedures swapped for fakes in dev and test, options applied to groups
+// of procedures, tighter handler timeouts. Apply it with WithOverlay or
+// ForEnv; the base router is left untouched.
+type Overlay struct {
+	// Procedures and Subscriptions replace the registrations of the same
+	// name; a name the router does not register panics.
+	Procedures    []*ProcedureDef
+	Subscriptions []*SubscriptionDef
+	// ProcedureOptions applies options to the procedures whose name
+	// matches the path.Match pattern ("admin.*", "*"). WithSandbox only
+	// takes effect on procedures registered without a quota.
+	ProcedureOptions map[string][]ProcedureOption
+	// Options adjusts the HandlerOptions of every handler built from the
+	// router, after defaults are applied (e.g. a shorter RPCTimeout).
+	Options func(*HandlerOptions)
+}
+
+// Clone returns a copy of the router whose registrations can be changed
+// without affecting r. Handlers, state, and build artifacts are shared;
+// the copy gets its own hub and ActiveStreams, so invalidations do not
+// cross between routers built for different environments.
+func (r *Router) Clone() *Router {
+	c := *r
+	c.procedures = slices.Clone(r.procedures)
+	c.subscriptions = slices.Clone(r.subscriptions)
+	c.streams = slices.Clone(r.streams)
+	c.uploads = slices.Clone(r.uploads)
+	c.channels = slices.Clone(r.channels)
+	c.pages = slices.Clone(r.pages)
+	c.strategies = slices.Clone(r.strategies)
+	c.contextConfigs = maps.Clone(r.contextConfigs)
+	c.groups = slices.Clone(r.groups)
+	c.redirects = slices.Clone(r.redirects)
+	c.optionOverlays = slices.Clone(r.optionOverlays)
+	c.hub = nil
+	c.tracker = nil
+	c.served = nil
+	return &c
+}
+
+// WithOverlay returns a clone of r with o applied.
+func (r *Router) WithOverlay(o Overlay) *Router {
+	c := r.Clone()
+	for _, def := range o.Procedures {
+		i := slices.IndexFunc(c.procedures, func(p ProcedureDef) bool { return p.Name == def.Name })
+		if i < 0 {
+			panic(fmt.Sprintf("overlay replaces procedure %q, which is not registered", def.Name))
+		}
+		c.procedures[i] = sandboxed(*def)
+	}
+	for _, def := range o.Subscriptions {
+		i := slices.IndexFunc(c.subscriptions, func(s SubscriptionDef) bool { return s.Name == def.Name })
+		if i < 0 {
+			panic(fmt.Sprintf("overlay replaces subscription %q, which is not registered", def.Name))
+		}
+		c.subscriptions[i] = *def
+	}
+	patterns := slices.Sorted(maps.Keys(o.ProcedureOptions))
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			panic(fmt.Sprintf("overlay procedure pattern %q: %v", pattern, err))
+		}
+		for i := range c.procedures {
+			def := &c.procedures[i]
+			if ok, _ := path.Match(pattern, def.Name); !ok {
+				continue
+			}
+			unsandboxed := def.Sandbox == nil
+			for _, opt := range o.ProcedureOptions[pattern] {
+				opt(def)
+			}
+			if unsandboxed && def.Sandbox != nil {
+				*def = sandboxed(*def)
+			}
+		}
+	}
+	if o.Options != nil {
+		c.optionOverlays = append(c.optionOverlays, o.Options)
+	}
+	return c
+}
+
+// ForEnv returns a clone of r with the overlay named by SEAM_ENV applied,
+// or an unchanged clone when SEAM_ENV has no overlay.
+func (r *Router) ForEnv(overlays map[string]Overlay) *Router {
+	env := os.Getenv("SEAM_ENV")
+	o, ok := overlays[env]
+	if !ok {
+		return r.Clone()
+	}
+	logf(slog.LevelInfo, "router overlay applied (SEAM_ENV=%s)\n", env)
+	return r.WithOverlay(o)
+}
@@ -0,0 +1,79 @@
+/* src/server/core/go/router_clone_test.go */
+
+package seam
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func cloneBaseRouter() *Router {
+	return NewRouter().
+		Procedure(Query("getUser", func(context.Context, struct{}) (string, error) { return "real", nil })).
+		Procedure(Command("admin.purge", func(ctx context.Context, _ struct{}) (bool, error) {
+			select {
+			case <-ctx.Done():
+				return false, ctx.Err()
+			case <-time.After(50 * time.Millisecond):
+				return true, nil
+			}
+		}))
+}
+
+func TestRouterCloneIsIndependent(t *testing.T) {
+	base := cloneBaseRouter()
+	c := base.Clone()
+	c.Procedure(Query("extra", func(context.Context, struct{}) (bool, error) { return true, nil }))
+	if len(base.Procedures()) != 2 || len(c.Procedures()) != 3 {
+		t.Errorf("procedures: base %d, clone %d", len(base.Procedures()), len(c.Procedures()))
+	}
+	if base.Hub() == c.Hub() {
+		t.Error("clone shares the hub")
+	}
+}
+
+func TestRouterOverlay(t *testing.T) {
+	base := cloneBaseRouter()
+	fake := Query("getUser", func(context.Context, struct{}) (string, error) { return "fake", nil })
+	overlays := map[string]Overlay{
+		"test": {
+			Procedures:       []*ProcedureDef{fake},
+			ProcedureOptions: map[string][]ProcedureOption{"admin.*": {WithDeprecated("use jobs.purge", "")}},
+			Options:          func(o *HandlerOptions) { o.RPCTimeout = 10 * time.Millisecond },
+		},
+	}
+	t.Setenv("SEAM_ENV", "test")
+	h := base.ForEnv(overlays).Handler()
+
+	if _, body := rpcBody(h, "/_seam/procedure/getUser", `{}`, nil); !strings.Contains(body, `"fake"`) {
+		t.Errorf("overlaid procedure: %s", body)
+	}
+	if code, _ := rpcBody(h, "/_seam/procedure/admin.purge", `{}`, nil); code != http.StatusGatewayTimeout {
+		t.Errorf("tighter timeout: %d", code)
+	}
+	infos := base.ForEnv(overlays).Procedures()
+	for _, info := range infos {
+		if info.Name == "admin.purge" && info.Deprecated == nil {
+			t.Errorf("option not applied: %+v", info)
+		}
+	}
+
+	// The base router is untouched.
+	h = base.Handler()
+	if _, body := rpcBody(h, "/_seam/procedure/getUser", `{}`, nil); !strings.Contains(body, `"real"`) {
+		t.Errorf("base procedure: %s", body)
+	}
+	if code, _ := rpcBody(h, "/_seam/procedure/admin.purge", `{}`, nil); code != http.StatusOK {
+		t.Errorf("base timeout: %d", code)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("replacing an unregistered procedure should panic")
+		}
+	}()
+	base.WithOverlay(Overlay{Procedures: []*ProcedureDef{Query("missing", func(context.Context, struct{}) (bool, error) { return true, nil })}})
+}
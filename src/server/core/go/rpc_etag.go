@@ -0,0 +1,276 @@
+/* src/server/core/go/rpc_etag.go */
+
+package seam
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cacheTTL reports whether a procedure is a cacheable query and its TTL
+// in seconds (0 when the cache hint has no ttl). Cache hints are
+// false | true | {"ttl": N} | CacheHints.
+func cacheTTL(proc *ProcedureDef) (int, bool) {
+	if proc.Type == "command" || proc.Cache == nil {
+		return 0, false
+	}
+	switch c := proc.Cache.(type) {
+	case bool:
+		return 0, c
+	case CacheHints:
+		return c.MaxAge, true
+	case *CacheHints:
+		if c == nil {
+			return 0, false
+		}
+		return c.MaxAge, true
+	case map[string]any:
+		switch ttl := c["ttl"].(type) {
+		case int:
+			return ttl, true
+		case int64:
+			return int(ttl), true
+		case float64:
+			return int(ttl), true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// hasCacheableQuery reports whether any registered query can answer with
+// an ETag, i.e. whether the ETag memo needs invalidations.
+func (s *appState) hasCacheableQuery() bool {
+	for _, proc := range s.handlers {
+		if _, ok := cacheHints(proc); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// cacheableQuery returns the cacheable query a GET or HEAD of
+// /_seam/procedure/{name} names, resolving hashes and versions.
+func (s *appState) cacheableQuery(r *http.Request, name string) (string, *ProcedureDef, CacheHints, bool) {
+	if s.hashToName != nil {
+		resolved, ok := s.hashToName[name]
+		if !ok {
+			return "", nil, CacheHints{}, false
+		}
+		name = resolved
+	}
+	name = s.resolveVersion(name, r)
+	proc, ok := s.handlers[name]
+	if !ok {
+		return "", nil, CacheHints{}, false
+	}
+	hints, ok := cacheHints(proc)
+	return name, proc, hints, ok
+}
+
+// handleQueryGet serves a GET or HEAD call of a cacheable query, with the
+// input in the ?input= query parameter ({} when absent). Only these
+// responses carry an ETag. A request whose If-None-Match names the ETag
+// served to the same caller for the same input, vary headers, and context
+// fields gets 304 without running the query while that response is
+// fresh (its max-age has not passed and the query was not invalidated);
+// otherwise the query runs and 304 is answered when the new body is
+// unchanged.
+func (s *appState) handleQueryGet(w http.ResponseWriter, r *http.Request, name string, proc *ProcedureDef, hints CacheHints) {
+	input := []byte("{}")
+	if raw := r.URL.Query().Get("input"); raw != "" {
+		input = []byte(raw)
+	}
+	if inputErr := s.checkInput(input); inputErr != nil {
+		writeError(w, errorHTTPStatus(inputErr), inputErr)
+		return
+	}
+	if !json.Valid(input) {
+		writeError(w, http.StatusBadRequest, ValidationError("Invalid JSON"))
+		return
+	}
+	warning := s.deprecationWarning(r, name, proc)
+	if warning != "" {
+		addWarningHeader(w, warning)
+	}
+	hints.MaxAge = s.opts.Tuning.queryMaxAgeOf(hints.MaxAge)
+
+	key := s.etagKey(r, name, proc, hints, input)
+	if etag, ok := s.etags.fresh(key); ok && etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.Header().Set("ETag", etag)
+		hints.setHeaders(w.Header())
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	result, seamErr := s.callProcedure(r, name, proc, input)
+	if seamErr != nil {
+		writeError(w, errorHTTPStatus(seamErr), seamErr)
+		return
+	}
+	envelope := map[string]any{"ok": true, "data": result}
+	if warning != "" {
+		envelope["meta"] = resultMeta{Warnings: []string{warning}}
+	}
+	if etag := writeCacheableJSON(w, r, hints, envelope); etag != "" && hints.MaxAge > 0 {
+		s.etags.set(key, name, input, etag, time.Duration(hints.MaxAge)*time.Second)
+	}
+}
+
+// etagKey identifies what a cacheable query response depends on: the
+// query, input, principal, vary headers, and context fields.
+func (s *appState) etagKey(r *http.Request, name string, proc *ProcedureDef, hints CacheHints, input []byte) string {
+	var b strings.Builder
+	b.WriteString(name + "\x00" + PrincipalOf(s.requestContext(r)) + "\x00" + string(input))
+	for _, header := range hints.VaryOn {
+		b.WriteString("\x00" + r.Header.Get(header))
+	}
+	if len(s.contextConfigs) > 0 && len(proc.ContextKeys) > 0 {
+		fields, _ := json.Marshal(resolveContextForProc(extractRawContext(r, s.contextConfigs), proc.ContextKeys))
+		b.WriteString("\x00" + string(fields))
+	}
+	return b.String()
+}
+
+// writeCacheableJSON writes a cacheable query response with a strong ETag
+// derived from the encoded body, answering 304 Not Modified when the
+// request's If-None-Match already names it, and returns the ETag ("" on
+// failure). Responses are private since query results may depend on the
+// principal.
+func writeCacheableJSON(w http.ResponseWriter, r *http.Request, hints CacheHints, v any) string {
+	body, err := codecMarshal(v)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, InternalError(err.Error()))
+		return ""
+	}
+	body = append(body, '\n')
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	h := w.Header()
+	h.Set("ETag", etag)
+	hints.setHeaders(h)
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return etag
+	}
+	h.Set("Content-Type", "application/json")
+	_, _ = w.Write(body)
+	return etag
+}
+
+// etagMemoSweepSize triggers a sweep of expired entries on insert.
+const etagMemoSweepSize = 4096
+
+// etagMemo remembers the ETags of fresh cacheable query responses, so a
+// revalidation can be answered without running the query. Entries expire
+// with the response's max-age and are dropped when their procedure is
+// invalidated through the hub.
+type etagMemo struct {
+	mu      sync.Mutex
+	entries map[string]etagMemoEntry
+}
+
+type etagMemoEntry struct {
+	etag      string
+	procedure string
+	input     []byte
+	expires   time.Time
+}
+
+func newETagMemo() *etagMemo {
+	return &etagMemo{entries: make(map[string]etagMemoEntry)}
+}
+
+// fresh returns the ETag of a response for key that is still fresh.
+func (m *etagMemo) fresh(key string) (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[key]
+	if !ok {
+		return "", false
+	}
+	if time.Now().After(e.expires) {
+		delete(m.entries, key)
+		return "", false
+	}
+	return e.etag, true
+}
+
+func (m *etagMemo) set(key, procedure string, input []byte, etag string, maxAge time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	if len(m.entries) >= etagMemoSweepSize {
+		for k, e := range m.entries {
+			if now.After(e.expires) {
+				delete(m.entries, k)
+			}
+		}
+		if len(m.entries) >= etagMemoSweepSize {
+			clear(m.entries)
+		}
+	}
+	m.entries[key] = etagMemoEntry{etag: etag, procedure: procedure, input: input, expires: now.Add(maxAge)}
+}
+
+// invalidate drops entries matching the keys; a nil Input matches every
+// input of the procedure.
+func (m *etagMemo) invalidate(keys []InvalidationKey) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, key := range keys {
+		var input []byte
+		if key.Input != nil {
+			input, _ = json.Marshal(key.Input)
+		}
+		for k, e := range m.entries {
+			if e.procedure == key.Procedure && (input == nil || jsonEqual(input, e.input)) {
+				delete(m.entries, k)
+			}
+		}
+	}
+}
+
+// watch applies hub invalidations until ctx is done (the handler was
+// stopped or collected) or shutdown is closed.
+func (m *etagMemo) watch(ctx context.Context, hub *Hub, shutdown <-chan struct{}) {
+	ctx, cancel := context.WithCancel(ctx)
+	msgs := hub.Subscribe(ctx, invalidationTopic)
+	go func() {
+		defer cancel()
+		for {
+			select {
+			case <-shutdown:
+				return
+			case msg, ok := <-msgs:
+				if !ok {
+					return
+				}
+				if ev, ok := msg.(InvalidationEvent); ok {
+					m.invalidate(ev.Keys)
+				}
+			}
+		}
+	}()
+}
+
+// etagMatches applies the weak comparison If-None-Match requires.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+/* src/server/core/go/rpc_etag_test.go */
+
+package seam
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func etagRouter() (*Router, *atomic.Int32) {
+	calls := new(atomic.Int32)
+	get := func(_ context.Context, _ struct{}) (string, error) {
+		calls.Add(1)
+		return "value", nil
+	}
+	return NewRouter().
+		Procedure(Query("getCached", get, WithCache(map[string]any{"ttl": 60}))).
+		Procedure(Query("getRevalidated", get, WithCache(true))).
+		Procedure(Query("getFresh", get)), calls
+}
+
+func TestCacheableQueryETag(t *testing.T) {
+	router, calls := etagRouter()
+	h := router.Handler()
+	get := func(path, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/_seam/procedure/getCached?input={}", "")
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || etag == "" || w.Header().Get("Cache-Control") != "private, max-age=60" {
+		t.Fatalf("expected ETag and Cache-Control, got %d %v", w.Code, w.Header())
+	}
+
+	// Fresh: answered from the remembered ETag without running the query
+	w = get("/_seam/procedure/getCached?input={}", `"other", W/`+etag)
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 || calls.Load() != 1 {
+		t.Fatalf("expected 304 without a call, got %d %q after %d calls", w.Code, w.Body.String(), calls.Load())
+	}
+
+	// Invalidated: the query runs again before answering 304
+	router.Invalidate("getCached")
+	deadline := time.Now().Add(time.Second)
+	for get("/_seam/procedure/getCached", etag).Code == http.StatusNotModified && calls.Load() == 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("invalidated query not run again: %d calls", calls.Load())
+	}
+
+	// max-age 0: always revalidated by running the query
+	etag = get("/_seam/procedure/getRevalidated", "").Header().Get("ETag")
+	if w = get("/_seam/procedure/getRevalidated", etag); w.Code != http.StatusNotModified || calls.Load() != 4 {
+		t.Fatalf("no-cache revalidation: %d after %d calls", w.Code, calls.Load())
+	}
+
+	if w = get("/_seam/procedure/getFresh", etag); w.Header().Get("ETag") != "" || calls.Load() != 4 {
+		t.Fatalf("queries without a cache hint must not get ETags, got %d %v", w.Code, w.Header())
+	}
+
+	req := httptest.NewRequest("POST", "/_seam/procedure/getCached", strings.NewReader(`{}`))
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != "" || w.Header().Get("Cache-Control") != "private, max-age=60" {
+		t.Fatalf("POST must not get ETags, got %d %v", w.Code, w.Header())
+	}
+}
+
+func TestQueryETagPerPrincipal(t *testing.T) {
+	router, calls := etagRouter()
+	h := router.Handler(HandlerOptions{Principal: func(r *http.Request) string { return r.Header.Get("X-User") }})
+	get := func(user, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("HEAD", "/_seam/procedure/getCached", nil)
+		req.Header.Set("X-User", user)
+		req.Header.Set("If-None-Match", ifNoneMatch)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+	etag := get("alice", "").Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("HEAD got no ETag")
+	}
+	// bob has no remembered response: his query runs
+	if w := get("bob", etag); w.Code != http.StatusNotModified || calls.Load() != 2 {
+		t.Fatalf("bob: %d after %d calls", w.Code, calls.Load())
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	cases := []struct {
+		proc ProcedureDef
+		ttl  int
+		ok   bool
+	}{
+		{ProcedureDef{}, 0, false},
+		{ProcedureDef{Cache: false}, 0, false},
+		{ProcedureDef{Cache: true}, 0, true},
+		{ProcedureDef{Cache: map[string]any{"ttl": 30.0}}, 30, true},
+		{ProcedureDef{Type: "command", Cache: true}, 0, false},
+	}
+	for _, c := range cases {
+		if ttl, ok := cacheTTL(&c.proc); ttl != c.ttl || ok != c.ok {
+			t.Errorf("cacheTTL(%v) = %d, %v; want %d, %v", c.proc.Cache, ttl, ok, c.ttl, c.ok)
+		}
+	}
+}
+
+func TestRebuiltHandlersReleaseWatchers(t *testing.T) {
+	plain := NewRouter().Procedure(Query("getFresh", func(context.Context, struct{}) (string, error) { return "", nil }))
+	plain.Handler()
+	if n := plain.Hub().Subscribers(invalidationTopic); n != 0 {
+		t.Fatalf("handler without cacheable queries subscribed %d watchers", n)
+	}
+
+	router, _ := etagRouter()
+	opts := HandlerOptions{LayoutCacheTTL: time.Minute}
+	kept := router.Handler(opts)
+	settle := func(done func() bool) {
+		for deadline := time.Now().Add(5 * time.Second); !done() && time.Now().Before(deadline); {
+			runtime.GC()
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	settle(func() bool { return router.Hub().Subscribers(invalidationTopic) == 2 })
+	baseGoroutines := runtime.NumGoroutine()
+	baseSubs := router.Hub().Subscribers(invalidationTopic)
+	if baseSubs != 2 {
+		t.Fatalf("expected etag and layout watchers, got %d subscribers", baseSubs)
+	}
+
+	for range 100 {
+		router.Handler(opts)
+	}
+	settle(func() bool {
+		return router.Hub().Subscribers(invalidationTopic) == baseSubs && runtime.NumGoroutine() <= baseGoroutines+2
+	})
+	if n := router.Hub().Subscribers(invalidationTopic); n != baseSubs {
+		t.Errorf("subscribers grew from %d to %d", baseSubs, n)
+	}
+	if n := runtime.NumGoroutine(); n > baseGoroutines+2 {
+		t.Errorf("goroutines grew from %d to %d", baseGoroutines, n)
+	}
+	runtime.KeepAlive(kept)
+}
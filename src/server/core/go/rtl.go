@@ -0,0 +1,76 @@
+/* src/server/core/go/rtl.go */
+
+package seam
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// defaultRTLLanguages are the language subtags written right to left when
+// HandlerOptions.RTLLocales is nil.
+var defaultRTLLanguages = []string{"ar", "arc", "ckb", "dv", "fa", "he", "ps", "sd", "ug", "ur", "yi"}
+
+var htmlDirAttr = regexp.MustCompile(`(?i)\sdir\s*=\s*("[^"]*"|'[^']*'|[^\s>]+)`)
+
+// localeDir returns "rtl" when locale, or its language subtag ("ar" for
+// "ar-EG"), is listed as right-to-left, else "ltr".
+func (s *appState) localeDir(locale string) string {
+	list := s.opts.RTLLocales
+	if list == nil {
+		list = defaultRTLLanguages
+	}
+	lang, _, _ := strings.Cut(strings.ReplaceAll(locale, "_", "-"), "-")
+	for _, l := range list {
+		if strings.EqualFold(l, locale) || strings.EqualFold(l, lang) {
+			return "rtl"
+		}
+	}
+	return "ltr"
+}
+
+// applyDir moves the "_dir" slot value of a localized page into the
+// decoded data script's _i18n.dir, reporting whether data changed. The
+// <html> dir attribute is set separately (setHTMLDir).
+func applyDir(data map[string]json.RawMessage, dir string) bool {
+	var i18n map[string]json.RawMessage
+	if json.Unmarshal(data["_i18n"], &i18n) != nil || i18n == nil {
+		return false
+	}
+	i18n["dir"], _ = json.Marshal(dir)
+	data["_i18n"], _ = json.Marshal(i18n)
+	delete(data, "_dir")
+	return true
+}
+
+// setHTMLDir sets the dir attribute of the first <html> start tag,
+// replacing one the template hardcodes.
+func setHTMLDir(html, dir string) string {
+	lower := strings.ToLower(html)
+	open := -1
+	for i := 0; ; {
+		n := strings.Index(lower[i:], "<html")
+		if n < 0 {
+			return html
+		}
+		open = i + n
+		if next := open + len("<html"); next < len(html) && (html[next] == '>' || html[next] == ' ' || html[next] == '\t' || html[next] == '\n') {
+			break
+		}
+		i = open + 1
+	}
+	closeTag := strings.IndexByte(html[open:], '>')
+	if closeTag < 0 {
+		return html
+	}
+	closeTag += open
+	tag := html[open:closeTag]
+	attr := ` dir="` + dir + `"`
+	if loc := htmlDirAttr.FindStringIndex(tag); loc != nil {
+		tag = tag[:loc[0]] + attr + tag[loc[1]:]
+	} else {
+		tag += attr
+	}
+	return html[:open] + tag + html[closeTag:]
+}
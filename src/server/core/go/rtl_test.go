@@ -0,0 +1,65 @@
+/* src/server/core/go/rtl_test.go */
+
+package seam
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLocaleDir(t *testing.T) {
+	s := &appState{}
+	for locale, want := range map[string]string{"ar": "rtl", "ar-EG": "rtl", "he_IL": "rtl", "en": "ltr", "de-AT": "ltr"} {
+		if got := s.localeDir(locale); got != want {
+			t.Errorf("localeDir(%q) = %q, want %q", locale, got, want)
+		}
+	}
+	s.opts.RTLLocales = []string{"x-custom"}
+	if s.localeDir("ar") != "ltr" || s.localeDir("x-custom") != "rtl" {
+		t.Fatal("expected RTLLocales to replace the defaults")
+	}
+}
+
+func TestSetHTMLDir(t *testing.T) {
+	cases := map[string]string{
+		`<!DOCTYPE html><html lang="ar"><body></body></html>`: `<!DOCTYPE html><html lang="ar" dir="rtl"><body></body></html>`,
+		`<html dir='ltr' lang="ar"><body></body></html>`:      `<html dir="rtl" lang="ar"><body></body></html>`,
+		`<htmlx><html><body></body></html>`:                   `<htmlx><html dir="rtl"><body></body></html>`,
+		`<p>fragment</p>`:                                     `<p>fragment</p>`,
+	}
+	for in, want := range cases {
+		if got := setHTMLDir(in, "rtl"); got != want {
+			t.Errorf("setHTMLDir(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestRTLPage(t *testing.T) {
+	router := NewRouter().
+		Page(&PageDef{Route: "/about", Template: `<html><body><main class="<!--seam:_dir-->">about</main></body></html>`}).
+		I18nConfig(&I18nConfig{Locales: []string{"en", "ar"}, Default: "en", Messages: map[string]map[string]json.RawMessage{}}).
+		ResolveStrategies(FromUrlQuery("lang"))
+	h := router.Handler()
+
+	get := func(lang string) string {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/_seam/page/about?lang="+lang, nil))
+		return w.Body.String()
+	}
+	body := get("ar")
+	for _, want := range []string{`<html lang="ar" dir="rtl">`, `<main class="rtl">`, `"_i18n":{"dir":"rtl","locale":"ar"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %s in %s", want, body)
+		}
+	}
+	if strings.Contains(body, `"_dir"`) {
+		t.Errorf("expected _dir moved into _i18n: %s", body)
+	}
+
+	body = get("en")
+	if strings.Contains(body, `dir=`) || !strings.Contains(body, `<main class="ltr">`) || !strings.Contains(body, `"dir":"ltr"`) {
+		t.Fatalf("unexpected ltr page %s", body)
+	}
+}
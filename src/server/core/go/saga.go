@@ -0,0 +1,70 @@
+/* src/server/core/go/saga.go */
+
+package seam
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// SagaStep is one step of a multi-step command. Compensate undoes a
+// completed Run and may be nil for steps with nothing to undo.
+type SagaStep struct {
+	Name       string
+	Run        func(ctx context.Context) error
+	Compensate func(ctx context.Context) error
+}
+
+// SagaFailure is the error detail RunSaga attaches when a step fails:
+// which step failed, which completed steps were compensated, and which
+// compensations themselves failed (needing manual repair).
+type SagaFailure struct {
+	Kind               string   `json:"kind"` // always "saga"
+	Step               string   `json:"step"`
+	Compensated        []string `json:"compensated"`
+	CompensationFailed []string `json:"compensationFailed,omitempty"`
+}
+
+// RunSaga runs steps in order. When a step fails, the compensations of
+// the steps completed before it run in reverse order, and the returned
+// *Error keeps the step's code and message with a SagaFailure prepended
+// to its details. Compensations run even if ctx was cancelled.
+//
+//	var orderID string
+//	return nil, seam.RunSaga(ctx,
+//		seam.SagaStep{Name: "reserve", Run: reserve(&orderID), Compensate: release(&orderID)},
+//		seam.SagaStep{Name: "charge", Run: charge(&orderID), Compensate: refund(&orderID)},
+//		seam.SagaStep{Name: "ship", Run: ship(&orderID)},
+//	)
+func RunSaga(ctx context.Context, steps ...SagaStep) error {
+	for i, step := range steps {
+		err := step.Run(ctx)
+		if err == nil {
+			continue
+		}
+		failure := SagaFailure{Kind: "saga", Step: step.Name, Compensated: []string{}}
+		undoCtx := context.WithoutCancel(ctx)
+		for j := i - 1; j >= 0; j-- {
+			done := steps[j]
+			if done.Compensate == nil {
+				continue
+			}
+			if cerr := done.Compensate(undoCtx); cerr != nil {
+				logf(slog.LevelError, "saga compensation '%s' failed: %v\n", done.Name, cerr)
+				failure.CompensationFailed = append(failure.CompensationFailed, done.Name)
+				continue
+			}
+			failure.Compensated = append(failure.Compensated, done.Name)
+		}
+
+		seamErr, ok := err.(*Error)
+		if !ok {
+			seamErr = InternalError(fmt.Sprintf("Step '%s' failed: %s", step.Name, err))
+		}
+		out := *seamErr
+		out.Details = append([]any{failure}, seamErr.Details...)
+		return &out
+	}
+	return nil
+}
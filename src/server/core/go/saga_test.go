@@ -0,0 +1,66 @@
+/* src/server/core/go/saga_test.go */
+
+package seam
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRunSagaCompensatesInReverse(t *testing.T) {
+	var log []string
+	step := func(name string, fail error) SagaStep {
+		return SagaStep{
+			Name: name,
+			Run: func(context.Context) error {
+				log = append(log, "run:"+name)
+				return fail
+			},
+			Compensate: func(context.Context) error {
+				log = append(log, "undo:"+name)
+				return nil
+			},
+		}
+	}
+
+	err := RunSaga(context.Background(),
+		step("reserve", nil),
+		SagaStep{Name: "notify", Run: func(context.Context) error { log = append(log, "run:notify"); return nil }},
+		step("charge", nil),
+		step("ship", errors.New("carrier down")),
+		step("email", nil),
+	)
+	want := []string{"run:reserve", "run:notify", "run:charge", "run:ship", "undo:charge", "undo:reserve"}
+	if !reflect.DeepEqual(log, want) {
+		t.Fatalf("got %v, want %v", log, want)
+	}
+	seamErr, ok := err.(*Error)
+	if !ok || seamErr.Code != "INTERNAL_ERROR" || !strings.Contains(seamErr.Message, "ship") {
+		t.Fatalf("unexpected error %#v", err)
+	}
+	failure := seamErr.Details[0].(SagaFailure)
+	if failure.Step != "ship" || !reflect.DeepEqual(failure.Compensated, []string{"charge", "reserve"}) {
+		t.Fatalf("unexpected failure detail %+v", failure)
+	}
+}
+
+func TestRunSagaErrorEnvelope(t *testing.T) {
+	router := NewRouter().Procedure(Command("placeOrder", func(ctx context.Context, _ struct{}) (string, error) {
+		return "", RunSaga(ctx,
+			SagaStep{Name: "reserve", Run: func(context.Context) error { return nil },
+				Compensate: func(context.Context) error { return errors.New("stock service down") }},
+			SagaStep{Name: "charge", Run: func(context.Context) error {
+				return NewError("PAYMENT_DECLINED", "Card declined", http.StatusPaymentRequired)
+			}},
+		)
+	}))
+	code, body := rpcBody(router.Handler(HandlerOptions{}), "/_seam/procedure/placeOrder", `{}`, nil)
+	want := `"details":[{"kind":"saga","step":"charge","compensated":[],"compensationFailed":["reserve"]}]`
+	if code != http.StatusPaymentRequired || !strings.Contains(body, `"PAYMENT_DECLINED"`) || !strings.Contains(body, want) {
+		t.Fatalf("got %d %s", code, body)
+	}
+}
@@ -0,0 +1,186 @@
+/* src/server/core/go/sandbox.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"runtime/metrics"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SandboxQuota constrains a misbehaving procedure handler (WithSandbox).
+// The handler runs in its own goroutine with panics recovered into
+// INTERNAL_ERROR; a call past Timeout is answered with the RPC timeout
+// error even when the handler ignores its context (it is abandoned and
+// still counts toward MaxConcurrent until it returns). The sandbox is not
+// a security boundary: the handler shares the process, its memory, and
+// its privileges, so it only contains bugs, not hostile code.
+type SandboxQuota struct {
+	Timeout time.Duration // per-call deadline (required)
+	// TimeBudget caps the handler time spent per Window, summed over
+	// calls (wall clock while handlers run, a proxy for CPU time); calls
+	// are refused with RATE_LIMITED until the window ends. 0 = unlimited.
+	TimeBudget time.Duration
+	Window     time.Duration // TimeBudget window (default 1m)
+	// WarnAllocBytes logs calls during which the process allocated more
+	// than this. Allocations are sampled process-wide, so concurrent calls
+	// are charged for each other's; the figure is a hint and never fails
+	// a call. 0 = not observed.
+	WarnAllocBytes uint64
+	MaxConcurrent  int // calls running at once, abandoned ones included (0 = unlimited)
+}
+
+// WithSandbox runs the procedure under quota. With
+// HandlerOptions.Sandbox every query and command must have one.
+func WithSandbox(quota SandboxQuota) ProcedureOption {
+	return func(p *ProcedureDef) {
+		p.Sandbox = &quota
+	}
+}
+
+// sandboxed wraps a procedure's handler in its quota; procedures without
+// one are returned unchanged.
+func sandboxed(def ProcedureDef) ProcedureDef {
+	if def.Sandbox == nil {
+		return def
+	}
+	if def.Sandbox.Timeout <= 0 {
+		panic(fmt.Sprintf("sandboxed procedure %q needs a Timeout", def.Name))
+	}
+	sb := &sandbox{name: def.Name, quota: *def.Sandbox, next: def.Handler}
+	if sb.quota.Window <= 0 {
+		sb.quota.Window = time.Minute
+	}
+	if sb.quota.MaxConcurrent > 0 {
+		sb.slots = make(chan struct{}, sb.quota.MaxConcurrent)
+	}
+	def.Handler = sb.call
+	return def
+}
+
+// checkSandboxed panics when sandbox mode is on and procedures have no
+// quota.
+func checkSandboxed(procedures []ProcedureDef) {
+	var missing []string
+	for _, p := range procedures {
+		if p.Sandbox == nil {
+			missing = append(missing, p.Name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		panic(fmt.Sprintf("sandbox mode: procedures without a quota (WithSandbox): %s", strings.Join(missing, ", ")))
+	}
+}
+
+type sandbox struct {
+	name  string
+	quota SandboxQuota
+	next  HandlerFunc
+	slots chan struct{} // nil when MaxConcurrent is 0
+
+	mu          sync.Mutex
+	windowStart time.Time
+	spent       time.Duration
+}
+
+type sandboxResult struct {
+	value any
+	err   error
+}
+
+func (sb *sandbox) call(ctx context.Context, input json.RawMessage) (any, error) {
+	if retry, ok := sb.budgetLeft(); !ok {
+		e := NewError("RATE_LIMITED", fmt.Sprintf("Procedure '%s' exhausted its time budget", sb.name), http.StatusTooManyRequests)
+		e.RetryAfter = retry
+		return nil, e
+	}
+	if sb.slots != nil {
+		select {
+		case sb.slots <- struct{}{}:
+		default:
+			e := NewError("UNAVAILABLE", fmt.Sprintf("Procedure '%s' is at its concurrency limit", sb.name), http.StatusServiceUnavailable)
+			e.Transient = true
+			return nil, e
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sb.quota.Timeout)
+	defer cancel()
+	done := make(chan sandboxResult, 1)
+	go func() {
+		start := time.Now()
+		allocs := heapAllocs()
+		defer func() {
+			sb.charge(time.Since(start))
+			if sb.slots != nil {
+				<-sb.slots
+			}
+			if p := recover(); p != nil {
+				logf(slog.LevelError, "sandboxed procedure %s panicked: %v\n%s", sb.name, p, debug.Stack())
+				done <- sandboxResult{err: InternalError(fmt.Sprintf("Procedure '%s' panicked", sb.name))}
+				return
+			}
+		}()
+		value, err := sb.next(ctx, input)
+		if used := heapAllocs() - allocs; sb.quota.WarnAllocBytes > 0 && used > sb.quota.WarnAllocBytes {
+			logf(slog.LevelWarn, "sandboxed procedure %s: %d bytes allocated during the call (warning at %d)\n", sb.name, used, sb.quota.WarnAllocBytes)
+		}
+		done <- sandboxResult{value: value, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.value, res.err
+	case <-ctx.Done():
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, rpcTimeoutError()
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// budgetLeft reports whether the current window has time budget left,
+// else how long until it resets.
+func (sb *sandbox) budgetLeft() (time.Duration, bool) {
+	if sb.quota.TimeBudget <= 0 {
+		return 0, true
+	}
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	now := time.Now()
+	if now.Sub(sb.windowStart) >= sb.quota.Window {
+		sb.windowStart, sb.spent = now, 0
+	}
+	if sb.spent >= sb.quota.TimeBudget {
+		return sb.quota.Window - now.Sub(sb.windowStart), false
+	}
+	return 0, true
+}
+
+func (sb *sandbox) charge(d time.Duration) {
+	if sb.quota.TimeBudget <= 0 {
+		return
+	}
+	sb.mu.Lock()
+	sb.spent += d
+	sb.mu.Unlock()
+}
+
+// heapAllocs returns the bytes allocated by the whole process so far.
+func heapAllocs() uint64 {
+	sample := []metrics.Sample{{Name: "/gc/heap/allocs:bytes"}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return sample[0].Value.Uint64()
+}
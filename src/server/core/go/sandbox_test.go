@@ -0,0 +1,90 @@
+/* src/server/core/go/sandbox_test.go */
+
+package seam
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSandboxIsolatesHandlers(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	router := NewRouter().
+		Procedure(Query("boom", func(context.Context, struct{}) (string, error) {
+			panic("nil map write")
+		}, WithSandbox(SandboxQuota{Timeout: time.Second}))).
+		Procedure(Query("hang", func(context.Context, struct{}) (string, error) {
+			<-release // ignores its context
+			return "late", nil
+		}, WithSandbox(SandboxQuota{Timeout: 20 * time.Millisecond, MaxConcurrent: 1}))).
+		Procedure(Query("hog", func(context.Context, struct{}) (int, error) {
+			buf := make([]byte, 4<<20)
+			return len(buf), nil
+		}, WithSandbox(SandboxQuota{Timeout: time.Second, WarnAllocBytes: 1 << 20})))
+	h := router.Handler(HandlerOptions{})
+
+	code, body := rpcBody(h, "/_seam/procedure/boom", `{}`, nil)
+	if code != http.StatusInternalServerError || !strings.Contains(body, "Procedure 'boom' panicked") {
+		t.Errorf("panic: %d %s", code, body)
+	}
+	code, body = rpcBody(h, "/_seam/procedure/hang", `{}`, nil)
+	if code != http.StatusGatewayTimeout {
+		t.Errorf("timeout: %d %s", code, body)
+	}
+	// The abandoned call still holds the only slot.
+	code, body = rpcBody(h, "/_seam/procedure/hang", `{}`, nil)
+	if code != http.StatusServiceUnavailable || !strings.Contains(body, "concurrency limit") {
+		t.Errorf("concurrency: %d %s", code, body)
+	}
+	// Allocations are only observed, never enforced.
+	code, body = rpcBody(h, "/_seam/procedure/hog", `{}`, nil)
+	if code != http.StatusOK {
+		t.Errorf("allocation warning failed the call: %d %s", code, body)
+	}
+}
+
+func TestSandboxTimeBudget(t *testing.T) {
+	router := NewRouter().Procedure(Query("slow", func(context.Context, struct{}) (bool, error) {
+		time.Sleep(15 * time.Millisecond)
+		return true, nil
+	}, WithSandbox(SandboxQuota{Timeout: time.Second, TimeBudget: 10 * time.Millisecond, Window: time.Hour})))
+	h := router.Handler(HandlerOptions{})
+
+	if code, body := rpcBody(h, "/_seam/procedure/slow", `{}`, nil); code != http.StatusOK {
+		t.Fatalf("first call: %d %s", code, body)
+	}
+	code, body := rpcBody(h, "/_seam/procedure/slow", `{}`, nil)
+	if code != http.StatusTooManyRequests || !strings.Contains(body, `"code":"RATE_LIMITED"`) {
+		t.Errorf("over budget: %d %s", code, body)
+	}
+}
+
+func TestSandboxMode(t *testing.T) {
+	quota := WithSandbox(SandboxQuota{Timeout: time.Second})
+	router := NewRouter().
+		Procedure(Query("ok", func(context.Context, struct{}) (bool, error) { return true, nil }, quota)).
+		Namespace("admin", Command("purge", func(context.Context, struct{}) (bool, error) { return true, nil })).
+		Procedure(Query("list", func(context.Context, struct{}) (bool, error) { return true, nil }))
+
+	defer func() {
+		msg, _ := recover().(string)
+		if !strings.Contains(msg, "admin.purge, list") {
+			t.Errorf("panic %q", msg)
+		}
+	}()
+	router.Handler(HandlerOptions{Sandbox: true})
+	t.Error("handler built with unsandboxed procedures")
+}
+
+func TestSandboxRequiresTimeout(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("quota without Timeout accepted")
+		}
+	}()
+	NewRouter().Procedure(Query("x", func(context.Context, struct{}) (bool, error) { return true, nil }, WithSandbox(SandboxQuota{})))
+}
@@ -5,6 +5,12 @@ package seam
 import (
 	"reflect"
 	"strings"
+	"time"
+)
+
+var (
+	timeType      = reflect.TypeFor[time.Time]()
+	timestampType = reflect.TypeFor[Timestamp]()
 )
 
 // SchemaOf generates a JTD (JSON Type Definition) schema from a Go type
@@ -14,12 +20,26 @@ func SchemaOf[T any]() any {
 	return schemaFor(reflect.TypeOf(zero))
 }
 
+// schemaProvider lets a type supply its own JTD schema (Patch).
+type schemaProvider interface{ seamSchema() any }
+
+var schemaProviderType = reflect.TypeFor[schemaProvider]()
+
 func schemaFor(t reflect.Type) any {
+	if t.Implements(schemaProviderType) {
+		return reflect.Zero(t).Interface().(schemaProvider).seamSchema()
+	}
 	// Unwrap pointer for the underlying type analysis;
 	// pointer-ness is handled at the struct field level (nullable in properties).
 	if t.Kind() == reflect.Ptr {
 		return schemaFor(t.Elem())
 	}
+	if t == timeType || t == timestampType {
+		return map[string]any{"type": "timestamp"}
+	}
+	if format := numberFormatOf(t); format != "" {
+		return map[string]any{"type": "string", "metadata": map[string]any{"format": format}}
+	}
 
 	switch t.Kind() {
 	case reflect.String:
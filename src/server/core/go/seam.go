@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"reflect"
 	"time"
 )
 
@@ -17,6 +18,13 @@ type Error struct {
 	Message string `json:"message"`
 	Status  int    `json:"-"`
 	Details []any  `json:"-"`
+	// Transient marks failures worth retrying (upstream timeouts, rate
+	// limits, 5xx); RetryAfter, when set, tells the client how long to wait.
+	Transient  bool          `json:"-"`
+	RetryAfter time.Duration `json:"-"`
+	// PollInterval, when set, tells a client refused a stream to poll at
+	// that interval instead (see ConnectionLimits).
+	PollInterval time.Duration `json:"-"`
 }
 
 func (e *Error) Error() string {
@@ -35,6 +43,10 @@ func defaultStatus(code string) int {
 		return http.StatusNotFound
 	case "RATE_LIMITED":
 		return http.StatusTooManyRequests
+	case "CONFLICT":
+		return http.StatusConflict
+	case "UNAVAILABLE":
+		return http.StatusServiceUnavailable
 	case "CONTEXT_ERROR":
 		return http.StatusBadRequest
 	case "INTERNAL_ERROR":
@@ -98,15 +110,26 @@ type HandlerFunc func(ctx context.Context, input json.RawMessage) (any, error)
 
 // ProcedureDef defines a single RPC procedure.
 type ProcedureDef struct {
-	Name         string
-	Type         string // "query" (default) or "command"
-	InputSchema  any
-	OutputSchema any
-	ErrorSchema  any      // optional: JTD schema for typed errors
-	ContextKeys  []string // context keys this procedure requires
-	Suppress     []string // optional: suppressed warnings for client SDK
-	Cache        any      // optional: false | map[string]any{"ttl": N}
-	Handler      HandlerFunc
+	Name                 string
+	Type                 string // "query" (default) or "command"
+	InputSchema          any
+	OutputSchema         any
+	ErrorSchema          any                  // optional: JTD schema for typed errors
+	ContextKeys          []string             // context keys this procedure requires
+	Suppress             []string             // optional: suppressed warnings for client SDK
+	Cache                any                  // optional: false | map[string]any{"ttl": N}
+	Deprecated           *Deprecation         // optional: deprecation notice emitted in the manifest
+	InvalidateTargets    []InvalidateTarget   // commands only: queries made stale by a successful call
+	Lock                 string               // optional: named lock held while the handler runs (WithLock)
+	Weight               int                  // optional: cost in a batch (WithWeight; default 1)
+	Fixtures             []any                // optional: SelfTest inputs (WithFixtures; default: derived from InputSchema)
+	Sandbox              *SandboxQuota        // optional: run the handler under a quota (WithSandbox)
+	Annotations          *Annotations         // optional: PII, auth, and cache declarations (WithAnnotations)
+	AdditionalProperties AdditionalProperties // optional: unknown input field policy (WithAdditionalProperties)
+	Handler              HandlerFunc
+
+	inputType reflect.Type // Query/Command input type, checked against typed loaders
+	canary    *canarySplit // set by Router.Canary
 }
 
 // ProcedureOption configures optional fields on a ProcedureDef.
@@ -137,6 +160,13 @@ func WithCache(cache any) ProcedureOption {
 type SubscriptionEvent struct {
 	Value any
 	Err   *Error
+	// Cursor optionally positions the event in the handler's source (e.g.
+	// a change feed offset). With HandlerOptions.StreamState, a resuming
+	// client's last cursor is returned by LastEventID.
+	Cursor string
+	// Event names the event on the wire (default "data"); SnapshotDeltas
+	// sets "snapshot" and "delta".
+	Event string
 }
 
 // SubscriptionHandlerFunc creates a channel-based event stream from raw JSON input.
@@ -150,6 +180,7 @@ type SubscriptionDef struct {
 	ErrorSchema  any      // optional: JTD schema for typed errors
 	ContextKeys  []string // context keys this subscription requires
 	Suppress     []string // optional: suppressed warnings for client SDK
+	Deltas       bool     // events are a snapshot then JSON Patch deltas (SubscribeDeltas)
 	Handler      SubscriptionHandlerFunc
 }
 
@@ -199,6 +230,12 @@ type LoaderDef struct {
 	DataKey   string
 	Procedure string
 	InputFn   func(params map[string]string) any
+	// Optional loaders are skipped while a ResourceGuard reports the
+	// process degraded; the page renders without their data.
+	Optional bool
+
+	proc  *ProcedureDef // set by Load: the bound procedure handle
+	input LoaderInput
 }
 
 // LayoutChainEntry represents one layout in the chain (outer to inner order).
@@ -222,15 +259,28 @@ type PageDef struct {
 	Template        string
 	LocaleTemplates map[string]string // locale -> pre-resolved template HTML (layout chain applied)
 	Loaders         []LoaderDef
-	DataID          string              // script ID for the injected data JSON (default "__data")
-	LayoutChain     []LayoutChainEntry  // layout chain from outer to inner with per-layout loader keys
-	PageLoaderKeys  []string            // data keys from page-level loaders (not layout)
-	I18nKeys        []string            // merged i18n keys from route + layout chain; empty means include all
-	HeadMeta        string              // head metadata HTML (injected at render time by engine)
-	Assets          *PageAssets         // per-page CSS/JS/preload/prefetch (nil when splitting is off)
-	Projections     map[string][]string // per-loader field projections for schema narrowing (nil = no narrowing)
-	Prerender       bool                // SSG: serve pre-rendered static HTML instead of running loaders
-	StaticDir       string              // SSG: directory containing pre-rendered HTML files
+	DataID          string                            // script ID for the injected data JSON (default "__data")
+	DataScripts     []DataScript                      // extra data scripts split off the main one, in emit order
+	LayoutChain     []LayoutChainEntry                // layout chain from outer to inner with per-layout loader keys
+	PageLoaderKeys  []string                          // data keys from page-level loaders (not layout)
+	I18nKeys        []string                          // merged i18n keys from route + layout chain; empty means include all
+	HeadMeta        string                            // head metadata HTML (injected at render time by engine)
+	Assets          *PageAssets                       // per-page CSS/JS/preload/prefetch (nil when splitting is off)
+	Projections     map[string][]string               // per-loader field projections for schema narrowing (nil = no narrowing)
+	Prerender       bool                              // SSG: serve pre-rendered static HTML instead of running loaders
+	StaticDir       string                            // SSG: directory containing pre-rendered HTML files
+	ErrorBoundaries []ErrorBoundary                   // layouts with an error template, outer to inner
+	Middleware      []func(http.Handler) http.Handler // wraps the page handler, first outermost
+	NamespacedSlots bool                              // slots must be <loader>.<field>; checked at handler build (lazy: first render)
+	Methods         []string                          // extra methods besides GET (e.g. "POST") handled by Form
+	Form            *PageForm                         // form submission handling for Methods
+	Coalesce        bool                              // concurrent anonymous GETs of one URL share a single render
+	Pagination      *Pagination                       // rel=prev/next link tags from route params and loader data
+	Robots          string                            // robots directives ("noindex", "nofollow", "none", comma-separated): X-Robots-Tag header and meta tag
+	Annotations     *Annotations                      // PII, auth, and cache declarations for policy checks (nil = none)
+
+	lazy   *lazyTemplate // LoadBuildOutputLazy: templates read on demand
+	origin *lazyTemplate // build output pages: template sources, for TemplateDebug
 }
 
 // I18nConfig holds runtime i18n state loaded from build output.
@@ -245,22 +295,224 @@ type I18nConfig struct {
 	DistDir       string                                // paged: base directory for on-demand reads
 }
 
-// HandlerOptions configures timeout behavior for the generated handler.
-// Zero values disable the corresponding timeout.
+// HandlerOptions configures timeout and runtime behavior for the generated handler.
+// Zero values disable the corresponding feature.
 type HandlerOptions struct {
 	RPCTimeout        time.Duration // per-RPC call timeout (default 30s)
 	PageTimeout       time.Duration // aggregate page-loader timeout (default 30s)
 	SSEIdleTimeout    time.Duration // idle timeout between SSE events (default 12s)
 	HeartbeatInterval time.Duration // SSE/WS heartbeat interval (default 8s)
 	PongTimeout       time.Duration // pong deadline after ping (default 5s)
+	PinnedManifest    string        // path to a pinned manifest; Handler panics on breaking changes against it
+	VersionHeader     string        // header selecting a procedure version for bare names (default "X-Seam-Version"; see WithVersion)
+
+	Principal   func(r *http.Request) string // derives the request principal (user id, API key); "" = anonymous
+	Flags       FlagProvider                 // feature flag provider; flags are read via Flags(ctx)
+	ExposeFlags bool                         // inject evaluated flags into the page data script as _flags
+	Hub         *Hub                         // in-process pub/sub for server push (default: the router's hub)
+
+	ReconnectDelay       time.Duration // reconnect delay suggested to SSE/WS clients on shutdown (default 1s)
+	DefaultRetryAfter    time.Duration // retry hint for RATE_LIMITED, UNAVAILABLE, and timeout errors without RetryAfter (0 = none)
+	MaxSubscriptionInput int           // max bytes of a ?input= subscription query parameter (default 8 KiB; negative disables)
+	AccessLog            *AccessLog    // per-request access log for /_seam traffic
+
+	TrustedProxies []string   // CIDRs/addresses whose forwarding headers are trusted for the client IP
+	ClientIPHeader string     // header carrying the client IP from trusted proxies (default X-Forwarded-For; set "CF-Connecting-IP" behind Cloudflare)
+	IPFilters      []IPFilter // allow/deny client address lists per route class
+
+	// LayoutCacheTTL caches layout loader results per layout id + principal
+	// (0 disables). Requests without a principal are not cached, so one
+	// user's layout data is never served to another, unless the layout is
+	// listed in PublicLayouts.
+	LayoutCacheTTL time.Duration
+	// PublicLayouts lists layout ids whose loader data is the same for
+	// every caller; LayoutCacheTTL caches them for anonymous requests too,
+	// in one entry shared by all of them.
+	PublicLayouts []string
+	// CacheKey builds the key of page render caches (Coalesce flights)
+	// from the URL, resolved locale, principal class, and Variant; the
+	// default is RenderCacheKey.String.
+	CacheKey CacheKeyFunc
+	// InputLimits caps input size (opt-in), nesting depth, and array
+	// length before parsing (depth and array defaults apply to zero
+	// fields; negative disables).
+	InputLimits InputLimits
+	// PseudoLocale adds a development locale (e.g. "en-XA") selectable by
+	// the resolve strategies: it renders the default locale's templates
+	// with messages accented, bracketed, and padded by about 40% to expose
+	// hardcoded strings and layout overflow.
+	PseudoLocale string
+	// RTLLocales lists right-to-left locales or language subtags (default:
+	// ar, arc, ckb, dv, fa, he, ps, sd, ug, ur, yi). Their pages get
+	// dir="rtl" on <html>; every localized page exposes its direction as
+	// the _dir slot and _i18n.dir in the data script.
+	RTLLocales []string
+	// SiteURL is the public origin ("https://example.com") templates see
+	// as _env.url, joined with the page path; paginated pages' prev/next
+	// links are absolute under it.
+	SiteURL string
+	// CanonicalLinks gives pages a rel=canonical link under SiteURL
+	// unless their template has one.
+	CanonicalLinks bool
+	// BuildVersion is exposed to templates as _env.version.
+	BuildVersion string
+	// BuildVersionFromVCS fills an empty BuildVersion with the VCS
+	// revision stamped by go build. Off by default so pages do not
+	// publish the commit they were built from.
+	BuildVersionFromVCS bool
+	// OnLocaleResolved can override the locale picked by the resolve
+	// strategies before template and message selection (e.g. force "en"
+	// for bots); it must return one of the configured locales. The final
+	// locale is available to loaders via LocaleOf and in JSON access logs.
+	OnLocaleResolved func(r *http.Request, resolved string) string
+	// Variant returns the request's experiment variant (e.g. an A/B
+	// bucket cookie), part of the render cache key.
+	Variant func(r *http.Request) string
+	// HeadCacheTTL reuses the status and headers of a HEAD page render
+	// per render cache key for this long, so repeated HEADs skip the
+	// loaders (0 renders every HEAD; personalized requests always do).
+	HeadCacheTTL time.Duration
+
+	// NamespacedSlots requires every page slot to start with a loader key
+	// (<!--seam:user.name-->) and renders slots against the keyed loader
+	// data, skipping flattening of nested loader fields. Checked at handler
+	// build (lazily loaded templates on first render, answering 500 when
+	// they violate it); also settable per PageDef.
+	NamespacedSlots bool
+	// Redirects are evaluated before page matching (first match wins),
+	// ahead of rules loaded from the build output's redirects.json.
+	Redirects []RedirectRule
+	// PasswordProtect puts page routes behind basic auth or a passphrase
+	// (staging/preview deployments); RPC is unaffected.
+	PasswordProtect *PasswordProtect
+	// SecurityHeaders adds HSTS, nosniff, Referrer-Policy, COOP/COEP, and
+	// frame-ancestors to page and static responses (&SecurityHeaders{}
+	// enables the defaults).
+	SecurityHeaders *SecurityHeaders
+	// ResponseSizes records per-procedure/page response sizes (percentiles,
+	// payload budget warnings).
+	ResponseSizes *ResponseSizes
+	// WebSocketRPC serves /_seam/ws: one persistent socket carrying any
+	// procedure call and subscription start/stop, correlated by request id.
+	WebSocketRPC bool
+	// WebSocketRPCOrigins lists extra origins (e.g. "https://app.example.com")
+	// allowed to open /_seam/ws. The socket carries cookie-authenticated
+	// calls, so other cross-origin upgrades are refused; same-origin
+	// requests and clients that send no Origin are always accepted.
+	WebSocketRPCOrigins []string
+	// MaxSocketSubscriptions caps concurrent subscriptions per WebSocketRPC
+	// connection (default 32); further subscribe frames get RATE_LIMITED.
+	MaxSocketSubscriptions int
+	// OnLatency enables timestamped heartbeats (WS frames, SSE ": ping"
+	// comments) and receives round trips measured from client pong frames.
+	// LatencyMetrics.Observe aggregates them for scraping.
+	OnLatency func(LatencySample)
+	// OnResponse sees every buffered response (pages, page data, RPC,
+	// batches, manifest) after all standard processing and may rewrite its
+	// body, headers, or status. SSE, streams, WebSockets, and non-/_seam
+	// requests (public files) are sent as they are written and not passed
+	// to it.
+	OnResponse func(*ResponseEvent)
+	// Locks provides the named locks of WithLock procedures (default:
+	// in-process MemoryLocks; RedisLocks excludes across replicas).
+	Locks LockProvider
+	// EventStore receives a CommandEvent for every successful command
+	// (name, input, result, principal, timestamp).
+	EventStore EventStore
+	// StreamState persists SSE stream sequence numbers and cursors so a
+	// client reconnecting with Last-Event-ID resumes on any replica
+	// (MemoryStreamState, RedisStreamState). Nil keeps per-connection ids.
+	StreamState StreamStateStore
+	// Examples captures anonymized input/output examples of successful
+	// queries and commands for docs and client mocks.
+	Examples *ExampleCapture
+	// EngineLimits bounds each page render in the WASM engine; a breach
+	// returns INTERNAL_ERROR and is counted in EngineBudgetHits.
+	EngineLimits EngineLimits
+	// ResourceGuard degrades the handler under heap or goroutine pressure
+	// (refuses new streams, skips Optional loaders, stops layout cache fills).
+	ResourceGuard *ResourceGuard
+	// Quota accounts calls and bytes per principal and rejects principals
+	// over their limit with RATE_LIMITED.
+	Quota *Quota
+	// SignedURLs verifies signed subscription URLs (SSE and channel
+	// WebSocket GETs) minted by URLSigner.SignSubscription.
+	SignedURLs *URLSigner
+	// RenderTraces honors TraceQueryParam on page requests. It is ignored
+	// when SEAM_ENV or NODE_ENV is "production".
+	RenderTraces bool
+	// TemplateDebug (development only) annotates rendered HTML with
+	// comments naming the loader behind each slot and the layout behind
+	// each region, and logs slots without data and unresolved markers
+	// with their source template.
+	TemplateDebug bool
+	// StrictTemplates fails page renders with 500 when the output still
+	// contains <!--seam:...--> markers the engine did not resolve, logging
+	// the markers, instead of serving the broken page.
+	StrictTemplates bool
+	// ExactNumbers writes the page data script with loader values exactly
+	// as encoding/json serializes them (json.Number and json.RawMessage
+	// included), instead of the engine's re-serialization, which reformats
+	// floats and rounds integers beyond 64 bits. Use it when __SEAM_DATA__
+	// must match other SDKs byte for byte.
+	ExactNumbers bool
+	// Policies check every procedure and page against its Annotations at
+	// handler build; Handler panics listing the violations. NoPublicPIICache,
+	// PIIRequiresAuth, and RequireAnnotations are built in.
+	Policies []Policy
+	// RenderCapture (development only) keeps the loader data and engine
+	// inputs of recent page renders, so a render can be replayed offline
+	// with ReplayRender.
+	RenderCapture *RenderCapture
+	// SlotFilters adds or replaces slot filters by name, applied as in
+	// <!--seam:price|currency:EUR-->; date, number, and truncate are
+	// built in.
+	SlotFilters map[string]SlotFilter
+	// DeprecatedCalls counts calls to WithDeprecated procedures per
+	// client; the calls get a Warning header and meta.warnings either way.
+	DeprecatedCalls *DeprecatedCalls
+	// ConnectionLimits caps open SSE and WebSocket connections; refused
+	// clients are told to poll /_seam/procedure/{name}/poll instead.
+	ConnectionLimits ConnectionLimits
+	// BatchLimits caps calls, summed procedure weights, and execution
+	// time per batch; zero fields disable a check (see DefaultBatchLimits).
+	BatchLimits BatchLimits
+	// Tuning lets operators change the RPC timeout, a per-caller rate
+	// limit, cache TTLs, and the log level at runtime through its admin
+	// procedure (register Tuning.Procedure on the router).
+	Tuning *Tuning
+	// RateLimits caps calls per caller by procedure class, with separate
+	// budgets for authenticated and anonymous callers.
+	RateLimits *RateLimits
+	// Sandbox refuses to build a handler while a query or command has no
+	// quota (WithSandbox); built-in and channel procedures are exempt.
+	Sandbox bool
+	// MockProfiles injects latency and failures into procedures when
+	// SEAM_ENV selects them (default: the SEAM_MOCK_PROFILES file, if set).
+	MockProfiles *MockProfiles
+	// Dependencies receives the upstream call outcomes procedures report
+	// with ReportDependency.
+	Dependencies *Dependencies
+	// BuildFallback serves a fallback page instead of a 500 when a page
+	// template is missing at request time (or no BuildSet version is
+	// loaded yet) and logs the missing file.
+	BuildFallback *BuildFallback
+	// MemoryBudget caps the body, loader result, and rendered HTML bytes
+	// a single request may account, and reports the heaviest targets.
+	MemoryBudget *MemoryBudget
+
+	activity *activityTracker // set by Router.handlerOptions
 }
 
 var defaultHandlerOptions = HandlerOptions{
-	RPCTimeout:        30 * time.Second,
-	PageTimeout:       30 * time.Second,
-	SSEIdleTimeout:    12 * time.Second,
-	HeartbeatInterval: 8 * time.Second,
-	PongTimeout:       5 * time.Second,
+	RPCTimeout:           30 * time.Second,
+	PageTimeout:          30 * time.Second,
+	SSEIdleTimeout:       12 * time.Second,
+	HeartbeatInterval:    8 * time.Second,
+	PongTimeout:          5 * time.Second,
+	VersionHeader:        "X-Seam-Version",
+	ReconnectDelay:       time.Second,
+	MaxSubscriptionInput: 8 << 10,
 }
 
 // Router collects procedure, subscription, channel, and page definitions and
@@ -279,6 +531,12 @@ type Router struct {
 	contextConfigs map[string]ContextConfig
 	appState       any
 	validationMode ValidationMode
+	hub            *Hub
+	groups         []RouteGroup
+	redirects      []RedirectRule
+	optionOverlays []func(*HandlerOptions) // Overlay.Options, in order
+	tracker        *activityTracker        // ActiveStreams of built handlers
+	served         *HandlerOptions         // options of the last built handler, for StartupConfig
 }
 
 func NewRouter() *Router {
@@ -307,7 +565,7 @@ func (r *Router) Validation(mode ValidationMode) *Router {
 }
 
 func (r *Router) Procedure(def *ProcedureDef) *Router {
-	r.procedures = append(r.procedures, *def)
+	r.procedures = append(r.procedures, sandboxed(*def))
 	return r
 }
 
@@ -327,6 +585,7 @@ func (r *Router) Upload(def *UploadDef) *Router {
 }
 
 func (r *Router) Channel(def ChannelDef) *Router {
+	def.validateEcho()
 	r.channels = append(r.channels, def)
 	return r
 }
@@ -335,7 +594,7 @@ func (r *Router) Channel(def ChannelDef) *Router {
 func (r *Router) Namespace(prefix string, procs ...*ProcedureDef) *Router {
 	for _, p := range procs {
 		p.Name = prefix + "." + p.Name
-		r.procedures = append(r.procedures, *p)
+		r.procedures = append(r.procedures, sandboxed(*p))
 	}
 	return r
 }
@@ -375,6 +634,7 @@ func (r *Router) Build(b BuildOutput) *Router {
 	if b.PublicDir != "" {
 		r.publicDir = b.PublicDir
 	}
+	r.redirects = append(r.redirects, b.Redirects...)
 	return r
 }
 
@@ -397,26 +657,20 @@ func (r *Router) ResolveStrategies(strategies ...ResolveStrategy) *Router {
 // (e.g. printing to stdout with --manifest). Channels are expanded to
 // Level 0 primitives, matching the runtime manifest exactly.
 func (r *Router) Manifest() ([]byte, error) {
-	var channelMetas map[string]channelMeta
-	// Collect procedure/subscription copies so we don't mutate Router state
-	procs := append([]ProcedureDef{}, r.procedures...)
-	subs := append([]SubscriptionDef{}, r.subscriptions...)
-	for _, ch := range r.channels {
-		p, s, meta := ch.expand()
-		procs = append(procs, p...)
-		subs = append(subs, s...)
-		if channelMetas == nil {
-			channelMetas = make(map[string]channelMeta)
-		}
-		channelMetas[ch.Name] = meta
-	}
+	procs, subs, channelMetas := r.expandAll()
 	m := buildManifest(procs, subs, r.streams, r.uploads, channelMetas, r.contextConfigs)
+	m.Pages = pageAnnotations(r.pages)
 	return json.Marshal(m)
 }
 
 // Handler returns an http.Handler that serves all /_seam/* routes.
 // When called with no arguments, default timeouts (30s) are used.
 func (r *Router) Handler(opts ...HandlerOptions) http.Handler {
+	return r.buildWith(r.pages, r.rpcHashMap, r.i18nConfig, r.publicDir, r.redirects, r.handlerOptions(opts))
+}
+
+// handlerOptions applies defaults to the optional HandlerOptions argument.
+func (r *Router) handlerOptions(opts []HandlerOptions) HandlerOptions {
 	o := defaultHandlerOptions
 	if len(opts) > 0 {
 		o = opts[0]
@@ -427,17 +681,40 @@ func (r *Router) Handler(opts ...HandlerOptions) http.Handler {
 		if o.PongTimeout == 0 {
 			o.PongTimeout = defaultHandlerOptions.PongTimeout
 		}
+		if o.VersionHeader == "" {
+			o.VersionHeader = defaultHandlerOptions.VersionHeader
+		}
+		if o.MaxSubscriptionInput == 0 {
+			o.MaxSubscriptionInput = defaultHandlerOptions.MaxSubscriptionInput
+		}
 	}
+	for _, overlay := range r.optionOverlays {
+		overlay(&o)
+	}
+	if o.Hub == nil {
+		o.Hub = r.Hub()
+	}
+	o.activity = r.activity()
+	return o
+}
+
+// buildWith builds a handler from the router's procedures and the given
+// build artifacts. Build-output redirects are evaluated after the ones
+// configured in HandlerOptions.
+func (r *Router) buildWith(pages []PageDef, rpcHashMap *RpcHashMap, i18nConfig *I18nConfig, publicDir string, redirects []RedirectRule, o HandlerOptions) http.Handler {
+	o.Redirects = append(append([]RedirectRule{}, o.Redirects...), redirects...)
+	served := o
+	r.served = &served
 	return buildHandler(
 		r.procedures,
 		r.subscriptions,
 		r.streams,
 		r.uploads,
 		r.channels,
-		r.pages,
-		r.rpcHashMap,
-		r.i18nConfig,
-		r.publicDir,
+		resolveBoundLoaders(applyRouteGroups(pages, r.groups)),
+		rpcHashMap,
+		i18nConfig,
+		publicDir,
 		r.strategies,
 		r.contextConfigs,
 		r.appState,
@@ -0,0 +1,346 @@
+/* src/server/core/go/seamdiff/seamdiff.go */
+
+// Package seamdiff compares seam responses across backends or builds:
+// stable JSON normalization, HTML normalization that sets the page data
+// script aside, and structured diffs naming where two responses differ.
+// The cross-backend parity tests use it, and so can shadow-traffic
+// comparisons of a live backend against a candidate.
+package seamdiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DataScriptIDs are the data script ids NormalizeHTML and SplitHTML look
+// for: the current default and the legacy one.
+var DataScriptIDs = []string{"__data", "__SEAM_DATA__"}
+
+// Options tunes a comparison. The zero value compares everything.
+type Options struct {
+	// Ignore lists dotted paths left out of JSON comparisons, e.g.
+	// "meta.requestId"; "*" matches any object key or array index.
+	Ignore []string
+	// NullIsAbsent treats a null field as equal to a missing one (TS
+	// emits "avatar": null where Rust omits the field).
+	NullIsAbsent bool
+}
+
+// Difference is one place where two responses differ.
+type Difference struct {
+	Path string // dotted JSON path ("" for the root, "[2]" for indices), or "html"
+	Kind string // "changed", "missing" (only in A), "extra" (only in B), or "type"
+	A, B any    // the values (HTML excerpts for "html")
+}
+
+func (d Difference) String() string {
+	path := d.Path
+	if path == "" {
+		path = "(root)"
+	}
+	switch d.Kind {
+	case "missing":
+		return fmt.Sprintf("%s: only in A: %s", path, show(d.A))
+	case "extra":
+		return fmt.Sprintf("%s: only in B: %s", path, show(d.B))
+	}
+	return fmt.Sprintf("%s: %s != %s", path, show(d.A), show(d.B))
+}
+
+// Report renders diffs one per line, or "" when there are none.
+func Report(diffs []Difference) string {
+	lines := make([]string, len(diffs))
+	for i, d := range diffs {
+		lines[i] = d.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+func show(v any) string {
+	if s, ok := v.(string); ok {
+		return strconv.Quote(s)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+// Decode parses JSON keeping numbers exact, normalized so that equal
+// values compare equal (1, 1.0, and 1e0 all become 1).
+func Decode(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("seamdiff: trailing data after JSON value")
+	}
+	return normalizeNumbers(v), nil
+}
+
+func normalizeNumbers(v any) any {
+	switch val := v.(type) {
+	case json.Number:
+		return json.Number(canonicalNumber(val))
+	case []any:
+		for i := range val {
+			val[i] = normalizeNumbers(val[i])
+		}
+	case map[string]any:
+		for k := range val {
+			val[k] = normalizeNumbers(val[k])
+		}
+	}
+	return v
+}
+
+func canonicalNumber(n json.Number) string {
+	if _, err := strconv.ParseInt(n.String(), 10, 64); err == nil {
+		return n.String()
+	}
+	f, err := strconv.ParseFloat(n.String(), 64)
+	if err != nil || math.IsInf(f, 0) {
+		return n.String()
+	}
+	if f == math.Trunc(f) && math.Abs(f) < 1e21 {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// NormalizeJSON re-encodes data compactly with sorted object keys and
+// normalized numbers, so byte-equal output means equal values.
+func NormalizeJSON(data []byte) ([]byte, error) {
+	v, err := Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// CompareJSON decodes two JSON documents and diffs them.
+func CompareJSON(a, b []byte, opts Options) ([]Difference, error) {
+	va, err := Decode(a)
+	if err != nil {
+		return nil, fmt.Errorf("seamdiff: decode A: %w", err)
+	}
+	vb, err := Decode(b)
+	if err != nil {
+		return nil, fmt.Errorf("seamdiff: decode B: %w", err)
+	}
+	return Diff(va, vb, opts), nil
+}
+
+// Diff compares two decoded JSON values (see Decode), returning the
+// differences in path order.
+func Diff(a, b any, opts Options) []Difference {
+	var diffs []Difference
+	diffValue(&diffs, "", a, b, opts)
+	return diffs
+}
+
+func diffValue(diffs *[]Difference, path string, a, b any, opts Options) {
+	if ignored(path, opts.Ignore) {
+		return
+	}
+	switch va := a.(type) {
+	case map[string]any:
+		vb, ok := b.(map[string]any)
+		if !ok {
+			*diffs = append(*diffs, Difference{Path: path, Kind: "type", A: a, B: b})
+			return
+		}
+		keys := make([]string, 0, len(va)+len(vb))
+		for k := range va {
+			keys = append(keys, k)
+		}
+		for k := range vb {
+			if _, ok := va[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			child := joinPath(path, k)
+			x, inA := va[k]
+			y, inB := vb[k]
+			switch {
+			case inA && inB:
+				diffValue(diffs, child, x, y, opts)
+			case ignored(child, opts.Ignore), opts.NullIsAbsent && x == nil && y == nil:
+			case inA:
+				*diffs = append(*diffs, Difference{Path: child, Kind: "missing", A: x})
+			default:
+				*diffs = append(*diffs, Difference{Path: child, Kind: "extra", B: y})
+			}
+		}
+	case []any:
+		vb, ok := b.([]any)
+		if !ok {
+			*diffs = append(*diffs, Difference{Path: path, Kind: "type", A: a, B: b})
+			return
+		}
+		for i := range max(len(va), len(vb)) {
+			child := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i < len(va) && i < len(vb):
+				diffValue(diffs, child, va[i], vb[i], opts)
+			case i < len(va):
+				*diffs = append(*diffs, Difference{Path: child, Kind: "missing", A: va[i]})
+			default:
+				*diffs = append(*diffs, Difference{Path: child, Kind: "extra", B: vb[i]})
+			}
+		}
+	default:
+		if !scalarEqual(a, b) {
+			kind := "changed"
+			if fmt.Sprintf("%T", a) != fmt.Sprintf("%T", b) {
+				kind = "type"
+			}
+			*diffs = append(*diffs, Difference{Path: path, Kind: kind, A: a, B: b})
+		}
+	}
+}
+
+func scalarEqual(a, b any) bool {
+	switch b.(type) {
+	case map[string]any, []any:
+		return false
+	}
+	return a == b
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// ignored reports whether path matches one of the patterns; "*" matches
+// one key, and index segments ("items[3]") match "items.*" or "items".
+func ignored(path string, patterns []string) bool {
+	if path == "" || len(patterns) == 0 {
+		return false
+	}
+	segs := pathSegments(path)
+	for _, p := range patterns {
+		pat := strings.Split(p, ".")
+		if len(pat) != len(segs) {
+			continue
+		}
+		match := true
+		for i := range pat {
+			if pat[i] != "*" && pat[i] != segs[i] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// pathSegments splits "a.b[2].c" into a, b, 2, c.
+func pathSegments(path string) []string {
+	path = strings.ReplaceAll(path, "[", ".")
+	path = strings.ReplaceAll(path, "]", "")
+	return strings.Split(strings.TrimPrefix(path, "."), ".")
+}
+
+var (
+	htmlComment  = regexp.MustCompile(`<!--[\s\S]*?-->`)
+	interTagGap  = regexp.MustCompile(`>\s+<`)
+	whitespaceRe = regexp.MustCompile(`\s+`)
+)
+
+// SplitHTML separates a page's data script from its markup: it returns
+// the HTML without the script and the script's JSON ("" when absent).
+func SplitHTML(html string) (markup, data string) {
+	for _, id := range DataScriptIDs {
+		open := `<script id="` + id + `" type="application/json">`
+		start := strings.Index(html, open)
+		if start < 0 {
+			continue
+		}
+		end := strings.Index(html[start:], "</script>")
+		if end < 0 {
+			continue
+		}
+		end += start
+		return html[:start] + html[end+len("</script>"):], html[start+len(open) : end]
+	}
+	return html, ""
+}
+
+// NormalizeHTML removes the data script and comments and collapses
+// whitespace, so markup from different backends compares byte for byte.
+func NormalizeHTML(html string) string {
+	markup, _ := SplitHTML(html)
+	markup = htmlComment.ReplaceAllString(markup, "")
+	markup = interTagGap.ReplaceAllString(markup, "><")
+	return strings.TrimSpace(whitespaceRe.ReplaceAllString(markup, " "))
+}
+
+// CompareHTML diffs two pages: their normalized markup (one "html"
+// difference with excerpts around the first mismatch) and their data
+// scripts (paths prefixed with "data.").
+func CompareHTML(a, b string, opts Options) ([]Difference, error) {
+	var diffs []Difference
+	ma, mb := NormalizeHTML(a), NormalizeHTML(b)
+	if ma != mb {
+		at := 0
+		for at < len(ma) && at < len(mb) && ma[at] == mb[at] {
+			at++
+		}
+		diffs = append(diffs, Difference{Path: "html", Kind: "changed", A: excerpt(ma, at), B: excerpt(mb, at)})
+	}
+	_, da := SplitHTML(a)
+	_, db := SplitHTML(b)
+	switch {
+	case da == "" && db == "":
+	case da == "" || db == "":
+		diffs = append(diffs, Difference{Path: "data", Kind: "changed", A: da, B: db})
+	default:
+		dataDiffs, err := CompareJSON([]byte(da), []byte(db), opts)
+		if err != nil {
+			return diffs, err
+		}
+		for _, d := range dataDiffs {
+			d.Path = joinPath("data", d.Path)
+			diffs = append(diffs, d)
+		}
+	}
+	return diffs, nil
+}
+
+// excerpt returns up to 40 bytes either side of at.
+func excerpt(s string, at int) string {
+	start, end := max(0, at-40), min(len(s), at+40)
+	out := s[start:end]
+	if start > 0 {
+		out = "…" + out
+	}
+	if end < len(s) {
+		out += "…"
+	}
+	return out
+}
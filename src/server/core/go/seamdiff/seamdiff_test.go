@@ -0,0 +1,64 @@
+/* src/server/core/go/seamdiff/seamdiff_test.go */
+
+package seamdiff
+
+import (
+	"testing"
+)
+
+func TestNormalizeJSON(t *testing.T) {
+	a, err := NormalizeJSON([]byte(`{"b": [1.0, 2e0, 0.5], "a": "<x>"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := NormalizeJSON([]byte(`{"a":"<x>","b":[1,2,0.5]}`))
+	if string(a) != `{"a":"<x>","b":[1,2,0.5]}` || string(a) != string(b) {
+		t.Errorf("normalized %s vs %s", a, b)
+	}
+	if _, err := NormalizeJSON([]byte(`{} {}`)); err == nil {
+		t.Error("trailing value accepted")
+	}
+}
+
+func TestCompareJSON(t *testing.T) {
+	a := `{"ok":true,"data":{"id":1,"name":"Alice","tags":["x","y"],"avatar":null,"meta":{"requestId":"r1"}}}`
+	b := `{"ok":true,"data":{"id":"1","name":"Alicia","tags":["x"],"meta":{"requestId":"r2"},"extra":1}}`
+	diffs, err := CompareJSON([]byte(a), []byte(b), Options{Ignore: []string{"data.meta.requestId"}, NullIsAbsent: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "data.extra: only in B: 1\n" +
+		"data.id: 1 != \"1\"\n" +
+		"data.name: \"Alice\" != \"Alicia\"\n" +
+		"data.tags[1]: only in A: \"y\""
+	if got := Report(diffs); got != want {
+		t.Errorf("report:\n%s\nwant:\n%s", got, want)
+	}
+	if diffs[1].Kind != "type" || diffs[2].Kind != "changed" {
+		t.Errorf("kinds %+v", diffs)
+	}
+
+	diffs, _ = CompareJSON([]byte(`{"items":[{"at":1},{"at":2}]}`), []byte(`{"items":[{"at":3},{"at":4}]}`), Options{Ignore: []string{"items.*.at"}})
+	if len(diffs) != 0 {
+		t.Errorf("wildcard ignore: %v", diffs)
+	}
+}
+
+func TestCompareHTML(t *testing.T) {
+	a := "<html>\n  <body><!-- rust --><p>Hi  Alice</p>\n" +
+		`<script id="__data" type="application/json">{"user":{"name":"Alice","id":1}}</script></body></html>`
+	b := `<html><body><p>Hi Alice</p><script id="__data" type="application/json">{"user":{"id":1.0,"name":"Alice"}}</script></body></html>`
+	diffs, err := CompareHTML(a, b, Options{})
+	if err != nil || len(diffs) != 0 {
+		t.Fatalf("equivalent pages differ: %v %v", diffs, err)
+	}
+
+	c := `<html><body><p>Hi Bob</p><script id="__SEAM_DATA__" type="application/json">{"user":{"id":2,"name":"Bob"}}</script></body></html>`
+	diffs, _ = CompareHTML(b, c, Options{})
+	if len(diffs) != 3 || diffs[0].Path != "html" || diffs[0].A != "<html><body><p>Hi Alice</p></body></html>" || diffs[1].Path != "data.user.id" {
+		t.Errorf("diffs %v", diffs)
+	}
+	if markup, data := SplitHTML(c); markup != "<html><body><p>Hi Bob</p></body></html>" || data != `{"user":{"id":2,"name":"Bob"}}` {
+		t.Errorf("split %q %q", markup, data)
+	}
+}
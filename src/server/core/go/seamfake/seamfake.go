@@ -0,0 +1,355 @@
+/* src/server/core/go/seamfake/seamfake.go */
+
+// Package seamfake provides a wire-compatible test double for seam
+// backends. A Server serves a given manifest and answers its procedures
+// with scripted responses, SSE event scripts, and latency profiles. It
+// runs on the real seam router, so envelopes, batching, input
+// validation, and SSE framing match production. Go consumers of a seam
+// backend (and the client SDK) can be integration-tested without the
+// backend.
+package seamfake
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	seam "github.com/canmi21/seam/src/server/core/go"
+)
+
+// Response is one scripted result of a query, command, or upload.
+type Response struct {
+	Data any
+	Err  *seam.Error
+}
+
+// Event is one scripted event of a subscription or stream.
+type Event struct {
+	Data  any
+	Err   *seam.Error
+	Delay time.Duration // wait before sending the event
+}
+
+// Latency delays every call to a procedure by Base plus a uniform random
+// amount up to Jitter; with probability TailRate, Tail is added as well,
+// simulating slow outliers. Delays end early when the call is canceled.
+type Latency struct {
+	Base     time.Duration
+	Jitter   time.Duration
+	Tail     time.Duration
+	TailRate float64 // 0..1
+}
+
+// Call records one call received by the fake.
+type Call struct {
+	Procedure string
+	Input     json.RawMessage
+	Time      time.Time
+}
+
+// Server is a fake seam backend. It is an http.Handler; Start serves it
+// on a local port. Scripts can be changed while it serves.
+type Server struct {
+	// URL is the base URL after Start, e.g. "http://127.0.0.1:41234".
+	URL string
+
+	manifest []byte
+	kinds    map[string]string
+	handler  http.Handler
+	ts       *httptest.Server
+
+	mu        sync.Mutex
+	responses map[string][]Response
+	funcs     map[string]seam.HandlerFunc
+	events    map[string][]Event
+	latency   map[string]Latency
+	calls     []Call
+}
+
+type manifestFile struct {
+	Procedures map[string]struct {
+		Kind  string `json:"kind"`
+		Input any    `json:"input"`
+	} `json:"procedures"`
+}
+
+// New builds a fake serving manifest (the JSON of a backend's
+// /_seam/manifest.json). Input is validated against the manifest's input
+// schemas; opts tune the handler as for seam.Router.Handler.
+func New(manifest []byte, opts ...seam.HandlerOptions) (*Server, error) {
+	var m manifestFile
+	if err := json.Unmarshal(manifest, &m); err != nil {
+		return nil, fmt.Errorf("seamfake: parse manifest: %w", err)
+	}
+	s := &Server{
+		manifest:  manifest,
+		kinds:     make(map[string]string, len(m.Procedures)),
+		responses: map[string][]Response{},
+		funcs:     map[string]seam.HandlerFunc{},
+		events:    map[string][]Event{},
+		latency:   map[string]Latency{},
+	}
+
+	router := seam.NewRouter().Validation(seam.ValidationModeAlways)
+	hashes := &seam.RpcHashMap{Batch: "_batch", Procedures: make(map[string]string, len(m.Procedures))}
+	for name, p := range m.Procedures {
+		s.kinds[name] = p.Kind
+		hashes.Procedures[name] = name
+		switch p.Kind {
+		case "query", "command":
+			router.Procedure(&seam.ProcedureDef{Name: name, Type: p.Kind, InputSchema: p.Input, Handler: s.respond(name)})
+		case "upload":
+			respond := s.respond(name)
+			router.Upload(&seam.UploadDef{Name: name, InputSchema: p.Input,
+				Handler: func(ctx context.Context, input json.RawMessage, _ *seam.SeamFileHandle) (any, error) {
+					return respond(ctx, input)
+				}})
+		case "subscription":
+			router.Subscription(&seam.SubscriptionDef{Name: name, InputSchema: p.Input, Handler: s.subscribe(name)})
+		case "stream":
+			router.Stream(&seam.StreamDef{Name: name, InputSchema: p.Input, Handler: s.stream(name)})
+		default:
+			return nil, fmt.Errorf("seamfake: procedure '%s' has unknown kind '%s'", name, p.Kind)
+		}
+	}
+	s.handler = router.RpcHashMap(hashes).Handler(opts...)
+	return s, nil
+}
+
+// ServeHTTP serves the manifest verbatim and everything else through the
+// seam handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet && r.URL.Path == "/_seam/manifest.json" {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(s.manifest)
+		return
+	}
+	s.handler.ServeHTTP(w, r)
+}
+
+// Start serves the fake on a local port and sets URL.
+func (s *Server) Start() {
+	s.ts = httptest.NewServer(s)
+	s.URL = s.ts.URL
+}
+
+// Close stops a started fake, closing open SSE connections.
+func (s *Server) Close() {
+	if s.ts != nil {
+		s.ts.CloseClientConnections()
+		s.ts.Close()
+	}
+}
+
+// Respond scripts a query, command, or upload: calls take the responses
+// in order and the last one repeats. It replaces any earlier script or
+// HandleFunc for the procedure.
+func (s *Server) Respond(procedure string, responses ...Response) *Server {
+	s.mustKind(procedure, "query", "command", "upload")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.funcs, procedure)
+	s.responses[procedure] = append([]Response(nil), responses...)
+	return s
+}
+
+// HandleFunc answers a query, command, or upload with fn, for responses
+// that depend on the input.
+func (s *Server) HandleFunc(procedure string, fn seam.HandlerFunc) *Server {
+	s.mustKind(procedure, "query", "command", "upload")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.responses, procedure)
+	s.funcs[procedure] = fn
+	return s
+}
+
+// Events scripts a subscription or stream: every connection receives the
+// events in order, then completes.
+func (s *Server) Events(procedure string, events ...Event) *Server {
+	s.mustKind(procedure, "subscription", "stream")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events[procedure] = append([]Event(nil), events...)
+	return s
+}
+
+// SetLatency sets the latency profile of a procedure; "" sets the
+// default for procedures without their own.
+func (s *Server) SetLatency(procedure string, l Latency) *Server {
+	if procedure != "" {
+		s.mustKind(procedure)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency[procedure] = l
+	return s
+}
+
+// Calls returns the calls received for procedure, or every call for "",
+// in arrival order.
+func (s *Server) Calls(procedure string) []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var calls []Call
+	for _, c := range s.calls {
+		if procedure == "" || c.Procedure == procedure {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// Reset clears scripts, latency profiles, and recorded calls.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses = map[string][]Response{}
+	s.funcs = map[string]seam.HandlerFunc{}
+	s.events = map[string][]Event{}
+	s.latency = map[string]Latency{}
+	s.calls = nil
+}
+
+// mustKind panics when procedure is not in the manifest or (with kinds)
+// is of another kind, so typos in test scripts fail loudly.
+func (s *Server) mustKind(procedure string, kinds ...string) {
+	kind, ok := s.kinds[procedure]
+	if !ok {
+		panic(fmt.Sprintf("seamfake: procedure '%s' is not in the manifest", procedure))
+	}
+	if len(kinds) == 0 {
+		return
+	}
+	for _, k := range kinds {
+		if k == kind {
+			return
+		}
+	}
+	panic(fmt.Sprintf("seamfake: procedure '%s' is a %s", procedure, kind))
+}
+
+// begin records a call and returns its delay.
+func (s *Server) begin(procedure string, input json.RawMessage) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, Call{Procedure: procedure, Input: append(json.RawMessage(nil), input...), Time: time.Now()})
+	l, ok := s.latency[procedure]
+	if !ok {
+		l = s.latency[""]
+	}
+	return l.delay()
+}
+
+func (l Latency) delay() time.Duration {
+	d := l.Base
+	if l.Jitter > 0 {
+		d += rand.N(l.Jitter)
+	}
+	if l.Tail > 0 && rand.Float64() < l.TailRate {
+		d += l.Tail
+	}
+	return d
+}
+
+func (s *Server) respond(procedure string) seam.HandlerFunc {
+	return func(ctx context.Context, input json.RawMessage) (any, error) {
+		if err := sleep(ctx, s.begin(procedure, input)); err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		fn := s.funcs[procedure]
+		script := s.responses[procedure]
+		var res Response
+		if len(script) > 0 {
+			res = script[0]
+			if len(script) > 1 {
+				s.responses[procedure] = script[1:]
+			}
+		}
+		s.mu.Unlock()
+
+		switch {
+		case fn != nil:
+			return fn(ctx, input)
+		case len(script) == 0:
+			return nil, seam.InternalError(fmt.Sprintf("seamfake: no response scripted for '%s'", procedure))
+		case res.Err != nil:
+			return nil, res.Err
+		}
+		return res.Data, nil
+	}
+}
+
+// play sends the procedure's event script through send.
+func (s *Server) play(ctx context.Context, procedure string, send func(Event) bool) {
+	s.mu.Lock()
+	events := s.events[procedure]
+	s.mu.Unlock()
+	for _, ev := range events {
+		if sleep(ctx, ev.Delay) != nil || !send(ev) {
+			return
+		}
+	}
+}
+
+func (s *Server) subscribe(procedure string) seam.SubscriptionHandlerFunc {
+	return func(ctx context.Context, input json.RawMessage) (<-chan seam.SubscriptionEvent, error) {
+		if err := sleep(ctx, s.begin(procedure, input)); err != nil {
+			return nil, err
+		}
+		ch := make(chan seam.SubscriptionEvent)
+		go func() {
+			defer close(ch)
+			s.play(ctx, procedure, func(ev Event) bool {
+				select {
+				case ch <- seam.SubscriptionEvent{Value: ev.Data, Err: ev.Err}:
+					return true
+				case <-ctx.Done():
+					return false
+				}
+			})
+		}()
+		return ch, nil
+	}
+}
+
+func (s *Server) stream(procedure string) seam.StreamHandlerFunc {
+	return func(ctx context.Context, input json.RawMessage) (<-chan seam.StreamEvent, error) {
+		if err := sleep(ctx, s.begin(procedure, input)); err != nil {
+			return nil, err
+		}
+		ch := make(chan seam.StreamEvent)
+		go func() {
+			defer close(ch)
+			s.play(ctx, procedure, func(ev Event) bool {
+				select {
+				case ch <- seam.StreamEvent{Value: ev.Data, Err: ev.Err}:
+					return true
+				case <-ctx.Done():
+					return false
+				}
+			})
+		}()
+		return ch, nil
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,193 @@
+/* src/server/core/go/seamfake/seamfake_test.go */
+
+package seamfake
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	seam "github.com/canmi21/seam/src/server/core/go"
+)
+
+type userInput struct {
+	ID string `json:"id"`
+}
+
+type user struct {
+	Name string `json:"name"`
+}
+
+// manifest returns the manifest a real backend with these procedures serves.
+func manifest(t *testing.T) []byte {
+	t.Helper()
+	h := seam.NewRouter().
+		Procedure(seam.Query("getUser", func(context.Context, userInput) (user, error) { return user{}, nil })).
+		Procedure(seam.Command("rename", func(context.Context, user) (user, error) { return user{}, nil })).
+		Subscription(seam.Subscribe("onCount", func(context.Context, struct{}) (<-chan int, error) { return nil, nil })).
+		Handler()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/_seam/manifest.json", nil))
+	return w.Body.Bytes()
+}
+
+func post(t *testing.T, url, body string) (int, string) {
+	t.Helper()
+	resp, err := http.Post(url, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, strings.TrimSpace(string(b))
+}
+
+func newFake(t *testing.T, opts ...seam.HandlerOptions) *Server {
+	t.Helper()
+	s, err := New(manifest(t), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Start()
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestScriptedResponses(t *testing.T) {
+	s := newFake(t)
+	rpc := s.URL + "/_seam/procedure/getUser"
+
+	if status, body := post(t, rpc, `{"id":"1"}`); status != 500 || !strings.Contains(body, "no response scripted") {
+		t.Errorf("unscripted: %d %s", status, body)
+	}
+
+	s.Respond("getUser",
+		Response{Data: user{Name: "ada"}},
+		Response{Err: seam.NotFoundError("No such user")},
+	)
+	if _, body := post(t, rpc, `{"id":"1"}`); body != `{"data":{"name":"ada"},"ok":true}` {
+		t.Errorf("first: %s", body)
+	}
+	for range 2 { // the last response repeats
+		if status, body := post(t, rpc, `{"id":"2"}`); status != 404 || !strings.Contains(body, `"code":"NOT_FOUND"`) {
+			t.Errorf("second: %d %s", status, body)
+		}
+	}
+	if status, body := post(t, rpc, `{"id":3}`); status != 400 || !strings.Contains(body, "VALIDATION_ERROR") {
+		t.Errorf("invalid input: %d %s", status, body)
+	}
+
+	calls := s.Calls("getUser")
+	if len(calls) != 4 || string(calls[1].Input) != `{"id":"1"}` {
+		t.Errorf("calls %+v", calls)
+	}
+}
+
+func TestHandleFuncAndBatch(t *testing.T) {
+	s := newFake(t)
+	s.HandleFunc("getUser", func(_ context.Context, input json.RawMessage) (any, error) {
+		var in userInput
+		_ = json.Unmarshal(input, &in)
+		return user{Name: "user-" + in.ID}, nil
+	})
+	s.Respond("rename", Response{Data: user{Name: "renamed"}})
+
+	_, body := post(t, s.URL+"/_seam/procedure/_batch",
+		`{"calls":[{"procedure":"getUser","input":{"id":"7"}},{"procedure":"rename","input":{"name":"x"}}]}`)
+	want := `{"data":{"results":[{"ok":true,"data":{"name":"user-7"}},{"ok":true,"data":{"name":"renamed"}}]},"ok":true}`
+	if body != want {
+		t.Errorf("batch:\n got %s\nwant %s", body, want)
+	}
+}
+
+func TestEventScript(t *testing.T) {
+	s := newFake(t)
+	s.Events("onCount",
+		Event{Data: 1},
+		Event{Data: 2, Delay: 10 * time.Millisecond},
+		Event{Err: seam.NewError("UNAVAILABLE", "Feed lost", 503)},
+	)
+	resp, err := http.Get(s.URL + "/_seam/procedure/onCount?input=%7B%7D")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	body := string(b)
+	for _, want := range []string{
+		"event: data\nid: 0\ndata: 1\n\n",
+		"event: data\nid: 1\ndata: 2\n\n",
+		`"code":"UNAVAILABLE"`,
+		"event: complete\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %q in %q", want, body)
+		}
+	}
+}
+
+func TestLatencyProfile(t *testing.T) {
+	s := newFake(t, seam.HandlerOptions{RPCTimeout: 30 * time.Millisecond})
+	s.Respond("getUser", Response{Data: user{}})
+	s.Respond("rename", Response{Data: user{}})
+	s.SetLatency("", Latency{Base: 20 * time.Millisecond})
+	s.SetLatency("rename", Latency{Base: time.Second})
+
+	start := time.Now()
+	if status, _ := post(t, s.URL+"/_seam/procedure/getUser", `{"id":"1"}`); status != 200 {
+		t.Errorf("getUser status %d", status)
+	}
+	if d := time.Since(start); d < 20*time.Millisecond {
+		t.Errorf("default latency not applied: %s", d)
+	}
+	if status, body := post(t, s.URL+"/_seam/procedure/rename", `{"name":"x"}`); status != 504 {
+		t.Errorf("slow call: %d %s", status, body)
+	}
+
+	l := Latency{Base: time.Millisecond, Jitter: time.Millisecond, Tail: time.Second, TailRate: 1}
+	if d := l.delay(); d < time.Second+time.Millisecond || d >= time.Second+2*time.Millisecond {
+		t.Errorf("delay %s", d)
+	}
+}
+
+func TestManifestServedVerbatim(t *testing.T) {
+	m := manifest(t)
+	s := newFake(t)
+	resp, err := http.Get(s.URL + "/_seam/manifest.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	if string(b) != string(m) {
+		t.Errorf("manifest\n got %s\nwant %s", b, m)
+	}
+}
+
+func TestUnknownProcedurePanics(t *testing.T) {
+	s, err := New(manifest(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, script := range map[string]func(){
+		"missing": func() { s.Respond("getUsr", Response{}) },
+		"kind":    func() { s.Events("getUser", Event{}) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: no panic", name)
+				}
+			}()
+			script()
+		}()
+	}
+	if _, err := New([]byte(`{"procedures":{"x":{"kind":"rpc"}}}`)); err == nil {
+		t.Error("unknown kind accepted")
+	}
+}
@@ -0,0 +1,47 @@
+/* src/server/core/go/security_headers_test.go */
+
+package seam
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSecurityHeadersOnPages(t *testing.T) {
+	handler := NewRouter().
+		Page(&PageDef{Route: "/", Template: "<html><body>home</body></html>"}).
+		Handler(HandlerOptions{SecurityHeaders: &SecurityHeaders{
+			HSTSMaxAge: time.Hour,
+			Overrides: map[string]string{
+				"cross-origin-embedder-policy": "",
+				"Referrer-Policy":              "no-referrer",
+			},
+		}})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/_seam/page/", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d", w.Code)
+	}
+	want := map[string]string{
+		"Strict-Transport-Security":    "max-age=3600; includeSubDomains",
+		"X-Content-Type-Options":       "nosniff",
+		"Referrer-Policy":              "no-referrer",
+		"Cross-Origin-Opener-Policy":   "same-origin",
+		"Cross-Origin-Embedder-Policy": "",
+		"Content-Security-Policy":      "frame-ancestors 'self'",
+	}
+	for name, value := range want {
+		if got := w.Header().Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/_seam/manifest.json", nil))
+	if w.Header().Get("X-Content-Type-Options") != "" {
+		t.Error("security headers should not be added to protocol routes")
+	}
+}
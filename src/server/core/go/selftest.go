@@ -0,0 +1,383 @@
+/* src/server/core/go/selftest.go */
+
+package seam
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SelfTestOptions configures Router.SelfTest.
+type SelfTestOptions struct {
+	// Handler renders the page routes (default: a fresh r.Handler()).
+	Handler http.Handler
+	// Paths gives concrete paths for dynamic page routes
+	// ("/user/:id" -> "/user/1"); other dynamic segments become "selftest".
+	Paths map[string]string
+	// Skip lists procedure names and page routes left out.
+	Skip []string
+	// Commands also calls commands that declare no fixtures, with an
+	// input derived from their schema. Off by default: a command that
+	// ignores DryRun would write to live dependencies.
+	Commands bool
+	// Timeout bounds each procedure call and page render (default 10s).
+	Timeout time.Duration
+}
+
+// SelfTestResult is the outcome of one exercised procedure or page.
+type SelfTestResult struct {
+	Target   string // "query getUser", "page /user/1", ...
+	Duration time.Duration
+	Err      error // nil when the check passed
+	Skipped  bool
+}
+
+// SelfTestReport lists every check SelfTest made, in target order.
+type SelfTestReport struct {
+	Results []SelfTestResult
+}
+
+// Failed returns the failed checks.
+func (r *SelfTestReport) Failed() []SelfTestResult {
+	var failed []SelfTestResult
+	for _, res := range r.Results {
+		if res.Err != nil {
+			failed = append(failed, res)
+		}
+	}
+	return failed
+}
+
+// WithFixtures declares inputs SelfTest calls the procedure with instead
+// of the input derived from its schema.
+func WithFixtures(inputs ...any) ProcedureOption {
+	return func(p *ProcedureDef) {
+		p.Fixtures = append(p.Fixtures, inputs...)
+	}
+}
+
+// SelfTest exercises the router before it takes traffic: every query is
+// called with its fixtures (WithFixtures) or an input derived from its
+// input schema, and its output must serialize. Commands are skipped
+// unless they declare fixtures or SelfTestOptions.Commands is set, and
+// then run as dry runs: only side effects guarded by DryRun or
+// SideEffect are left out.
+// Subscriptions and streams are opened and closed, and every page route
+// is rendered and must not answer 5xx or leave <!--seam:...--> markers.
+// A procedure answering with a client error (4xx, e.g. NOT_FOUND for a
+// made-up id) passes: it ran. Uploads are skipped. The error joins every
+// failure; the report is always returned.
+func (r *Router) SelfTest(ctx context.Context, opts ...SelfTestOptions) (*SelfTestReport, error) {
+	var o SelfTestOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = 10 * time.Second
+	}
+	skip := func(name string) bool { return slices.Contains(o.Skip, name) }
+	report := &SelfTestReport{}
+	run := func(target string, check func(ctx context.Context) error) {
+		start := time.Now()
+		cctx, cancel := context.WithTimeout(ctx, o.Timeout)
+		defer cancel()
+		err := check(cctx)
+		report.Results = append(report.Results, SelfTestResult{Target: target, Duration: time.Since(start), Err: err})
+	}
+
+	for i := range r.procedures {
+		proc := &r.procedures[i]
+		kind := proc.Type
+		if kind == "" {
+			kind = "query"
+		}
+		if skip(proc.Name) || kind == "command" && len(proc.Fixtures) == 0 && !o.Commands {
+			report.Results = append(report.Results, SelfTestResult{Target: kind + " " + proc.Name, Skipped: true})
+			continue
+		}
+		inputs := proc.Fixtures
+		if len(inputs) == 0 {
+			inputs = []any{SchemaExample(proc.InputSchema)}
+		}
+		for n, input := range inputs {
+			target := kind + " " + proc.Name
+			if len(inputs) > 1 {
+				target += fmt.Sprintf(" (fixture %d)", n+1)
+			}
+			run(target, func(ctx context.Context) error {
+				if kind == "command" {
+					ctx = context.WithValue(ctx, dryRunKey, true)
+				}
+				return r.selfTestProcedure(ctx, proc, input)
+			})
+		}
+	}
+	for i := range r.subscriptions {
+		sub := &r.subscriptions[i]
+		if skip(sub.Name) {
+			report.Results = append(report.Results, SelfTestResult{Target: "subscription " + sub.Name, Skipped: true})
+			continue
+		}
+		run("subscription "+sub.Name, func(ctx context.Context) error {
+			raw, err := json.Marshal(SchemaExample(sub.InputSchema))
+			if err != nil {
+				return err
+			}
+			ctx, cancel := context.WithCancel(injectState(ctx, r.appState))
+			defer cancel()
+			_, err = sub.Handler(ctx, raw)
+			return selfTestError(err)
+		})
+	}
+	for i := range r.streams {
+		st := &r.streams[i]
+		if skip(st.Name) {
+			report.Results = append(report.Results, SelfTestResult{Target: "stream " + st.Name, Skipped: true})
+			continue
+		}
+		run("stream "+st.Name, func(ctx context.Context) error {
+			raw, err := json.Marshal(SchemaExample(st.InputSchema))
+			if err != nil {
+				return err
+			}
+			ctx, cancel := context.WithCancel(injectState(ctx, r.appState))
+			defer cancel()
+			_, err = st.Handler(ctx, raw)
+			return selfTestError(err)
+		})
+	}
+	for i := range r.uploads {
+		report.Results = append(report.Results, SelfTestResult{Target: "upload " + r.uploads[i].Name, Skipped: true})
+	}
+
+	if len(r.pages) > 0 {
+		h := o.Handler
+		if h == nil {
+			h = r.Handler()
+		}
+		for i := range r.pages {
+			route := r.pages[i].Route
+			if skip(route) {
+				report.Results = append(report.Results, SelfTestResult{Target: "page " + route, Skipped: true})
+				continue
+			}
+			path, ok := o.Paths[route]
+			if !ok {
+				path = selfTestPath(route)
+			}
+			run("page "+path, func(ctx context.Context) error {
+				return selfTestPage(ctx, h, path)
+			})
+		}
+	}
+
+	var errs []error
+	for _, res := range report.Results {
+		if res.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", res.Target, res.Err))
+		}
+	}
+	return report, errors.Join(errs...)
+}
+
+func (r *Router) selfTestProcedure(ctx context.Context, proc *ProcedureDef, input any) error {
+	raw, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	out, err := proc.Handler(injectState(ctx, r.appState), raw)
+	if err != nil {
+		return selfTestError(err)
+	}
+	if _, err := json.Marshal(out); err != nil {
+		return fmt.Errorf("output not serializable: %w", err)
+	}
+	return nil
+}
+
+// selfTestError keeps failures: errors that are not seam client errors.
+func selfTestError(err error) error {
+	var seamErr *Error
+	if err == nil || errors.As(err, &seamErr) && errorHTTPStatus(seamErr) < 500 {
+		return nil
+	}
+	return err
+}
+
+// selfTestPath fills the dynamic segments of route with "selftest".
+func selfTestPath(route string) string {
+	parts := strings.Split(route, "/")
+	for i, p := range parts {
+		if strings.HasPrefix(p, ":") {
+			parts[i] = "selftest"
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+func selfTestPage(ctx context.Context, h http.Handler, path string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, seamPagePath(path, ""), nil)
+	if err != nil {
+		return err
+	}
+	w := &captureResponse{header: http.Header{}}
+	h.ServeHTTP(w, req)
+	if w.status >= 500 {
+		return fmt.Errorf("status %d: %s", w.status, strings.TrimSpace(w.body.String()))
+	}
+	if markers := unresolvedMarkers(w.body.String()); len(markers) > 0 {
+		return fmt.Errorf("unresolved markers: %s", strings.Join(markers, ", "))
+	}
+	return nil
+}
+
+// captureResponse is a ResponseWriter that keeps the status and body.
+type captureResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *captureResponse) Header() http.Header { return w.header }
+
+func (w *captureResponse) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(b)
+}
+
+func (w *captureResponse) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+// SelfTestMain runs SelfTest and exits when the process was started with
+// --selftest (or SEAM_SELFTEST=1): status 0 when every check passed,
+// else 1, after printing the report to stderr. Otherwise it returns at
+// once. Call it before serving, e.g. as a container healthcheck gate:
+//
+//	router.SelfTestMain(ctx)
+//	http.ListenAndServe(addr, router.Handler())
+func (r *Router) SelfTestMain(ctx context.Context, opts ...SelfTestOptions) {
+	if !slices.Contains(os.Args[1:], "--selftest") && os.Getenv("SEAM_SELFTEST") != "1" {
+		return
+	}
+	report, err := r.SelfTest(ctx, opts...)
+	for _, res := range report.Results {
+		switch {
+		case res.Skipped:
+			fmt.Fprintf(os.Stderr, "[seam] selftest skip %s\n", res.Target)
+		case res.Err != nil:
+			fmt.Fprintf(os.Stderr, "[seam] selftest FAIL %s (%s): %s\n", res.Target, res.Duration.Round(time.Microsecond), res.Err)
+		default:
+			fmt.Fprintf(os.Stderr, "[seam] selftest ok   %s (%s)\n", res.Target, res.Duration.Round(time.Microsecond))
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[seam] selftest: %d of %d checks failed\n", len(report.Failed()), len(report.Results))
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "[seam] selftest: %d checks passed\n", len(report.Results))
+	os.Exit(0)
+}
+
+// SchemaExample returns a value valid against a JTD schema: the first
+// enum member or discriminator mapping, one element per list, required
+// properties only, and fixed sample scalars ("example", 1, true, a
+// timestamp). Nullable schemas still get a non-null value, except on
+// recursion through refs.
+func SchemaExample(schema any) any {
+	var m map[string]any
+	switch s := schema.(type) {
+	case nil:
+		return map[string]any{}
+	case map[string]any:
+		m = s
+	default:
+		raw, err := json.Marshal(s)
+		if err != nil || json.Unmarshal(raw, &m) != nil {
+			return nil
+		}
+	}
+	defs, _ := m["definitions"].(map[string]any)
+	return schemaExample(m, defs, 0)
+}
+
+func schemaExample(s, defs map[string]any, depth int) any {
+	if depth > 8 {
+		return nil
+	}
+	if ref, ok := s["ref"].(string); ok {
+		def, _ := defs[ref].(map[string]any)
+		if def == nil {
+			return nil
+		}
+		if nullable, _ := s["nullable"].(bool); nullable && depth > 0 {
+			return nil
+		}
+		return schemaExample(def, defs, depth+1)
+	}
+	if t, ok := s["type"].(string); ok {
+		switch t {
+		case "boolean":
+			return true
+		case "string":
+			if meta, ok := s["metadata"].(map[string]any); ok && meta["format"] != nil {
+				return "1"
+			}
+			return "example"
+		case "timestamp":
+			return "2024-01-01T00:00:00Z"
+		case "float32", "float64", "int8", "uint8", "int16", "uint16", "int32", "uint32":
+			return 1
+		}
+		return nil
+	}
+	if enum, ok := s["enum"].([]any); ok && len(enum) > 0 {
+		return enum[0]
+	}
+	if elems, ok := s["elements"].(map[string]any); ok {
+		return []any{schemaExample(elems, defs, depth+1)}
+	}
+	if _, ok := s["values"].(map[string]any); ok {
+		return map[string]any{}
+	}
+	if tag, ok := s["discriminator"].(string); ok {
+		mapping, _ := s["mapping"].(map[string]any)
+		keys := make([]string, 0, len(mapping))
+		for k := range mapping {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		if len(keys) == 0 {
+			return map[string]any{tag: ""}
+		}
+		variant, _ := mapping[keys[0]].(map[string]any)
+		out, _ := schemaExample(variant, defs, depth+1).(map[string]any)
+		if out == nil {
+			out = map[string]any{}
+		}
+		out[tag] = keys[0]
+		return out
+	}
+	out := map[string]any{}
+	if props, ok := s["properties"].(map[string]any); ok {
+		for k, v := range props {
+			if ps, ok := v.(map[string]any); ok {
+				out[k] = schemaExample(ps, defs, depth+1)
+			}
+		}
+	}
+	return out
+}
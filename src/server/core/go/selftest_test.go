@@ -0,0 +1,111 @@
+/* src/server/core/go/selftest_test.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type selfTestInput struct {
+	ID    string   `json:"id"`
+	Tags  []string `json:"tags"`
+	Limit int      `json:"limit"`
+	Note  *string  `json:"note,omitempty"`
+}
+
+func TestSchemaExample(t *testing.T) {
+	got := SchemaExample(SchemaOf[selfTestInput]())
+	m, ok := got.(map[string]any)
+	if !ok || m["id"] != "example" || m["limit"] != 1 || len(m["tags"].([]any)) != 1 {
+		t.Fatalf("example %#v", got)
+	}
+	if _, ok := m["note"]; ok {
+		t.Errorf("optional property included: %#v", m)
+	}
+
+	union := map[string]any{
+		"discriminator": "kind",
+		"mapping": map[string]any{
+			"b": map[string]any{"properties": map[string]any{"n": map[string]any{"type": "uint8"}}},
+			"a": map[string]any{"properties": map[string]any{"s": map[string]any{"enum": []any{"x", "y"}}}},
+		},
+	}
+	if got := SchemaExample(union).(map[string]any); got["kind"] != "a" || got["s"] != "x" {
+		t.Errorf("discriminator example %#v", got)
+	}
+
+	tree := map[string]any{
+		"definitions": map[string]any{"node": map[string]any{"properties": map[string]any{
+			"children": map[string]any{"elements": map[string]any{"ref": "node"}},
+		}}},
+		"ref": "node",
+	}
+	if got := SchemaExample(tree); got == nil {
+		t.Error("recursive schema produced nil")
+	}
+	raw, _ := json.Marshal(m)
+	if _, err := ParseRPCInput(SchemaOf[selfTestInput](), raw); err != nil {
+		t.Errorf("example fails its schema: %s", err.Message)
+	}
+}
+
+func TestSelfTest(t *testing.T) {
+	var dryRuns, fixtureIDs []string
+	router := NewRouter().
+		Procedure(Query("getItem", func(_ context.Context, in selfTestInput) (selfTestInput, error) {
+			fixtureIDs = append(fixtureIDs, in.ID)
+			return in, nil
+		}, WithFixtures(selfTestInput{ID: "a"}, selfTestInput{ID: "b"}))).
+		Procedure(Query("missing", func(context.Context, selfTestInput) (string, error) {
+			return "", NotFoundError("No such item")
+		})).
+		Procedure(Command("save", func(ctx context.Context, in selfTestInput) (bool, error) {
+			if DryRun(ctx) {
+				dryRuns = append(dryRuns, in.ID)
+			}
+			return true, nil
+		})).
+		Procedure(Query("broken", func(context.Context, struct{}) (string, error) {
+			return "", errors.New("database unreachable")
+		})).
+		Page(&PageDef{Route: "/ok/:id", Template: `<html><body>ok</body></html>`}).
+		Procedure(Query("getBody", func(context.Context, struct{}) (string, error) {
+			return "<p><!--seam:user.name--></p>", nil
+		})).
+		Page(&PageDef{
+			Route:    "/bad",
+			Template: `<html><body><!--seam:body:html--></body></html>`,
+			Loaders:  []LoaderDef{{DataKey: "body", Procedure: "getBody", InputFn: func(map[string]string) any { return struct{}{} }}},
+		})
+
+	report, err := router.SelfTest(context.Background(), SelfTestOptions{Skip: []string{"nothing"}, Commands: true})
+	if err == nil {
+		t.Fatal("expected failures")
+	}
+	var failed []string
+	for _, res := range report.Failed() {
+		failed = append(failed, res.Target)
+	}
+	if strings.Join(failed, ",") != "query broken,page /bad" {
+		t.Errorf("failed %v (%v)", failed, err)
+	}
+	if strings.Join(fixtureIDs, ",") != "a,b" || strings.Join(dryRuns, ",") != "example" {
+		t.Errorf("fixtures %v, dry runs %v", fixtureIDs, dryRuns)
+	}
+	if len(report.Results) != 8 || report.Results[0].Target != "query getItem (fixture 1)" || report.Results[6].Target != "page /ok/selftest" {
+		t.Errorf("results %+v", report.Results)
+	}
+
+	// commands without fixtures are skipped unless Commands is set
+	report, err = router.SelfTest(context.Background(), SelfTestOptions{Skip: []string{"broken", "/bad"}})
+	if err != nil {
+		t.Errorf("skipped failures still reported: %v", err)
+	}
+	if len(dryRuns) != 1 || report.Results[3] != (SelfTestResult{Target: "command save", Skipped: true}) {
+		t.Errorf("dry runs %v, result %+v", dryRuns, report.Results[3])
+	}
+}
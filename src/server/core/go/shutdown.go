@@ -0,0 +1,71 @@
+/* src/server/core/go/shutdown.go */
+
+package seam
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Graceful shutdown: the first long-lived request served through an
+// *http.Server registers an OnShutdown hook, so http.Server.Shutdown (and
+// ListenAndServe on SIGTERM) notifies open SSE and WebSocket clients with a
+// "server-restarting" event before their connections close, and new
+// subscriptions are refused while draining.
+
+// restartNotice is the payload of the server-restarting event.
+type restartNotice struct {
+	ReconnectDelay int64 `json:"reconnectDelay"` // milliseconds
+}
+
+// watchServer registers the shutdown hook on the serving http.Server once.
+func (s *appState) watchServer(r *http.Request) {
+	srv, ok := r.Context().Value(http.ServerContextKey).(*http.Server)
+	if !ok {
+		return
+	}
+	if _, loaded := s.servers.LoadOrStore(srv, struct{}{}); !loaded {
+		srv.RegisterOnShutdown(s.beginShutdown)
+	}
+}
+
+func (s *appState) beginShutdown() {
+	s.shutdownOnce.Do(func() { close(s.shutdownCh) })
+}
+
+func (s *appState) shuttingDown() bool {
+	select {
+	case <-s.shutdownCh:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *appState) restartNotice() restartNotice {
+	return restartNotice{ReconnectDelay: s.reconnectDelay().Milliseconds()}
+}
+
+func (s *appState) unavailableError() *Error {
+	e := NewError("UNAVAILABLE", "Server is shutting down", http.StatusServiceUnavailable)
+	e.RetryAfter = s.reconnectDelay()
+	return e
+}
+
+// reconnectDelay is the reconnect hint of the restart notice and the retry
+// hint of shutdown and shedding errors: ReconnectDelay, one second when unset.
+func (s *appState) reconnectDelay() time.Duration {
+	if s.opts.ReconnectDelay <= 0 {
+		return time.Second
+	}
+	return s.opts.ReconnectDelay
+}
+
+// writeSSERestarting sends the shutdown notice on an SSE stream.
+func (s *appState) writeSSERestarting(w http.ResponseWriter) {
+	_, _ = fmt.Fprintf(w, "event: server-restarting\ndata: %s\n\n", mustJSON(s.restartNotice()))
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,104 @@
+/* src/server/core/go/shutdown_test.go */
+
+package seam
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func shutdownTestHandler(reconnectDelay time.Duration) http.Handler {
+	blocking := func(ctx context.Context, _ json.RawMessage) (<-chan SubscriptionEvent, error) {
+		ch := make(chan SubscriptionEvent)
+		go func() { <-ctx.Done(); close(ch) }()
+		return ch, nil
+	}
+	return NewRouter().
+		Subscription(&SubscriptionDef{Name: "live", Handler: blocking}).
+		Channel(echoTestChannel()).
+		Handler(HandlerOptions{HeartbeatInterval: time.Minute, ReconnectDelay: reconnectDelay})
+}
+
+func TestShutdownNotifiesSSEAndRefusesNewSubscriptions(t *testing.T) {
+	handler := shutdownTestHandler(2500 * time.Millisecond)
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/_seam/procedure/live")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	reader := bufio.NewReader(resp.Body)
+	if line, _ := reader.ReadString('\n'); line != ": heartbeat\n" {
+		t.Fatalf("expected initial heartbeat, got %q", line)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	shutdownErr := make(chan error, 1)
+	go func() { shutdownErr <- srv.Config.Shutdown(ctx) }()
+
+	var rest strings.Builder
+	for {
+		line, err := reader.ReadString('\n')
+		rest.WriteString(line)
+		if err != nil || strings.HasPrefix(line, "data: ") {
+			break
+		}
+	}
+	if !strings.Contains(rest.String(), "event: server-restarting\ndata: {\"reconnectDelay\":2500}") {
+		t.Fatalf("expected server-restarting event, got %q", rest.String())
+	}
+	if err := <-shutdownErr; err != nil {
+		t.Fatalf("shutdown did not drain: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/_seam/procedure/live", http.NoBody))
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "3" {
+		t.Fatalf("expected 503 with Retry-After 3, got %d %q", w.Code, w.Header().Get("Retry-After"))
+	}
+}
+
+func TestShutdownNotifiesChannelWebSocket(t *testing.T) {
+	// ReconnectDelay unset: the notice carries the one-second fallback
+	srv := httptest.NewServer(shutdownTestHandler(0))
+	defer srv.Close()
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + `/_seam/procedure/chat.events?input={"roomId":"r1"}`
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// Hijacked connections are not tracked by Shutdown; it returns at once
+	_ = srv.Config.Shutdown(context.Background())
+
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var push struct {
+		Event   string        `json:"event"`
+		Payload restartNotice `json:"payload"`
+	}
+	if err := conn.ReadJSON(&push); err != nil || push.Event != "server-restarting" {
+		t.Fatalf("expected server-restarting push, got %+v (%v)", push, err)
+	}
+	if push.Payload.ReconnectDelay != 1000 {
+		t.Fatalf("expected reconnectDelay 1000, got %d", push.Payload.ReconnectDelay)
+	}
+	_, _, err = conn.ReadMessage()
+	var closeErr *websocket.CloseError
+	if !errors.As(err, &closeErr) || closeErr.Code != websocket.CloseGoingAway {
+		t.Fatalf("expected going-away close, got %v", err)
+	}
+}
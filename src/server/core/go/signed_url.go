@@ -0,0 +1,124 @@
+/* src/server/core/go/signed_url.go */
+
+package seam
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// URLSigner mints short-lived signed subscription URLs (SSE and channel
+// WebSocket endpoints) that pages can embed for anonymous visitors
+// instead of long-lived credentials. A signature binds the subscription
+// name, the exact input, an expiry, and an optional principal, which the
+// handler adopts as the request principal. Set it as
+// HandlerOptions.SignedURLs; with Required, unsigned subscription
+// requests are rejected, and so are subscriptions started over /_seam/ws,
+// which cannot carry a signature. Secret must not be empty.
+type URLSigner struct {
+	Secret   []byte
+	TTL      time.Duration // lifetime of minted URLs (default 5 minutes)
+	Required bool          // reject GET subscriptions without a valid signature
+}
+
+type signedPrincipalKeyType struct{}
+
+var signedPrincipalKey = signedPrincipalKeyType{}
+
+// check panics when the signer has no secret; an empty HMAC key would
+// let anyone mint valid URLs.
+func (u *URLSigner) check() {
+	if u != nil && len(u.Secret) == 0 {
+		panic("SignedURLs: URLSigner.Secret is empty")
+	}
+}
+
+func (u *URLSigner) ttl() time.Duration {
+	if u.TTL > 0 {
+		return u.TTL
+	}
+	return 5 * time.Minute
+}
+
+// SignSubscription returns "/_seam/procedure/<name>?input=...&exp=...&sig=..."
+// for the subscription (or "<channel>.events" for a channel socket).
+// principal, when not empty, becomes PrincipalOf in the handler.
+func (u *URLSigner) SignSubscription(name string, input any, principal string) (string, error) {
+	u.check()
+	if input == nil {
+		input = map[string]any{}
+	}
+	raw, err := json.Marshal(input)
+	if err != nil {
+		return "", fmt.Errorf("marshal subscription input: %w", err)
+	}
+	exp := strconv.FormatInt(time.Now().Add(u.ttl()).Unix(), 10)
+	q := url.Values{}
+	q.Set("input", string(raw))
+	q.Set("exp", exp)
+	if principal != "" {
+		q.Set("principal", principal)
+	}
+	q.Set("sig", u.sign(name, string(raw), exp, principal))
+	return "/_seam/procedure/" + url.PathEscape(name) + "?" + q.Encode(), nil
+}
+
+func (u *URLSigner) sign(name, input, exp, principal string) string {
+	mac := hmac.New(sha256.New, u.Secret)
+	for _, part := range []string{name, input, exp, principal} {
+		mac.Write([]byte(part))
+		mac.Write([]byte{0})
+	}
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// verify checks the signature of a subscription request, returning the
+// signed principal. ok is false for unsigned requests.
+func (u *URLSigner) verify(name string, query url.Values) (principal string, ok bool, e *Error) {
+	sig := query.Get("sig")
+	if sig == "" {
+		if u.Required {
+			return "", false, UnauthorizedError("Subscription URL must be signed")
+		}
+		return "", false, nil
+	}
+	if query.Has("inputRef") {
+		return "", false, ValidationError("Signed subscription URLs carry their input inline")
+	}
+	exp := query.Get("exp")
+	principal = query.Get("principal")
+	if !secureEqual(sig, u.sign(name, query.Get("input"), exp, principal)) {
+		return "", false, UnauthorizedError("Invalid subscription URL signature")
+	}
+	expiry, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil || time.Now().Unix() > expiry {
+		return "", false, UnauthorizedError("Subscription URL expired")
+	}
+	return principal, true, nil
+}
+
+// checkSignedURL verifies a GET subscription against HandlerOptions.SignedURLs,
+// returning the request with the signed principal attached.
+func (s *appState) checkSignedURL(w http.ResponseWriter, r *http.Request) (*http.Request, bool) {
+	signer := s.opts.SignedURLs
+	if signer == nil {
+		return r, true
+	}
+	principal, signed, e := signer.verify(r.PathValue("name"), r.URL.Query())
+	if e != nil {
+		writeError(w, errorHTTPStatus(e), e)
+		return r, false
+	}
+	if signed && principal != "" {
+		r = r.WithContext(context.WithValue(r.Context(), signedPrincipalKey, principal))
+	}
+	return r, true
+}
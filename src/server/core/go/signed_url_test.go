@@ -0,0 +1,114 @@
+/* src/server/core/go/signed_url_test.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func signedURLTestHandler(signer *URLSigner) http.Handler {
+	return NewRouter().
+		Subscription(&SubscriptionDef{
+			Name: "ticker",
+			Handler: func(ctx context.Context, _ json.RawMessage) (<-chan SubscriptionEvent, error) {
+				ch := make(chan SubscriptionEvent, 1)
+				ch <- SubscriptionEvent{Value: map[string]string{"viewer": PrincipalOf(ctx)}}
+				close(ch)
+				return ch, nil
+			},
+		}).
+		Handler(HandlerOptions{HeartbeatInterval: time.Second, SignedURLs: signer})
+}
+
+func getSigned(h http.Handler, target string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, http.NoBody))
+	return w
+}
+
+func TestSignedSubscriptionURL(t *testing.T) {
+	signer := &URLSigner{Secret: []byte("s3cret"), Required: true}
+	h := signedURLTestHandler(signer)
+
+	target, err := signer.SignSubscription("ticker", map[string]string{"symbol": "SEAM"}, "visitor-7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := getSigned(h, target); !strings.Contains(w.Body.String(), `data: {"viewer":"visitor-7"}`) {
+		t.Fatalf("signed URL: %d %s", w.Code, w.Body.String())
+	}
+
+	u, _ := url.Parse(target)
+	q := u.Query()
+	q.Set("input", `{"symbol":"OTHER"}`)
+	u.RawQuery = q.Encode()
+	if w := getSigned(h, u.String()); w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), "Invalid subscription URL signature") {
+		t.Fatalf("tampered input: %d %s", w.Code, w.Body.String())
+	}
+
+	if w := getSigned(h, "/_seam/procedure/ticker"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("unsigned request: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestSignedSubscriptionURLExpires(t *testing.T) {
+	signer := &URLSigner{Secret: []byte("s3cret")}
+	exp := strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10)
+	target := "/_seam/procedure/ticker?input=%7B%7D&exp=" + exp + "&sig=" + signer.sign("ticker", "{}", exp, "")
+	w := getSigned(signedURLTestHandler(signer), target)
+	if w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), "expired") {
+		t.Fatalf("expired URL: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestUnsignedSubscriptionAllowedWhenOptional(t *testing.T) {
+	w := getSigned(signedURLTestHandler(&URLSigner{Secret: []byte("s3cret")}), "/_seam/procedure/ticker")
+	if !strings.Contains(w.Body.String(), `data: {"viewer":""}`) {
+		t.Fatalf("unsigned optional: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestSignedURLsRequiredOnSocketRPC(t *testing.T) {
+	router := NewRouter().Subscription(Subscribe("idle", func(ctx context.Context, _ struct{}) (<-chan int, error) {
+		ch := make(chan int)
+		go func() { <-ctx.Done(); close(ch) }()
+		return ch, nil
+	}))
+	signer := &URLSigner{Secret: []byte("s3cret"), Required: true}
+	srv := httptest.NewServer(router.Handler(HandlerOptions{WebSocketRPC: true, SignedURLs: signer, HeartbeatInterval: time.Minute}))
+	defer srv.Close()
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/_seam/ws", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	var m map[string]any
+	_ = conn.WriteJSON(map[string]any{"id": "a", "subscribe": "idle"})
+	if err := conn.ReadJSON(&m); err != nil || m["event"] != "error" {
+		t.Fatalf("unsigned socket subscription: %v %v", m, err)
+	}
+	if code := m["error"].(map[string]any)["code"]; code != "UNAUTHORIZED" {
+		t.Fatalf("unexpected error code %v", code)
+	}
+}
+
+func TestSignedURLsRejectEmptySecret(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for an empty secret")
+		}
+	}()
+	signedURLTestHandler(&URLSigner{Required: true})
+}
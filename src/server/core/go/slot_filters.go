@@ -0,0 +1,597 @@
+/* src/server/core/go/slot_filters.go */
+
+package seam
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Slot markers may format their value on the server with filters, using
+// the request locale: <!--seam:post.created_at|date:short-->,
+// <!--seam:price|number:2-->, <!--seam:bio|truncate:140-->. Filters chain
+// left to right and work in :attr:, :style:, and :html slots and on loop
+// paths ($, $$). The engine resolves plain paths only, so before rendering
+// the handler stores the formatted values under the fmtDataKey data key
+// and rewrites each marker to point at its value; loops with filtered
+// items iterate over copies of their items carrying the values. The key
+// stays in the data script so hydration renders the same text: the
+// values of root slots are also under fmtSlotsKey by their marker
+// expression ("post.created_at|date:short"). Only the loader keys the
+// filtered markers reference are decoded. A filter that is unknown or rejects its value
+// leaves the value unformatted and logs a warning once per page.
+
+// SlotFilter formats a slot value for a locale ("" without i18n). value
+// is the JSON-decoded data (string, json.Number, bool, nil, []any, or
+// map[string]any) or the previous filter's result; arg is the text after
+// the filter name's colon ("" when absent).
+type SlotFilter func(value any, arg, locale string) (any, error)
+
+// fmtDataKey holds the formatted slot values in the engine's page data.
+const fmtDataKey = "_fmt"
+
+// fmtSlotsKey holds, under fmtDataKey, the formatted values of root
+// slots keyed by marker expression, for client code to read.
+const fmtSlotsKey = "slots"
+
+var builtinSlotFilters = map[string]SlotFilter{
+	"date":     dateFilter,
+	"number":   numberFilter,
+	"truncate": truncateFilter,
+}
+
+type slotFilterCall struct{ name, arg string }
+
+// filterNode is a filtered slot or an each block of a template.
+type filterNode struct {
+	start, end int    // marker bounds in the template
+	each       bool   // each block (else a filtered slot)
+	scope      int    // -1 root, 0 $, 1 $$
+	path       string // path below the scope ("" for $ itself)
+	expr       string // filtered slot: path and filters as written
+	suffix     string // ":attr:<name>", ":style:<prop>", ":html", or ""
+	filters    []slotFilterCall
+	key        string
+	children   []*filterNode // each: body
+	copies     bool          // each: iterate over item copies holding values
+}
+
+// hasSlotFilters reports whether any marker of tmpl carries a filter.
+func hasSlotFilters(tmpl string) bool {
+	for rest := tmpl; ; {
+		start := strings.Index(rest, "<!--seam:")
+		if start < 0 {
+			return false
+		}
+		rest = rest[start+len("<!--seam:"):]
+		end := strings.Index(rest, "-->")
+		if end < 0 {
+			return false
+		}
+		if strings.Contains(rest[:end], "|") {
+			return true
+		}
+		rest = rest[end+len("-->"):]
+	}
+}
+
+// parseSlotFilters returns the each blocks and filtered slots of tmpl as
+// a tree, in template order.
+func parseSlotFilters(tmpl string) []*filterNode {
+	var roots []*filterNode
+	var stack []*filterNode
+	add := func(n *filterNode) {
+		if len(stack) > 0 {
+			top := stack[len(stack)-1]
+			top.children = append(top.children, n)
+		} else {
+			roots = append(roots, n)
+		}
+	}
+	count := 0
+	for pos := 0; ; {
+		start := strings.Index(tmpl[pos:], "<!--seam:")
+		if start < 0 {
+			return roots
+		}
+		start += pos
+		body := start + len("<!--seam:")
+		end := strings.Index(tmpl[body:], "-->")
+		if end < 0 {
+			return roots
+		}
+		directive := tmpl[body : body+end]
+		pos = body + end + len("-->")
+
+		switch {
+		case strings.HasPrefix(directive, "each:"):
+			scope, path := splitScope(strings.TrimPrefix(directive, "each:"))
+			n := &filterNode{start: start, end: pos, each: true, scope: scope, path: path, key: fmt.Sprintf("_l%d", count)}
+			count++
+			add(n)
+			stack = append(stack, n)
+		case directive == "endeach":
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case strings.HasPrefix(directive, "if:"), strings.HasPrefix(directive, "match:"), strings.HasPrefix(directive, "when:"):
+		case strings.Contains(directive, "|"):
+			slot, suffix := directive, ""
+			if i := strings.Index(slot, ":style:"); i >= 0 {
+				slot, suffix = slot[:i], slot[i:]
+			} else if i := strings.Index(slot, ":attr:"); i >= 0 {
+				slot, suffix = slot[:i], slot[i:]
+			} else if strings.HasSuffix(slot, ":html") {
+				slot, suffix = strings.TrimSuffix(slot, ":html"), ":html"
+			}
+			parts := strings.Split(slot, "|")
+			n := &filterNode{start: start, end: pos, suffix: suffix, expr: strings.TrimSpace(slot), key: fmt.Sprintf("_f%d", count)}
+			count++
+			n.scope, n.path = splitScope(strings.TrimSpace(parts[0]))
+			for _, p := range parts[1:] {
+				name, arg, _ := strings.Cut(strings.TrimSpace(p), ":")
+				n.filters = append(n.filters, slotFilterCall{name: name, arg: arg})
+			}
+			add(n)
+		}
+	}
+}
+
+// splitScope splits a loop scope prefix ($ or $$) off a path.
+func splitScope(path string) (int, string) {
+	head, rest, _ := strings.Cut(path, ".")
+	switch head {
+	case "$":
+		return 0, rest
+	case "$$":
+		return 1, rest
+	}
+	return -1, path
+}
+
+// markCopies flags the each blocks whose items must be copied: those
+// holding a filtered value or a nested block's copies. Nodes whose scope
+// is deeper than their enclosing loops are marked invalid and left as
+// written.
+func markCopies(nodes []*filterNode, loops []*filterNode) {
+	for _, n := range nodes {
+		if n.each {
+			markCopies(n.children, append(loops, n))
+			if !n.copies {
+				continue
+			}
+		}
+		if n.scope >= len(loops) {
+			n.scope = -2
+			continue
+		}
+		if n.scope >= 0 {
+			loops[len(loops)-1-n.scope].copies = true
+		}
+	}
+}
+
+// rewriteFilterMarkers points the filtered slots and copied each blocks
+// of tmpl at their values.
+func rewriteFilterMarkers(tmpl string, nodes []*filterNode) string {
+	var flat []*filterNode
+	var collect func([]*filterNode)
+	collect = func(nodes []*filterNode) {
+		for _, n := range nodes {
+			if n.scope != -2 && (!n.each || n.copies) {
+				flat = append(flat, n)
+			}
+			collect(n.children)
+		}
+	}
+	collect(nodes)
+	slices.SortFunc(flat, func(a, b *filterNode) int { return a.start - b.start })
+
+	var b strings.Builder
+	last := 0
+	for _, n := range flat {
+		b.WriteString(tmpl[last:n.start])
+		prefix := [...]string{fmtDataKey + ".", "$.", "$$."}[n.scope+1]
+		if n.each {
+			fmt.Fprintf(&b, "<!--seam:each:%s%s-->", prefix, n.key)
+		} else {
+			fmt.Fprintf(&b, "<!--seam:%s%s%s-->", prefix, n.key, n.suffix)
+		}
+		last = n.end
+	}
+	b.WriteString(tmpl[last:])
+	return b.String()
+}
+
+// applySlotFilters rewrites the filtered markers of tmpl and adds their
+// formatted values to data.
+func (s *appState) applySlotFilters(route, tmpl string, data map[string]any, locale string) string {
+	nodes := parseSlotFilters(tmpl)
+	markCopies(nodes, nil)
+	values := make(map[string]any)
+	slots := make(map[string]any)
+	f := slotFormatter{s: s, route: route, locale: locale, data: data, decoded: make(map[string]any), values: values, slots: slots}
+	f.eval(nodes, nil)
+	if len(slots) > 0 {
+		values[fmtSlotsKey] = slots
+	}
+	data[fmtDataKey] = values
+	return rewriteFilterMarkers(tmpl, nodes)
+}
+
+type slotFormatter struct {
+	s       *appState
+	route   string
+	locale  string
+	data    map[string]any // page data as the loaders returned it
+	decoded map[string]any // JSON-decoded data keys, filled on first use
+	values  map[string]any
+	slots   map[string]any
+}
+
+// dataKey returns the JSON-decoded value of a top-level data key.
+func (f *slotFormatter) dataKey(key string) (any, bool) {
+	if v, ok := f.decoded[key]; ok {
+		return v, true
+	}
+	v, ok := f.data[key]
+	if !ok {
+		return nil, false
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, false
+	}
+	decoded, err := decodeNumbers(raw)
+	if err != nil {
+		return nil, false
+	}
+	f.decoded[key] = decoded
+	return decoded, true
+}
+
+// lookup resolves a root path like the engine: from the data, else
+// through the fields of top-level objects (first key in order wins).
+func (f *slotFormatter) lookup(path string) (any, bool) {
+	head, rest, _ := strings.Cut(path, ".")
+	if v, ok := f.dataKey(head); ok {
+		return lookupDataPath(v, rest)
+	}
+	for _, key := range slices.Sorted(maps.Keys(f.data)) {
+		if key == fmtDataKey {
+			continue
+		}
+		if obj, ok := f.dataKey(key); ok {
+			if obj, ok := obj.(map[string]any); ok {
+				if _, ok := obj[head]; ok {
+					return lookupDataPath(obj, path)
+				}
+			}
+		}
+	}
+	return nil, false
+}
+
+// eval computes the values of nodes; levels holds the item (copy) of each
+// enclosing loop, innermost last, nil for loops that are not copied.
+func (f *slotFormatter) eval(nodes []*filterNode, levels []map[string]any) {
+	for _, n := range nodes {
+		if n.scope == -2 {
+			continue
+		}
+		var target map[string]any
+		var value any
+		var found bool
+		if n.scope < 0 {
+			target = f.values
+			value, found = f.lookup(n.path)
+		} else if target = levels[len(levels)-1-n.scope]; target != nil {
+			value, found = lookupDataPath(target, n.path)
+		}
+
+		if !n.each {
+			if found && target != nil {
+				target[n.key] = f.format(value, n.filters)
+				if n.scope < 0 {
+					f.slots[n.expr] = target[n.key]
+				}
+			}
+			continue
+		}
+		items, _ := value.([]any)
+		if !n.copies || target == nil {
+			f.eval(n.children, append(levels, nil))
+			continue
+		}
+		copies := make([]any, len(items))
+		for i, item := range items {
+			m, ok := item.(map[string]any)
+			if ok {
+				m = maps.Clone(m)
+				copies[i] = m
+			} else {
+				copies[i] = item
+			}
+			f.eval(n.children, append(levels, m))
+		}
+		target[n.key] = copies
+	}
+}
+
+func (f *slotFormatter) format(value any, calls []slotFilterCall) any {
+	for _, c := range calls {
+		filter := f.s.opts.SlotFilters[c.name]
+		if filter == nil {
+			filter = builtinSlotFilters[c.name]
+		}
+		if filter == nil {
+			f.s.warnSlotFilter(f.route, fmt.Sprintf("unknown slot filter '%s'", c.name))
+			continue
+		}
+		out, err := filter(value, c.arg, f.locale)
+		if err != nil {
+			f.s.warnSlotFilter(f.route, fmt.Sprintf("slot filter '%s': %s", c.name, err))
+			continue
+		}
+		value = out
+	}
+	return value
+}
+
+func (s *appState) warnSlotFilter(route, msg string) {
+	if _, seen := s.filterWarned.LoadOrStore(route+"\x00"+msg, struct{}{}); !seen {
+		logf(slog.LevelWarn, "page %s: %s\n", route, msg)
+	}
+}
+
+func lookupDataPath(v any, path string) (any, bool) {
+	if path == "" {
+		return v, true
+	}
+	for _, seg := range strings.Split(path, ".") {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if v, ok = obj[seg]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// --- built-in filters ---
+
+// localeLanguage returns the lowercase language subtag and region of a
+// BCP 47 locale, defaulting to English.
+func localeLanguage(locale string) (lang, region string) {
+	lang, region, _ = strings.Cut(strings.ReplaceAll(locale, "_", "-"), "-")
+	if lang == "" {
+		return "en", ""
+	}
+	return strings.ToLower(lang), strings.ToUpper(region)
+}
+
+// datePatterns are the short, medium, and long patterns per language: d,
+// dd, M, MM, MMM, MMMM, and y are fields; text in single quotes is literal.
+var datePatterns = map[string][3]string{
+	"en-US": {"M/d/y", "MMM d, y", "MMMM d, y"},
+	"en":    {"dd/MM/y", "d MMM y", "d MMMM y"},
+	"de":    {"dd.MM.y", "d. MMM y", "d. MMMM y"},
+	"fr":    {"dd/MM/y", "d MMM y", "d MMMM y"},
+	"es":    {"d/M/y", "d MMM y", "d 'de' MMMM 'de' y"},
+	"it":    {"dd/MM/y", "d MMM y", "d MMMM y"},
+	"pt":    {"dd/MM/y", "d 'de' MMM 'de' y", "d 'de' MMMM 'de' y"},
+	"nl":    {"dd-MM-y", "d MMM y", "d MMMM y"},
+	"ja":    {"y/MM/dd", "y年M月d日", "y年M月d日"},
+	"zh":    {"y/M/d", "y年M月d日", "y年M月d日"},
+	"ko":    {"y. M. d.", "y년 M월 d일", "y년 M월 d일"},
+}
+
+// monthNames are the abbreviated then full month names per language.
+var monthNames = map[string][2][12]string{
+	"en": {
+		{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+		{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+	},
+	"de": {
+		{"Jan.", "Feb.", "März", "Apr.", "Mai", "Juni", "Juli", "Aug.", "Sept.", "Okt.", "Nov.", "Dez."},
+		{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+	},
+	"fr": {
+		{"janv.", "févr.", "mars", "avr.", "mai", "juin", "juil.", "août", "sept.", "oct.", "nov.", "déc."},
+		{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+	},
+	"es": {
+		{"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "sept", "oct", "nov", "dic"},
+		{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+	},
+	"it": {
+		{"gen", "feb", "mar", "apr", "mag", "giu", "lug", "ago", "set", "ott", "nov", "dic"},
+		{"gennaio", "febbraio", "marzo", "aprile", "maggio", "giugno", "luglio", "agosto", "settembre", "ottobre", "novembre", "dicembre"},
+	},
+	"pt": {
+		{"jan.", "fev.", "mar.", "abr.", "mai.", "jun.", "jul.", "ago.", "set.", "out.", "nov.", "dez."},
+		{"janeiro", "fevereiro", "março", "abril", "maio", "junho", "julho", "agosto", "setembro", "outubro", "novembro", "dezembro"},
+	},
+	"nl": {
+		{"jan", "feb", "mrt", "apr", "mei", "jun", "jul", "aug", "sep", "okt", "nov", "dec"},
+		{"januari", "februari", "maart", "april", "mei", "juni", "juli", "augustus", "september", "oktober", "november", "december"},
+	},
+}
+
+// dateFilter formats an RFC 3339 timestamp or YYYY-MM-DD date in its own
+// offset. arg is short, medium (default), long, or iso; languages without
+// patterns use English.
+func dateFilter(value any, arg, locale string) (any, error) {
+	str, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("date needs a string, got %T", value)
+	}
+	t, err := time.Parse(time.RFC3339Nano, str)
+	if err != nil {
+		if t, err = time.Parse(time.DateOnly, str); err != nil {
+			return nil, fmt.Errorf("date needs an RFC 3339 timestamp or YYYY-MM-DD date")
+		}
+	}
+	style := map[string]int{"short": 0, "": 1, "medium": 1, "long": 2}
+	if arg == "iso" {
+		return t.Format(time.DateOnly), nil
+	}
+	idx, ok := style[arg]
+	if !ok {
+		return nil, fmt.Errorf("unknown date style %q (short, medium, long, iso)", arg)
+	}
+	lang, region := localeLanguage(locale)
+	patterns, ok := datePatterns[lang]
+	if lang == "en" && (region == "" || region == "US") || !ok {
+		lang, patterns = "en", datePatterns["en-US"]
+	}
+	months, ok := monthNames[lang]
+	if !ok {
+		months = monthNames["en"]
+	}
+	return formatDatePattern(t, patterns[idx], months), nil
+}
+
+func formatDatePattern(t time.Time, pattern string, months [2][12]string) string {
+	var b strings.Builder
+	for i := 0; i < len(pattern); {
+		c := pattern[i]
+		if c == '\'' {
+			j := strings.IndexByte(pattern[i+1:], '\'')
+			if j < 0 {
+				j = len(pattern) - i - 1
+			}
+			b.WriteString(pattern[i+1 : i+1+j])
+			i += j + 2
+			continue
+		}
+		if c != 'd' && c != 'M' && c != 'y' {
+			_, size := utf8.DecodeRuneInString(pattern[i:])
+			b.WriteString(pattern[i : i+size])
+			i += size
+			continue
+		}
+		n := 1
+		for i+n < len(pattern) && pattern[i+n] == c {
+			n++
+		}
+		switch {
+		case c == 'y':
+			b.WriteString(strconv.Itoa(t.Year()))
+		case c == 'd' && n == 1:
+			b.WriteString(strconv.Itoa(t.Day()))
+		case c == 'd':
+			fmt.Fprintf(&b, "%02d", t.Day())
+		case n == 1:
+			b.WriteString(strconv.Itoa(int(t.Month())))
+		case n == 2:
+			fmt.Fprintf(&b, "%02d", int(t.Month()))
+		case n == 3:
+			b.WriteString(months[0][t.Month()-1])
+		default:
+			b.WriteString(months[1][t.Month()-1])
+		}
+		i += n
+	}
+	return b.String()
+}
+
+// numberSeparators are the group and decimal separators per language
+// (default: "," and ".").
+var numberSeparators = map[string][2]string{
+	"de": {".", ","}, "es": {".", ","}, "it": {".", ","}, "pt": {".", ","},
+	"nl": {".", ","}, "id": {".", ","}, "tr": {".", ","}, "da": {".", ","},
+	"fr": {" ", ","}, "ru": {" ", ","}, "pl": {" ", ","},
+	"cs": {" ", ","}, "sv": {" ", ","}, "fi": {" ", ","},
+	"nb": {" ", ","}, "uk": {" ", ","},
+}
+
+// numberFilter groups digits with the locale's separators. arg, when
+// set, is the number of decimals to round to.
+func numberFilter(value any, arg, locale string) (any, error) {
+	var digits string
+	switch v := value.(type) {
+	case json.Number:
+		digits = v.String()
+	case string:
+		digits = strings.TrimSpace(v)
+	case float64:
+		digits = strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		digits = strconv.Itoa(v)
+	default:
+		return nil, fmt.Errorf("number needs a number, got %T", value)
+	}
+	if arg != "" || strings.ContainsAny(digits, "eE") {
+		f, err := strconv.ParseFloat(digits, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", digits)
+		}
+		decimals := -1
+		if arg != "" {
+			if decimals, err = strconv.Atoi(arg); err != nil || decimals < 0 || decimals > 20 {
+				return nil, fmt.Errorf("decimals %q is not in 0..20", arg)
+			}
+		}
+		digits = strconv.FormatFloat(f, 'f', decimals, 64)
+	} else if _, err := strconv.ParseFloat(digits, 64); err != nil {
+		return nil, fmt.Errorf("%q is not a number", digits)
+	}
+
+	lang, _ := localeLanguage(locale)
+	seps, ok := numberSeparators[lang]
+	if !ok {
+		seps = [2]string{",", "."}
+	}
+	sign := ""
+	if strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		sign, digits = strings.TrimPrefix(digits[:1], "+"), digits[1:]
+	}
+	whole, frac, hasFrac := strings.Cut(digits, ".")
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(seps[0])
+		}
+		b.WriteRune(r)
+	}
+	if hasFrac {
+		b.WriteString(seps[1])
+		b.WriteString(frac)
+	}
+	return b.String(), nil
+}
+
+// truncateFilter shortens text to arg characters, ending in an ellipsis.
+func truncateFilter(value any, arg, _ string) (any, error) {
+	limit, err := strconv.Atoi(arg)
+	if err != nil || limit <= 0 {
+		return nil, fmt.Errorf("truncate needs a positive length, got %q", arg)
+	}
+	var text string
+	switch v := value.(type) {
+	case string:
+		text = v
+	case json.Number:
+		text = v.String()
+	case nil:
+		return "", nil
+	default:
+		return nil, fmt.Errorf("truncate needs a string, got %T", value)
+	}
+	if utf8.RuneCountInString(text) <= limit {
+		return text, nil
+	}
+	runes := []rune(text)
+	return strings.TrimRight(string(runes[:limit]), " \t\n") + "…", nil
+}
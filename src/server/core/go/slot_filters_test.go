@@ -0,0 +1,177 @@
+/* src/server/core/go/slot_filters_test.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type filterPost struct {
+	Title     string   `json:"title"`
+	CreatedAt string   `json:"created_at"`
+	Views     int      `json:"views"`
+	Bio       string   `json:"bio"`
+	Tags      []string `json:"tags"`
+}
+
+type filterFeed struct {
+	Featured filterPost   `json:"featured"`
+	Posts    []filterPost `json:"posts"`
+}
+
+func filterRouter(tmpl string) *Router {
+	empty := func(map[string]string) any { return struct{}{} }
+	return NewRouter().
+		Procedure(Query("getFeed", func(context.Context, struct{}) (filterFeed, error) {
+			first := filterPost{Title: "First", CreatedAt: "2024-03-05T10:00:00Z", Views: 1234567, Bio: "A rather long biography", Tags: []string{"go"}}
+			return filterFeed{Featured: first, Posts: []filterPost{
+				first,
+				{Title: "Second", CreatedAt: "2024-12-24", Views: 12, Bio: "Short"},
+			}}, nil
+		})).
+		Page(&PageDef{
+			Route:    "/feed",
+			Template: "<html><body>" + tmpl + "</body></html>",
+			Loaders:  []LoaderDef{{DataKey: "feed", Procedure: "getFeed", InputFn: empty}},
+		}).
+		I18nConfig(&I18nConfig{Locales: []string{"en", "de", "fr"}, Default: "en", Messages: map[string]map[string]json.RawMessage{}}).
+		ResolveStrategies(FromUrlQuery("lang"))
+}
+
+func renderFiltered(t *testing.T, tmpl, lang string, opts ...HandlerOptions) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	filterRouter(tmpl).Handler(opts...).ServeHTTP(w, httptest.NewRequest("GET", "/_seam/page/feed?lang="+lang, nil))
+	if w.Code != 200 {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+	return w.Body.String()
+}
+
+func TestSlotFiltersLocalized(t *testing.T) {
+	tmpl := `<p><!--seam:each:feed.posts--><i><!--seam:$.created_at|date:short--> <!--seam:$.views|number--></i><!--seam:endeach--></p>` +
+		`<b><!--seam:feed.featured.created_at|date:long--></b>`
+	for lang, want := range map[string][]string{
+		"en": {"<i>3/5/2024 1,234,567</i>", "<i>12/24/2024 12</i>", "<b>March 5, 2024</b>"},
+		"de": {"<i>05.03.2024 1.234.567</i>", "<i>24.12.2024 12</i>", "<b>5. März 2024</b>"},
+		"fr": {"<i>05/03/2024 1 234 567</i>", "<b>5 mars 2024</b>"},
+	} {
+		body := renderFiltered(t, tmpl, lang)
+		for _, w := range want {
+			if !strings.Contains(body, w) {
+				t.Errorf("%s: missing %s in %s", lang, w, body)
+			}
+		}
+	}
+}
+
+func TestSlotFiltersReachTheClient(t *testing.T) {
+	tmpl := `<b><!--seam:feed.featured.created_at|date:long--></b><i><!--seam:featured.views|number--></i>`
+	body := renderFiltered(t, tmpl, "en")
+	start, end, _, ok := findDataScript(body, "__data")
+	if !ok {
+		t.Fatalf("no data script: %s", body)
+	}
+	var data struct {
+		Fmt struct {
+			Slots map[string]string `json:"slots"`
+		} `json:"_fmt"`
+	}
+	script := body[start:end]
+	script = script[strings.Index(script, ">")+1 : strings.LastIndex(script, "</script>")]
+	if err := json.Unmarshal([]byte(script), &data); err != nil {
+		t.Fatalf("%v: %s", err, script)
+	}
+	if data.Fmt.Slots["feed.featured.created_at|date:long"] != "March 5, 2024" || data.Fmt.Slots["featured.views|number"] != "1,234,567" {
+		t.Errorf("formatted slots %v", data.Fmt.Slots)
+	}
+}
+
+func TestSlotFiltersDecodeReferencedKeysOnly(t *testing.T) {
+	s := &appState{opts: HandlerOptions{}}
+	data := map[string]any{
+		"post":   filterPost{Views: 42},
+		"stream": make(chan int), // not JSON-encodable; must stay untouched
+	}
+	tmpl := s.applySlotFilters("/p", `<!--seam:post.views|number-->`, data, "en")
+	values, _ := data[fmtDataKey].(map[string]any)
+	if tmpl != `<!--seam:_fmt._f0-->` || values == nil || values["_f0"] != "42" {
+		t.Fatalf("template %q, values %v", tmpl, values)
+	}
+}
+
+func TestSlotFiltersNestedAndAttr(t *testing.T) {
+	tmpl := `<!--seam:each:feed.posts--><!--seam:$.bio|truncate:8:attr:title--><a>` +
+		`<!--seam:each:$.tags--><!--seam:$$.title|truncate:3-->/<!--seam:$-->;<!--seam:endeach--></a><!--seam:endeach-->`
+	body := renderFiltered(t, tmpl, "en")
+	for _, want := range []string{`title="A rather…"`, `Fir…/go;`, `title="Short"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %s in %s", want, body)
+		}
+	}
+}
+
+func TestSlotFiltersCustomAndUnknown(t *testing.T) {
+	shout := func(value any, arg, locale string) (any, error) {
+		s, ok := value.(string)
+		if !ok {
+			return nil, errors.New("not a string")
+		}
+		return strings.ToUpper(s) + arg + "@" + locale, nil
+	}
+	tmpl := `<p><!--seam:feed.featured.title|shout:!--></p><em><!--seam:featured.title|nope--></em><s><!--seam:featured.views|shout--></s>`
+	body := renderFiltered(t, tmpl, "de", HandlerOptions{SlotFilters: map[string]SlotFilter{"shout": shout}})
+	for _, want := range []string{"<p>FIRST!@de</p>", "<em>First</em>", "<s>1234567</s>"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %s in %s", want, body)
+		}
+	}
+}
+
+func TestBuiltinSlotFilters(t *testing.T) {
+	cases := []struct {
+		filter           SlotFilter
+		value            any
+		arg, locale, out string
+	}{
+		{dateFilter, "2024-01-09T23:30:00-05:00", "", "", "Jan 9, 2024"},
+		{dateFilter, "2024-01-09", "iso", "de", "2024-01-09"},
+		{dateFilter, "2024-01-09", "medium", "en-GB", "9 Jan 2024"},
+		{dateFilter, "2024-01-09", "long", "es", "9 de enero de 2024"},
+		{dateFilter, "2024-01-09", "long", "ja", "2024年1月9日"},
+		{dateFilter, "2024-01-09", "short", "xx", "1/9/2024"},
+		{numberFilter, json.Number("-1234.5"), "2", "de", "-1.234,50"},
+		{numberFilter, json.Number("999"), "", "en", "999"},
+		{numberFilter, "1e6", "", "en", "1,000,000"},
+		{truncateFilter, "héllo wörld", "6", "", "héllo…"},
+		{truncateFilter, "short", "10", "", "short"},
+	}
+	for _, c := range cases {
+		out, err := c.filter(c.value, c.arg, c.locale)
+		if err != nil || out != c.out {
+			t.Errorf("%v|%s (%s) = %v, %v; want %s", c.value, c.arg, c.locale, out, err, c.out)
+		}
+	}
+	for _, bad := range []func() (any, error){
+		func() (any, error) { return dateFilter("yesterday", "", "") },
+		func() (any, error) { return dateFilter("2024-01-09", "full", "") },
+		func() (any, error) { return numberFilter("abc", "", "") },
+		func() (any, error) { return truncateFilter("abc", "x", "") },
+	} {
+		if out, err := bad(); err == nil {
+			t.Errorf("expected error, got %v", out)
+		}
+	}
+}
+
+func TestSlotPathsIgnoreFilters(t *testing.T) {
+	paths := slotPaths(`<!--seam:post.created_at|date:short--><!--seam:bio|truncate:9:html-->`)
+	if strings.Join(paths, ",") != "post.created_at,bio" {
+		t.Errorf("paths %v", paths)
+	}
+}
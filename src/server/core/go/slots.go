@@ -0,0 +1,217 @@
+/* src/server/core/go/slots.go */
+
+package seam
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+
+	engine "github.com/canmi21/seam/src/server/engine/go"
+)
+
+// Slot markers that are not data paths (asset slots, layout outlet) and
+// data keys the server injects besides loader results.
+var (
+	reservedSlots    = map[string]bool{"page-styles": true, "page-scripts": true, "prefetch": true, "outlet": true}
+	reservedDataKeys = map[string]bool{"_dir": true, "_env": true, "_error": true, "_fmt": true, "_flags": true, "_form": true, "_i18n": true, "_layouts": true, "_scripts": true}
+)
+
+// slotPaths returns the data paths referenced by <!--seam:...--> markers in
+// a template, excluding block closers, reserved slots, and loop-scoped
+// paths ($, $$).
+func slotPaths(tmpl string) []string {
+	var paths []string
+	for {
+		start := strings.Index(tmpl, "<!--seam:")
+		if start < 0 {
+			return paths
+		}
+		tmpl = tmpl[start+len("<!--seam:"):]
+		end := strings.Index(tmpl, "-->")
+		if end < 0 {
+			return paths
+		}
+		directive := tmpl[:end]
+		tmpl = tmpl[end+len("-->"):]
+
+		if directive == "else" || directive == "endmatch" || directive == "endeach" ||
+			strings.HasPrefix(directive, "endif:") || strings.HasPrefix(directive, "when:") || reservedSlots[directive] {
+			continue
+		}
+		for _, prefix := range []string{"if:", "each:", "match:"} {
+			directive = strings.TrimPrefix(directive, prefix)
+		}
+		if i := strings.Index(directive, ":style:"); i >= 0 {
+			directive = directive[:i]
+		} else if i := strings.Index(directive, ":attr:"); i >= 0 {
+			directive = directive[:i]
+		}
+		directive = strings.TrimSuffix(directive, ":html")
+		directive, _, _ = strings.Cut(directive, "|") // slot filters
+		if directive == "" || strings.HasPrefix(directive, "$") {
+			continue
+		}
+		paths = append(paths, directive)
+	}
+}
+
+// checkNamespacedSlots panics when a page opted into namespaced slots
+// references a path whose first segment is not a loader data key: such a
+// slot would otherwise render empty, since namespaced pages skip flattening
+// of nested loader objects. Lazily loaded templates are not in memory at
+// handler build; they are checked on first render (slotWarnings.namespaced).
+func checkNamespacedSlots(page *PageDef) {
+	templates := []string{page.Template, page.HeadMeta}
+	for _, t := range page.LocaleTemplates {
+		templates = append(templates, t)
+	}
+	for _, b := range page.ErrorBoundaries {
+		templates = append(templates, b.Template)
+	}
+	if bad := nonLoaderSlots(page, templates...); len(bad) > 0 {
+		panic(namespacedSlotsMessage(page.Route, bad))
+	}
+}
+
+// nonLoaderSlots returns the sorted slot paths in templates whose first
+// segment is neither a loader data key nor a reserved data key.
+func nonLoaderSlots(page *PageDef, templates ...string) []string {
+	keys := make(map[string]bool, len(page.Loaders))
+	for _, ld := range page.Loaders {
+		keys[ld.DataKey] = true
+	}
+	var bad []string
+	seen := make(map[string]bool)
+	for _, t := range templates {
+		for _, path := range slotPaths(t) {
+			head, _, _ := strings.Cut(path, ".")
+			if !keys[head] && !reservedDataKeys[head] && !seen[path] {
+				seen[path] = true
+				bad = append(bad, path)
+			}
+		}
+	}
+	sort.Strings(bad)
+	return bad
+}
+
+func namespacedSlotsMessage(route string, bad []string) string {
+	return fmt.Sprintf("page %s uses namespaced slots but references non-loader paths: %s (write them as <loader>.<field>)",
+		route, strings.Join(bad, ", "))
+}
+
+// injectNamespaced resolves the data slots of tmpl against the keyed loader
+// data as-is, so a namespaced page never sees nested loader fields spread to
+// the top level by the engine's flattening pass. Asset slots and the outlet
+// are masked during injection and restored for the engine's full render.
+func injectNamespaced(tmpl, dataJSON string) (string, error) {
+	if !strings.Contains(tmpl, "<!--seam:") {
+		return tmpl, nil
+	}
+	for name := range reservedSlots {
+		tmpl = strings.ReplaceAll(tmpl, "<!--seam:"+name+"-->", "<!--seam-reserved:"+name+"-->")
+	}
+	out, err := engine.InjectNoScript(tmpl, dataJSON)
+	if err != nil {
+		return "", err
+	}
+	for name := range reservedSlots {
+		out = strings.ReplaceAll(out, "<!--seam-reserved:"+name+"-->", "<!--seam:"+name+"-->")
+	}
+	return out, nil
+}
+
+// slotCollisions reports top-level slot names that flattening resolves
+// ambiguously: the name is a field of several loader objects, or a field
+// shadowed by a loader key. Only names referenced by bare slots in the
+// template are reported. Result maps slot name -> loader keys, sorted.
+func slotCollisions(bare map[string]bool, data map[string]any) map[string][]string {
+	providers := make(map[string][]string)
+	for key, value := range data {
+		nested, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+		for field := range nested {
+			if bare[field] {
+				providers[field] = append(providers[field], key)
+			}
+		}
+	}
+	var collisions map[string][]string
+	for field, keys := range providers {
+		if _, shadowed := data[field]; shadowed {
+			keys = append(keys, field)
+		}
+		if len(keys) < 2 {
+			continue
+		}
+		sort.Strings(keys)
+		if collisions == nil {
+			collisions = make(map[string][]string)
+		}
+		collisions[field] = keys
+	}
+	return collisions
+}
+
+// slotWarnings logs each collision once per route and slot name.
+type slotWarnings struct {
+	bare    sync.Map // route + "\x00" + locale -> map[string]bool (bare slot names)
+	warned  sync.Map // route + "\x00" + slot -> struct{}
+	checked sync.Map // route + "\x00" + locale -> namespacedCheck
+}
+
+// namespacedCheck is the checkNamespacedSlots result for one lazily loaded
+// template; tmpl is kept so a re-read template is checked again.
+type namespacedCheck struct {
+	tmpl string
+	bad  []string
+}
+
+// namespaced validates a lazily loaded template of a namespaced page on
+// first render, logging violations once per template, and returns the
+// offending slot paths.
+func (sw *slotWarnings) namespaced(page *PageDef, locale, tmpl string) []string {
+	key := page.Route + "\x00" + locale
+	if v, ok := sw.checked.Load(key); ok && v.(namespacedCheck).tmpl == tmpl {
+		return v.(namespacedCheck).bad
+	}
+	bad := nonLoaderSlots(page, tmpl)
+	if len(bad) > 0 {
+		logf(slog.LevelError, "%s\n", namespacedSlotsMessage(page.Route, bad))
+	}
+	sw.checked.Store(key, namespacedCheck{tmpl: tmpl, bad: bad})
+	return bad
+}
+
+func (sw *slotWarnings) bareSlots(key, tmpl string) map[string]bool {
+	if v, ok := sw.bare.Load(key); ok {
+		return v.(map[string]bool)
+	}
+	bare := make(map[string]bool)
+	for _, path := range slotPaths(tmpl) {
+		if !strings.Contains(path, ".") {
+			bare[path] = true
+		}
+	}
+	sw.bare.Store(key, bare)
+	return bare
+}
+
+func (sw *slotWarnings) check(route, locale, tmpl string, data map[string]any) {
+	bare := sw.bareSlots(route+"\x00"+locale, tmpl)
+	if len(bare) == 0 {
+		return
+	}
+	for slot, keys := range slotCollisions(bare, data) {
+		if _, dup := sw.warned.LoadOrStore(route+"\x00"+slot, struct{}{}); dup {
+			continue
+		}
+		logf(slog.LevelWarn, "page %s: slot %q is ambiguous across loaders %s; use <!--seam:<loader>.%s-->\n",
+			route, slot, strings.Join(keys, ", "), slot)
+	}
+}
@@ -0,0 +1,99 @@
+/* src/server/core/go/slots_test.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSlotPaths(t *testing.T) {
+	tmpl := `<head><!--seam:page-styles--></head><h1><!--seam:title--></h1>` +
+		`<!--seam:if:user.admin--><b>admin</b><!--seam:else--><!--seam:endif:user.admin-->` +
+		`<!--seam:each:items--><li><!--seam:$.name--></li><!--seam:endeach-->` +
+		`<a><!--seam:link.url:attr:href--></a><div><!--seam:body:html--></div><!--seam:outlet-->`
+	want := []string{"title", "user.admin", "items", "link.url", "body"}
+	if got := slotPaths(tmpl); !reflect.DeepEqual(got, want) {
+		t.Errorf("slotPaths = %v, want %v", got, want)
+	}
+}
+
+func TestSlotCollisions(t *testing.T) {
+	data := map[string]any{
+		"user":  map[string]any{"name": "alice", "id": 1},
+		"team":  map[string]any{"name": "core"},
+		"title": "Home",
+		"page":  map[string]any{"title": "nested"},
+	}
+	bare := map[string]bool{"name": true, "title": true, "id": true}
+	got := slotCollisions(bare, data)
+	want := map[string][]string{"name": {"team", "user"}, "title": {"page", "title"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("slotCollisions = %v, want %v", got, want)
+	}
+}
+
+func TestCheckNamespacedSlots(t *testing.T) {
+	page := &PageDef{
+		Route:    "/profile",
+		Template: `<p><!--seam:user.name--></p><!--seam:if:_flags.beta-->beta<!--seam:endif:_flags.beta-->`,
+		Loaders:  []LoaderDef{{DataKey: "user"}},
+	}
+	checkNamespacedSlots(page) // valid: no panic
+
+	page.Template += `<p><!--seam:name--></p>`
+	defer func() {
+		r := recover()
+		if r == nil || !strings.Contains(r.(string), "name") {
+			t.Fatalf("expected panic naming the bare slot, got %v", r)
+		}
+	}()
+	checkNamespacedSlots(page)
+}
+
+func TestNamespacedSlotsOptionPanicsAtBuild(t *testing.T) {
+	router := NewRouter().Page(&PageDef{Route: "/", Template: "<p><!--seam:name--></p>"})
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic")
+		}
+	}()
+	router.Handler(HandlerOptions{NamespacedSlots: true})
+}
+
+func TestInjectNamespacedSkipsFlattening(t *testing.T) {
+	tmpl := `<head><!--seam:page-styles--></head><p><!--seam:user.name--></p><i><!--seam:name--></i><!--seam:outlet-->`
+	got, err := injectNamespaced(tmpl, `{"user":{"name":"alice"}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `<head><!--seam:page-styles--></head><p>alice</p><i></i><!--seam:outlet-->`
+	if got != want {
+		t.Errorf("injectNamespaced = %s, want %s", got, want)
+	}
+}
+
+func TestNamespacedPageRendersKeyedData(t *testing.T) {
+	h := NewRouter().
+		Procedure(&ProcedureDef{Name: "getUser", Handler: func(ctx context.Context, input json.RawMessage) (any, error) {
+			return map[string]any{"name": "alice"}, nil
+		}}).
+		Page(&PageDef{
+			Route:           "/",
+			Template:        `<html><head><meta charset="utf-8"></head><body><p><!--seam:user.name--></p></body></html>`,
+			HeadMeta:        `<title><!--seam:user.name--></title>`,
+			Loaders:         []LoaderDef{{DataKey: "user", Procedure: "getUser", InputFn: func(map[string]string) any { return nil }}},
+			NamespacedSlots: true,
+		}).
+		Handler(HandlerOptions{})
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/_seam/page/", nil))
+	body := w.Body.String()
+	if !strings.Contains(body, "<p>alice</p>") || !strings.Contains(body, "<title>alice</title>") || !strings.Contains(body, `"user":{"name":"alice"}`) {
+		t.Errorf("unexpected page: %s", body)
+	}
+}
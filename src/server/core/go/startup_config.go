@@ -0,0 +1,169 @@
+/* src/server/core/go/startup_config.go */
+
+package seam
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// StartupConfig is a machine-readable summary of the active configuration,
+// for logging once at startup so misconfiguration (no pages, missing
+// locales, obfuscation off in production) is visible in the first line
+// of the log.
+type StartupConfig struct {
+	Event       string             `json:"event"` // always "seam.startup"
+	Environment string             `json:"environment"`
+	Routes      []string           `json:"routes"`
+	Procedures  map[string]int     `json:"procedures"` // count per kind, plus "subscription"
+	Channels    int                `json:"channels"`
+	Validation  StartupValidation  `json:"validation"`
+	I18n        *StartupI18n       `json:"i18n,omitempty"`
+	Obfuscation StartupObfuscation `json:"obfuscation"`
+	Cache       StartupCache       `json:"cache"`
+	Timeouts    StartupTimeouts    `json:"timeouts"`
+}
+
+// StartupValidation reports the router's validation mode and whether it
+// validates in this environment.
+type StartupValidation struct {
+	Mode   ValidationMode `json:"mode"`
+	Active bool           `json:"active"`
+}
+
+// StartupI18n summarizes the loaded i18n configuration.
+type StartupI18n struct {
+	Locales      []string `json:"locales"`
+	Default      string   `json:"default"`
+	Mode         string   `json:"mode"`
+	ContentHash  bool     `json:"contentHash"`
+	PseudoLocale string   `json:"pseudoLocale,omitempty"`
+}
+
+// StartupObfuscation reports whether procedure names are hashed on the wire.
+type StartupObfuscation struct {
+	Enabled    bool `json:"enabled"`
+	Procedures int  `json:"procedures"` // names in the RPC hash map
+}
+
+// StartupCache summarizes the caching features in effect.
+type StartupCache struct {
+	LayoutTTLMs      int64 `json:"layoutTtlMs"`
+	TemplateCacheMB  int64 `json:"templateCacheMb"` // 0 = templates loaded eagerly
+	CachedProcedures int   `json:"cachedProcedures"`
+	CoalescedPages   int   `json:"coalescedPages"`
+	PrerenderedPages int   `json:"prerenderedPages"`
+}
+
+// StartupTimeouts lists the handler timeouts in milliseconds (0 = disabled).
+type StartupTimeouts struct {
+	RPCMs       int64 `json:"rpcMs"`
+	PageMs      int64 `json:"pageMs"`
+	SSEIdleMs   int64 `json:"sseIdleMs"`
+	HeartbeatMs int64 `json:"heartbeatMs"`
+	PongMs      int64 `json:"pongMs"`
+	ReconnectMs int64 `json:"reconnectMs"`
+}
+
+// StartupConfig summarizes the router as Handler(opts...) would serve it.
+// Once a handler is built, the summary reports the options it was built
+// with: call it without options after Handler. Options that disagree with
+// the built handler's panic, since the log would describe a server that
+// is not running.
+func (r *Router) StartupConfig(opts ...HandlerOptions) StartupConfig {
+	o := r.handlerOptions(opts)
+	if r.served != nil {
+		if len(opts) > 0 && startupSettings(o) != startupSettings(*r.served) {
+			panic(fmt.Sprintf("StartupConfig options %+v disagree with the built handler's %+v", startupSettings(o), startupSettings(*r.served)))
+		}
+		o = *r.served
+	}
+	settings := startupSettings(o)
+	cfg := StartupConfig{
+		Event:       "seam.startup",
+		Environment: "development",
+		Routes:      []string{},
+		Procedures:  map[string]int{},
+		Channels:    len(r.channels),
+		Validation:  StartupValidation{Mode: r.validationMode, Active: shouldValidateMode(r.validationMode)},
+		Cache:       StartupCache{LayoutTTLMs: settings.LayoutTTLMs},
+		Timeouts:    settings.Timeouts,
+	}
+	if isProduction() {
+		cfg.Environment = "production"
+	}
+	if cfg.Validation.Mode == "" {
+		cfg.Validation.Mode = ValidationModeDev
+	}
+
+	for _, p := range r.Procedures() {
+		cfg.Procedures[p.Kind]++
+		if _, ok := cacheTTL(&ProcedureDef{Type: p.Kind, Cache: p.Cache}); ok {
+			cfg.Cache.CachedProcedures++
+		}
+	}
+	if n := len(r.Subscriptions()); n > 0 {
+		cfg.Procedures["subscription"] = n
+	}
+
+	caches := map[*TemplateCache]bool{}
+	for _, p := range applyRouteGroups(r.pages, r.groups) {
+		cfg.Routes = append(cfg.Routes, p.Route)
+		if p.Coalesce {
+			cfg.Cache.CoalescedPages++
+		}
+		if p.Prerender {
+			cfg.Cache.PrerenderedPages++
+		}
+		if p.lazy != nil && p.lazy.cache != nil && !caches[p.lazy.cache] {
+			caches[p.lazy.cache] = true
+			cfg.Cache.TemplateCacheMB += p.lazy.cache.maxBytes >> 20
+		}
+	}
+	sort.Strings(cfg.Routes)
+
+	if c := r.i18nConfig; c != nil {
+		cfg.I18n = &StartupI18n{
+			Locales:      cloneStrings(c.Locales),
+			Default:      c.Default,
+			Mode:         c.Mode,
+			ContentHash:  c.Cache,
+			PseudoLocale: settings.PseudoLocale,
+		}
+	}
+	if m := r.rpcHashMap; m != nil {
+		cfg.Obfuscation = StartupObfuscation{Enabled: true, Procedures: len(m.Procedures)}
+	}
+	return cfg
+}
+
+// startupOptions are the HandlerOptions settings StartupConfig reports.
+type startupOptions struct {
+	Timeouts     StartupTimeouts
+	LayoutTTLMs  int64
+	PseudoLocale string
+}
+
+func startupSettings(o HandlerOptions) startupOptions {
+	return startupOptions{
+		Timeouts: StartupTimeouts{
+			RPCMs:       o.RPCTimeout.Milliseconds(),
+			PageMs:      o.PageTimeout.Milliseconds(),
+			SSEIdleMs:   o.SSEIdleTimeout.Milliseconds(),
+			HeartbeatMs: o.HeartbeatInterval.Milliseconds(),
+			PongMs:      o.PongTimeout.Milliseconds(),
+			ReconnectMs: o.ReconnectDelay.Milliseconds(),
+		},
+		LayoutTTLMs:  o.LayoutCacheTTL.Milliseconds(),
+		PseudoLocale: o.PseudoLocale,
+	}
+}
+
+// LogStartupConfig writes StartupConfig(opts...) to w as one JSON line,
+// e.g. LogStartupConfig(os.Stderr) after Handler, right before
+// ListenAndServe.
+func (r *Router) LogStartupConfig(w io.Writer, opts ...HandlerOptions) error {
+	return json.NewEncoder(w).Encode(r.StartupConfig(opts...))
+}
@@ -0,0 +1,90 @@
+/* src/server/core/go/startup_config_test.go */
+
+package seam
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStartupConfig(t *testing.T) {
+	t.Setenv("SEAM_ENV", "production")
+	noop := func(context.Context, json.RawMessage) (any, error) { return nil, nil }
+	r := NewRouter().
+		Procedure(&ProcedureDef{Name: "getUser", Handler: noop, Cache: map[string]any{"ttl": 60}}).
+		Procedure(&ProcedureDef{Name: "saveUser", Type: "command", Handler: noop}).
+		Subscription(&SubscriptionDef{Name: "onCount"}).
+		Page(&PageDef{Route: "/users/:id", Coalesce: true}).
+		Page(&PageDef{Route: "/", Prerender: true}).
+		RpcHashMap(&RpcHashMap{Procedures: map[string]string{"getUser": "a1", "saveUser": "b2"}}).
+		I18nConfig(&I18nConfig{Locales: []string{"en", "zh"}, Default: "en", Mode: "memory"})
+
+	cfg := r.StartupConfig(HandlerOptions{RPCTimeout: 5 * time.Second, LayoutCacheTTL: time.Minute, PseudoLocale: "en-XA"})
+	if cfg.Environment != "production" || cfg.Validation != (StartupValidation{Mode: ValidationModeDev, Active: false}) {
+		t.Errorf("environment %q validation %+v", cfg.Environment, cfg.Validation)
+	}
+	if !reflect.DeepEqual(cfg.Routes, []string{"/", "/users/:id"}) {
+		t.Errorf("routes %v", cfg.Routes)
+	}
+	if !reflect.DeepEqual(cfg.Procedures, map[string]int{"query": 1, "command": 1, "subscription": 1}) {
+		t.Errorf("procedures %v", cfg.Procedures)
+	}
+	if cfg.I18n == nil || cfg.I18n.Default != "en" || cfg.I18n.PseudoLocale != "en-XA" || len(cfg.I18n.Locales) != 2 {
+		t.Errorf("i18n %+v", cfg.I18n)
+	}
+	if cfg.Obfuscation != (StartupObfuscation{Enabled: true, Procedures: 2}) {
+		t.Errorf("obfuscation %+v", cfg.Obfuscation)
+	}
+	wantCache := StartupCache{LayoutTTLMs: 60000, CachedProcedures: 1, CoalescedPages: 1, PrerenderedPages: 1}
+	if cfg.Cache != wantCache {
+		t.Errorf("cache %+v", cfg.Cache)
+	}
+	// explicit options are taken as given, except the heartbeat defaults
+	if cfg.Timeouts.RPCMs != 5000 || cfg.Timeouts.PageMs != 0 || cfg.Timeouts.HeartbeatMs != 8000 {
+		t.Errorf("timeouts %+v", cfg.Timeouts)
+	}
+}
+
+func TestLogStartupConfig(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewRouter().LogStartupConfig(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Count(buf.Bytes(), []byte("\n")) != 1 {
+		t.Fatalf("want one line, got %q", buf.String())
+	}
+	var out map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out["event"] != "seam.startup" || out["i18n"] != nil {
+		t.Errorf("summary %v", out)
+	}
+	if timeouts := out["timeouts"].(map[string]any); timeouts["rpcMs"] != float64(30000) {
+		t.Errorf("default timeouts %v", timeouts)
+	}
+	if obf := out["obfuscation"].(map[string]any); obf["enabled"] != false {
+		t.Errorf("obfuscation %v", obf)
+	}
+}
+
+func TestStartupConfigReportsBuiltHandler(t *testing.T) {
+	r := NewRouter()
+	_ = r.Handler(HandlerOptions{RPCTimeout: 2 * time.Second, PseudoLocale: "en-XA"})
+	cfg := r.StartupConfig()
+	if cfg.Timeouts.RPCMs != 2000 || cfg.Timeouts.HeartbeatMs != 8000 {
+		t.Errorf("timeouts %+v", cfg.Timeouts)
+	}
+	// agreeing options are accepted
+	_ = r.StartupConfig(HandlerOptions{RPCTimeout: 2 * time.Second, PseudoLocale: "en-XA"})
+	defer func() {
+		if recover() == nil {
+			t.Fatal("want panic for options that disagree with the built handler")
+		}
+	}()
+	_ = r.StartupConfig(HandlerOptions{RPCTimeout: 5 * time.Second})
+}
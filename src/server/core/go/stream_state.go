@@ -0,0 +1,292 @@
+/* src/server/core/go/stream_state.go */
+
+package seam
+
+import (
+	"container/list"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StreamState is the resumable state of one SSE subscription stream.
+type StreamState struct {
+	Subscription string         `json:"subscription"`
+	Principal    string         `json:"principal,omitempty"`
+	Seq          int            `json:"seq"`                 // id of the last data event written
+	Cursors      []StreamCursor `json:"cursors,omitempty"`   // recent event cursors, oldest first
+	Truncated    bool           `json:"truncated,omitempty"` // older cursors fell out of the window
+	Reconnects   int            `json:"reconnects"`
+	UpdatedAt    time.Time      `json:"updatedAt"`
+}
+
+// StreamCursor pairs an event's sequence number with its handler cursor
+// (SubscriptionEvent.Cursor).
+type StreamCursor struct {
+	Seq    int    `json:"seq"`
+	Cursor string `json:"cursor"`
+}
+
+// StreamStateStore persists SSE stream state between connections. With
+// HandlerOptions.StreamState set, event IDs become "<stream>.<seq>" and a
+// client reconnecting with Last-Event-ID resumes its stream on any
+// replica sharing the store: sequence numbers continue, and the cursor of
+// the last event it received is returned by LastEventID to the handler.
+// A client resuming from before the cursor window (the last
+// streamCursorWindow cursors) gets a CURSOR_EXPIRED error (410) instead
+// and must start a new stream without Last-Event-ID. State is saved after every data event and deleted when the stream
+// completes; streams cut by disconnects or shutdown expire with the
+// store's TTL.
+type StreamStateStore interface {
+	Load(ctx context.Context, id string) (state StreamState, ok bool, err error)
+	Save(ctx context.Context, id string, state StreamState) error
+	Delete(ctx context.Context, id string) error
+}
+
+// streamCursorWindow bounds StreamState.Cursors.
+const streamCursorWindow = 32
+
+// streamResume tracks one SSE stream's state in the store; nil when no
+// store is configured.
+type streamResume struct {
+	store StreamStateStore
+	id    string
+	state StreamState
+}
+
+// CursorExpiredError is returned to a client resuming a stream from an
+// event whose cursor is no longer kept.
+func CursorExpiredError() *Error {
+	return NewError("CURSOR_EXPIRED", "Stream cursor expired; reconnect without Last-Event-ID", http.StatusGone)
+}
+
+// resumeStream starts or resumes the stream state of a subscription
+// request. It returns the first sequence number to use and the value
+// LastEventID reports to the handler: the resumed cursor, else the raw
+// Last-Event-ID header.
+func (s *appState) resumeStream(ctx context.Context, r *http.Request, sub *SubscriptionDef) (resume *streamResume, seq int, lastID string, seamErr *Error) {
+	header := r.Header.Get("Last-Event-ID")
+	store := s.opts.StreamState
+	if store == nil {
+		return nil, 0, header, nil
+	}
+	resume = &streamResume{store: store, state: StreamState{Subscription: sub.Name, Principal: PrincipalOf(ctx), Seq: -1}}
+
+	if id, seqText, ok := strings.Cut(header, "."); ok {
+		clientSeq, err := strconv.Atoi(seqText)
+		state, found, loadErr := store.Load(ctx, id)
+		if loadErr != nil {
+			logf(slog.LevelWarn, "stream state: load %s: %v\n", id, loadErr)
+		}
+		if err == nil && found && state.Subscription == sub.Name && state.Principal == resume.state.Principal {
+			// Cursors are ordered by seq: the last one at or before clientSeq
+			i := sort.Search(len(state.Cursors), func(i int) bool { return state.Cursors[i].Seq > clientSeq })
+			if i == 0 && state.Truncated {
+				return nil, 0, "", CursorExpiredError()
+			}
+			if i > 0 {
+				lastID = state.Cursors[i-1].Cursor
+			}
+			resume.id, resume.state = id, state
+			resume.state.Seq = clientSeq
+			resume.state.Reconnects++
+			resume.state.UpdatedAt = time.Now()
+			if err := store.Save(ctx, id, resume.state); err != nil {
+				logf(slog.LevelError, "stream state: save %s: %v\n", id, err)
+			}
+			return resume, clientSeq + 1, lastID, nil
+		}
+	}
+
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	resume.id = hex.EncodeToString(b[:])
+	return resume, 0, header, nil
+}
+
+// eventID returns the SSE id of a data event, recording it in the store.
+func (sr *streamResume) eventID(ctx context.Context, ev SubscriptionEvent, seq int) string {
+	if sr == nil {
+		return strconv.Itoa(seq)
+	}
+	if ev.Err != nil {
+		return "" // error events carry no id
+	}
+	sr.state.Seq = seq
+	if ev.Cursor != "" {
+		sr.state.Cursors = append(sr.state.Cursors, StreamCursor{Seq: seq, Cursor: ev.Cursor})
+		if n := len(sr.state.Cursors); n > streamCursorWindow {
+			sr.state.Cursors = append([]StreamCursor(nil), sr.state.Cursors[n-streamCursorWindow:]...)
+			sr.state.Truncated = true
+		}
+	}
+	sr.state.UpdatedAt = time.Now()
+	if err := sr.store.Save(ctx, sr.id, sr.state); err != nil {
+		logf(slog.LevelError, "stream state: save %s: %v\n", sr.id, err)
+	}
+	return sr.id + "." + strconv.Itoa(seq)
+}
+
+// complete drops the state of a stream that ended normally.
+func (sr *streamResume) complete(ctx context.Context) {
+	if sr == nil {
+		return
+	}
+	if err := sr.store.Delete(ctx, sr.id); err != nil {
+		logf(slog.LevelError, "stream state: delete %s: %v\n", sr.id, err)
+	}
+}
+
+type memoryStreamState struct {
+	ttl time.Duration
+
+	mu     sync.Mutex
+	order  *list.List // by last save, oldest first
+	states map[string]*list.Element
+}
+
+type memoryStreamEntry struct {
+	id    string
+	saved time.Time
+	state StreamState
+}
+
+// MemoryStreamState returns an in-process store whose entries expire ttl
+// after their last update (default 5 minutes). It only resumes streams on
+// the same replica; use RedisStreamState to resume across replicas.
+func MemoryStreamState(ttl time.Duration) StreamStateStore {
+	if ttl <= 0 {
+		ttl = 5 * time.Minute
+	}
+	return &memoryStreamState{ttl: ttl, order: list.New(), states: make(map[string]*list.Element)}
+}
+
+func (m *memoryStreamState) Load(_ context.Context, id string) (StreamState, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	el, ok := m.states[id]
+	if !ok {
+		return StreamState{}, false, nil
+	}
+	entry := el.Value.(*memoryStreamEntry)
+	if time.Since(entry.saved) > m.ttl {
+		m.order.Remove(el)
+		delete(m.states, id)
+		return StreamState{}, false, nil
+	}
+	return entry.state, true, nil
+}
+
+// Save stores state as the newest entry and drops the expired ones from
+// the old end, so each save costs only the entries it expires.
+func (m *memoryStreamState) Save(_ context.Context, id string, state StreamState) error {
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for el := m.order.Front(); el != nil; el = m.order.Front() {
+		entry := el.Value.(*memoryStreamEntry)
+		if now.Sub(entry.saved) <= m.ttl {
+			break
+		}
+		m.order.Remove(el)
+		delete(m.states, entry.id)
+	}
+	if el, ok := m.states[id]; ok {
+		entry := el.Value.(*memoryStreamEntry)
+		entry.saved, entry.state = now, state
+		m.order.MoveToBack(el)
+		return nil
+	}
+	m.states[id] = m.order.PushBack(&memoryStreamEntry{id: id, saved: now, state: state})
+	return nil
+}
+
+func (m *memoryStreamState) Delete(_ context.Context, id string) error {
+	m.mu.Lock()
+	if el, ok := m.states[id]; ok {
+		m.order.Remove(el)
+		delete(m.states, id)
+	}
+	m.mu.Unlock()
+	return nil
+}
+
+// RedisStreamClient is the subset of a Redis client RedisStreamState
+// needs. A go-redis client adapts in a few lines:
+//
+//	func (c adapter) Get(ctx context.Context, key string) (string, bool, error) {
+//		v, err := c.Client.Get(ctx, key).Result()
+//		if err == redis.Nil {
+//			return "", false, nil
+//		}
+//		return v, err == nil, err
+//	}
+//	func (c adapter) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+//		return c.Client.Set(ctx, key, value, ttl).Err()
+//	}
+//	func (c adapter) Del(ctx context.Context, key string) error {
+//		return c.Client.Del(ctx, key).Err()
+//	}
+type RedisStreamClient interface {
+	Get(ctx context.Context, key string) (value string, ok bool, err error)
+	Set(ctx context.Context, key, value string, ttl time.Duration) error
+	Del(ctx context.Context, key string) error
+}
+
+// RedisStreamStateOptions tunes RedisStreamState. Zero values use the
+// defaults.
+type RedisStreamStateOptions struct {
+	Prefix string        // key prefix (default "seam:stream:")
+	TTL    time.Duration // expiry after the last update (default 5m)
+}
+
+type redisStreamState struct {
+	client RedisStreamClient
+	prefix string
+	ttl    time.Duration
+}
+
+// RedisStreamState returns a store sharing stream state across replicas
+// through Redis, one JSON value per stream.
+func RedisStreamState(client RedisStreamClient, opts RedisStreamStateOptions) StreamStateStore {
+	if opts.Prefix == "" {
+		opts.Prefix = "seam:stream:"
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = 5 * time.Minute
+	}
+	return &redisStreamState{client: client, prefix: opts.Prefix, ttl: opts.TTL}
+}
+
+func (r *redisStreamState) Load(ctx context.Context, id string) (StreamState, bool, error) {
+	var state StreamState
+	raw, ok, err := r.client.Get(ctx, r.prefix+id)
+	if err != nil || !ok {
+		return state, false, err
+	}
+	if err := json.Unmarshal([]byte(raw), &state); err != nil {
+		return state, false, fmt.Errorf("decode stream state: %w", err)
+	}
+	return state, true, nil
+}
+
+func (r *redisStreamState) Save(ctx context.Context, id string, state StreamState) error {
+	raw, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, r.prefix+id, string(raw), r.ttl)
+}
+
+func (r *redisStreamState) Delete(ctx context.Context, id string) error {
+	return r.client.Del(ctx, r.prefix+id)
+}
@@ -0,0 +1,175 @@
+/* src/server/core/go/stream_state_test.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStreamStateResumesOnAnotherReplica(t *testing.T) {
+	store := MemoryStreamState(0)
+	ctx, cut := context.WithCancel(context.Background())
+	defer cut()
+	release := make(chan struct{})
+	defer close(release)
+
+	feed := func(ctx context.Context, input json.RawMessage) (<-chan SubscriptionEvent, error) {
+		ch := make(chan SubscriptionEvent)
+		from := LastEventID(ctx)
+		go func() {
+			defer close(ch)
+			if from == "" {
+				// First connection: three events, then the connection drops
+				for _, c := range []string{"c1", "c2", "c3"} {
+					ch <- SubscriptionEvent{Value: c, Cursor: c}
+				}
+				cut()
+				<-release
+				return
+			}
+			ch <- SubscriptionEvent{Value: "after " + from, Cursor: "c4"}
+		}()
+		return ch, nil
+	}
+	replica := func() http.Handler {
+		return buildHandler(nil, []SubscriptionDef{{Name: "feed", Handler: feed}}, nil, nil, nil, nil, nil, nil, "", nil, nil,
+			nil, HandlerOptions{HeartbeatInterval: time.Minute, StreamState: store}, ValidationModeNever)
+	}
+
+	w := httptest.NewRecorder()
+	replica().ServeHTTP(w, httptest.NewRequest("GET", "/_seam/procedure/feed", http.NoBody).WithContext(ctx))
+	ids := regexp.MustCompile(`id: ([0-9a-f]{32})\.(\d)`).FindAllStringSubmatch(w.Body.String(), -1)
+	if len(ids) != 3 || ids[2][2] != "2" || strings.Contains(w.Body.String(), "event: complete") {
+		t.Fatalf("unexpected first stream:\n%s", w.Body.String())
+	}
+	stream := ids[0][1]
+
+	// The client received only the first two events before the cut
+	req := httptest.NewRequest("GET", "/_seam/procedure/feed", http.NoBody)
+	req.Header.Set("Last-Event-ID", stream+".1")
+	w = httptest.NewRecorder()
+	replica().ServeHTTP(w, req)
+	body := w.Body.String()
+	if !strings.Contains(body, "id: "+stream+".2\ndata: \"after c2\"") || !strings.Contains(body, "event: complete") {
+		t.Fatalf("unexpected resumed stream:\n%s", body)
+	}
+	if _, ok, _ := store.Load(context.Background(), stream); ok {
+		t.Fatal("state kept after the stream completed")
+	}
+}
+
+func TestStreamStateUnknownID(t *testing.T) {
+	feed := func(ctx context.Context, input json.RawMessage) (<-chan SubscriptionEvent, error) {
+		ch := make(chan SubscriptionEvent, 1)
+		ch <- SubscriptionEvent{Value: LastEventID(ctx)}
+		close(ch)
+		return ch, nil
+	}
+	h := buildHandler(nil, []SubscriptionDef{{Name: "feed", Handler: feed}}, nil, nil, nil, nil, nil, nil, "", nil, nil,
+		nil, HandlerOptions{HeartbeatInterval: time.Minute, StreamState: MemoryStreamState(0)}, ValidationModeNever)
+
+	req := httptest.NewRequest("GET", "/_seam/procedure/feed", http.NoBody)
+	req.Header.Set("Last-Event-ID", "gone.7")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	// Not resumable: a fresh stream, the raw header passed through
+	if !regexp.MustCompile(`id: [0-9a-f]{32}\.0\ndata: "gone.7"`).MatchString(w.Body.String()) {
+		t.Fatalf("unexpected stream:\n%s", w.Body.String())
+	}
+}
+
+func TestStreamStateCursorExpired(t *testing.T) {
+	store := MemoryStreamState(0)
+	feed := func(ctx context.Context, input json.RawMessage) (<-chan SubscriptionEvent, error) {
+		ch := make(chan SubscriptionEvent, 1)
+		ch <- SubscriptionEvent{Value: LastEventID(ctx)}
+		close(ch)
+		return ch, nil
+	}
+	h := buildHandler(nil, []SubscriptionDef{{Name: "feed", Handler: feed}}, nil, nil, nil, nil, nil, nil, "", nil, nil,
+		nil, HandlerOptions{HeartbeatInterval: time.Minute, StreamState: store}, ValidationModeNever)
+	state := StreamState{Subscription: "feed", Seq: 40, Truncated: true, UpdatedAt: time.Now()}
+	for seq := 9; seq <= 40; seq++ {
+		state.Cursors = append(state.Cursors, StreamCursor{Seq: seq, Cursor: "c" + strconv.Itoa(seq)})
+	}
+	_ = store.Save(context.Background(), "s1", state)
+
+	resume := func(lastEventID string) string {
+		req := httptest.NewRequest("GET", "/_seam/procedure/feed", http.NoBody)
+		req.Header.Set("Last-Event-ID", lastEventID)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+	if body := resume("s1.20"); !strings.Contains(body, "id: s1.21\ndata: \"c20\"") {
+		t.Fatalf("resume inside the window:\n%s", body)
+	}
+	_ = store.Save(context.Background(), "s1", state) // completing the stream dropped it
+	body := resume("s1.5")
+	if !strings.Contains(body, "event: error") || !strings.Contains(body, `"code":"CURSOR_EXPIRED"`) {
+		t.Fatalf("resume before the window:\n%s", body)
+	}
+}
+
+func TestMemoryStreamStateExpiry(t *testing.T) {
+	store := MemoryStreamState(20 * time.Millisecond)
+	ctx := context.Background()
+	_ = store.Save(ctx, "old", StreamState{Seq: 1})
+	_ = store.Save(ctx, "kept", StreamState{Seq: 1})
+	time.Sleep(30 * time.Millisecond)
+	_ = store.Save(ctx, "kept", StreamState{Seq: 2})
+	_ = store.Save(ctx, "new", StreamState{Seq: 1})
+	m := store.(*memoryStreamState)
+	if len(m.states) != 2 || m.order.Len() != 2 {
+		t.Fatalf("expired entry kept: %d states, %d ordered", len(m.states), m.order.Len())
+	}
+	if got, ok, _ := store.Load(ctx, "kept"); !ok || got.Seq != 2 {
+		t.Fatalf("kept = %+v, %v", got, ok)
+	}
+}
+
+type fakeRedisStream map[string]string
+
+func (f fakeRedisStream) Get(_ context.Context, key string) (string, bool, error) {
+	v, ok := f[key]
+	return v, ok, nil
+}
+
+func (f fakeRedisStream) Set(_ context.Context, key, value string, _ time.Duration) error {
+	f[key] = value
+	return nil
+}
+
+func (f fakeRedisStream) Del(_ context.Context, key string) error {
+	delete(f, key)
+	return nil
+}
+
+func TestRedisStreamState(t *testing.T) {
+	client := fakeRedisStream{}
+	store := RedisStreamState(client, RedisStreamStateOptions{})
+	ctx := context.Background()
+	state := StreamState{Subscription: "feed", Seq: 3, Cursors: []StreamCursor{{Seq: 3, Cursor: "c"}}}
+	if err := store.Save(ctx, "abc", state); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := client["seam:stream:abc"]; !ok {
+		t.Fatalf("unexpected keys %v", client)
+	}
+	got, ok, err := store.Load(ctx, "abc")
+	if err != nil || !ok || got.Seq != 3 || got.Cursors[0].Cursor != "c" {
+		t.Fatalf("Load = %+v, %v, %v", got, ok, err)
+	}
+	_ = store.Delete(ctx, "abc")
+	if _, ok, _ := store.Load(ctx, "abc"); ok {
+		t.Fatal("state kept after Delete")
+	}
+}
@@ -0,0 +1,89 @@
+/* src/server/core/go/subscription_delta.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Subscription event names of the snapshot + delta protocol: the first
+// event carries the full state, later ones a JSON Patch (RFC 6902) of
+// PatchOps against the previous state.
+const (
+	SnapshotEvent = "snapshot"
+	DeltaEvent    = "delta"
+)
+
+func (ev SubscriptionEvent) eventName() string {
+	if ev.Event != "" {
+		return ev.Event
+	}
+	return "data"
+}
+
+// SubscribeDeltas is Subscribe for large, slowly changing states (live
+// dashboards): fn sends whole states, clients receive one snapshot event
+// and then delta events with patches between consecutive states. The
+// client's SSE parser applies the patches and hands every subscriber the
+// full state; polling (GET .../poll) also answers with the full state.
+// The manifest marks the subscription with "deltas": true.
+func SubscribeDeltas[In, Out any](name string, fn func(context.Context, In) (<-chan Out, error)) *SubscriptionDef {
+	def := Subscribe(name, fn)
+	inner := def.Handler
+	def.Handler = func(ctx context.Context, raw json.RawMessage) (<-chan SubscriptionEvent, error) {
+		ch, err := inner(ctx, raw)
+		if err != nil {
+			return nil, err
+		}
+		return SnapshotDeltas(ch), nil
+	}
+	def.Deltas = true
+	return def
+}
+
+// SnapshotDeltas turns a stream of full states into snapshot and delta
+// events. Unchanged states are dropped; a delta that would not be smaller
+// than the state is sent as a new snapshot instead. Error events and
+// events that already name an Event pass through.
+func SnapshotDeltas(events <-chan SubscriptionEvent) <-chan SubscriptionEvent {
+	out := make(chan SubscriptionEvent)
+	go func() {
+		defer close(out)
+		var prev any
+		started := false
+		for ev := range events {
+			if ev.Err != nil || ev.Event != "" {
+				out <- ev
+				continue
+			}
+			raw, err := json.Marshal(ev.Value)
+			if err != nil {
+				out <- SubscriptionEvent{Err: InternalError(fmt.Sprintf("Subscription state not serializable: %v", err))}
+				continue
+			}
+			cur, err := decodeJSONValue(raw)
+			if err != nil {
+				out <- SubscriptionEvent{Err: InternalError(fmt.Sprintf("Subscription state not serializable: %v", err))}
+				continue
+			}
+			if !started {
+				started, prev = true, cur
+				out <- SubscriptionEvent{Event: SnapshotEvent, Value: ev.Value, Cursor: ev.Cursor}
+				continue
+			}
+			ops := diffJSONValues(prev, cur)
+			prev = cur
+			if len(ops) == 0 {
+				continue
+			}
+			if patch, _ := json.Marshal(ops); len(patch) >= len(raw) {
+				out <- SubscriptionEvent{Event: SnapshotEvent, Value: ev.Value, Cursor: ev.Cursor}
+				continue
+			}
+			out <- SubscriptionEvent{Event: DeltaEvent, Value: ops, Cursor: ev.Cursor}
+		}
+	}()
+	return out
+}
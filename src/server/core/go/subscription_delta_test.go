@@ -0,0 +1,73 @@
+/* src/server/core/go/subscription_delta_test.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type dashboard struct {
+	Title   string         `json:"title"`
+	Counts  map[string]int `json:"counts"`
+	Recent  []string       `json:"recent"`
+	Warning *string        `json:"warning"`
+}
+
+func TestSnapshotDeltas(t *testing.T) {
+	in := make(chan SubscriptionEvent, 5)
+	big := strings.Repeat("row ", 50)
+	in <- SubscriptionEvent{Value: map[string]any{"n": 1, "body": big}}
+	in <- SubscriptionEvent{Value: map[string]any{"n": 1, "body": big}} // unchanged: dropped
+	in <- SubscriptionEvent{Value: map[string]any{"n": 2, "body": big}}
+	in <- SubscriptionEvent{Err: NotFoundError("gone")}
+	in <- SubscriptionEvent{Value: map[string]any{"m": 1}} // patch larger than state
+	close(in)
+
+	var events []string
+	for ev := range SnapshotDeltas(in) {
+		switch {
+		case ev.Err != nil:
+			events = append(events, "error")
+		case ev.Event == DeltaEvent:
+			raw, _ := json.Marshal(ev.Value)
+			events = append(events, "delta "+string(raw))
+		default:
+			events = append(events, ev.Event)
+		}
+	}
+	want := []string{"snapshot", `delta [{"op":"replace","path":"/n","value":2}]`, "error", "snapshot"}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("events %v", events)
+	}
+}
+
+func TestSubscribeDeltasOverSSE(t *testing.T) {
+	router := NewRouter().Subscription(SubscribeDeltas("board", func(context.Context, struct{}) (<-chan dashboard, error) {
+		ch := make(chan dashboard, 2)
+		ch <- dashboard{Title: "Ops", Counts: map[string]int{"open": 12}, Recent: []string{"deploy api", "rotate keys", "scale workers"}}
+		ch <- dashboard{Title: "Ops", Counts: map[string]int{"open": 13}, Recent: []string{"deploy api", "rotate keys", "scale workers"}}
+		close(ch)
+		return ch, nil
+	}))
+	h := router.Handler()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/_seam/procedure/board?input=%7B%7D", nil))
+	body := w.Body.String()
+	snapshot := strings.Index(body, "event: snapshot\n")
+	delta := strings.Index(body, "event: delta\n")
+	if snapshot < 0 || delta < snapshot || !strings.Contains(body, `[{"op":"replace","path":"/counts/open","value":13}]`) {
+		t.Errorf("stream:\n%s", body)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/_seam/manifest.json", nil))
+	if !strings.Contains(w.Body.String(), `"deltas":true`) {
+		t.Errorf("manifest %s", w.Body.String())
+	}
+}
@@ -0,0 +1,184 @@
+/* src/server/core/go/subscription_input.go */
+
+package seam
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// StageInputProcedure is the built-in command that stores a large
+// subscription input server-side. It returns {"ref": "..."}, which the
+// client passes as ?inputRef= on the subscription GET or WebSocket upgrade
+// instead of an oversized ?input= query parameter.
+const StageInputProcedure = "seam.input.stage"
+
+// stagedInputTTL bounds how long a staged input waits for its subscription.
+const stagedInputTTL = 30 * time.Second
+
+const (
+	stagedInputMax         = 10000 // staged inputs held at once
+	stagedInputsPerCaller  = 16    // per principal, or client IP when anonymous
+	stagedInputSweepPeriod = stagedInputTTL / 4
+)
+
+type stagedInput struct {
+	subscription string
+	owner        string
+	input        json.RawMessage
+	expires      time.Time
+}
+
+// stagedInputStore holds single-use staged subscription inputs, bounded
+// in total and per caller; expired entries are swept periodically.
+type stagedInputStore struct {
+	mu        sync.Mutex
+	entries   map[string]stagedInput
+	perOwner  map[string]int
+	nextSweep time.Time
+}
+
+func newStagedInputStore() *stagedInputStore {
+	return &stagedInputStore{entries: make(map[string]stagedInput), perOwner: make(map[string]int)}
+}
+
+// stagedInputOwner keys the caller of ctx: its principal, else its client IP.
+func stagedInputOwner(ctx context.Context) string {
+	if p := PrincipalOf(ctx); p != "" {
+		return p
+	}
+	return "ip:" + ClientIP(ctx)
+}
+
+// put stores input for subscription, reporting false when the store or
+// the owner's share of it is full.
+func (st *stagedInputStore) put(owner, subscription string, input json.RawMessage) (string, bool) {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	ref := hex.EncodeToString(b[:])
+	now := time.Now()
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if now.After(st.nextSweep) || len(st.entries) >= stagedInputMax {
+		st.nextSweep = now.Add(stagedInputSweepPeriod)
+		for id, e := range st.entries {
+			if now.After(e.expires) {
+				st.remove(id, e)
+			}
+		}
+	}
+	if len(st.entries) >= stagedInputMax || st.perOwner[owner] >= stagedInputsPerCaller {
+		return "", false
+	}
+	st.entries[ref] = stagedInput{subscription: subscription, owner: owner, input: input, expires: now.Add(stagedInputTTL)}
+	st.perOwner[owner]++
+	return ref, true
+}
+
+func (st *stagedInputStore) remove(ref string, e stagedInput) {
+	delete(st.entries, ref)
+	if st.perOwner[e.owner]--; st.perOwner[e.owner] <= 0 {
+		delete(st.perOwner, e.owner)
+	}
+}
+
+// take removes and returns the staged input, which must belong to
+// subscription and have been staged by owner.
+func (st *stagedInputStore) take(ref, owner, subscription string) (json.RawMessage, bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	e, ok := st.entries[ref]
+	if !ok || e.subscription != subscription || e.owner != owner {
+		return nil, false
+	}
+	st.remove(ref, e)
+	if time.Now().After(e.expires) {
+		return nil, false
+	}
+	return e.input, true
+}
+
+// stageInputProcedure builds the built-in staging command.
+func (s *appState) stageInputProcedure() ProcedureDef {
+	return ProcedureDef{
+		Name:         StageInputProcedure,
+		Type:         "command",
+		InputSchema:  map[string]any{},
+		OutputSchema: map[string]any{},
+		Handler: func(ctx context.Context, raw json.RawMessage) (any, error) {
+			var req struct {
+				Subscription string          `json:"subscription"`
+				Input        json.RawMessage `json:"input"`
+			}
+			if err := codecUnmarshal(raw, &req); err != nil {
+				return nil, ValidationError("Invalid input")
+			}
+			if _, ok := s.subs[req.Subscription]; !ok {
+				return nil, NotFoundError(fmt.Sprintf("Subscription '%s' not found", req.Subscription))
+			}
+			if len(req.Input) == 0 {
+				req.Input = json.RawMessage("{}")
+			}
+			ref, ok := s.stagedInputs.put(stagedInputOwner(ctx), req.Subscription, req.Input)
+			if !ok {
+				return nil, NewError("RATE_LIMITED", "Too many staged inputs; subscribe with the ones already staged", http.StatusTooManyRequests)
+			}
+			return map[string]string{"ref": ref}, nil
+		},
+	}
+}
+
+// subscriptionInput resolves a subscription's raw input from ?inputRef=
+// (staged) or ?input= (size-limited), then checks it is valid JSON and,
+// when validation is active, matches the subscription's input schema.
+func (s *appState) subscriptionInput(r *http.Request, name string) (json.RawMessage, *Error) {
+	query := r.URL.Query()
+	var input json.RawMessage
+	if ref := query.Get("inputRef"); ref != "" {
+		staged, ok := s.stagedInputs.take(ref, stagedInputOwner(s.requestContext(r)), name)
+		if !ok {
+			return nil, ValidationError("Unknown or expired input reference")
+		}
+		input = staged
+	} else if inputStr := query.Get("input"); inputStr != "" {
+		if limit := s.opts.MaxSubscriptionInput; limit > 0 && len(inputStr) > limit {
+			return nil, ValidationError(fmt.Sprintf("Subscription input exceeds %d bytes; stage it via %s", limit, StageInputProcedure))
+		}
+		input = json.RawMessage(inputStr)
+	} else {
+		input = json.RawMessage("{}")
+	}
+	if err := s.validateSubscriptionInput(name, input); err != nil {
+		return nil, err
+	}
+	return input, nil
+}
+
+// validateSubscriptionInput checks input limits, JSON validity and, when
+// validation is active, the subscription's input schema.
+func (s *appState) validateSubscriptionInput(name string, input json.RawMessage) *Error {
+	if err := s.checkInput(input); err != nil {
+		return err
+	}
+	if !json.Valid(input) {
+		return ValidationError("Invalid JSON in subscription input")
+	}
+	if s.shouldValidate {
+		if cs, ok := s.compiledSubSchemas[name]; ok {
+			var parsed any
+			_ = codecUnmarshal(input, &parsed)
+			if msg, details := validateCompiled(cs, parsed); msg != "" {
+				return ValidationErrorDetailed(
+					fmt.Sprintf("Input validation failed for subscription '%s': %s", name, msg), toAnySlice(details))
+			}
+		}
+	}
+	return nil
+}
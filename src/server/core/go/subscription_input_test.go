@@ -0,0 +1,162 @@
+/* src/server/core/go/subscription_input_test.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func subscriptionInputTestHandler(opts HandlerOptions) http.Handler {
+	echo := func(_ context.Context, input json.RawMessage) (<-chan SubscriptionEvent, error) {
+		ch := make(chan SubscriptionEvent, 1)
+		ch <- SubscriptionEvent{Value: input}
+		close(ch)
+		return ch, nil
+	}
+	return NewRouter().
+		Subscription(&SubscriptionDef{
+			Name: "feed",
+			InputSchema: map[string]any{
+				"properties": map[string]any{"topic": map[string]any{"type": "string"}},
+			},
+			Handler: echo,
+		}).
+		Validation(ValidationModeAlways).
+		Handler(opts)
+}
+
+func subscribeBody(h http.Handler, query string) string {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/_seam/procedure/feed?"+query, http.NoBody))
+	return w.Body.String()
+}
+
+func TestSubscriptionInputValidation(t *testing.T) {
+	h := subscriptionInputTestHandler(HandlerOptions{HeartbeatInterval: time.Second, MaxSubscriptionInput: 64})
+	cases := []struct {
+		query string
+		want  string
+	}{
+		{"input=" + url.QueryEscape(`{"topic":"go"}`), `data: {"topic":"go"}`},
+		{"input=" + url.QueryEscape(`{not json`), "Invalid JSON in subscription input"},
+		{"input=" + url.QueryEscape(`{"topic":1}`), "Input validation failed for subscription 'feed'"},
+		{"input=" + url.QueryEscape(`{"topic":"`+strings.Repeat("x", 80)+`"}`), "exceeds 64 bytes"},
+		{"inputRef=missing", "Unknown or expired input reference"},
+	}
+	for _, c := range cases {
+		if body := subscribeBody(h, c.query); !strings.Contains(body, c.want) {
+			t.Errorf("query %s: expected %q in %s", c.query, c.want, body)
+		}
+	}
+}
+
+func TestSubscriptionInputDefaultLimitWithPartialOptions(t *testing.T) {
+	h := subscriptionInputTestHandler(HandlerOptions{HeartbeatInterval: time.Second})
+	big := "input=" + url.QueryEscape(`{"topic":"`+strings.Repeat("x", 9<<10)+`"}`)
+	if body := subscribeBody(h, big); !strings.Contains(body, "exceeds 8192 bytes") {
+		t.Fatalf("expected the 8 KiB default with partial options: %s", body)
+	}
+
+	h = subscriptionInputTestHandler(HandlerOptions{HeartbeatInterval: time.Second, MaxSubscriptionInput: -1})
+	if body := subscribeBody(h, big); !strings.Contains(body, "data: ") {
+		t.Fatalf("negative limit must disable the cap: %s", body)
+	}
+}
+
+func TestSubscriptionStagedInput(t *testing.T) {
+	h := subscriptionInputTestHandler(HandlerOptions{HeartbeatInterval: time.Second, MaxSubscriptionInput: 64})
+	topic := strings.Repeat("y", 200)
+	body, _ := json.Marshal(map[string]any{"subscription": "feed", "input": map[string]any{"topic": topic}})
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/_seam/procedure/"+StageInputProcedure, strings.NewReader(string(body))))
+	var resp struct {
+		Data struct {
+			Ref string `json:"ref"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Data.Ref == "" {
+		t.Fatalf("stage failed: %s", w.Body.String())
+	}
+
+	if got := subscribeBody(h, "inputRef="+resp.Data.Ref); !strings.Contains(got, topic) {
+		t.Fatalf("staged input not used: %s", got)
+	}
+	if got := subscribeBody(h, "inputRef="+resp.Data.Ref); !strings.Contains(got, "Unknown or expired") {
+		t.Fatalf("staged input must be single-use: %s", got)
+	}
+}
+
+func TestStagedInputBoundToSubscription(t *testing.T) {
+	st := newStagedInputStore()
+	ref, _ := st.put("u1", "a", json.RawMessage(`{}`))
+	if _, ok := st.take(ref, "u1", "b"); ok {
+		t.Fatal("staged input must not be usable by another subscription")
+	}
+	if _, ok := st.take(ref, "u2", "a"); ok {
+		t.Fatal("staged input must not be usable by another caller")
+	}
+	if _, ok := st.take(ref, "u1", "a"); !ok {
+		t.Fatal("staged input should remain for its own subscription")
+	}
+}
+
+func TestStagedInputLimits(t *testing.T) {
+	st := newStagedInputStore()
+	for i := range stagedInputsPerCaller {
+		if _, ok := st.put("u1", "a", json.RawMessage(`{}`)); !ok {
+			t.Fatalf("put %d refused", i)
+		}
+	}
+	if _, ok := st.put("u1", "a", json.RawMessage(`{}`)); ok {
+		t.Fatal("per-caller limit not enforced")
+	}
+	ref, ok := st.put("u2", "a", json.RawMessage(`{}`))
+	if !ok {
+		t.Fatal("other callers keep their share")
+	}
+	st.take(ref, "u2", "a")
+	if len(st.perOwner) != 1 || len(st.entries) != stagedInputsPerCaller {
+		t.Fatalf("owners %v, entries %d", st.perOwner, len(st.entries))
+	}
+
+	// expired entries are swept, freeing the caller's share
+	st.mu.Lock()
+	for id, e := range st.entries {
+		e.expires = time.Now().Add(-time.Second)
+		st.entries[id] = e
+	}
+	st.nextSweep = time.Time{}
+	st.mu.Unlock()
+	if _, ok := st.put("u1", "a", json.RawMessage(`{}`)); !ok || len(st.entries) != 1 {
+		t.Fatalf("expired entries not swept: %d left", len(st.entries))
+	}
+}
+
+func TestSubscriptionViaPost(t *testing.T) {
+	h := subscriptionInputTestHandler(HandlerOptions{HeartbeatInterval: time.Second, MaxSubscriptionInput: 64})
+	topic := strings.Repeat("z", 200) // beyond the query limit; POST bodies are not capped by it
+	cases := []struct {
+		body string
+		want string
+	}{
+		{`{"topic":"` + topic + `"}`, `data: {"topic":"` + topic + `"}`},
+		{`{"topic":1}`, "Input validation failed for subscription 'feed'"},
+		{`{oops`, "Invalid JSON in subscription input"},
+	}
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/_seam/procedure/feed", strings.NewReader(c.body))
+		req.Header.Set("Accept", "text/event-stream")
+		h.ServeHTTP(w, req)
+		if w.Header().Get("Content-Type") != "text/event-stream" || !strings.Contains(w.Body.String(), c.want) {
+			t.Errorf("body %s: expected SSE containing %q, got %q", c.body, c.want, w.Body.String())
+		}
+	}
+}
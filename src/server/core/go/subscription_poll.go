@@ -0,0 +1,161 @@
+/* src/server/core/go/subscription_poll.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// pollWatchersMax bounds the subscriptions kept running for pollers;
+// polls needing a new one beyond it are shed.
+const pollWatchersMax = 1024
+
+// pollWatchers shares one running subscription between the pollers of
+// the same subscription, input, caller, and context fields.
+type pollWatchers struct {
+	mu       sync.Mutex
+	watchers map[string]*pollWatch
+}
+
+// pollWatch keeps the latest value of a subscription for polling. Delta
+// subscriptions are folded back into full states.
+type pollWatch struct {
+	ready     chan struct{} // closed at the first event or when the subscription ends
+	readyOnce sync.Once
+	lastPoll  atomic.Int64 // unix nanoseconds
+
+	mu    sync.Mutex
+	data  json.RawMessage // nil until the first value
+	err   *Error          // set when the latest event was an error
+	state any             // decoded state of snapshot + delta subscriptions
+}
+
+func (pw *pollWatch) markReady() {
+	pw.readyOnce.Do(func() { close(pw.ready) })
+}
+
+func (pw *pollWatch) touch() {
+	pw.lastPoll.Store(time.Now().UnixNano())
+}
+
+// latest returns the current value, or the error of the latest event.
+func (pw *pollWatch) latest() (json.RawMessage, *Error) {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	return pw.data, pw.err
+}
+
+func (pw *pollWatch) update(ev SubscriptionEvent) {
+	defer pw.markReady()
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	if ev.Err != nil {
+		pw.err = ev.Err
+		return
+	}
+	value := ev.Value
+	switch ev.Event {
+	case SnapshotEvent:
+		state, err := toJSONValue(ev.Value)
+		if err != nil {
+			pw.err = InternalError(fmt.Sprintf("Subscription state not serializable: %v", err))
+			return
+		}
+		pw.state = state
+	case DeltaEvent:
+		ops, _ := ev.Value.([]PatchOp)
+		state, err := ApplyPatch(pw.state, ops)
+		if err != nil {
+			pw.err = InternalError(fmt.Sprintf("Subscription delta: %v", err))
+			return
+		}
+		pw.state, value = state, state
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		pw.err = InternalError(fmt.Sprintf("Subscription value not serializable: %v", err))
+		return
+	}
+	pw.data, pw.err = raw, nil
+}
+
+// pollWatch returns the running subscription for a poll of sub, starting
+// it when there is none. ctx is the poll's request context with context
+// fields and state injected; the subscription keeps its values but not
+// its cancellation, and stops once no poll has come for three poll
+// intervals, at shutdown, or when it ends.
+func (s *appState) pollWatch(ctx context.Context, sub *SubscriptionDef, fields map[string]any, input json.RawMessage) (*pollWatch, *Error) {
+	keyFields, _ := json.Marshal(fields)
+	key := sub.Name + "\x00" + stagedInputOwner(ctx) + "\x00" + string(keyFields) + "\x00" + string(input)
+
+	s.polls.mu.Lock()
+	if pw, ok := s.polls.watchers[key]; ok {
+		s.polls.mu.Unlock()
+		pw.touch()
+		return pw, nil
+	}
+	if len(s.polls.watchers) >= pollWatchersMax {
+		s.polls.mu.Unlock()
+		return nil, s.sheddingError("poll", true)
+	}
+	if s.polls.watchers == nil {
+		s.polls.watchers = make(map[string]*pollWatch)
+	}
+	pw := &pollWatch{ready: make(chan struct{})}
+	pw.touch()
+	s.polls.watchers[key] = pw
+	s.polls.mu.Unlock()
+
+	remove := func() {
+		s.polls.mu.Lock()
+		if s.polls.watchers[key] == pw {
+			delete(s.polls.watchers, key)
+		}
+		s.polls.mu.Unlock()
+	}
+	subCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	ch, err := sub.Handler(subCtx, input)
+	if err != nil {
+		cancel()
+		remove()
+		seamErr, ok := err.(*Error)
+		if !ok {
+			seamErr = InternalError(err.Error())
+		}
+		pw.mu.Lock()
+		pw.err = seamErr
+		pw.mu.Unlock()
+		pw.markReady()
+		return nil, seamErr
+	}
+	go func() {
+		defer trackEvents(s.activity, "subscription", sub.Name, ch)()
+		defer cancel()
+		defer pw.markReady()
+		defer remove()
+		interval := s.opts.ConnectionLimits.pollInterval()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case ev, ok := <-ch:
+				if !ok {
+					return
+				}
+				pw.update(ev)
+			case <-ticker.C:
+				if time.Since(time.Unix(0, pw.lastPoll.Load())) > 3*interval {
+					return
+				}
+			case <-s.shutdownCh:
+				return
+			}
+		}
+	}()
+	return pw, nil
+}
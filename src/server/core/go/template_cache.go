@@ -0,0 +1,192 @@
+/* src/server/core/go/template_cache.go */
+
+package seam
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// TemplateCache is a byte-bounded LRU of resolved page templates, shared
+// by the pages of LoadBuildOutputLazy. A miss reads the route template
+// from disk and applies the (in-memory) layout chain. Templates with
+// include directives expire with their partials (WithPartialTTL), and
+// Invalidate drops everything, so edited partials and overrides are
+// picked up without a restart.
+type TemplateCache struct {
+	maxBytes   int64
+	partialTTL time.Duration
+
+	mu       sync.Mutex
+	partials []*partialSet // of the builds loaded into the cache
+	lru      *list.List    // front = most recently used
+	items    map[string]*list.Element
+	bytes    int64
+	hits     int64
+	misses   int64
+}
+
+type templateCacheEntry struct {
+	key     string
+	html    string
+	expires time.Time // zero: kept until evicted
+}
+
+// TemplateCacheStats reports cache occupancy and effectiveness.
+type TemplateCacheStats struct {
+	Entries int
+	Bytes   int64
+	Hits    int64
+	Misses  int64
+}
+
+// NewTemplateCache creates a cache holding at most maxBytes of templates
+// (default 32 MiB when maxBytes <= 0). A template larger than the cap is
+// served but not kept.
+func NewTemplateCache(maxBytes int64) *TemplateCache {
+	if maxBytes <= 0 {
+		maxBytes = 32 << 20
+	}
+	return &TemplateCache{maxBytes: maxBytes, partialTTL: defaultPartialTTL, lru: list.New(), items: make(map[string]*list.Element)}
+}
+
+// WithPartialTTL sets how long partials, and the templates including
+// them, are reused before being read again (default one minute; < 0
+// keeps them until Invalidate).
+func (c *TemplateCache) WithPartialTTL(ttl time.Duration) *TemplateCache {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.partialTTL = ttl
+	for _, p := range c.partials {
+		p.mu.Lock()
+		p.ttl = ttl
+		p.mu.Unlock()
+	}
+	return c
+}
+
+// Invalidate drops every cached template and partial; the next render of
+// each page reads its template again.
+func (c *TemplateCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lru.Init()
+	clear(c.items)
+	c.bytes = 0
+	for _, p := range c.partials {
+		p.invalidate()
+	}
+}
+
+// addPartials ties the partials of a build loaded into the cache to its
+// TTL and Invalidate.
+func (c *TemplateCache) addPartials(p *partialSet) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p.ttl = c.partialTTL
+	c.partials = append(c.partials, p)
+}
+
+func (c *TemplateCache) remove(el *list.Element) {
+	entry := el.Value.(*templateCacheEntry)
+	c.lru.Remove(el)
+	delete(c.items, entry.key)
+	c.bytes -= int64(len(entry.html))
+}
+
+// get returns the cached template for key, loading and caching it on a
+// miss. load reports whether the template included partials, which makes
+// the entry expire with the partial TTL.
+func (c *TemplateCache) get(key string, load func() (html string, includes bool, err error)) (string, error) {
+	c.mu.Lock()
+	if el, ok := c.items[key]; ok {
+		entry := el.Value.(*templateCacheEntry)
+		if entry.expires.IsZero() || time.Now().Before(entry.expires) {
+			c.lru.MoveToFront(el)
+			c.hits++
+			c.mu.Unlock()
+			return entry.html, nil
+		}
+		c.remove(el)
+	}
+	c.misses++
+	c.mu.Unlock()
+
+	html, includes, err := load()
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.items[key]; ok || int64(len(html)) > c.maxBytes {
+		return html, nil
+	}
+	entry := &templateCacheEntry{key: key, html: html}
+	if includes && c.partialTTL >= 0 {
+		entry.expires = time.Now().Add(c.partialTTL)
+	}
+	c.items[key] = c.lru.PushFront(entry)
+	c.bytes += int64(len(html))
+	for c.bytes > c.maxBytes {
+		c.remove(c.lru.Back())
+	}
+	return html, nil
+}
+
+// Stats returns a snapshot of the cache counters.
+func (c *TemplateCache) Stats() TemplateCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return TemplateCacheStats{Entries: len(c.items), Bytes: c.bytes, Hits: c.hits, Misses: c.misses}
+}
+
+// lazyTemplate locates a page's templates on disk for LoadBuildOutputLazy.
+// Layouts stay in memory: they are few and shared, while resolved page
+// templates (one per route and locale) dominate memory.
+type lazyTemplate struct {
+	cache         *TemplateCache
+	src           templateSource
+	route         string
+	layout        string
+	path          string            // default-locale template
+	localePaths   map[string]string // locale -> template (i18n only)
+	layouts       map[string]layoutResolved
+	localeLayouts map[string]map[string]layoutResolved
+}
+
+func (t *lazyTemplate) resolve(locale string) (string, error) {
+	relPath, ok := t.localePaths[locale]
+	if !ok || locale == "" {
+		locale, relPath = "", t.path
+	}
+	return t.cache.get(t.route+"\x00"+locale, func() (string, bool, error) {
+		data, includes, err := t.src.readIncludes("routes", t.route, locale, relPath)
+		if err != nil {
+			return "", false, err
+		}
+		if t.layout == "" {
+			return string(data), includes, nil
+		}
+		layouts := t.layouts
+		if ll := t.localeLayouts[locale]; locale != "" && ll != nil {
+			layouts = ll
+		}
+		return resolveLayoutChain(t.layout, string(data), layouts), includes, nil
+	})
+}
+
+// template returns the page template for locale: the locale-specific
+// pre-resolved template when present, else the default one.
+func (p *PageDef) template(locale string) (string, error) {
+	if p.lazy != nil {
+		return p.lazy.resolve(locale)
+	}
+	if locale != "" && p.LocaleTemplates != nil {
+		if lt, ok := p.LocaleTemplates[locale]; ok {
+			return lt, nil
+		}
+	}
+	return p.Template, nil
+}
@@ -0,0 +1,99 @@
+/* src/server/core/go/template_cache_test.go */
+
+package seam
+
+import (
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTemplateCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewTemplateCache(10)
+	load := func(html string) func() (string, bool, error) {
+		return func() (string, bool, error) { return html, false, nil }
+	}
+	_, _ = c.get("a", load("aaaa"))
+	_, _ = c.get("b", load("bbbb"))
+	_, _ = c.get("a", load("xxxx")) // hit, refreshes a
+	_, _ = c.get("c", load("cccc")) // evicts b
+	if got, _ := c.get("a", load("reloaded")); got != "aaaa" {
+		t.Fatalf("a should still be cached, got %q", got)
+	}
+	if got, _ := c.get("b", load("reloaded")); got != "reloaded" {
+		t.Fatalf("b should have been evicted, got %q", got)
+	}
+	if _, err := c.get("d", func() (string, bool, error) { return "", false, errors.New("gone") }); err == nil {
+		t.Fatal("load errors must surface")
+	}
+	if st := c.Stats(); st.Bytes > 10 || st.Hits != 2 || st.Misses != 5 {
+		t.Fatalf("unexpected stats %+v", st)
+	}
+}
+
+func TestLoadBuildOutputLazy(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"route-manifest.json": `{
+			"layouts": {"root": {"templates": {"en": "t/root.en.html", "zh": "t/root.zh.html"}}},
+			"routes": {"/about": {"templates": {"en": "t/about.en.html", "zh": "t/about.zh.html"}, "layout": "root"}},
+			"i18n": {"locales": ["en", "zh"], "default": "en"}
+		}`,
+		"t/root.en.html":  `<body>EN<!--seam:outlet--></body>`,
+		"t/root.zh.html":  `<body>ZH<!--seam:outlet--></body>`,
+		"t/about.en.html": `<p>about</p>`,
+		"t/about.zh.html": `<p>关于</p>`,
+	})
+
+	cache := NewTemplateCache(1 << 20)
+	pages, err := LoadBuildOutputLazy(dir, "", cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := &pages[0]
+	if page.Template != "" || page.LocaleTemplates != nil {
+		t.Fatal("lazy load must not keep templates on the page")
+	}
+	for locale, want := range map[string]string{"zh": `<body>ZH<p>关于</p></body>`, "": `<body>EN<p>about</p></body>`} {
+		got, err := page.template(locale)
+		if err != nil || got != want {
+			t.Fatalf("template(%q) = %q, %v; want %q", locale, got, err, want)
+		}
+	}
+
+	// Served from cache after the file is gone
+	_ = os.Remove(filepath.Join(dir, "t/about.zh.html"))
+	if _, err := page.template("zh"); err != nil {
+		t.Fatalf("expected cache hit: %v", err)
+	}
+	if st := cache.Stats(); fmt.Sprint(st.Entries, st.Hits, st.Misses) != "2 1 2" {
+		t.Fatalf("unexpected stats %+v", st)
+	}
+}
+
+func TestLazyNamespacedSlotsCheckedOnFirstRender(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"route-manifest.json": `{"routes": {
+			"/ok": {"template": "t/ok.html"},
+			"/bad": {"template": "t/bad.html"}
+		}}`,
+		"t/ok.html":  `<p>ok</p>`,
+		"t/bad.html": `<p><!--seam:name--></p>`,
+	})
+	pages, err := LoadBuildOutputLazy(dir, "", NewTemplateCache(1<<20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewRouter().Page(&pages[0]).Page(&pages[1]).Handler(HandlerOptions{NamespacedSlots: true})
+	for path, want := range map[string]int{"/_seam/page/ok": 200, "/_seam/page/bad": 500} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != want {
+			t.Errorf("%s: status %d, want %d", path, w.Code, want)
+		}
+	}
+}
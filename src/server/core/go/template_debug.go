@@ -0,0 +1,231 @@
+/* src/server/core/go/template_debug.go */
+
+package seam
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+)
+
+// Region comments emitted by TemplateDebug (slots get "<!--seam-slot:").
+// The engine only processes "<!--seam:" markers, so these pass through
+// rendering untouched.
+const (
+	debugOpen  = "<!--seam-debug:"
+	debugClose = "<!--/seam-debug:"
+)
+
+// debugTemplate returns the page template with each layout's and the
+// page's region wrapped in provenance comments. Build output pages are
+// re-read from disk (templates may have been edited since load); other
+// pages get a single PageDef.Template region.
+func (p *PageDef) debugTemplate(locale string) (string, error) {
+	if p.origin == nil {
+		tmpl, err := p.template(locale)
+		return debugRegion("page "+p.Route+" (PageDef.Template)", tmpl), err
+	}
+	t := p.origin
+	relPath, ok := t.localePaths[locale]
+	if !ok || locale == "" {
+		locale, relPath = "", t.path
+	}
+	data, err := t.src.read("routes", t.route, locale, relPath)
+	if err != nil {
+		return "", err
+	}
+	result := debugRegion("page "+t.route+" ("+relPath+")", string(data))
+	layouts := t.layouts
+	if ll := t.localeLayouts[locale]; locale != "" && ll != nil {
+		layouts = ll
+	}
+	for current := t.layout; current != ""; {
+		lr, ok := layouts[current]
+		if !ok {
+			break
+		}
+		source := "layout " + current + " (" + lr.path + ")"
+		if before, after, found := strings.Cut(lr.template, "<!--seam:outlet-->"); found {
+			result = debugRegion(source, before) + result + debugRegion(source, after)
+		} else {
+			result = debugRegion(source, lr.template)
+		}
+		current = lr.parent
+	}
+	return result, nil
+}
+
+// debugRegion wraps html in provenance comments, keeping a leading
+// doctype first.
+func debugRegion(source, html string) string {
+	if html == "" {
+		return ""
+	}
+	prefix := ""
+	if trimmed := strings.TrimLeft(html, " \t\r\n"); len(trimmed) >= 9 && strings.EqualFold(trimmed[:9], "<!doctype") {
+		if end := strings.IndexByte(trimmed, '>'); end >= 0 {
+			prefix, html = trimmed[:end+1], trimmed[end+1:]
+		}
+	}
+	return prefix + debugOpen + source + "-->" + html + debugClose + source + "-->"
+}
+
+// scanMarkers calls fn for each "<!--seam:" marker in html with its
+// directive and the innermost provenance region containing it.
+func scanMarkers(html string, fn func(start int, directive, source string)) {
+	var regions []string
+	pos := 0
+	for {
+		i := strings.Index(html[pos:], "<!--")
+		if i < 0 {
+			return
+		}
+		start := pos + i
+		end := strings.Index(html[start:], "-->")
+		if end < 0 {
+			return
+		}
+		comment := html[start : start+end+3]
+		pos = start + end + 3
+		switch {
+		case strings.HasPrefix(comment, debugOpen):
+			regions = append(regions, comment[len(debugOpen):len(comment)-3])
+		case strings.HasPrefix(comment, debugClose):
+			if len(regions) > 0 {
+				regions = regions[:len(regions)-1]
+			}
+		case strings.HasPrefix(comment, "<!--seam:"):
+			source := "unknown source"
+			if len(regions) > 0 {
+				source = regions[len(regions)-1]
+			}
+			fn(start, comment[len("<!--seam:"):len(comment)-3], source)
+		}
+	}
+}
+
+// slotPath reduces a value directive to its data path ("" for control
+// directives, reserved slots, and loop-scoped paths).
+func slotPath(directive string) string {
+	paths := slotPaths("<!--seam:" + directive + "-->")
+	if len(paths) == 0 || strings.HasPrefix(directive, "if:") || strings.HasPrefix(directive, "each:") || strings.HasPrefix(directive, "match:") {
+		return ""
+	}
+	return paths[0]
+}
+
+// annotateSlots inserts a provenance comment before each value slot
+// naming the loader (and procedure) its data comes from, and logs slots
+// without data. Slots inside raw-text elements (title, textarea, script,
+// style) are logged but not annotated: a comment there would be text.
+func (s *appState) annotateSlots(page *PageDef, tmpl string, dataJSON []byte) string {
+	var data map[string]any
+	_ = json.Unmarshal(dataJSON, &data)
+	procedures := make(map[string]string, len(page.Loaders))
+	for _, ld := range page.Loaders {
+		procedures[ld.DataKey] = ld.Procedure
+	}
+	lower := strings.ToLower(tmpl)
+	var b strings.Builder
+	last := 0
+	scanMarkers(tmpl, func(start int, directive, source string) {
+		path := slotPath(directive)
+		if path == "" {
+			return
+		}
+		origin := slotOrigin(path, data, procedures)
+		if origin == "" {
+			s.debugWarn(page.Route, fmt.Sprintf("slot %q has no data (%s)", path, source))
+			origin = "no data"
+		}
+		if inRawText(lower[:start]) {
+			return
+		}
+		b.WriteString(tmpl[last:start])
+		fmt.Fprintf(&b, "<!--seam-slot: %s <- %s-->", path, origin)
+		last = start
+	})
+	b.WriteString(tmpl[last:])
+	return b.String()
+}
+
+// slotOrigin describes where a slot's value comes from, or "" when the
+// data has nothing at path.
+func slotOrigin(path string, data map[string]any, procedures map[string]string) string {
+	head, rest, _ := strings.Cut(path, ".")
+	if value, ok := data[head]; ok && (rest == "" || lookupPath(value, rest)) {
+		if proc, ok := procedures[head]; ok {
+			return fmt.Sprintf("loader %s (procedure %s)", head, proc)
+		}
+		return "server data " + head
+	}
+	// Bare slots also resolve through fields of loader objects (flattening)
+	var via []string
+	for key, value := range data {
+		if nested, ok := value.(map[string]any); ok {
+			if _, ok := nested[head]; ok && (rest == "" || lookupPath(nested[head], rest)) {
+				via = append(via, key)
+			}
+		}
+	}
+	if len(via) == 0 {
+		return ""
+	}
+	sort.Strings(via)
+	return "flattened from loader " + strings.Join(via, ", ")
+}
+
+func lookupPath(value any, path string) bool {
+	for _, seg := range strings.Split(path, ".") {
+		m, ok := value.(map[string]any)
+		if !ok {
+			return false
+		}
+		if value, ok = m[seg]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// inRawText reports whether the end of the lowercased html prefix lies
+// inside an element whose content is text, not markup.
+func inRawText(prefix string) bool {
+	for _, tag := range []string{"title", "textarea", "script", "style"} {
+		if strings.LastIndex(prefix, "<"+tag) > strings.LastIndex(prefix, "</"+tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRendered logs markers the engine left in the output.
+func (s *appState) checkRendered(page *PageDef, html string) {
+	scanMarkers(html, func(start int, directive, source string) {
+		s.debugWarn(page.Route, fmt.Sprintf("unresolved marker <!--seam:%s--> (%s)", directive, source))
+	})
+}
+
+// unresolvedMarkers lists the distinct directives of markers left in
+// rendered html, in document order.
+func unresolvedMarkers(html string) []string {
+	var markers []string
+	seen := make(map[string]bool)
+	scanMarkers(html, func(_ int, directive, _ string) {
+		if !seen[directive] {
+			seen[directive] = true
+			markers = append(markers, directive)
+		}
+	})
+	return markers
+}
+
+// debugWarn logs a template debug warning once per route.
+func (s *appState) debugWarn(route, msg string) {
+	if _, dup := s.debugWarned.LoadOrStore(route+"\x00"+msg, struct{}{}); dup {
+		return
+	}
+	logf(slog.LevelInfo, "template debug: page %s: %s\n", route, msg)
+}
@@ -0,0 +1,120 @@
+/* src/server/core/go/template_debug_test.go */
+
+package seam
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTemplateDebugAnnotatesProvenance(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"route-manifest.json": `{
+			"layouts": {"root": {"template": "templates/root.html", "loaders": {"site": {"procedure": "getSite"}}}},
+			"routes": {"/dash": {"template": "templates/dash.html", "layout": "root", "loaders": {"stats": {"procedure": "getStats"}}}}
+		}`,
+		"templates/root.html": `<!DOCTYPE html><html><head><title><!--seam:site.name--></title></head><body><nav><!--seam:site.name--></nav><!--seam:outlet--></body></html>`,
+		"templates/dash.html": `<p><!--seam:stats.count--></p><p><!--seam:stats.missing--></p>`,
+	})
+	pages, err := LoadBuildOutput(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := NewRouter().
+		Procedure(Query("getSite", func(context.Context, struct{}) (map[string]string, error) {
+			return map[string]string{"name": "Acme"}, nil
+		})).
+		Procedure(Query("getStats", func(context.Context, struct{}) (map[string]int, error) { return map[string]int{"count": 7}, nil })).
+		Page(&pages[0])
+
+	w := httptest.NewRecorder()
+	router.Handler(HandlerOptions{TemplateDebug: true}).ServeHTTP(w, httptest.NewRequest("GET", "/_seam/page/dash", nil))
+	html := w.Body.String()
+	for _, want := range []string{
+		`<!DOCTYPE html><!--seam-debug:layout root (templates/root.html)--><html>`,
+		`<title>Acme</title>`,
+		`<nav><!--seam-slot: site.name <- loader site (procedure getSite)-->Acme</nav>`,
+		`<!--seam-debug:page /dash (templates/dash.html)--><p><!--seam-slot: stats.count <- loader stats (procedure getStats)-->7</p>`,
+		`<!--seam-slot: stats.missing <- no data-->`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("expected %s in\n%s", want, html)
+		}
+	}
+
+	plain := httptest.NewRecorder()
+	router.Handler().ServeHTTP(plain, httptest.NewRequest("GET", "/_seam/page/dash", nil))
+	if strings.Contains(plain.Body.String(), "seam-debug") || strings.Contains(plain.Body.String(), "seam-slot") {
+		t.Fatalf("debug comments without TemplateDebug: %s", plain.Body.String())
+	}
+}
+
+func TestTemplateDebugWarnings(t *testing.T) {
+	s := &appState{}
+	page := &PageDef{Route: "/p", Loaders: []LoaderDef{{DataKey: "user", Procedure: "getUser"}}}
+	tmpl := debugRegion("page /p (routes/p.html)", `<b><!--seam:user.name--></b><i><!--seam:nickname--></i><!--seam:if:user--><!--seam:endif:user-->`)
+	s.annotateSlots(page, tmpl, []byte(`{"user":{"name":"Ada"}}`))
+	s.checkRendered(page, debugRegion("layout root (layouts/root.html)", `<!--seam:bogus:directive-->`))
+
+	var warnings []string
+	s.debugWarned.Range(func(k, _ any) bool {
+		warnings = append(warnings, k.(string))
+		return true
+	})
+	want := map[string]bool{
+		"/p\x00slot \"nickname\" has no data (page /p (routes/p.html))":                         true,
+		"/p\x00unresolved marker <!--seam:bogus:directive--> (layout root (layouts/root.html))": true,
+	}
+	if len(warnings) != len(want) {
+		t.Fatalf("unexpected warnings %q", warnings)
+	}
+	for _, w := range warnings {
+		if !want[w] {
+			t.Errorf("unexpected warning %q", w)
+		}
+	}
+}
+
+func TestStrictTemplates(t *testing.T) {
+	loaders := []LoaderDef{{DataKey: "user", Procedure: "getUser", InputFn: func(map[string]string) any { return map[string]any{} }}}
+	router := NewRouter().
+		Procedure(Query("getUser", func(context.Context, struct{}) (map[string]string, error) {
+			return map[string]string{"name": "Ada", "bio": "<b>hi</b><!--seam:user.missing-->"}, nil
+		})).
+		Page(&PageDef{
+			Route:    "/broken",
+			Template: `<html><body><p><!--seam:user.name--></p><!--seam:user.bio:html--></body></html>`,
+			Loaders:  loaders,
+		}).
+		Page(&PageDef{
+			Route:    "/ok",
+			Template: `<html><body><p><!--seam:user.name--></p></body></html>`,
+			Loaders:  loaders,
+		})
+
+	for _, tc := range []struct {
+		path   string
+		strict bool
+		status int
+	}{
+		{"/_seam/page/broken", true, 500},
+		{"/_seam/page/broken", false, 200},
+		{"/_seam/page/ok", true, 200},
+	} {
+		w := httptest.NewRecorder()
+		router.Handler(HandlerOptions{StrictTemplates: tc.strict}).ServeHTTP(w, httptest.NewRequest("GET", tc.path, nil))
+		if w.Code != tc.status {
+			t.Errorf("%s (strict %v): status %d, want %d: %s", tc.path, tc.strict, w.Code, tc.status, w.Body.String())
+		}
+	}
+}
+
+func TestUnresolvedMarkers(t *testing.T) {
+	got := unresolvedMarkers(`<p><!--seam:a--><!--seam:b:c--><!-- note --><!--seam:a--></p>`)
+	if strings.Join(got, ",") != "a,b:c" {
+		t.Fatalf("got %q", got)
+	}
+}
@@ -0,0 +1,74 @@
+/* src/server/core/go/template_env.go */
+
+package seam
+
+import (
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Templates can read request facts from the reserved _env data key
+// without a loader procedure: <!--seam:_env.path-->, _env.locale,
+// _env.url (canonical, needs HandlerOptions.SiteURL), _env.version, and
+// _env.year. The key is only added for templates that reference it, and
+// stays in the data script so hydration sees the same values.
+type templateEnv struct {
+	Path    string `json:"path"`              // public path, without /_seam/page and query
+	Locale  string `json:"locale,omitempty"`  // resolved locale ("" without i18n)
+	URL     string `json:"url,omitempty"`     // SiteURL + path
+	Version string `json:"version,omitempty"` // HandlerOptions.BuildVersion (or the VCS revision with BuildVersionFromVCS)
+	Year    int    `json:"year"`              // current year (UTC)
+}
+
+// usesTemplateEnv reports whether a slot marker of tmpl reads the _env
+// key; text that merely contains "_env" does not count.
+func usesTemplateEnv(tmpl string) bool {
+	if !strings.Contains(tmpl, "_env") {
+		return false
+	}
+	for _, path := range slotPaths(tmpl) {
+		if head, _, _ := strings.Cut(path, "."); head == "_env" {
+			return true
+		}
+	}
+	return false
+}
+
+// publicPagePath returns the path of a page request without the
+// /_seam/page prefix.
+func publicPagePath(r *http.Request) string {
+	if path := strings.TrimPrefix(r.URL.Path, "/_seam/page"); path != "" {
+		return path
+	}
+	return "/"
+}
+
+func (s *appState) templateEnv(r *http.Request, locale string) templateEnv {
+	path := publicPagePath(r)
+	env := templateEnv{Path: path, Locale: locale, Version: s.opts.BuildVersion, Year: time.Now().UTC().Year()}
+	if s.opts.SiteURL != "" {
+		env.URL = strings.TrimSuffix(s.opts.SiteURL, "/") + path
+	}
+	if env.Version == "" && s.opts.BuildVersionFromVCS {
+		env.Version = vcsRevision()
+	}
+	return env
+}
+
+// vcsRevision returns the short VCS revision stamped into the binary by
+// go build, or "" when absent.
+var vcsRevision = sync.OnceValue(func() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, s := range info.Settings {
+		if s.Key == "vcs.revision" {
+			return s.Value[:min(12, len(s.Value))]
+		}
+	}
+	return ""
+})
@@ -0,0 +1,64 @@
+/* src/server/core/go/template_env_test.go */
+
+package seam
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTemplateEnv(t *testing.T) {
+	router := NewRouter().
+		Page(&PageDef{Route: "/docs/:slug", Template: `<html><body><p><!--seam:_env.path-->|<!--seam:_env.locale-->|<!--seam:_env.url-->|<!--seam:_env.version-->|<!--seam:_env.year--></p></body></html>`}).
+		Page(&PageDef{Route: "/", Template: `<html><body>plain</body></html>`}).
+		I18nConfig(&I18nConfig{Locales: []string{"en", "de"}, Default: "en", Messages: map[string]map[string]json.RawMessage{}}).
+		ResolveStrategies(FromUrlPrefix(), FromUrlQuery("lang"))
+	h := router.Handler(HandlerOptions{SiteURL: "https://example.com/", BuildVersion: "v1.2.3"})
+	get := func(path string) string {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w.Body.String()
+	}
+
+	year := time.Now().UTC().Year()
+	body := get("/_seam/page/docs/intro?lang=de")
+	if want := fmt.Sprintf("<p>/docs/intro|de|https://example.com/docs/intro|v1.2.3|%d</p>", year); !strings.Contains(body, want) {
+		t.Errorf("missing %s in %s", want, body)
+	}
+	if !strings.Contains(body, `"_env":{"locale":"de","path":"/docs/intro"`) {
+		t.Errorf("expected _env in the data script: %s", body)
+	}
+	body = get("/_seam/page/de/docs/intro")
+	if want := "<p>/de/docs/intro|de|https://example.com/de/docs/intro|"; !strings.Contains(body, want) {
+		t.Errorf("missing %s in %s", want, body)
+	}
+
+	if body := get("/_seam/page/"); strings.Contains(body, "_env") {
+		t.Errorf("_env added to a page not using it: %s", body)
+	}
+}
+
+func TestUsesTemplateEnvMatchesMarkers(t *testing.T) {
+	for tmpl, want := range map[string]bool{
+		`<p><!--seam:_env.year--></p>`:                               true,
+		`<!--seam:if:_env.locale-->x<!--seam:endif:_env.locale-->`:   true,
+		`<p><!--seam:_env.year|number--></p>`:                        true,
+		`<code>process_env</code><!--seam:user.my_env-->`:            false,
+		`<script>const API_env = 1</script><!--seam:_envelope.id-->`: false,
+	} {
+		if got := usesTemplateEnv(tmpl); got != want {
+			t.Errorf("usesTemplateEnv(%s) = %v, want %v", tmpl, got, want)
+		}
+	}
+}
+
+func TestTemplateEnvVCSVersionIsOptIn(t *testing.T) {
+	s := &appState{opts: HandlerOptions{}}
+	if v := s.templateEnv(httptest.NewRequest("GET", "/_seam/page/", nil), "").Version; v != "" {
+		t.Errorf("version exposed without opt-in: %q", v)
+	}
+}
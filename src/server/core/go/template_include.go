@@ -0,0 +1,116 @@
+/* src/server/core/go/template_include.go */
+
+package seam
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// includePrefix starts an include directive: <!--seam:include:header-->
+// is replaced at load time by partials/header.html from the build
+// output, so shared fragments are stored once instead of in every route
+// template. Partials may include other partials; cycles fail the load.
+// An overrides directory can shadow a partial at partials/<name>.html.
+const includePrefix = "<!--seam:include:"
+
+// defaultPartialTTL is how long a read partial is reused before it is
+// read again (see TemplateCache.WithPartialTTL).
+const defaultPartialTTL = time.Minute
+
+// partialSet reads and caches the partials of one build output. Entries
+// are read again once older than ttl (never when ttl < 0).
+type partialSet struct {
+	dir       string
+	overrides string
+	ttl       time.Duration
+
+	mu    sync.Mutex
+	files map[string]partialFile
+}
+
+type partialFile struct {
+	body string
+	read time.Time
+}
+
+func newPartialSet(dir, overrides string) *partialSet {
+	return &partialSet{dir: dir, overrides: overrides, ttl: defaultPartialTTL, files: make(map[string]partialFile)}
+}
+
+// invalidate drops every cached partial.
+func (p *partialSet) invalidate() {
+	p.mu.Lock()
+	clear(p.files)
+	p.mu.Unlock()
+}
+
+// expand resolves the include directives of tmpl; stack holds the
+// partials being expanded, for cycle detection. A nil set leaves tmpl
+// unchanged.
+func (p *partialSet) expand(tmpl string, stack []string) (string, error) {
+	if p == nil || !strings.Contains(tmpl, includePrefix) {
+		return tmpl, nil
+	}
+	var b strings.Builder
+	for {
+		i := strings.Index(tmpl, includePrefix)
+		if i < 0 {
+			b.WriteString(tmpl)
+			return b.String(), nil
+		}
+		end := strings.Index(tmpl[i:], "-->")
+		if end < 0 {
+			return "", fmt.Errorf("unterminated include directive")
+		}
+		name := strings.TrimSpace(tmpl[i+len(includePrefix) : i+end])
+		if slices.Contains(stack, name) {
+			return "", fmt.Errorf("include cycle: %s", strings.Join(append(stack, name), " -> "))
+		}
+		body, err := p.load(name)
+		if err != nil {
+			return "", err
+		}
+		expanded, err := p.expand(body, append(slices.Clip(stack), name))
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(tmpl[:i])
+		b.WriteString(expanded)
+		tmpl = tmpl[i+end+len("-->"):]
+	}
+}
+
+// load returns the raw partial, preferring an override.
+func (p *partialSet) load(name string) (string, error) {
+	if name == "" || path.IsAbs(name) || strings.Contains(name, "..") || strings.ContainsAny(name, `\:`) {
+		return "", fmt.Errorf("invalid partial name %q", name)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if f, ok := p.files[name]; ok && (p.ttl < 0 || time.Since(f.read) <= p.ttl) {
+		return f.body, nil
+	}
+	rel := filepath.Join("partials", filepath.FromSlash(name)+".html")
+	var data []byte
+	var err error
+	if p.overrides != "" {
+		if data, err = os.ReadFile(filepath.Join(p.overrides, rel)); err == nil {
+			logf(slog.LevelInfo, "template override: %s\n", filepath.Join(p.overrides, rel))
+		}
+	}
+	if data == nil {
+		if data, err = os.ReadFile(filepath.Join(p.dir, rel)); err != nil {
+			return "", fmt.Errorf("read partial '%s': %w", name, err)
+		}
+	}
+	p.files[name] = partialFile{body: string(data), read: time.Now()}
+	return string(data), nil
+}
@@ -0,0 +1,122 @@
+/* src/server/core/go/template_include_test.go */
+
+package seam
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func includeBuild(t *testing.T, partials map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"route-manifest.json": `{
+			"layouts": {"root": {"template": "templates/root.html"}},
+			"routes": {"/": {"template": "templates/index.html", "layout": "root"}}
+		}`,
+		"templates/root.html":  `<body><!--seam:include:header--><!--seam:outlet--></body>`,
+		"templates/index.html": `<main><!--seam:include:cards/card--> <!--seam:include:cards/card--></main>`,
+	}
+	for name, body := range partials {
+		files["partials/"+name+".html"] = body
+	}
+	writeFiles(t, dir, files)
+	return dir
+}
+
+func TestIncludeDirectives(t *testing.T) {
+	dir := includeBuild(t, map[string]string{
+		"header":     `<header><!--seam:include:nav--></header>`,
+		"nav":        `<nav><!--seam:user.name--></nav>`,
+		"cards/card": `<div class="card"></div>`,
+	})
+	for _, load := range []func() ([]PageDef, error){
+		func() ([]PageDef, error) { return LoadBuildOutput(dir) },
+		func() ([]PageDef, error) { return LoadBuildOutputLazy(dir, "", NewTemplateCache(1<<20)) },
+	} {
+		pages, err := load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		tmpl, err := pages[0].template("")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := `<body><header><nav><!--seam:user.name--></nav></header><main><div class="card"></div> <div class="card"></div></main></body>`
+		if tmpl != want {
+			t.Errorf("template\n got %s\nwant %s", tmpl, want)
+		}
+	}
+
+	overrides := t.TempDir()
+	writeFiles(t, overrides, map[string]string{"partials/nav.html": `<nav>maintenance</nav>`})
+	pages, err := LoadBuildOutputWithOverrides(dir, overrides)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(pages[0].Template, `<nav>maintenance</nav>`) {
+		t.Errorf("override not applied: %s", pages[0].Template)
+	}
+}
+
+func TestIncludeErrors(t *testing.T) {
+	cases := map[string]struct {
+		partials map[string]string
+		want     string
+	}{
+		"cycle": {map[string]string{
+			"header":     `<!--seam:include:nav-->`,
+			"nav":        `<!--seam:include:header-->`,
+			"cards/card": ``,
+		}, "include cycle: header -> nav -> header"},
+		"missing":      {map[string]string{"header": ``}, "read partial 'cards/card'"},
+		"unterminated": {map[string]string{"header": `<!--seam:include:nav`, "cards/card": ``}, "unterminated include directive"},
+		"escape":       {map[string]string{"header": `<!--seam:include:../secret-->`, "cards/card": ``}, `invalid partial name "../secret"`},
+	}
+	for name, tc := range cases {
+		_, err := LoadBuildOutput(includeBuild(t, tc.partials))
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: error %v, want %q", name, err, tc.want)
+		}
+	}
+}
+
+func TestLazyPartialsExpireAndInvalidate(t *testing.T) {
+	dir := includeBuild(t, map[string]string{"header": `<header>v1</header>`, "cards/card": `<i></i>`})
+	cache := NewTemplateCache(1 << 20).WithPartialTTL(20 * time.Millisecond)
+	pages, err := LoadBuildOutputLazy(dir, "", cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	render := func() string {
+		tmpl, err := pages[0].template("")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tmpl
+	}
+	edit := func(files map[string]string) { writeFiles(t, dir, files) }
+
+	// The route includes cards/card; the layout's header is resolved at load
+	render()
+	edit(map[string]string{"partials/cards/card.html": `<b></b>`})
+	if got := render(); !strings.Contains(got, "<i></i>") {
+		t.Fatalf("partial reread before its TTL: %s", got)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if got := render(); !strings.Contains(got, "<b></b> <b></b>") {
+		t.Fatalf("partial not reread after its TTL: %s", got)
+	}
+
+	cache.WithPartialTTL(-1)
+	edit(map[string]string{"partials/cards/card.html": `<u></u>`})
+	if got := render(); !strings.Contains(got, "<b></b>") {
+		t.Fatalf("partial reread without a TTL: %s", got)
+	}
+	cache.Invalidate()
+	if got := render(); !strings.Contains(got, "<u></u> <u></u>") {
+		t.Fatalf("partial not reread after Invalidate: %s", got)
+	}
+}
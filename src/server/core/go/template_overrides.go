@@ -0,0 +1,82 @@
+/* src/server/core/go/template_overrides.go */
+
+package seam
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// templateSource reads build templates, letting an operator overlay
+// directory shadow them without a frontend rebuild. Overrides are matched
+// by id rather than build path (which carries content hashes):
+//
+//	layouts/<layoutId>.html           routes/<route>.html
+//	layouts/<layoutId>.<locale>.html  routes/<route>.<locale>.html
+//
+// where <route> is the route path without the leading slash, "index" for
+// "/", and ":param" segments written as "[param]" (e.g. "users/[id]").
+// A locale-specific override wins over the generic one. Layout error
+// boundary fragments use the id "<layoutId>.error". Include directives
+// are expanded from partials (see template_include.go).
+type templateSource struct {
+	dir       string
+	overrides string
+	partials  *partialSet
+}
+
+func (t templateSource) read(kind, id, locale, relPath string) ([]byte, error) {
+	data, _, err := t.readIncludes(kind, id, locale, relPath)
+	return data, err
+}
+
+// readIncludes reads a template with its include directives expanded,
+// reporting whether it had any.
+func (t templateSource) readIncludes(kind, id, locale, relPath string) ([]byte, bool, error) {
+	data, err := t.readFile(kind, id, locale, relPath)
+	if err != nil || t.partials == nil || !strings.Contains(string(data), includePrefix) {
+		return data, false, err
+	}
+	expanded, err := t.partials.expand(string(data), nil)
+	if err != nil {
+		return nil, false, err
+	}
+	return []byte(expanded), true, nil
+}
+
+func (t templateSource) readFile(kind, id, locale, relPath string) ([]byte, error) {
+	if t.overrides != "" {
+		base := filepath.Join(t.overrides, kind, filepath.FromSlash(overrideName(kind, id)))
+		candidates := []string{base + ".html"}
+		if locale != "" {
+			candidates = []string{base + "." + locale + ".html", base + ".html"}
+		}
+		for _, path := range candidates {
+			if data, err := os.ReadFile(path); err == nil {
+				logf(slog.LevelInfo, "template override: %s\n", path)
+				return data, nil
+			}
+		}
+	}
+	return os.ReadFile(filepath.Join(t.dir, relPath))
+}
+
+// overrideName maps a layout id or route path to its overlay file stem.
+func overrideName(kind, id string) string {
+	if kind != "routes" {
+		return id
+	}
+	route := strings.Trim(id, "/")
+	if route == "" {
+		return "index"
+	}
+	segments := strings.Split(route, "/")
+	for i, seg := range segments {
+		if strings.HasPrefix(seg, ":") {
+			segments[i] = "[" + seg[1:] + "]"
+		}
+	}
+	return strings.Join(segments, "/")
+}
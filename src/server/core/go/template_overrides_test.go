@@ -0,0 +1,81 @@
+/* src/server/core/go/template_overrides_test.go */
+
+package seam
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestOverrideName(t *testing.T) {
+	cases := map[string]string{"/": "index", "/about": "about", "/users/:id": "users/[id]"}
+	for route, want := range cases {
+		if got := overrideName("routes", route); got != want {
+			t.Errorf("overrideName(%q) = %q, want %q", route, got, want)
+		}
+	}
+	if got := overrideName("layouts", "_layout_root"); got != "_layout_root" {
+		t.Errorf("layout id should pass through, got %q", got)
+	}
+}
+
+func TestLoadBuildOutputWithOverrides(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"route-manifest.json": `{
+			"layouts": {"root": {"template": "templates/root.a1.html"}},
+			"routes": {
+				"/": {"template": "templates/index.b2.html", "layout": "root"},
+				"/users/:id": {"template": "templates/user.c3.html", "layout": "root"}
+			}
+		}`,
+		"templates/root.a1.html":  `<body>ROOT<!--seam:outlet--></body>`,
+		"templates/index.b2.html": `<p>home</p>`,
+		"templates/user.c3.html":  `<p>user</p>`,
+	})
+	overrides := t.TempDir()
+	writeFiles(t, overrides, map[string]string{
+		"layouts/root.html":      `<body><div>Scheduled maintenance</div><!--seam:outlet--></body>`,
+		"routes/users/[id].html": `<p>user (patched)</p>`,
+	})
+
+	pages, err := LoadBuildOutputWithOverrides(dir, overrides)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byRoute := make(map[string]string)
+	for _, p := range pages {
+		byRoute[p.Route] = p.Template
+	}
+	if got := byRoute["/"]; got != `<body><div>Scheduled maintenance</div><p>home</p></body>` {
+		t.Errorf("layout override not applied: %q", got)
+	}
+	if got := byRoute["/users/:id"]; !strings.Contains(got, "user (patched)") || !strings.Contains(got, "Scheduled maintenance") {
+		t.Errorf("route override not applied: %q", got)
+	}
+
+	plain, err := LoadBuildOutput(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range plain {
+		if strings.Contains(p.Template, "maintenance") || strings.Contains(p.Template, "patched") {
+			t.Errorf("overrides leaked into plain load: %q", p.Template)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+/* src/server/core/go/timestamp.go */
+
+package seam
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// TimezoneContextKey is the context key read by TimezoneOf: map it to the
+// client's IANA zone (e.g. ContextConfig{Extract: "header:x-timezone"})
+// and declare it with WithProcedureContext(TimezoneContextKey).
+const TimezoneContextKey = "timezone"
+
+// Timestamp is a time.Time that always serializes as an RFC 3339 string
+// in its location, with schema {"type": "timestamp"}. Build one with
+// UTCTime or RequesterTime instead of returning epoch numbers or
+// locally formatted strings, so every backend emits the same shape.
+type Timestamp struct {
+	time.Time
+}
+
+// UTCTime returns t as a UTC Timestamp.
+func UTCTime(t time.Time) Timestamp {
+	return Timestamp{t.UTC()}
+}
+
+// RequesterTime returns t in the requester's timezone (see TimezoneOf).
+func RequesterTime(ctx context.Context, t time.Time) Timestamp {
+	return Timestamp{t.In(TimezoneOf(ctx))}
+}
+
+// MarshalJSON writes the time as an RFC 3339 string with nanoseconds
+// trimmed of trailing zeros.
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + t.Format(time.RFC3339Nano) + `"`), nil
+}
+
+// UnmarshalJSON accepts an RFC 3339 string, keeping its offset.
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
+		return fmt.Errorf("timestamp: expected RFC 3339 string, got %s", data)
+	}
+	parsed, err := time.Parse(time.RFC3339Nano, string(data[1:len(data)-1]))
+	if err != nil {
+		return fmt.Errorf("timestamp: %w", err)
+	}
+	t.Time = parsed
+	return nil
+}
+
+var timezones sync.Map // IANA name -> *time.Location
+
+// TimezoneOf returns the requester's timezone from the TimezoneContextKey
+// context value, or UTC when it is absent or not a known IANA zone.
+func TimezoneOf(ctx context.Context) *time.Location {
+	name, ok := ContextValue[string](ctx, TimezoneContextKey)
+	if !ok || name == "" || name == "Local" {
+		return time.UTC
+	}
+	if loc, ok := timezones.Load(name); ok {
+		return loc.(*time.Location)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	timezones.Store(name, loc)
+	return loc
+}
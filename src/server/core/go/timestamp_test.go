@@ -0,0 +1,65 @@
+/* src/server/core/go/timestamp_test.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTimestampJSON(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 30, 0, 500_000_000, time.UTC)
+	raw, err := json.Marshal(struct {
+		At Timestamp `json:"at"`
+	}{UTCTime(at.In(time.FixedZone("X", 3600)))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(raw) != `{"at":"2026-03-01T12:30:00.5Z"}` {
+		t.Fatalf("unexpected json %s", raw)
+	}
+
+	var back Timestamp
+	if err := json.Unmarshal([]byte(`"2026-03-01T13:30:00.5+01:00"`), &back); err != nil {
+		t.Fatal(err)
+	}
+	if !back.Equal(at) {
+		t.Fatalf("round trip mismatch: %v", back)
+	}
+	if err := json.Unmarshal([]byte(`1772368200`), &back); err == nil {
+		t.Fatal("expected epoch numbers to be rejected")
+	}
+}
+
+func TestRequesterTime(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	ctx := injectContext(context.Background(), map[string]any{TimezoneContextKey: "Asia/Tokyo"})
+	if got := RequesterTime(ctx, at).Format(time.RFC3339); got != "2026-03-01T21:00:00+09:00" {
+		t.Fatalf("unexpected requester time %s", got)
+	}
+	for _, zone := range []any{nil, "Not/AZone", "Local"} {
+		ctx := injectContext(context.Background(), map[string]any{TimezoneContextKey: zone})
+		if loc := TimezoneOf(ctx); loc != time.UTC {
+			t.Errorf("TimezoneOf(%v) = %v, want UTC", zone, loc)
+		}
+	}
+}
+
+func TestTimestampSchema(t *testing.T) {
+	type Event struct {
+		At      time.Time  `json:"at"`
+		Created Timestamp  `json:"created"`
+		Ended   *Timestamp `json:"ended"`
+	}
+	want := map[string]any{"properties": map[string]any{
+		"at":      map[string]any{"type": "timestamp"},
+		"created": map[string]any{"type": "timestamp"},
+		"ended":   map[string]any{"type": "timestamp", "nullable": true},
+	}}
+	if got := SchemaOf[Event](); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected schema %v", got)
+	}
+}
@@ -0,0 +1,248 @@
+/* src/server/core/go/tuning.go */
+
+package seam
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Tuning holds settings operators can change at runtime, within Bounds,
+// through its admin procedure (see Procedure) to react to incidents
+// without redeploying. Pass the same Tuning as HandlerOptions.Tuning; the
+// RPC timeout and layout cache TTL start from the handler options.
+type Tuning struct {
+	// Authorize admits callers of the admin procedure, e.g. by checking
+	// PrincipalOf(ctx) against an operator list; nil rejects every call.
+	Authorize func(ctx context.Context) error
+	// Bounds limits the accepted values (zero fields use the defaults).
+	Bounds TuningBounds
+	// Level is the adjustable log level (default info). It filters seam's
+	// own log lines once a handler is built with this Tuning; hand it to
+	// the application's slog handler too: &slog.HandlerOptions{Level: &t.Level}.
+	Level slog.LevelVar
+
+	initOnce    sync.Once
+	mu          sync.Mutex // serializes updates
+	rpcTimeout  atomic.Int64
+	rateLimit   atomic.Int64
+	layoutTTL   atomic.Int64
+	queryMaxAge atomic.Int64
+	limiter     rateWindow
+}
+
+// TuningBounds limits tuned values. Zero fields use the defaults.
+type TuningBounds struct {
+	MinRPCTimeout     time.Duration // default 1s
+	MaxRPCTimeout     time.Duration // default 5m
+	MaxRateLimit      int64         // calls per minute (default 100000)
+	MaxLayoutCacheTTL time.Duration // default 1h
+	MaxQueryCacheAge  int64         // seconds (default 86400)
+}
+
+// TuningSettings is the current state reported by the admin procedure.
+type TuningSettings struct {
+	LogLevel         string `json:"logLevel"`
+	RPCTimeoutMs     int64  `json:"rpcTimeoutMs"`     // 0 = no timeout
+	RateLimit        int64  `json:"rateLimit"`        // calls per minute per principal or client IP; 0 = off
+	LayoutCacheTTLMs int64  `json:"layoutCacheTtlMs"` // new layout cache entries; 0 = not cached
+	QueryCacheMaxAge int64  `json:"queryCacheMaxAge"` // cap on query Cache-Control max-age in seconds; -1 = none
+}
+
+// TuningUpdate is the admin procedure input; absent fields are unchanged,
+// so an empty update reads the settings.
+type TuningUpdate struct {
+	LogLevel         *string `json:"logLevel,omitempty"` // debug, info, warn, or error
+	RPCTimeoutMs     *int64  `json:"rpcTimeoutMs,omitempty"`
+	RateLimit        *int64  `json:"rateLimit,omitempty"`
+	LayoutCacheTTLMs *int64  `json:"layoutCacheTtlMs,omitempty"`
+	QueryCacheMaxAge *int64  `json:"queryCacheMaxAge,omitempty"`
+}
+
+// init seeds the tuned values from the first handler's options and makes
+// Level the level of seam's own logging.
+func (t *Tuning) init(opts HandlerOptions) {
+	logLevel.Store(&t.Level)
+	t.initOnce.Do(func() {
+		t.rpcTimeout.Store(int64(opts.RPCTimeout))
+		t.layoutTTL.Store(int64(opts.LayoutCacheTTL))
+		t.queryMaxAge.Store(-1)
+	})
+}
+
+// Settings returns the current settings.
+func (t *Tuning) Settings() TuningSettings {
+	return TuningSettings{
+		LogLevel:         strings.ToLower(t.Level.Level().String()),
+		RPCTimeoutMs:     time.Duration(t.rpcTimeout.Load()).Milliseconds(),
+		RateLimit:        t.rateLimit.Load(),
+		LayoutCacheTTLMs: time.Duration(t.layoutTTL.Load()).Milliseconds(),
+		QueryCacheMaxAge: t.queryMaxAge.Load(),
+	}
+}
+
+// Update checks every field of u against Bounds, then applies them all
+// and logs the changes; on a VALIDATION_ERROR nothing changes.
+func (t *Tuning) Update(ctx context.Context, u TuningUpdate) (TuningSettings, error) {
+	b := t.bounds()
+	var level slog.Level
+	if u.LogLevel != nil {
+		if err := level.UnmarshalText([]byte(*u.LogLevel)); err != nil {
+			return TuningSettings{}, ValidationError(fmt.Sprintf("logLevel %q is not one of debug, info, warn, error", *u.LogLevel))
+		}
+	}
+	if v := u.RPCTimeoutMs; v != nil && (*v < b.MinRPCTimeout.Milliseconds() || *v > b.MaxRPCTimeout.Milliseconds()) {
+		return TuningSettings{}, outOfBounds("rpcTimeoutMs", *v, b.MinRPCTimeout.Milliseconds(), b.MaxRPCTimeout.Milliseconds())
+	}
+	if v := u.RateLimit; v != nil && (*v < 0 || *v > b.MaxRateLimit) {
+		return TuningSettings{}, outOfBounds("rateLimit", *v, 0, b.MaxRateLimit)
+	}
+	if v := u.LayoutCacheTTLMs; v != nil && (*v < 0 || *v > b.MaxLayoutCacheTTL.Milliseconds()) {
+		return TuningSettings{}, outOfBounds("layoutCacheTtlMs", *v, 0, b.MaxLayoutCacheTTL.Milliseconds())
+	}
+	if v := u.QueryCacheMaxAge; v != nil && (*v < -1 || *v > b.MaxQueryCacheAge) {
+		return TuningSettings{}, outOfBounds("queryCacheMaxAge", *v, -1, b.MaxQueryCacheAge)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	before := t.Settings()
+	if u.LogLevel != nil {
+		t.Level.Set(level)
+	}
+	if u.RPCTimeoutMs != nil {
+		t.rpcTimeout.Store(int64(time.Duration(*u.RPCTimeoutMs) * time.Millisecond))
+	}
+	if u.RateLimit != nil {
+		t.rateLimit.Store(*u.RateLimit)
+	}
+	if u.LayoutCacheTTLMs != nil {
+		t.layoutTTL.Store(int64(time.Duration(*u.LayoutCacheTTLMs) * time.Millisecond))
+	}
+	if u.QueryCacheMaxAge != nil {
+		t.queryMaxAge.Store(*u.QueryCacheMaxAge)
+	}
+	after := t.Settings()
+	if after != before {
+		who := PrincipalOf(ctx)
+		if who == "" {
+			who = "anonymous"
+		}
+		logf(slog.LevelInfo, "tuning changed by %s: %+v -> %+v\n", who, before, after)
+	}
+	return after, nil
+}
+
+// Procedure returns the admin command that applies a TuningUpdate and
+// returns the resulting settings. Callers must pass Authorize; others
+// get FORBIDDEN.
+func (t *Tuning) Procedure(name string) *ProcedureDef {
+	return Command(name, func(ctx context.Context, in TuningUpdate) (TuningSettings, error) {
+		if t.Authorize == nil {
+			return TuningSettings{}, ForbiddenError("Tuning is not enabled for any caller")
+		}
+		if err := t.Authorize(ctx); err != nil {
+			if seamErr, ok := err.(*Error); ok {
+				return TuningSettings{}, seamErr
+			}
+			return TuningSettings{}, ForbiddenError(err.Error())
+		}
+		return t.Update(ctx, in)
+	})
+}
+
+func (t *Tuning) bounds() TuningBounds {
+	b := t.Bounds
+	if b.MinRPCTimeout <= 0 {
+		b.MinRPCTimeout = time.Second
+	}
+	if b.MaxRPCTimeout <= 0 {
+		b.MaxRPCTimeout = 5 * time.Minute
+	}
+	if b.MaxRateLimit <= 0 {
+		b.MaxRateLimit = 100000
+	}
+	if b.MaxLayoutCacheTTL <= 0 {
+		b.MaxLayoutCacheTTL = time.Hour
+	}
+	if b.MaxQueryCacheAge <= 0 {
+		b.MaxQueryCacheAge = 86400
+	}
+	return b
+}
+
+func outOfBounds(field string, v, lo, hi int64) *Error {
+	return ValidationError(fmt.Sprintf("%s %d is outside [%d, %d]", field, v, lo, hi))
+}
+
+// rpcTimeoutOr returns the tuned RPC timeout, or def without a Tuning.
+func (t *Tuning) rpcTimeoutOr(def time.Duration) time.Duration {
+	if t == nil {
+		return def
+	}
+	return time.Duration(t.rpcTimeout.Load())
+}
+
+// layoutTTLOr returns the tuned layout cache TTL, or def without a Tuning.
+func (t *Tuning) layoutTTLOr(def time.Duration) time.Duration {
+	if t == nil {
+		return def
+	}
+	return time.Duration(t.layoutTTL.Load())
+}
+
+// queryMaxAgeOf caps a cacheable query's max-age.
+func (t *Tuning) queryMaxAgeOf(ttl int) int {
+	if t == nil {
+		return ttl
+	}
+	if limit := t.queryMaxAge.Load(); limit >= 0 && int64(ttl) > limit {
+		return int(limit)
+	}
+	return ttl
+}
+
+// checkRate rejects calls beyond the tuned per-minute rate limit of the
+// caller (principal, else client IP).
+func (t *Tuning) checkRate(ctx context.Context) *Error {
+	if t == nil {
+		return nil
+	}
+	limit := t.rateLimit.Load()
+	if limit <= 0 {
+		return nil
+	}
+	key := PrincipalOf(ctx)
+	if key == "" {
+		key = "ip:" + ClientIP(ctx)
+	}
+	if count, end := t.limiter.add(key); count > limit {
+		e := RateLimitedError(fmt.Sprintf("Rate limit of %d calls per minute exceeded", limit))
+		e.Transient = true
+		e.RetryAfter = time.Until(end)
+		return e
+	}
+	return nil
+}
+
+// rateWindow counts calls per key in fixed one-minute windows.
+type rateWindow struct {
+	mu     sync.Mutex
+	start  time.Time
+	counts map[string]int64
+}
+
+func (rw *rateWindow) add(key string) (int64, time.Time) {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	if start := time.Now().Truncate(time.Minute); !start.Equal(rw.start) || rw.counts == nil {
+		rw.start, rw.counts = start, make(map[string]int64)
+	}
+	rw.counts[key]++
+	return rw.counts[key], rw.start.Add(time.Minute)
+}
@@ -0,0 +1,126 @@
+/* src/server/core/go/tuning_test.go */
+
+package seam
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func tuningRouter(tuning *Tuning) http.Handler {
+	return NewRouter().
+		Procedure(&ProcedureDef{Name: "slow", Handler: slowHandler(200 * time.Millisecond)}).
+		Procedure(&ProcedureDef{Name: "getRepos", Handler: echoHandler(), Cache: map[string]any{"ttl": 300}}).
+		Procedure(tuning.Procedure("admin.tune")).
+		Handler(HandlerOptions{
+			RPCTimeout: 30 * time.Second,
+			Tuning:     tuning,
+			Principal:  func(r *http.Request) string { return r.Header.Get("X-Api-Key") },
+		})
+}
+
+func operatorsOnly(ctx context.Context) error {
+	if PrincipalOf(ctx) != "ops" {
+		return ForbiddenError("operators only")
+	}
+	return nil
+}
+
+func TestTuningProcedure(t *testing.T) {
+	tuning := &Tuning{Authorize: operatorsOnly}
+	h := tuningRouter(tuning)
+	ops := http.Header{"X-Api-Key": {"ops"}}
+
+	if code, body := rpcBody(h, "/_seam/procedure/admin.tune", `{}`, http.Header{"X-Api-Key": {"user"}}); code != http.StatusForbidden {
+		t.Fatalf("non-operator: %d %s", code, body)
+	}
+	code, body := rpcBody(h, "/_seam/procedure/admin.tune", `{}`, ops)
+	if code != http.StatusOK || !strings.Contains(body, `"rpcTimeoutMs":30000`) || !strings.Contains(body, `"logLevel":"info"`) {
+		t.Fatalf("read: %d %s", code, body)
+	}
+
+	// Out-of-bounds updates change nothing
+	code, body = rpcBody(h, "/_seam/procedure/admin.tune", `{"logLevel":"debug","rpcTimeoutMs":1}`, ops)
+	if code != http.StatusBadRequest || !strings.Contains(body, "rpcTimeoutMs 1 is outside [1000, 300000]") {
+		t.Fatalf("out of bounds: %d %s", code, body)
+	}
+	if tuning.Level.Level() != slog.LevelInfo {
+		t.Fatal("rejected update applied the log level")
+	}
+
+	code, body = rpcBody(h, "/_seam/procedure/admin.tune", `{"logLevel":"debug","rpcTimeoutMs":1000,"queryCacheMaxAge":10}`, ops)
+	if code != http.StatusOK || !strings.Contains(body, `"logLevel":"debug"`) || !strings.Contains(body, `"queryCacheMaxAge":10`) {
+		t.Fatalf("update: %d %s", code, body)
+	}
+	if tuning.Level.Level() != slog.LevelDebug {
+		t.Errorf("log level %v", tuning.Level.Level())
+	}
+}
+
+func TestTuningAppliesToHandler(t *testing.T) {
+	tuning := &Tuning{Authorize: operatorsOnly}
+	h := tuningRouter(tuning)
+	if code, body := rpcBody(h, "/_seam/procedure/slow", `{}`, nil); code != http.StatusOK {
+		t.Fatalf("slow before tuning: %d %s", code, body)
+	}
+	limit, maxAge := int64(2), int64(10)
+	if _, err := tuning.Update(context.Background(), TuningUpdate{RateLimit: &limit, QueryCacheMaxAge: &maxAge}); err != nil {
+		t.Fatal(err)
+	}
+	tuning.rpcTimeout.Store(int64(50 * time.Millisecond)) // below the bounds, for test speed
+	if code, body := rpcBody(h, "/_seam/procedure/slow", `{}`, nil); code != http.StatusGatewayTimeout {
+		t.Fatalf("tuned timeout: %d %s", code, body)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/_seam/procedure/getRepos", strings.NewReader(`{}`))
+	req.Header.Set("X-Api-Key", "partner")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if cc := rec.Header().Get("Cache-Control"); !strings.Contains(cc, "max-age=10") {
+		t.Errorf("Cache-Control %q, want max-age capped to 10", cc)
+	}
+	partner := http.Header{"X-Api-Key": {"partner"}}
+	if code, _ := rpcBody(h, "/_seam/procedure/getRepos", `{}`, partner); code != http.StatusOK {
+		t.Fatalf("second call got %d", code)
+	}
+	code, body := rpcBody(h, "/_seam/procedure/getRepos", `{}`, partner)
+	if code != http.StatusTooManyRequests || !strings.Contains(body, "Rate limit of 2 calls per minute exceeded") {
+		t.Fatalf("over rate limit: %d %s", code, body)
+	}
+}
+
+func TestTuningWithoutAuthorize(t *testing.T) {
+	h := tuningRouter(&Tuning{})
+	if code, body := rpcBody(h, "/_seam/procedure/admin.tune", `{}`, http.Header{"X-Api-Key": {"ops"}}); code != http.StatusForbidden {
+		t.Fatalf("got %d %s", code, body)
+	}
+}
+
+func TestTuningLevelFiltersSeamLogs(t *testing.T) {
+	defer logLevel.Store(nil)
+	tuning := &Tuning{}
+	tuningRouter(tuning)
+	tuning.Level.Set(slog.LevelError)
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = w
+	logf(slog.LevelWarn, "dropped\n")
+	logf(slog.LevelError, "kept\n")
+	os.Stderr = stderr
+	_ = w.Close()
+	out, _ := io.ReadAll(r)
+	if string(out) != "[seam] kept\n" {
+		t.Fatalf("log output %q", out)
+	}
+}
@@ -0,0 +1,122 @@
+/* src/server/core/go/upstream.go */
+
+package seam
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// UpstreamError classifies a failed call to an upstream HTTP service into
+// a seam error carrying client guidance. Pass the response and error of
+// the call; it returns nil for a successful (< 400) response.
+//
+//   - timeouts -> UNAVAILABLE (504), transient
+//   - network failures -> UNAVAILABLE (503), transient
+//   - 429, or 403 with X-RateLimit-Remaining: 0 (GitHub) -> RATE_LIMITED (429), transient
+//   - 5xx -> UNAVAILABLE (502, or the upstream 503/504), transient
+//   - 404 -> NOT_FOUND, 409 -> CONFLICT
+//   - other 4xx -> INTERNAL_ERROR: the upstream rejected our request
+//
+// RetryAfter is taken from Retry-After (seconds or HTTP date) or, for
+// rate limits, X-RateLimit-Reset (Unix seconds). Upstream bodies are not
+// echoed to the client.
+func UpstreamError(service string, resp *http.Response, err error) *Error {
+	if err != nil {
+		switch {
+		case isTimeout(err):
+			return &Error{Code: "UNAVAILABLE", Message: service + " timed out", Status: http.StatusGatewayTimeout, Transient: true}
+		case errors.Is(err, context.Canceled):
+			return InternalError(service + " call canceled")
+		default:
+			return &Error{Code: "UNAVAILABLE", Message: service + " is unreachable", Status: http.StatusServiceUnavailable, Transient: true}
+		}
+	}
+	if resp == nil || resp.StatusCode < 400 {
+		return nil
+	}
+
+	status := resp.StatusCode
+	switch {
+	case status == http.StatusTooManyRequests ||
+		(status == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0"):
+		e := RateLimitedError(service + " rate limit exceeded")
+		e.Transient = true
+		e.RetryAfter = upstreamRetryAfter(resp.Header, true)
+		return e
+	case status >= 500:
+		e := &Error{Code: "UNAVAILABLE", Message: fmt.Sprintf("%s returned %d", service, status), Status: http.StatusBadGateway, Transient: true}
+		if status == http.StatusServiceUnavailable || status == http.StatusGatewayTimeout {
+			e.Status = status
+		}
+		e.RetryAfter = upstreamRetryAfter(resp.Header, false)
+		return e
+	case status == http.StatusNotFound:
+		return NotFoundError(service + " resource not found")
+	case status == http.StatusConflict:
+		return &Error{Code: "CONFLICT", Message: service + " reported a conflict", Status: http.StatusConflict}
+	default:
+		return InternalError(fmt.Sprintf("%s rejected the request with %d", service, status))
+	}
+}
+
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// upstreamRetryAfter reads the wait hinted by an upstream response.
+func upstreamRetryAfter(h http.Header, rateLimited bool) time.Duration {
+	if v := h.Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+			return time.Duration(secs) * time.Second
+		}
+		if at, err := http.ParseTime(v); err == nil {
+			return max(time.Until(at), 0)
+		}
+	}
+	if rateLimited {
+		if reset, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return max(time.Until(time.Unix(reset, 0)), 0)
+		}
+	}
+	return 0
+}
+
+// withRetryHint returns e, or a copy carrying HandlerOptions.DefaultRetryAfter
+// when it is set and e is a rate limit, unavailability, or timeout (504)
+// error without RetryAfter.
+func (s *appState) withRetryHint(e *Error) *Error {
+	hint := s.opts.DefaultRetryAfter
+	if e == nil || hint <= 0 || e.RetryAfter > 0 {
+		return e
+	}
+	if e.Code != "RATE_LIMITED" && e.Code != "UNAVAILABLE" && errorHTTPStatus(e) != http.StatusGatewayTimeout {
+		return e
+	}
+	hinted := *e
+	hinted.RetryAfter = hint
+	return &hinted
+}
+
+// retryAfterSeconds renders RetryAfter in whole seconds, rounded up (0
+// when unset), for the Retry-After header.
+func (e *Error) retryAfterSeconds() int {
+	if e.RetryAfter <= 0 {
+		return 0
+	}
+	return int((e.RetryAfter + time.Second - 1) / time.Second)
+}
+
+// retryAfterMs renders RetryAfter in milliseconds for the error envelope.
+func (e *Error) retryAfterMs() int64 {
+	return e.RetryAfter.Milliseconds()
+}
@@ -0,0 +1,143 @@
+/* src/server/core/go/upstream_test.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUpstreamErrorClassification(t *testing.T) {
+	header := func(kv ...string) http.Header {
+		h := http.Header{}
+		for i := 0; i < len(kv); i += 2 {
+			h.Set(kv[i], kv[i+1])
+		}
+		return h
+	}
+	reset := strconv.FormatInt(time.Now().Add(90*time.Second).Unix(), 10)
+	cases := []struct {
+		name       string
+		resp       *http.Response
+		err        error
+		code       string
+		status     int
+		transient  bool
+		retryAfter int
+	}{
+		{"ok", &http.Response{StatusCode: 200}, nil, "", 0, false, 0},
+		{"429", &http.Response{StatusCode: 429, Header: header("Retry-After", "30")}, nil, "RATE_LIMITED", 429, true, 30},
+		{"github 403", &http.Response{StatusCode: 403, Header: header("X-RateLimit-Remaining", "0", "X-RateLimit-Reset", reset)}, nil, "RATE_LIMITED", 429, true, 90},
+		{"plain 403", &http.Response{StatusCode: 403, Header: header()}, nil, "INTERNAL_ERROR", 500, false, 0},
+		{"503", &http.Response{StatusCode: 503, Header: header("Retry-After", "5")}, nil, "UNAVAILABLE", 503, true, 5},
+		{"500", &http.Response{StatusCode: 500, Header: header()}, nil, "UNAVAILABLE", 502, true, 0},
+		{"404", &http.Response{StatusCode: 404, Header: header()}, nil, "NOT_FOUND", 404, false, 0},
+		{"timeout", nil, context.DeadlineExceeded, "UNAVAILABLE", 504, true, 0},
+		{"refused", nil, errors.New("dial tcp: connection refused"), "UNAVAILABLE", 503, true, 0},
+	}
+	for _, c := range cases {
+		e := UpstreamError("github", c.resp, c.err)
+		if c.code == "" {
+			if e != nil {
+				t.Errorf("%s: expected nil, got %v", c.name, e)
+			}
+			continue
+		}
+		if e == nil || e.Code != c.code || errorHTTPStatus(e) != c.status || e.Transient != c.transient {
+			t.Errorf("%s: got %+v", c.name, e)
+			continue
+		}
+		// X-RateLimit-Reset is second-granular; allow one second of drift
+		if got := e.retryAfterSeconds(); got < c.retryAfter-1 || got > c.retryAfter {
+			t.Errorf("%s: retryAfter %d, want %d", c.name, got, c.retryAfter)
+		}
+	}
+}
+
+func TestUpstreamRateLimitReachesClient(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Retry-After", "42")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer upstream.Close()
+
+	router := NewRouter().Procedure(Query("getUser", func(ctx context.Context, _ struct{}) (string, error) {
+		resp, err := http.Get(upstream.URL)
+		if err == nil {
+			defer resp.Body.Close()
+		}
+		if e := UpstreamError("github", resp, err); e != nil {
+			return "", e
+		}
+		return "canmi", nil
+	}))
+	req := httptest.NewRequest("POST", "/_seam/procedure/getUser", strings.NewReader(`{}`))
+	w := httptest.NewRecorder()
+	router.Handler().ServeHTTP(w, req)
+
+	want := `{"error":{"code":"RATE_LIMITED","message":"github rate limit exceeded","retryAfterMs":42000,"transient":true},"ok":false}`
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "42" {
+		t.Fatalf("got %d Retry-After %q", w.Code, w.Header().Get("Retry-After"))
+	}
+	if got := w.Body.String(); got != want+"\n" && got != want {
+		t.Fatalf("got %s", got)
+	}
+}
+
+func TestRetryAfterMsInEnvelopes(t *testing.T) {
+	limited := RateLimitedError("slow down")
+	limited.RetryAfter = 1500 * time.Millisecond
+	h := NewRouter().
+		Procedure(&ProcedureDef{Name: "slow", Handler: slowHandler(time.Second)}).
+		Procedure(&ProcedureDef{Name: "limited", Handler: func(context.Context, json.RawMessage) (any, error) { return nil, limited }}).
+		Procedure(&ProcedureDef{Name: "missing", Handler: func(context.Context, json.RawMessage) (any, error) { return nil, NotFoundError("gone") }}).
+		Subscription(&SubscriptionDef{Name: "feed", Handler: func(context.Context, json.RawMessage) (<-chan SubscriptionEvent, error) {
+			ch := make(chan SubscriptionEvent, 1)
+			ch <- SubscriptionEvent{Err: NewError("UNAVAILABLE", "feed paused", 0)}
+			close(ch)
+			return ch, nil
+		}}).
+		RpcHashMap(&RpcHashMap{Batch: "_batch", Procedures: map[string]string{"slow": "slow", "limited": "limited", "missing": "missing"}}).
+		Handler(HandlerOptions{RPCTimeout: 20 * time.Millisecond, DefaultRetryAfter: time.Second})
+
+	// Timeouts suggest the default backoff
+	if code, body := rpcBody(h, "/_seam/procedure/slow", `{}`, nil); code != http.StatusGatewayTimeout || !strings.Contains(body, `"retryAfterMs":1000`) {
+		t.Errorf("timeout: %d %s", code, body)
+	}
+	if _, body := rpcBody(h, "/_seam/procedure/limited", `{}`, nil); !strings.Contains(body, `"retryAfterMs":1500`) || strings.Contains(body, `"retryAfter":`) {
+		t.Errorf("rate limited: %s", body)
+	}
+	if _, body := rpcBody(h, "/_seam/procedure/missing", `{}`, nil); strings.Contains(body, "retryAfter") {
+		t.Errorf("NOT_FOUND carries a retry hint: %s", body)
+	}
+	_, body := rpcBody(h, "/_seam/procedure/_batch", `{"calls":[{"procedure":"limited","input":{}},{"procedure":"missing","input":{}}]}`, nil)
+	if !strings.Contains(body, `"retryAfterMs":1500`) || strings.Contains(body, `"retryAfter":`) || strings.Count(body, "retryAfterMs") != 1 {
+		t.Errorf("batch: %s", body)
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/_seam/procedure/feed", http.NoBody))
+	if !strings.Contains(w.Body.String(), `"retryAfterMs":1000`) {
+		t.Errorf("SSE error event: %s", w.Body.String())
+	}
+
+	if ws := toWsError(limited); ws.RetryAfterMs != 1500 {
+		t.Errorf("ws error %+v", ws)
+	}
+}
+
+func TestRetryHintIsOptIn(t *testing.T) {
+	h := NewRouter().
+		Procedure(&ProcedureDef{Name: "slow", Handler: slowHandler(time.Second)}).
+		Handler(HandlerOptions{RPCTimeout: 20 * time.Millisecond})
+	if code, body := rpcBody(h, "/_seam/procedure/slow", `{}`, nil); code != http.StatusGatewayTimeout || strings.Contains(body, "retryAfter") {
+		t.Errorf("timeout without DefaultRetryAfter: %d %s", code, body)
+	}
+}
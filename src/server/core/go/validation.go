@@ -25,23 +25,88 @@ func shouldValidateMode(mode ValidationMode) bool {
 		return true
 	default:
 		// dev mode: skip validation when running in production
-		if env := os.Getenv("SEAM_ENV"); env == "production" {
-			return false
-		}
-		if env := os.Getenv("NODE_ENV"); env == "production" {
-			return false
-		}
-		return true
+		return !isProduction()
 	}
 }
 
-// ValidationDetail describes a single validation error at a specific path.
+// isProduction reports whether SEAM_ENV or NODE_ENV is "production".
+func isProduction() bool {
+	return os.Getenv("SEAM_ENV") == "production" || os.Getenv("NODE_ENV") == "production"
+}
+
+// ValidationDetail describes a single validation error. Path is a JSON
+// Pointer into the input ("" for the root), so clients can map errors to
+// form fields; the shape matches the TypeScript server's details.
 type ValidationDetail struct {
 	Path     string `json:"path"`
 	Expected string `json:"expected"`
 	Actual   string `json:"actual"`
 }
 
+// validationDetailsSchema is the JTD shape of `error.details` on
+// VALIDATION_ERROR responses.
+var validationDetailsSchema = map[string]any{
+	"elements": map[string]any{
+		"properties": map[string]any{
+			"path":     map[string]any{"type": "string"},
+			"expected": map[string]any{"type": "string"},
+			"actual":   map[string]any{"type": "string"},
+		},
+	},
+}
+
+// withValidationDetails adds an optional `details` property carrying the
+// validation error shape to a procedure's error schema, so generated
+// clients can type field errors. Procedures without input get the schema
+// unchanged, as do error schemas that are not properties forms or that
+// already declare `details`.
+func withValidationDetails(errorSchema, inputSchema any) any {
+	if isEmptySchema(inputSchema) {
+		return errorSchema
+	}
+	if errorSchema == nil {
+		return map[string]any{"optionalProperties": map[string]any{"details": validationDetailsSchema}}
+	}
+	m, ok := errorSchema.(map[string]any)
+	if !ok {
+		return errorSchema
+	}
+	_, hasProps := m["properties"]
+	_, hasOptional := m["optionalProperties"]
+	if !hasProps && !hasOptional {
+		return errorSchema
+	}
+	props, _ := m["properties"].(map[string]any)
+	optional, _ := m["optionalProperties"].(map[string]any)
+	if _, ok := props["details"]; ok {
+		return errorSchema
+	}
+	if _, ok := optional["details"]; ok {
+		return errorSchema
+	}
+	merged := make(map[string]any, len(m)+1)
+	for k, v := range m {
+		merged[k] = v
+	}
+	mergedOptional := make(map[string]any, len(optional)+1)
+	for k, v := range optional {
+		mergedOptional[k] = v
+	}
+	mergedOptional["details"] = validationDetailsSchema
+	merged["optionalProperties"] = mergedOptional
+	return merged
+}
+
+// isEmptySchema reports whether schema accepts anything (nil or {}), in
+// which case input validation can never fail.
+func isEmptySchema(schema any) bool {
+	if schema == nil {
+		return true
+	}
+	m, ok := schema.(map[string]any)
+	return ok && len(m) == 0
+}
+
 type schemaKind int
 
 const (
@@ -63,6 +128,7 @@ type namedSchema struct {
 type compiledSchema struct {
 	kind       schemaKind
 	jtdType    string                     // for kindType
+	format     string                     // for kindType "string": metadata.format (decimal, bigint)
 	enumValues []string                   // for kindEnum
 	inner      *compiledSchema            // for kindElements, kindValues, kindNullable
 	required   []namedSchema              // for kindProperties
@@ -115,6 +181,9 @@ func validateValue(cs *compiledSchema, data any, path []string, errors *[]Valida
 
 	case kindType:
 		validateType(cs.jtdType, data, path, errors)
+		if cs.format != "" {
+			validateNumberFormat(cs.format, data, path, errors)
+		}
 
 	case kindEnum:
 		s, ok := data.(string)
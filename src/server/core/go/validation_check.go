@@ -126,13 +126,22 @@ func isTimestampLike(s string) bool {
 	return false
 }
 
+// pathString renders an instance path as a JSON Pointer (RFC 6901); the
+// root is "".
 func pathString(path []string) string {
 	if len(path) == 0 {
 		return ""
 	}
-	return "/" + strings.Join(path, "/")
+	var b strings.Builder
+	for _, token := range path {
+		b.WriteByte('/')
+		b.WriteString(pointerEscaper.Replace(token))
+	}
+	return b.String()
 }
 
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
 func typeNameOf(v any) string {
 	switch v.(type) {
 	case nil:
@@ -56,6 +56,9 @@ func compileInner(schema, defs map[string]any) (*compiledSchema, error) {
 			return nil, fmt.Errorf("type must be a string")
 		}
 		cs := &compiledSchema{kind: kindType, jtdType: ts}
+		if meta, ok := schema["metadata"].(map[string]any); ok && ts == "string" {
+			cs.format, _ = meta["format"].(string)
+		}
 		if nullable {
 			return &compiledSchema{kind: kindNullable, inner: cs}, nil
 		}
@@ -141,3 +141,48 @@ func TestTypeNameOf(t *testing.T) {
 		})
 	}
 }
+
+func TestValidationDetailPathsAreJSONPointers(t *testing.T) {
+	schema := map[string]any{
+		"properties": map[string]any{
+			"items": map[string]any{"elements": map[string]any{
+				"properties": map[string]any{"a/b~c": map[string]any{"type": "string"}},
+			}},
+		},
+	}
+	_, details := ValidateInput(schema, map[string]any{
+		"items": []any{map[string]any{"a/b~c": "ok"}, map[string]any{"a/b~c": 1.0}},
+	})
+	if len(details) != 1 || details[0].Path != "/items/1/a~1b~0c" {
+		t.Fatalf("unexpected details %+v", details)
+	}
+	if details[0].Expected != "string" || details[0].Actual != "number" {
+		t.Fatalf("unexpected expected/actual %+v", details[0])
+	}
+}
+
+func TestWithValidationDetails(t *testing.T) {
+	input := map[string]any{"properties": map[string]any{"name": map[string]any{"type": "string"}}}
+
+	if got := withValidationDetails(nil, map[string]any{}); got != nil {
+		t.Fatalf("empty input should not add an error schema, got %v", got)
+	}
+	got := withValidationDetails(nil, input).(map[string]any)
+	if got["optionalProperties"].(map[string]any)["details"] == nil {
+		t.Fatalf("expected details property, got %v", got)
+	}
+
+	custom := map[string]any{"properties": map[string]any{"reason": map[string]any{"type": "string"}}}
+	merged := withValidationDetails(custom, input).(map[string]any)
+	if merged["properties"].(map[string]any)["reason"] == nil || merged["optionalProperties"].(map[string]any)["details"] == nil {
+		t.Fatalf("expected reason and details, got %v", merged)
+	}
+	if _, ok := custom["optionalProperties"]; ok {
+		t.Fatal("caller's error schema must not be mutated")
+	}
+
+	disc := map[string]any{"discriminator": "kind", "mapping": map[string]any{}}
+	if withValidationDetails(disc, input).(map[string]any)["optionalProperties"] != nil {
+		t.Fatal("non-properties error schemas are left unchanged")
+	}
+}
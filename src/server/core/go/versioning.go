@@ -0,0 +1,108 @@
+/* src/server/core/go/versioning.go */
+
+package seam
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Deprecation marks a procedure as deprecated in the manifest.
+type Deprecation struct {
+	Message     string `json:"message,omitempty"`
+	Replacement string `json:"replacement,omitempty"` // e.g. "getUser@v2"
+}
+
+// WithVersion registers the procedure under a versioned name ("getUser@v2").
+// Clients select it by calling the suffixed name directly or by sending the
+// version header (HandlerOptions.VersionHeader) with the bare name. A bare
+// name without a matching header resolves to the unversioned registration
+// when there is one, else to the newest version: register the stable
+// version without WithVersion to keep bare callers on it.
+func WithVersion(version string) ProcedureOption {
+	return func(p *ProcedureDef) {
+		base, _ := splitVersion(p.Name)
+		p.Name = base + "@" + version
+	}
+}
+
+// WithDeprecated marks the procedure as deprecated, optionally pointing
+// clients at its replacement.
+func WithDeprecated(message, replacement string) ProcedureOption {
+	return func(p *ProcedureDef) {
+		p.Deprecated = &Deprecation{Message: message, Replacement: replacement}
+	}
+}
+
+// splitVersion splits "getUser@v2" into ("getUser", "v2").
+func splitVersion(name string) (string, string) {
+	if idx := strings.LastIndexByte(name, '@'); idx > 0 {
+		return name[:idx], name[idx+1:]
+	}
+	return name, ""
+}
+
+// buildVersionIndex maps each base name with versioned registrations to its
+// versions, sorted oldest to newest.
+func buildVersionIndex(handlers map[string]*ProcedureDef) map[string][]string {
+	index := make(map[string][]string)
+	for name := range handlers {
+		base, version := splitVersion(name)
+		if version != "" {
+			index[base] = append(index[base], version)
+		}
+	}
+	for _, versions := range index {
+		sort.Slice(versions, func(i, j int) bool { return compareVersions(versions[i], versions[j]) < 0 })
+	}
+	return index
+}
+
+// compareVersions orders "v1" < "v2" < "v10" < "v10.1"; non-numeric
+// segments fall back to string comparison.
+func compareVersions(a, b string) int {
+	as := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bs := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		ai, aErr := strconv.Atoi(as[i])
+		bi, bErr := strconv.Atoi(bs[i])
+		if aErr == nil && bErr == nil {
+			if ai != bi {
+				return ai - bi
+			}
+			continue
+		}
+		if c := strings.Compare(as[i], bs[i]); c != 0 {
+			return c
+		}
+	}
+	return len(as) - len(bs)
+}
+
+// resolveVersion maps a bare procedure name to a versioned registration.
+// Precedence: explicit "@version" suffix, version header, unversioned
+// registration, newest registered version.
+func (s *appState) resolveVersion(name string, r *http.Request) string {
+	if len(s.versions) == 0 {
+		return name
+	}
+	if _, version := splitVersion(name); version != "" {
+		return name
+	}
+	if s.opts.VersionHeader != "" && r != nil {
+		if v := r.Header.Get(s.opts.VersionHeader); v != "" {
+			if _, ok := s.handlers[name+"@"+v]; ok {
+				return name + "@" + v
+			}
+		}
+	}
+	if _, ok := s.handlers[name]; ok {
+		return name
+	}
+	if versions := s.versions[name]; len(versions) > 0 {
+		return name + "@" + versions[len(versions)-1]
+	}
+	return name
+}
@@ -0,0 +1,104 @@
+/* src/server/core/go/versioning_test.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type versionedOut struct {
+	Version string `json:"version"`
+}
+
+func versionedRouter(withBase bool) *Router {
+	r := NewRouter()
+	if withBase {
+		r.Procedure(Query("getUser", func(ctx context.Context, _ struct{}) (versionedOut, error) {
+			return versionedOut{Version: "base"}, nil
+		}, WithDeprecated("use getUser@v2", "getUser@v2")))
+	}
+	r.Procedure(Query("getUser", func(ctx context.Context, _ struct{}) (versionedOut, error) {
+		return versionedOut{Version: "v2"}, nil
+	}, WithVersion("v2")))
+	r.Procedure(Query("getUser", func(ctx context.Context, _ struct{}) (versionedOut, error) {
+		return versionedOut{Version: "v10"}, nil
+	}, WithVersion("v10")))
+	return r
+}
+
+func callVersion(t *testing.T, h http.Handler, name, header string) string {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/_seam/procedure/"+name, strings.NewReader(`{}`))
+	if header != "" {
+		req.Header.Set("X-Seam-Version", header)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("%s: expected 200, got %d: %s", name, w.Code, w.Body.String())
+	}
+	var resp struct {
+		Data versionedOut `json:"data"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	return resp.Data.Version
+}
+
+func TestProcedureVersionSelection(t *testing.T) {
+	h := versionedRouter(true).Handler()
+	for _, tc := range []struct{ name, header, want string }{
+		{"getUser", "", "base"},
+		{"getUser@v2", "", "v2"},
+		{"getUser", "v10", "v10"},
+		{"getUser", "v99", "base"},
+		{"getUser@v2", "v10", "v2"},
+	} {
+		if got := callVersion(t, h, tc.name, tc.header); got != tc.want {
+			t.Errorf("%s (header %q): got %s, want %s", tc.name, tc.header, got, tc.want)
+		}
+	}
+}
+
+func TestProcedureVersionDefaultsToNewest(t *testing.T) {
+	h := versionedRouter(false).Handler()
+	if got := callVersion(t, h, "getUser", ""); got != "v10" {
+		t.Fatalf("expected newest version v10, got %s", got)
+	}
+}
+
+func TestVersionHeaderDefaultsWithExplicitOptions(t *testing.T) {
+	h := versionedRouter(true).Handler(HandlerOptions{RPCTimeout: 5 * time.Second})
+	if got := callVersion(t, h, "getUser", "v10"); got != "v10" {
+		t.Fatalf("expected the default version header to select v10, got %s", got)
+	}
+}
+
+func TestProcedureVersionManifest(t *testing.T) {
+	raw, _ := versionedRouter(true).Manifest()
+	var m struct {
+		Procedures map[string]map[string]any `json:"procedures"`
+	}
+	_ = json.Unmarshal(raw, &m)
+	if m.Procedures["getUser@v2"]["version"] != "v2" {
+		t.Fatalf("expected version field on getUser@v2, got %v", m.Procedures["getUser@v2"])
+	}
+	dep, ok := m.Procedures["getUser"]["deprecated"].(map[string]any)
+	if !ok || dep["replacement"] != "getUser@v2" {
+		t.Fatalf("expected deprecation metadata, got %v", m.Procedures["getUser"])
+	}
+	if _, ok := m.Procedures["getUser@v10"]["deprecated"]; ok {
+		t.Fatal("expected deprecated to be omitted")
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	if compareVersions("v2", "v10") >= 0 || compareVersions("v10", "v10.1") >= 0 || compareVersions("v1", "v1") != 0 {
+		t.Fatal("unexpected version ordering")
+	}
+}
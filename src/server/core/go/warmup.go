@@ -0,0 +1,203 @@
+/* src/server/core/go/warmup.go */
+
+package seam
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	engine "github.com/canmi21/seam/src/server/engine/go"
+)
+
+// WarmupOptions selects what Warmup primes besides the WASM engine.
+type WarmupOptions struct {
+	// Handler is the handler about to serve traffic; its per-handler
+	// caches (layout cache, memoized loaders) are primed by rendering
+	// Routes through it. Default: a fresh r.Handler().
+	Handler http.Handler
+	// Routes are concrete page paths ("/", "/blog/hello") rendered once
+	// per locale.
+	Routes []string
+	// Locales to render each route in (default: every configured locale;
+	// routes render once without a locale when i18n is off).
+	Locales []string
+	// Procedures are critical upstream calls to make once, e.g. to open
+	// connection pools; a failure fails the warmup.
+	Procedures []WarmupCall
+	// Concurrency bounds parallel page renders and calls (default 4).
+	Concurrency int
+}
+
+// WarmupCall is a procedure call made by Warmup.
+type WarmupCall struct {
+	Procedure string
+	Input     any
+}
+
+// WarmupReport records what Warmup did and how long each step took.
+type WarmupReport struct {
+	Engine     time.Duration
+	Pages      []WarmupResult
+	Procedures []WarmupResult
+	Total      time.Duration
+}
+
+// WarmupResult is the outcome of one warmed page or procedure.
+type WarmupResult struct {
+	Target   string
+	Duration time.Duration
+	Err      error
+}
+
+// Warmup pays cold-start costs before the first request does: it
+// instantiates the WASM engine, renders the configured routes in each
+// locale through the serving handler, and calls critical procedures.
+// Failed pages and procedures are reported and joined into the error;
+// the report is always returned.
+func (r *Router) Warmup(ctx context.Context, opts ...WarmupOptions) (*WarmupReport, error) {
+	var o WarmupOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	concurrency := o.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	start := time.Now()
+	report := &WarmupReport{}
+
+	// First engine call compiles the module; later calls only instantiate
+	if _, err := engine.AsciiEscapeJSON("{}"); err != nil {
+		return report, fmt.Errorf("warm up engine: %w", err)
+	}
+	report.Engine = time.Since(start)
+
+	var tasks []func() WarmupResult
+	if len(o.Routes) > 0 {
+		h := o.Handler
+		if h == nil {
+			h = r.Handler()
+		}
+		locales := o.Locales
+		if locales == nil && r.i18nConfig != nil {
+			locales = r.i18nConfig.Locales
+		}
+		for _, route := range o.Routes {
+			if len(locales) == 0 {
+				tasks = append(tasks, warmPage(ctx, h, route, ""))
+			}
+			for _, locale := range locales {
+				tasks = append(tasks, warmPage(ctx, h, route, locale))
+			}
+		}
+	}
+	report.Pages = runWarmup(tasks, concurrency)
+
+	tasks = tasks[:0]
+	for _, call := range o.Procedures {
+		tasks = append(tasks, r.warmProcedure(ctx, call))
+	}
+	report.Procedures = runWarmup(tasks, concurrency)
+	report.Total = time.Since(start)
+
+	var errs []error
+	for _, res := range append(append([]WarmupResult{}, report.Pages...), report.Procedures...) {
+		if res.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", res.Target, res.Err))
+		}
+	}
+	return report, errors.Join(errs...)
+}
+
+func runWarmup(tasks []func() WarmupResult, concurrency int) []WarmupResult {
+	results := make([]WarmupResult, len(tasks))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, task := range tasks {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, task func() WarmupResult) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			started := time.Now()
+			results[i] = task()
+			results[i].Duration = time.Since(started)
+		}(i, task)
+	}
+	wg.Wait()
+	return results
+}
+
+// warmPage renders route (with an optional locale prefix) through h.
+func warmPage(ctx context.Context, h http.Handler, route, locale string) func() WarmupResult {
+	path := seamPagePath(route, locale)
+	return func() WarmupResult {
+		res := WarmupResult{Target: "page " + path}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+		if err != nil {
+			res.Err = err
+			return res
+		}
+		w := &discardResponse{header: http.Header{}}
+		h.ServeHTTP(w, req)
+		if w.status >= 400 {
+			res.Err = fmt.Errorf("status %d", w.status)
+		}
+		return res
+	}
+}
+
+// warmProcedure calls a registered query or command directly.
+func (r *Router) warmProcedure(ctx context.Context, call WarmupCall) func() WarmupResult {
+	return func() WarmupResult {
+		res := WarmupResult{Target: "procedure " + call.Procedure}
+		var proc *ProcedureDef
+		for i := range r.procedures {
+			if r.procedures[i].Name == call.Procedure {
+				proc = &r.procedures[i]
+				break
+			}
+		}
+		if proc == nil {
+			res.Err = fmt.Errorf("procedure not registered")
+			return res
+		}
+		input := call.Input
+		if input == nil {
+			input = map[string]any{}
+		}
+		raw, err := json.Marshal(input)
+		if err != nil {
+			res.Err = err
+			return res
+		}
+		_, res.Err = proc.Handler(injectState(ctx, r.appState), raw)
+		return res
+	}
+}
+
+// discardResponse is a ResponseWriter that keeps only the status.
+type discardResponse struct {
+	header http.Header
+	status int
+}
+
+func (w *discardResponse) Header() http.Header { return w.header }
+
+func (w *discardResponse) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return len(b), nil
+}
+
+func (w *discardResponse) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
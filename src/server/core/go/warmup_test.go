@@ -0,0 +1,55 @@
+/* src/server/core/go/warmup_test.go */
+
+package seam
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestWarmupRendersRoutesAndCallsProcedures(t *testing.T) {
+	var loads, pings atomic.Int32
+	router := NewRouter().
+		Procedure(Query("getHome", func(context.Context, struct{}) (string, error) {
+			loads.Add(1)
+			return "hi", nil
+		})).
+		Procedure(Query("pingDB", func(context.Context, struct{}) (bool, error) {
+			pings.Add(1)
+			return true, nil
+		})).
+		Procedure(Query("pingSearch", func(context.Context, struct{}) (bool, error) {
+			return false, errors.New("connection refused")
+		})).
+		Page(&PageDef{
+			Route:    "/home",
+			Template: "<html><body><!--seam:home--></body></html>",
+			Loaders:  []LoaderDef{{DataKey: "home", Procedure: "getHome", InputFn: func(map[string]string) any { return map[string]any{} }}},
+		})
+
+	report, err := router.Warmup(context.Background(), WarmupOptions{
+		Handler:    router.Handler(),
+		Routes:     []string{"/home", "/missing"},
+		Procedures: []WarmupCall{{Procedure: "pingDB"}, {Procedure: "pingSearch"}},
+	})
+	if loads.Load() != 1 || pings.Load() != 1 {
+		t.Fatalf("loads=%d pings=%d", loads.Load(), pings.Load())
+	}
+	if len(report.Pages) != 2 || report.Pages[0].Err != nil || report.Pages[1].Err == nil {
+		t.Fatalf("unexpected page results %+v", report.Pages)
+	}
+	if err == nil || !strings.Contains(err.Error(), "procedure pingSearch: connection refused") ||
+		!strings.Contains(err.Error(), "page /_seam/page/missing: status 404") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestWarmupEngineOnly(t *testing.T) {
+	report, err := NewRouter().Warmup(context.Background())
+	if err != nil || len(report.Pages) != 0 || report.Total < report.Engine {
+		t.Fatalf("report=%+v err=%v", report, err)
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"sync"
 
 	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/experimental"
 )
 
 //go:embed engine.wasm
@@ -24,7 +26,8 @@ var (
 
 func initialize() {
 	ctx := context.Background()
-	rt = wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfigInterpreter())
+	// Terminate calls whose context is done (Limits.Timeout, caller deadlines, call budget)
+	rt = wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfigInterpreter().WithCloseOnContextDone(true))
 	compiled, initErr = rt.CompileModule(ctx, wasmBytes)
 }
 
@@ -33,49 +36,100 @@ func ensureInit() error {
 	return initErr
 }
 
-// callWasm invokes a WASM function with N string arguments, returning a string result.
-func callWasm(funcName string, args ...string) (string, error) {
+// instance is one instantiated WASM module. Calls on an instance share
+// its linear memory, so an instance must not be used concurrently.
+type instance struct {
+	mod          api.Module
+	malloc       api.Function
+	free         api.Function
+	stackPointer api.Function
+	memory       *limitedMemory
+}
+
+// newInstance instantiates the module under limits: the metered module
+// when a call budget is set, and a capped linear memory.
+func newInstance(limits Limits) (*instance, error) {
 	if err := ensureInit(); err != nil {
-		return "", err
+		return nil, err
 	}
+	code := compiled
+	if limits.MaxCalls > 0 {
+		var err error
+		if code, err = meteredModule(); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkMemoryLimit(code, limits.MaxMemoryBytes); err != nil {
+		return nil, err
+	}
+	memory := &limitedMemory{limit: limits.MaxMemoryBytes}
+	instCtx := experimental.WithMemoryAllocator(context.Background(), experimental.MemoryAllocatorFunc(memory.allocate))
+	mod, err := rt.InstantiateModule(instCtx, code, wazero.NewModuleConfig().WithName(""))
+	if err != nil {
+		return nil, fmt.Errorf("instantiate: %w", classify(nil, memory, err))
+	}
+	inst := &instance{
+		memory:       memory,
+		mod:          mod,
+		malloc:       mod.ExportedFunction("__wbindgen_export"),
+		free:         mod.ExportedFunction("__wbindgen_export3"),
+		stackPointer: mod.ExportedFunction("__wbindgen_add_to_stack_pointer"),
+	}
+	if inst.malloc == nil {
+		inst.close()
+		return nil, fmt.Errorf("__wbindgen_export (malloc) not exported")
+	}
+	if inst.stackPointer == nil {
+		inst.close()
+		return nil, fmt.Errorf("__wbindgen_add_to_stack_pointer not exported")
+	}
+	return inst, nil
+}
 
-	ctx := context.Background()
+func (inst *instance) close() {
+	_ = inst.mod.Close(context.Background())
+}
 
+// callWasm invokes a WASM function with N string arguments on a fresh
+// instance, returning a string result.
+func callWasm(ctx context.Context, funcName string, args ...string) (string, error) {
 	// Fresh instance per call for isolation
-	mod, err := rt.InstantiateModule(ctx, compiled, wazero.NewModuleConfig().WithName(""))
+	inst, err := newInstance(limitsFrom(ctx))
 	if err != nil {
-		return "", fmt.Errorf("instantiate: %w", err)
+		return "", err
 	}
-	defer func() { _ = mod.Close(ctx) }()
+	defer inst.close()
+	return inst.call(ctx, funcName, args...)
+}
 
-	malloc := mod.ExportedFunction("__wbindgen_export")
-	free := mod.ExportedFunction("__wbindgen_export3")
-	stackPointer := mod.ExportedFunction("__wbindgen_add_to_stack_pointer")
+// call invokes a WASM function with N string arguments, returning a string
+// result. A call breaching the limits of ctx closes the module.
+func (inst *instance) call(ctx context.Context, funcName string, args ...string) (string, error) {
+	ctx, budget, cancel := withBudget(ctx)
+	defer cancel()
+	inst.memory.hit = false
+	mod := inst.mod
 	fn := mod.ExportedFunction(funcName)
 	if fn == nil {
 		return "", fmt.Errorf("function %s not exported", funcName)
 	}
-	if malloc == nil {
-		return "", fmt.Errorf("__wbindgen_export (malloc) not exported")
-	}
-	if stackPointer == nil {
-		return "", fmt.Errorf("__wbindgen_add_to_stack_pointer not exported")
-	}
 
 	// Allocate stack space for return values (ptr + len = 8 bytes, padded to 16)
-	spRes, err := stackPointer.Call(ctx, uint64(^uint32(15)))
+	spRes, err := inst.stackPointer.Call(ctx, uint64(^uint32(15)))
 	if err != nil {
-		return "", fmt.Errorf("stack pointer alloc: %w", err)
+		return "", fmt.Errorf("stack pointer alloc: %w", classify(budget, inst.memory, err))
 	}
 	retptr := uint32(spRes[0])
+	// Restore stack pointer on every path so the instance stays reusable
+	defer func() { _, _ = inst.stackPointer.Call(context.WithoutCancel(ctx), 16) }()
 
 	// Write all string arguments to WASM memory
 	params := []uint64{uint64(retptr)}
 	for _, arg := range args {
 		argBytes := []byte(arg)
-		res, err := malloc.Call(ctx, uint64(len(argBytes)), 1)
+		res, err := inst.malloc.Call(ctx, uint64(len(argBytes)), 1)
 		if err != nil {
-			return "", fmt.Errorf("malloc arg: %w", err)
+			return "", fmt.Errorf("malloc arg: %w", classify(budget, inst.memory, err))
 		}
 		ptr := uint32(res[0])
 		if !mod.Memory().Write(ptr, argBytes) {
@@ -87,7 +141,7 @@ func callWasm(funcName string, args ...string) (string, error) {
 	// Call function (results written to retptr, not returned)
 	_, err = fn.Call(ctx, params...)
 	if err != nil {
-		return "", fmt.Errorf("call %s: %w", funcName, err)
+		return "", fmt.Errorf("call %s: %w", funcName, classify(budget, inst.memory, err))
 	}
 
 	// Read return values from stack memory
@@ -98,9 +152,6 @@ func callWasm(funcName string, args ...string) (string, error) {
 	resultPtr := binary.LittleEndian.Uint32(retBytes[0:4])
 	resultLen := binary.LittleEndian.Uint32(retBytes[4:8])
 
-	// Restore stack pointer
-	_, _ = stackPointer.Call(ctx, 16)
-
 	// Read result string from WASM memory
 	resultBytes, ok := mod.Memory().Read(resultPtr, resultLen)
 	if !ok {
@@ -109,8 +160,8 @@ func callWasm(funcName string, args ...string) (string, error) {
 	output := string(resultBytes)
 
 	// Free result memory
-	if free != nil {
-		_, _ = free.Call(ctx, uint64(resultPtr), uint64(resultLen), 1)
+	if inst.free != nil {
+		_, _ = inst.free.Call(ctx, uint64(resultPtr), uint64(resultLen), 1)
 	}
 
 	return output, nil
@@ -118,40 +169,133 @@ func callWasm(funcName string, args ...string) (string, error) {
 
 // RenderPage assembles a page: inject slots, build data script, apply locale/meta.
 func RenderPage(template, loaderDataJSON, configJSON, i18nOptsJSON string) (string, error) {
-	return callWasm("render_page", template, loaderDataJSON, configJSON, i18nOptsJSON)
+	return RenderPageContext(context.Background(), template, loaderDataJSON, configJSON, i18nOptsJSON)
+}
+
+// RenderPageContext is RenderPage bounded by ctx: its deadline and the
+// Limits attached with WithLimits (default: SetDefaultLimits).
+func RenderPageContext(ctx context.Context, template, loaderDataJSON, configJSON, i18nOptsJSON string) (string, error) {
+	return callWasm(ctx, "render_page", template, loaderDataJSON, configJSON, i18nOptsJSON)
 }
 
 // ParseBuildOutput parses route-manifest.json into page definitions with layout chains.
 func ParseBuildOutput(manifestJSON string) (string, error) {
-	return callWasm("parse_build_output", manifestJSON)
+	return callWasm(context.Background(), "parse_build_output", manifestJSON)
 }
 
 // ParseI18nConfig extracts i18n configuration from manifest JSON.
 func ParseI18nConfig(manifestJSON string) (string, error) {
-	return callWasm("parse_i18n_config", manifestJSON)
+	return callWasm(context.Background(), "parse_i18n_config", manifestJSON)
 }
 
 // ParseRpcHashMap builds a reverse lookup from RPC hash map JSON.
 func ParseRpcHashMap(hashMapJSON string) (string, error) {
-	return callWasm("parse_rpc_hash_map", hashMapJSON)
+	return callWasm(context.Background(), "parse_rpc_hash_map", hashMapJSON)
 }
 
 // AsciiEscapeJSON escapes non-ASCII characters in JSON string values.
 func AsciiEscapeJSON(json string) (string, error) {
-	return callWasm("ascii_escape_json", json)
+	return callWasm(context.Background(), "ascii_escape_json", json)
 }
 
 // I18nQuery looks up i18n translation keys from locale messages.
 func I18nQuery(keysJSON, locale, defaultLocale, messagesJSON string) (string, error) {
-	return callWasm("i18n_query", keysJSON, locale, defaultLocale, messagesJSON)
+	return callWasm(context.Background(), "i18n_query", keysJSON, locale, defaultLocale, messagesJSON)
 }
 
 // Inject renders template with data and appends a data script tag using dataID.
 func Inject(template, dataJSON, dataID string) (string, error) {
-	return callWasm("inject", template, dataJSON, dataID)
+	return callWasm(context.Background(), "inject", template, dataJSON, dataID)
 }
 
 // InjectNoScript renders template with data without data script tag.
 func InjectNoScript(template, dataJSON string) (string, error) {
-	return callWasm("inject_no_script", template, dataJSON)
+	return callWasm(context.Background(), "inject_no_script", template, dataJSON)
+}
+
+// RenderCall holds the arguments of one RenderPage call.
+type RenderCall struct {
+	Template       string
+	LoaderDataJSON string
+	ConfigJSON     string
+	I18nOptsJSON   string
+}
+
+// Session reuses one WASM instance across calls, skipping per-call
+// instantiation for batch jobs (multi-locale prerender, export, ISR).
+// Calls still run one at a time: a Session is not safe for concurrent use.
+type Session struct {
+	inst   *instance
+	limits Limits
+}
+
+// NewSession instantiates the engine for a sequence of calls. Close it
+// when done.
+func NewSession() (*Session, error) {
+	return NewSessionContext(context.Background())
+}
+
+// NewSessionContext is NewSession with the memory limit and call budget
+// (see WithLimits) of ctx applied to the session's instances.
+func NewSessionContext(ctx context.Context) (*Session, error) {
+	limits := limitsFrom(ctx)
+	inst, err := newInstance(limits)
+	if err != nil {
+		return nil, err
+	}
+	return &Session{inst: inst, limits: limits}, nil
+}
+
+// RenderPage is RenderPage on the session's instance. A failed call
+// replaces the instance, since a trapped module may be left inconsistent.
+func (s *Session) RenderPage(template, loaderDataJSON, configJSON, i18nOptsJSON string) (string, error) {
+	return s.RenderPageContext(context.Background(), template, loaderDataJSON, configJSON, i18nOptsJSON)
+}
+
+// RenderPageContext is RenderPage bounded by ctx's deadline and the
+// session's Limits.
+func (s *Session) RenderPageContext(ctx context.Context, template, loaderDataJSON, configJSON, i18nOptsJSON string) (string, error) {
+	if s.inst == nil {
+		inst, err := newInstance(s.limits)
+		if err != nil {
+			return "", err
+		}
+		s.inst = inst
+	}
+	if _, ok := ctx.Value(limitsKey{}).(Limits); !ok {
+		ctx = WithLimits(ctx, s.limits)
+	}
+	html, err := s.inst.call(ctx, "render_page", template, loaderDataJSON, configJSON, i18nOptsJSON)
+	if err != nil {
+		s.inst.close()
+		s.inst = nil
+	}
+	return html, err
+}
+
+// Close releases the session's instance.
+func (s *Session) Close() {
+	if s.inst != nil {
+		s.inst.close()
+		s.inst = nil
+	}
+}
+
+// RenderPages renders every call on one instance. Results and errors are
+// index-aligned with calls; one failed render does not stop the rest.
+func RenderPages(calls []RenderCall) ([]string, []error) {
+	out := make([]string, len(calls))
+	errs := make([]error, len(calls))
+	s, err := NewSession()
+	if err != nil {
+		for i := range errs {
+			errs[i] = err
+		}
+		return out, errs
+	}
+	defer s.Close()
+	for i, c := range calls {
+		out[i], errs[i] = s.RenderPage(c.Template, c.LoaderDataJSON, c.ConfigJSON, c.I18nOptsJSON)
+	}
+	return out, errs
 }
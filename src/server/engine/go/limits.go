@@ -0,0 +1,208 @@
+/* src/server/engine/go/limits.go */
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/experimental"
+)
+
+// Limits bounds a single engine call so a malformed template or huge
+// payload cannot spin or balloon memory. Zero fields are unlimited.
+type Limits struct {
+	// Timeout is the wall-clock budget of one call, on top of any
+	// deadline of the caller's context.
+	Timeout time.Duration
+	// MaxMemoryBytes caps the linear memory of the WASM instance.
+	MaxMemoryBytes uint64
+	// MaxCalls caps WASM function calls per engine call, a proxy for an
+	// instruction count (wazero has no instruction metering). Metered
+	// calls run on a separately compiled module with a call listener.
+	MaxCalls int64
+}
+
+// Budget breach errors, wrapped in the error of the failing call.
+var (
+	ErrTimeout     = errors.New("engine call exceeded its time budget")
+	ErrMemoryLimit = errors.New("engine call exceeded its memory limit")
+	ErrCallLimit   = errors.New("engine call exceeded its call budget")
+)
+
+// BudgetStats counts engine calls stopped by each limit.
+type BudgetStats struct {
+	Timeouts     uint64
+	MemoryLimits uint64
+	CallLimits   uint64
+}
+
+var (
+	defaultLimits atomic.Pointer[Limits]
+	budgetHits    struct{ timeouts, memory, calls atomic.Uint64 }
+)
+
+type limitsKey struct{}
+
+// SetDefaultLimits sets the limits of calls whose context carries none,
+// including every call of the context-less functions.
+func SetDefaultLimits(l Limits) {
+	defaultLimits.Store(&l)
+}
+
+// WithLimits attaches per-call limits to ctx for RenderPageContext and
+// NewSessionContext, overriding the defaults.
+func WithLimits(ctx context.Context, l Limits) context.Context {
+	return context.WithValue(ctx, limitsKey{}, l)
+}
+
+func limitsFrom(ctx context.Context) Limits {
+	if l, ok := ctx.Value(limitsKey{}).(Limits); ok {
+		return l
+	}
+	if l := defaultLimits.Load(); l != nil {
+		return *l
+	}
+	return Limits{}
+}
+
+// BudgetHits returns how many calls each limit has stopped since start.
+func BudgetHits() BudgetStats {
+	return BudgetStats{
+		Timeouts:     budgetHits.timeouts.Load(),
+		MemoryLimits: budgetHits.memory.Load(),
+		CallLimits:   budgetHits.calls.Load(),
+	}
+}
+
+// callBudget counts the WASM function calls of one engine call; the
+// listener cancels the call's context once max is exceeded.
+type callBudget struct {
+	max      int64
+	n        int64
+	cancel   context.CancelFunc
+	exceeded bool
+}
+
+type budgetKey struct{}
+
+// withBudget applies the Timeout and MaxCalls of ctx's limits.
+func withBudget(ctx context.Context) (context.Context, *callBudget, context.CancelFunc) {
+	limits := limitsFrom(ctx)
+	cancelTimeout := func() {}
+	if limits.Timeout > 0 {
+		ctx, cancelTimeout = context.WithTimeout(ctx, limits.Timeout)
+	}
+	if limits.MaxCalls <= 0 {
+		return ctx, nil, cancelTimeout
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	budget := &callBudget{max: limits.MaxCalls, cancel: cancel}
+	return context.WithValue(ctx, budgetKey{}, budget), budget, func() {
+		cancel()
+		cancelTimeout()
+	}
+}
+
+// countCalls is the function listener of the metered module.
+func countCalls(ctx context.Context, _ api.Module, _ api.FunctionDefinition, _ []uint64, _ experimental.StackIterator) {
+	budget, ok := ctx.Value(budgetKey{}).(*callBudget)
+	if !ok || budget.exceeded {
+		return
+	}
+	budget.n++
+	if budget.n > budget.max {
+		budget.exceeded = true
+		budget.cancel()
+	}
+}
+
+var (
+	meteredOnce     sync.Once
+	meteredCompiled wazero.CompiledModule
+	meteredErr      error
+)
+
+// meteredModule compiles the module with the call-counting listener on
+// first use, so unmetered calls pay no listener overhead.
+func meteredModule() (wazero.CompiledModule, error) {
+	meteredOnce.Do(func() {
+		factory := experimental.FunctionListenerFactoryFunc(func(api.FunctionDefinition) experimental.FunctionListener {
+			return experimental.FunctionListenerFunc(countCalls)
+		})
+		ctx := experimental.WithFunctionListenerFactory(context.Background(), factory)
+		meteredCompiled, meteredErr = rt.CompileModule(ctx, wasmBytes)
+	})
+	return meteredCompiled, meteredErr
+}
+
+// limitedMemory backs an instance's linear memory, refusing to grow past
+// limit; the guest sees a failed memory.grow and traps.
+type limitedMemory struct {
+	limit uint64
+	buf   []byte
+	hit   bool
+}
+
+func (m *limitedMemory) allocate(capacity, _ uint64) experimental.LinearMemory {
+	if m.limit > 0 && capacity > m.limit {
+		capacity = m.limit
+	}
+	m.buf = make([]byte, 0, capacity)
+	return m
+}
+
+// Reallocate implements experimental.LinearMemory.
+func (m *limitedMemory) Reallocate(size uint64) []byte {
+	if m.limit > 0 && size > m.limit {
+		m.hit = true
+		return nil
+	}
+	if size > uint64(cap(m.buf)) {
+		grown := make([]byte, size, max(size, 2*uint64(cap(m.buf))))
+		copy(grown, m.buf)
+		m.buf = grown
+	}
+	m.buf = m.buf[:size]
+	return m.buf
+}
+
+// checkMemoryLimit rejects a limit below the module's initial memory,
+// which wazero cannot instantiate into.
+func checkMemoryLimit(mod wazero.CompiledModule, limit uint64) error {
+	if limit == 0 {
+		return nil
+	}
+	for _, mem := range mod.ExportedMemories() {
+		if initial := uint64(mem.Min()) * 65536; initial > limit {
+			return fmt.Errorf("%w: limit %d bytes is below the initial %d bytes", ErrMemoryLimit, limit, initial)
+		}
+	}
+	return nil
+}
+
+// Free implements experimental.LinearMemory.
+func (m *limitedMemory) Free() { m.buf = nil }
+
+// classify maps a failed WASM call to the limit it breached, counting
+// the hit; other errors are returned unchanged.
+func classify(budget *callBudget, memory *limitedMemory, err error) error {
+	switch {
+	case budget != nil && budget.exceeded:
+		budgetHits.calls.Add(1)
+		return fmt.Errorf("%w (%d calls): %v", ErrCallLimit, budget.max, err)
+	case memory != nil && memory.hit:
+		budgetHits.memory.Add(1)
+		return fmt.Errorf("%w (%d bytes): %v", ErrMemoryLimit, memory.limit, err)
+	case errors.Is(err, context.DeadlineExceeded):
+		budgetHits.timeouts.Add(1)
+		return fmt.Errorf("%w: %v", ErrTimeout, err)
+	}
+	return err
+}
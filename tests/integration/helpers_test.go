@@ -154,19 +154,6 @@ func assertErrorResponse(t *testing.T, body map[string]any, expectedCode string)
 	}
 }
 
-func normalizeJSON(t *testing.T, data []byte) string {
-	t.Helper()
-	var v any
-	if err := json.Unmarshal(data, &v); err != nil {
-		t.Fatalf("unmarshal for normalization: %v", err)
-	}
-	out, err := json.Marshal(v)
-	if err != nil {
-		t.Fatalf("remarshal for normalization: %v", err)
-	}
-	return string(out)
-}
-
 func assertContentType(t *testing.T, resp *http.Response, prefix string) {
 	t.Helper()
 	ct := resp.Header.Get("Content-Type")
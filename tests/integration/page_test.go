@@ -9,6 +9,8 @@ import (
 	"regexp"
 	"strings"
 	"testing"
+
+	"github.com/canmi21/seam/src/server/core/go/seamdiff"
 )
 
 // --- helpers ---
@@ -215,42 +217,20 @@ func TestPageParity(t *testing.T) {
 		t.Skip("need at least 2 backends for parity test")
 	}
 
-	t.Run("user id=1 HTML parity", func(t *testing.T) {
-		htmls := make([]string, len(backends))
-		for i, b := range backends {
-			_, html := getHTML(t, b.BaseURL+"/_seam/page/user/1")
-			htmls[i] = stripSeamData(html)
-		}
-
-		for i := 1; i < len(htmls); i++ {
-			if htmls[0] != htmls[i] {
-				t.Errorf("HTML mismatch between %s and %s:\n  %s: %s\n  %s: %s",
-					backends[0].Name, backends[i].Name,
-					backends[0].Name, htmls[0],
-					backends[i].Name, htmls[i])
-			}
-		}
-	})
-
-	t.Run("user id=1 data parity", func(t *testing.T) {
-		datas := make([]string, len(backends))
-		for i, b := range backends {
-			_, html := getHTML(t, b.BaseURL+"/_seam/page/user/1")
-			raw := extractSeamData(t, html)
-			j, err := json.Marshal(raw)
-			if err != nil {
-				t.Fatalf("remarshal: %v", err)
-			}
-			datas[i] = normalizeJSON(t, j)
+	// Markup is compared without the data script; the data script is
+	// compared as JSON.
+	htmls := make([]string, len(backends))
+	for i, b := range backends {
+		_, htmls[i] = getHTML(t, b.BaseURL+"/_seam/page/user/1")
+	}
+	for i := 1; i < len(htmls); i++ {
+		diffs, err := seamdiff.CompareHTML(htmls[0], htmls[i], seamdiff.Options{})
+		if err != nil {
+			t.Fatalf("compare user id=1: %v", err)
 		}
-
-		for i := 1; i < len(datas); i++ {
-			if datas[0] != datas[i] {
-				t.Errorf("data mismatch between %s and %s:\n  %s: %s\n  %s: %s",
-					backends[0].Name, backends[i].Name,
-					backends[0].Name, datas[0],
-					backends[i].Name, datas[i])
-			}
+		if len(diffs) > 0 {
+			t.Errorf("user id=1 mismatch between %s (A) and %s (B):\n%s",
+				backends[0].Name, backends[i].Name, seamdiff.Report(diffs))
 		}
-	})
+	}
 }
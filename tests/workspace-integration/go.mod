@@ -1,3 +1,10 @@
 module seam/workspace-integration
 
 go 1.25.0
+
+require github.com/canmi21/seam/src/server/core/go v0.5.36
+
+replace (
+	github.com/canmi21/seam/src/server/core/go => ../../src/server/core/go
+	github.com/canmi21/seam/src/server/engine/go => ../../src/server/engine/go
+)
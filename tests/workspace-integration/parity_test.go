@@ -4,6 +4,8 @@ package workspace_integration
 
 import (
 	"testing"
+
+	"github.com/canmi21/seam/src/server/core/go/seamdiff"
 )
 
 // TestManifestParity compares procedure names and types across backends.
@@ -82,13 +84,11 @@ func TestStaticRPCParity(t *testing.T) {
 		tc := tc
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
-			responses := make([]string, len(backends))
+			responses := make([][]byte, len(backends))
 			statuses := make([]int, len(backends))
 
 			for i, b := range backends {
-				status, raw := postJSONRaw(t, b.BaseURL+"/_seam/procedure/"+tc.proc, tc.payload)
-				statuses[i] = status
-				responses[i] = normalizeJSON(t, raw)
+				statuses[i], responses[i] = postJSONRaw(t, b.BaseURL+"/_seam/procedure/"+tc.proc, tc.payload)
 			}
 
 			for i := 1; i < len(backends); i++ {
@@ -97,11 +97,13 @@ func TestStaticRPCParity(t *testing.T) {
 						backends[0].Name, statuses[0],
 						backends[i].Name, statuses[i])
 				}
-				if responses[0] != responses[i] {
-					t.Errorf("response mismatch for %s:\n  %s: %s\n  %s: %s",
-						tc.name,
-						backends[0].Name, responses[0],
-						backends[i].Name, responses[i])
+				diffs, err := seamdiff.CompareJSON(responses[0], responses[i], seamdiff.Options{})
+				if err != nil {
+					t.Fatalf("compare %s: %v", tc.name, err)
+				}
+				if len(diffs) > 0 {
+					t.Errorf("response mismatch for %s between %s (A) and %s (B):\n%s",
+						tc.name, backends[0].Name, backends[i].Name, seamdiff.Report(diffs))
 				}
 			}
 		})
@@ -129,13 +131,11 @@ func TestGitHubRPCParity(t *testing.T) {
 		tc := tc
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
-			responses := make([]string, len(backends))
+			responses := make([][]byte, len(backends))
 			statuses := make([]int, len(backends))
 
 			for i, b := range backends {
-				status, raw := postJSONRaw(t, b.BaseURL+"/_seam/procedure/"+tc.proc, tc.payload)
-				statuses[i] = status
-				responses[i] = normalizeJSON(t, raw)
+				statuses[i], responses[i] = postJSONRaw(t, b.BaseURL+"/_seam/procedure/"+tc.proc, tc.payload)
 			}
 
 			for i := 1; i < len(backends); i++ {
@@ -144,11 +144,13 @@ func TestGitHubRPCParity(t *testing.T) {
 						backends[0].Name, statuses[0],
 						backends[i].Name, statuses[i])
 				}
-				if responses[0] != responses[i] {
-					t.Errorf("response mismatch for %s:\n  %s: %s\n  %s: %s",
-						tc.name,
-						backends[0].Name, responses[0],
-						backends[i].Name, responses[i])
+				diffs, err := seamdiff.CompareJSON(responses[0], responses[i], seamdiff.Options{})
+				if err != nil {
+					t.Fatalf("compare %s: %v", tc.name, err)
+				}
+				if len(diffs) > 0 {
+					t.Errorf("response mismatch for %s between %s (A) and %s (B):\n%s",
+						tc.name, backends[0].Name, backends[i].Name, seamdiff.Report(diffs))
 				}
 			}
 		})